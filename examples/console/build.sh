@@ -13,7 +13,7 @@ fi
 rm -rf ${BUILD_PATH}
 mkdir -m 775 -p ${BUILD_PATH}
 
-files="simple_inputs"
+files="simple_inputs setup_wizard"
 for n in $files ;do
     # linux build
     ${GO} build -o ${BUILD_PATH}/${n} ${n}/main.go
@@ -0,0 +1,75 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/exonlabs/go-utils/pkg/console"
+	"github.com/exonlabs/go-utils/pkg/jconfig"
+)
+
+var (
+	CFGFILE = filepath.Join(os.TempDir(), "sample_setup.json")
+	SECRET  = "12345678"
+)
+
+// setup wizard fields, current config values are used as defaults
+var SETUP = &console.Form{
+	Title: "Device Setup",
+	Fields: []console.Field{
+		{Name: "device.name", Prompt: "Device name", Default: "device-1",
+			Validators: []console.Validator{console.HostnameValidator}},
+		{Name: "network.address", Prompt: "Server address", Required: true,
+			Validators: []console.Validator{console.AnyOf(
+				console.IPValidator, console.HostnameValidator)}},
+		{Name: "network.port", Prompt: "Server port", Type: console.FIELD_NUMBER,
+			Default:    8080,
+			Validators: []console.Validator{console.PortValidator}},
+		{Name: "network.tls", Prompt: "Enable TLS", Type: console.FIELD_BOOL,
+			Default: true},
+		{Name: "logging.level", Prompt: "Log level", Type: console.FIELD_SELECT,
+			Options: []string{"debug", "info", "warn", "error"}, Default: "info"},
+		{Name: "admin.password", Prompt: "Admin password", Secure: true,
+			Required: true},
+	},
+}
+
+func main() {
+	con, _ := console.NewTermConsole()
+	defer con.Close()
+
+	cfg, _ := jconfig.New(CFGFILE, nil)
+	cfg.InitAES128(SECRET)
+	fmt.Println("\n* using cfg file:", CFGFILE)
+	if cfg.IsExist() {
+		if err := cfg.Load(); err != nil {
+			panic(err)
+		}
+	}
+	fmt.Println()
+
+	// the stored password is encrypted, only secure it again if changed
+	passwd := cfg.Get("admin.password", nil)
+	if err := SETUP.Fill(con, cfg.Buffer); err != nil {
+		fmt.Printf("\n-- setup aborted: %v\n\n", err)
+		return
+	}
+	if val := cfg.Get("admin.password", nil); val != passwd {
+		if err := cfg.SetSecure("admin.password", val); err != nil {
+			panic(err)
+		}
+	}
+
+	if err := cfg.Save(); err != nil {
+		panic(err)
+	}
+
+	b, _ := json.MarshalIndent(cfg.Buffer, "", "  ")
+	fmt.Printf("\n-- saved config:\n%s\n\n", string(b))
+}
@@ -78,6 +78,13 @@ func main() {
 			"(?:25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)$").
 		ReadValue("Enter IPv4 (x.x.x.x)", ""))
 
+	printValue(con.Required().
+		Validate(console.AnyOf(console.IPValidator, console.HostnameValidator)).
+		ReadValue("Enter server address (IP or hostname)", ""))
+	printValue(con.
+		Validate(console.PortValidator).
+		ReadNumber("Enter server port", 8080))
+
 	printValue(con.Required().
 		ReadNumber("Enter required number", 0))
 	printValue(con.
@@ -110,5 +117,11 @@ func main() {
 	printValue(con.Required().
 		SelectYesNo("Select Yes/No with default", "n"))
 
+	printValue(con.
+		Menu("Select from menu", []string{"val1", "val2", "val3"}, "val2"))
+	printValue(con.
+		SelectMulti("Select multiple from list",
+			[]string{"val1", "val2", "val3"}, []string{"val1", "val3"}))
+
 	fmt.Println()
 }
@@ -0,0 +1,51 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/logging/audit"
+)
+
+func main() {
+	path := flag.String("path",
+		filepath.Join(os.TempDir(), "audit.log"), "audit log path")
+	key := flag.String("key", os.Getenv("AUDIT_KEY"),
+		"audit HMAC key, (default from AUDIT_KEY env)")
+	verify := flag.Bool("verify", false, "verify audit log only")
+	flag.Parse()
+
+	if *key == "" {
+		fmt.Println("Error!! empty audit key")
+		os.Exit(2)
+	}
+
+	if !*verify {
+		l, err := audit.Open(*path, []byte(*key))
+		if err != nil {
+			fmt.Printf("Error!! %s\n", err.Error())
+			os.Exit(1)
+		}
+		l.Write("admin", "login", "", nil)
+		l.Write("admin", "set_config", "net.ip",
+			dictx.Dict{"old": "10.0.0.1", "new": "10.0.0.2"})
+		l.Write("admin", "logout", "", nil)
+		l.Close()
+		fmt.Println("\n* written audit records in:", *path)
+	}
+
+	last, err := audit.Verify(*path, []byte(*key))
+	if err != nil {
+		fmt.Printf("\n* verification FAILED: %s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("\n* verification OK, %d records, last mac: %s\n\n",
+		last.Seq, last.Mac)
+}
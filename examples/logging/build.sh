@@ -13,7 +13,7 @@ fi
 rm -rf ${BUILD_PATH}
 mkdir -m 775 -p ${BUILD_PATH}
 
-files="basic_loggers custom_formatter file_logging tree_loggers"
+files="basic_loggers custom_formatter file_logging tree_loggers audit_log"
 for n in $files ;do
     # linux build
     ${GO} build -o ${BUILD_PATH}/${n} ${n}/main.go
@@ -57,5 +57,9 @@ func main() {
 	logger.SetFormatter(formatter3)
 	log_messages(logger)
 
+	fmt.Println("\n* with color formatter:")
+	logger.SetFormatter(logging.NewColorFormatter())
+	log_messages(logger)
+
 	fmt.Println()
 }
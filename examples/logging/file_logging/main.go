@@ -7,9 +7,9 @@ package main
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
+	"github.com/exonlabs/go-utils/pkg/abc/fsx"
 	"github.com/exonlabs/go-utils/pkg/logging"
 )
 
@@ -26,8 +26,13 @@ func log_messages(logger *logging.Logger) {
 }
 
 func main() {
-	log_path := filepath.Join(os.TempDir(), "foobar.log")
-	defer os.Remove(log_path)
+	wd, err := fsx.NewWorkDir("", "file_logging", 0o755)
+	if err != nil {
+		fmt.Printf("Error!! failed create work dir, %s", err.Error())
+		return
+	}
+	defer wd.Close()
+	log_path := wd.Track("foobar.log")
 
 	logger := logging.NewStdoutLogger("main")
 	logger.Level = logging.DEBUG
@@ -17,4 +17,9 @@ files="send_recv"
 for n in $files ;do
     # linux build
     ${GO} build -o ${BUILD_PATH}/${n} ${n}/main.go
+    # windows build
+    GOOS=windows GOARCH=amd64 ${GO} build \
+        -o ${BUILD_PATH}/${n}_64.exe ${n}/main.go
+    GOOS=windows GOARCH=386 ${GO} build \
+        -o ${BUILD_PATH}/${n}_32.exe ${n}/main.go
 done
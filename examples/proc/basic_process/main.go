@@ -7,10 +7,12 @@ package main
 import (
 	"bytes"
 	"flag"
+	"fmt"
 	"os"
 	"runtime/debug"
 	"syscall"
 
+	"github.com/exonlabs/go-utils/pkg/abc/exitcode"
 	"github.com/exonlabs/go-utils/pkg/logging"
 	"github.com/exonlabs/go-utils/pkg/proc"
 )
@@ -39,7 +41,8 @@ func (p *SampleProcess) Execute() error {
 	// stop after n counts
 	if p.counter >= 60 {
 		p.Log.Info("exit process at count %d", p.counter)
-		p.Stop()
+		p.StopWithError(fmt.Errorf("%w: count limit reached",
+			exitcode.ErrTimeout))
 		return nil
 	}
 
@@ -70,16 +73,21 @@ func (p *SampleProcess) handleSigQuit() {
 func main() {
 	log := logging.NewStdoutLogger("main")
 
+	var p *SampleProcess
 	defer func() {
 		if r := recover(); r != nil {
 			stack := debug.Stack()
 			indx := bytes.Index(stack, []byte("panic({"))
 			log.Panic("%s", r)
 			log.Trace1("\n----------\n%s----------", stack[indx:])
-			os.Exit(1)
+			os.Exit(exitcode.SOFTWARE)
 		} else {
-			log.Info("exit")
-			os.Exit(0)
+			code := exitcode.OK
+			if p != nil {
+				code = p.ExitCode()
+			}
+			log.Info("exit (code %d)", code)
+			os.Exit(code)
 		}
 	}()
 
@@ -102,6 +110,6 @@ func main() {
 
 	log.Info("**** starting ****")
 
-	p := NewSampleProcess(log)
+	p = NewSampleProcess(log)
 	p.Start()
 }
@@ -6,16 +6,17 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime/debug"
-	"sort"
 	"strings"
 	"sync/atomic"
 	"time"
 
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
 	"github.com/exonlabs/go-utils/pkg/comm/commutils"
 	"github.com/exonlabs/go-utils/pkg/logging"
 	"github.com/exonlabs/go-utils/pkg/proc"
@@ -65,13 +66,15 @@ func HandleCommand(cmd string) string {
 		}()
 
 	case "list_workers":
-		workers := wrkManager.ListRoutines()
-		sort.Strings(workers)
-		res := strings.Join(workers, ",")
-		if len(res) > 0 {
-			return res
+		status := wrkManager.Status()
+		if len(status) == 0 {
+			return "<empty>"
 		}
-		return "<empty>"
+		b, err := json.Marshal(status)
+		if err != nil {
+			return "FAILED"
+		}
+		return string(b)
 
 	case "add_worker":
 		if (workers.Load() - wrkIndx.Load() + 1) >= 10 {
@@ -83,6 +86,7 @@ func HandleCommand(cmd string) string {
 			fmt.Println(err.Error())
 			return "FAILED"
 		}
+		logging.DefaultRegistry.Register("main."+wname, wrk.Log)
 		workers.Add(1)
 		fmt.Printf("added worker: %s\n", wname)
 
@@ -186,7 +190,25 @@ func main() {
 	}
 
 	wrkManager = proc.NewRoutineManager(log)
-	wrkManager.SetCmdHandler(commListener, HandleCommand)
+	// structured commands with JSON replies, legacy commands fallback
+	cmds := proc.NewCommandRegistry()
+	cmds.Register("status", "workers status", []proc.CmdParam{
+		{Name: "name", Help: "worker name, empty for all workers"},
+	}, func(args dictx.Dict) (any, error) {
+		status := wrkManager.Status()
+		if name := dictx.GetString(args, "name", ""); name != "" {
+			if st, ok := status[name]; ok {
+				return st, nil
+			}
+			return nil, fmt.Errorf("invalid worker name")
+		}
+		return status, nil
+	})
+	// runtime loggers levels control, ex: log_level main.wrk1 DEBUG
+	logging.DefaultRegistry.Register("main", log)
+	proc.RegisterLogLevel(cmds, nil)
+	cmds.SetFallback(HandleCommand)
+	wrkManager.SetCmdHandler(commListener, cmds.Handle)
 
 	for i := int32(1); i <= workers.Load(); i++ {
 		wname := fmt.Sprintf("wrk%d", i)
@@ -195,6 +217,7 @@ func main() {
 			log.Error(err.Error())
 			return
 		}
+		logging.DefaultRegistry.Register("main."+wname, wrk.Log)
 	}
 
 	wrkManager.Start()
@@ -25,6 +25,9 @@ var (
 	wrkIndx atomic.Int32
 
 	MaxWorkers = int32(10)
+
+	// simulate workers hanging on termination
+	hangTerm = false
 )
 
 type Worker struct {
@@ -34,6 +37,10 @@ type Worker struct {
 func NewWorker(log *logging.Logger) *Worker {
 	wk := &Worker{}
 	wk.RoutineHandler = proc.NewRoutineHandler(log, wk)
+	// abandon workers stuck in execution or termination
+	wk.SetExecuteTimeout(10)
+	wk.SetTerminateTimeout(3)
+	wk.SetAbandonStuck(true)
 	return wk
 }
 
@@ -54,6 +61,10 @@ func (wk *Worker) Execute() error {
 }
 
 func (wk *Worker) Terminate() error {
+	if hangTerm {
+		wk.Log.Info("terminating ... will not exit")
+		select {}
+	}
 	wk.Log.Info("terminated")
 	return nil
 }
@@ -78,7 +89,9 @@ func main() {
 	debug1 := flag.Bool("xx", false, "enable debug and trace1 logs")
 	debug2 := flag.Bool("xxx", false, "enable debug and trace2 logs")
 	debug3 := flag.Bool("xxxx", false, "enable debug and trace3 logs")
+	hang := flag.Bool("hang", false, "simulate workers hanging on termination")
 	flag.Parse()
+	hangTerm = *hang
 
 	switch {
 	case *debug3:
@@ -0,0 +1,100 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package bitx
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrRange is returned when a bit range does not fit in the data
+// or a value does not fit in the bit range width.
+var ErrRange = errors.New("bit range out of bounds")
+
+// Order defines the byte order of the data holding bit fields. Bits are
+// numbered treating the data as a single integer in that byte order,
+// where bit 0 is the least significant bit.
+type Order int
+
+const (
+	// BIG_ENDIAN defines data where the last byte holds bits 0-7.
+	BIG_ENDIAN Order = iota
+	// LITTLE_ENDIAN defines data where the first byte holds bits 0-7.
+	LITTLE_ENDIAN
+)
+
+// checkRange validates a bit range of width bits starting at offset.
+func checkRange(b []byte, offset, width int) error {
+	if offset < 0 || width < 1 || width > 64 || offset+width > 8*len(b) {
+		return fmt.Errorf("%w: offset %d width %d in %d bytes",
+			ErrRange, offset, width, len(b))
+	}
+	return nil
+}
+
+// bytePos returns the byte index and bit shift holding bit n.
+func bytePos(b []byte, order Order, n int) (int, uint) {
+	if order == LITTLE_ENDIAN {
+		return n / 8, uint(n % 8)
+	}
+	return len(b) - 1 - n/8, uint(n % 8)
+}
+
+// Get extracts an unsigned value of width bits starting at bit offset.
+func Get(b []byte, order Order, offset, width int) (uint64, error) {
+	if err := checkRange(b, offset, width); err != nil {
+		return 0, err
+	}
+	var val uint64
+	for i := 0; i < width; i++ {
+		idx, shift := bytePos(b, order, offset+i)
+		val |= uint64(b[idx]>>shift&1) << i
+	}
+	return val, nil
+}
+
+// GetSigned extracts a two's complement signed value of width bits
+// starting at bit offset, with sign extension.
+func GetSigned(b []byte, order Order, offset, width int) (int64, error) {
+	val, err := Get(b, order, offset, width)
+	if err != nil {
+		return 0, err
+	}
+	if width < 64 && val>>(width-1)&1 == 1 {
+		val |= ^uint64(0) << width
+	}
+	return int64(val), nil
+}
+
+// Put inserts an unsigned value of width bits starting at bit offset,
+// keeping the other bits unchanged.
+func Put(b []byte, order Order, offset, width int, val uint64) error {
+	if err := checkRange(b, offset, width); err != nil {
+		return err
+	}
+	if width < 64 && val>>width != 0 {
+		return fmt.Errorf("%w: value %d exceeds %d bits", ErrRange, val, width)
+	}
+	for i := 0; i < width; i++ {
+		idx, shift := bytePos(b, order, offset+i)
+		b[idx] = b[idx]&^(1<<shift) | byte(val>>i&1)<<shift
+	}
+	return nil
+}
+
+// PutSigned inserts a two's complement signed value of width bits
+// starting at bit offset, keeping the other bits unchanged.
+func PutSigned(b []byte, order Order, offset, width int, val int64) error {
+	if err := checkRange(b, offset, width); err != nil {
+		return err
+	}
+	if width < 64 {
+		if lim := int64(1) << (width - 1); val < -lim || val >= lim {
+			return fmt.Errorf("%w: value %d exceeds %d bits", ErrRange, val, width)
+		}
+		return Put(b, order, offset, width, uint64(val)&(1<<width-1))
+	}
+	return Put(b, order, offset, width, uint64(val))
+}
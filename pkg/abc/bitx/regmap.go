@@ -0,0 +1,121 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package bitx
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+)
+
+// Field describes a named bit field in a register map.
+type Field struct {
+	// Name defines the field key, which may be a nested dictx key.
+	Name string
+	// Offset defines the field first bit, where bit 0 is the least
+	// significant bit of the register data.
+	Offset int
+	// Width defines the field number of bits.
+	Width int
+	// Signed defines a two's complement signed field.
+	Signed bool
+	// Scale defines an optional factor converting the raw value into
+	// a float64 value, unused if zero.
+	Scale float64
+}
+
+// RegisterMap describes the fields layout of register data.
+type RegisterMap struct {
+	// Size defines the register data size in bytes.
+	Size int
+	// Order defines the register data byte order.
+	Order Order
+	// Fields defines the register fields.
+	Fields []Field
+}
+
+// Decode extracts the fields values from register data into a dictionary.
+// Values are uint64, int64 for signed fields, or float64 for scaled fields.
+func (m *RegisterMap) Decode(b []byte) (dictx.Dict, error) {
+	if len(b) < m.Size {
+		return nil, fmt.Errorf("%w: data size %d less than %d",
+			ErrRange, len(b), m.Size)
+	}
+	b = b[:m.Size]
+	d := dictx.Dict{}
+	for _, f := range m.Fields {
+		var val any
+		if f.Signed {
+			v, err := GetSigned(b, m.Order, f.Offset, f.Width)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			val = v
+			if f.Scale != 0 {
+				val = float64(v) * f.Scale
+			}
+		} else {
+			v, err := Get(b, m.Order, f.Offset, f.Width)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			val = v
+			if f.Scale != 0 {
+				val = float64(v) * f.Scale
+			}
+		}
+		dictx.Set(d, f.Name, val)
+	}
+	return d, nil
+}
+
+// Encode packs the fields values from a dictionary into register data.
+// Missing fields are left zero. Scaled fields values are divided by the
+// scale and rounded to the nearest raw value.
+func (m *RegisterMap) Encode(d dictx.Dict) ([]byte, error) {
+	b := make([]byte, m.Size)
+	for _, f := range m.Fields {
+		if !dictx.IsExist(d, f.Name) {
+			continue
+		}
+		var raw int64
+		if f.Scale != 0 {
+			v, err := dictx.GetFloatE(d, f.Name)
+			if err != nil {
+				return nil, err
+			}
+			raw = int64(math.Round(v / f.Scale))
+		} else if !f.Signed {
+			v, err := dictx.GetUintE(d, f.Name)
+			if err != nil {
+				return nil, err
+			}
+			if err := Put(b, m.Order, f.Offset, f.Width, uint64(v)); err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			continue
+		} else {
+			v, err := dictx.GetIntE(d, f.Name)
+			if err != nil {
+				return nil, err
+			}
+			raw = int64(v)
+		}
+
+		var err error
+		if f.Signed {
+			err = PutSigned(b, m.Order, f.Offset, f.Width, raw)
+		} else if raw < 0 {
+			err = fmt.Errorf("%w: negative value %d", ErrRange, raw)
+		} else {
+			err = Put(b, m.Order, f.Offset, f.Width, uint64(raw))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package bitx_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/abc/bitx"
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+)
+
+func TestGet(t *testing.T) {
+	b := []byte{0x12, 0x34}
+
+	v, err := bitx.Get(b, bitx.BIG_ENDIAN, 0, 16)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0x1234), v)
+	v, err = bitx.Get(b, bitx.LITTLE_ENDIAN, 0, 16)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0x3412), v)
+	v, err = bitx.Get(b, bitx.BIG_ENDIAN, 4, 8)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0x23), v)
+	v, err = bitx.Get(b, bitx.BIG_ENDIAN, 2, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0x5), v)
+
+	_, err = bitx.Get(b, bitx.BIG_ENDIAN, 10, 8)
+	assert.ErrorIs(t, err, bitx.ErrRange)
+	_, err = bitx.Get(b, bitx.BIG_ENDIAN, 0, 0)
+	assert.ErrorIs(t, err, bitx.ErrRange)
+}
+
+func TestGetSigned(t *testing.T) {
+	b := []byte{0xF0, 0x7F}
+
+	v, err := bitx.GetSigned(b, bitx.BIG_ENDIAN, 12, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-1), v)
+	v, err = bitx.GetSigned(b, bitx.BIG_ENDIAN, 0, 8)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(127), v)
+	v, err = bitx.GetSigned(b, bitx.BIG_ENDIAN, 0, 16)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-3969), v)
+
+	full := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFE}
+	v, err = bitx.GetSigned(full, bitx.BIG_ENDIAN, 0, 64)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-2), v)
+}
+
+func TestPut(t *testing.T) {
+	b := []byte{0xFF, 0x00}
+	assert.Nil(t, bitx.Put(b, bitx.BIG_ENDIAN, 4, 8, 0xA5))
+	assert.Equal(t, []byte{0xFA, 0x50}, b)
+
+	b = []byte{0x00, 0x00}
+	assert.Nil(t, bitx.Put(b, bitx.LITTLE_ENDIAN, 0, 12, 0xABC))
+	assert.Equal(t, []byte{0xBC, 0x0A}, b)
+	assert.ErrorIs(t, bitx.Put(b, bitx.BIG_ENDIAN, 0, 4, 0x10), bitx.ErrRange)
+
+	assert.Nil(t, bitx.PutSigned(b, bitx.BIG_ENDIAN, 0, 4, -2))
+	v, err := bitx.GetSigned(b, bitx.BIG_ENDIAN, 0, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-2), v)
+	assert.ErrorIs(t, bitx.PutSigned(b, bitx.BIG_ENDIAN, 0, 4, 8), bitx.ErrRange)
+	assert.ErrorIs(t, bitx.PutSigned(b, bitx.BIG_ENDIAN, 0, 4, -9), bitx.ErrRange)
+	assert.ErrorIs(t, bitx.PutSigned(b, bitx.BIG_ENDIAN, 0, 0, 0), bitx.ErrRange)
+}
+
+func TestRegisterMap(t *testing.T) {
+	m := &bitx.RegisterMap{
+		Size:  4,
+		Order: bitx.BIG_ENDIAN,
+		Fields: []bitx.Field{
+			{Name: "status.ready", Offset: 31, Width: 1},
+			{Name: "status.mode", Offset: 28, Width: 3},
+			{Name: "temp", Offset: 16, Width: 12, Signed: true, Scale: 0.1},
+			{Name: "count", Offset: 0, Width: 16},
+		},
+	}
+
+	b := []byte{0xBF, 0xF6, 0x01, 0x02}
+	d, err := m.Decode(b)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), dictx.Get(d, "status.ready", nil))
+	assert.Equal(t, uint64(3), dictx.Get(d, "status.mode", nil))
+	assert.InDelta(t, -1.0, dictx.Get(d, "temp", nil), 1e-9)
+	assert.Equal(t, uint64(0x0102), dictx.Get(d, "count", nil))
+
+	res, err := m.Encode(d)
+	assert.Nil(t, err)
+	assert.Equal(t, b, res)
+
+	res, err = m.Encode(dictx.Dict{"count": 7, "temp": 2.5})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00, 0x19, 0x00, 0x07}, res)
+
+	_, err = m.Encode(dictx.Dict{"count": 70000})
+	assert.ErrorIs(t, err, bitx.ErrRange)
+	_, err = m.Decode([]byte{0x00})
+	assert.ErrorIs(t, err, bitx.ErrRange)
+}
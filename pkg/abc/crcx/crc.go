@@ -0,0 +1,183 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package crcx
+
+import "hash"
+
+// Params defines a CRC algorithm in the Rocksoft model.
+type Params struct {
+	// Width defines the CRC size in bits, from 8 to 64.
+	Width int
+	// Poly defines the generator polynomial, in normal form.
+	Poly uint64
+	// Init defines the initial register value.
+	Init uint64
+	// RefIn defines reflected input bytes.
+	RefIn bool
+	// RefOut defines reflected output value.
+	RefOut bool
+	// XorOut defines the value applied to the final register.
+	XorOut uint64
+}
+
+// Table is a precomputed lookup table for a CRC algorithm.
+type Table struct {
+	params Params
+	mask   uint64
+	table  [256]uint64
+}
+
+// Predefined CRC algorithm tables.
+var (
+	// CRC8 defines CRC-8 with polynomial 0x07.
+	CRC8 = MakeTable(Params{Width: 8, Poly: 0x07})
+	// CRC16Modbus defines CRC-16/MODBUS.
+	CRC16Modbus = MakeTable(Params{Width: 16, Poly: 0x8005, Init: 0xFFFF,
+		RefIn: true, RefOut: true})
+	// CRC16CCITT defines CRC-16/CCITT-FALSE.
+	CRC16CCITT = MakeTable(Params{Width: 16, Poly: 0x1021, Init: 0xFFFF})
+	// CRC16X25 defines CRC-16/X-25, as used in HDLC framing.
+	CRC16X25 = MakeTable(Params{Width: 16, Poly: 0x1021, Init: 0xFFFF,
+		RefIn: true, RefOut: true, XorOut: 0xFFFF})
+	// CRC16XModem defines CRC-16/XMODEM.
+	CRC16XModem = MakeTable(Params{Width: 16, Poly: 0x1021})
+	// CRC32 defines CRC-32/ISO-HDLC, as used in ethernet and zip.
+	CRC32 = MakeTable(Params{Width: 32, Poly: 0x04C11DB7, Init: 0xFFFFFFFF,
+		RefIn: true, RefOut: true, XorOut: 0xFFFFFFFF})
+	// CRC32C defines CRC-32C (Castagnoli).
+	CRC32C = MakeTable(Params{Width: 32, Poly: 0x1EDC6F41, Init: 0xFFFFFFFF,
+		RefIn: true, RefOut: true, XorOut: 0xFFFFFFFF})
+)
+
+// reflect reverses the lower width bits of v.
+func reflect(v uint64, width int) uint64 {
+	var r uint64
+	for i := 0; i < width; i++ {
+		r = r<<1 | v&1
+		v >>= 1
+	}
+	return r
+}
+
+// MakeTable builds the lookup table for a CRC algorithm.
+// It panics if the width is not in range 8 to 64.
+func MakeTable(p Params) *Table {
+	if p.Width < 8 || p.Width > 64 {
+		panic("crcx: width must be in range 8 to 64")
+	}
+	t := &Table{params: p, mask: ^uint64(0) >> (64 - p.Width)}
+	if p.RefIn {
+		poly := reflect(p.Poly, p.Width)
+		for i := range t.table {
+			crc := uint64(i)
+			for j := 0; j < 8; j++ {
+				if crc&1 != 0 {
+					crc = crc>>1 ^ poly
+				} else {
+					crc >>= 1
+				}
+			}
+			t.table[i] = crc
+		}
+	} else {
+		top := uint64(1) << (p.Width - 1)
+		for i := range t.table {
+			crc := uint64(i) << (p.Width - 8)
+			for j := 0; j < 8; j++ {
+				if crc&top != 0 {
+					crc = crc<<1 ^ p.Poly
+				} else {
+					crc <<= 1
+				}
+			}
+			t.table[i] = crc & t.mask
+		}
+	}
+	return t
+}
+
+// init returns the initial register value.
+func (t *Table) init() uint64 {
+	if t.params.RefIn {
+		return reflect(t.params.Init, t.params.Width)
+	}
+	return t.params.Init & t.mask
+}
+
+// update returns the register value updated with data.
+func (t *Table) update(crc uint64, data []byte) uint64 {
+	if t.params.RefIn {
+		for _, b := range data {
+			crc = t.table[byte(crc)^b] ^ crc>>8
+		}
+	} else {
+		shift := t.params.Width - 8
+		for _, b := range data {
+			crc = (t.table[byte(crc>>shift)^b] ^ crc<<8) & t.mask
+		}
+	}
+	return crc
+}
+
+// final returns the CRC value from the register value.
+func (t *Table) final(crc uint64) uint64 {
+	if t.params.RefIn != t.params.RefOut {
+		crc = reflect(crc, t.params.Width)
+	}
+	return (crc ^ t.params.XorOut) & t.mask
+}
+
+// Checksum returns the CRC value of data.
+func Checksum(data []byte, t *Table) uint64 {
+	return t.final(t.update(t.init(), data))
+}
+
+// Digest is a streaming CRC calculation implementing hash.Hash64.
+type Digest struct {
+	table *Table
+	crc   uint64
+}
+
+var _ hash.Hash64 = (*Digest)(nil)
+
+// New creates a new streaming CRC calculation for a table.
+func New(t *Table) *Digest {
+	return &Digest{table: t, crc: t.init()}
+}
+
+// Write updates the CRC with data, it never returns an error.
+func (d *Digest) Write(p []byte) (int, error) {
+	d.crc = d.table.update(d.crc, p)
+	return len(p), nil
+}
+
+// Sum64 returns the current CRC value.
+func (d *Digest) Sum64() uint64 {
+	return d.table.final(d.crc)
+}
+
+// Sum appends the current CRC value to b in big-endian byte order.
+func (d *Digest) Sum(b []byte) []byte {
+	v := d.Sum64()
+	for i := d.Size() - 1; i >= 0; i-- {
+		b = append(b, byte(v>>(8*i)))
+	}
+	return b
+}
+
+// Reset resets the CRC to its initial value.
+func (d *Digest) Reset() {
+	d.crc = d.table.init()
+}
+
+// Size returns the CRC size in bytes.
+func (d *Digest) Size() int {
+	return (d.table.params.Width + 7) / 8
+}
+
+// BlockSize returns the CRC block size in bytes.
+func (d *Digest) BlockSize() int {
+	return 1
+}
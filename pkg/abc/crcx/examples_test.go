@@ -0,0 +1,26 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package crcx_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/exonlabs/go-utils/pkg/abc/crcx"
+)
+
+func ExampleChecksum() {
+	data := []byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A}
+	fmt.Printf("%04X\n", crcx.Checksum(data, crcx.CRC16Modbus))
+	// Output: CDC5
+}
+
+func ExampleNew() {
+	d := crcx.New(crcx.CRC32)
+	io.Copy(d, strings.NewReader("123456789"))
+	fmt.Printf("%x\n", d.Sum(nil))
+	// Output: cbf43926
+}
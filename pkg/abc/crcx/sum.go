@@ -0,0 +1,173 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package crcx
+
+import "hash"
+
+// LRC returns the longitudinal redundancy check of data, the two's
+// complement of the bytes sum, as used in Modbus ASCII.
+func LRC(data []byte) byte {
+	var sum byte
+	for _, b := range data {
+		sum += b
+	}
+	return -sum
+}
+
+// Fletcher16 returns the Fletcher-16 checksum of data.
+func Fletcher16(data []byte) uint16 {
+	d := NewFletcher16()
+	d.Write(data)
+	return d.Sum16()
+}
+
+// Fletcher32 returns the Fletcher-32 checksum of data, computed over
+// little-endian 16-bit words with zero padding of odd lengths.
+func Fletcher32(data []byte) uint32 {
+	d := NewFletcher32()
+	d.Write(data)
+	return d.Sum32()
+}
+
+// LRCDigest is a streaming LRC calculation implementing hash.Hash.
+type LRCDigest struct {
+	sum byte
+}
+
+var _ hash.Hash = (*LRCDigest)(nil)
+
+// NewLRC creates a new streaming LRC calculation.
+func NewLRC() *LRCDigest {
+	return &LRCDigest{}
+}
+
+// Write updates the LRC with data, it never returns an error.
+func (d *LRCDigest) Write(p []byte) (int, error) {
+	for _, b := range p {
+		d.sum += b
+	}
+	return len(p), nil
+}
+
+// Sum8 returns the current LRC value.
+func (d *LRCDigest) Sum8() byte { return -d.sum }
+
+// Sum appends the current LRC value to b.
+func (d *LRCDigest) Sum(b []byte) []byte { return append(b, d.Sum8()) }
+
+// Reset resets the LRC to its initial value.
+func (d *LRCDigest) Reset() { d.sum = 0 }
+
+// Size returns the LRC size in bytes.
+func (d *LRCDigest) Size() int { return 1 }
+
+// BlockSize returns the LRC block size in bytes.
+func (d *LRCDigest) BlockSize() int { return 1 }
+
+// Fletcher16Digest is a streaming Fletcher-16 calculation implementing
+// hash.Hash.
+type Fletcher16Digest struct {
+	s1, s2 uint32
+}
+
+var _ hash.Hash = (*Fletcher16Digest)(nil)
+
+// NewFletcher16 creates a new streaming Fletcher-16 calculation.
+func NewFletcher16() *Fletcher16Digest {
+	return &Fletcher16Digest{}
+}
+
+// Write updates the checksum with data, it never returns an error.
+func (d *Fletcher16Digest) Write(p []byte) (int, error) {
+	for _, b := range p {
+		d.s1 = (d.s1 + uint32(b)) % 255
+		d.s2 = (d.s2 + d.s1) % 255
+	}
+	return len(p), nil
+}
+
+// Sum16 returns the current checksum value.
+func (d *Fletcher16Digest) Sum16() uint16 {
+	return uint16(d.s2<<8 | d.s1)
+}
+
+// Sum appends the current checksum value to b in big-endian byte order.
+func (d *Fletcher16Digest) Sum(b []byte) []byte {
+	v := d.Sum16()
+	return append(b, byte(v>>8), byte(v))
+}
+
+// Reset resets the checksum to its initial value.
+func (d *Fletcher16Digest) Reset() { d.s1, d.s2 = 0, 0 }
+
+// Size returns the checksum size in bytes.
+func (d *Fletcher16Digest) Size() int { return 2 }
+
+// BlockSize returns the checksum block size in bytes.
+func (d *Fletcher16Digest) BlockSize() int { return 1 }
+
+// Fletcher32Digest is a streaming Fletcher-32 calculation implementing
+// hash.Hash, over little-endian 16-bit words.
+type Fletcher32Digest struct {
+	s1, s2  uint32
+	pending []byte
+}
+
+var _ hash.Hash = (*Fletcher32Digest)(nil)
+
+// NewFletcher32 creates a new streaming Fletcher-32 calculation.
+func NewFletcher32() *Fletcher32Digest {
+	return &Fletcher32Digest{}
+}
+
+func (d *Fletcher32Digest) add(w uint32) {
+	d.s1 = (d.s1 + w) % 65535
+	d.s2 = (d.s2 + d.s1) % 65535
+}
+
+// Write updates the checksum with data, it never returns an error.
+// A trailing odd byte is kept until the next write.
+func (d *Fletcher32Digest) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(d.pending) > 0 && len(p) > 0 {
+		d.add(uint32(d.pending[0]) | uint32(p[0])<<8)
+		d.pending, p = d.pending[:0], p[1:]
+	}
+	for ; len(p) >= 2; p = p[2:] {
+		d.add(uint32(p[0]) | uint32(p[1])<<8)
+	}
+	if len(p) == 1 {
+		d.pending = append(d.pending, p[0])
+	}
+	return n, nil
+}
+
+// Sum32 returns the current checksum value, with a pending odd byte
+// padded with zero.
+func (d *Fletcher32Digest) Sum32() uint32 {
+	s1, s2 := d.s1, d.s2
+	if len(d.pending) > 0 {
+		s1 = (s1 + uint32(d.pending[0])) % 65535
+		s2 = (s2 + s1) % 65535
+	}
+	return s2<<16 | s1
+}
+
+// Sum appends the current checksum value to b in big-endian byte order.
+func (d *Fletcher32Digest) Sum(b []byte) []byte {
+	v := d.Sum32()
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+// Reset resets the checksum to its initial value.
+func (d *Fletcher32Digest) Reset() {
+	d.s1, d.s2, d.pending = 0, 0, d.pending[:0]
+}
+
+// Size returns the checksum size in bytes.
+func (d *Fletcher32Digest) Size() int { return 4 }
+
+// BlockSize returns the checksum block size in bytes.
+func (d *Fletcher32Digest) BlockSize() int { return 2 }
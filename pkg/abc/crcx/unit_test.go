@@ -0,0 +1,84 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package crcx_test
+
+import (
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/abc/crcx"
+)
+
+var check = []byte("123456789")
+
+func TestChecksum(t *testing.T) {
+	tests := []struct {
+		name  string
+		table *crcx.Table
+		value uint64
+	}{
+		{"CRC8", crcx.CRC8, 0xF4},
+		{"CRC16Modbus", crcx.CRC16Modbus, 0x4B37},
+		{"CRC16CCITT", crcx.CRC16CCITT, 0x29B1},
+		{"CRC16X25", crcx.CRC16X25, 0x906E},
+		{"CRC16XModem", crcx.CRC16XModem, 0x31C3},
+		{"CRC32", crcx.CRC32, 0xCBF43926},
+		{"CRC32C", crcx.CRC32C, 0xE3069283},
+		{"CRC64ECMA", crcx.MakeTable(crcx.Params{Width: 64,
+			Poly: 0x42F0E1EBA9EA3693}), 0x6C40DF5F0B497347},
+		{"CRC16Kermit", crcx.MakeTable(crcx.Params{Width: 16,
+			Poly: 0x1021, RefIn: true, RefOut: true}), 0x2189},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.value, crcx.Checksum(check, tc.table))
+
+			d := crcx.New(tc.table)
+			d.Write(check[:4])
+			d.Write(check[4:])
+			assert.Equal(t, tc.value, d.Sum64())
+			d.Reset()
+			d.Write(check)
+			assert.Equal(t, tc.value, d.Sum64())
+		})
+	}
+
+	assert.Equal(t, uint64(crc32.ChecksumIEEE([]byte("hello"))),
+		crcx.Checksum([]byte("hello"), crcx.CRC32))
+	d := crcx.New(crcx.CRC16Modbus)
+	d.Write(check)
+	assert.Equal(t, []byte{0x4B, 0x37}, d.Sum(nil))
+	assert.Panics(t, func() { crcx.MakeTable(crcx.Params{Width: 4}) })
+}
+
+func TestLRC(t *testing.T) {
+	// modbus ASCII read holding registers request
+	data := []byte{0x11, 0x03, 0x00, 0x6B, 0x00, 0x03}
+	assert.Equal(t, byte(0x7E), crcx.LRC(data))
+
+	d := crcx.NewLRC()
+	d.Write(data[:2])
+	d.Write(data[2:])
+	assert.Equal(t, []byte{0x7E}, d.Sum(nil))
+}
+
+func TestFletcher(t *testing.T) {
+	assert.Equal(t, uint16(0xC8F0), crcx.Fletcher16([]byte("abcde")))
+	assert.Equal(t, uint16(0x2057), crcx.Fletcher16([]byte("abcdef")))
+	assert.Equal(t, uint32(0xF04FC729), crcx.Fletcher32([]byte("abcde")))
+	assert.Equal(t, uint32(0x56502D2A), crcx.Fletcher32([]byte("abcdef")))
+
+	d := crcx.NewFletcher32()
+	for _, c := range []byte("abcdef") {
+		d.Write([]byte{c})
+	}
+	assert.Equal(t, uint32(0x56502D2A), d.Sum32())
+	d.Reset()
+	d.Write([]byte("abc"))
+	d.Write([]byte("de"))
+	assert.Equal(t, []byte{0xF0, 0x4F, 0xC7, 0x29}, d.Sum(nil))
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package dictx
+
+import (
+	"reflect"
+	"time"
+)
+
+// Cloner is implemented by types providing their own deep copy,
+// used by Clone instead of the reflective copy.
+type Cloner interface {
+	Clone() any
+}
+
+// Clone creates a deep copy of a Dict.
+// It returns a new dictionary that is a copy of the original,
+// preserving the structure and values. Nested dictionaries, slices,
+// maps and pointers are copied, types implementing Cloner are copied
+// by their Clone method. Unexported struct fields are copied shallow
+// and cyclic references are not supported.
+func Clone(d Dict) (Dict, error) {
+	if d == nil {
+		return Dict{}, nil
+	}
+	return cloneDict(d), nil
+}
+
+func cloneDict(d Dict) Dict {
+	newDict := make(Dict, len(d))
+	for k, v := range d {
+		newDict[k] = cloneAny(v)
+	}
+	return newDict
+}
+
+func cloneAny(v any) any {
+	switch t := v.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, float32, float64,
+		time.Time, time.Duration:
+		return v
+	case Dict:
+		return cloneDict(t)
+	case []any:
+		if t == nil {
+			return t
+		}
+		res := make([]any, len(t))
+		for i, e := range t {
+			res[i] = cloneAny(e)
+		}
+		return res
+	case Cloner:
+		return t.Clone()
+	}
+	return cloneValue(reflect.ValueOf(v)).Interface()
+}
+
+func cloneValue(v reflect.Value) reflect.Value {
+	if v.CanInterface() {
+		if c, ok := v.Interface().(Cloner); ok {
+			if res := reflect.ValueOf(c.Clone()); res.IsValid() &&
+				res.Type().AssignableTo(v.Type()) {
+				return res
+			}
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.New(v.Type()).Elem()
+		res.Set(cloneValue(v.Elem()))
+		return res
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.New(v.Type().Elem())
+		res.Elem().Set(cloneValue(v.Elem()))
+		return res
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(cloneValue(v.Index(i)))
+		}
+		return res
+	case reflect.Array:
+		res := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(cloneValue(v.Index(i)))
+		}
+		return res
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			res.SetMapIndex(iter.Key(), cloneValue(iter.Value()))
+		}
+		return res
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			return v
+		}
+		res := reflect.New(v.Type()).Elem()
+		res.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if res.Field(i).CanSet() {
+				res.Field(i).Set(cloneValue(v.Field(i)))
+			}
+		}
+		return res
+	}
+	return v
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package dictx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ToJSON returns the JSON encoding of the dictionary with keys sorted at
+// all nesting levels, indented with the given number of spaces or compact
+// if indent is zero. HTML characters are not escaped.
+func ToJSON(d Dict, indent int) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if indent > 0 {
+		enc.SetIndent("", fmt.Sprintf("%*s", indent, ""))
+	}
+	if err := enc.Encode(d); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// FromJSON parses a JSON object into a dictionary, with nested objects
+// decoded as Dict. A null or empty input returns an empty dictionary.
+func FromJSON(b []byte) (Dict, error) {
+	d := Dict{}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return d, nil
+	}
+	if err := json.Unmarshal(b, &d); err != nil {
+		return nil, err
+	}
+	if d == nil {
+		d = Dict{}
+	}
+	return d, nil
+}
+
+// ToYAML returns the YAML encoding of the dictionary with keys sorted at
+// all nesting levels, indented with the given number of spaces or 2
+// spaces if indent is zero.
+func ToYAML(d Dict, indent int) ([]byte, error) {
+	if indent <= 0 {
+		indent = 2
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent)
+	if err := enc.Encode(d); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// FromYAML parses a YAML mapping into a dictionary, with nested mappings
+// decoded as Dict, converting non-string keys to strings. An empty input
+// returns an empty dictionary.
+func FromYAML(b []byte) (Dict, error) {
+	d := Dict{}
+	if err := yaml.Unmarshal(b, &d); err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return Dict{}, nil
+	}
+	return normalize(d).(Dict), nil
+}
+
+// normalize converts nested generic maps into Dict recursively.
+func normalize(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, nv := range val {
+			val[k] = normalize(nv)
+		}
+		return val
+	case map[any]any:
+		d := make(Dict, len(val))
+		for k, nv := range val {
+			d[fmt.Sprint(k)] = normalize(nv)
+		}
+		return d
+	case []any:
+		for i, nv := range val {
+			val[i] = normalize(nv)
+		}
+		return val
+	}
+	return v
+}
@@ -6,6 +6,7 @@ package dictx_test
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/exonlabs/go-utils/pkg/abc/dictx"
 )
@@ -175,3 +176,28 @@ func ExampleDelete() {
 	// Output:
 	// false
 }
+
+func ExampleDecode() {
+	type Server struct {
+		Host    string        `dictx:"host"`
+		Port    int           `dictx:"port"`
+		Timeout time.Duration `dictx:"timeout"`
+	}
+
+	d := dictx.Dict{
+		"host":    "localhost",
+		"port":    8080,
+		"timeout": "30s",
+	}
+
+	// default values are kept for missing keys
+	srv := Server{Port: 80}
+	if err := dictx.Decode(d, &srv); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Println(srv.Host, srv.Port, srv.Timeout)
+
+	// Output:
+	// localhost 8080 30s
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package dictx
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/exonlabs/go-utils/pkg/abc/parsex"
+)
+
+var (
+	// ErrNotFound is returned when a key does not exist.
+	ErrNotFound = errors.New("key not found")
+	// ErrWrongType is returned when a value has a wrong type or can not
+	// be converted to the requested type.
+	ErrWrongType = errors.New("wrong value type")
+)
+
+// Strict enables strict conversions in the GetE getters, where values
+// must have the exact requested kind: numeric strings are not parsed,
+// non-string values are not formatted as strings, and floats with
+// fraction parts are not truncated to integers.
+var Strict = false
+
+// GetE retrieves a value from the dictionary by key, returning
+// ErrNotFound if the key does not exist.
+func GetE(d Dict, key string) (any, error) {
+	if val, ok := lookupPath(d, key); ok {
+		return val, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+}
+
+// GetStringE retrieves a string value from the dictionary by key.
+// Numeric and boolean values are formatted as strings unless Strict.
+func GetStringE(d Dict, key string) (string, error) {
+	val, err := GetE(d, key)
+	if err != nil {
+		return "", err
+	}
+	if v, ok := val.(string); ok {
+		return v, nil
+	}
+	if !Strict {
+		switch reflect.ValueOf(val).Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16,
+			reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+			reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return fmt.Sprintf("%v", val), nil
+		}
+	}
+	return "", typeError(key, val, "string")
+}
+
+// GetBoolE retrieves a boolean value from the dictionary by key.
+// Boolean strings such as "yes" or "off" are parsed unless Strict.
+func GetBoolE(d Dict, key string) (bool, error) {
+	val, err := GetE(d, key)
+	if err != nil {
+		return false, err
+	}
+	switch v := val.(type) {
+	case bool:
+		return v, nil
+	case string:
+		if !Strict {
+			if b, err := parsex.ParseBool(v); err == nil {
+				return b, nil
+			}
+		}
+	}
+	return false, typeError(key, val, "bool")
+}
+
+// GetFloatE retrieves a float value from the dictionary by key.
+// Numeric strings are parsed unless Strict.
+func GetFloatE(d Dict, key string) (float64, error) {
+	val, err := GetE(d, key)
+	if err != nil {
+		return 0, err
+	}
+	if f, ok := toNumber(val); ok {
+		return f, nil
+	}
+	return 0, typeError(key, val, "float")
+}
+
+// GetIntE retrieves an integer value from the dictionary by key.
+// Values out of int range are rejected, and floats with fraction
+// parts are truncated unless Strict.
+func GetIntE(d Dict, key string) (int, error) {
+	val, err := GetE(d, key)
+	if err != nil {
+		return 0, err
+	}
+	if v, ok := val.(int); ok {
+		return v, nil
+	}
+	f, ok := toNumber(val)
+	if !ok || (Strict && f != math.Trunc(f)) {
+		return 0, typeError(key, val, "int")
+	}
+	if u, ok := val.(uint64); ok && u > math.MaxInt {
+		return 0, rangeError(key, val, "int")
+	}
+	if f < math.MinInt || f >= math.MaxInt {
+		return 0, rangeError(key, val, "int")
+	}
+	if i, ok := toInt64(val); ok {
+		return int(i), nil
+	}
+	return int(f), nil
+}
+
+// GetUintE retrieves an unsigned integer value from the dictionary by
+// key. Negative values and values out of range are rejected, and floats
+// with fraction parts are truncated unless Strict.
+func GetUintE(d Dict, key string) (uint, error) {
+	val, err := GetE(d, key)
+	if err != nil {
+		return 0, err
+	}
+	if v, ok := val.(uint); ok {
+		return v, nil
+	}
+	f, ok := toNumber(val)
+	if !ok || (Strict && f != math.Trunc(f)) {
+		return 0, typeError(key, val, "uint")
+	}
+	if f < 0 || f >= math.MaxUint {
+		return 0, rangeError(key, val, "uint")
+	}
+	if u, ok := val.(uint64); ok {
+		return uint(u), nil
+	}
+	if i, ok := toInt64(val); ok {
+		return uint(i), nil
+	}
+	return uint(f), nil
+}
+
+// toNumber converts numeric values, and numeric strings unless Strict.
+func toNumber(val any) (float64, bool) {
+	if s, ok := val.(string); ok {
+		if Strict {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil
+	}
+	return toFloat(val)
+}
+
+// toInt64 returns exact integer values, avoiding float precision loss.
+func toInt64(val any) (int64, bool) {
+	if s, ok := val.(string); ok && !Strict {
+		i, err := strconv.ParseInt(s, 10, 64)
+		return i, err == nil
+	}
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return int64(rv.Uint()), true
+	}
+	return 0, false
+}
+
+func typeError(key string, val any, typ string) error {
+	return fmt.Errorf("%w: %s: %T value %v is not %s",
+		ErrWrongType, key, val, val, typ)
+}
+
+func rangeError(key string, val any, typ string) error {
+	return fmt.Errorf("%w: %s: value %v out of %s range",
+		ErrWrongType, key, val, typ)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package dictx
+
+import "reflect"
+
+// MergeStrategy resolves a merge conflict, where both dictionaries hold
+// a value for the same key and the values are not both dictionaries.
+// The key is the full nested key, and the returned value is stored.
+type MergeStrategy func(key string, current, update any) any
+
+// MergeOverride is the strategy replacing the current value with the
+// update value, the same as Merge.
+func MergeOverride(_ string, _, update any) any {
+	return update
+}
+
+// MergeKeepExisting is the strategy keeping the current value.
+func MergeKeepExisting(_ string, current, _ any) any {
+	return current
+}
+
+// MergeAppendSlices is the strategy appending the update slice to the
+// current slice if both are slices of the same type, otherwise the
+// current value is replaced.
+func MergeAppendSlices(_ string, current, update any) any {
+	cv, uv := reflect.ValueOf(current), reflect.ValueOf(update)
+	if cv.Kind() != reflect.Slice || uv.Kind() != reflect.Slice ||
+		cv.Type() != uv.Type() {
+		return update
+	}
+	res := reflect.MakeSlice(cv.Type(), 0, cv.Len()+uv.Len())
+	res = reflect.AppendSlice(res, cv)
+	return reflect.AppendSlice(res, uv).Interface()
+}
+
+// MergeWith updates a source dictionary recursively with an update
+// dictionary, like Merge, using the strategy to resolve conflicting keys.
+// Nested dictionaries present in both are always merged recursively.
+// A nil strategy uses MergeOverride.
+func MergeWith(src, updt Dict, strategy MergeStrategy) {
+	if strategy == nil {
+		strategy = MergeOverride
+	}
+	mergeWith(src, updt, strategy, "")
+}
+
+func mergeWith(src, updt Dict, strategy MergeStrategy, path string) {
+	for k, v := range updt {
+		key := k
+		if path != "" {
+			key = path + Separator + k
+		}
+
+		current, exist := src[k]
+		if !exist {
+			src[k] = v
+			continue
+		}
+		if vDict, ok := v.(Dict); ok {
+			if srcDict, ok := current.(Dict); ok {
+				mergeWith(srcDict, vDict, strategy, key)
+				continue
+			}
+		}
+		src[k] = strategy(key, current, v)
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package dictx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ODict is an ordered dictionary preserving keys insertion order,
+// with nested keys using the separator stored as nested *ODict values.
+// The zero value is not usable, use NewODict to create.
+type ODict struct {
+	keys   []string
+	values map[string]any
+}
+
+// NewODict creates a new empty ordered dictionary.
+func NewODict() *ODict {
+	return &ODict{values: map[string]any{}}
+}
+
+// Len returns the number of top-level keys.
+func (o *ODict) Len() int {
+	return len(o.keys)
+}
+
+// Keys returns the top-level keys in insertion order.
+func (o *ODict) Keys() []string {
+	return append([]string(nil), o.keys...)
+}
+
+// IsExist checks if a key exists in the dictionary.
+// It supports nested keys using the separator.
+func (o *ODict) IsExist(key string) bool {
+	_, ok := o.lookup(key)
+	return ok
+}
+
+// Get retrieves a value from the dictionary by key.
+// If the key is not found, the defaultValue is returned.
+func (o *ODict) Get(key string, defaultValue any) any {
+	if val, ok := o.lookup(key); ok {
+		return val
+	}
+	return defaultValue
+}
+
+func (o *ODict) lookup(key string) (any, bool) {
+	if key == "" {
+		return nil, false
+	}
+	keys := strings.Split(key, Separator)
+	current := o
+	for i, k := range keys {
+		val, ok := current.values[k]
+		if !ok {
+			return nil, false
+		}
+		if i == len(keys)-1 {
+			return val, true
+		}
+		if current, ok = val.(*ODict); !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// Set adds a new value in the dictionary by key, appended after
+// existing keys. If the key already exists, its value is overwritten
+// keeping its position.
+func (o *ODict) Set(key string, newValue any) {
+	if key == "" {
+		return
+	}
+	keys := strings.Split(key, Separator)
+	current := o
+	for i, k := range keys {
+		if i == len(keys)-1 {
+			current.put(k, newValue)
+			return
+		}
+		// If not an ODict, create new nested ODict
+		if nested, ok := current.values[k].(*ODict); ok {
+			current = nested
+		} else {
+			nested = NewODict()
+			current.put(k, nested)
+			current = nested
+		}
+	}
+}
+
+func (o *ODict) put(k string, v any) {
+	if _, ok := o.values[k]; !ok {
+		o.keys = append(o.keys, k)
+	}
+	o.values[k] = v
+}
+
+// Delete removes a key from the dictionary if it exists.
+// It supports nested keys using the separator.
+func (o *ODict) Delete(key string) {
+	if key == "" {
+		return
+	}
+	keys := strings.Split(key, Separator)
+	current := o
+	for i, k := range keys {
+		if _, ok := current.values[k]; !ok {
+			return
+		}
+		if i == len(keys)-1 {
+			delete(current.values, k)
+			for n, ck := range current.keys {
+				if ck == k {
+					current.keys = append(current.keys[:n], current.keys[n+1:]...)
+					break
+				}
+			}
+			return
+		}
+		nested, ok := current.values[k].(*ODict)
+		if !ok {
+			return
+		}
+		current = nested
+	}
+}
+
+// ToDict converts the ordered dictionary into a Dict, with nested
+// ordered dictionaries converted recursively.
+func (o *ODict) ToDict() Dict {
+	d := make(Dict, len(o.keys))
+	for _, k := range o.keys {
+		if nested, ok := o.values[k].(*ODict); ok {
+			d[k] = nested.ToDict()
+		} else {
+			d[k] = o.values[k]
+		}
+	}
+	return d
+}
+
+// MarshalJSON encodes the dictionary as a JSON object in key order.
+func (o *ODict) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+		vb, err := json.Marshal(o.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a JSON object keeping its keys order, with
+// nested objects decoded as *ODict, including objects inside arrays.
+func (o *ODict) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != json.Delim('{') {
+		return fmt.Errorf("invalid JSON object")
+	}
+	res, err := decodeODict(dec)
+	if err != nil {
+		return err
+	}
+	*o = *res
+	return nil
+}
+
+func decodeODict(dec *json.Decoder) (*ODict, error) {
+	o := NewODict()
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		k, _ := tok.(string)
+		v, err := decodeOValue(dec)
+		if err != nil {
+			return nil, err
+		}
+		o.put(k, v)
+	}
+	// consume closing delimiter
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func decodeOValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			return decodeODict(dec)
+		}
+		list := []any{}
+		for dec.More() {
+			v, err := decodeOValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return list, nil
+	case json.Number:
+		return t.Float64()
+	}
+	return tok, nil
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package dictx
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Wildcard matches all values of a dictionary or slice in query keys.
+const Wildcard = "*"
+
+// Query retrieves all values matching a nested key, which may contain
+// slice indexes such as "a.b[1].2" and wildcards such as "devices.*.addr".
+// Dictionary keys matched by wildcards are visited in sorted order.
+// An empty list is returned if nothing matches.
+func Query(d Dict, key string) []any {
+	res := []any{}
+	if len(d) == 0 || key == "" {
+		return res
+	}
+	walkPath(d, strings.Split(key, Separator), true, func(v any) bool {
+		res = append(res, v)
+		return true
+	})
+	return res
+}
+
+// GetPointer retrieves a value from the dictionary by RFC 6901 JSON
+// Pointer, such as "/a/b/0". If the value is not found or the pointer
+// is invalid, the defaultValue is returned.
+func GetPointer(d Dict, pointer string, defaultValue any) any {
+	if pointer == "" {
+		return d
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return defaultValue
+	}
+	var cur any = d
+	for _, tok := range strings.Split(pointer[1:], "/") {
+		tok = strings.ReplaceAll(tok, "~1", "/")
+		tok = strings.ReplaceAll(tok, "~0", "~")
+		v, ok := stepPath(cur, tok)
+		if !ok {
+			return defaultValue
+		}
+		cur = v
+	}
+	return cur
+}
+
+// lookupPath resolves a nested key with slice indexes to a single value.
+func lookupPath(d Dict, key string) (any, bool) {
+	if len(d) == 0 || key == "" {
+		return nil, false
+	}
+	var res any
+	found := false
+	walkPath(d, strings.Split(key, Separator), false, func(v any) bool {
+		res, found = v, true
+		return false
+	})
+	return res, found
+}
+
+// walkPath calls fn for each value matching the key segments, until fn
+// returns false. Segments are matched literally first, then as index
+// expressions such as "name[1][2]".
+func walkPath(cur any, segs []string, wildcard bool, fn func(any) bool) bool {
+	if len(segs) == 0 {
+		return fn(cur)
+	}
+	seg, rest := segs[0], segs[1:]
+	if wildcard && seg == Wildcard {
+		for _, v := range children(cur) {
+			if !walkPath(v, rest, wildcard, fn) {
+				return false
+			}
+		}
+		return true
+	}
+	if v, ok := stepPath(cur, seg); ok {
+		return walkPath(v, rest, wildcard, fn)
+	}
+	if name, idx, ok := splitIndex(seg); ok {
+		if name != "" {
+			idx = append([]string{name}, idx...)
+		}
+		return walkPath(cur, append(idx, rest...), wildcard, fn)
+	}
+	return true
+}
+
+// stepPath gets a dictionary value by key or a slice element by index.
+func stepPath(cur any, seg string) (any, bool) {
+	if d, ok := cur.(Dict); ok {
+		v, ok := d[seg]
+		return v, ok
+	}
+	rv := reflect.ValueOf(cur)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(seg)
+		if err != nil || i < 0 || i >= rv.Len() || seg != strconv.Itoa(i) {
+			return nil, false
+		}
+		return rv.Index(i).Interface(), true
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		v := rv.MapIndex(reflect.ValueOf(seg).Convert(rv.Type().Key()))
+		if !v.IsValid() {
+			return nil, false
+		}
+		return v.Interface(), true
+	}
+	return nil, false
+}
+
+// children returns the values of a dictionary in sorted key order
+// or the elements of a slice.
+func children(cur any) []any {
+	rv := reflect.ValueOf(cur)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		res := make([]any, rv.Len())
+		for i := range res {
+			res[i] = rv.Index(i).Interface()
+		}
+		return res
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+		res := make([]any, len(keys))
+		for i, k := range keys {
+			res[i] = rv.MapIndex(k).Interface()
+		}
+		return res
+	}
+	return nil
+}
+
+// splitIndex splits an index expression "name[1][2]" into its name
+// and index parts.
+func splitIndex(seg string) (string, []string, bool) {
+	n := strings.IndexByte(seg, '[')
+	if n < 0 || !strings.HasSuffix(seg, "]") {
+		return "", nil, false
+	}
+	name := seg[:n]
+	idx := strings.Split(seg[n+1:len(seg)-1], "][")
+	for _, s := range idx {
+		if s == "" || strings.ContainsAny(s, "[]") {
+			return "", nil, false
+		}
+	}
+	return name, idx, true
+}
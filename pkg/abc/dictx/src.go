@@ -6,6 +6,7 @@ package dictx
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -15,35 +16,23 @@ const Separator = "."
 // Dict type representation as a map with string keys and any values
 type Dict = map[string]any
 
-// Clone creates a deep copy of a Dict.
-// It returns a new dictionary that is a copy of the original,
-// preserving the structure and values.
-func Clone(d Dict) (Dict, error) {
-	newDict := make(Dict, len(d))
-	for k, v := range d {
-		if nestedDict, ok := v.(Dict); ok {
-			clonedNestedDict, err := Clone(nestedDict)
-			if err != nil {
-				return nil, err
-			}
-			newDict[k] = clonedNestedDict
-		} else {
-			newDict[k] = v
+// String returns string representation of keys and values,
+// with keys in sorted order.
+func String(d Dict) string {
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		if len(k) > 0 {
+			keys = append(keys, k)
 		}
 	}
-	return newDict, nil
-}
+	sort.Strings(keys)
 
-// String returns string representation of keys and values.
-func String(d Dict) string {
 	s := ""
-	for k, v := range d {
-		if len(k) > 0 {
-			if nestedDict, ok := v.(Dict); ok {
-				s += fmt.Sprintf("%s: %s, ", k, String(nestedDict))
-			} else {
-				s += fmt.Sprintf("%s: %v, ", k, v)
-			}
+	for _, k := range keys {
+		if nestedDict, ok := d[k].(Dict); ok {
+			s += fmt.Sprintf("%s: %s, ", k, String(nestedDict))
+		} else {
+			s += fmt.Sprintf("%s: %v, ", k, d[k])
 		}
 	}
 	if len(s) > 0 {
@@ -81,27 +70,12 @@ func Keys(d Dict) []string {
 }
 
 // IsExist checks if a key exists in the dictionary.
-// It supports nested keys using the separator.
+// It supports nested keys using the separator and slice indexes
+// such as "a.b[1]" or "a.b.1".
 // Returns true if the key exists, false otherwise.
 func IsExist(d Dict, key string) bool {
-	if len(d) == 0 || key == "" {
-		return false
-	}
-	keys := strings.Split(key, Separator)
-	current := d
-	for _, k := range keys {
-		val, ok := current[k]
-		if !ok {
-			return false
-		}
-		if nestedDict, ok := val.(Dict); ok {
-			current = nestedDict
-		} else if k != keys[len(keys)-1] {
-			// We reached a non-Dict value before the last key
-			return false
-		}
-	}
-	return true
+	_, ok := lookupPath(d, key)
+	return ok
 }
 
 // Fetch retrieves a value from the dictionary by key with type casting conversion.
@@ -115,25 +89,12 @@ func Fetch[T any](d Dict, key string, defaultValue T) T {
 }
 
 // Get retrieves a value from the dictionary by key.
+// It supports nested keys using the separator and slice indexes
+// such as "a.b[1]" or "a.b.1".
 // If the key is not found, the defaultValue is returned.
 func Get(d Dict, key string, defaultValue any) any {
-	if len(d) == 0 || key == "" {
-		return defaultValue
-	}
-	keys := strings.Split(key, Separator)
-	current := d
-	for i, k := range keys {
-		val, ok := current[k]
-		if !ok {
-			return defaultValue
-		}
-		if i == len(keys)-1 {
-			return val
-		} else if nestedDict, ok := val.(Dict); ok {
-			current = nestedDict
-		} else {
-			return defaultValue
-		}
+	if val, ok := lookupPath(d, key); ok {
+		return val
 	}
 	return defaultValue
 }
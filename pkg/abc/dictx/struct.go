@@ -0,0 +1,467 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package dictx
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/parsex"
+)
+
+// TAG_NAME is the struct field tag used for binding dictionary keys.
+//
+//	Field string `dictx:"name,omitempty"`
+//
+// Fields without tag use the field name as key, and fields tagged
+// with "-" are skipped. Embedded structs are flattened.
+const TAG_NAME = "dictx"
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// structField holds the binding info of a struct field.
+type structField struct {
+	key       string
+	index     []int
+	omitEmpty bool
+}
+
+// structFields returns the binding info of the exported fields of a
+// struct type, including the fields of embedded structs.
+func structFields(t reflect.Type) []structField {
+	fields := []structField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get(TAG_NAME)
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for _, sf := range structFields(ft) {
+				sf.index = append([]int{i}, sf.index...)
+				fields = append(fields, sf)
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, structField{
+			key:       name,
+			index:     []int{i},
+			omitEmpty: opts == "omitempty",
+		})
+	}
+	return fields
+}
+
+// Decode binds the dictionary values to the struct pointed to by v.
+// Keys are matched to fields by tag or name, case-insensitively if no
+// exact match exists. Fields without matching keys are left unchanged,
+// so v can be initialized with the default values.
+//
+// Values are converted to the field types, including nested structs,
+// maps, slices and pointers. Numbers and bools are parsed from strings,
+// time.Duration is parsed from strings like "1m30s" or from numbers as
+// seconds, and types implementing encoding.TextUnmarshaler are parsed
+// from strings.
+func Decode(d Dict, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() ||
+		rv.Elem().Kind() != reflect.Struct {
+		return errors.New("decode target must be a non-nil pointer to struct")
+	}
+	return decodeStruct(d, rv.Elem(), "")
+}
+
+// decodeStruct binds dictionary values to the struct fields.
+func decodeStruct(d Dict, rv reflect.Value, path string) error {
+	for _, f := range structFields(rv.Type()) {
+		val, ok := d[f.key]
+		if !ok {
+			for k, kv := range d {
+				if strings.EqualFold(k, f.key) {
+					val, ok = kv, true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+		fv, err := fieldByIndex(rv, f.index)
+		if err != nil {
+			return err
+		}
+		if err := decodeValue(val, fv, joinPath(path, f.key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldByIndex returns the nested field, allocating nil embedded pointers.
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				if !rv.CanSet() {
+					return reflect.Value{}, fmt.Errorf(
+						"cannot set embedded pointer to unexported struct %s",
+						rv.Type().Elem())
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, nil
+}
+
+// joinPath joins nested keys for error messages.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + Separator + key
+}
+
+// decodeValue converts and sets the value into the target.
+func decodeValue(val any, rv reflect.Value, path string) error {
+	convErr := func() error {
+		return fmt.Errorf("cannot decode %T into %s for key '%s'",
+			val, rv.Type(), path)
+	}
+
+	// nil values reset the target
+	if val == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decodeValue(val, rv.Elem(), path)
+	}
+
+	if rv.Type() == durationType {
+		switch v := val.(type) {
+		case string:
+			dur, err := parsex.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid duration for key '%s' - %v", path, err)
+			}
+			rv.SetInt(int64(dur))
+			return nil
+		case time.Duration:
+			rv.SetInt(int64(v))
+			return nil
+		}
+		f, ok := toFloat(val)
+		if !ok {
+			return convErr()
+		}
+		rv.SetInt(int64(f * float64(time.Second)))
+		return nil
+	}
+
+	if s, ok := val.(string); ok && reflect.PointerTo(rv.Type()).Implements(textUnmarshalerType) {
+		if err := rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("invalid value for key '%s' - %v", path, err)
+		}
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Interface:
+		if !reflect.TypeOf(val).AssignableTo(rv.Type()) {
+			return convErr()
+		}
+		rv.Set(reflect.ValueOf(val))
+
+	case reflect.String:
+		switch v := val.(type) {
+		case string:
+			rv.SetString(v)
+		case bool, int, int8, int16, int32, int64, uint, uint8, uint16,
+			uint32, uint64, float32, float64:
+			rv.SetString(fmt.Sprint(v))
+		default:
+			return convErr()
+		}
+
+	case reflect.Bool:
+		switch v := val.(type) {
+		case bool:
+			rv.SetBool(v)
+		case string:
+			b, err := parsex.ParseBool(v)
+			if err != nil {
+				return convErr()
+			}
+			rv.SetBool(b)
+		default:
+			return convErr()
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if s, ok := val.(string); ok {
+			i, err := strconv.ParseInt(s, 0, 64)
+			if err != nil {
+				return convErr()
+			}
+			n = i
+		} else if f, ok := toFloat(val); ok && f == math.Trunc(f) &&
+			f >= math.MinInt64 && f < math.MaxInt64 {
+			n = int64(f)
+			if i, ok := val.(int64); ok {
+				n = i
+			}
+		} else {
+			return convErr()
+		}
+		if rv.OverflowInt(n) {
+			return fmt.Errorf("value %d overflows %s for key '%s'",
+				n, rv.Type(), path)
+		}
+		rv.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		if s, ok := val.(string); ok {
+			u, err := strconv.ParseUint(s, 0, 64)
+			if err != nil {
+				return convErr()
+			}
+			n = u
+		} else if f, ok := toFloat(val); ok && f == math.Trunc(f) &&
+			f >= 0 && f < math.MaxUint64 {
+			n = uint64(f)
+			if u, ok := val.(uint64); ok {
+				n = u
+			}
+		} else {
+			return convErr()
+		}
+		if rv.OverflowUint(n) {
+			return fmt.Errorf("value %d overflows %s for key '%s'",
+				n, rv.Type(), path)
+		}
+		rv.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if s, ok := val.(string); ok {
+			pf, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return convErr()
+			}
+			f = pf
+		} else if nf, ok := toFloat(val); ok {
+			f = nf
+		} else {
+			return convErr()
+		}
+		if rv.OverflowFloat(f) {
+			return fmt.Errorf("value %v overflows %s for key '%s'",
+				f, rv.Type(), path)
+		}
+		rv.SetFloat(f)
+
+	case reflect.Struct:
+		d, ok := val.(Dict)
+		if !ok {
+			return convErr()
+		}
+		return decodeStruct(d, rv, path)
+
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map key type %s for key '%s'",
+				rv.Type().Key(), path)
+		}
+		d, ok := val.(Dict)
+		if !ok {
+			return convErr()
+		}
+		m := reflect.MakeMapWithSize(rv.Type(), len(d))
+		for k, v := range d {
+			ev := reflect.New(rv.Type().Elem()).Elem()
+			if err := decodeValue(v, ev, joinPath(path, k)); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), ev)
+		}
+		rv.Set(m)
+
+	case reflect.Slice, reflect.Array:
+		sv := reflect.ValueOf(val)
+		if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+			return convErr()
+		}
+		n := sv.Len()
+		if rv.Kind() == reflect.Array {
+			if n > rv.Len() {
+				return fmt.Errorf("too many values for %s for key '%s'",
+					rv.Type(), path)
+			}
+		} else {
+			rv.Set(reflect.MakeSlice(rv.Type(), n, n))
+		}
+		for i := 0; i < n; i++ {
+			err := decodeValue(sv.Index(i).Interface(), rv.Index(i),
+				fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("unsupported type %s for key '%s'", rv.Type(), path)
+	}
+	return nil
+}
+
+// toFloat converts numeric values to float64.
+func toFloat(val any) (float64, bool) {
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// Encode converts the struct, or pointer to struct, into a dictionary
+// using the same key binding as Decode. Nested structs and maps are
+// converted to Dict and slices to []any, time.Duration values are
+// encoded as strings like "1m30s", and types implementing
+// encoding.TextMarshaler are encoded as strings.
+func Encode(v any) (Dict, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("encode source must be a struct or pointer to struct")
+	}
+	val, err := encodeValue(rv)
+	if err != nil {
+		return nil, err
+	}
+	return val.(Dict), nil
+}
+
+// encodeStruct converts the struct fields into a dictionary.
+func encodeStruct(rv reflect.Value) (Dict, error) {
+	d := Dict{}
+	for _, f := range structFields(rv.Type()) {
+		fv, err := rv.FieldByIndexErr(f.index)
+		if err != nil {
+			// nil embedded struct pointer
+			continue
+		}
+		if f.omitEmpty && fv.IsZero() {
+			continue
+		}
+		val, err := encodeValue(fv)
+		if err != nil {
+			return nil, err
+		}
+		d[f.key] = val
+	}
+	return d, nil
+}
+
+// encodeValue converts the value into its dictionary representation.
+func encodeValue(rv reflect.Value) (any, error) {
+	if rv.Type() == durationType {
+		return time.Duration(rv.Int()).String(), nil
+	}
+	if rv.Type().Implements(textMarshalerType) &&
+		(rv.Kind() != reflect.Pointer || !rv.IsNil()) {
+		b, err := rv.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return encodeValue(rv.Elem())
+	case reflect.Struct:
+		return encodeStruct(rv)
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", rv.Type().Key())
+		}
+		d := make(Dict, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			val, err := encodeValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			d[iter.Key().String()] = val
+		}
+		return d, nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, nil
+		}
+		// keep byte slices as is
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Interface(), nil
+		}
+		list := make([]any, rv.Len())
+		for i := range list {
+			val, err := encodeValue(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list[i] = val
+		}
+		return list, nil
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil, fmt.Errorf("unsupported type %s", rv.Type())
+	}
+	return rv.Interface(), nil
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package dictx
+
+import "sync"
+
+// SyncDict is a dictionary safe for concurrent use, with the same
+// nested key API as Dict. Nested Dict values returned by Get are
+// copies, so changes must be done through Set, Merge or Update.
+type SyncDict struct {
+	mu   sync.RWMutex
+	data Dict
+}
+
+// NewSyncDict creates a new concurrency-safe dictionary holding
+// a copy of the initial values.
+func NewSyncDict(d Dict) *SyncDict {
+	data, _ := Clone(d)
+	if data == nil {
+		data = Dict{}
+	}
+	return &SyncDict{data: data}
+}
+
+// Get retrieves a value by key. If the key is not found,
+// the defaultValue is returned.
+func (s *SyncDict) Get(key string, defaultValue any) any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val := Get(s.data, key, defaultValue)
+	if nestedDict, ok := val.(Dict); ok {
+		val, _ = Clone(nestedDict)
+	}
+	return val
+}
+
+// IsExist checks if a key exists in the dictionary.
+func (s *SyncDict) IsExist(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return IsExist(s.data, key)
+}
+
+// Keys returns a list of all nested keys in the dictionary.
+func (s *SyncDict) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return Keys(s.data)
+}
+
+// KeysN returns a list of keys up to N levels nested.
+func (s *SyncDict) KeysN(n int) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return KeysN(s.data, n)
+}
+
+// Set adds or updates a value in the dictionary by key.
+func (s *SyncDict) Set(key string, newValue any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	Set(s.data, key, newValue)
+}
+
+// Merge updates the dictionary recursively with an update dictionary.
+func (s *SyncDict) Merge(updt Dict) {
+	updt, _ = Clone(updt)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	Merge(s.data, updt)
+}
+
+// Delete removes a key from the dictionary if it exists.
+func (s *SyncDict) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	Delete(s.data, key)
+}
+
+// Update runs fn with exclusive access to the underlying dictionary,
+// for compound changes done atomically. The dictionary must not be
+// retained after fn returns.
+func (s *SyncDict) Update(fn func(d Dict)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.data)
+}
+
+// Snapshot returns a copy of the dictionary contents.
+func (s *SyncDict) Snapshot() Dict {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	d, _ := Clone(s.data)
+	return d
+}
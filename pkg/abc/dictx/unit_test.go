@@ -5,7 +5,13 @@
 package dictx
 
 import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -29,6 +35,91 @@ func TestClone(t *testing.T) {
 	assert.Equal(t, "e", original["a"].(Dict)["b"].(Dict)["c"].(Dict)["d"])
 }
 
+type cloneItem struct {
+	Name string
+	Tags []string
+}
+
+type cloneCounter struct{ n *int }
+
+func (c cloneCounter) Clone() any {
+	n := *c.n + 1
+	return cloneCounter{n: &n}
+}
+
+func TestClone_Values(t *testing.T) {
+	now := time.Now()
+	n := 1
+	original := Dict{
+		"list":  []any{1, Dict{"a": "b"}, []string{"x"}},
+		"ints":  []int{1, 2},
+		"map":   map[string][]int{"k": {1}},
+		"time":  now,
+		"ptr":   &cloneItem{Name: "p", Tags: []string{"t"}},
+		"item":  cloneItem{Name: "i", Tags: []string{"t"}},
+		"arr":   [2][]int{{1}, {2}},
+		"cnt":   cloneCounter{n: &n},
+		"nil":   nil,
+		"empty": []any(nil),
+	}
+	cloned, err := Clone(original)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, *cloned["cnt"].(cloneCounter).n)
+	delete(cloned, "cnt")
+	delete(original, "cnt")
+	assert.Equal(t, original, cloned)
+
+	cloned["list"].([]any)[1].(Dict)["a"] = "c"
+	cloned["list"].([]any)[2].([]string)[0] = "y"
+	cloned["ints"].([]int)[0] = 9
+	cloned["map"].(map[string][]int)["k"][0] = 9
+	cloned["ptr"].(*cloneItem).Tags[0] = "u"
+	cloned["item"].(cloneItem).Tags[0] = "u"
+	cloned["arr"].([2][]int)[0][0] = 9
+	assert.Equal(t, Dict{"a": "b"}, original["list"].([]any)[1])
+	assert.Equal(t, []string{"x"}, original["list"].([]any)[2])
+	assert.Equal(t, []int{1, 2}, original["ints"])
+	assert.Equal(t, []int{1}, original["map"].(map[string][]int)["k"])
+	assert.Equal(t, []string{"t"}, original["ptr"].(*cloneItem).Tags)
+	assert.Equal(t, []string{"t"}, original["item"].(cloneItem).Tags)
+	assert.Equal(t, 1, original["arr"].([2][]int)[0][0])
+}
+
+func benchDict() Dict {
+	d := Dict{}
+	for i := 0; i < 20; i++ {
+		Set(d, fmt.Sprintf("g%d.k%d", i%4, i), i)
+		Set(d, fmt.Sprintf("g%d.s%d", i%4, i), "value")
+		Set(d, fmt.Sprintf("g%d.l%d", i%4, i), []any{1.5, "x", true})
+	}
+	return d
+}
+
+func BenchmarkClone(b *testing.B) {
+	d := benchDict()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Clone(d)
+	}
+}
+
+func BenchmarkClone_Gob(b *testing.B) {
+	gob.Register(Dict{})
+	gob.Register([]any{})
+	d := benchDict()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(d); err != nil {
+			b.Fatal(err)
+		}
+		res := Dict{}
+		if err := gob.NewDecoder(&buf).Decode(&res); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestString(t *testing.T) {
 	d := Dict{
 		"a": Dict{
@@ -170,3 +261,412 @@ func TestDelete(t *testing.T) {
 	assert.False(t, IsExist(d, "a.b.c.d"))
 	assert.True(t, IsExist(d, "a.b.c"))
 }
+
+func TestJSON(t *testing.T) {
+	d := Dict{
+		"b": Dict{"y": 1, "x": "<a&b>"},
+		"a": []any{Dict{"k": true}, "v"},
+	}
+	b, err := ToJSON(d, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":[{"k":true},"v"],"b":{"x":"<a&b>","y":1}}`, string(b))
+
+	b, err = ToJSON(d, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\": [\n    {\n      \"k\": true\n    },\n"+
+		"    \"v\"\n  ],\n  \"b\": {\n    \"x\": \"<a&b>\",\n    \"y\": 1\n  }\n}",
+		string(b))
+
+	res, err := FromJSON(b)
+	assert.NoError(t, err)
+	assert.IsType(t, Dict{}, res["b"])
+	assert.IsType(t, Dict{}, res["a"].([]any)[0])
+	assert.Equal(t, float64(1), Get(res, "b.y", nil))
+
+	for _, s := range []string{"", "null", " "} {
+		res, err = FromJSON([]byte(s))
+		assert.NoError(t, err)
+		assert.Equal(t, Dict{}, res)
+	}
+	_, err = FromJSON([]byte(`[1]`))
+	assert.Error(t, err)
+}
+
+func TestYAML(t *testing.T) {
+	d := Dict{
+		"b": Dict{"q": 1, "p": "text"},
+		"a": []any{Dict{"k": true}, "v"},
+	}
+	b, err := ToYAML(d, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "a:\n  - k: true\n  - v\nb:\n  p: text\n  q: 1\n", string(b))
+
+	res, err := FromYAML(b)
+	assert.NoError(t, err)
+	assert.IsType(t, Dict{}, res["b"])
+	assert.IsType(t, Dict{}, res["a"].([]any)[0])
+	assert.Equal(t, 1, Get(res, "b.q", nil))
+
+	// non-string keys are converted to strings
+	res, err = FromYAML([]byte("a:\n  1: x\n  true: y\nb: [{2: z}]\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "x", Get(res, "a.1", nil))
+	assert.Equal(t, "y", Get(res, "a.true", nil))
+	assert.Equal(t, Dict{"2": "z"}, res["b"].([]any)[0])
+
+	res, err = FromYAML(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Dict{}, res)
+	_, err = FromYAML([]byte("- 1\n- 2\n"))
+	assert.Error(t, err)
+}
+
+type testBase struct {
+	ID string `dictx:"id"`
+}
+
+type testServer struct {
+	Host    string        `dictx:"host"`
+	Port    int           `dictx:"port"`
+	Timeout time.Duration `dictx:"timeout"`
+}
+
+type testConfig struct {
+	testBase
+	Name     string
+	Enabled  bool                  `dictx:"enabled"`
+	Ratio    float32               `dictx:"ratio,omitempty"`
+	Server   testServer            `dictx:"server"`
+	Backup   *testServer           `dictx:"backup,omitempty"`
+	Tags     []string              `dictx:"tags"`
+	Servers  []testServer          `dictx:"servers"`
+	Limits   map[string]uint8      `dictx:"limits"`
+	Since    time.Time             `dictx:"since"`
+	Retries  *int                  `dictx:"retries"`
+	Extra    any                   `dictx:"extra"`
+	Ignored  string                `dictx:"-"`
+	Services map[string]testServer `dictx:"services,omitempty"`
+	hidden   int
+}
+
+func TestDecode(t *testing.T) {
+	d, err := FromJSON([]byte(`{
+		"id": "x1",
+		"name": "dev",
+		"enabled": "true",
+		"ratio": 0.5,
+		"server": {"host": "h1", "port": 80, "timeout": "1m30s"},
+		"backup": {"host": "h2", "port": "8080", "timeout": 2.5},
+		"tags": ["a", "b"],
+		"servers": [{"host": "h3"}, {"port": 1}],
+		"limits": {"x": 1, "y": 255},
+		"since": "2024-01-02T03:04:05Z",
+		"retries": 3,
+		"extra": {"k": [1, 2]},
+		"Ignored": "value"
+	}`))
+	assert.NoError(t, err)
+
+	cfg := testConfig{Server: testServer{Port: 1234}, Ignored: "keep"}
+	assert.NoError(t, Decode(d, &cfg))
+	assert.Equal(t, "x1", cfg.ID)
+	assert.Equal(t, "dev", cfg.Name)
+	assert.True(t, cfg.Enabled)
+	assert.Equal(t, float32(0.5), cfg.Ratio)
+	assert.Equal(t, testServer{"h1", 80, 90 * time.Second}, cfg.Server)
+	assert.Equal(t, &testServer{"h2", 8080, 2500 * time.Millisecond}, cfg.Backup)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	assert.Equal(t, []testServer{{Host: "h3"}, {Port: 1}}, cfg.Servers)
+	assert.Equal(t, map[string]uint8{"x": 1, "y": 255}, cfg.Limits)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), cfg.Since)
+	assert.Equal(t, 3, *cfg.Retries)
+	assert.Equal(t, Dict{"k": []any{float64(1), float64(2)}}, cfg.Extra)
+	assert.Equal(t, "keep", cfg.Ignored)
+
+	// missing keys keep defaults and nil resets values
+	cfg = testConfig{Name: "default", Retries: new(int)}
+	assert.NoError(t, Decode(Dict{"retries": nil}, &cfg))
+	assert.Equal(t, "default", cfg.Name)
+	assert.Nil(t, cfg.Retries)
+}
+
+func TestDecode_Errors(t *testing.T) {
+	var cfg testConfig
+	assert.Error(t, Decode(Dict{}, cfg))
+	assert.Error(t, Decode(Dict{}, nil))
+
+	for _, d := range []Dict{
+		{"server": Dict{"port": "x"}},
+		{"server": Dict{"port": 1.5}},
+		{"server": "x"},
+		{"limits": Dict{"x": 256}},
+		{"limits": Dict{"x": -1}},
+		{"tags": "x"},
+		{"tags": []any{Dict{}}},
+		{"enabled": 1},
+		{"server": Dict{"timeout": "x"}},
+		{"since": "x"},
+	} {
+		assert.Error(t, Decode(d, &cfg), "input: %v", d)
+	}
+
+	err := Decode(Dict{"servers": []any{Dict{}, Dict{"port": "x"}}}, &cfg)
+	assert.ErrorContains(t, err, "servers[1].port")
+}
+
+func TestEncode(t *testing.T) {
+	retries := 3
+	cfg := testConfig{
+		testBase: testBase{ID: "x1"},
+		Name:     "dev",
+		Server:   testServer{"h1", 80, 90 * time.Second},
+		Tags:     []string{"a"},
+		Servers:  []testServer{{Host: "h3"}},
+		Limits:   map[string]uint8{"x": 1},
+		Since:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Retries:  &retries,
+		Ignored:  "value",
+	}
+	d, err := Encode(&cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, Dict{
+		"id":      "x1",
+		"Name":    "dev",
+		"enabled": false,
+		"server":  Dict{"host": "h1", "port": 80, "timeout": "1m30s"},
+		"tags":    []any{"a"},
+		"servers": []any{Dict{"host": "h3", "port": 0, "timeout": "0s"}},
+		"limits":  Dict{"x": uint8(1)},
+		"since":   "2024-01-02T03:04:05Z",
+		"retries": 3,
+		"extra":   nil,
+	}, d)
+
+	// round trip through JSON
+	b, err := ToJSON(d, 0)
+	assert.NoError(t, err)
+	d, err = FromJSON(b)
+	assert.NoError(t, err)
+	var res testConfig
+	assert.NoError(t, Decode(d, &res))
+	cfg.Ignored = ""
+	assert.Equal(t, cfg, res)
+
+	_, err = Encode("x")
+	assert.Error(t, err)
+	_, err = Encode(struct{ F func() }{})
+	assert.Error(t, err)
+}
+
+func TestMergeWith(t *testing.T) {
+	newSrc := func() Dict {
+		return Dict{
+			"a": 1,
+			"b": Dict{"c": []any{1}, "d": "x", "s": []string{"p"}},
+			"e": Dict{"f": 1},
+		}
+	}
+	updt := Dict{
+		"a": 2,
+		"b": Dict{"c": []any{2}, "s": []string{"q"}, "g": true},
+		"e": "flat",
+		"h": Dict{"i": 1},
+	}
+
+	// override matches Merge semantics
+	src, expected := newSrc(), newSrc()
+	MergeWith(src, updt, nil)
+	Merge(expected, updt)
+	assert.Equal(t, expected, src)
+
+	src = newSrc()
+	MergeWith(src, updt, MergeKeepExisting)
+	assert.Equal(t, Dict{
+		"a": 1,
+		"b": Dict{"c": []any{1}, "d": "x", "s": []string{"p"}, "g": true},
+		"e": Dict{"f": 1},
+		"h": Dict{"i": 1},
+	}, src)
+
+	src = newSrc()
+	MergeWith(src, updt, MergeAppendSlices)
+	assert.Equal(t, Dict{
+		"a": 2,
+		"b": Dict{"c": []any{1, 2}, "d": "x", "s": []string{"p", "q"}, "g": true},
+		"e": "flat",
+		"h": Dict{"i": 1},
+	}, src)
+
+	// custom resolver gets the full nested key
+	keys := []string{}
+	src = newSrc()
+	MergeWith(src, updt, func(key string, current, update any) any {
+		keys = append(keys, key)
+		if key == "a" {
+			return current.(int) + update.(int)
+		}
+		return current
+	})
+	assert.ElementsMatch(t, []string{"a", "b.c", "b.s", "e"}, keys)
+	assert.Equal(t, 3, src["a"])
+}
+
+func TestPaths(t *testing.T) {
+	d := Dict{
+		"k7": Dict{"t": []any{"a", []any{0, 1, 2, []int{7, 8, 9}}}},
+		"devices": Dict{
+			"d2": Dict{"address": "10.0.0.2"},
+			"d1": Dict{"address": "10.0.0.1"},
+			"d3": Dict{"name": "none"},
+		},
+		"list":  []Dict{{"id": 1}, {"id": 2}},
+		"a/b":   Dict{"m~n": 5},
+		"x[0]":  "literal",
+		"extra": map[string]string{"p": "q"},
+	}
+
+	assert.Equal(t, "a", Get(d, "k7.t[0]", nil))
+	assert.Equal(t, 8, Get(d, "k7.t[1].3.1", nil))
+	assert.Equal(t, 8, Get(d, "k7.t.1[3][1]", nil))
+	assert.Equal(t, 2, Get(d, "list[1].id", nil))
+	assert.Equal(t, "literal", Get(d, "x[0]", nil))
+	assert.Equal(t, "q", Get(d, "extra.p", nil))
+	assert.Equal(t, "def", Get(d, "k7.t[5]", "def"))
+	assert.Equal(t, "def", Get(d, "k7.t[-1]", "def"))
+	assert.Equal(t, "def", Get(d, "devices.*.address", "def"))
+	assert.True(t, IsExist(d, "list[0]"))
+	assert.False(t, IsExist(d, "list[2]"))
+
+	assert.Equal(t, []any{"10.0.0.1", "10.0.0.2"},
+		Query(d, "devices.*.address"))
+	assert.Equal(t, []any{1, 2}, Query(d, "list[*].id"))
+	assert.Equal(t, []any{7, 8, 9}, Query(d, "k7.t[1].3.*"))
+	assert.Equal(t, []any{}, Query(d, "devices.*.port"))
+
+	assert.Equal(t, 9, GetPointer(d, "/k7/t/1/3/2", nil))
+	assert.Equal(t, 5, GetPointer(d, "/a~1b/m~0n", nil))
+	assert.Equal(t, d, GetPointer(d, "", nil))
+	assert.Equal(t, "def", GetPointer(d, "k7", "def"))
+	assert.Equal(t, "def", GetPointer(d, "/k7/t/01", "def"))
+}
+
+func TestSyncDict(t *testing.T) {
+	s := NewSyncDict(Dict{"a": Dict{"b": 1}})
+	assert.Equal(t, 1, s.Get("a.b", nil))
+
+	// returned nested dicts are copies
+	s.Get("a", nil).(Dict)["b"] = 5
+	assert.Equal(t, 1, s.Get("a.b", nil))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s.Set(fmt.Sprintf("n.k%d", i), i)
+			s.Update(func(d Dict) {
+				Set(d, "count", Fetch(d, "count", 0)+1)
+			})
+			_ = s.Keys()
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 50, s.Get("count", nil))
+	assert.Len(t, s.KeysN(-1), 52)
+
+	s.Merge(Dict{"a": Dict{"c": 2}})
+	s.Delete("n")
+	assert.False(t, s.IsExist("n"))
+	assert.Equal(t, Dict{"a": Dict{"b": 1, "c": 2}, "count": 50}, s.Snapshot())
+}
+
+func TestODict(t *testing.T) {
+	o := NewODict()
+	o.Set("z", 1)
+	o.Set("a.y", "v")
+	o.Set("a.b", true)
+	o.Set("m", []any{1})
+	o.Set("z", 2)
+	assert.Equal(t, []string{"z", "a", "m"}, o.Keys())
+	assert.Equal(t, 2, o.Get("z", nil))
+	assert.Equal(t, "v", o.Get("a.y", nil))
+	assert.Equal(t, "def", o.Get("a.x", "def"))
+	assert.True(t, o.IsExist("a.b"))
+
+	b, err := json.Marshal(o)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"z":2,"a":{"y":"v","b":true},"m":[1]}`, string(b))
+
+	o.Delete("a.y")
+	o.Delete("z")
+	assert.Equal(t, []string{"a", "m"}, o.Keys())
+	assert.Equal(t, Dict{"a": Dict{"b": true}, "m": []any{1}}, o.ToDict())
+
+	src := `{"k3":1.5,"k1":{"n2":"s","n1":[{"q":1,"p":2}]},"k2":null}`
+	r := NewODict()
+	assert.Nil(t, json.Unmarshal([]byte(src), r))
+	assert.Equal(t, []string{"k3", "k1", "k2"}, r.Keys())
+	assert.Equal(t, []string{"n2", "n1"},
+		r.Get("k1", nil).(*ODict).Keys())
+	b, err = json.Marshal(r)
+	assert.Nil(t, err)
+	assert.Equal(t, src, string(b))
+
+	assert.NotNil(t, json.Unmarshal([]byte(`[1]`), r))
+}
+
+func TestGetE(t *testing.T) {
+	d := Dict{
+		"a": Dict{"i": 5, "f": 2.5, "w": 3.0, "s": "12", "b": true},
+		"n": -1,
+		"l": []any{1},
+	}
+
+	_, err := GetE(d, "a.x")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = GetIntE(d, "a.x")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = GetStringE(d, "l")
+	assert.ErrorIs(t, err, ErrWrongType)
+	_, err = GetBoolE(d, "a.i")
+	assert.ErrorIs(t, err, ErrWrongType)
+	_, err = GetUintE(d, "n")
+	assert.ErrorIs(t, err, ErrWrongType)
+	_, err = GetIntE(d, "a.b")
+	assert.ErrorIs(t, err, ErrWrongType)
+
+	i, err := GetIntE(d, "a.f")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, i)
+	i, err = GetIntE(d, "a.s")
+	assert.Nil(t, err)
+	assert.Equal(t, 12, i)
+	u, err := GetUintE(d, "a.i")
+	assert.Nil(t, err)
+	assert.Equal(t, uint(5), u)
+	f, err := GetFloatE(d, "a.i")
+	assert.Nil(t, err)
+	assert.Equal(t, 5.0, f)
+	s, err := GetStringE(d, "a.i")
+	assert.Nil(t, err)
+	assert.Equal(t, "5", s)
+	b, err := GetBoolE(d, "a.b")
+	assert.Nil(t, err)
+	assert.True(t, b)
+	b, err = GetBoolE(Dict{"k": "off"}, "k")
+	assert.Nil(t, err)
+	assert.False(t, b)
+
+	Strict = true
+	defer func() { Strict = false }()
+
+	_, err = GetIntE(d, "a.f")
+	assert.ErrorIs(t, err, ErrWrongType)
+	_, err = GetIntE(d, "a.s")
+	assert.ErrorIs(t, err, ErrWrongType)
+	_, err = GetStringE(d, "a.i")
+	assert.ErrorIs(t, err, ErrWrongType)
+	i, err = GetIntE(d, "a.w")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, i)
+}
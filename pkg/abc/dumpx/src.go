@@ -0,0 +1,106 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package dumpx
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// DUMP_WIDTH defines the default number of bytes per hexdump line.
+const DUMP_WIDTH = 16
+
+// Hex returns the uppercase hexadecimal string of data, with the
+// separator between bytes.
+//
+//	Hex([]byte{0x01, 0xAB}, ":")   // "01:AB"
+func Hex(data []byte, sep string) string {
+	s := strings.ToUpper(hex.EncodeToString(data))
+	if sep == "" || len(data) < 2 {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s) + len(sep)*(len(data)-1))
+	for i := 0; i < len(s); i += 2 {
+		if i > 0 {
+			sb.WriteString(sep)
+		}
+		sb.WriteString(s[i : i+2])
+	}
+	return sb.String()
+}
+
+// ASCII returns the printable ASCII representation of data, with
+// non-printable bytes replaced by dots.
+func ASCII(data []byte) string {
+	b := make([]byte, len(data))
+	for i, c := range data {
+		if c < 0x20 || c > 0x7E {
+			c = '.'
+		}
+		b[i] = c
+	}
+	return string(b)
+}
+
+// Hexdump returns the canonical hexdump of data, with width bytes per
+// line or DUMP_WIDTH if width is zero. Each line holds the offset,
+// the hexadecimal bytes and the ASCII representation.
+//
+//	00000000  48 65 6C 6C 6F 2C 20 77  6F 72 6C 64 21 0A        |Hello, world!.|
+func Hexdump(data []byte, width int) string {
+	if width <= 0 {
+		width = DUMP_WIDTH
+	}
+	var sb strings.Builder
+	for off := 0; off < len(data); off += width {
+		line := data[off:]
+		if len(line) > width {
+			line = line[:width]
+		}
+		fmt.Fprintf(&sb, "%08X ", off)
+		for i := 0; i < width; i++ {
+			if i%8 == 0 {
+				sb.WriteByte(' ')
+			}
+			if i < len(line) {
+				fmt.Fprintf(&sb, "%02X ", line[i])
+			} else {
+				sb.WriteString("   ")
+			}
+		}
+		sb.WriteString(" |" + ASCII(line) + "|\n")
+	}
+	return sb.String()
+}
+
+// ParseHex decodes a hexadecimal string, ignoring whitespace, colon,
+// dash and comma separators and "0x" prefixes.
+//
+//	ParseHex("01:AB cd, 0xEF")   // []byte{0x01, 0xAB, 0xCD, 0xEF}
+func ParseHex(s string) ([]byte, error) {
+	s = strings.NewReplacer("0x", " ", "0X", " ").Replace(s)
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n', ':', '-', ',':
+			return -1
+		}
+		return r
+	}, s)
+	return hex.DecodeString(s)
+}
+
+// ParseBase64 decodes a base64 string in standard or URL encoding,
+// with or without padding, ignoring whitespace.
+func ParseBase64(s string) ([]byte, error) {
+	s = strings.Join(strings.Fields(s), "")
+	s = strings.TrimRight(s, "=")
+	if strings.ContainsAny(s, "-_") {
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package dumpx_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dumpx"
+)
+
+func TestHex(t *testing.T) {
+	assert.Equal(t, "01AB", dumpx.Hex([]byte{0x01, 0xAB}, ""))
+	assert.Equal(t, "01:AB:FF", dumpx.Hex([]byte{0x01, 0xAB, 0xFF}, ":"))
+	assert.Equal(t, "01", dumpx.Hex([]byte{0x01}, " "))
+	assert.Equal(t, "", dumpx.Hex(nil, " "))
+}
+
+func TestASCII(t *testing.T) {
+	assert.Equal(t, "ab.c.", dumpx.ASCII([]byte{'a', 'b', 0x00, 'c', 0xFF}))
+}
+
+func TestHexdump(t *testing.T) {
+	assert.Equal(t, "", dumpx.Hexdump(nil, 0))
+	assert.Equal(t,
+		"00000000  48 65 6C 6C 6F 2C 20 77  6F 72 6C 64 21 0A        |Hello, world!.|\n",
+		dumpx.Hexdump([]byte("Hello, world!\n"), 0))
+	assert.Equal(t,
+		"00000000  41 42 43 44  |ABCD|\n"+
+			"00000004  45           |E|\n",
+		dumpx.Hexdump([]byte("ABCDE"), 4))
+}
+
+func TestParseHex(t *testing.T) {
+	b, err := dumpx.ParseHex("01:AB cd,\n0xEF-10")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x01, 0xAB, 0xCD, 0xEF, 0x10}, b)
+
+	b, err = dumpx.ParseHex(dumpx.Hex([]byte{0xDE, 0xAD}, " "))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0xDE, 0xAD}, b)
+
+	_, err = dumpx.ParseHex("0G")
+	assert.NotNil(t, err)
+	_, err = dumpx.ParseHex("ABC")
+	assert.NotNil(t, err)
+}
+
+func TestParseBase64(t *testing.T) {
+	for _, s := range []string{"+/8=", "+/8", "-_8", " +/\n8= "} {
+		b, err := dumpx.ParseBase64(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, []byte{0xFB, 0xFF}, b, s)
+	}
+	_, err := dumpx.ParseBase64("+_8")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package exitcode_test
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/exonlabs/go-utils/pkg/abc/exitcode"
+)
+
+func ExampleCode() {
+	err := fmt.Errorf("%w: missing key 'port'", exitcode.ErrConfig)
+	fmt.Println(exitcode.Code(err))
+	fmt.Println(exitcode.Code(errors.New("unknown")))
+	fmt.Println(exitcode.Code(nil))
+	// Output:
+	// 78
+	// 1
+	// 0
+}
+
+func ExampleWithCode() {
+	err := exitcode.WithCode(errors.New("license expired"), 3)
+	fmt.Println(err, exitcode.Code(err))
+	// Output: license expired 3
+}
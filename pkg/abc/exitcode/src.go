@@ -0,0 +1,144 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Process exit codes, following the BSD sysexits conventions.
+const (
+	// OK indicates successful termination.
+	OK = 0
+	// FAILURE indicates a general unclassified failure.
+	FAILURE = 1
+	// USAGE indicates invalid command line usage.
+	USAGE = 64
+	// UNAVAILABLE indicates a failed connection or unavailable service.
+	UNAVAILABLE = 69
+	// SOFTWARE indicates an internal software error, such as a panic.
+	SOFTWARE = 70
+	// TEMPFAIL indicates a temporary failure such as a timeout, where
+	// retrying later may succeed.
+	TEMPFAIL = 75
+	// NOPERM indicates insufficient permissions.
+	NOPERM = 77
+	// CONFIG indicates an invalid or missing configuration.
+	CONFIG = 78
+)
+
+var (
+	// ErrUsage indicates invalid command line usage.
+	ErrUsage = errors.New("invalid usage")
+	// ErrConfig indicates an invalid or missing configuration.
+	ErrConfig = errors.New("invalid config")
+	// ErrConnection indicates a failed connection.
+	ErrConnection = errors.New("connection failed")
+	// ErrPermission indicates insufficient permissions.
+	ErrPermission = errors.New("permission denied")
+	// ErrTimeout indicates an operation timeout.
+	ErrTimeout = errors.New("timeout")
+	// ErrInternal indicates an internal software error.
+	ErrInternal = errors.New("internal error")
+)
+
+// Error defines an error carrying an explicit exit code.
+type Error struct {
+	Code int
+	Err  error
+}
+
+// Error returns the wrapped error message.
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit code %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithCode wraps the error with an explicit exit code.
+func WithCode(err error, code int) error {
+	return &Error{Code: code, Err: err}
+}
+
+type mapping struct {
+	target error
+	code   int
+}
+
+var (
+	mapLock sync.RWMutex
+	// registered mappings, checked in order before the default mappings
+	mappings []mapping
+	// default mappings
+	defaults = []mapping{
+		{ErrUsage, USAGE},
+		{ErrConfig, CONFIG},
+		{ErrConnection, UNAVAILABLE},
+		{ErrPermission, NOPERM},
+		{os.ErrPermission, NOPERM},
+		{ErrTimeout, TEMPFAIL},
+		{os.ErrDeadlineExceeded, TEMPFAIL},
+		{context.DeadlineExceeded, TEMPFAIL},
+		{ErrInternal, SOFTWARE},
+	}
+)
+
+// Register maps the target error to exit code. Errors matching the target
+// using [errors.Is] resolve to the code. Registered mappings take priority
+// over the default mappings, in registration order.
+func Register(target error, code int) {
+	mapLock.Lock()
+	defer mapLock.Unlock()
+	mappings = append(mappings, mapping{target, code})
+}
+
+// Code returns the exit code for the error. The code is resolved from
+// explicit [Error] codes, then the registered mappings, then the default
+// mappings, then errors implementing `Timeout() bool` are mapped to
+// TEMPFAIL. nil errors return OK and unmatched errors return FAILURE.
+func Code(err error) int {
+	if err == nil {
+		return OK
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+
+	mapLock.RLock()
+	defer mapLock.RUnlock()
+	for _, m := range mappings {
+		if errors.Is(err, m.target) {
+			return m.code
+		}
+	}
+	for _, m := range defaults {
+		if errors.Is(err, m.target) {
+			return m.code
+		}
+	}
+
+	var t interface{ Timeout() bool }
+	if errors.As(err, &t) && t.Timeout() {
+		return TEMPFAIL
+	}
+	return FAILURE
+}
+
+// Exit terminates the process with the exit code resolved for the error.
+func Exit(err error) {
+	os.Exit(Code(err))
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package exitcode_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/abc/exitcode"
+)
+
+func TestCode(t *testing.T) {
+	assert.Equal(t, exitcode.OK, exitcode.Code(nil))
+	assert.Equal(t, exitcode.FAILURE, exitcode.Code(errors.New("x")))
+
+	tests := map[error]int{
+		exitcode.ErrUsage:      exitcode.USAGE,
+		exitcode.ErrConfig:     exitcode.CONFIG,
+		exitcode.ErrConnection: exitcode.UNAVAILABLE,
+		exitcode.ErrPermission: exitcode.NOPERM,
+		exitcode.ErrTimeout:    exitcode.TEMPFAIL,
+		exitcode.ErrInternal:   exitcode.SOFTWARE,
+	}
+	for err, code := range tests {
+		assert.Equal(t, code, exitcode.Code(err), err.Error())
+		assert.Equal(t, code, exitcode.Code(fmt.Errorf("wrapped: %w", err)),
+			err.Error())
+	}
+}
+
+func TestCodeStdErrors(t *testing.T) {
+	_, err := os.ReadFile("/proc/1/mem")
+	if errors.Is(err, os.ErrPermission) {
+		assert.Equal(t, exitcode.NOPERM, exitcode.Code(err))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	assert.Equal(t, exitcode.TEMPFAIL, exitcode.Code(ctx.Err()))
+
+	var netErr net.Error = &net.DNSError{IsTimeout: true}
+	assert.Equal(t, exitcode.TEMPFAIL, exitcode.Code(netErr))
+}
+
+func TestWithCode(t *testing.T) {
+	base := errors.New("base")
+	err := exitcode.WithCode(base, 5)
+	assert.Equal(t, 5, exitcode.Code(err))
+	assert.Equal(t, 5, exitcode.Code(fmt.Errorf("wrapped: %w", err)))
+	assert.ErrorIs(t, err, base)
+	assert.Equal(t, "base", err.Error())
+
+	// explicit code takes priority over mappings
+	err = exitcode.WithCode(exitcode.ErrConfig, 9)
+	assert.Equal(t, 9, exitcode.Code(err))
+}
+
+func TestRegister(t *testing.T) {
+	errCustom := errors.New("custom")
+	assert.Equal(t, exitcode.FAILURE, exitcode.Code(errCustom))
+	exitcode.Register(errCustom, 10)
+	assert.Equal(t, 10, exitcode.Code(errCustom))
+	assert.Equal(t, 10, exitcode.Code(fmt.Errorf("wrapped: %w", errCustom)))
+}
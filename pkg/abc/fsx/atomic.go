@@ -0,0 +1,143 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package fsx
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// syncDir flushes the directory entries to disk. syncing directories is
+// not supported on all platforms, so errors are ignored.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// createTemp creates a new temp file in dir for name, with file mode perm
+// before umask, like [os.WriteFile] for new files.
+func createTemp(dir, name string, perm os.FileMode) (*os.File, error) {
+	for i := 0; i < 10000; i++ {
+		path := filepath.Join(dir,
+			"."+name+".tmp"+strconv.FormatUint(uint64(rand.Uint32()), 10))
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if os.IsExist(err) {
+			continue
+		}
+		return f, err
+	}
+	return nil, &os.PathError{
+		Op: "createtemp", Path: filepath.Join(dir, "."+name+".tmp*"),
+		Err: os.ErrExist}
+}
+
+// writeTemp writes data from fn to a temp file in dir, flushed to disk.
+// The temp file is created with file mode perm before umask, or with the
+// mode and owner of the file info if not nil, where failing to set the
+// owner is ignored. It returns the temp file path.
+func writeTemp(dir, name string, perm os.FileMode, info os.FileInfo,
+	fn func(f *os.File) error) (string, error) {
+	f, err := createTemp(dir, name, perm)
+	if err != nil {
+		return "", err
+	}
+	tmpPath := f.Name()
+
+	if err = fn(f); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && info != nil {
+		// owner is kept where permitted, before mode to keep setuid bits
+		chown(tmpPath, info)
+		err = os.Chmod(tmpPath, info.Mode()&(os.ModePerm|
+			os.ModeSetuid|os.ModeSetgid|os.ModeSticky))
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	return tmpPath, nil
+}
+
+// WriteFileAtomic writes data to a temp file in the same directory as
+// path, flushes it to disk and renames it over path, so readers see
+// either the old or the new content. The directory is synced after the
+// rename to persist the new entry where supported. New files are created
+// with file mode perm before umask like [os.WriteFile], and existing files
+// keep their mode, and their owner where permitted.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		info = nil
+	}
+	tmpPath, err := writeTemp(dir, name, perm, info, func(f *os.File) error {
+		_, err := f.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// ReplaceFile moves the file src over dst, replacing it atomically.
+// When src and dst are on different devices, src is first copied to a
+// temp file next to dst with the src mode and owner where permitted,
+// then renamed over dst and src is removed.
+func ReplaceFile(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil {
+		syncDir(filepath.Dir(dst))
+		return nil
+	}
+	if !isCrossDevice(err) {
+		return err
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	fin, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer fin.Close()
+
+	dir, name := filepath.Split(dst)
+	if dir == "" {
+		dir = "."
+	}
+	tmpPath, err := writeTemp(dir, name, info.Mode().Perm(), info,
+		func(f *os.File) error {
+			_, err := f.ReadFrom(fin)
+			return err
+		})
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, dst); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	syncDir(dir)
+	return os.Remove(src)
+}
@@ -0,0 +1,296 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package fsx
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// CopyProgress holds the copy progress reported after each copied file.
+type CopyProgress struct {
+	// Path is the copied file path relative to source.
+	Path string
+	// Files is the number of files copied so far.
+	Files int
+	// Bytes is the number of bytes copied so far.
+	Bytes int64
+}
+
+// CopyOption defines the copy options used with [Copy] and [CopyDir].
+type CopyOption func(*copyOptions)
+
+type copyOptions struct {
+	include, exclude []string
+	progress         func(CopyProgress)
+	preserveTimes    bool
+	preserveOwner    bool
+	preserveMode     bool
+	dryRun           bool
+
+	files int
+	bytes int64
+}
+
+// Include sets the glob patterns of files to copy, matched against the
+// path relative to source or the base name. Directories are always
+// traversed unless excluded.
+func Include(patterns ...string) CopyOption {
+	return func(o *copyOptions) {
+		o.include = append(o.include, patterns...)
+	}
+}
+
+// Exclude sets the glob patterns of files and directories to skip,
+// matched against the path relative to source or the base name.
+func Exclude(patterns ...string) CopyOption {
+	return func(o *copyOptions) {
+		o.exclude = append(o.exclude, patterns...)
+	}
+}
+
+// Progress sets a callback called after each copied file.
+func Progress(fn func(CopyProgress)) CopyOption {
+	return func(o *copyOptions) {
+		o.progress = fn
+	}
+}
+
+// PreserveTimes preserves the modification time of copied entries.
+func PreserveTimes() CopyOption {
+	return func(o *copyOptions) {
+		o.preserveTimes = true
+	}
+}
+
+// PreserveOwner preserves the owner and group of copied entries,
+// it requires privileges and is ignored on windows.
+func PreserveOwner() CopyOption {
+	return func(o *copyOptions) {
+		o.preserveOwner = true
+	}
+}
+
+// PreserveMode preserves the exact mode of copied entries, including the
+// setuid, setgid and sticky bits, regardless of the process umask.
+// By default, only the permission bits are applied through the umask.
+func PreserveMode() CopyOption {
+	return func(o *copyOptions) {
+		o.preserveMode = true
+	}
+}
+
+// DryRun walks the source and reports progress without writing anything.
+func DryRun() CopyOption {
+	return func(o *copyOptions) {
+		o.dryRun = true
+	}
+}
+
+// matchAny checks if the relative path or its base name matches any of
+// the glob patterns.
+func matchAny(patterns []string, rel string) bool {
+	rel = filepath.ToSlash(rel)
+	base := filepath.Base(rel)
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, rel); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(p, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// skip checks if an entry is filtered out of copy.
+func (o *copyOptions) skip(rel string, isDir bool) bool {
+	if matchAny(o.exclude, rel) {
+		return true
+	}
+	return !isDir && len(o.include) > 0 && !matchAny(o.include, rel)
+}
+
+// done updates and reports the copy progress for a copied file.
+func (o *copyOptions) done(rel string, size int64) {
+	o.files++
+	o.bytes += size
+	if o.progress != nil {
+		o.progress(CopyProgress{Path: rel, Files: o.files, Bytes: o.bytes})
+	}
+}
+
+// preserve applies the preserved attributes of info to dst.
+func (o *copyOptions) preserve(dst string, info os.FileInfo) error {
+	isLink := info.Mode()&os.ModeSymlink != 0
+	if o.preserveOwner {
+		if err := chown(dst, info); err != nil {
+			return err
+		}
+	}
+	if o.preserveMode && !isLink {
+		if err := os.Chmod(dst, info.Mode()&(os.ModePerm|
+			os.ModeSetuid|os.ModeSetgid|os.ModeSticky)); err != nil {
+			return err
+		}
+	}
+	if o.preserveTimes && !isLink {
+		if err := os.Chtimes(dst, info.ModTime(), info.ModTime()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile copies regular files from src to dst, preserving file mode.
+func copyFile(src, dst string, perm os.FileMode) error {
+	fin, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer fin.Close()
+
+	fout, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer fout.Close()
+
+	if _, err := io.Copy(fout, fin); err != nil {
+		return err
+	}
+	return fout.Sync()
+}
+
+// copySymlink copies symbolic links from src to dst.
+func copySymlink(src, dst string) error {
+	link, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	return os.Symlink(link, dst)
+}
+
+// copyEntry copies a file or symbolic link from src to dst.
+func (o *copyOptions) copyEntry(src, dst, rel string, info os.FileInfo) error {
+	if !o.dryRun {
+		var err error
+		if info.Mode()&os.ModeSymlink != 0 {
+			err = copySymlink(src, dst)
+		} else {
+			err = copyFile(src, dst, info.Mode().Perm())
+		}
+		if err != nil {
+			return err
+		}
+		if err := o.preserve(dst, info); err != nil {
+			return err
+		}
+	}
+	var size int64
+	if info.Mode().IsRegular() {
+		size = info.Size()
+	}
+	o.done(rel, size)
+	return nil
+}
+
+// Copy copies a file from src to dst. It handles files and symbolic links.
+func Copy(src, dst string, opts ...CopyOption) error {
+	srcInfo, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if srcInfo.IsDir() {
+		return errors.New("source is a directory")
+	}
+
+	if dst == src {
+		return errors.New("source and destination are the same")
+	}
+
+	if !IsExist(filepath.Dir(dst)) {
+		return errors.New("destination parent directory does not exist")
+	}
+
+	o := &copyOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o.copyEntry(src, dst, filepath.Base(src), srcInfo)
+}
+
+// copyDir recursively copies a directory from src to dst.
+func (o *copyOptions) copyDir(src, dst, rel string) error {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if !o.dryRun {
+		if err := os.MkdirAll(dst, srcInfo.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		srcPath := filepath.Join(src, entry.Name())
+		dstPath := filepath.Join(dst, entry.Name())
+		relPath := filepath.Join(rel, entry.Name())
+		if o.skip(relPath, entry.IsDir()) {
+			continue
+		}
+
+		if entry.IsDir() {
+			err = o.copyDir(srcPath, dstPath, relPath)
+		} else {
+			var entryInfo os.FileInfo
+			if entryInfo, err = entry.Info(); err == nil {
+				err = o.copyEntry(srcPath, dstPath, relPath, entryInfo)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// directory attributes are applied after its contents are copied
+	if !o.dryRun {
+		return o.preserve(dst, srcInfo)
+	}
+	return nil
+}
+
+// CopyDir copies a directory and its contents from src to dst.
+func CopyDir(src, dst string, opts ...CopyOption) error {
+	srcInfo, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if !srcInfo.IsDir() {
+		return errors.New("source is not a directory")
+	}
+
+	if dst == src {
+		return errors.New("source and destination are the same")
+	}
+
+	if !IsExist(filepath.Dir(dst)) {
+		return errors.New("destination parent directory does not exist")
+	}
+
+	o := &copyOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o.copyDir(src, dst, "")
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package fsx
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNoSpace indicates insufficient free disk space.
+var ErrNoSpace = errors.New("insufficient disk space")
+
+// Usage holds the disk usage of a file system.
+type Usage struct {
+	// Total is the file system size in bytes.
+	Total uint64
+	// Free is the free space in bytes available to the process,
+	// excluding the space reserved for privileged users.
+	Free uint64
+	// Used is the used space in bytes.
+	Used uint64
+}
+
+// UsedPercent returns the used space percentage of the total size.
+func (u Usage) UsedPercent() float64 {
+	if u.Total == 0 {
+		return 0
+	}
+	return float64(u.Used) * 100 / float64(u.Total)
+}
+
+// DiskUsage returns the disk usage of the file system holding path.
+func DiskUsage(path string) (Usage, error) {
+	return diskUsage(path)
+}
+
+// SpaceGuard checks the free space thresholds of the file system holding
+// a path before large writes, and runs the registered cleanup callbacks
+// when the free space is low.
+type SpaceGuard struct {
+	// path is the guarded path.
+	path string
+	// minFree defines the min free space in bytes.
+	minFree uint64
+	// minPercent defines the min free space percentage of total size.
+	minPercent float64
+
+	// cleanups holds the cleanup callbacks in registration order.
+	cleanups []func(Usage)
+	// mu serializes checks and cleanups.
+	mu sync.Mutex
+}
+
+// NewSpaceGuard creates a new [SpaceGuard] for path keeping at least
+// minFree bytes free.
+func NewSpaceGuard(path string, minFree uint64) *SpaceGuard {
+	return &SpaceGuard{path: path, minFree: minFree}
+}
+
+// SetMinPercent sets the min free space as percentage of total size,
+// applied in addition to the min free bytes.
+func (g *SpaceGuard) SetMinPercent(percent float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.minPercent = percent
+}
+
+// OnLowSpace registers a cleanup callback called with the current usage
+// when free space is below thresholds. The callbacks are called in
+// registration order until enough space is freed.
+func (g *SpaceGuard) OnLowSpace(fn func(Usage)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.cleanups = append(g.cleanups, fn)
+}
+
+// required returns the required free space in bytes for writing size
+// bytes with usage u.
+func (g *SpaceGuard) required(u Usage, size uint64) uint64 {
+	min := g.minFree
+	if v := uint64(g.minPercent * float64(u.Total) / 100); v > min {
+		min = v
+	}
+	return min + size
+}
+
+// Check checks that size bytes can be written while keeping the free
+// space thresholds, running the cleanup callbacks if needed.
+// It returns ErrNoSpace if not enough space is available after cleanup.
+func (g *SpaceGuard) Check(size uint64) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	u, err := diskUsage(g.path)
+	if err != nil {
+		return err
+	}
+	if u.Free >= g.required(u, size) {
+		return nil
+	}
+
+	for _, fn := range g.cleanups {
+		fn(u)
+		if u, err = diskUsage(g.path); err != nil {
+			return err
+		}
+		if u.Free >= g.required(u, size) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w, %d bytes free, %d required",
+		ErrNoSpace, u.Free, g.required(u, size))
+}
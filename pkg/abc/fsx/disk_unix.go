@@ -0,0 +1,28 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package fsx
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// diskUsage returns the disk usage using statfs.
+func diskUsage(path string) (Usage, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return Usage{}, &os.PathError{Op: "statfs", Path: path, Err: err}
+	}
+	bsize := uint64(st.Bsize)
+	total := uint64(st.Blocks) * bsize
+	return Usage{
+		Total: total,
+		Free:  uint64(st.Bavail) * bsize,
+		Used:  total - uint64(st.Bfree)*bsize,
+	}, nil
+}
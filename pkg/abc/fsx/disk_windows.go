@@ -0,0 +1,31 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build windows
+
+package fsx
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// diskUsage returns the disk usage using GetDiskFreeSpaceEx.
+func diskUsage(path string) (Usage, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return Usage{}, err
+	}
+	var avail, total, free uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &avail, &total, &free); err != nil {
+		return Usage{}, &os.PathError{
+			Op: "GetDiskFreeSpaceEx", Path: path, Err: err}
+	}
+	return Usage{
+		Total: total,
+		Free:  avail,
+		Used:  total - free,
+	}, nil
+}
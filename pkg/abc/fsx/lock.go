@@ -0,0 +1,52 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package fsx
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ErrLocked indicates a file locked by another process.
+var ErrLocked = errors.New("file is locked")
+
+// FileLock represents an exclusive lock held on a file.
+type FileLock struct {
+	file *os.File
+}
+
+// Lock acquires an exclusive non-blocking lock on the file at path,
+// creating the file and its parent dirs if not exist. It returns
+// [ErrLocked] if the lock is held by another process. The lock is
+// released on [FileLock.Unlock] or when the process exits.
+func Lock(path string) (*FileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o775); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o664)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &FileLock{file: f}, nil
+}
+
+// File returns the locked file.
+func (l *FileLock) File() *os.File {
+	return l.file
+}
+
+// Unlock releases the lock and closes the file.
+func (l *FileLock) Unlock() error {
+	err := unlockFile(l.file)
+	if e := l.file.Close(); err == nil {
+		err = e
+	}
+	return err
+}
@@ -0,0 +1,21 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package fsx
+
+import (
+	"os"
+	"syscall"
+)
+
+// chown sets the owner and group of path from the file info.
+func chown(path string, info os.FileInfo) error {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	return os.Lchown(path, int(st.Uid), int(st.Gid))
+}
@@ -0,0 +1,16 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build windows
+
+package fsx
+
+import (
+	"os"
+)
+
+// chown is not supported on windows.
+func chown(path string, info os.FileInfo) error {
+	return nil
+}
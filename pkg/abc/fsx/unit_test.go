@@ -7,7 +7,10 @@ package fsx_test
 import (
 	"os"
 	"path/filepath"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -106,6 +109,166 @@ func TestCopyDirToExistingDirectory(t *testing.T) {
 		"copied file content should match the original")
 }
 
+// makeTree creates the files in dir with their content.
+func makeTree(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o775))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o664))
+	}
+}
+
+func TestCopyDirFilters(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := filepath.Join(t.TempDir(), "dst")
+	makeTree(t, srcDir, map[string]string{
+		"fw.bin":          "1234",
+		"notes.txt":       "txt",
+		"sub/fw2.bin":     "56",
+		"sub/skip.bin":    "78",
+		"cache/cache.bin": "90",
+	})
+
+	var last fsx.CopyProgress
+	err := fsx.CopyDir(srcDir, dstDir,
+		fsx.Include("*.bin"),
+		fsx.Exclude("cache", "sub/skip.bin"),
+		fsx.Progress(func(p fsx.CopyProgress) { last = p }))
+	assert.NoError(t, err)
+
+	assert.True(t, fsx.IsExist(filepath.Join(dstDir, "fw.bin")))
+	assert.True(t, fsx.IsExist(filepath.Join(dstDir, "sub", "fw2.bin")))
+	assert.False(t, fsx.IsExist(filepath.Join(dstDir, "notes.txt")),
+		"not included file should be skipped")
+	assert.False(t, fsx.IsExist(filepath.Join(dstDir, "sub", "skip.bin")),
+		"excluded file should be skipped")
+	assert.False(t, fsx.IsExist(filepath.Join(dstDir, "cache")),
+		"excluded directory should be skipped")
+
+	assert.Equal(t, 2, last.Files)
+	assert.Equal(t, int64(6), last.Bytes)
+}
+
+func TestCopyDirDryRun(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := filepath.Join(t.TempDir(), "dst")
+	makeTree(t, srcDir, map[string]string{"a.txt": "a", "sub/b.txt": "bb"})
+
+	var paths []string
+	err := fsx.CopyDir(srcDir, dstDir, fsx.DryRun(),
+		fsx.Progress(func(p fsx.CopyProgress) { paths = append(paths, p.Path) }))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t,
+		[]string{"a.txt", filepath.Join("sub", "b.txt")}, paths)
+	assert.False(t, fsx.IsExist(dstDir), "dry run should not write")
+}
+
+func TestCopyPreserve(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := filepath.Join(t.TempDir(), "dst")
+	makeTree(t, srcDir, map[string]string{"sub/a.txt": "a"})
+
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	srcFile := filepath.Join(srcDir, "sub", "a.txt")
+	assert.NoError(t, os.Chmod(srcFile, 0o600))
+	assert.NoError(t, os.Chtimes(srcFile, mtime, mtime))
+	assert.NoError(t, os.Chtimes(filepath.Join(srcDir, "sub"), mtime, mtime))
+
+	err := fsx.CopyDir(srcDir, dstDir,
+		fsx.PreserveTimes(), fsx.PreserveMode(), fsx.PreserveOwner())
+	assert.NoError(t, err)
+
+	info, err := os.Stat(filepath.Join(dstDir, "sub", "a.txt"))
+	assert.NoError(t, err)
+	assert.True(t, mtime.Equal(info.ModTime()), "file mtime should match")
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+	info, err = os.Stat(filepath.Join(dstDir, "sub"))
+	assert.NoError(t, err)
+	assert.True(t, mtime.Equal(info.ModTime()), "dir mtime should match")
+
+	// single file copy
+	dstFile := filepath.Join(dstDir, "b.txt")
+	assert.NoError(t, fsx.Copy(srcFile, dstFile, fsx.PreserveTimes()))
+	info, err = os.Stat(dstFile)
+	assert.NoError(t, err)
+	assert.True(t, mtime.Equal(info.ModTime()))
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cfg.json")
+
+	assert.NoError(t, fsx.WriteFileAtomic(path, []byte("v1"), 0o600))
+	assert.NoError(t, fsx.WriteFileAtomic(path, []byte("v2"), 0o600))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", string(content))
+	if runtime.GOOS != "windows" {
+		info, _ := os.Stat(path)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+
+	// no temp files left behind
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	err = fsx.WriteFileAtomic(filepath.Join(dir, "none", "x"), nil, 0o600)
+	assert.Error(t, err, "should fail for missing parent directory")
+}
+
+func TestWriteFileAtomicMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes not supported on windows")
+	}
+	dir := t.TempDir()
+
+	// new files are created with umask applied like os.WriteFile
+	ref := filepath.Join(dir, "ref")
+	assert.NoError(t, os.WriteFile(ref, nil, 0o666))
+	refInfo, err := os.Stat(ref)
+	assert.NoError(t, err)
+	path := filepath.Join(dir, "cfg.json")
+	assert.NoError(t, fsx.WriteFileAtomic(path, []byte("v1"), 0o666))
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, refInfo.Mode().Perm(), info.Mode().Perm())
+
+	// existing files keep their mode
+	assert.NoError(t, os.Chmod(path, 0o600))
+	assert.NoError(t, fsx.WriteFileAtomic(path, []byte("v2"), 0o664))
+	info, err = os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	assert.NoError(t, os.Chmod(path, 0o640))
+	assert.NoError(t, fsx.WriteFileAtomic(path, []byte("v3"), 0o600))
+	info, err = os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "v3", string(content))
+}
+
+func TestReplaceFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "new.bin")
+	dst := filepath.Join(dir, "fw.bin")
+	assert.NoError(t, os.WriteFile(src, []byte("new"), 0o664))
+	assert.NoError(t, os.WriteFile(dst, []byte("old"), 0o664))
+
+	assert.NoError(t, fsx.ReplaceFile(src, dst))
+	content, err := os.ReadFile(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+	assert.False(t, fsx.IsExist(src), "source should be moved")
+
+	assert.Error(t, fsx.ReplaceFile(src, dst), "should fail for missing source")
+}
+
 func TestRemoveFile(t *testing.T) {
 	srcFile := filepath.Join(t.TempDir(), "srcfile.txt")
 	err := os.WriteFile(srcFile, []byte("test content"), 0o664)
@@ -149,3 +312,195 @@ func TestTouch(t *testing.T) {
 	assert.True(t, fsx.IsExist(srcFile),
 		"source file should exist after touch")
 }
+
+func TestLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lockdir", "file.lock")
+	l1, err := fsx.Lock(path)
+	assert.NoError(t, err, "should acquire lock")
+	assert.True(t, fsx.IsExist(path), "lock file should be created")
+
+	_, err = fsx.Lock(path)
+	assert.ErrorIs(t, err, fsx.ErrLocked,
+		"should fail to acquire held lock")
+
+	assert.NoError(t, l1.Unlock(), "should release lock")
+	l2, err := fsx.Lock(path)
+	assert.NoError(t, err, "should acquire released lock")
+	assert.NoError(t, l2.Unlock())
+}
+
+// collector gathers the reported watch events.
+type collector struct {
+	mu   sync.Mutex
+	evts map[string]fsx.WatchOp
+}
+
+func (c *collector) cb(evts []fsx.WatchEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, e := range evts {
+		c.evts[e.Path] |= e.Op
+	}
+}
+
+func (c *collector) has(path string, op fsx.WatchOp) func() bool {
+	return func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.evts[path]&op == op
+	}
+}
+
+func TestWatch(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []fsx.WatchOption
+	}{
+		{"native", nil},
+		{"polling", []fsx.WatchOption{fsx.WatchPolling(),
+			fsx.WatchInterval(20 * time.Millisecond)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			makeTree(t, dir, map[string]string{"old.txt": "x"})
+			c := &collector{evts: map[string]fsx.WatchOp{}}
+			opts := append([]fsx.WatchOption{fsx.WatchRecursive(),
+				fsx.WatchDebounce(10 * time.Millisecond)}, tc.opts...)
+			w, err := fsx.Watch(dir, fsx.WATCH_ALL, c.cb, opts...)
+			assert.NoError(t, err)
+			defer w.Close()
+
+			newFile := filepath.Join(dir, "new.txt")
+			assert.NoError(t, os.WriteFile(newFile, []byte("a"), 0o664))
+			assert.Eventually(t, c.has(newFile, fsx.WATCH_CREATE),
+				2*time.Second, 10*time.Millisecond)
+
+			// changes in directories created after watch started
+			subFile := filepath.Join(dir, "sub", "f.txt")
+			assert.NoError(t, os.MkdirAll(filepath.Dir(subFile), 0o775))
+			time.Sleep(50 * time.Millisecond)
+			assert.NoError(t, os.WriteFile(subFile, []byte("a"), 0o664))
+			assert.Eventually(t, c.has(subFile, fsx.WATCH_CREATE),
+				2*time.Second, 10*time.Millisecond)
+
+			oldFile := filepath.Join(dir, "old.txt")
+			assert.NoError(t, os.Remove(oldFile))
+			assert.Eventually(t, c.has(oldFile, fsx.WATCH_REMOVE),
+				2*time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestWatchFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cfg.json")
+	makeTree(t, dir, map[string]string{"cfg.json": "{}", "other": ""})
+
+	var mu sync.Mutex
+	var got []fsx.WatchEvent
+	w, err := fsx.Watch(path, fsx.WATCH_WRITE|fsx.WATCH_CREATE,
+		func(evts []fsx.WatchEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, evts...)
+		})
+	assert.NoError(t, err)
+	defer w.Close()
+
+	// atomic replace is reported, other files are ignored
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "other"), nil, 0o664))
+	assert.NoError(t, fsx.WriteFileAtomic(path, []byte("{1}"), 0o664))
+	assert.NoError(t, fsx.WriteFileAtomic(path, []byte("{2}"), 0o664))
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(got) > 0
+	}, 2*time.Second, 10*time.Millisecond)
+
+	time.Sleep(3 * fsx.WATCH_DEBOUNCE)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, got, 1, "burst should be collapsed")
+	assert.Equal(t, path, got[0].Path)
+
+	_, err = fsx.Watch(filepath.Join(dir, "none"), fsx.WATCH_ALL, nil)
+	assert.Error(t, err)
+}
+
+func TestDiskUsage(t *testing.T) {
+	u, err := fsx.DiskUsage(t.TempDir())
+	assert.NoError(t, err)
+	assert.Greater(t, u.Total, uint64(0))
+	assert.LessOrEqual(t, u.Free, u.Total)
+	assert.LessOrEqual(t, u.Used, u.Total)
+	assert.InDelta(t, float64(u.Used)*100/float64(u.Total),
+		u.UsedPercent(), 0.001)
+
+	_, err = fsx.DiskUsage(filepath.Join(t.TempDir(), "none"))
+	assert.Error(t, err)
+}
+
+func TestSpaceGuard(t *testing.T) {
+	dir := t.TempDir()
+	u, err := fsx.DiskUsage(dir)
+	assert.NoError(t, err)
+
+	g := fsx.NewSpaceGuard(dir, 0)
+	assert.NoError(t, g.Check(0))
+
+	// thresholds that cannot be satisfied run all cleanups
+	calls := 0
+	g = fsx.NewSpaceGuard(dir, 0)
+	g.OnLowSpace(func(fsx.Usage) { calls++ })
+	g.OnLowSpace(func(fsx.Usage) { calls++ })
+	assert.ErrorIs(t, g.Check(u.Total+1), fsx.ErrNoSpace)
+	assert.Equal(t, 2, calls)
+
+	g = fsx.NewSpaceGuard(dir, 0)
+	g.SetMinPercent(100)
+	if u.Free < u.Total {
+		assert.ErrorIs(t, g.Check(0), fsx.ErrNoSpace)
+	}
+}
+
+func TestWorkDir(t *testing.T) {
+	base := t.TempDir()
+	w, err := fsx.NewWorkDir(base, "app", 0o750)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(base, "app"), w.Path())
+	if runtime.GOOS != "windows" {
+		info, _ := os.Stat(w.Path())
+		assert.Equal(t, os.FileMode(0o750), info.Mode().Perm())
+	}
+
+	pid, err := w.WriteFile("app.pid", []byte("123"), 0o644)
+	assert.NoError(t, err)
+	assert.True(t, fsx.IsExist(pid))
+	run, err := w.Mkdir("run", 0o700)
+	assert.NoError(t, err)
+	assert.True(t, fsx.IsExist(run))
+	assert.Equal(t, filepath.Join(w.Path(), "app.sock"), w.Track("app.sock"))
+
+	// created directory is removed on close
+	assert.NoError(t, w.Close())
+	assert.False(t, fsx.IsExist(w.Path()))
+	assert.NoError(t, w.Close())
+
+	_, err = fsx.NewWorkDir(base, "../app", 0o750)
+	assert.Error(t, err)
+}
+
+func TestWorkDirExisting(t *testing.T) {
+	base := t.TempDir()
+	makeTree(t, base, map[string]string{"app/keep.txt": "x"})
+
+	w, err := fsx.NewWorkDir(base, "app", 0o755)
+	assert.NoError(t, err)
+	tmp, err := w.WriteFile("tmp.txt", nil, 0o644)
+	assert.NoError(t, err)
+
+	// only tracked entries are removed from existing directory
+	assert.NoError(t, w.Close())
+	assert.False(t, fsx.IsExist(tmp))
+	assert.True(t, fsx.IsExist(w.Join("keep.txt")))
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package fsx
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/sync/flowx"
+)
+
+// WatchOp defines the file system operations reported by [Watch].
+type WatchOp uint32
+
+const (
+	// WATCH_CREATE reports created or moved in entries.
+	WATCH_CREATE WatchOp = 1 << iota
+	// WATCH_WRITE reports modified file contents.
+	WATCH_WRITE
+	// WATCH_REMOVE reports removed entries.
+	WATCH_REMOVE
+	// WATCH_RENAME reports moved out entries.
+	WATCH_RENAME
+	// WATCH_CHMOD reports changed entry attributes.
+	WATCH_CHMOD
+
+	// WATCH_ALL reports all operations.
+	WATCH_ALL = WATCH_CREATE | WATCH_WRITE | WATCH_REMOVE |
+		WATCH_RENAME | WATCH_CHMOD
+)
+
+const (
+	// WATCH_DEBOUNCE defines the default quiet duration before reporting
+	// the collected events.
+	WATCH_DEBOUNCE = 100 * time.Millisecond
+	// WATCH_INTERVAL defines the default polling interval on platforms
+	// without native file system notifications.
+	WATCH_INTERVAL = time.Second
+)
+
+// String returns the names of operations set in op.
+func (op WatchOp) String() string {
+	var names []string
+	for _, v := range []struct {
+		op   WatchOp
+		name string
+	}{
+		{WATCH_CREATE, "CREATE"}, {WATCH_WRITE, "WRITE"},
+		{WATCH_REMOVE, "REMOVE"}, {WATCH_RENAME, "RENAME"},
+		{WATCH_CHMOD, "CHMOD"},
+	} {
+		if op&v.op != 0 {
+			names = append(names, v.name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// WatchEvent represents a reported file system change.
+type WatchEvent struct {
+	// Path is the changed entry path.
+	Path string
+	// Op holds the operations collected for path.
+	Op WatchOp
+}
+
+// String returns a string representation of the event.
+func (e WatchEvent) String() string {
+	return fmt.Sprintf("%s: %s", e.Op, e.Path)
+}
+
+// WatchOption defines the watch options used with [Watch].
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	recursive bool
+	polling   bool
+	debounce  time.Duration
+	interval  time.Duration
+}
+
+// WatchRecursive watches the sub directories of path, including the
+// directories created after the watch started.
+func WatchRecursive() WatchOption {
+	return func(o *watchOptions) {
+		o.recursive = true
+	}
+}
+
+// WatchDebounce sets the quiet duration before reporting the collected
+// events, use 0 to report each event immediately. (default is WATCH_DEBOUNCE)
+func WatchDebounce(d time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		o.debounce = d
+	}
+}
+
+// WatchPolling forces detecting changes by polling, for file systems
+// without native notifications support such as network mounts.
+func WatchPolling() WatchOption {
+	return func(o *watchOptions) {
+		o.polling = true
+	}
+}
+
+// WatchInterval sets the polling interval used on platforms without native
+// file system notifications or with polling forced. (default is WATCH_INTERVAL)
+func WatchInterval(d time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		if d > 0 {
+			o.interval = d
+		}
+	}
+}
+
+// watchBackend defines the platform specific changes notifier.
+type watchBackend interface {
+	close()
+}
+
+// Watcher reports the file system changes of a watched path.
+type Watcher struct {
+	// path is the watched path.
+	path string
+	// ops defines the reported operations.
+	ops WatchOp
+	// cb is the events callback.
+	cb func([]WatchEvent)
+
+	// backend is the running changes notifier.
+	backend watchBackend
+	// debouncer collapses events bursts.
+	debouncer *flowx.Debouncer
+
+	// pending holds the collected events by path in arrival order.
+	pending []WatchEvent
+	index   map[string]int
+	// closed indicates that no more events are reported.
+	closed bool
+	// mu defines mutex for watcher state.
+	mu sync.Mutex
+	// cbMutex serializes the callback calls.
+	cbMutex sync.Mutex
+}
+
+// Watch starts watching path, a file or a directory, and calls cb with the
+// collected events of the ops operations. Events bursts are collapsed per
+// path until no more events arrive for the debounce duration.
+// It uses inotify on linux, and falls back to polling on other platforms.
+//
+// Native notifications are only supported on linux. On other platforms,
+// including darwin, BSD and windows, changes are detected by polling the
+// watched tree every WATCH_INTERVAL, see [WatchInterval]. Polling costs a
+// full tree scan per interval, reports changes with up to one interval of
+// delay, and misses changes reverted within an interval.
+func Watch(path string, ops WatchOp, cb func([]WatchEvent),
+	opts ...WatchOption) (*Watcher, error) {
+	o := &watchOptions{debounce: WATCH_DEBOUNCE, interval: WATCH_INTERVAL}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	path = filepath.Clean(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		path:  path,
+		ops:   ops,
+		cb:    cb,
+		index: map[string]int{},
+	}
+	if o.debounce > 0 {
+		w.debouncer = flowx.Debounce(w.flush, o.debounce)
+	}
+	if o.polling {
+		w.backend = newPollBackend(path, info.IsDir(), o, w.emit)
+	} else if w.backend, err = newWatchBackend(
+		path, info.IsDir(), o, w.emit); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Path returns the watched path.
+func (w *Watcher) Path() string {
+	return w.path
+}
+
+// emit collects an event from backend.
+func (w *Watcher) emit(e WatchEvent) {
+	if e.Op&w.ops == 0 {
+		return
+	}
+	e.Op &= w.ops
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	if i, ok := w.index[e.Path]; ok {
+		w.pending[i].Op |= e.Op
+	} else {
+		w.index[e.Path] = len(w.pending)
+		w.pending = append(w.pending, e)
+	}
+	w.mu.Unlock()
+
+	if w.debouncer != nil {
+		w.debouncer.Call()
+	} else {
+		w.flush()
+	}
+}
+
+// flush reports the collected events.
+func (w *Watcher) flush() {
+	w.cbMutex.Lock()
+	defer w.cbMutex.Unlock()
+
+	w.mu.Lock()
+	evts := w.pending
+	w.pending = nil
+	w.index = map[string]int{}
+	closed := w.closed
+	w.mu.Unlock()
+
+	if len(evts) > 0 && !closed {
+		w.cb(evts)
+	}
+}
+
+// Close stops watching, the pending events are dropped.
+func (w *Watcher) Close() {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	w.backend.close()
+	if w.debouncer != nil {
+		w.debouncer.Stop()
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build linux
+
+package fsx
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// inotifyMask defines the inotify events watched for directories.
+const inotifyMask = unix.IN_CREATE | unix.IN_MODIFY | unix.IN_CLOSE_WRITE |
+	unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_ATTRIB
+
+// inotifyBackend reports changes from inotify events.
+type inotifyBackend struct {
+	// name is the watched file name, empty when watching a directory.
+	name      string
+	recursive bool
+	emit      func(WatchEvent)
+
+	// fd is the inotify instance descriptor, and f its file used for
+	// polled reads which are interrupted on close.
+	fd int
+	f  *os.File
+	// dirs holds the watched directories by watch descriptor.
+	dirs map[int32]string
+	mu   sync.Mutex
+
+	done chan struct{}
+}
+
+// newWatchBackend creates an inotify changes notifier. A watched file is
+// tracked through its parent directory, so it survives being replaced.
+func newWatchBackend(path string, isDir bool, o *watchOptions,
+	emit func(WatchEvent)) (watchBackend, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+
+	b := &inotifyBackend{
+		recursive: isDir && o.recursive,
+		emit:      emit,
+		fd:        fd,
+		f:         os.NewFile(uintptr(fd), "inotify"),
+		dirs:      map[int32]string{},
+		done:      make(chan struct{}),
+	}
+	dir := path
+	if !isDir {
+		dir, b.name = filepath.Split(path)
+		dir = filepath.Clean(dir)
+	}
+	if err := b.addDir(dir); err != nil {
+		b.f.Close()
+		return nil, err
+	}
+
+	go b.run()
+	return b, nil
+}
+
+// addDir adds an inotify watch for dir, and its sub directories if
+// recursive.
+func (b *inotifyBackend) addDir(dir string) error {
+	if !b.recursive {
+		return b.addWatch(dir)
+	}
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// ignore entries removed while walking
+			if p != dir && os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		return b.addWatch(p)
+	})
+}
+
+func (b *inotifyBackend) addWatch(dir string) error {
+	wd, err := unix.InotifyAddWatch(b.fd, dir, inotifyMask)
+	if err != nil {
+		return &os.PathError{Op: "inotify_add_watch", Path: dir, Err: err}
+	}
+	b.mu.Lock()
+	b.dirs[int32(wd)] = dir
+	b.mu.Unlock()
+	return nil
+}
+
+// run reads the inotify events until closed.
+func (b *inotifyBackend) run() {
+	defer close(b.done)
+
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		n, err := b.f.Read(buf)
+		if err != nil {
+			return
+		}
+		for off := 0; off+unix.SizeofInotifyEvent <= n; {
+			ev := (*unix.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			off += unix.SizeofInotifyEvent
+			name := buf[off : off+int(ev.Len)]
+			if i := bytes.IndexByte(name, 0); i >= 0 {
+				name = name[:i]
+			}
+			off += int(ev.Len)
+			b.handle(ev, string(name))
+		}
+	}
+}
+
+// handle converts an inotify event and emits it.
+func (b *inotifyBackend) handle(ev *unix.InotifyEvent, name string) {
+	if ev.Mask&unix.IN_IGNORED != 0 {
+		b.mu.Lock()
+		delete(b.dirs, ev.Wd)
+		b.mu.Unlock()
+		return
+	}
+	if name == "" || (b.name != "" && name != b.name) {
+		return
+	}
+
+	b.mu.Lock()
+	dir, ok := b.dirs[ev.Wd]
+	b.mu.Unlock()
+	if !ok {
+		return
+	}
+	path := filepath.Join(dir, name)
+
+	var op WatchOp
+	if ev.Mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0 {
+		op |= WATCH_CREATE
+		// watch the new sub directories
+		if b.recursive && ev.Mask&unix.IN_ISDIR != 0 {
+			b.addDir(path)
+		}
+	}
+	if ev.Mask&(unix.IN_MODIFY|unix.IN_CLOSE_WRITE) != 0 {
+		op |= WATCH_WRITE
+	}
+	if ev.Mask&unix.IN_DELETE != 0 {
+		op |= WATCH_REMOVE
+	}
+	if ev.Mask&unix.IN_MOVED_FROM != 0 {
+		op |= WATCH_RENAME
+	}
+	if ev.Mask&unix.IN_ATTRIB != 0 {
+		op |= WATCH_CHMOD
+	}
+	if op != 0 {
+		b.emit(WatchEvent{Path: path, Op: op})
+	}
+}
+
+func (b *inotifyBackend) close() {
+	b.f.Close()
+	<-b.done
+}
@@ -0,0 +1,14 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !linux
+
+package fsx
+
+// newWatchBackend creates a polling changes notifier. Native kqueue and
+// ReadDirectoryChangesW notifications are not implemented.
+func newWatchBackend(path string, isDir bool, o *watchOptions,
+	emit func(WatchEvent)) (watchBackend, error) {
+	return newPollBackend(path, isDir, o, emit), nil
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package fsx
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// entryState identifies a version of a file system entry.
+type entryState struct {
+	modTime time.Time
+	size    int64
+	mode    os.FileMode
+}
+
+// pollBackend detects changes by comparing periodic snapshots.
+type pollBackend struct {
+	path      string
+	isDir     bool
+	recursive bool
+	emit      func(WatchEvent)
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newPollBackend(path string, isDir bool, o *watchOptions,
+	emit func(WatchEvent)) *pollBackend {
+	b := &pollBackend{
+		path:      path,
+		isDir:     isDir,
+		recursive: o.recursive,
+		emit:      emit,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go b.run(b.snapshot(), o.interval)
+	return b
+}
+
+// snapshot returns the current states of the watched entries.
+func (b *pollBackend) snapshot() map[string]entryState {
+	res := map[string]entryState{}
+	add := func(p string, info fs.FileInfo) {
+		res[p] = entryState{
+			modTime: info.ModTime(), size: info.Size(), mode: info.Mode()}
+	}
+
+	if !b.isDir {
+		if info, err := os.Stat(b.path); err == nil {
+			add(b.path, info)
+		}
+		return res
+	}
+	filepath.WalkDir(b.path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == b.path {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			add(p, info)
+		}
+		if d.IsDir() && !b.recursive {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return res
+}
+
+// run polls the watched entries and emits the detected changes.
+func (b *pollBackend) run(last map[string]entryState, interval time.Duration) {
+	defer close(b.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+		}
+
+		cur := b.snapshot()
+		for p, st := range cur {
+			old, ok := last[p]
+			switch {
+			case !ok:
+				b.emit(WatchEvent{Path: p, Op: WATCH_CREATE})
+			case old.mode != st.mode:
+				b.emit(WatchEvent{Path: p, Op: WATCH_CHMOD})
+			case !old.modTime.Equal(st.modTime) || old.size != st.size:
+				if !st.mode.IsDir() {
+					b.emit(WatchEvent{Path: p, Op: WATCH_WRITE})
+				}
+			}
+		}
+		for p := range last {
+			if _, ok := cur[p]; !ok {
+				b.emit(WatchEvent{Path: p, Op: WATCH_REMOVE})
+			}
+		}
+		last = cur
+	}
+}
+
+func (b *pollBackend) close() {
+	close(b.stop)
+	<-b.done
+}
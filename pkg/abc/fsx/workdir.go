@@ -0,0 +1,131 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package fsx
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// WorkDir manages a namespaced runtime directory holding entries such as
+// pipes, sockets and pid files. It tracks the created entries and removes
+// them all on Close.
+type WorkDir struct {
+	// path is the work directory path.
+	path string
+	// created indicates the directory was created by the WorkDir, so it
+	// is removed with its contents on close.
+	created bool
+
+	// entries holds the tracked entries paths in creation order.
+	entries []string
+	// closed indicates the work directory is closed.
+	closed bool
+	// mu defines mutex for work directory state.
+	mu sync.Mutex
+}
+
+// NewWorkDir creates the work directory name under base with mode perm,
+// regardless of the process umask. The system temp directory is used if
+// base is empty. An existing directory is reused and kept on close.
+func NewWorkDir(base, name string, perm os.FileMode) (*WorkDir, error) {
+	if base == "" {
+		base = os.TempDir()
+	}
+	if name == "" || name != filepath.Base(name) {
+		return nil, errors.New("invalid work directory name")
+	}
+
+	w := &WorkDir{path: filepath.Join(base, name)}
+	info, err := os.Stat(w.path)
+	switch {
+	case err == nil && !info.IsDir():
+		return nil, errors.New("work directory path is not a directory")
+	case os.IsNotExist(err):
+		if err := os.MkdirAll(w.path, perm); err != nil {
+			return nil, err
+		}
+		w.created = true
+	case err != nil:
+		return nil, err
+	}
+	if err := os.Chmod(w.path, perm); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Path returns the work directory path.
+func (w *WorkDir) Path() string {
+	return w.path
+}
+
+// Join returns the path of name in the work directory, without tracking.
+func (w *WorkDir) Join(name ...string) string {
+	return filepath.Join(append([]string{w.path}, name...)...)
+}
+
+// Track returns the path of name in the work directory and tracks it for
+// removal on close. It is used for entries created by other components,
+// such as sockets and named pipes.
+func (w *WorkDir) Track(name string) string {
+	path := w.Join(name)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.entries = append(w.entries, path)
+	return path
+}
+
+// Mkdir creates the tracked sub directory name with mode perm,
+// regardless of the process umask.
+func (w *WorkDir) Mkdir(name string, perm os.FileMode) (string, error) {
+	path := w.Join(name)
+	if err := os.MkdirAll(path, perm); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(path, perm); err != nil {
+		return "", err
+	}
+	return w.Track(name), nil
+}
+
+// WriteFile writes the tracked file name atomically with mode perm,
+// see [WriteFileAtomic].
+func (w *WorkDir) WriteFile(name string, data []byte, perm os.FileMode) (string, error) {
+	if err := WriteFileAtomic(w.Join(name), data, perm); err != nil {
+		return "", err
+	}
+	return w.Track(name), nil
+}
+
+// Close removes the tracked entries in reverse creation order, and the
+// work directory itself if it was created by the WorkDir.
+func (w *WorkDir) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	var errs []error
+	if w.created {
+		if err := os.RemoveAll(w.path); err != nil {
+			errs = append(errs, err)
+		}
+	} else {
+		for i := len(w.entries) - 1; i >= 0; i-- {
+			if err := os.RemoveAll(w.entries[i]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	w.entries = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,20 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build windows
+
+package fsx
+
+import (
+	"errors"
+	"syscall"
+)
+
+// ERROR_NOT_SAME_DEVICE windows error code.
+const errNotSameDevice = syscall.Errno(17)
+
+// isCrossDevice checks if a rename failed across devices.
+func isCrossDevice(err error) bool {
+	return errors.Is(err, errNotSameDevice)
+}
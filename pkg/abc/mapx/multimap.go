@@ -0,0 +1,60 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package mapx
+
+// MultiMap is a map holding multiple values per key, where values of
+// each key keep their insertion order. Iteration order over keys is
+// not defined.
+type MultiMap[K comparable, V any] map[K][]V
+
+// Add appends values to a key.
+func (m MultiMap[K, V]) Add(key K, values ...V) {
+	m[key] = append(m[key], values...)
+}
+
+// Get returns the values of a key, or nil if not found.
+func (m MultiMap[K, V]) Get(key K) []V {
+	return m[key]
+}
+
+// Has checks if a key exists in the map.
+func (m MultiMap[K, V]) Has(key K) bool {
+	_, ok := m[key]
+	return ok
+}
+
+// Delete removes a key and all its values.
+func (m MultiMap[K, V]) Delete(key K) {
+	delete(m, key)
+}
+
+// RemoveFunc removes the values of a key for which fn returns true,
+// deleting the key if no values remain.
+func (m MultiMap[K, V]) RemoveFunc(key K, fn func(V) bool) {
+	values, ok := m[key]
+	if !ok {
+		return
+	}
+	res := values[:0]
+	for _, v := range values {
+		if !fn(v) {
+			res = append(res, v)
+		}
+	}
+	if len(res) == 0 {
+		delete(m, key)
+	} else {
+		m[key] = res
+	}
+}
+
+// Count returns the total number of values of all keys.
+func (m MultiMap[K, V]) Count() int {
+	n := 0
+	for _, values := range m {
+		n += len(values)
+	}
+	return n
+}
@@ -42,3 +42,23 @@ func TestFind(t *testing.T) {
 	assert.False(t, found, "Value '4' should not be found")
 	assert.Equal(t, "", keyStr, "The key should be an empty string for a non-found value")
 }
+
+func TestMultiMap(t *testing.T) {
+	m := mapx.MultiMap[string, int]{}
+	m.Add("a", 1, 2)
+	m.Add("a", 3)
+	m.Add("b", 4)
+	assert.Equal(t, []int{1, 2, 3}, m.Get("a"))
+	assert.Nil(t, m.Get("c"))
+	assert.True(t, m.Has("b"))
+	assert.Equal(t, 2, len(m))
+	assert.Equal(t, 4, m.Count())
+
+	m.RemoveFunc("a", func(v int) bool { return v%2 == 1 })
+	assert.Equal(t, []int{2}, m.Get("a"))
+	m.RemoveFunc("b", func(v int) bool { return true })
+	assert.False(t, m.Has("b"))
+
+	m.Delete("a")
+	assert.Equal(t, 0, m.Count())
+}
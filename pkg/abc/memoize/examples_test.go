@@ -0,0 +1,46 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package memoize_test
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/memoize"
+)
+
+func ExampleFunc() {
+	calls := 0
+	lookup := func(name string) (string, error) {
+		calls++
+		return strings.ToUpper(name), nil
+	}
+
+	// cache results for 1 minute, keeping at most 100 values
+	cached := memoize.Func(lookup, time.Minute, 100)
+	v1, _ := cached("host")
+	v2, _ := cached("host")
+
+	fmt.Println(v1, v2, calls)
+	// Output:
+	// HOST HOST 1
+}
+
+func ExampleFunc0() {
+	calls := 0
+	uptime := func() (int, error) {
+		calls++
+		return 42, nil
+	}
+
+	cached := memoize.Func0(uptime, time.Minute)
+	v1, _ := cached()
+	v2, _ := cached()
+
+	fmt.Println(v1, v2, calls)
+	// Output:
+	// 42 42 1
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package memoize
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// entry represents a cached value.
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// call represents an in-flight getter call shared by concurrent callers.
+type call[V any] struct {
+	wg    sync.WaitGroup
+	value V
+	err   error
+}
+
+// Cache caches the results of an expensive getter function by key, with
+// expiry time, size limit and deduplication of concurrent calls for the
+// same key. Failed calls are not cached.
+type Cache[K comparable, V any] struct {
+	// fn is the cached getter function.
+	fn func(K) (V, error)
+	// ttl defines the cached values expiry duration, 0 for no expiry.
+	ttl time.Duration
+	// maxSize defines the max number of cached values, 0 for no limit.
+	// the least recently used values are evicted first.
+	maxSize int
+
+	// entries holds the cached values by key.
+	entries map[K]*list.Element
+	// lru holds the cached values in recently used order.
+	lru *list.List
+	// calls holds the in-flight calls by key.
+	calls map[K]*call[V]
+	// mu defines mutex for cache state.
+	mu sync.Mutex
+}
+
+// New creates a new cache for getter function fn, where values expire
+// after ttl and at most maxSize values are kept.
+// use ttl=0 to disable expiry and maxSize=0 to disable size limit.
+func New[K comparable, V any](
+	fn func(K) (V, error), ttl time.Duration, maxSize int) *Cache[K, V] {
+	return &Cache[K, V]{
+		fn:      fn,
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: map[K]*list.Element{},
+		lru:     list.New(),
+		calls:   map[K]*call[V]{},
+	}
+}
+
+// Get returns the cached value for key, or calls the getter function if
+// no valid value is cached. Concurrent calls for the same key wait for
+// a single getter call and share its result.
+func (c *Cache[K, V]) Get(key K) (V, error) {
+	c.mu.Lock()
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*entry[K, V])
+		if e.expires.IsZero() || time.Now().Before(e.expires) {
+			c.lru.MoveToFront(el)
+			c.mu.Unlock()
+			return e.value, nil
+		}
+		c.remove(el)
+	}
+	if cl, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		cl.wg.Wait()
+		return cl.value, cl.err
+	}
+	cl := &call[V]{}
+	cl.wg.Add(1)
+	c.calls[key] = cl
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+		cl.wg.Done()
+	}()
+
+	cl.value, cl.err = c.fn(key)
+	if cl.err == nil {
+		c.set(key, cl.value)
+	}
+	return cl.value, cl.err
+}
+
+// set adds a value to cache, evicting the least recently used values
+// when size limit is exceeded.
+func (c *Cache[K, V]) set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := &entry[K, V]{key: key, value: value}
+	if c.ttl > 0 {
+		e.expires = time.Now().Add(c.ttl)
+	}
+	if el, ok := c.entries[key]; ok {
+		el.Value = e
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	for c.maxSize > 0 && c.lru.Len() > c.maxSize {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove deletes a cached value. requires lock to be held.
+func (c *Cache[K, V]) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*entry[K, V]).key)
+}
+
+// Invalidate removes the cached value for key.
+func (c *Cache[K, V]) Invalidate(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+}
+
+// Purge removes all cached values.
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[K]*list.Element{}
+	c.lru.Init()
+}
+
+// Len returns the number of cached values, including expired values
+// not yet removed.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Func returns a caching decorator for getter function fn with key
+// argument, see [New].
+//
+//	lookup := memoize.Func(net.LookupHost, time.Minute, 100)
+//	addrs, err := lookup("example.com")
+func Func[K comparable, V any](
+	fn func(K) (V, error), ttl time.Duration, maxSize int) func(K) (V, error) {
+	return New(fn, ttl, maxSize).Get
+}
+
+// Func0 returns a caching decorator for getter function fn without
+// arguments, see [New].
+//
+//	hostname := memoize.Func0(os.Hostname, time.Minute)
+//	name, err := hostname()
+func Func0[V any](fn func() (V, error), ttl time.Duration) func() (V, error) {
+	c := New(func(struct{}) (V, error) { return fn() }, ttl, 0)
+	return func() (V, error) { return c.Get(struct{}{}) }
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package memoize_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/abc/memoize"
+)
+
+func TestTTL(t *testing.T) {
+	var calls atomic.Int32
+	c := memoize.New(func(k int) (int, error) {
+		calls.Add(1)
+		return k * 2, nil
+	}, 50*time.Millisecond, 0)
+
+	v, err := c.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+	c.Get(1)
+	assert.Equal(t, int32(1), calls.Load())
+
+	time.Sleep(60 * time.Millisecond)
+	c.Get(1)
+	assert.Equal(t, int32(2), calls.Load())
+
+	c.Invalidate(1)
+	c.Get(1)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestErrors(t *testing.T) {
+	calls := 0
+	c := memoize.New(func(k string) (string, error) {
+		calls++
+		return "", errors.New("failed")
+	}, 0, 0)
+
+	_, err := c.Get("a")
+	assert.Error(t, err)
+	_, err = c.Get("a")
+	assert.Error(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestMaxSize(t *testing.T) {
+	calls := map[int]int{}
+	c := memoize.New(func(k int) (int, error) {
+		calls[k]++
+		return k, nil
+	}, 0, 2)
+
+	c.Get(1)
+	c.Get(2)
+	c.Get(1) // 1 most recently used
+	c.Get(3) // evicts 2
+	assert.Equal(t, 2, c.Len())
+
+	c.Get(1)
+	c.Get(2)
+	assert.Equal(t, map[int]int{1: 1, 2: 2, 3: 1}, calls)
+
+	c.Purge()
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestSingleFlight(t *testing.T) {
+	var calls atomic.Int32
+	c := memoize.New(func(k int) (int, error) {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return k, nil
+	}, time.Minute, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.Get(7)
+			assert.NoError(t, err)
+			assert.Equal(t, 7, v)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+}
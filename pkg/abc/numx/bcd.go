@@ -0,0 +1,78 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package numx
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrBCD is returned for invalid BCD digits or values not fitting
+// in the requested size.
+var ErrBCD = errors.New("invalid BCD value")
+
+// BCD converts a packed BCD byte slice, with 2 decimal digits per byte
+// and the most significant digits first, to a uint64 number.
+// Up to 19 digits are supported.
+func BCD(b []byte) (uint64, error) {
+	if len(b) > 10 || (len(b) == 10 && b[0]>>4 != 0) {
+		return 0, fmt.Errorf("%w: too many digits", ErrBCD)
+	}
+	var val uint64
+	for _, c := range b {
+		hi, lo := c>>4, c&0x0F
+		if hi > 9 || lo > 9 {
+			return 0, fmt.Errorf("%w: byte 0x%02X", ErrBCD, c)
+		}
+		val = val*100 + uint64(hi)*10 + uint64(lo)
+	}
+	return val, nil
+}
+
+// ToBCD converts a uint64 number into a packed BCD byte slice with
+// the most significant digits first. The result is zero padded to
+// size bytes, or uses the minimal number of bytes if size is zero.
+// An error is returned if the number does not fit in size bytes.
+func ToBCD(n uint64, size int) ([]byte, error) {
+	var b []byte
+	for n > 0 || len(b) == 0 {
+		b = append(b, byte(n%100/10)<<4|byte(n%10))
+		n /= 100
+	}
+	if size > 0 {
+		if len(b) > size {
+			return nil, fmt.Errorf("%w: exceeds %d bytes", ErrBCD, size)
+		}
+		b = append(b, make([]byte, size-len(b))...)
+	}
+	return Reverse(b), nil
+}
+
+// SwapNibbles returns a copy of the byte slice with the high and low
+// nibbles of each byte swapped.
+func SwapNibbles(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i, c := range b {
+		r[i] = c<<4 | c>>4
+	}
+	return r
+}
+
+// BCDSwapped converts a packed BCD byte slice with swapped nibbles,
+// where the low nibble of each byte holds the higher digit, to a
+// uint64 number.
+func BCDSwapped(b []byte) (uint64, error) {
+	return BCD(SwapNibbles(b))
+}
+
+// ToBCDSwapped converts a uint64 number into a packed BCD byte slice
+// with swapped nibbles, padded to size bytes as in ToBCD.
+func ToBCDSwapped(n uint64, size int) ([]byte, error) {
+	b, err := ToBCD(n, size)
+	if err != nil {
+		return nil, err
+	}
+	return SwapNibbles(b), nil
+}
@@ -45,3 +45,15 @@ func ExampleQ8() {
 	fmt.Printf("%x\n", numx.Q8(n))
 	// Output: ffffffffffffffff
 }
+
+func ExampleF32LE() {
+	b := []byte{0x00, 0x00, 0xC0, 0x3F}
+	fmt.Println(numx.F32LE(b))
+	// Output: 1.5
+}
+
+func ExampleBCD() {
+	n, _ := numx.BCD([]byte{0x12, 0x34, 0x56})
+	fmt.Println(n)
+	// Output: 123456
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package numx
+
+import "math"
+
+// Reverse returns a copy of the byte slice in reversed order,
+// converting between big-endian and little-endian byte order.
+func Reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i, c := range b {
+		r[len(b)-1-i] = c
+	}
+	return r
+}
+
+// F64 converts a big-endian IEEE-754 byte slice to a float64 number.
+// It processes up to the first 8 bytes of the slice.
+func F64(b []byte) float64 {
+	return math.Float64frombits(U64(b))
+}
+
+// F32 converts a big-endian IEEE-754 byte slice to a float32 number.
+// It processes up to the first 4 bytes of the slice.
+func F32(b []byte) float32 {
+	return math.Float32frombits(U32(b))
+}
+
+// F64LE converts a little-endian IEEE-754 byte slice to a float64 number.
+// It processes up to the first 8 bytes of the slice.
+func F64LE(b []byte) float64 {
+	return F64(Reverse(b[:minNum(len(b), 8)]))
+}
+
+// F32LE converts a little-endian IEEE-754 byte slice to a float32 number.
+// It processes up to the first 4 bytes of the slice.
+func F32LE(b []byte) float32 {
+	return F32(Reverse(b[:minNum(len(b), 4)]))
+}
+
+// BF64 converts a float64 number into a big-endian IEEE-754 byte slice
+// of length 8.
+func BF64(f float64) []byte {
+	return B8(math.Float64bits(f))
+}
+
+// BF32 converts a float32 number into a big-endian IEEE-754 byte slice
+// of length 4.
+func BF32(f float32) []byte {
+	return B4(math.Float32bits(f))
+}
+
+// BF64LE converts a float64 number into a little-endian IEEE-754 byte
+// slice of length 8.
+func BF64LE(f float64) []byte {
+	return Reverse(BF64(f))
+}
+
+// BF32LE converts a float32 number into a little-endian IEEE-754 byte
+// slice of length 4.
+func BF32LE(f float32) []byte {
+	return Reverse(BF32(f))
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package numx_test
+
+import (
+	"testing"
+
+	"github.com/exonlabs/go-utils/pkg/abc/numx"
+)
+
+func FuzzUnsigned(f *testing.F) {
+	f.Add(uint64(0))
+	f.Add(uint64(1))
+	f.Add(uint64(1<<64 - 1))
+	f.Add(uint64(0x0102030405060708))
+	f.Fuzz(func(t *testing.T, n uint64) {
+		if v := numx.U64(numx.B8(n)); v != n {
+			t.Fatalf("U64/B8 mismatch: %d != %d", v, n)
+		}
+		if v := numx.U32(numx.B4(uint32(n))); v != uint32(n) {
+			t.Fatalf("U32/B4 mismatch: %d != %d", v, uint32(n))
+		}
+		if v := numx.U16(numx.B2(uint16(n))); v != uint16(n) {
+			t.Fatalf("U16/B2 mismatch: %d != %d", v, uint16(n))
+		}
+		if v := numx.U8(numx.B1(uint8(n))); v != uint8(n) {
+			t.Fatalf("U8/B1 mismatch: %d != %d", v, uint8(n))
+		}
+	})
+}
+
+func FuzzSigned(f *testing.F) {
+	f.Add(int64(0))
+	f.Add(int64(-1))
+	f.Add(int64(-1 << 63))
+	f.Add(int64(1<<63 - 1))
+	f.Fuzz(func(t *testing.T, n int64) {
+		if v := numx.I64(numx.Q8(n)); v != n {
+			t.Fatalf("I64/Q8 mismatch: %d != %d", v, n)
+		}
+		if v := numx.I32(numx.Q4(int32(n))); v != int32(n) {
+			t.Fatalf("I32/Q4 mismatch: %d != %d", v, int32(n))
+		}
+		if v := numx.I16(numx.Q2(int16(n))); v != int16(n) {
+			t.Fatalf("I16/Q2 mismatch: %d != %d", v, int16(n))
+		}
+		if v := numx.I8(numx.Q1(int8(n))); v != int8(n) {
+			t.Fatalf("I8/Q1 mismatch: %d != %d", v, int8(n))
+		}
+	})
+}
+
+func FuzzBytes(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0x80})
+	f.Add([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		numx.U64(b)
+		numx.U32(b)
+		numx.U16(b)
+		numx.U8(b)
+		numx.I64(b)
+		numx.I32(b)
+		numx.I16(b)
+		numx.I8(b)
+		numx.F64(b)
+		numx.F32LE(b)
+		if n, err := numx.BCD(b); err == nil && len(b) > 0 {
+			if v, err := numx.ToBCD(n, len(b)); err != nil || string(v) != string(b) {
+				t.Fatalf("BCD/ToBCD mismatch: %x != %x", v, b)
+			}
+		}
+	})
+}
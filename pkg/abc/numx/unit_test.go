@@ -5,6 +5,7 @@
 package numx_test
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -148,3 +149,56 @@ func TestQ1(t *testing.T) {
 	assert.Equal(t, []byte{0x01},
 		numx.Q1(0x01))
 }
+
+func TestFloat(t *testing.T) {
+	assert.Equal(t, []byte{0x40, 0x09, 0x21, 0xFB, 0x54, 0x44, 0x2D, 0x18},
+		numx.BF64(math.Pi))
+	assert.Equal(t, []byte{0x18, 0x2D, 0x44, 0x54, 0xFB, 0x21, 0x09, 0x40},
+		numx.BF64LE(math.Pi))
+	assert.Equal(t, []byte{0x3F, 0xC0, 0x00, 0x00}, numx.BF32(1.5))
+	assert.Equal(t, []byte{0x00, 0x00, 0xC0, 0x3F}, numx.BF32LE(1.5))
+
+	assert.Equal(t, math.Pi, numx.F64(numx.BF64(math.Pi)))
+	assert.Equal(t, math.Pi, numx.F64LE(numx.BF64LE(math.Pi)))
+	assert.Equal(t, float32(-2.25), numx.F32(numx.BF32(-2.25)))
+	assert.Equal(t, float32(-2.25), numx.F32LE(numx.BF32LE(-2.25)))
+	assert.Equal(t, float32(1.5), numx.F32LE([]byte{0x00, 0x00, 0xC0, 0x3F, 0xFF}),
+		"Extra bytes should be ignored")
+	assert.True(t, math.IsNaN(numx.F64(numx.BF64(math.NaN()))))
+	assert.Equal(t, 0.0, numx.F64([]byte{}), "Empty slice should return 0")
+}
+
+func TestBCD(t *testing.T) {
+	n, err := numx.BCD([]byte{0x12, 0x34, 0x56})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(123456), n)
+	_, err = numx.BCD([]byte{0x99, 0x99, 0x99, 0x99, 0x99, 0x99, 0x99, 0x99, 0x99, 0x09})
+	assert.ErrorIs(t, err, numx.ErrBCD)
+	n, err = numx.BCD([]byte{0x09, 0x99, 0x99, 0x99, 0x99, 0x99, 0x99, 0x99, 0x99, 0x99})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(9999999999999999999), n)
+	_, err = numx.BCD([]byte{0x1A})
+	assert.ErrorIs(t, err, numx.ErrBCD)
+
+	b, err := numx.ToBCD(123456, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x12, 0x34, 0x56}, b)
+	b, err = numx.ToBCD(1234, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00, 0x00, 0x12, 0x34}, b)
+	b, err = numx.ToBCD(0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00}, b)
+	_, err = numx.ToBCD(123456, 2)
+	assert.ErrorIs(t, err, numx.ErrBCD)
+
+	assert.Equal(t, []byte{0x21, 0x43}, numx.SwapNibbles([]byte{0x12, 0x34}))
+	b, err = numx.ToBCDSwapped(1234, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00, 0x21, 0x43}, b)
+	n, err = numx.BCDSwapped(b)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1234), n)
+	_, err = numx.BCDSwapped([]byte{0xA1})
+	assert.ErrorIs(t, err, numx.ErrBCD)
+}
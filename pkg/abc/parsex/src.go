@@ -0,0 +1,200 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package parsex
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration units added to the time package units.
+const (
+	DAY  = 24 * time.Hour
+	WEEK = 7 * DAY
+)
+
+// Size units, decimal SI and binary IEC multiples of bytes.
+const (
+	KB = 1000
+	MB = 1000 * KB
+	GB = 1000 * MB
+	TB = 1000 * GB
+
+	KiB = 1024
+	MiB = 1024 * KiB
+	GiB = 1024 * MiB
+	TiB = 1024 * GiB
+)
+
+var boolValues = map[string]bool{
+	"1": true, "t": true, "true": true, "y": true, "yes": true,
+	"on": true, "enable": true, "enabled": true,
+	"0": false, "f": false, "false": false, "n": false, "no": false,
+	"off": false, "disable": false, "disabled": false,
+}
+
+// ParseBool parses a boolean string, case insensitive, accepting
+// 1/t/true/y/yes/on/enable/enabled and 0/f/false/n/no/off/disable/disabled.
+func ParseBool(s string) (bool, error) {
+	if b, ok := boolValues[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return b, nil
+	}
+	return false, fmt.Errorf("invalid boolean value %q", s)
+}
+
+// ParseDuration parses a duration string like time.ParseDuration, with
+// additional "d" days and "w" weeks units, such as "1d12h" or "2w".
+// A number without units is parsed as seconds.
+func ParseDuration(s string) (time.Duration, error) {
+	orig := s
+	s = strings.TrimSpace(s)
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		if math.Abs(f) > math.MaxInt64/float64(time.Second) {
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+		return time.Duration(f * float64(time.Second)), nil
+	}
+
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	if s == "" {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+
+	var total float64
+	for s != "" {
+		// number part
+		i := 0
+		for i < len(s) && (s[i] == '.' || (s[i] >= '0' && s[i] <= '9')) {
+			i++
+		}
+		num := s[:i]
+		// unit part
+		j := i
+		for j < len(s) && (s[j] < '0' || s[j] > '9') && s[j] != '.' {
+			j++
+		}
+		unit := s[i:j]
+		s = s[j:]
+
+		var d float64
+		switch unit {
+		case "d", "w":
+			f, err := strconv.ParseFloat(num, 64)
+			if err != nil || num == "" {
+				return 0, fmt.Errorf("invalid duration %q", orig)
+			}
+			d = f * float64(DAY)
+			if unit == "w" {
+				d = f * float64(WEEK)
+			}
+		default:
+			v, err := time.ParseDuration(num + unit)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", orig)
+			}
+			d = float64(v)
+		}
+		total += d
+	}
+	if total > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+	if neg {
+		total = -total
+	}
+	return time.Duration(total), nil
+}
+
+// FormatDuration formats a duration like time.Duration.String, with a
+// days unit and without trailing zero units, such as "1d2h" or "1h30m".
+func FormatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+	sign := ""
+	if d < 0 {
+		// the min duration has no positive counterpart
+		if d == math.MinInt64 {
+			return d.String()
+		}
+		sign, d = "-", -d
+	}
+	s := ""
+	if days := d / DAY; days > 0 {
+		s = strconv.FormatInt(int64(days), 10) + "d"
+		d -= days * DAY
+	}
+	if d > 0 {
+		rest := d.String()
+		if d >= time.Minute {
+			rest = strings.TrimSuffix(rest, "m0s")
+			if strings.HasSuffix(rest, "h0") {
+				rest = strings.TrimSuffix(rest, "0") // "1h0" to "1h"
+			} else if !strings.HasSuffix(rest, "s") {
+				rest += "m"
+			}
+		}
+		s += rest
+	}
+	return sign + s
+}
+
+var sizeUnits = map[string]uint64{
+	"": 1, "b": 1,
+	"k": KiB, "kb": KB, "kib": KiB,
+	"m": MiB, "mb": MB, "mib": MiB,
+	"g": GiB, "gb": GB, "gib": GiB,
+	"t": TiB, "tb": TB, "tib": TiB,
+}
+
+// ParseSize parses a size string in bytes, case insensitive, with
+// optional decimal units KB/MB/GB/TB or binary units KiB/MiB/GiB/TiB.
+// Single letter units K/M/G/T are binary, such as "10M" or "1.5GiB".
+func ParseSize(s string) (uint64, error) {
+	t := strings.TrimSpace(s)
+	i := len(t)
+	for i > 0 && (t[i-1] < '0' || t[i-1] > '9') && t[i-1] != '.' {
+		i--
+	}
+	mult, ok := sizeUnits[strings.ToLower(strings.TrimSpace(t[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit %q", s)
+	}
+	num := strings.TrimSpace(t[:i])
+	if n, err := strconv.ParseUint(num, 10, 64); err == nil {
+		if n > math.MaxUint64/mult {
+			return 0, fmt.Errorf("invalid size %q", s)
+		}
+		return n * mult, nil
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || f < 0 || f*float64(mult) >= math.MaxUint64 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return uint64(math.Round(f * float64(mult))), nil
+}
+
+// FormatSize formats a size in bytes using the largest binary unit
+// with up to one decimal digit, such as "512B", "10MiB" or "1.5GiB".
+func FormatSize(n uint64) string {
+	units := []struct {
+		name string
+		size uint64
+	}{{"TiB", TiB}, {"GiB", GiB}, {"MiB", MiB}, {"KiB", KiB}}
+	for _, u := range units {
+		if n >= u.size {
+			v := strconv.FormatFloat(float64(n)/float64(u.size), 'f', 1, 64)
+			return strings.TrimSuffix(v, ".0") + u.name
+		}
+	}
+	return strconv.FormatUint(n, 10) + "B"
+}
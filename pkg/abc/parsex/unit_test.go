@@ -0,0 +1,113 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package parsex_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/abc/parsex"
+)
+
+func TestParseBool(t *testing.T) {
+	for _, s := range []string{"1", "true", "Yes", " ON ", "enabled", "y"} {
+		b, err := parsex.ParseBool(s)
+		assert.Nil(t, err, s)
+		assert.True(t, b, s)
+	}
+	for _, s := range []string{"0", "False", "no", "off", "Disabled", "n"} {
+		b, err := parsex.ParseBool(s)
+		assert.Nil(t, err, s)
+		assert.False(t, b, s)
+	}
+	_, err := parsex.ParseBool("maybe")
+	assert.NotNil(t, err)
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"1h30m":   90 * time.Minute,
+		"1d12h":   36 * time.Hour,
+		"2w":      14 * 24 * time.Hour,
+		"1.5d":    36 * time.Hour,
+		"-1d1s":   -(24*time.Hour + time.Second),
+		"90":      90 * time.Second,
+		"0.5":     500 * time.Millisecond,
+		"250ms":   250 * time.Millisecond,
+		" 10s ":   10 * time.Second,
+		"1w2d3h4": 0,
+	}
+	for s, expected := range tests {
+		d, err := parsex.ParseDuration(s)
+		if expected == 0 {
+			assert.NotNil(t, err, s)
+			continue
+		}
+		assert.Nil(t, err, s)
+		assert.Equal(t, expected, d, s)
+	}
+	for _, s := range []string{"", "-", "d", "1x", "1e300"} {
+		_, err := parsex.ParseDuration(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                              "0s",
+		90 * time.Minute:               "1h30m",
+		2 * time.Hour:                  "2h",
+		10 * time.Minute:               "10m",
+		36 * time.Hour:                 "1d12h",
+		48 * time.Hour:                 "2d",
+		time.Hour + 5*time.Second:      "1h0m5s",
+		1500 * time.Millisecond:        "1.5s",
+		-(24*time.Hour + time.Minute):  "-1d1m",
+		24*time.Hour + 10*time.Hour:    "1d10h",
+		3*time.Hour + 20*time.Minute:   "3h20m",
+		250 * time.Millisecond:         "250ms",
+		time.Minute + 30*time.Second:   "1m30s",
+		100*time.Hour + 10*time.Minute: "4d4h10m",
+	}
+	for d, expected := range tests {
+		assert.Equal(t, expected, parsex.FormatDuration(d))
+		back, err := parsex.ParseDuration(expected)
+		assert.Nil(t, err, expected)
+		assert.Equal(t, d, back, expected)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]uint64{
+		"512":     512,
+		"512B":    512,
+		"10MiB":   10 * parsex.MiB,
+		"10 MB":   10 * parsex.MB,
+		"10m":     10 * parsex.MiB,
+		"1.5GiB":  3 * parsex.GiB / 2,
+		"2kb":     2000,
+		"1TiB":    parsex.TiB,
+		" 4 KiB ": 4096,
+	}
+	for s, expected := range tests {
+		n, err := parsex.ParseSize(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, expected, n, s)
+	}
+	for _, s := range []string{"", "MB", "10XB", "-1", "1.2.3K", "99999999999TiB"} {
+		_, err := parsex.ParseSize(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "0B", parsex.FormatSize(0))
+	assert.Equal(t, "512B", parsex.FormatSize(512))
+	assert.Equal(t, "10MiB", parsex.FormatSize(10*parsex.MiB))
+	assert.Equal(t, "1.5GiB", parsex.FormatSize(3*parsex.GiB/2))
+	assert.Equal(t, "1.2KiB", parsex.FormatSize(1234))
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package setx_test
+
+import (
+	"fmt"
+
+	"github.com/exonlabs/go-utils/pkg/abc/setx"
+)
+
+func ExampleSet() {
+	a := setx.New("read", "write")
+	b := setx.New("write", "admin")
+
+	fmt.Println(setx.Sorted(a.Union(b)))
+	fmt.Println(setx.Sorted(a.Intersection(b)))
+	fmt.Println(setx.Sorted(a.Difference(b)))
+	// Output:
+	// [admin read write]
+	// [write]
+	// [read]
+}
+
+func ExampleOrderedSet() {
+	s := setx.NewOrdered("eth1", "eth0", "eth1", "wlan0")
+	s.Remove("eth0")
+	fmt.Println(s.Items())
+	// Output:
+	// [eth1 wlan0]
+}
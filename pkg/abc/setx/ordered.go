@@ -0,0 +1,98 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package setx
+
+// OrderedSet is a collection of unique values preserving insertion
+// order. Results of set operations keep the order of the receiver
+// items followed by the other set items.
+// The zero value is not usable, use NewOrdered to create.
+type OrderedSet[T comparable] struct {
+	items []T
+	index map[T]int
+}
+
+// NewOrdered creates a new ordered set holding the given items,
+// ignoring duplicates.
+func NewOrdered[T comparable](items ...T) *OrderedSet[T] {
+	s := &OrderedSet[T]{index: make(map[T]int, len(items))}
+	s.Add(items...)
+	return s
+}
+
+// Add appends items not already in the set.
+func (s *OrderedSet[T]) Add(items ...T) {
+	for _, v := range items {
+		if _, ok := s.index[v]; !ok {
+			s.index[v] = len(s.items)
+			s.items = append(s.items, v)
+		}
+	}
+}
+
+// Remove removes items from the set if they exist, keeping the order
+// of remaining items.
+func (s *OrderedSet[T]) Remove(items ...T) {
+	for _, v := range items {
+		n, ok := s.index[v]
+		if !ok {
+			continue
+		}
+		delete(s.index, v)
+		s.items = append(s.items[:n], s.items[n+1:]...)
+		for i := n; i < len(s.items); i++ {
+			s.index[s.items[i]] = i
+		}
+	}
+}
+
+// Has checks if an item exists in the set.
+func (s *OrderedSet[T]) Has(item T) bool {
+	_, ok := s.index[item]
+	return ok
+}
+
+// Len returns the number of items in the set.
+func (s *OrderedSet[T]) Len() int {
+	return len(s.items)
+}
+
+// Items returns the set items in insertion order.
+func (s *OrderedSet[T]) Items() []T {
+	return append([]T(nil), s.items...)
+}
+
+// Clone returns a copy of the set.
+func (s *OrderedSet[T]) Clone() *OrderedSet[T] {
+	return NewOrdered(s.items...)
+}
+
+// Union returns a new set with the items of both sets.
+func (s *OrderedSet[T]) Union(other *OrderedSet[T]) *OrderedSet[T] {
+	res := s.Clone()
+	res.Add(other.items...)
+	return res
+}
+
+// Intersection returns a new set with the items present in both sets.
+func (s *OrderedSet[T]) Intersection(other *OrderedSet[T]) *OrderedSet[T] {
+	res := NewOrdered[T]()
+	for _, v := range s.items {
+		if other.Has(v) {
+			res.Add(v)
+		}
+	}
+	return res
+}
+
+// Difference returns a new set with the items not present in other.
+func (s *OrderedSet[T]) Difference(other *OrderedSet[T]) *OrderedSet[T] {
+	res := NewOrdered[T]()
+	for _, v := range s.items {
+		if !other.Has(v) {
+			res.Add(v)
+		}
+	}
+	return res
+}
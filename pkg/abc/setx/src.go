@@ -0,0 +1,119 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package setx
+
+import (
+	"sort"
+
+	"github.com/exonlabs/go-utils/pkg/abc/gx"
+)
+
+// Set is an unordered collection of unique values.
+// Iteration order over the set items is not defined, use Sorted
+// for a stable order.
+type Set[T comparable] map[T]struct{}
+
+// New creates a new set holding the given items.
+func New[T comparable](items ...T) Set[T] {
+	s := make(Set[T], len(items))
+	s.Add(items...)
+	return s
+}
+
+// Add adds items to the set.
+func (s Set[T]) Add(items ...T) {
+	for _, v := range items {
+		s[v] = struct{}{}
+	}
+}
+
+// Remove removes items from the set if they exist.
+func (s Set[T]) Remove(items ...T) {
+	for _, v := range items {
+		delete(s, v)
+	}
+}
+
+// Has checks if an item exists in the set.
+func (s Set[T]) Has(item T) bool {
+	_, ok := s[item]
+	return ok
+}
+
+// Len returns the number of items in the set.
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+// Items returns the set items in undefined order.
+func (s Set[T]) Items() []T {
+	res := make([]T, 0, len(s))
+	for v := range s {
+		res = append(res, v)
+	}
+	return res
+}
+
+// Clone returns a copy of the set.
+func (s Set[T]) Clone() Set[T] {
+	res := make(Set[T], len(s))
+	for v := range s {
+		res[v] = struct{}{}
+	}
+	return res
+}
+
+// Union returns a new set with the items of both sets.
+func (s Set[T]) Union(other Set[T]) Set[T] {
+	res := s.Clone()
+	for v := range other {
+		res[v] = struct{}{}
+	}
+	return res
+}
+
+// Intersection returns a new set with the items present in both sets.
+func (s Set[T]) Intersection(other Set[T]) Set[T] {
+	res := Set[T]{}
+	for v := range s {
+		if other.Has(v) {
+			res[v] = struct{}{}
+		}
+	}
+	return res
+}
+
+// Difference returns a new set with the items not present in other.
+func (s Set[T]) Difference(other Set[T]) Set[T] {
+	res := Set[T]{}
+	for v := range s {
+		if !other.Has(v) {
+			res[v] = struct{}{}
+		}
+	}
+	return res
+}
+
+// IsSubset checks if all items of the set exist in other.
+func (s Set[T]) IsSubset(other Set[T]) bool {
+	for v := range s {
+		if !other.Has(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal checks if both sets hold the same items.
+func (s Set[T]) Equal(other Set[T]) bool {
+	return len(s) == len(other) && s.IsSubset(other)
+}
+
+// Sorted returns the set items in ascending order.
+func Sorted[T gx.Ordered](s Set[T]) []T {
+	res := s.Items()
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
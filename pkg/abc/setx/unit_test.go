@@ -0,0 +1,63 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package setx_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/abc/setx"
+)
+
+func TestSet(t *testing.T) {
+	s := setx.New(1, 2, 3, 2)
+	assert.Equal(t, 3, s.Len())
+	assert.True(t, s.Has(2))
+	assert.False(t, s.Has(4))
+
+	s.Add(4)
+	s.Remove(1, 9)
+	assert.Equal(t, []int{2, 3, 4}, setx.Sorted(s))
+
+	other := setx.New(3, 4, 5)
+	assert.Equal(t, []int{2, 3, 4, 5}, setx.Sorted(s.Union(other)))
+	assert.Equal(t, []int{3, 4}, setx.Sorted(s.Intersection(other)))
+	assert.Equal(t, []int{2}, setx.Sorted(s.Difference(other)))
+	assert.Equal(t, []int{2, 3, 4}, setx.Sorted(s), "operations must not modify the set")
+
+	assert.True(t, setx.New(3, 4).IsSubset(s))
+	assert.False(t, other.IsSubset(s))
+	assert.True(t, s.Equal(setx.New(4, 3, 2)))
+	assert.False(t, s.Equal(other))
+
+	c := s.Clone()
+	c.Add(10)
+	assert.False(t, s.Has(10))
+	assert.ElementsMatch(t, []int{2, 3, 4}, s.Items())
+}
+
+func TestOrderedSet(t *testing.T) {
+	s := setx.NewOrdered("c", "a", "b", "a")
+	assert.Equal(t, []string{"c", "a", "b"}, s.Items())
+	assert.Equal(t, 3, s.Len())
+
+	s.Add("d", "c")
+	s.Remove("a", "x")
+	assert.Equal(t, []string{"c", "b", "d"}, s.Items())
+	assert.True(t, s.Has("d"))
+	assert.False(t, s.Has("a"))
+
+	other := setx.NewOrdered("e", "d", "c")
+	assert.Equal(t, []string{"c", "b", "d", "e"}, s.Union(other).Items())
+	assert.Equal(t, []string{"c", "d"}, s.Intersection(other).Items())
+	assert.Equal(t, []string{"b"}, s.Difference(other).Items())
+
+	c := s.Clone()
+	c.Remove("c")
+	c.Add("c")
+	assert.Equal(t, []string{"b", "d", "c"}, c.Items())
+	assert.Equal(t, []string{"c", "b", "d"}, s.Items())
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/logging"
+)
+
+// Build attributes set at build time using linker flags, if not set the
+// values are loaded from the embedded module and VCS build information.
+//
+//	go build -ldflags "\
+//	  -X github.com/exonlabs/go-utils/pkg/buildinfo.Version=1.0.0 \
+//	  -X github.com/exonlabs/go-utils/pkg/buildinfo.GitCommit=$(git rev-parse HEAD) \
+//	  -X github.com/exonlabs/go-utils/pkg/buildinfo.BuildDate=$(date -u +%FT%TZ)"
+var (
+	Version   = ""
+	GitCommit = ""
+	BuildDate = ""
+)
+
+// Info represents the application build information.
+type Info struct {
+	Name      string // Application name
+	Version   string // Application version
+	GitCommit string // VCS commit revision
+	BuildDate string // Build date
+	Modified  bool   // VCS tree had local modifications at build time
+	GoVersion string // Go version used for build
+	Platform  string // Target OS and architecture
+}
+
+// New creates the build information for the named application.
+func New(name string) *Info {
+	i := &Info{
+		Name:      name,
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	// fill missing values from embedded build information
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if i.Version == "" && bi.Main.Version != "(devel)" {
+			i.Version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if i.GitCommit == "" {
+					i.GitCommit = s.Value
+				}
+			case "vcs.time":
+				if i.BuildDate == "" {
+					i.BuildDate = s.Value
+				}
+			case "vcs.modified":
+				i.Modified = s.Value == "true"
+			}
+		}
+	}
+
+	if i.Version == "" {
+		i.Version = "devel"
+	}
+	return i
+}
+
+// ShortCommit returns the abbreviated VCS commit revision.
+func (i *Info) ShortCommit() string {
+	if len(i.GitCommit) > 8 {
+		return i.GitCommit[:8]
+	}
+	return i.GitCommit
+}
+
+// String returns a one line representation of the build information.
+//
+//	myapp 1.0.0 (commit 0123abcd, built 2006-01-02T15:04:05Z) go1.20 linux/amd64
+func (i *Info) String() string {
+	attrs := []string{}
+	if c := i.ShortCommit(); c != "" {
+		if i.Modified {
+			c += "-dirty"
+		}
+		attrs = append(attrs, "commit "+c)
+	}
+	if i.BuildDate != "" {
+		attrs = append(attrs, "built "+i.BuildDate)
+	}
+
+	s := strings.TrimSpace(i.Name + " " + i.Version)
+	if len(attrs) > 0 {
+		s += " (" + strings.Join(attrs, ", ") + ")"
+	}
+	return fmt.Sprintf("%s %s %s", s, i.GoVersion, i.Platform)
+}
+
+// Banner returns the standard multi-line startup banner.
+func (i *Info) Banner() string {
+	lines := []string{
+		fmt.Sprintf("%s %s", i.Name, i.Version),
+	}
+	if i.GitCommit != "" {
+		c := i.GitCommit
+		if i.Modified {
+			c += " (modified)"
+		}
+		lines = append(lines, "  commit:   "+c)
+	}
+	if i.BuildDate != "" {
+		lines = append(lines, "  built:    "+i.BuildDate)
+	}
+	lines = append(lines,
+		"  go:       "+i.GoVersion,
+		"  platform: "+i.Platform)
+	return strings.Join(lines, "\n")
+}
+
+// Dict returns the build information as dictionary, to use in status replies.
+func (i *Info) Dict() dictx.Dict {
+	return dictx.Dict{
+		"name":       i.Name,
+		"version":    i.Version,
+		"git_commit": i.GitCommit,
+		"build_date": i.BuildDate,
+		"modified":   i.Modified,
+		"go_version": i.GoVersion,
+		"platform":   i.Platform,
+	}
+}
+
+// LogBanner writes the startup banner lines to logger with Info level.
+func (i *Info) LogBanner(log *logging.Logger) {
+	if log == nil {
+		return
+	}
+	for _, line := range strings.Split(i.Banner(), "\n") {
+		log.Info("%s", line)
+	}
+}
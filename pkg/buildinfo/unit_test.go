@@ -0,0 +1,61 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package buildinfo_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/buildinfo"
+)
+
+func TestNew(t *testing.T) {
+	buildinfo.Version = "1.2.3"
+	buildinfo.GitCommit = "0123456789abcdef"
+	buildinfo.BuildDate = "2024-01-02T03:04:05Z"
+	defer func() {
+		buildinfo.Version = ""
+		buildinfo.GitCommit = ""
+		buildinfo.BuildDate = ""
+	}()
+
+	i := buildinfo.New("myapp")
+	assert.Equal(t, "myapp", i.Name)
+	assert.Equal(t, "1.2.3", i.Version)
+	assert.Equal(t, "0123456789abcdef", i.GitCommit)
+	assert.Equal(t, "01234567", i.ShortCommit())
+	assert.Equal(t, "2024-01-02T03:04:05Z", i.BuildDate)
+	assert.Equal(t, runtime.Version(), i.GoVersion)
+	assert.Equal(t, runtime.GOOS+"/"+runtime.GOARCH, i.Platform)
+}
+
+func TestFormatting(t *testing.T) {
+	i := &buildinfo.Info{
+		Name:      "myapp",
+		Version:   "1.2.3",
+		GitCommit: "0123456789abcdef",
+		BuildDate: "2024-01-02T03:04:05Z",
+		GoVersion: "go1.20",
+		Platform:  "linux/amd64",
+	}
+	assert.Equal(t, "myapp 1.2.3 (commit 01234567, "+
+		"built 2024-01-02T03:04:05Z) go1.20 linux/amd64", i.String())
+	assert.Equal(t, "myapp 1.2.3\n"+
+		"  commit:   0123456789abcdef\n"+
+		"  built:    2024-01-02T03:04:05Z\n"+
+		"  go:       go1.20\n"+
+		"  platform: linux/amd64", i.Banner())
+
+	i.Modified = true
+	assert.Contains(t, i.String(), "commit 01234567-dirty")
+	assert.Equal(t, true, i.Dict()["modified"])
+	assert.Equal(t, "1.2.3", i.Dict()["version"])
+
+	i = &buildinfo.Info{Name: "myapp", Version: "devel",
+		GoVersion: "go1.20", Platform: "linux/amd64"}
+	assert.Equal(t, "myapp devel go1.20 linux/amd64", i.String())
+}
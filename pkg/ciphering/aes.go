@@ -5,13 +5,11 @@
 package ciphering
 
 import (
-	"crypto/aes"
 	"crypto/cipher"
-	"crypto/rand"
 	"crypto/sha256"
 	"crypto/sha512"
-	"errors"
-	"io"
+
+	"github.com/exonlabs/go-utils/pkg/crypto/cipherx"
 )
 
 // Handler defines the contract for encryption and decryption methods.
@@ -30,30 +28,13 @@ type aesHandler struct {
 // Encrypt encrypts the input data using AES-GCM.
 // It generates a nonce, encrypts the data, and prepends the nonce to the output.
 func (h *aesHandler) Encrypt(b []byte) ([]byte, error) {
-	if len(b) == 0 {
-		return nil, errors.New("input data cannot be empty")
-	}
-	nonce := make([]byte, h.aead.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, err
-	}
-	ciphertext := h.aead.Seal(nil, nonce, b, h.aad)
-	return append(nonce, ciphertext...), nil
+	return cipherx.SealGCM(h.aead, b, h.aad)
 }
 
 // Decrypt decrypts the input data using AES-GCM.
 // It extracts the nonce from the input and decrypts the data.
 func (h *aesHandler) Decrypt(b []byte) ([]byte, error) {
-	nonceSize := h.aead.NonceSize()
-	if len(b) <= nonceSize {
-		return nil, errors.New("input data is too short")
-	}
-	nonce, ciphertext := b[:nonceSize], b[nonceSize:]
-	plaintext, err := h.aead.Open(nil, nonce, ciphertext, h.aad)
-	if err != nil {
-		return nil, err
-	}
-	return plaintext, nil
+	return cipherx.OpenGCM(h.aead, b, h.aad)
 }
 
 // AES128 provides AES encryption with a 128-bit key.
@@ -65,11 +46,7 @@ type AES128 struct {
 // The secret is hashed with SHA-256 to derive a 128-bit key and AAD.
 func NewAES128(secret string) (*AES128, error) {
 	key := sha256.Sum256([]byte(secret))
-	block, err := aes.NewCipher(key[len(key)/2:]) // AES-128, 128-bit key
-	if err != nil {
-		return nil, err
-	}
-	aead, err := cipher.NewGCM(block)
+	aead, err := cipherx.NewGCM(key[len(key)/2:]) // AES-128, 128-bit key
 	if err != nil {
 		return nil, err
 	}
@@ -90,11 +67,7 @@ type AES256 struct {
 // The secret is hashed with SHA-512 to derive a 256-bit key and AAD.
 func NewAES256(secret string) (*AES256, error) {
 	key := sha512.Sum512([]byte(secret))
-	block, err := aes.NewCipher(key[len(key)/2:]) // AES-256, 256-bit key
-	if err != nil {
-		return nil, err
-	}
-	aead, err := cipher.NewGCM(block)
+	aead, err := cipherx.NewGCM(key[len(key)/2:]) // AES-256, 256-bit key
 	if err != nil {
 		return nil, err
 	}
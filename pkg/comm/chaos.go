@@ -0,0 +1,198 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package comm
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ChaosConfig defines the fault injection settings for ChaosConnection.
+// Rates are probabilities in range [0, 1] applied on each data frame.
+type ChaosConfig struct {
+	// Send enables faults on transmitted frames.
+	Send bool
+	// Recv enables faults on received frames.
+	Recv bool
+
+	// DropRate defines the probability of dropping a frame.
+	DropRate float64
+	// DelayRate defines the probability of delaying a frame.
+	DelayRate float64
+	// DuplicateRate defines the probability of duplicating a frame.
+	DuplicateRate float64
+	// CorruptRate defines the probability of corrupting a random byte in frame.
+	CorruptRate float64
+	// TruncateRate defines the probability of truncating a frame.
+	TruncateRate float64
+
+	// MinDelay defines the min delay duration in seconds.
+	MinDelay float64
+	// MaxDelay defines the max delay duration in seconds.
+	MaxDelay float64
+
+	// Seed defines the random generator seed for reproducible faults.
+	// use 0 to seed with current time.
+	Seed int64
+}
+
+// chaosFrame holds a duplicated received frame.
+type chaosFrame struct {
+	data []byte
+	addr any
+}
+
+// ChaosConnection wraps a Connection and injects faults on transmitted
+// and received data frames, used for testing protocols robustness.
+type ChaosConnection struct {
+	Connection
+
+	// Config defines the fault injection settings.
+	Config ChaosConfig
+
+	// pending holds duplicated received frames.
+	pending []chaosFrame
+	// rnd is the faults random generator.
+	rnd *rand.Rand
+	// mu defines mutex for random generator and pending frames.
+	mu sync.Mutex
+}
+
+// NewChaosConnection creates a new fault injection wrapper for connection.
+func NewChaosConnection(conn Connection, cfg ChaosConfig) *ChaosConnection {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &ChaosConnection{
+		Connection: conn,
+		Config:     cfg,
+		rnd:        rand.New(rand.NewSource(seed)),
+	}
+}
+
+// chance returns true with the given probability.
+func (c *ChaosConnection) chance(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rnd.Float64() < rate
+}
+
+// intn returns a random number in range [0, n).
+func (c *ChaosConnection) intn(n int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rnd.Intn(n)
+}
+
+// delay sleeps for a random duration between min and max delay.
+func (c *ChaosConnection) delay() {
+	if !c.chance(c.Config.DelayRate) {
+		return
+	}
+	d := c.Config.MinDelay
+	if c.Config.MaxDelay > d {
+		c.mu.Lock()
+		d += c.rnd.Float64() * (c.Config.MaxDelay - d)
+		c.mu.Unlock()
+	}
+	time.Sleep(time.Duration(d * float64(time.Second)))
+}
+
+// mutate applies corruption and truncation faults on a copy of data.
+func (c *ChaosConnection) mutate(data []byte) []byte {
+	b := make([]byte, len(data))
+	copy(b, data)
+	if len(b) > 0 && c.chance(c.Config.CorruptRate) {
+		i := c.intn(len(b))
+		b[i] ^= byte(1 + c.intn(255))
+	}
+	if len(b) > 1 && c.chance(c.Config.TruncateRate) {
+		b = b[:1+c.intn(len(b)-1)]
+	}
+	return b
+}
+
+// Send transmits data over the connection with faults injection.
+func (c *ChaosConnection) Send(data []byte, timeout float64) error {
+	return c.SendTo(data, nil, timeout)
+}
+
+// SendTo transmits data to addr over the connection with faults injection.
+func (c *ChaosConnection) SendTo(data []byte, addr any, timeout float64) error {
+	if !c.Config.Send {
+		return c.Connection.SendTo(data, addr, timeout)
+	}
+
+	if c.chance(c.Config.DropRate) {
+		return nil
+	}
+	c.delay()
+	b := c.mutate(data)
+	if err := c.Connection.SendTo(b, addr, timeout); err != nil {
+		return err
+	}
+	if c.chance(c.Config.DuplicateRate) {
+		return c.Connection.SendTo(b, addr, timeout)
+	}
+	return nil
+}
+
+// Recv receives data over the connection with faults injection.
+func (c *ChaosConnection) Recv(timeout float64) ([]byte, error) {
+	b, _, err := c.RecvFrom(timeout)
+	return b, err
+}
+
+// RecvFrom receives data from addr over the connection with faults injection.
+func (c *ChaosConnection) RecvFrom(timeout float64) ([]byte, any, error) {
+	if !c.Config.Recv {
+		return c.Connection.RecvFrom(timeout)
+	}
+
+	c.mu.Lock()
+	if len(c.pending) > 0 {
+		f := c.pending[0]
+		c.pending = c.pending[1:]
+		c.mu.Unlock()
+		return f.data, f.addr, nil
+	}
+	c.mu.Unlock()
+
+	var tBreak time.Time
+	if timeout > 0 {
+		tBreak = time.Now().Add(time.Duration(timeout * float64(time.Second)))
+	}
+
+	for {
+		data, addr, err := c.Connection.RecvFrom(timeout)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !c.chance(c.Config.DropRate) {
+			c.delay()
+			b := c.mutate(data)
+			if c.chance(c.Config.DuplicateRate) {
+				c.mu.Lock()
+				c.pending = append(c.pending, chaosFrame{b, addr})
+				c.mu.Unlock()
+			}
+			return b, addr, nil
+		}
+
+		// adjust remaining timeout after dropping frame
+		if timeout > 0 {
+			timeout = time.Until(tBreak).Seconds()
+			if timeout <= 0 {
+				return nil, nil, ErrTimeout
+			}
+		}
+	}
+}
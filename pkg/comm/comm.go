@@ -60,3 +60,18 @@ type Listener interface {
 	// ConnectionHandler sets a callback function to handle incoming connections.
 	ConnectionHandler(func(conn Connection))
 }
+
+// GracefulListener defines the listeners supporting graceful stop, where
+// the active connection handlers are notified to finish their work before
+// the listener is terminated, see [IsDraining].
+type GracefulListener interface {
+	Listener
+
+	// StopGraceful stops accepting new connections and notifies the active
+	// connection handlers to finish, waiting up to timeout seconds before
+	// terminating the listener and closing all connections.
+	StopGraceful(timeout float64)
+
+	// IsDraining checks if a graceful stop is in progress.
+	IsDraining() bool
+}
@@ -10,15 +10,34 @@ import (
 
 	"github.com/exonlabs/go-utils/pkg/abc/dictx"
 	"github.com/exonlabs/go-utils/pkg/comm"
+	"github.com/exonlabs/go-utils/pkg/comm/memcomm"
 	"github.com/exonlabs/go-utils/pkg/comm/netcomm"
 	"github.com/exonlabs/go-utils/pkg/comm/serialcomm"
 	"github.com/exonlabs/go-utils/pkg/comm/sockcomm"
+	"github.com/exonlabs/go-utils/pkg/comm/sshcomm"
 	"github.com/exonlabs/go-utils/pkg/logging"
 )
 
 // NewConnection creates a new Connection based on the provided URI prefix.
-// It supports different connection types (e.g., tcp, udp, sock, serial)
+// It supports different connection types (e.g., tcp, udp, sock, serial, mem, ssh)
+// The connection is wrapped with heartbeat handling if the heartbeat_interval
+// option is set, see [comm.NewHeartbeatConnection], and with a sending queue
+// if the send_queue_size option is set, see [comm.NewSendQueueConnection].
 func NewConnection(uri string, log *logging.Logger, opts dictx.Dict) (comm.Connection, error) {
+	conn, err := newConnection(uri, log, opts)
+	if err != nil {
+		return nil, err
+	}
+	if comm.IsSendQueueEnabled(opts) {
+		conn = comm.NewSendQueueConnection(conn, opts)
+	}
+	if comm.IsHeartbeatEnabled(opts) {
+		conn = comm.NewHeartbeatConnection(conn, opts)
+	}
+	return conn, nil
+}
+
+func newConnection(uri string, log *logging.Logger, opts dictx.Dict) (comm.Connection, error) {
 	if uri == "" {
 		return nil, errors.New("uri should not be empty")
 	}
@@ -32,13 +51,17 @@ func NewConnection(uri string, log *logging.Logger, opts dictx.Dict) (comm.Conne
 		return sockcomm.NewConnection(uri, log, opts)
 	case "serial":
 		return serialcomm.NewConnection(uri, log, opts)
+	case "mem":
+		return memcomm.NewConnection(uri, log, opts)
+	case "ssh":
+		return sshcomm.NewConnection(uri, log, opts)
 	}
 
 	return nil, comm.ErrUri
 }
 
 // NewListener creates a new Listener based on the provided URI prefix.
-// It supports different listener types (e.g., tcp, udp, sock, serial)
+// It supports different listener types (e.g., tcp, udp, sock, serial, mem)
 func NewListener(uri string, log *logging.Logger, opts dictx.Dict) (comm.Listener, error) {
 	if uri == "" {
 		return nil, errors.New("uri should not be empty")
@@ -53,6 +76,8 @@ func NewListener(uri string, log *logging.Logger, opts dictx.Dict) (comm.Listene
 		return sockcomm.NewListener(uri, log, opts)
 	case "serial":
 		return serialcomm.NewListener(uri, log, opts)
+	case "mem":
+		return memcomm.NewListener(uri, log, opts)
 	}
 
 	return nil, comm.ErrUri
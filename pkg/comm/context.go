@@ -5,11 +5,11 @@
 package comm
 
 import (
-	"encoding/hex"
 	"fmt"
 	"strings"
 
 	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/abc/dumpx"
 	"github.com/exonlabs/go-utils/pkg/logging"
 )
 
@@ -22,6 +22,33 @@ const (
 	POLL_MAXSIZE = 0
 )
 
+// Formats of logged communication data.
+const (
+	// LOG_FORMAT_HEX defines compact uppercase hex, the default.
+	LOG_FORMAT_HEX = "hex"
+	// LOG_FORMAT_SPACED defines uppercase hex with spaces between bytes.
+	LOG_FORMAT_SPACED = "spaced"
+	// LOG_FORMAT_DUMP defines multi-line canonical hexdump with ASCII.
+	LOG_FORMAT_DUMP = "dump"
+)
+
+// FrameFormatter formats communication data for logging.
+type FrameFormatter func(data []byte) string
+
+// NewFrameFormatter returns the frame formatter for a log format,
+// defaulting to LOG_FORMAT_HEX for unknown formats.
+func NewFrameFormatter(format string) FrameFormatter {
+	switch strings.ToLower(format) {
+	case LOG_FORMAT_SPACED:
+		return func(data []byte) string { return dumpx.Hex(data, " ") }
+	case LOG_FORMAT_DUMP:
+		return func(data []byte) string {
+			return "\n" + strings.TrimSuffix(dumpx.Hexdump(data, 0), "\n")
+		}
+	}
+	return func(data []byte) string { return dumpx.Hex(data, "") }
+}
+
 // Context represents the configuration and state for communication handling.
 type Context struct {
 	// uri specifies the resource identifier.
@@ -40,6 +67,12 @@ type Context struct {
 	// PollMaxSize defines the maximum size for read polling data.
 	// use 0 or negative value to disable max limit for read data polling.
 	PollMaxSize int
+
+	// Guard defines the receiving limits, nil if not configured.
+	Guard *Guard
+
+	// FrameFormatter defines the formatting of logged TX/RX data.
+	FrameFormatter FrameFormatter
 }
 
 // NewContext creates and initializes a new Context instance with optional settings.
@@ -48,6 +81,10 @@ type Context struct {
 //   - poll_chunksize: (int) the size of chunks to read during polling.
 //   - poll_maxsize: (int) the maximum size for read polling data.
 //     use 0 or negative value to disable max limit for read data polling.
+//   - log_format: (string) the format of logged TX/RX data, one of
+//     "hex" (default), "spaced" or "dump".
+//
+// The receiving guard limits are also parsed from options, see [NewGuard].
 func NewContext(uri string, log *logging.Logger, opts dictx.Dict) *Context {
 	// adjust default communicatin logging format
 	if log != nil {
@@ -61,7 +98,10 @@ func NewContext(uri string, log *logging.Logger, opts dictx.Dict) *Context {
 		PollTimeout:   POLL_TIMEOUT,
 		PollChunkSize: POLL_CHUNKSIZE,
 		PollMaxSize:   POLL_MAXSIZE,
+		Guard:         NewGuard(opts),
 	}
+	ctx.FrameFormatter = NewFrameFormatter(
+		dictx.Fetch(opts, "log_format", LOG_FORMAT_HEX))
 
 	// Apply custom options.
 	if opts != nil {
@@ -97,25 +137,31 @@ func (c *Context) LogMsg(msg string, args ...any) {
 	}
 }
 
-// LogTx logs transmitted data in a formatted hexadecimal string.
+// LogTx logs transmitted data formatted by the frame formatter,
+// and emits a TxFrame event to the event sink.
 //
 //	2006-01-02 15:04:05.000000 TX >> 0102030405060708090A0B0C0D0E0F
 func (c *Context) LogTx(data []byte, addr any) {
-	if c.CommLog != nil && len(data) > 0 {
-		msg := "TX >> " + strings.ToUpper(hex.EncodeToString(data))
-		if addr != nil {
-			msg = fmt.Sprintf("(%s) %s", addr, msg)
-		}
-		c.CommLog.Info(msg)
-	}
+	c.EmitEvent(EVENT_TX_FRAME, addr, data, nil)
+	c.logFrame("TX >> ", data, addr)
 }
 
-// LogRx logs received data in a formatted hexadecimal string.
+// LogRx logs received data formatted by the frame formatter,
+// and emits a RxFrame event to the event sink.
 //
 //	2006-01-02 15:04:05.000000 RX << 0102030405060708090A0B0C0D0E0F
 func (c *Context) LogRx(data []byte, addr any) {
-	if c.CommLog != nil && len(data) > 0 {
-		msg := "RX << " + strings.ToUpper(hex.EncodeToString(data))
+	c.EmitEvent(EVENT_RX_FRAME, addr, data, nil)
+	c.logFrame("RX << ", data, addr)
+}
+
+func (c *Context) logFrame(prefix string, data []byte, addr any) {
+	if c.CommLog != nil && c.CommLog.Enabled(logging.INFO) && len(data) > 0 {
+		format := c.FrameFormatter
+		if format == nil {
+			format = NewFrameFormatter(LOG_FORMAT_HEX)
+		}
+		msg := prefix + format(data)
 		if addr != nil {
 			msg = fmt.Sprintf("(%s) %s", addr, msg)
 		}
@@ -0,0 +1,110 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package comm
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Drainer tracks the active connections of a listener, used to notify
+// the connection handlers on graceful shutdown and to force close the
+// remaining connections after the drain deadline.
+type Drainer struct {
+	// drainEvent signals a drain operation.
+	drainEvent atomic.Bool
+
+	// conns holds the active connections with their force close functions.
+	conns map[Connection]func()
+	// mu defines mutex for active connections.
+	mu sync.Mutex
+}
+
+// Reset clears the drain event, used when the listener starts.
+func (d *Drainer) Reset() {
+	d.drainEvent.Store(false)
+}
+
+// IsDraining checks if a drain operation is in progress.
+func (d *Drainer) IsDraining() bool {
+	return d.drainEvent.Load()
+}
+
+// Track adds an active connection with its force close function, and
+// returns the function to remove the connection when its handler returns.
+func (d *Drainer) Track(conn Connection, forceClose func()) func() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.conns == nil {
+		d.conns = map[Connection]func(){}
+	}
+	d.conns[conn] = forceClose
+	return func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		delete(d.conns, conn)
+	}
+}
+
+// Count returns the number of active connections.
+func (d *Drainer) Count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.conns)
+}
+
+// Drain sets the drain event and interrupts the ongoing receiving
+// operations of active connections, so handlers can check the drain
+// event and finish their work.
+func (d *Drainer) Drain() {
+	d.drainEvent.Store(true)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for conn := range d.conns {
+		conn.CancelRecv()
+	}
+}
+
+// Wait waits up to timeout seconds for all active connections handlers
+// to return. Setting timeout=0 will wait indefinitely.
+// It returns false if the timeout elapsed with active connections.
+func (d *Drainer) Wait(timeout float64) bool {
+	var tBreak time.Time
+	if timeout > 0 {
+		tBreak = time.Now().Add(time.Duration(timeout * float64(time.Second)))
+	}
+	for d.Count() > 0 {
+		if timeout > 0 && time.Now().After(tBreak) {
+			return false
+		}
+		time.Sleep(time.Duration(POLL_TIMEOUT * float64(time.Second)))
+	}
+	return true
+}
+
+// ForceClose calls the force close functions of active connections.
+func (d *Drainer) ForceClose() {
+	d.mu.Lock()
+	fns := make([]func(), 0, len(d.conns))
+	for _, fn := range d.conns {
+		fns = append(fns, fn)
+	}
+	d.mu.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// IsDraining checks if the parent listener of connection is draining,
+// where listeners not supporting graceful stop are never draining.
+func IsDraining(conn Connection) bool {
+	if l, ok := conn.Parent().(GracefulListener); ok {
+		return l.IsDraining()
+	}
+	return false
+}
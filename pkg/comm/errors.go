@@ -29,6 +29,10 @@ var (
 	ErrRead = fmt.Errorf("%wread failed", ErrError)
 	// ErrWrite indicates a write failure.
 	ErrWrite = fmt.Errorf("%wwrite failed", ErrError)
+	// ErrQueueFull indicates a message dropped by a full sending queue.
+	ErrQueueFull = fmt.Errorf("%w, send queue full", ErrWrite)
+	// ErrGuard indicates a connection guard limits violation.
+	ErrGuard = fmt.Errorf("%wguard violation", ErrError)
 )
 
 // IsClosedError checks if the error is related to a closed connection.
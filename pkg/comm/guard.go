@@ -0,0 +1,109 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package comm
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+)
+
+// Guard enforces receiving limits on a connection, protecting listeners
+// exposed on untrusted networks from oversized frames, message floods
+// and slow incomplete frames.
+type Guard struct {
+	// MaxFrameSize defines the max size of received frame in bytes.
+	MaxFrameSize int
+	// FrameTimeout defines the max time in seconds to receive a frame
+	// after its first bytes arrived.
+	FrameTimeout float64
+	// MaxRate defines the max received frames per second.
+	MaxRate float64
+	// RateBurst defines the max burst of received frames above rate.
+	RateBurst int
+
+	// tokens holds the available rate tokens.
+	tokens float64
+	// tLast holds the last rate tokens update time.
+	tLast time.Time
+	// mu defines mutex for rate tokens update.
+	mu sync.Mutex
+}
+
+// NewGuard creates a new Guard from the parsed options, or nil if no
+// guard limits are configured.
+// The parsed options are:
+//   - guard_max_frame_size: (int) the max size of received frame in bytes.
+//     the connection is closed immediately on exceeding the size.
+//   - guard_frame_timeout: (float64) the max time in seconds to receive
+//     a frame after its first bytes. the connection is closed on timeout.
+//   - guard_max_rate: (float64) the max received frames per second.
+//     the connection is closed on exceeding the rate.
+//   - guard_rate_burst: (int) the max burst of received frames above rate.
+//     default is the max rate.
+func NewGuard(opts dictx.Dict) *Guard {
+	g := &Guard{
+		MaxFrameSize: dictx.GetInt(opts, "guard_max_frame_size", 0),
+		FrameTimeout: dictx.GetFloat(opts, "guard_frame_timeout", 0),
+		MaxRate:      dictx.GetFloat(opts, "guard_max_rate", 0),
+	}
+	g.RateBurst = dictx.GetInt(opts, "guard_rate_burst", int(g.MaxRate))
+	if g.RateBurst < 1 {
+		g.RateBurst = 1
+	}
+	if g.MaxFrameSize <= 0 && g.FrameTimeout <= 0 && g.MaxRate <= 0 {
+		return nil
+	}
+	g.tokens = float64(g.RateBurst)
+	return g
+}
+
+// CheckSize checks the size of a received frame.
+func (g *Guard) CheckSize(n int) error {
+	if g != nil && g.MaxFrameSize > 0 && n > g.MaxFrameSize {
+		return fmt.Errorf("%w, frame size %d exceeds limit %d",
+			ErrGuard, n, g.MaxFrameSize)
+	}
+	return nil
+}
+
+// CheckTime checks the elapsed time of an incomplete frame started at
+// tStart, where zero tStart means no frame bytes received yet.
+func (g *Guard) CheckTime(tStart time.Time) error {
+	if g != nil && g.FrameTimeout > 0 && !tStart.IsZero() &&
+		time.Since(tStart).Seconds() > g.FrameTimeout {
+		return fmt.Errorf("%w, incomplete frame timeout", ErrGuard)
+	}
+	return nil
+}
+
+// CheckRate checks the received frames rate, consuming a rate token
+// for each received frame.
+func (g *Guard) CheckRate() error {
+	if g == nil || g.MaxRate <= 0 {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	if !g.tLast.IsZero() {
+		g.tokens += now.Sub(g.tLast).Seconds() * g.MaxRate
+		if g.tokens > float64(g.RateBurst) {
+			g.tokens = float64(g.RateBurst)
+		}
+	}
+	g.tLast = now
+
+	if g.tokens < 1 {
+		return fmt.Errorf("%w, frames rate exceeds limit %v/s",
+			ErrGuard, g.MaxRate)
+	}
+	g.tokens--
+	return nil
+}
@@ -0,0 +1,381 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package comm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+)
+
+const (
+	// HEARTBEAT_PAYLOAD defines the default heartbeat message payload.
+	HEARTBEAT_PAYLOAD = "\x00"
+	// HEARTBEAT_MAX_FRAME defines the max size of a heartbeat connection
+	// frame in framing mode.
+	HEARTBEAT_MAX_FRAME = 16 * 1024 * 1024
+)
+
+// heartbeat connection frame types.
+const (
+	frameData      byte = 0x01
+	frameHeartbeat byte = 0x02
+)
+
+// frameHeaderSize is the frame header size, 1 byte type and 4 bytes
+// big endian payload length.
+const frameHeaderSize = 5
+
+// HeartbeatConnection wraps a Connection with an application level
+// heartbeat. The heartbeat payload is sent to the peer when no data was
+// sent within the heartbeat interval, and received heartbeats are filtered
+// out from the received data. If no data or heartbeat is received from the
+// peer within the heartbeat timeout, the connection is considered dead and
+// operations return [ErrClosed]. The peer liveness is tracked on received
+// data, so the connection must be read continuously.
+//
+// By default data is sent unchanged and received heartbeats are matched
+// against whole received data chunks, which suits message oriented peers
+// such as UDP or serial devices. In this mode, received data equal to the
+// heartbeat payload is dropped as heartbeat, so the payload must never
+// occur as a whole application message. With framing enabled, all sent data is
+// framed with a type and length header, so both peers must use a framing
+// HeartbeatConnection, and received frames may span or share the
+// underlying received chunks, which suits stream connections.
+type HeartbeatConnection struct {
+	Connection
+
+	// Interval defines the heartbeat sending interval in seconds.
+	Interval float64
+	// Timeout defines the max time in seconds without receiving data or
+	// heartbeats from peer before the connection is considered dead.
+	Timeout float64
+	// Payload defines the heartbeat message payload.
+	Payload []byte
+	// Framing enables the framing of sent and received data.
+	Framing bool
+
+	// lastSeen holds the time of last received data or heartbeat in unix
+	// nanoseconds.
+	lastSeen atomic.Int64
+	// lastSent holds the time of last sent data or heartbeat in unix
+	// nanoseconds.
+	lastSent atomic.Int64
+	// expired signals a heartbeat timeout.
+	expired atomic.Bool
+
+	// stopCh signals the heartbeat routine to stop.
+	stopCh chan struct{}
+	// sMutex defines mutex for heartbeat routine state changes.
+	sMutex sync.Mutex
+
+	// rBuffer holds the received data of incomplete frames in framing mode.
+	rBuffer []byte
+	// rAddr holds the source address of buffered data.
+	rAddr any
+	// rMutex defines mutex for read operations.
+	rMutex sync.Mutex
+}
+
+// NewHeartbeatConnection creates a new heartbeat wrapper for a connection.
+// The heartbeat routine starts when the connection is opened, or immediately
+// if the connection is already opened.
+// The parsed options are:
+//   - heartbeat_interval: (float64) the heartbeat sending interval in seconds.
+//   - heartbeat_timeout: (float64) the max time in seconds without receiving
+//     data or heartbeats from peer. default is 3 times the heartbeat interval.
+//   - heartbeat_payload: (string) the heartbeat message payload. without
+//     framing, received data equal to payload is dropped as heartbeat.
+//   - heartbeat_framing: (bool) enable framing of sent and received data.
+//     default is false.
+func NewHeartbeatConnection(conn Connection, opts dictx.Dict) *HeartbeatConnection {
+	c := &HeartbeatConnection{
+		Connection: conn,
+		Interval:   dictx.GetFloat(opts, "heartbeat_interval", 0),
+		Payload: []byte(dictx.Fetch(
+			opts, "heartbeat_payload", HEARTBEAT_PAYLOAD)),
+		Framing: dictx.Fetch(opts, "heartbeat_framing", false),
+	}
+	c.Timeout = dictx.GetFloat(opts, "heartbeat_timeout", 3*c.Interval)
+	if len(c.Payload) == 0 {
+		c.Payload = []byte(HEARTBEAT_PAYLOAD)
+	}
+
+	if conn.IsOpened() {
+		c.start()
+	}
+	return c
+}
+
+// IsHeartbeatEnabled checks if the heartbeat is configured in options.
+func IsHeartbeatEnabled(opts dictx.Dict) bool {
+	return dictx.GetFloat(opts, "heartbeat_interval", 0) > 0
+}
+
+// start runs the heartbeat routine. The routine state is cleared when it
+// exits, so it restarts when the connection is opened again.
+func (c *HeartbeatConnection) start() {
+	c.sMutex.Lock()
+	defer c.sMutex.Unlock()
+
+	if c.stopCh != nil || c.Interval <= 0 {
+		return
+	}
+
+	now := time.Now().UnixNano()
+	c.expired.Store(false)
+	c.lastSeen.Store(now)
+	c.lastSent.Store(now)
+	c.stopCh = make(chan struct{})
+
+	go func(stopCh chan struct{}) {
+		interval := time.Duration(c.Interval * float64(time.Second))
+		timer := time.NewTimer(interval)
+		defer timer.Stop()
+		defer c.exited(stopCh)
+
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-timer.C:
+			}
+
+			if !c.Connection.IsOpened() {
+				return
+			}
+			if c.isTimedOut() {
+				c.expired.Store(true)
+				c.Connection.Cancel()
+				c.Connection.Close()
+				return
+			}
+
+			// send heartbeat only if no data was sent within interval
+			idle := time.Since(time.Unix(0, c.lastSent.Load()))
+			if idle >= interval {
+				c.sendHeartbeat()
+				idle = 0
+			}
+			timer.Reset(interval - idle)
+		}
+	}(c.stopCh)
+}
+
+// sendHeartbeat transmits the heartbeat payload over the connection.
+func (c *HeartbeatConnection) sendHeartbeat() {
+	data := c.Payload
+	if c.Framing {
+		data = encodeFrame(frameHeartbeat, c.Payload)
+	}
+	// the sending time is updated on failure too, to retry on next interval
+	c.lastSent.Store(time.Now().UnixNano())
+	c.Connection.Send(data, c.Interval)
+}
+
+// touchSent updates the sending time.
+func (c *HeartbeatConnection) touchSent() {
+	c.lastSent.Store(time.Now().UnixNano())
+}
+
+// touchSeen updates the peer liveness time.
+func (c *HeartbeatConnection) touchSeen() {
+	c.lastSeen.Store(time.Now().UnixNano())
+}
+
+// exited clears the routine state if not already stopped or restarted.
+func (c *HeartbeatConnection) exited(stopCh chan struct{}) {
+	c.sMutex.Lock()
+	defer c.sMutex.Unlock()
+
+	if c.stopCh == stopCh {
+		c.stopCh = nil
+	}
+}
+
+// stop terminates the heartbeat routine.
+func (c *HeartbeatConnection) stop() {
+	c.sMutex.Lock()
+	defer c.sMutex.Unlock()
+
+	if c.stopCh != nil {
+		close(c.stopCh)
+		c.stopCh = nil
+	}
+}
+
+// isTimedOut checks if the heartbeat timeout elapsed since last received
+// data or heartbeat.
+func (c *HeartbeatConnection) isTimedOut() bool {
+	if c.Timeout <= 0 {
+		return false
+	}
+	t := time.Unix(0, c.lastSeen.Load()).Add(
+		time.Duration(c.Timeout * float64(time.Second)))
+	return time.Now().After(t)
+}
+
+// IsExpired checks if the connection was dropped by heartbeat timeout.
+func (c *HeartbeatConnection) IsExpired() bool {
+	return c.expired.Load()
+}
+
+// IsOpened checks if the connection is open and heartbeat is not expired.
+func (c *HeartbeatConnection) IsOpened() bool {
+	return !c.expired.Load() && c.Connection.IsOpened()
+}
+
+// Open establishes the connection and starts the heartbeat routine.
+// The heartbeat expiry and buffered data of a previous session are reset.
+func (c *HeartbeatConnection) Open(timeout float64) error {
+	if err := c.Connection.Open(timeout); err != nil {
+		return err
+	}
+	c.rMutex.Lock()
+	c.rBuffer, c.rAddr = nil, nil
+	c.rMutex.Unlock()
+	c.start()
+	return nil
+}
+
+// Close stops the heartbeat routine and terminates the connection.
+func (c *HeartbeatConnection) Close() {
+	c.stop()
+	c.Connection.Close()
+}
+
+// Send transmits data over the connection, with a specified timeout.
+func (c *HeartbeatConnection) Send(data []byte, timeout float64) error {
+	if c.expired.Load() {
+		return ErrClosed
+	}
+	if c.Framing {
+		data = encodeFrame(frameData, data)
+	}
+	if err := c.Connection.Send(data, timeout); err != nil {
+		return err
+	}
+	c.touchSent()
+	return nil
+}
+
+// SendTo transmits data to addr over the connection, with a specified timeout.
+func (c *HeartbeatConnection) SendTo(data []byte, addr any, timeout float64) error {
+	if c.expired.Load() {
+		return ErrClosed
+	}
+	if c.Framing {
+		data = encodeFrame(frameData, data)
+	}
+	if err := c.Connection.SendTo(data, addr, timeout); err != nil {
+		return err
+	}
+	c.touchSent()
+	return nil
+}
+
+// Recv receives data over the connection, with a specified timeout.
+// Received heartbeats are filtered out from the received data.
+func (c *HeartbeatConnection) Recv(timeout float64) ([]byte, error) {
+	b, _, err := c.RecvFrom(timeout)
+	return b, err
+}
+
+// RecvFrom receives data from addr over the connection, with a specified
+// timeout. Received heartbeats are filtered out from the received data.
+func (c *HeartbeatConnection) RecvFrom(timeout float64) ([]byte, any, error) {
+	c.rMutex.Lock()
+	defer c.rMutex.Unlock()
+
+	var tBreak time.Time
+	if timeout > 0 {
+		tBreak = time.Now().Add(time.Duration(timeout * float64(time.Second)))
+	}
+
+	for {
+		if c.expired.Load() {
+			return nil, nil, ErrClosed
+		}
+
+		// return buffered data frames first
+		for c.Framing {
+			t, payload, err := c.nextFrame()
+			if err != nil {
+				return nil, nil, err
+			}
+			if t == 0 {
+				break
+			}
+			if t == frameData {
+				return payload, c.rAddr, nil
+			}
+		}
+
+		// adjust remaining timeout for subsequent reads
+		if timeout > 0 {
+			timeout = time.Until(tBreak).Seconds()
+			if timeout <= 0 {
+				return nil, nil, ErrTimeout
+			}
+		}
+
+		data, addr, err := c.Connection.RecvFrom(timeout)
+		if err != nil {
+			if c.expired.Load() {
+				return nil, nil, ErrClosed
+			}
+			return nil, nil, err
+		}
+		c.touchSeen()
+		if !c.Framing {
+			if bytes.Equal(data, c.Payload) {
+				continue
+			}
+			return data, addr, nil
+		}
+		c.rBuffer = append(c.rBuffer, data...)
+		c.rAddr = addr
+	}
+}
+
+// nextFrame extracts the next complete frame from the receive buffer.
+// It returns a zero frame type if no complete frame is buffered.
+func (c *HeartbeatConnection) nextFrame() (byte, []byte, error) {
+	if len(c.rBuffer) < frameHeaderSize {
+		return 0, nil, nil
+	}
+	t := c.rBuffer[0]
+	n := binary.BigEndian.Uint32(c.rBuffer[1:frameHeaderSize])
+	if (t != frameData && t != frameHeartbeat) || n > HEARTBEAT_MAX_FRAME {
+		// drop the buffered data, the stream can not be resynchronized
+		c.rBuffer = nil
+		return 0, nil, fmt.Errorf("%w, invalid heartbeat frame", ErrRead)
+	}
+	if len(c.rBuffer) < frameHeaderSize+int(n) {
+		return 0, nil, nil
+	}
+
+	payload := make([]byte, n)
+	copy(payload, c.rBuffer[frameHeaderSize:])
+	c.rBuffer = c.rBuffer[frameHeaderSize+int(n):]
+	if len(c.rBuffer) == 0 {
+		c.rBuffer = nil
+	}
+	return t, payload, nil
+}
+
+// encodeFrame creates a frame of type t holding payload.
+func encodeFrame(t byte, payload []byte) []byte {
+	b := make([]byte, frameHeaderSize+len(payload))
+	b[0] = t
+	binary.BigEndian.PutUint32(b[1:frameHeaderSize], uint32(len(payload)))
+	copy(b[frameHeaderSize:], payload)
+	return b
+}
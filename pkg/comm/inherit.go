@@ -0,0 +1,82 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package comm
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// INHERIT_ENV defines the environment variable holding the URIs of the
+// listeners inherited from the parent process. The URIs are separated by
+// newlines and mapped in order to file descriptors starting from 3.
+const INHERIT_ENV = "COMM_INHERIT_LISTENERS"
+
+// FileListener defines the listeners supporting the hand over of their
+// underlying sockets to a child process.
+type FileListener interface {
+	Listener
+	// Uri returns the URI of listener.
+	Uri() string
+	// File returns a duplicate of the underlying listening socket file.
+	File() (*os.File, error)
+}
+
+var (
+	inheritOnce sync.Once
+	inheritMu   sync.Mutex
+	inherited   map[string]*os.File
+)
+
+// loadInherited loads the inherited files described by the environment.
+func loadInherited() {
+	inheritOnce.Do(func() {
+		inherited = map[string]*os.File{}
+		if v := os.Getenv(INHERIT_ENV); v != "" {
+			for i, u := range strings.Split(v, "\n") {
+				inherited[u] = os.NewFile(uintptr(3+i), u)
+			}
+		}
+		os.Unsetenv(INHERIT_ENV)
+	})
+}
+
+// InheritedFile returns the listening socket file inherited from the parent
+// process for the listener uri, or nil if no file was inherited.
+// The file is returned only once, the caller owns and closes it.
+func InheritedFile(uri string) *os.File {
+	loadInherited()
+
+	inheritMu.Lock()
+	defer inheritMu.Unlock()
+	f, ok := inherited[uri]
+	if !ok {
+		return nil
+	}
+	delete(inherited, uri)
+	return f
+}
+
+// SetInheritedFile registers the listening socket file f for the listener
+// uri, as if inherited from the parent process. It is used when the socket
+// is received at runtime from another process instead of at startup.
+// Any previously registered file for uri is closed.
+func SetInheritedFile(uri string, f *os.File) {
+	loadInherited()
+
+	inheritMu.Lock()
+	defer inheritMu.Unlock()
+	if old, ok := inherited[uri]; ok {
+		old.Close()
+	}
+	inherited[uri] = f
+}
+
+// InheritEnv returns the environment entry describing the listeners URIs
+// passed in order as extra files to a child process.
+func InheritEnv(uris []string) string {
+	return INHERIT_ENV + "=" + strings.Join(uris, "\n")
+}
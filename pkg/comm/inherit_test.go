@@ -0,0 +1,108 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package comm_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/comm"
+)
+
+// inheritUris defines the listeners URIs handed over to helper process.
+var inheritUris = []string{"tcp@0.0.0.0:1234", "unix@/tmp/app.sock", "udp@:53"}
+
+// TestInheritHelper runs as the child process receiving inherited files.
+func TestInheritHelper(t *testing.T) {
+	if os.Getenv("COMM_TEST_HELPER") == "" {
+		t.Skip("inherit helper process")
+	}
+	for _, u := range inheritUris {
+		f := comm.InheritedFile(u)
+		if f == nil {
+			fmt.Printf("%s=<nil>\n", u)
+			continue
+		}
+		b, _ := io.ReadAll(f)
+		f.Close()
+		fmt.Printf("%s=%s|%v\n", u, b, comm.InheritedFile(u) == nil)
+	}
+	fmt.Printf("unknown=%v\n", comm.InheritedFile("tcp@unknown") == nil)
+	fmt.Printf("env=%q\n", os.Getenv(comm.INHERIT_ENV))
+	os.Exit(0)
+}
+
+func TestInheritEnv(t *testing.T) {
+	assert.Equal(t, "COMM_INHERIT_LISTENERS=", comm.InheritEnv(nil))
+	assert.Equal(t, "COMM_INHERIT_LISTENERS=tcp@:1\nunix@/a.sock",
+		comm.InheritEnv([]string{"tcp@:1", "unix@/a.sock"}))
+
+	// files passed in order as extra files are mapped to their URIs
+	dir := t.TempDir()
+	files := []*os.File{}
+	for i := range inheritUris {
+		path := filepath.Join(dir, fmt.Sprintf("f%d", i))
+		require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf("file%d", i)), 0o644))
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		files = append(files, f)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestInheritHelper$")
+	cmd.Env = append(os.Environ(), "COMM_TEST_HELPER=1",
+		comm.InheritEnv(inheritUris))
+	cmd.ExtraFiles = files
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"tcp@0.0.0.0:1234=file0|true",
+		"unix@/tmp/app.sock=file1|true",
+		"udp@:53=file2|true",
+		"unknown=true",
+		`env=""`,
+	}, strings.Split(strings.TrimSpace(string(out)), "\n"))
+
+	// no inherited files without environment
+	cmd = exec.Command(os.Args[0], "-test.run=^TestInheritHelper$")
+	cmd.Env = append(os.Environ(), "COMM_TEST_HELPER=1")
+	out, err = cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"tcp@0.0.0.0:1234=<nil>",
+		"unix@/tmp/app.sock=<nil>",
+		"udp@:53=<nil>",
+		"unknown=true",
+		`env=""`,
+	}, strings.Split(strings.TrimSpace(string(out)), "\n"))
+}
+
+func TestSetInheritedFile(t *testing.T) {
+	dir := t.TempDir()
+	f1, err := os.Create(filepath.Join(dir, "f1"))
+	require.NoError(t, err)
+	f2, err := os.Create(filepath.Join(dir, "f2"))
+	require.NoError(t, err)
+	defer f2.Close()
+
+	comm.SetInheritedFile("tcp@:9999", f1)
+	// replacing registered file closes previous one
+	comm.SetInheritedFile("tcp@:9999", f2)
+	_, err = f1.Stat()
+	assert.ErrorIs(t, err, os.ErrClosed)
+
+	assert.Equal(t, f2, comm.InheritedFile("tcp@:9999"))
+	assert.Nil(t, comm.InheritedFile("tcp@:9999"))
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package memcomm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/exonlabs/go-utils/pkg/comm/memcomm"
+)
+
+func FuzzParseUri(f *testing.F) {
+	for _, s := range []string{
+		"mem@queue1", "MEM@ q ", "mem@", "mem", "@", "", "mem@@", "tcp@x",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, uri string) {
+		name, err := memcomm.ParseUri(uri)
+		if err != nil {
+			return
+		}
+		if name == "" || name != strings.TrimSpace(name) {
+			t.Fatalf("invalid name %q for uri %q", name, uri)
+		}
+		if n, err := memcomm.ParseUri("mem@" + name); err != nil || n != name {
+			t.Fatalf("round trip failed for %q: %q, %v", uri, n, err)
+		}
+	})
+}
@@ -0,0 +1,543 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package memcomm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/comm"
+	"github.com/exonlabs/go-utils/pkg/logging"
+)
+
+// INBOX_SIZE defines the default number of pending frames per connection.
+const INBOX_SIZE = 1024
+
+// ParseUri parses an in-memory URI into its name.
+//
+//	The expected URI format is `mem@<name>`
+//
+//	example:
+//	server
+//	   - mem@service1
+//	client
+//	   - mem@service1
+//
+// Returns the name and any error encountered.
+func ParseUri(uri string) (string, error) {
+	parts := strings.SplitN(uri, "@", 2)
+	if len(parts) < 2 || strings.ToLower(parts[0]) != "mem" ||
+		strings.TrimSpace(parts[1]) == "" {
+		return "", comm.ErrUri
+	}
+	return strings.TrimSpace(parts[1]), nil
+}
+
+// registry holds the active in-memory listeners by name.
+var registry = struct {
+	listeners map[string]*Listener
+	sync.Mutex
+}{listeners: map[string]*Listener{}}
+
+/////////////////////////////////////////////////////
+
+// Connection represents an in-memory connection linked to a peer connection.
+// Each sent data frame is received by the peer as a single frame.
+type Connection struct {
+	// Context containing common attributes and functions.
+	*comm.Context
+
+	// The in-memory endpoint name.
+	name string
+
+	// The linked peer connection.
+	peer *Connection
+	// inbox holds the received frames from peer.
+	inbox chan []byte
+
+	// The parent Listener (if any), managing the connection.
+	parent *Listener
+
+	// isOpened represents the connecton status, opened or closed.
+	isOpened atomic.Bool
+	// closeEvent signals a close operation.
+	closeEvent atomic.Bool
+	// breakReadEvent signals a read interrupt operation.
+	breakReadEvent atomic.Bool
+	// breakWriteEvent signals a write interrupt operation.
+	breakWriteEvent atomic.Bool
+
+	// sMutex defines mutex for state change operations (open/close).
+	sMutex sync.Mutex
+	// rMutex defines mutex for read operations.
+	rMutex sync.Mutex
+	// wMutex defines mutex for write operations.
+	wMutex sync.Mutex
+}
+
+// NewConnection creates and initializes a new Connection for the given URI.
+// The URI specifies the name of the in-memory listener to connect to.
+// The parsed options are:
+//   - inbox_size: (int) the number of pending received frames.
+func NewConnection(uri string, log *logging.Logger, opts dictx.Dict) (*Connection, error) {
+	name, err := ParseUri(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Connection{
+		Context: comm.NewContext(uri, log, opts),
+		name:    name,
+	}, nil
+}
+
+// NewPipe creates two opened and linked in-memory connections, where data
+// sent on one connection is received on the other.
+func NewPipe(log *logging.Logger, opts dictx.Dict) (*Connection, *Connection) {
+	c1 := &Connection{
+		Context: comm.NewContext("mem@pipe", log, opts),
+		name:    "pipe",
+	}
+	c2 := &Connection{
+		Context: comm.NewContext("mem@pipe", log, opts),
+		name:    "pipe",
+	}
+	link(c1, c2)
+	return c1, c2
+}
+
+// link connects two connections as peers and marks them opened.
+func link(c1, c2 *Connection) {
+	for _, c := range []*Connection{c1, c2} {
+		size := dictx.GetInt(c.Options, "inbox_size", INBOX_SIZE)
+		if size <= 0 {
+			size = INBOX_SIZE
+		}
+		c.inbox = make(chan []byte, size)
+		c.closeEvent.Store(false)
+		c.isOpened.Store(true)
+	}
+	c1.peer, c2.peer = c2, c1
+}
+
+// String returns a string representation of the Connection.
+func (c *Connection) String() string {
+	return fmt.Sprintf("<MemConnection: %s>", c.Uri())
+}
+
+// Peer returns the linked peer connection.
+func (c *Connection) Peer() *Connection {
+	return c.peer
+}
+
+// Parent retrieves the parent Listener, if any, associated with the Connection.
+func (c *Connection) Parent() comm.Listener {
+	if c.parent == nil {
+		return nil
+	}
+	return c.parent
+}
+
+// IsOpened indicates whether the connection is currently open and active.
+func (c *Connection) IsOpened() bool {
+	return c.isOpened.Load() && !c.closeEvent.Load()
+}
+
+// Open establishes the connection with the named in-memory listener.
+func (c *Connection) Open(timeout float64) error {
+	// take no action if managed by parent listener
+	if c.parent != nil {
+		return nil
+	}
+
+	c.sMutex.Lock()
+	defer c.sMutex.Unlock()
+
+	// do nothing if already opened
+	if c.isOpened.Load() {
+		return nil
+	}
+
+	registry.Lock()
+	l, ok := registry.listeners[c.name]
+	registry.Unlock()
+	if !ok || !l.IsActive() {
+		c.LogMsg("CONNECT_FAIL -- listener not found")
+		c.EmitEvent(comm.EVENT_ERROR, nil, nil, comm.ErrConnection)
+		return fmt.Errorf("%w, listener not found", comm.ErrConnection)
+	}
+
+	if err := l.accept(c); err != nil {
+		c.LogMsg("CONNECT_FAIL -- %v", err)
+		c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
+		return fmt.Errorf("%w, %v", comm.ErrConnection, err)
+	}
+	c.LogMsg("CONNECTED -- %s", c.Uri())
+	c.EmitEvent(comm.EVENT_CONNECTED, nil, nil, nil)
+	return nil
+}
+
+// Close shuts down the connection.
+func (c *Connection) Close() {
+	// take no action if managed by parent listener
+	if c.parent != nil {
+		return
+	}
+	c.close()
+}
+
+// close marks the connection as closed.
+func (c *Connection) close() {
+	c.closeEvent.Store(true)
+
+	c.sMutex.Lock()
+	defer c.sMutex.Unlock()
+
+	// do nothing if already closed
+	if !c.isOpened.Load() {
+		return
+	}
+
+	c.LogMsg("DISCONNECTED -- %s", c.Uri())
+	c.EmitEvent(comm.EVENT_DISCONNECTED, nil, nil, nil)
+	c.isOpened.Store(false)
+}
+
+// Cancel cancels any ongoing operations on the connection.
+func (c *Connection) Cancel() {
+	c.breakReadEvent.Store(true)
+	c.breakWriteEvent.Store(true)
+}
+
+// CancelSend interrupts the ongoing sending operation for this Connection.
+func (c *Connection) CancelSend() {
+	c.breakWriteEvent.Store(true)
+}
+
+// CancelRecv interrupts the ongoing receiving operation for this Connection.
+func (c *Connection) CancelRecv() {
+	c.breakReadEvent.Store(true)
+}
+
+// pollInterval returns the polling interval for wait operations.
+func (c *Connection) pollInterval() time.Duration {
+	if c.PollTimeout > 0 {
+		return time.Duration(c.PollTimeout * float64(time.Second))
+	}
+	return time.Duration(comm.POLL_TIMEOUT * float64(time.Second))
+}
+
+// Send transmits data over the connection, with a specified timeout.
+func (c *Connection) Send(data []byte, timeout float64) error {
+	return c.SendTo(data, nil, timeout)
+}
+
+// SendTo transmits data to the peer connection, with a specified timeout.
+// The addr value is ignored for in-memory connections.
+func (c *Connection) SendTo(data []byte, _ any, timeout float64) error {
+	if len(data) == 0 {
+		return errors.New("empty data")
+	}
+
+	// Acquire write lock
+	c.wMutex.Lock()
+	defer c.wMutex.Unlock()
+
+	// Check connection state after acquiring the lock
+	if c.closeEvent.Load() || !c.isOpened.Load() {
+		return comm.ErrClosed
+	}
+
+	c.breakWriteEvent.Store(false)
+
+	var tBreak time.Time
+	if timeout > 0 {
+		tBreak = time.Now().Add(
+			time.Duration(timeout * float64(time.Second)))
+	}
+
+	b := make([]byte, len(data))
+	copy(b, data)
+
+	tPoll := c.pollInterval()
+	for {
+		if !c.peer.IsOpened() {
+			c.LogMsg("CONN_CLOSED -- peer closed")
+			c.EmitEvent(comm.EVENT_ERROR, nil, nil, comm.ErrClosed)
+			c.close()
+			return comm.ErrClosed
+		}
+
+		select {
+		case c.peer.inbox <- b:
+			c.LogTx(data, nil)
+			return nil
+		case <-time.After(tPoll):
+		}
+
+		if c.breakWriteEvent.Load() {
+			return comm.ErrBreak
+		}
+		if timeout > 0 && time.Now().After(tBreak) {
+			return comm.ErrTimeout
+		}
+	}
+}
+
+// Recv waits for incoming data over the connection until a timeout
+// or interrupt event occurs. Setting timeout=0 will wait indefinitely.
+func (c *Connection) Recv(timeout float64) ([]byte, error) {
+	b, _, err := c.RecvFrom(timeout)
+	return b, err
+}
+
+// RecvFrom waits for incoming data over the connection until a timeout
+// or interrupt event occurs. Setting timeout=0 will wait indefinitely.
+// The returned addr is always nil for in-memory connections.
+func (c *Connection) RecvFrom(timeout float64) ([]byte, any, error) {
+	// Acquire read lock
+	c.rMutex.Lock()
+	defer c.rMutex.Unlock()
+
+	// Check connection state after acquiring the lock
+	if c.closeEvent.Load() || !c.isOpened.Load() {
+		return nil, nil, comm.ErrClosed
+	}
+
+	c.breakReadEvent.Store(false)
+
+	var tBreak time.Time
+	if timeout > 0 {
+		tBreak = time.Now().Add(
+			time.Duration(timeout * float64(time.Second)))
+	}
+
+	tPoll := c.pollInterval()
+	for {
+		select {
+		case data := <-c.inbox:
+			c.LogRx(data, nil)
+			return data, nil, nil
+		case <-time.After(tPoll):
+		}
+
+		if !c.peer.IsOpened() && len(c.inbox) == 0 {
+			c.LogMsg("CONN_CLOSED -- peer closed")
+			c.EmitEvent(comm.EVENT_ERROR, nil, nil, comm.ErrClosed)
+			c.close()
+			return nil, nil, comm.ErrClosed
+		}
+		if c.parent != nil && c.parent.stopEvent.Load() {
+			return nil, nil, comm.ErrClosed
+		}
+		if c.breakReadEvent.Load() {
+			return nil, nil, comm.ErrBreak
+		}
+		if timeout > 0 && time.Now().After(tBreak) {
+			return nil, nil, comm.ErrTimeout
+		}
+	}
+}
+
+/////////////////////////////////////////////////////
+
+// Listener represents an in-memory listener that handles incoming
+// connections with a custom connection handler.
+type Listener struct {
+	// Context containing common attributes such as logging and events.
+	*comm.Context
+
+	// The in-memory endpoint name.
+	name string
+
+	// pool dispatches the connection handlers.
+	pool *comm.HandlerPool
+
+	// The handler function to be called when a new connection is accepted.
+	connectionHandler func(comm.Connection)
+
+	// isActive represents the listener status, started or stopped.
+	isActive atomic.Bool
+	// stopEvent signals a stop operation.
+	stopEvent atomic.Bool
+	// stopCh signals the listener loop to stop.
+	stopCh chan struct{}
+	// drainer tracks active connections for graceful stop.
+	drainer comm.Drainer
+
+	// sMutex defines mutex for state change operations (start/stop).
+	sMutex sync.Mutex
+	// aMutex defines mutex for accepting connections.
+	aMutex sync.Mutex
+}
+
+// NewListener creates a new in-memory Listener.
+// The parsed options are:
+//   - handler_pool_size: (int) the number of connection handler workers.
+//     use 0 to run one handler goroutine per connection. (default is 0)
+//   - handler_pool_policy: (string) the overflow policy {queue|reject}
+//     when all handler workers are busy. (default is queue)
+//   - handler_queue_size: (int) the size of pending connections queue.
+//     (default is the handler pool size)
+//
+// Accepted connections are wrapped with heartbeat handling if the
+// heartbeat_interval option is set, see [comm.NewHeartbeatConnection].
+func NewListener(uri string, log *logging.Logger, opts dictx.Dict) (*Listener, error) {
+	name, err := ParseUri(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Listener{
+		Context: comm.NewContext(uri, log, opts),
+		name:    name,
+	}, nil
+}
+
+// String returns a string representation of the Listener.
+func (l *Listener) String() string {
+	return fmt.Sprintf("<MemListener: %s>", l.Uri())
+}
+
+// ConnectionHandler sets a callback function to handle connections.
+func (l *Listener) ConnectionHandler(h func(comm.Connection)) {
+	l.connectionHandler = h
+}
+
+// IsActive checks if the listener is currently active.
+func (l *Listener) IsActive() bool {
+	return l.isActive.Load() && !l.stopEvent.Load()
+}
+
+// accept links a new server side connection to the client connection
+// and dispatches it to the connection handler.
+func (l *Listener) accept(client *Connection) error {
+	l.aMutex.Lock()
+	defer l.aMutex.Unlock()
+
+	if !l.IsActive() || l.drainer.IsDraining() {
+		return errors.New("listener not active")
+	}
+
+	nc := &Connection{
+		Context: comm.NewContext(l.Uri(), l.CommLog, l.Options),
+		name:    l.name,
+		parent:  l,
+	}
+	link(nc, client)
+	nc.EmitEvent(comm.EVENT_CONNECTED, nil, nil, nil)
+
+	ok := l.pool.Submit(func() {
+		defer nc.close()
+		if l.stopEvent.Load() || l.drainer.IsDraining() {
+			return
+		}
+		defer l.drainer.Track(nc, nc.close)()
+
+		// wrap with heartbeat handling if enabled
+		var conn comm.Connection = nc
+		if comm.IsHeartbeatEnabled(l.Options) {
+			hc := comm.NewHeartbeatConnection(nc, l.Options)
+			defer hc.Close()
+			conn = hc
+		}
+		l.connectionHandler(conn)
+	})
+	if !ok {
+		nc.close()
+		client.isOpened.Store(false)
+		l.LogMsg("CONN_REJECTED -- %s", client.Uri())
+		return errors.New("connection rejected")
+	}
+	return nil
+}
+
+// Start registers the listener and begins accepting in-memory connections,
+// calling the connectionHandler for each established connection.
+// It blocks until the listener is stopped.
+func (l *Listener) Start() error {
+	if l.connectionHandler == nil {
+		return errors.New("empty connection handler")
+	}
+
+	// error if already started
+	if !l.sMutex.TryLock() {
+		return errors.New("Listener already started")
+	}
+	defer l.sMutex.Unlock()
+
+	registry.Lock()
+	if _, ok := registry.listeners[l.name]; ok {
+		registry.Unlock()
+		return fmt.Errorf("listener name already in use: %s", l.name)
+	}
+	l.pool = comm.NewHandlerPool(l.CommLog, l.Options)
+	l.stopCh = make(chan struct{})
+	l.drainer.Reset()
+	l.stopEvent.Store(false)
+	l.isActive.Store(true)
+	registry.listeners[l.name] = l
+	registry.Unlock()
+
+	l.LogMsg("LISTENING -- %s", l.Uri())
+	defer func() {
+		registry.Lock()
+		delete(registry.listeners, l.name)
+		registry.Unlock()
+		l.aMutex.Lock()
+		defer l.aMutex.Unlock()
+		// wait all connections handlers termination
+		l.pool.Stop()
+		l.LogMsg("CLOSED -- %s", l.Uri())
+		l.isActive.Store(false)
+	}()
+
+	<-l.stopCh
+	return nil
+}
+
+// IsDraining checks if a graceful stop is in progress.
+func (l *Listener) IsDraining() bool {
+	return l.drainer.IsDraining()
+}
+
+// StopGraceful stops accepting new connections and notifies the active
+// connection handlers via the drain event, see [comm.IsDraining], then
+// waits up to timeout seconds for handlers termination before force
+// closing the listener and the remaining connections.
+// Setting timeout=0 will wait indefinitely.
+func (l *Listener) StopGraceful(timeout float64) {
+	// stop immediately if not active
+	if !l.IsActive() {
+		l.Stop()
+		return
+	}
+
+	l.LogMsg("DRAINING -- %s", l.Uri())
+	l.aMutex.Lock()
+	l.drainer.Drain()
+	l.aMutex.Unlock()
+
+	if !l.drainer.Wait(timeout) {
+		l.LogMsg("DRAIN_TIMEOUT -- %s", l.Uri())
+	}
+	l.Stop()
+	l.drainer.ForceClose()
+}
+
+// Stop shuts down the listener, signaling the active connection handlers
+// to terminate.
+func (l *Listener) Stop() {
+	// do nothing if already stopped
+	if !l.isActive.Load() || l.stopEvent.Swap(true) {
+		return
+	}
+	close(l.stopCh)
+}
@@ -0,0 +1,414 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package memcomm_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/comm"
+	"github.com/exonlabs/go-utils/pkg/comm/memcomm"
+)
+
+func TestPipe(t *testing.T) {
+	c1, c2 := memcomm.NewPipe(nil, nil)
+	assert.True(t, c1.IsOpened())
+	assert.True(t, c2.IsOpened())
+
+	assert.NoError(t, c1.Send([]byte("hello"), 1))
+	b, err := c2.Recv(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("hello"), b)
+
+	assert.NoError(t, c2.Send([]byte("world"), 1))
+	b, err = c1.Recv(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("world"), b)
+
+	_, err = c1.Recv(0.05)
+	assert.ErrorIs(t, err, comm.ErrTimeout)
+
+	c1.Close()
+	assert.False(t, c1.IsOpened())
+	_, err = c2.Recv(1)
+	assert.ErrorIs(t, err, comm.ErrClosed)
+}
+
+func TestListener(t *testing.T) {
+	l, err := memcomm.NewListener("mem@echo", nil, nil)
+	assert.NoError(t, err)
+	l.ConnectionHandler(func(conn comm.Connection) {
+		for conn.IsOpened() {
+			b, err := conn.Recv(0)
+			if err != nil {
+				return
+			}
+			conn.Send(b, 0)
+		}
+	})
+	go l.Start()
+	defer l.Stop()
+	for i := 0; i < 100 && !l.IsActive(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	c, err := memcomm.NewConnection("mem@echo", nil, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, c.Open(1))
+	defer c.Close()
+
+	assert.NoError(t, c.Send([]byte("ping"), 1))
+	b, err := c.Recv(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("ping"), b)
+
+	// connecting to unknown listener
+	c, _ = memcomm.NewConnection("mem@unknown", nil, nil)
+	assert.ErrorIs(t, c.Open(1), comm.ErrConnection)
+
+	// invalid uri
+	_, err = memcomm.NewConnection("mem@", nil, nil)
+	assert.ErrorIs(t, err, comm.ErrUri)
+}
+
+func TestChaosConnection(t *testing.T) {
+	c1, c2 := memcomm.NewPipe(nil, nil)
+	data := []byte("0123456789")
+
+	// drop all sent frames
+	cc := comm.NewChaosConnection(c1, comm.ChaosConfig{Send: true, DropRate: 1})
+	assert.NoError(t, cc.Send(data, 1))
+	_, err := c2.Recv(0.05)
+	assert.ErrorIs(t, err, comm.ErrTimeout)
+
+	// duplicate and corrupt sent frames
+	cc.Config = comm.ChaosConfig{Send: true, DuplicateRate: 1, CorruptRate: 1}
+	assert.NoError(t, cc.Send(data, 1))
+	b1, err := c2.Recv(1)
+	assert.NoError(t, err)
+	b2, err := c2.Recv(1)
+	assert.NoError(t, err)
+	assert.Equal(t, b1, b2)
+	assert.NotEqual(t, data, b1)
+	assert.Len(t, b1, len(data))
+
+	// truncate received frames
+	cc.Config = comm.ChaosConfig{Recv: true, TruncateRate: 1}
+	assert.NoError(t, c2.Send(data, 1))
+	b, err := cc.Recv(1)
+	assert.NoError(t, err)
+	assert.Less(t, len(b), len(data))
+	assert.Equal(t, data[:len(b)], b)
+
+	// no faults injected
+	cc.Config = comm.ChaosConfig{DropRate: 1}
+	assert.NoError(t, c2.Send(data, 1))
+	b, err = cc.Recv(1)
+	assert.NoError(t, err)
+	assert.Equal(t, data, b)
+}
+
+func TestSendQueueConnection(t *testing.T) {
+	c1, c2 := memcomm.NewPipe(nil, map[string]any{"inbox_size": 1})
+	var slowStats []comm.SendQueueStats
+	sq := comm.NewSendQueueConnection(c1, map[string]any{
+		"slow_consumer_depth":  2,
+		"slow_consumer_policy": "drop",
+		"send_timeout":         0.5,
+	})
+	sq.SlowConsumer = func(c *comm.SendQueueConnection, st comm.SendQueueStats) {
+		slowStats = append(slowStats, st)
+	}
+	defer sq.Close()
+
+	// peer not reading, messages accumulate in queue then are dropped
+	dropped := 0
+	for i := 0; i < 10; i++ {
+		if err := sq.Send([]byte{byte(i)}, 0); err != nil {
+			assert.ErrorIs(t, err, comm.ErrQueueFull)
+			assert.ErrorIs(t, err, comm.ErrWrite)
+			dropped++
+		}
+	}
+	assert.True(t, sq.IsSlow())
+	assert.Len(t, slowStats, 1)
+	st := sq.Stats()
+	assert.Greater(t, dropped, 0)
+	assert.Equal(t, uint64(dropped), st.Dropped)
+	assert.ErrorIs(t, <-sq.SendAsync([]byte("async")), comm.ErrQueueFull)
+	assert.LessOrEqual(t, st.Depth, 3)
+	assert.Greater(t, st.Lag, 0.0)
+
+	// peer catches up
+	for {
+		if _, err := c2.Recv(0.2); err != nil {
+			break
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, sq.IsSlow())
+	assert.Equal(t, 0, sq.Stats().Depth)
+	assert.NoError(t, sq.Send([]byte("data"), 0))
+	b, err := c2.Recv(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), b)
+
+	// disconnect slow consumer
+	c1, _ = memcomm.NewPipe(nil, map[string]any{"inbox_size": 1})
+	sq = comm.NewSendQueueConnection(c1, map[string]any{
+		"slow_consumer_depth":  1,
+		"slow_consumer_policy": "disconnect",
+	})
+	var err2 error
+	for i := 0; i < 5 && err2 == nil; i++ {
+		err2 = sq.Send([]byte{byte(i)}, 0)
+	}
+	assert.ErrorIs(t, err2, comm.ErrClosed)
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, sq.IsOpened())
+}
+
+func TestSendAsync(t *testing.T) {
+	c1, c2 := memcomm.NewPipe(nil, map[string]any{"inbox_size": 1})
+	sq := comm.NewSendQueueConnection(c1, map[string]any{
+		"send_flush_policy":  "drain",
+		"send_flush_timeout": 1,
+	})
+
+	// results delivered after peer reads
+	results := []<-chan error{}
+	for i := 0; i < 3; i++ {
+		results = append(results, sq.SendAsync([]byte{byte(i)}))
+	}
+	for i := 0; i < 3; i++ {
+		b, err := c2.Recv(1)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{byte(i)}, b)
+	}
+	for _, ch := range results {
+		select {
+		case err := <-ch:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("async send result timeout")
+		}
+	}
+
+	// pending messages flushed on close
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		for i := 0; i < 2; i++ {
+			c2.Recv(1)
+		}
+	}()
+	ch1 := sq.SendAsync([]byte("a"))
+	ch2 := sq.SendAsync([]byte("b"))
+	sq.Close()
+	assert.NoError(t, <-ch1)
+	assert.NoError(t, <-ch2)
+	assert.ErrorIs(t, <-sq.SendAsync([]byte("c")), comm.ErrClosed)
+
+	// pending messages discarded on close
+	c1, _ = memcomm.NewPipe(nil, map[string]any{"inbox_size": 1})
+	sq = comm.NewSendQueueConnection(c1, nil)
+	results = results[:0]
+	for i := 0; i < 3; i++ {
+		results = append(results, sq.SendAsync([]byte{byte(i)}))
+	}
+	time.Sleep(50 * time.Millisecond)
+	sq.Close()
+	errs := 0
+	for _, ch := range results {
+		if <-ch != nil {
+			errs++
+		}
+	}
+	assert.Greater(t, errs, 0)
+}
+
+func TestSendQueueReopen(t *testing.T) {
+	received := make(chan []byte, 10)
+	l, err := memcomm.NewListener("mem@sendqueue", nil, nil)
+	require.NoError(t, err)
+	l.ConnectionHandler(func(conn comm.Connection) {
+		for conn.IsOpened() {
+			b, err := conn.Recv(0)
+			if err != nil {
+				return
+			}
+			received <- b
+		}
+	})
+	go l.Start()
+	defer l.Stop()
+	require.Eventually(t, l.IsActive, time.Second, 10*time.Millisecond)
+
+	c, err := memcomm.NewConnection("mem@sendqueue", nil, nil)
+	require.NoError(t, err)
+	sq := comm.NewSendQueueConnection(c, map[string]any{
+		"send_flush_policy": "drain",
+	})
+
+	// writer not running before open
+	assert.ErrorIs(t, sq.Send([]byte("data"), 0), comm.ErrClosed)
+	assert.ErrorIs(t, <-sq.SendAsync([]byte("data")), comm.ErrClosed)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, sq.Open(1))
+		msg := []byte(fmt.Sprintf("msg-%d", i))
+		assert.NoError(t, <-sq.SendAsync(msg))
+		assert.Equal(t, msg, <-received)
+
+		// pending messages drained on close
+		assert.NoError(t, sq.Send([]byte("last"), 0))
+		sq.Close()
+		assert.Equal(t, []byte("last"), <-received)
+		assert.False(t, sq.IsOpened())
+		assert.ErrorIs(t, sq.Send([]byte("data"), 0), comm.ErrClosed)
+	}
+	st := sq.Stats()
+	assert.Equal(t, uint64(6), st.Sent)
+	assert.Equal(t, 0, st.Depth)
+}
+
+func TestListenerStopGraceful(t *testing.T) {
+	done := make(chan bool, 2)
+	l, err := memcomm.NewListener("mem@drain", nil, nil)
+	require.NoError(t, err)
+	l.ConnectionHandler(func(conn comm.Connection) {
+		for {
+			_, err := conn.Recv(0)
+			if comm.IsDraining(conn) {
+				// finish pending work
+				conn.Send([]byte("bye"), 1)
+				done <- true
+				return
+			}
+			if err != nil {
+				done <- false
+				return
+			}
+		}
+	})
+	go l.Start()
+	for i := 0; i < 100 && !l.IsActive(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	c, err := memcomm.NewConnection("mem@drain", nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, c.Open(1))
+	time.Sleep(50 * time.Millisecond)
+
+	var cl comm.Listener = l
+	gl, ok := cl.(comm.GracefulListener)
+	require.True(t, ok)
+	gl.StopGraceful(1)
+	assert.True(t, <-done)
+	b, err := c.Recv(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("bye"), b)
+
+	// no new connections accepted
+	c, _ = memcomm.NewConnection("mem@drain", nil, nil)
+	assert.Error(t, c.Open(1))
+
+	// connections without listener are never draining
+	p1, _ := memcomm.NewPipe(nil, nil)
+	assert.False(t, comm.IsDraining(p1))
+}
+
+func TestEventSink(t *testing.T) {
+	var mu sync.Mutex
+	events := []string{}
+	comm.SetEventSink(comm.EventSinkFunc(func(ev *comm.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if ev.Uri == "mem@events" {
+			events = append(events, fmt.Sprintf("%s:%d", ev.Type, len(ev.Data)))
+		}
+	}))
+	defer comm.SetEventSink(nil)
+
+	l, err := memcomm.NewListener("mem@events", nil, nil)
+	require.NoError(t, err)
+	l.ConnectionHandler(func(conn comm.Connection) {
+		b, err := conn.Recv(1)
+		if err == nil {
+			conn.Send(b, 1)
+		}
+	})
+	go l.Start()
+	defer l.Stop()
+	for i := 0; i < 100 && !l.IsActive(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	c, err := memcomm.NewConnection("mem@events", nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, c.Open(1))
+	assert.NoError(t, c.Send([]byte("ping"), 1))
+	_, err = c.Recv(1)
+	assert.NoError(t, err)
+	c.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.ElementsMatch(t, []string{
+		"Connected:0", "Connected:0", "TxFrame:4", "RxFrame:4",
+		"TxFrame:4", "RxFrame:4", "Disconnected:0", "Disconnected:0",
+	}, events)
+}
+
+func TestHeartbeatReconnect(t *testing.T) {
+	// server heartbeat is too slow to keep the client alive
+	l, err := memcomm.NewListener("mem@heartbeat", nil, map[string]any{
+		"heartbeat_interval": 10,
+		"heartbeat_timeout":  0,
+	})
+	require.NoError(t, err)
+	l.ConnectionHandler(func(conn comm.Connection) {
+		assert.IsType(t, &comm.HeartbeatConnection{}, conn)
+		for conn.IsOpened() {
+			b, err := conn.Recv(0)
+			if err != nil {
+				return
+			}
+			conn.Send(b, 1)
+		}
+	})
+	go l.Start()
+	defer l.Stop()
+	require.Eventually(t, l.IsActive, time.Second, 10*time.Millisecond)
+
+	c, err := memcomm.NewConnection("mem@heartbeat", nil, nil)
+	require.NoError(t, err)
+	hc := comm.NewHeartbeatConnection(c, map[string]any{
+		"heartbeat_interval": 0.05,
+		"heartbeat_timeout":  0.2,
+	})
+	defer hc.Close()
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, hc.Open(1))
+		assert.False(t, hc.IsExpired())
+		assert.NoError(t, hc.Send([]byte("ping"), 1))
+		b, err := hc.Recv(1)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("ping"), b)
+
+		// expires after server stays silent
+		_, err = hc.Recv(1)
+		assert.ErrorIs(t, err, comm.ErrClosed)
+		assert.True(t, hc.IsExpired())
+		hc.Close()
+	}
+}
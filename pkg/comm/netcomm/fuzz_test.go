@@ -0,0 +1,30 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package netcomm_test
+
+import (
+	"testing"
+
+	"github.com/exonlabs/go-utils/pkg/comm/netcomm"
+)
+
+func FuzzParseUri(f *testing.F) {
+	for _, s := range []string{
+		"tcp@0.0.0.0:1234", "udp4@127.0.0.1:0", "TCP6@[::1]:80",
+		"tcp@:80", "tcp@host", "udp@", "tcp", "@:", "", "unix@x:1",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, uri string) {
+		network, address, err := netcomm.ParseUri(uri)
+		if err != nil {
+			return
+		}
+		n, a, err := netcomm.ParseUri(network + "@" + address)
+		if err != nil || n != network || a != address {
+			t.Fatalf("round trip failed for %q: %q %q %v", uri, n, a, err)
+		}
+	})
+}
@@ -22,8 +22,16 @@ import (
 	"github.com/exonlabs/go-utils/pkg/abc/dictx"
 	"github.com/exonlabs/go-utils/pkg/comm"
 	"github.com/exonlabs/go-utils/pkg/logging"
+	"github.com/exonlabs/go-utils/pkg/secrets"
 )
 
+// WRITE_COALESCE_SIZE defines the default size of coalesced writes buffer.
+const WRITE_COALESCE_SIZE = 4096
+
+// WRITE_FLUSH_TIMEOUT defines the default timeout in seconds of sending
+// coalesced data flushed by timer or [Connection.Flush].
+const WRITE_FLUSH_TIMEOUT = 5.0
+
 // ParseUri parses a network URI into network type and address.
 //
 //	The expected URI format is `<network>@<host>:<port>`
@@ -89,7 +97,8 @@ func GetAddr(network, address string) (net.Addr, error) {
 //   - tls_local_cert: (string) cert to use for TLS session.
 //     cert could be file path to load or cert content in PEM format.
 //   - tls_local_key: (string) private key to use for TLS session.
-//     key could be file path to load or key content in PEM format.
+//     key could be file path to load, key content in PEM format or
+//     secret reference `secret:<name>` loaded from default secrets provider.
 func GetTlsConfig(opts dictx.Dict) (*tls.Config, error) {
 	if !dictx.Fetch(opts, "tls_enable", false) {
 		return nil, nil
@@ -172,19 +181,15 @@ func GetTlsConfig(opts dictx.Dict) (*tls.Config, error) {
 			return nil, errors.New("empty tls_local_key value")
 		}
 
-		var cert tls.Certificate
-		var err error
-		if strings.HasPrefix(crtStr, "-----BEGIN") &&
-			strings.HasPrefix(keyStr, "-----BEGIN") {
-			cert, err = tls.X509KeyPair([]byte(crtStr), []byte(keyStr))
-		} else if strings.HasPrefix(crtStr, "-----BEGIN") ||
-			strings.HasPrefix(keyStr, "-----BEGIN") {
-			return nil, errors.New(
-				"both options tls_local_cert and tls_local_key should be " +
-					"file paths or PEM formatted contents for cert and key")
-		} else {
-			cert, err = tls.LoadX509KeyPair(crtStr, keyStr)
+		crtByte, err := loadPem(crtStr)
+		if err != nil {
+			return nil, fmt.Errorf("error loading tls_local_cert - %v", err)
 		}
+		keyByte, err := loadPem(keyStr)
+		if err != nil {
+			return nil, fmt.Errorf("error loading tls_local_key - %v", err)
+		}
+		cert, err := tls.X509KeyPair(crtByte, keyByte)
 		if err != nil {
 			return nil, fmt.Errorf(
 				"error loading tls_local_cert, tls_local_key - %v", err)
@@ -196,6 +201,54 @@ func GetTlsConfig(opts dictx.Dict) (*tls.Config, error) {
 	return tlsConfig, nil
 }
 
+// loadPem returns PEM content from value, where value could be PEM content,
+// secret reference or file path to load.
+func loadPem(value string) ([]byte, error) {
+	if secrets.IsRef(value) {
+		v, err := secrets.Resolve(value)
+		if err != nil {
+			return nil, err
+		}
+		value = strings.TrimSpace(v)
+	}
+	if strings.HasPrefix(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
+
+// SetTcpOptions applies the socket options on TCP connection, and takes
+// no action for other connection types.
+// The parsed options are:
+//   - tcp_nodelay: (bool) enable/disable TCP_NODELAY. (default is true)
+//   - write_buffer: (int) the OS send buffer size.
+//   - read_buffer: (int) the OS receive buffer size.
+func SetTcpOptions(conn net.Conn, opts dictx.Dict) error {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+
+	if err := tcpConn.SetNoDelay(
+		dictx.Fetch(opts, "tcp_nodelay", true)); err != nil {
+		return err
+	}
+	if v := dictx.GetInt(opts, "write_buffer", 0); v > 0 {
+		if err := tcpConn.SetWriteBuffer(v); err != nil {
+			return err
+		}
+	}
+	if v := dictx.GetInt(opts, "read_buffer", 0); v > 0 {
+		if err := tcpConn.SetReadBuffer(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // IsTLSError checks if the error is related to TLS error
 func IsTLSError(err error) bool {
 	switch {
@@ -233,6 +286,17 @@ type Connection struct {
 	// breakReadEvent signals a read interrupt operation.
 	breakReadEvent atomic.Bool
 
+	// coalesceDelay defines the max delay of coalesced writes.
+	coalesceDelay time.Duration
+	// coalesceSize defines the size of coalesced writes buffer.
+	coalesceSize int
+	// flushTimeout defines the timeout in seconds of flushing coalesced data.
+	flushTimeout float64
+	// wBuffer holds the pending coalesced writes.
+	wBuffer []byte
+	// wTimer triggers flushing of pending coalesced writes.
+	wTimer *time.Timer
+
 	// sMutex defines mutex for state change operations (open/close).
 	sMutex sync.Mutex
 	// rMutex defines mutex for read operations.
@@ -245,6 +309,18 @@ type Connection struct {
 
 // NewConnection creates and initializes a new Connection for the given URI.
 // The URI specifies the network type and address.
+// The parsed options are:
+//   - tcp_nodelay: (bool) enable/disable TCP_NODELAY on TCP connections.
+//     disabling allows the OS to coalesce small writes. (default is true)
+//   - write_buffer: (int) the OS send buffer size of TCP connections.
+//   - read_buffer: (int) the OS receive buffer size of TCP connections.
+//   - write_coalesce_delay: (float64) the max delay in seconds to coalesce
+//     written data of stream connections before sending. use [Connection.Flush]
+//     to send pending data immediately. use 0 to disable. (default is 0)
+//   - write_coalesce_size: (int) the size of pending coalesced data that
+//     triggers sending. (default is 4096)
+//   - write_flush_timeout: (float64) the timeout in seconds of sending
+//     coalesced data flushed by timer or [Connection.Flush]. (default is 5)
 func NewConnection(uri string, log *logging.Logger, opts dictx.Dict) (*Connection, error) {
 	network, address, err := ParseUri(uri)
 	if err != nil {
@@ -255,6 +331,15 @@ func NewConnection(uri string, log *logging.Logger, opts dictx.Dict) (*Connectio
 		Context: comm.NewContext(uri, log, opts),
 		network: network,
 		address: address,
+		coalesceDelay: time.Duration(dictx.GetFloat(
+			opts, "write_coalesce_delay", 0) * float64(time.Second)),
+		coalesceSize: dictx.GetInt(
+			opts, "write_coalesce_size", WRITE_COALESCE_SIZE),
+		flushTimeout: dictx.GetFloat(
+			opts, "write_flush_timeout", WRITE_FLUSH_TIMEOUT),
+	}
+	if c.flushTimeout <= 0 {
+		c.flushTimeout = WRITE_FLUSH_TIMEOUT
 	}
 
 	// set TLS config for connection
@@ -281,6 +366,9 @@ func (c *Connection) NetConn() any {
 
 // Parent retrieves the parent Listener, if any, associated with the Connection.
 func (c *Connection) Parent() comm.Listener {
+	if c.parent == nil {
+		return nil
+	}
 	return c.parent
 }
 
@@ -321,6 +409,13 @@ func (c *Connection) Open(timeout float64) error {
 	conn, err := dialer.Dial(c.network, c.address)
 	if err != nil {
 		c.LogMsg("CONNECT_FAIL -- %v", err)
+		c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
+		return fmt.Errorf("%w, %v", comm.ErrConnection, err)
+	}
+	if err := SetTcpOptions(conn, c.Options); err != nil {
+		conn.Close()
+		c.LogMsg("CONNECT_FAIL -- %v", err)
+		c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
 		return fmt.Errorf("%w, %v", comm.ErrConnection, err)
 	}
 	// set tls config for connection
@@ -330,6 +425,7 @@ func (c *Connection) Open(timeout float64) error {
 	} else {
 		c.LogMsg("CONNECTED -- %s", c.Uri())
 	}
+	c.EmitEvent(comm.EVENT_CONNECTED, nil, nil, nil)
 	c.netConn = conn
 
 	c.closeEvent.Store(false)
@@ -340,10 +436,16 @@ func (c *Connection) Open(timeout float64) error {
 // Close shuts down the connection and cleaning up resources.
 func (c *Connection) Close() {
 	// take no action if managed by parent listener
-	if c.parent != nil {
+	c.sMutex.Lock()
+	managed := c.parent != nil
+	c.sMutex.Unlock()
+	if managed {
 		return
 	}
 
+	// send pending coalesced data
+	c.Flush()
+
 	c.closeEvent.Store(true)
 
 	c.sMutex.Lock()
@@ -363,6 +465,7 @@ func (c *Connection) Close() {
 
 	c.rwWaitGrp.Wait()
 	c.LogMsg("DISCONNECTED -- %s", c.Uri())
+	c.EmitEvent(comm.EVENT_DISCONNECTED, nil, nil, nil)
 	c.isOpened.Store(false)
 }
 
@@ -423,6 +526,9 @@ func (c *Connection) SendTo(data []byte, addr any, timeout float64) error {
 		}
 	} else if conn, ok := c.netConn.(net.Conn); ok {
 		c.LogTx(data, nil)
+		if c.coalesceDelay > 0 {
+			return c.coalesce(data, timeout)
+		}
 		if timeout > 0 {
 			conn.SetWriteDeadline(time.Now().Add(
 				time.Duration(timeout * float64(time.Second))))
@@ -435,20 +541,91 @@ func (c *Connection) SendTo(data []byte, addr any, timeout float64) error {
 		err = errors.New("partial data sent")
 	}
 
+	return c.sendError(err)
+}
+
+// sendError handles send errors, closing connection on closed errors.
+func (c *Connection) sendError(err error) error {
 	if err != nil {
 		if comm.IsClosedError(err) || IsTLSError(err) {
 			c.closeEvent.Store(true)
 			c.LogMsg("CONN_CLOSED -- %v", err)
+			c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
 			go c.Close()
 			return comm.ErrClosed
 		}
 		c.LogMsg("SEND_ERROR -- %v", err)
+		c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
 		return fmt.Errorf("%w, %v", comm.ErrWrite, err)
 	}
+	return nil
+}
 
+// coalesce buffers data for delayed sending, and sends the pending data
+// when the buffer size is reached. requires write lock to be held.
+func (c *Connection) coalesce(data []byte, timeout float64) error {
+	c.wBuffer = append(c.wBuffer, data...)
+	if len(c.wBuffer) >= c.coalesceSize {
+		return c.flush(timeout)
+	}
+	if c.wTimer == nil {
+		c.wTimer = time.AfterFunc(c.coalesceDelay, func() { c.Flush() })
+	}
 	return nil
 }
 
+// flush sends the pending coalesced data. requires write lock to be held.
+func (c *Connection) flush(timeout float64) error {
+	if c.wTimer != nil {
+		c.wTimer.Stop()
+		c.wTimer = nil
+	}
+	if len(c.wBuffer) == 0 {
+		return nil
+	}
+	data := c.wBuffer
+	c.wBuffer = nil
+
+	conn, ok := c.netConn.(net.Conn)
+	if !ok {
+		return errors.New("invalid connection type")
+	}
+	if timeout > 0 {
+		conn.SetWriteDeadline(time.Now().Add(
+			time.Duration(timeout * float64(time.Second))))
+	} else {
+		conn.SetWriteDeadline(time.Time{})
+	}
+	n, err := conn.Write(data)
+	if err == nil && n != len(data) {
+		err = errors.New("partial data sent")
+	}
+	return c.sendError(err)
+}
+
+// Flush sends the pending coalesced data immediately.
+// It takes no action if write coalescing is disabled.
+func (c *Connection) Flush() error {
+	if c.coalesceDelay <= 0 {
+		return nil
+	}
+
+	// Acquire write lock
+	c.wMutex.Lock()
+	defer c.wMutex.Unlock()
+
+	// Check connection state after acquiring the lock
+	if c.closeEvent.Load() || !c.isOpened.Load() {
+		c.wBuffer = nil
+		return comm.ErrClosed
+	}
+
+	c.rwWaitGrp.Add(1)
+	defer c.rwWaitGrp.Done()
+
+	return c.flush(c.flushTimeout)
+}
+
 // Recv waits for incoming data over the connection until a timeout
 // or interrupt event occurs. Setting timeout=0 will wait indefinitely.
 func (c *Connection) Recv(timeout float64) ([]byte, error) {
@@ -503,6 +680,7 @@ func (c *Connection) RecvFrom(timeout float64) ([]byte, any, error) {
 	var data []byte
 	var n int
 	var addr net.Addr
+	var tFrame time.Time
 
 	b := make([]byte, nRead)
 	for {
@@ -521,18 +699,26 @@ func (c *Connection) RecvFrom(timeout float64) ([]byte, any, error) {
 			if comm.IsClosedError(err) || IsTLSError(err) {
 				c.closeEvent.Store(true)
 				c.LogMsg("CONN_CLOSED -- %v", err)
+				c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
 				go c.Close()
 				return nil, nil, comm.ErrClosed
 			}
 			if _, ok := err.(net.Error); !ok || !err.(net.Error).Timeout() {
 				c.LogMsg("RECV_ERROR -- %v", err)
+				c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
 				return nil, nil, fmt.Errorf("%w, %v", comm.ErrRead, err)
 			}
 		}
 
 		if n > 0 {
+			if tFrame.IsZero() {
+				tFrame = time.Now()
+			}
 			data = append(data, b[:n]...)
-			if _, ok := c.netConn.(net.PacketConn); ok {
+			if err := c.Guard.CheckSize(len(data)); err != nil {
+				return nil, nil, c.guardClose(err, addr)
+			}
+			if c.isPacket() {
 				break
 			}
 			if c.PollMaxSize > 0 {
@@ -553,15 +739,49 @@ func (c *Connection) RecvFrom(timeout float64) ([]byte, any, error) {
 		if c.breakReadEvent.Load() {
 			return nil, nil, comm.ErrBreak
 		}
+		if err := c.Guard.CheckTime(tFrame); err != nil {
+			return nil, nil, c.guardClose(err, addr)
+		}
 		if timeout > 0 && time.Now().After(tBreak) {
 			return nil, nil, comm.ErrTimeout
 		}
 	}
 
+	if err := c.Guard.CheckRate(); err != nil {
+		return nil, nil, c.guardClose(err, addr)
+	}
+
 	c.LogRx(data, addr)
 	return data, addr, nil
 }
 
+// isPacket checks if the connection reads single datagrams.
+func (c *Connection) isPacket() bool {
+	switch c.netConn.(type) {
+	case net.PacketConn, *sessionConn:
+		return true
+	}
+	return false
+}
+
+// guardClose closes the connection on receiving guard limits violation.
+// For listener packet connections, the received data is dropped and the
+// guard error is returned, as the packet socket is shared.
+func (c *Connection) guardClose(err error, addr net.Addr) error {
+	if _, ok := c.netConn.(net.PacketConn); ok && c.parent != nil {
+		c.LogMsg("GUARD_DROPPED -- (%v) %v", addr, err)
+		return err
+	}
+	c.closeEvent.Store(true)
+	c.LogMsg("GUARD_CLOSED -- %v", err)
+	c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
+	if conn, ok := c.netConn.(net.Conn); ok {
+		conn.Close()
+	}
+	go c.Close()
+	return comm.ErrClosed
+}
+
 /////////////////////////////////////////////////////
 
 // Listener represents a network listener that handles incoming connections
@@ -579,6 +799,9 @@ type Listener struct {
 
 	// The underlying network listener/Packet connection.
 	netListener any // (net.Listener|net.PacketConn)
+	// The unwrapped network listener/Packet connection, used to get the
+	// listening socket file.
+	rawListener any // (net.Listener|net.PacketConn)
 
 	// The handler function to be called when a new connection is accepted.
 	connectionHandler func(comm.Connection)
@@ -588,6 +811,9 @@ type Listener struct {
 	// stopEvent signals a stop operation.
 	stopEvent atomic.Bool
 
+	// drainer tracks active connections for graceful stop.
+	drainer comm.Drainer
+
 	// sMutex defines mutex for state change operations (start/stop).
 	sMutex sync.Mutex
 }
@@ -596,9 +822,26 @@ type Listener struct {
 // The parsed options are:
 //   - connections_limit: (int) the limit on number of concurrent connections.
 //     use 0 to disable connections limit.
+//   - handler_pool_size: (int) the number of connection handler workers.
+//     use 0 to run one handler goroutine per connection. (default is 0)
+//   - handler_pool_policy: (string) the overflow policy {queue|reject}
+//     when all handler workers are busy. (default is queue)
+//   - handler_queue_size: (int) the size of pending connections queue.
+//     (default is the handler pool size)
 //   - keepalive_interval: (float64) the keep-alive interval in seconds.
 //     use 0 to enable keep-alive probes with OS defined values.
 //     use -1 to disable keep-alive probes. (default is -1)
+//   - udp_session_timeout: (float64) enables session mode for UDP listeners,
+//     where datagrams are demultiplexed by remote address into per-peer
+//     connections passed to the connection handler, and sessions are closed
+//     after the timeout in seconds of inactivity. use 0 to disable, where a
+//     single shared packet connection is passed. (default is 0)
+//
+// The TCP socket and write coalescing options for accepted connections are
+// also parsed from options, see [NewConnection]. The receiving guard limits
+// for accepted connections are also parsed from options, see [comm.NewGuard].
+// Accepted and session connections are wrapped with heartbeat handling if
+// the heartbeat_interval option is set, see [comm.NewHeartbeatConnection].
 func NewListener(uri string, log *logging.Logger, opts dictx.Dict) (*Listener, error) {
 	network, address, err := ParseUri(uri)
 	if err != nil {
@@ -633,6 +876,15 @@ func (l *Listener) NetListener() any {
 	return l.netListener
 }
 
+// File returns a duplicate of the listening socket file, used to hand over
+// the listener to a child process, see [comm.InheritedFile].
+func (l *Listener) File() (*os.File, error) {
+	if v, ok := l.rawListener.(interface{ File() (*os.File, error) }); ok {
+		return v.File()
+	}
+	return nil, errors.New("listener not active")
+}
+
 // ConnectionHandler sets a callback function to handle connections.
 func (l *Listener) ConnectionHandler(h func(comm.Connection)) {
 	l.connectionHandler = h
@@ -651,11 +903,21 @@ func (l *Listener) startListener() error {
 		cfg.KeepAlive = time.Duration(v * float64(time.Second))
 	}
 
-	// listener instance
-	netListener, err := cfg.Listen(context.Background(), l.network, l.address)
+	// listener instance, inherited from parent process or created
+	var netListener net.Listener
+	var err error
+	if f := comm.InheritedFile(l.Uri()); f != nil {
+		netListener, err = net.FileListener(f)
+		f.Close()
+		l.LogMsg("INHERITED -- %s", l.Uri())
+	} else {
+		netListener, err = cfg.Listen(
+			context.Background(), l.network, l.address)
+	}
 	if err != nil {
 		return err
 	}
+	l.rawListener = netListener
 	// set connection limit (if configured)
 	if v := dictx.GetInt(l.Options, "connections_limit", 0); v > 0 {
 		netListener = netutil.LimitListener(netListener, v)
@@ -669,15 +931,19 @@ func (l *Listener) startListener() error {
 	}
 	l.netListener = netListener
 
-	var waitGrp sync.WaitGroup
+	pool := comm.NewHandlerPool(l.CommLog, l.Options)
 
+	l.drainer.Reset()
 	l.stopEvent.Store(false)
 	l.isActive.Store(true)
 	defer func() {
-		l.stopEvent.Store(true)
+		// keep handlers running while draining
+		if !l.drainer.IsDraining() {
+			l.stopEvent.Store(true)
+		}
 		netListener.Close()
 		// wait all connections handlers termination
-		waitGrp.Wait()
+		pool.Stop()
 		l.LogMsg("CLOSED -- %s", l.Uri())
 		l.isActive.Store(false)
 	}()
@@ -695,49 +961,86 @@ func (l *Listener) startListener() error {
 		}
 
 		// handle new connection
-		waitGrp.Add(1)
-		go func(netConn net.Conn) {
-			uri := fmt.Sprintf("%s@%s", l.Type(), netConn.RemoteAddr())
-			nc, err := NewConnection(uri, nil, l.Options)
-			if err != nil {
-				l.LogMsg("CONN_ERROR -- %v", err)
-				netConn.Close()
-				return
-			}
-			if l.CommLog != nil {
-				nc.CommLog = l.CommLog.SubLogger(fmt.Sprintf("(%s) ", uri))
-			}
-			nc.netConn = netConn
-			nc.parent = l
-			nc.isOpened.Store(true)
-			nc.LogMsg("CONNECTED")
+		if !pool.Submit(func() { l.handleConnection(c) }) {
+			l.LogMsg("CONN_REJECTED -- %s", c.RemoteAddr())
+			c.Close()
+		}
+	}
 
-			defer func() {
-				netConn.Close()
-				nc.LogMsg("DISCONNECTED")
-				waitGrp.Done()
-			}()
+	return nil
+}
+
+// handleConnection runs the connection handler for an accepted connection.
+func (l *Listener) handleConnection(netConn net.Conn) {
+	defer netConn.Close()
 
-			l.connectionHandler(nc)
-		}(c)
+	// drop pending connections if listener is stopping
+	if l.stopEvent.Load() || l.drainer.IsDraining() {
+		return
 	}
 
-	return nil
+	if err := SetTcpOptions(netConn, l.Options); err != nil {
+		l.LogMsg("CONN_ERROR -- %v", err)
+		return
+	}
+
+	uri := fmt.Sprintf("%s@%s", l.Type(), netConn.RemoteAddr())
+	nc, err := NewConnection(uri, nil, l.Options)
+	if err != nil {
+		l.LogMsg("CONN_ERROR -- %v", err)
+		return
+	}
+	// send pending coalesced data after handler returns
+	defer nc.Flush()
+	if l.CommLog != nil {
+		nc.CommLog = l.CommLog.SubLogger(fmt.Sprintf("(%s) ", uri))
+	}
+	nc.netConn = netConn
+	nc.parent = l
+	nc.isOpened.Store(true)
+	nc.LogMsg("CONNECTED")
+	nc.EmitEvent(comm.EVENT_CONNECTED, nil, nil, nil)
+	defer nc.LogMsg("DISCONNECTED")
+	defer nc.EmitEvent(comm.EVENT_DISCONNECTED, nil, nil, nil)
+	defer l.drainer.Track(nc, func() { netConn.Close() })()
+
+	// wrap with heartbeat handling if enabled
+	var conn comm.Connection = nc
+	if comm.IsHeartbeatEnabled(l.Options) {
+		hc := comm.NewHeartbeatConnection(nc, l.Options)
+		defer hc.Close()
+		conn = hc
+	}
+
+	l.connectionHandler(conn)
 }
 
 func (l *Listener) startPacketConn() error {
 	var cfg net.ListenConfig
 
-	// packet connection instance
-	packetConn, err := cfg.ListenPacket(
-		context.Background(), l.network, l.address)
+	// packet connection instance, inherited from parent process or created
+	var packetConn net.PacketConn
+	var err error
+	if f := comm.InheritedFile(l.Uri()); f != nil {
+		packetConn, err = net.FilePacketConn(f)
+		f.Close()
+		l.LogMsg("INHERITED -- %s", l.Uri())
+	} else {
+		packetConn, err = cfg.ListenPacket(
+			context.Background(), l.network, l.address)
+	}
 	if err != nil {
 		return err
 	}
 	l.netListener = packetConn
+	l.rawListener = packetConn
 
 	l.LogMsg("LISTENING -- %s", l.Uri())
 
+	if v := dictx.GetFloat(l.Options, "udp_session_timeout", 0); v > 0 {
+		return l.startSessions(packetConn, v)
+	}
+
 	nc := &Connection{
 		Context: comm.NewContext(l.Uri(), l.CommLog, l.Options),
 		network: l.network,
@@ -747,14 +1050,18 @@ func (l *Listener) startPacketConn() error {
 	}
 	nc.isOpened.Store(true)
 
+	l.drainer.Reset()
 	l.stopEvent.Store(false)
 	l.isActive.Store(true)
 	defer func() {
 		l.stopEvent.Store(true)
+		nc.sMutex.Lock()
 		nc.parent = nil
+		nc.sMutex.Unlock()
 		nc.Close()
 		l.isActive.Store(false)
 	}()
+	defer l.drainer.Track(nc, func() { packetConn.Close() })()
 
 	// run connection handler
 	l.connectionHandler(nc)
@@ -781,7 +1088,39 @@ func (l *Listener) Start() error {
 	return l.startListener()
 }
 
-// Stop gracefully shuts down the listener.
+// IsDraining checks if a graceful stop is in progress.
+func (l *Listener) IsDraining() bool {
+	return l.drainer.IsDraining()
+}
+
+// StopGraceful stops accepting new connections and notifies the active
+// connection handlers via the drain event, see [comm.IsDraining], then
+// waits up to timeout seconds for handlers termination before force
+// closing the listener and the remaining connections.
+// Setting timeout=0 will wait indefinitely.
+func (l *Listener) StopGraceful(timeout float64) {
+	// stop immediately if not active
+	if !l.IsActive() {
+		l.Stop()
+		return
+	}
+
+	l.LogMsg("DRAINING -- %s", l.Uri())
+	l.drainer.Drain()
+	// stop accepting new connections
+	if v, ok := l.netListener.(net.Listener); ok {
+		v.Close()
+	}
+
+	if !l.drainer.Wait(timeout) {
+		l.LogMsg("DRAIN_TIMEOUT -- %s", l.Uri())
+	}
+	l.Stop()
+	l.drainer.ForceClose()
+}
+
+// Stop shuts down the listener, signaling the active connection handlers
+// to terminate.
 func (l *Listener) Stop() {
 	l.stopEvent.Store(true)
 
@@ -0,0 +1,257 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package netcomm
+
+import (
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/comm"
+)
+
+const (
+	// UDP_SESSION_INBOX defines the max number of pending datagrams per
+	// session, new datagrams are dropped when the session inbox is full.
+	UDP_SESSION_INBOX = 64
+	// UDP_MAX_SIZE defines the max size of received datagrams.
+	UDP_MAX_SIZE = 65535
+)
+
+// sessionConn represents a virtual connection with a remote peer over
+// a shared packet connection, implementing net.Conn interface.
+type sessionConn struct {
+	// The shared packet connection.
+	packetConn net.PacketConn
+	// The remote peer address.
+	addr net.Addr
+
+	// inbox holds the received datagrams.
+	inbox chan []byte
+	// closeCh signals the session close.
+	closeCh chan struct{}
+	// closeOnce ensures single close of session.
+	closeOnce sync.Once
+	// onClose is called when the session is closed.
+	onClose func()
+
+	// tActive holds the last activity time in unix nano.
+	tActive atomic.Int64
+	// rDeadline holds the read deadline.
+	rDeadline time.Time
+	// mu defines mutex for read deadline.
+	mu sync.Mutex
+}
+
+func newSessionConn(pc net.PacketConn, addr net.Addr, onClose func()) *sessionConn {
+	s := &sessionConn{
+		packetConn: pc,
+		addr:       addr,
+		inbox:      make(chan []byte, UDP_SESSION_INBOX),
+		closeCh:    make(chan struct{}),
+		onClose:    onClose,
+	}
+	s.tActive.Store(time.Now().UnixNano())
+	return s
+}
+
+// push adds a received datagram to session inbox, dropping the
+// datagram if the inbox is full.
+func (s *sessionConn) push(data []byte) bool {
+	s.tActive.Store(time.Now().UnixNano())
+	select {
+	case s.inbox <- append([]byte(nil), data...):
+		return true
+	default:
+		return false
+	}
+}
+
+// idle returns the duration since last session activity.
+func (s *sessionConn) idle() time.Duration {
+	return time.Since(time.Unix(0, s.tActive.Load()))
+}
+
+// Read reads a single datagram, truncated to the size of b.
+func (s *sessionConn) Read(b []byte) (int, error) {
+	s.mu.Lock()
+	deadline := s.rDeadline
+	s.mu.Unlock()
+
+	var tC <-chan time.Time
+	if !deadline.IsZero() {
+		d := time.Until(deadline)
+		if d <= 0 {
+			return 0, os.ErrDeadlineExceeded
+		}
+		t := time.NewTimer(d)
+		defer t.Stop()
+		tC = t.C
+	}
+
+	select {
+	case data := <-s.inbox:
+		return copy(b, data), nil
+	case <-s.closeCh:
+		return 0, net.ErrClosed
+	case <-tC:
+		return 0, os.ErrDeadlineExceeded
+	}
+}
+
+// Write sends a datagram to the remote peer.
+func (s *sessionConn) Write(b []byte) (int, error) {
+	select {
+	case <-s.closeCh:
+		return 0, net.ErrClosed
+	default:
+	}
+	s.tActive.Store(time.Now().UnixNano())
+	return s.packetConn.WriteTo(b, s.addr)
+}
+
+// Close closes the session, the shared packet connection is not closed.
+func (s *sessionConn) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closeCh)
+		if s.onClose != nil {
+			s.onClose()
+		}
+	})
+	return nil
+}
+
+func (s *sessionConn) LocalAddr() net.Addr {
+	return s.packetConn.LocalAddr()
+}
+
+func (s *sessionConn) RemoteAddr() net.Addr {
+	return s.addr
+}
+
+func (s *sessionConn) SetDeadline(t time.Time) error {
+	return s.SetReadDeadline(t)
+}
+
+func (s *sessionConn) SetReadDeadline(t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rDeadline = t
+	return nil
+}
+
+// SetWriteDeadline takes no action, as write deadlines are not applied
+// on the shared packet connection.
+func (s *sessionConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+// startSessions runs the packet connection in session mode, demultiplexing
+// datagrams by remote address into virtual per-peer connections handled
+// by the connection handler. Sessions are closed after timeout seconds
+// of inactivity.
+func (l *Listener) startSessions(packetConn net.PacketConn, timeout float64) error {
+	tIdle := time.Duration(timeout * float64(time.Second))
+	tSweep := tIdle / 2
+	if tSweep > time.Second {
+		tSweep = time.Second
+	}
+
+	var mu sync.Mutex
+	sessions := map[string]*sessionConn{}
+
+	pool := comm.NewHandlerPool(l.CommLog, l.Options)
+
+	l.drainer.Reset()
+	l.stopEvent.Store(false)
+	l.isActive.Store(true)
+	defer func() {
+		// keep handlers running while draining
+		if !l.drainer.IsDraining() {
+			l.stopEvent.Store(true)
+		}
+		packetConn.Close()
+		mu.Lock()
+		active := make([]*sessionConn, 0, len(sessions))
+		for _, s := range sessions {
+			active = append(active, s)
+		}
+		mu.Unlock()
+		for _, s := range active {
+			s.Close()
+		}
+		// wait all connections handlers termination
+		pool.Stop()
+		l.LogMsg("CLOSED -- %s", l.Uri())
+		l.isActive.Store(false)
+	}()
+
+	tLastSweep := time.Now()
+	b := make([]byte, UDP_MAX_SIZE)
+	for !l.stopEvent.Load() {
+		// close expired sessions
+		if time.Since(tLastSweep) >= tSweep {
+			tLastSweep = time.Now()
+			expired := []*sessionConn{}
+			mu.Lock()
+			for _, s := range sessions {
+				if s.idle() > tIdle {
+					expired = append(expired, s)
+				}
+			}
+			mu.Unlock()
+			for _, s := range expired {
+				l.LogMsg("SESSION_EXPIRED -- %s", s.addr)
+				s.Close()
+			}
+		}
+
+		packetConn.SetReadDeadline(time.Now().Add(tSweep))
+		n, addr, err := packetConn.ReadFrom(b)
+		if err != nil {
+			if comm.IsClosedError(err) {
+				break
+			}
+			var netErr net.Error
+			if !errors.As(err, &netErr) || !netErr.Timeout() {
+				l.LogMsg("CONN_ERROR -- %v", err)
+			}
+			continue
+		}
+
+		key := addr.String()
+		mu.Lock()
+		s, ok := sessions[key]
+		if !ok {
+			// no new sessions while draining
+			if l.drainer.IsDraining() {
+				mu.Unlock()
+				continue
+			}
+			s = newSessionConn(packetConn, addr, func() {
+				mu.Lock()
+				defer mu.Unlock()
+				delete(sessions, key)
+			})
+			sessions[key] = s
+		}
+		mu.Unlock()
+
+		if !s.push(b[:n]) {
+			l.LogMsg("SESSION_DROPPED -- %s", addr)
+		}
+
+		// handle new session
+		if !ok && !pool.Submit(func() { l.handleConnection(s) }) {
+			l.LogMsg("CONN_REJECTED -- %s", addr)
+			s.Close()
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,309 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package netcomm_test
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/comm"
+	"github.com/exonlabs/go-utils/pkg/comm/netcomm"
+)
+
+// startListener starts a listener on uri and returns its local address.
+func startListener(t *testing.T, uri string, opts dictx.Dict,
+	h func(comm.Connection)) string {
+	l, err := netcomm.NewListener(uri, nil, opts)
+	require.NoError(t, err)
+	l.ConnectionHandler(h)
+	go l.Start()
+	t.Cleanup(l.Stop)
+	require.Eventually(t, l.IsActive, time.Second, 10*time.Millisecond)
+
+	switch v := l.NetListener().(type) {
+	case net.Listener:
+		return v.Addr().String()
+	case net.PacketConn:
+		return v.LocalAddr().String()
+	}
+	t.Fatal("invalid listener type")
+	return ""
+}
+
+// recvHandler returns a handler reporting received data and errors,
+// until the connection is closed.
+func recvHandler(dataCh chan<- []byte, errCh chan<- error) func(comm.Connection) {
+	return func(conn comm.Connection) {
+		for conn.IsOpened() {
+			b, err := conn.Recv(1)
+			if err == comm.ErrTimeout {
+				continue
+			}
+			if err != nil {
+				errCh <- err
+				if err == comm.ErrClosed {
+					return
+				}
+				continue
+			}
+			dataCh <- b
+		}
+	}
+}
+
+// assertPeerClosed checks the remote peer closed the connection.
+func assertPeerClosed(t *testing.T, conn net.Conn) {
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err := conn.Read(make([]byte, 16))
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestGuardFrameSize(t *testing.T) {
+	dataCh, errCh := make(chan []byte, 8), make(chan error, 8)
+	addr := startListener(t, "tcp@127.0.0.1:0", dictx.Dict{
+		"guard_max_frame_size": 8,
+	}, recvHandler(dataCh, errCh))
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("small"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("small"), <-dataCh)
+
+	// oversize frame closes the connection
+	_, err = conn.Write([]byte("oversized frame"))
+	require.NoError(t, err)
+	assert.ErrorIs(t, <-errCh, comm.ErrClosed)
+	assertPeerClosed(t, conn)
+}
+
+func TestGuardFrameTimeout(t *testing.T) {
+	dataCh, errCh := make(chan []byte, 8), make(chan error, 8)
+	addr := startListener(t, "tcp@127.0.0.1:0", dictx.Dict{
+		"poll_timeout":        0.1,
+		"guard_frame_timeout": 0.2,
+	}, recvHandler(dataCh, errCh))
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// slow frame bytes within poll timeout never complete the frame
+	go func() {
+		for i := 0; i < 20; i++ {
+			if _, err := conn.Write([]byte{byte(i)}); err != nil {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}()
+	assert.ErrorIs(t, <-errCh, comm.ErrClosed)
+	assert.Empty(t, dataCh)
+	assertPeerClosed(t, conn)
+}
+
+func TestGuardRate(t *testing.T) {
+	dataCh, errCh := make(chan []byte, 8), make(chan error, 8)
+	addr := startListener(t, "tcp@127.0.0.1:0", dictx.Dict{
+		"poll_timeout":     0.02,
+		"guard_max_rate":   1,
+		"guard_rate_burst": 2,
+	}, recvHandler(dataCh, errCh))
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// frames within burst are received
+	for _, s := range []string{"f1", "f2"} {
+		_, err = conn.Write([]byte(s))
+		require.NoError(t, err)
+		assert.Equal(t, []byte(s), <-dataCh)
+	}
+
+	// frames above rate close the connection
+	_, err = conn.Write([]byte("f3"))
+	require.NoError(t, err)
+	assert.ErrorIs(t, <-errCh, comm.ErrClosed)
+	assert.Empty(t, dataCh)
+	assertPeerClosed(t, conn)
+}
+
+func TestGuardPacketDropped(t *testing.T) {
+	dataCh, errCh := make(chan []byte, 8), make(chan error, 8)
+	addr := startListener(t, "udp@127.0.0.1:0", dictx.Dict{
+		"guard_max_frame_size": 8,
+	}, recvHandler(dataCh, errCh))
+
+	conn, err := net.Dial("udp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// oversize datagram is dropped, shared packet connection kept open
+	_, err = conn.Write([]byte("oversized datagram"))
+	require.NoError(t, err)
+	err = <-errCh
+	assert.ErrorIs(t, err, comm.ErrGuard)
+	assert.NotErrorIs(t, err, comm.ErrClosed)
+
+	_, err = conn.Write([]byte("small"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("small"), <-dataCh)
+}
+
+// rawServer accepts a single TCP connection and reports received data.
+func rawServer(t *testing.T) (string, <-chan []byte) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	dataCh := make(chan []byte, 16)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		b := make([]byte, 1024)
+		for {
+			n, err := conn.Read(b)
+			if err != nil {
+				close(dataCh)
+				return
+			}
+			dataCh <- append([]byte(nil), b[:n]...)
+		}
+	}()
+	return ln.Addr().String(), dataCh
+}
+
+// readFor collects the received data within d.
+func readFor(dataCh <-chan []byte, d time.Duration) []byte {
+	var res []byte
+	tC := time.After(d)
+	for {
+		select {
+		case b, ok := <-dataCh:
+			if !ok {
+				return res
+			}
+			res = append(res, b...)
+		case <-tC:
+			return res
+		}
+	}
+}
+
+func TestWriteCoalescing(t *testing.T) {
+	addr, dataCh := rawServer(t)
+	c, err := netcomm.NewConnection("tcp@"+addr, nil, dictx.Dict{
+		"write_coalesce_delay": 0.2,
+		"write_coalesce_size":  8,
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.Open(1))
+	defer c.Close()
+
+	// flushed when coalesced size is reached
+	require.NoError(t, c.Send([]byte("abcd"), 1))
+	assert.Empty(t, readFor(dataCh, 50*time.Millisecond))
+	require.NoError(t, c.Send([]byte("efgh"), 1))
+	assert.Equal(t, []byte("abcdefgh"), readFor(dataCh, 50*time.Millisecond))
+
+	// flushed by coalescing delay timer
+	require.NoError(t, c.Send([]byte("ab"), 1))
+	require.NoError(t, c.Send([]byte("cd"), 1))
+	assert.Empty(t, readFor(dataCh, 100*time.Millisecond))
+	assert.Equal(t, []byte("abcd"), readFor(dataCh, 300*time.Millisecond))
+
+	// flushed explicitly
+	require.NoError(t, c.Send([]byte("xy"), 1))
+	assert.Empty(t, readFor(dataCh, 50*time.Millisecond))
+	require.NoError(t, c.Flush())
+	assert.Equal(t, []byte("xy"), readFor(dataCh, 50*time.Millisecond))
+
+	// flushed on close
+	require.NoError(t, c.Send([]byte("end"), 1))
+	c.Close()
+	assert.Equal(t, []byte("end"), readFor(dataCh, time.Second))
+}
+
+func TestUdpSessions(t *testing.T) {
+	type session struct {
+		addr string
+		err  error
+	}
+	startCh, endCh := make(chan string, 8), make(chan session, 8)
+	addr := startListener(t, "udp@127.0.0.1:0", dictx.Dict{
+		"udp_session_timeout": 0.2,
+	}, func(conn comm.Connection) {
+		nc := conn.(*netcomm.Connection)
+		raddr := nc.NetConn().(net.Conn).RemoteAddr().String()
+		startCh <- raddr
+		for {
+			b, err := conn.Recv(0)
+			if err != nil {
+				endCh <- session{raddr, err}
+				return
+			}
+			conn.Send(append([]byte("echo:"), b...), 1)
+		}
+	})
+
+	// each peer address gets its own session
+	peers := []net.Conn{}
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("udp", addr)
+		require.NoError(t, err)
+		defer conn.Close()
+		peers = append(peers, conn)
+	}
+	b := make([]byte, 64)
+	for n := 0; n < 2; n++ {
+		for i, conn := range peers {
+			msg := []byte{'a' + byte(i)}
+			_, err := conn.Write(msg)
+			require.NoError(t, err)
+			conn.SetReadDeadline(time.Now().Add(time.Second))
+			k, err := conn.Read(b)
+			require.NoError(t, err)
+			assert.Equal(t, append([]byte("echo:"), msg...), b[:k])
+		}
+	}
+	started := []string{<-startCh, <-startCh}
+	assert.ElementsMatch(t, []string{
+		peers[0].LocalAddr().String(), peers[1].LocalAddr().String(),
+	}, started)
+	assert.Empty(t, startCh)
+
+	// idle sessions expire and close their connections
+	for range peers {
+		s := <-endCh
+		assert.Contains(t, started, s.addr)
+		assert.ErrorIs(t, s.err, comm.ErrClosed)
+	}
+
+	// new datagrams after expiry create a new session
+	_, err := peers[0].Write([]byte("x"))
+	require.NoError(t, err)
+	select {
+	case raddr := <-startCh:
+		assert.Equal(t, peers[0].LocalAddr().String(), raddr)
+	case <-time.After(time.Second):
+		t.Fatal("session not created")
+	}
+	peers[0].SetReadDeadline(time.Now().Add(time.Second))
+	k, err := peers[0].Read(b)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("echo:x"), b[:k])
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package comm
+
+import (
+	"bytes"
+	"runtime/debug"
+	"strings"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/logging"
+	"github.com/exonlabs/go-utils/pkg/sync/poolx"
+)
+
+const (
+	// POOL_QUEUE defines the overflow policy to queue pending jobs
+	// until a pool worker is available.
+	POOL_QUEUE = "queue"
+	// POOL_REJECT defines the overflow policy to reject jobs when
+	// all pool workers are busy and the waiting queue is full.
+	POOL_REJECT = "reject"
+)
+
+// HandlerPool dispatches connection handling jobs to a bounded number
+// of worker goroutines. A pool with zero size runs each job in its own
+// goroutine.
+type HandlerPool struct {
+	// policy defines the overflow policy when all workers are busy.
+	policy string
+
+	// pool defines the underlying workers pool.
+	pool *poolx.Pool[func()]
+}
+
+// NewHandlerPool creates a new handler pool from the parsed options.
+// Handler panics are recovered and logged with their stack trace to log,
+// if not nil.
+//
+// The parsed options are:
+//   - handler_pool_size: (int) the number of pool workers.
+//     use 0 to disable pool and run one goroutine per connection.
+//   - handler_pool_policy: (string) the overflow policy {queue|reject}.
+//     default is queue.
+//   - handler_queue_size: (int) the size of pending connections queue.
+//     default is the pool size.
+func NewHandlerPool(log *logging.Logger, opts dictx.Dict) *HandlerPool {
+	p := &HandlerPool{policy: POOL_QUEUE}
+	if size := dictx.GetInt(opts, "handler_pool_size", 0); size <= 0 {
+		p.pool = poolx.NewFunc(0, 0)
+	} else {
+		if v := strings.ToLower(dictx.GetString(
+			opts, "handler_pool_policy", POOL_QUEUE)); v == POOL_REJECT {
+			p.policy = POOL_REJECT
+		}
+		p.pool = poolx.NewFunc(
+			size, dictx.GetInt(opts, "handler_queue_size", size))
+	}
+
+	if log != nil {
+		p.pool.OnPanic = func(_ func(), r any) {
+			stack := debug.Stack()
+			if indx := bytes.Index(stack, []byte("panic({")); indx > 0 {
+				stack = stack[indx:]
+			}
+			log.Error("connection handler panic: %v", r)
+			log.Trace1("\n----------\n%s----------", stack)
+		}
+	}
+	return p
+}
+
+// Size returns the number of pool workers.
+func (p *HandlerPool) Size() int {
+	return p.pool.Size()
+}
+
+// Policy returns the pool overflow policy.
+func (p *HandlerPool) Policy() string {
+	return p.policy
+}
+
+// Stats returns a snapshot of the pool jobs counters.
+func (p *HandlerPool) Stats() poolx.Stats {
+	return p.pool.Stats()
+}
+
+// Submit dispatches a job to the pool. It returns false if the job was
+// rejected by the overflow policy.
+func (p *HandlerPool) Submit(fn func()) bool {
+	if p.policy == POOL_REJECT {
+		return p.pool.TrySubmit(fn) == nil
+	}
+	return p.pool.Submit(fn) == nil
+}
+
+// Stop waits for all pending and running jobs to finish,
+// then terminates the pool workers.
+func (p *HandlerPool) Stop() {
+	p.pool.Stop()
+}
@@ -0,0 +1,250 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package modbus
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/comm"
+)
+
+// Client sends modbus requests over a comm connection and matches
+// the received responses, using RTU or TCP framing.
+type Client struct {
+	// Conn defines the underlying comm connection.
+	Conn comm.Connection
+	// Mode defines the framing mode {rtu|tcp}.
+	Mode string
+	// Timeout defines the response timeout in seconds.
+	Timeout float64
+
+	// txid holds the last TCP transaction id.
+	txid uint16
+	// mu defines mutex for request/response transactions.
+	mu sync.Mutex
+}
+
+// NewClient creates a new modbus client over connection.
+func NewClient(conn comm.Connection, mode string) *Client {
+	return &Client{
+		Conn:    conn,
+		Mode:    mode,
+		Timeout: 1,
+	}
+}
+
+// Request sends request pdu to unit id and returns the matched
+// response pdu. Exception responses are returned as [ExceptionError].
+func (c *Client) Request(unit byte, pdu []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var frame []byte
+	var err error
+	var txid uint16
+	if c.Mode == MODE_TCP {
+		c.txid++
+		txid = c.txid
+		frame, err = EncodeTCP(txid, unit, pdu)
+	} else {
+		frame, err = EncodeRTU(unit, pdu)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Conn.Send(frame, c.Timeout); err != nil {
+		return nil, err
+	}
+
+	tBreak := time.Now().Add(time.Duration(c.Timeout * float64(time.Second)))
+	var buf []byte
+	for {
+		timeout := time.Until(tBreak).Seconds()
+		if timeout <= 0 {
+			return nil, comm.ErrTimeout
+		}
+		data, err := c.Conn.Recv(timeout)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, data...)
+
+		// extract and match complete frames from buffer
+		for len(buf) > 0 {
+			var resp []byte
+			var n int
+			if c.Mode == MODE_TCP {
+				resp, n, err = c.matchTCP(buf, txid, unit)
+			} else {
+				resp, n, err = c.matchRTU(buf, unit, pdu[0])
+			}
+			if n == 0 {
+				break
+			}
+			buf = buf[n:]
+			if err != nil {
+				// drop invalid or unmatched frames
+				continue
+			}
+			return resp, Exception(resp)
+		}
+	}
+}
+
+// matchTCP extracts a frame from buf and matches it to request.
+// It returns the response pdu and the consumed bytes count, where 0
+// count means incomplete frame.
+func (c *Client) matchTCP(buf []byte, txid uint16, unit byte) ([]byte, int, error) {
+	n := TCPFrameLen(buf)
+	if n == 0 || len(buf) < n {
+		return nil, 0, nil
+	}
+	rTxid, rUnit, pdu, err := DecodeTCP(buf[:n])
+	if err != nil {
+		// drop whole buffer on invalid header
+		return nil, len(buf), err
+	}
+	if rTxid != txid || rUnit != unit {
+		return nil, n, ErrFrame
+	}
+	return pdu, n, nil
+}
+
+// matchRTU extracts a frame from buf and matches it to request.
+// It returns the response pdu and the consumed bytes count, where 0
+// count means incomplete frame.
+func (c *Client) matchRTU(buf []byte, unit byte, fc byte) ([]byte, int, error) {
+	n := RTUFrameLen(buf)
+	if n == 0 {
+		if len(buf) < 3 {
+			return nil, 0, nil
+		}
+		// unknown function code, consider whole buffer as frame
+		n = len(buf)
+	}
+	if len(buf) < n {
+		return nil, 0, nil
+	}
+	rUnit, pdu, err := DecodeRTU(buf[:n])
+	if err != nil {
+		// drop whole buffer on invalid frame
+		return nil, len(buf), err
+	}
+	if rUnit != unit || pdu[0]&^FC_EXCEPTION_FLAG != fc {
+		return nil, n, ErrFrame
+	}
+	return pdu, n, nil
+}
+
+// readBits reads coils or discrete inputs.
+func (c *Client) readBits(unit, fc byte, addr, qty uint16) ([]bool, error) {
+	resp, err := c.Request(unit, buildPdu(fc, addr, qty))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) < 2 || len(resp) != 2+int(resp[1]) ||
+		int(resp[1]) < (int(qty)+7)/8 {
+		return nil, ErrFrame
+	}
+	res := make([]bool, qty)
+	for i := range res {
+		res[i] = resp[2+i/8]&(1<<(i%8)) != 0
+	}
+	return res, nil
+}
+
+// readRegisters reads holding or input registers.
+func (c *Client) readRegisters(unit, fc byte, addr, qty uint16) ([]uint16, error) {
+	resp, err := c.Request(unit, buildPdu(fc, addr, qty))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) < 2 || len(resp) != 2+int(resp[1]) ||
+		int(resp[1]) != 2*int(qty) {
+		return nil, ErrFrame
+	}
+	res := make([]uint16, qty)
+	for i := range res {
+		res[i] = binary.BigEndian.Uint16(resp[2+2*i:])
+	}
+	return res, nil
+}
+
+// ReadCoils reads qty coils starting from addr.
+func (c *Client) ReadCoils(unit byte, addr, qty uint16) ([]bool, error) {
+	return c.readBits(unit, FC_READ_COILS, addr, qty)
+}
+
+// ReadDiscreteInputs reads qty discrete inputs starting from addr.
+func (c *Client) ReadDiscreteInputs(unit byte, addr, qty uint16) ([]bool, error) {
+	return c.readBits(unit, FC_READ_DISCRETE_INPUTS, addr, qty)
+}
+
+// ReadHoldingRegisters reads qty holding registers starting from addr.
+func (c *Client) ReadHoldingRegisters(unit byte, addr, qty uint16) ([]uint16, error) {
+	return c.readRegisters(unit, FC_READ_HOLDING_REGISTERS, addr, qty)
+}
+
+// ReadInputRegisters reads qty input registers starting from addr.
+func (c *Client) ReadInputRegisters(unit byte, addr, qty uint16) ([]uint16, error) {
+	return c.readRegisters(unit, FC_READ_INPUT_REGISTERS, addr, qty)
+}
+
+// WriteSingleCoil writes a single coil value at addr.
+func (c *Client) WriteSingleCoil(unit byte, addr uint16, value bool) error {
+	v := uint16(0x0000)
+	if value {
+		v = 0xFF00
+	}
+	return c.writeSingle(unit, FC_WRITE_SINGLE_COIL, addr, v)
+}
+
+// WriteSingleRegister writes a single register value at addr.
+func (c *Client) WriteSingleRegister(unit byte, addr, value uint16) error {
+	return c.writeSingle(unit, FC_WRITE_SINGLE_REGISTER, addr, value)
+}
+
+// writeSingle writes single coil or register and validates echo response.
+func (c *Client) writeSingle(unit, fc byte, addr, value uint16) error {
+	pdu := buildPdu(fc, addr, value)
+	resp, err := c.Request(unit, pdu)
+	if err != nil {
+		return err
+	}
+	if string(resp) != string(pdu) {
+		return ErrFrame
+	}
+	return nil
+}
+
+// WriteMultipleRegisters writes register values starting from addr.
+func (c *Client) WriteMultipleRegisters(unit byte, addr uint16, values []uint16) error {
+	if len(values) == 0 || len(values) > 123 {
+		return errors.New("invalid registers count")
+	}
+	pdu := buildPdu(FC_WRITE_MULTIPLE_REGISTERS, addr, uint16(len(values)))
+	pdu = append(pdu, byte(2*len(values)))
+	for _, v := range values {
+		pdu = binary.BigEndian.AppendUint16(pdu, v)
+	}
+	resp, err := c.Request(unit, pdu)
+	if err != nil {
+		return err
+	}
+	if len(resp) != 5 || string(resp) != string(pdu[:5]) {
+		return ErrFrame
+	}
+	return nil
+}
+
+// buildPdu builds pdu with function code followed by two 16-bit values.
+func buildPdu(fc byte, addr, value uint16) []byte {
+	pdu := []byte{fc}
+	pdu = binary.BigEndian.AppendUint16(pdu, addr)
+	return binary.BigEndian.AppendUint16(pdu, value)
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package modbus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/exonlabs/go-utils/pkg/abc/crcx"
+)
+
+const (
+	// MODE_RTU defines the serial RTU framing mode.
+	MODE_RTU = "rtu"
+	// MODE_TCP defines the TCP framing mode with MBAP header.
+	MODE_TCP = "tcp"
+
+	// MAX_PDU_SIZE defines the max size of protocol data unit.
+	MAX_PDU_SIZE = 253
+	// MBAP_SIZE defines the size of TCP MBAP header including unit id.
+	MBAP_SIZE = 7
+)
+
+// Modbus function codes.
+const (
+	FC_READ_COILS               = 0x01
+	FC_READ_DISCRETE_INPUTS     = 0x02
+	FC_READ_HOLDING_REGISTERS   = 0x03
+	FC_READ_INPUT_REGISTERS     = 0x04
+	FC_WRITE_SINGLE_COIL        = 0x05
+	FC_WRITE_SINGLE_REGISTER    = 0x06
+	FC_WRITE_MULTIPLE_COILS     = 0x0F
+	FC_WRITE_MULTIPLE_REGISTERS = 0x10
+
+	// FC_EXCEPTION_FLAG defines the function code flag of exception responses.
+	FC_EXCEPTION_FLAG = 0x80
+)
+
+var (
+	// ErrFrame indicates invalid or incomplete frame.
+	ErrFrame = errors.New("invalid modbus frame")
+	// ErrCrc indicates RTU frame CRC mismatch.
+	ErrCrc = errors.New("modbus crc mismatch")
+)
+
+// ExceptionError represents a modbus exception response.
+type ExceptionError struct {
+	// Function defines the request function code.
+	Function byte
+	// Code defines the exception code.
+	Code byte
+}
+
+// Error returns the exception error message.
+func (e *ExceptionError) Error() string {
+	return fmt.Sprintf(
+		"modbus exception 0x%02X for function 0x%02X", e.Code, e.Function)
+}
+
+// Exception returns the exception error if pdu is an exception response.
+func Exception(pdu []byte) error {
+	if len(pdu) >= 2 && pdu[0]&FC_EXCEPTION_FLAG != 0 {
+		return &ExceptionError{
+			Function: pdu[0] &^ FC_EXCEPTION_FLAG,
+			Code:     pdu[1],
+		}
+	}
+	return nil
+}
+
+// Crc16 calculates the modbus CRC16 of data.
+func Crc16(data []byte) uint16 {
+	return uint16(crcx.Checksum(data, crcx.CRC16Modbus))
+}
+
+// EncodeRTU builds RTU frame for unit id and pdu.
+//
+//	RTU frame: unit(1) + pdu(N) + crc(2) little-endian
+func EncodeRTU(unit byte, pdu []byte) ([]byte, error) {
+	if len(pdu) == 0 || len(pdu) > MAX_PDU_SIZE {
+		return nil, ErrFrame
+	}
+	b := make([]byte, 0, len(pdu)+3)
+	b = append(b, unit)
+	b = append(b, pdu...)
+	return binary.LittleEndian.AppendUint16(b, Crc16(b)), nil
+}
+
+// DecodeRTU validates RTU frame and returns its unit id and pdu.
+func DecodeRTU(frame []byte) (byte, []byte, error) {
+	if len(frame) < 4 {
+		return 0, nil, ErrFrame
+	}
+	n := len(frame) - 2
+	if Crc16(frame[:n]) != binary.LittleEndian.Uint16(frame[n:]) {
+		return 0, nil, ErrCrc
+	}
+	return frame[0], frame[1:n], nil
+}
+
+// RTUFrameLen returns the expected length of RTU response frame from its
+// leading bytes, or 0 if not enough bytes or unknown function code.
+func RTUFrameLen(b []byte) int {
+	if len(b) < 2 {
+		return 0
+	}
+	fc := b[1]
+	if fc&FC_EXCEPTION_FLAG != 0 {
+		return 5
+	}
+	switch fc {
+	case FC_READ_COILS, FC_READ_DISCRETE_INPUTS,
+		FC_READ_HOLDING_REGISTERS, FC_READ_INPUT_REGISTERS:
+		if len(b) < 3 {
+			return 0
+		}
+		return 5 + int(b[2])
+	case FC_WRITE_SINGLE_COIL, FC_WRITE_SINGLE_REGISTER,
+		FC_WRITE_MULTIPLE_COILS, FC_WRITE_MULTIPLE_REGISTERS:
+		return 8
+	}
+	return 0
+}
+
+// EncodeTCP builds TCP frame for transaction id, unit id and pdu.
+//
+//	TCP frame: txid(2) + proto(2)=0 + length(2) + unit(1) + pdu(N)
+func EncodeTCP(txid uint16, unit byte, pdu []byte) ([]byte, error) {
+	if len(pdu) == 0 || len(pdu) > MAX_PDU_SIZE {
+		return nil, ErrFrame
+	}
+	b := make([]byte, 0, len(pdu)+MBAP_SIZE)
+	b = binary.BigEndian.AppendUint16(b, txid)
+	b = binary.BigEndian.AppendUint16(b, 0)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(pdu)+1))
+	b = append(b, unit)
+	return append(b, pdu...), nil
+}
+
+// DecodeTCP validates TCP frame and returns its transaction id,
+// unit id and pdu.
+func DecodeTCP(frame []byte) (uint16, byte, []byte, error) {
+	n := TCPFrameLen(frame)
+	if n <= MBAP_SIZE || len(frame) != n {
+		return 0, 0, nil, ErrFrame
+	}
+	if binary.BigEndian.Uint16(frame[2:4]) != 0 {
+		return 0, 0, nil, ErrFrame
+	}
+	return binary.BigEndian.Uint16(frame[0:2]), frame[6], frame[MBAP_SIZE:], nil
+}
+
+// TCPFrameLen returns the length of TCP frame from its MBAP header,
+// or 0 if not enough bytes.
+func TCPFrameLen(b []byte) int {
+	if len(b) < 6 {
+		return 0
+	}
+	return 6 + int(binary.BigEndian.Uint16(b[4:6]))
+}
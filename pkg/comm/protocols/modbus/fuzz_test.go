@@ -0,0 +1,49 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package modbus_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/exonlabs/go-utils/pkg/comm/protocols/modbus"
+)
+
+func FuzzDecodeRTU(f *testing.F) {
+	f.Add([]byte{0x01, 0x03, 0x02, 0x00, 0x2A, 0x38, 0x5B})
+	f.Add([]byte{0x01, 0x83, 0x02, 0xC0, 0xF1})
+	f.Add([]byte{0x01, 0x06, 0x00})
+	f.Add([]byte{})
+	f.Fuzz(func(t *testing.T, frame []byte) {
+		modbus.RTUFrameLen(frame)
+		unit, pdu, err := modbus.DecodeRTU(frame)
+		if err != nil {
+			return
+		}
+		modbus.Exception(pdu)
+		b, err := modbus.EncodeRTU(unit, pdu)
+		if err == nil && !bytes.Equal(b, frame) {
+			t.Fatalf("round trip failed: % X != % X", b, frame)
+		}
+	})
+}
+
+func FuzzDecodeTCP(f *testing.F) {
+	f.Add([]byte{0x00, 0x01, 0x00, 0x00, 0x00, 0x03, 0x01, 0x83, 0x02})
+	f.Add([]byte{0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x01})
+	f.Add([]byte{0x00, 0x01, 0x00, 0x00, 0xFF, 0xFF})
+	f.Add([]byte{})
+	f.Fuzz(func(t *testing.T, frame []byte) {
+		txid, unit, pdu, err := modbus.DecodeTCP(frame)
+		if err != nil {
+			return
+		}
+		modbus.Exception(pdu)
+		b, err := modbus.EncodeTCP(txid, unit, pdu)
+		if err == nil && !bytes.Equal(b, frame) {
+			t.Fatalf("round trip failed: % X != % X", b, frame)
+		}
+	})
+}
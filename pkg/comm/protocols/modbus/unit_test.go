@@ -0,0 +1,126 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package modbus_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/comm"
+	"github.com/exonlabs/go-utils/pkg/comm/memcomm"
+	"github.com/exonlabs/go-utils/pkg/comm/protocols/modbus"
+)
+
+func TestCrc16(t *testing.T) {
+	// read holding registers request: unit 1, addr 0, qty 10
+	frame, err := modbus.EncodeRTU(1, []byte{0x03, 0x00, 0x00, 0x00, 0x0A})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A, 0xC5, 0xCD}, frame)
+
+	unit, pdu, err := modbus.DecodeRTU(frame)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(1), unit)
+	assert.Equal(t, []byte{0x03, 0x00, 0x00, 0x00, 0x0A}, pdu)
+
+	frame[2] ^= 0xFF
+	_, _, err = modbus.DecodeRTU(frame)
+	assert.ErrorIs(t, err, modbus.ErrCrc)
+}
+
+func TestTCPFrame(t *testing.T) {
+	frame, err := modbus.EncodeTCP(0x1234, 5, []byte{0x03, 0x00, 0x01, 0x00, 0x02})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{
+		0x12, 0x34, 0x00, 0x00, 0x00, 0x06, 0x05,
+		0x03, 0x00, 0x01, 0x00, 0x02}, frame)
+	assert.Equal(t, len(frame), modbus.TCPFrameLen(frame))
+
+	txid, unit, pdu, err := modbus.DecodeTCP(frame)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(0x1234), txid)
+	assert.Equal(t, byte(5), unit)
+	assert.Equal(t, []byte{0x03, 0x00, 0x01, 0x00, 0x02}, pdu)
+
+	_, _, _, err = modbus.DecodeTCP(frame[:8])
+	assert.ErrorIs(t, err, modbus.ErrFrame)
+}
+
+// serve runs a test modbus server with registers on connection.
+func serve(conn comm.Connection, mode string, regs []uint16) {
+	for {
+		b, err := conn.Recv(0)
+		if err != nil {
+			return
+		}
+
+		var txid uint16
+		var unit byte
+		var pdu []byte
+		if mode == modbus.MODE_TCP {
+			txid, unit, pdu, err = modbus.DecodeTCP(b)
+		} else {
+			unit, pdu, err = modbus.DecodeRTU(b)
+		}
+		if err != nil {
+			continue
+		}
+
+		addr := binary.BigEndian.Uint16(pdu[1:])
+		value := binary.BigEndian.Uint16(pdu[3:])
+		var resp []byte
+		switch {
+		case pdu[0] == modbus.FC_READ_HOLDING_REGISTERS &&
+			int(addr)+int(value) <= len(regs):
+			resp = []byte{pdu[0], byte(2 * value)}
+			for _, v := range regs[addr : addr+value] {
+				resp = binary.BigEndian.AppendUint16(resp, v)
+			}
+		case pdu[0] == modbus.FC_WRITE_SINGLE_REGISTER && int(addr) < len(regs):
+			regs[addr] = value
+			resp = pdu
+		default:
+			resp = []byte{pdu[0] | modbus.FC_EXCEPTION_FLAG, 0x02}
+		}
+
+		var frame []byte
+		if mode == modbus.MODE_TCP {
+			frame, _ = modbus.EncodeTCP(txid, unit, resp)
+		} else {
+			frame, _ = modbus.EncodeRTU(unit, resp)
+			// send unmatched unit frame first
+			b, _ := modbus.EncodeRTU(unit+1, resp)
+			conn.Send(b, 0)
+		}
+		conn.Send(frame, 0)
+	}
+}
+
+func TestClient(t *testing.T) {
+	for _, mode := range []string{modbus.MODE_RTU, modbus.MODE_TCP} {
+		c1, c2 := memcomm.NewPipe(nil, nil)
+		go serve(c2, mode, []uint16{10, 20, 30, 40})
+
+		cl := modbus.NewClient(c1, mode)
+		regs, err := cl.ReadHoldingRegisters(1, 1, 2)
+		assert.NoError(t, err, mode)
+		assert.Equal(t, []uint16{20, 30}, regs, mode)
+
+		assert.NoError(t, cl.WriteSingleRegister(1, 3, 99), mode)
+		regs, err = cl.ReadHoldingRegisters(1, 3, 1)
+		assert.NoError(t, err, mode)
+		assert.Equal(t, []uint16{99}, regs, mode)
+
+		_, err = cl.ReadHoldingRegisters(1, 3, 5)
+		var exc *modbus.ExceptionError
+		assert.ErrorAs(t, err, &exc, mode)
+		assert.Equal(t, byte(0x02), exc.Code)
+
+		c1.Close()
+		c2.Close()
+	}
+}
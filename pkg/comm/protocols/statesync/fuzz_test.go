@@ -0,0 +1,39 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package statesync_test
+
+import (
+	"testing"
+
+	"github.com/exonlabs/go-utils/pkg/comm/protocols/statesync"
+)
+
+func FuzzDecode(f *testing.F) {
+	for _, msg := range []*statesync.Message{
+		{Type: statesync.MSG_SNAPSHOT, Seq: 1, Data: map[string]any{"a": 1.0}},
+		{Type: statesync.MSG_DIFF, Seq: 2, Set: map[string]any{"b": "x"},
+			Del: []string{"a"}},
+		{Type: statesync.MSG_RESYNC},
+	} {
+		b, _ := statesync.Encode(msg, 0)
+		f.Add(b)
+		b, _ = statesync.Encode(msg, 1)
+		f.Add(b)
+	}
+	f.Add([]byte{0x00, 0x00, 0x00, 0x01, 0x01})
+	f.Add([]byte{0xFF, 0xFF, 0xFF, 0xFF})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		msg, n, err := statesync.Decode(data)
+		if err != nil || n == 0 {
+			return
+		}
+		if n > len(data) {
+			t.Fatalf("consumed %d bytes of %d", n, len(data))
+		}
+		if _, err := statesync.Encode(msg, 0); err != nil {
+			t.Fatalf("encode decoded message failed: %v", err)
+		}
+	})
+}
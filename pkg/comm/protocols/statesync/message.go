@@ -0,0 +1,116 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package statesync
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+)
+
+const (
+	// MSG_SNAPSHOT defines the full state snapshot message.
+	MSG_SNAPSHOT = "snapshot"
+	// MSG_DIFF defines the incremental state changes message.
+	MSG_DIFF = "diff"
+	// MSG_RESYNC defines the subscriber request for full snapshot.
+	MSG_RESYNC = "resync"
+
+	// COMPRESS_SIZE defines the default payload size that enables
+	// compression of messages.
+	COMPRESS_SIZE = 512
+	// MAX_FRAME_SIZE defines the max size of message frames.
+	MAX_FRAME_SIZE = 16 << 20
+
+	// flagDeflate marks deflate compressed payloads.
+	flagDeflate = 0x01
+)
+
+// ErrFrame indicates invalid message frame.
+var ErrFrame = errors.New("invalid sync frame")
+
+// Message represents a state sync message.
+//
+// Values are carried as JSON, and decoded as the JSON types: numbers as
+// float64, objects as map[string]any, arrays as []any, besides string,
+// bool and nil. Use the dictx getters, such as [dictx.GetInt], to read
+// numbers as other types.
+type Message struct {
+	// Type defines the message type {snapshot|diff|resync}.
+	Type string `json:"type"`
+	// Seq defines the state sequence number after applying the message.
+	Seq uint64 `json:"seq"`
+	// Data holds the full state of snapshot messages.
+	Data dictx.Dict `json:"data,omitempty"`
+	// Set holds the changed values by nested key of diff messages.
+	Set map[string]any `json:"set,omitempty"`
+	// Del holds the deleted nested keys of diff messages.
+	Del []string `json:"del,omitempty"`
+}
+
+// Encode encodes message into frame, compressing payloads larger than
+// compressSize bytes. use compressSize=0 to disable compression.
+//
+//	frame: <length:4 bytes> <flags:1 byte> <payload>
+func Encode(msg *Message, compressSize int) ([]byte, error) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var flags byte
+	if compressSize > 0 && len(payload) > compressSize {
+		var buf bytes.Buffer
+		w, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+		w.Write(payload)
+		w.Close()
+		if buf.Len() < len(payload) {
+			payload = buf.Bytes()
+			flags |= flagDeflate
+		}
+	}
+
+	frame := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(frame, uint32(1+len(payload)))
+	frame[4] = flags
+	return append(frame, payload...), nil
+}
+
+// Decode decodes the first message frame in data, and returns the message
+// and the number of consumed bytes. It returns zero consumed bytes if data
+// has no complete frame.
+func Decode(data []byte) (*Message, int, error) {
+	if len(data) < 4 {
+		return nil, 0, nil
+	}
+	n := int(binary.BigEndian.Uint32(data))
+	if n < 1 || n > MAX_FRAME_SIZE {
+		return nil, 0, ErrFrame
+	}
+	if len(data) < 4+n {
+		return nil, 0, nil
+	}
+
+	flags, payload := data[4], data[5:4+n]
+	if flags&flagDeflate != 0 {
+		b, err := io.ReadAll(io.LimitReader(
+			flate.NewReader(bytes.NewReader(payload)), MAX_FRAME_SIZE))
+		if err != nil {
+			return nil, 0, ErrFrame
+		}
+		payload = b
+	}
+
+	msg := &Message{}
+	if err := json.Unmarshal(payload, msg); err != nil {
+		return nil, 0, ErrFrame
+	}
+	return msg, 4 + n, nil
+}
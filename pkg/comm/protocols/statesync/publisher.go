@@ -0,0 +1,210 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package statesync
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/comm"
+)
+
+// Publisher holds a state dict replicated to subscribers over comm
+// connections, sending a full snapshot on subscription followed by
+// incremental diffs with sequence numbers.
+//
+// Values are stored as decoded from JSON, see [Message], so the publisher
+// and subscribers hold the same state values and types.
+type Publisher struct {
+	// CompressSize defines the payload size that enables compression.
+	// use 0 to disable compression.
+	CompressSize int
+	// SendTimeout defines the timeout in seconds for sending messages.
+	SendTimeout float64
+
+	// state holds the published state.
+	state dictx.Dict
+	// seq holds the state sequence number.
+	seq uint64
+	// subs holds the subscribers connections.
+	subs map[comm.Connection]bool
+	// mu defines mutex for state and subscribers.
+	mu sync.Mutex
+	// sendMu serializes sending messages in sequence order, without
+	// holding the state mutex while sending.
+	sendMu sync.Mutex
+}
+
+// NewPublisher creates a new state publisher.
+// The parsed options are:
+//   - compress_size: (int) the payload size that enables compression.
+//     use 0 to disable compression. (default is 512)
+//   - send_timeout: (float64) the timeout in seconds for sending messages,
+//     slow subscribers are dropped on timeout. (default is 1)
+func NewPublisher(opts dictx.Dict) *Publisher {
+	return &Publisher{
+		CompressSize: dictx.GetInt(opts, "compress_size", COMPRESS_SIZE),
+		SendTimeout:  dictx.GetFloat(opts, "send_timeout", 1),
+		state:        dictx.Dict{},
+		subs:         map[comm.Connection]bool{},
+	}
+}
+
+// Seq returns the current state sequence number.
+func (p *Publisher) Seq() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.seq
+}
+
+// Snapshot returns a copy of the current state and its sequence number.
+func (p *Publisher) Snapshot() (dictx.Dict, uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	d, _ := dictx.Clone(p.state)
+	return d, p.seq
+}
+
+// Set sets the value of nested key and publishes the change.
+func (p *Publisher) Set(key string, value any) error {
+	return p.Apply(map[string]any{key: value}, nil)
+}
+
+// Delete deletes nested key and publishes the change.
+func (p *Publisher) Delete(key string) error {
+	return p.Apply(nil, []string{key})
+}
+
+// Apply sets and deletes nested keys as single change, and publishes
+// the change to subscribers. Values failing JSON encoding are refused
+// without changing the state.
+func (p *Publisher) Apply(set map[string]any, del []string) error {
+	if len(set) == 0 && len(del) == 0 {
+		return nil
+	}
+	set, err := normalize(set)
+	if err != nil {
+		return err
+	}
+
+	p.sendMu.Lock()
+	defer p.sendMu.Unlock()
+
+	p.mu.Lock()
+	for _, k := range del {
+		dictx.Delete(p.state, k)
+	}
+	for k, v := range set {
+		dictx.Set(p.state, k, v)
+	}
+	p.seq++
+	if len(p.subs) == 0 {
+		p.mu.Unlock()
+		return nil
+	}
+	frame, err := Encode(&Message{
+		Type: MSG_DIFF, Seq: p.seq, Set: set, Del: del}, p.CompressSize)
+	subs := make([]comm.Connection, 0, len(p.subs))
+	for conn := range p.subs {
+		subs = append(subs, conn)
+	}
+	p.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, conn := range subs {
+		if err := conn.Send(frame, p.SendTimeout); err != nil {
+			// drop subscriber, resyncs on reconnect
+			p.mu.Lock()
+			delete(p.subs, conn)
+			p.mu.Unlock()
+			go conn.Close()
+		}
+	}
+	return nil
+}
+
+// normalize returns the set values as decoded by subscribers.
+func normalize(set map[string]any) (map[string]any, error) {
+	if len(set) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(set)
+	if err != nil {
+		return nil, err
+	}
+	var res map[string]any
+	if err := json.Unmarshal(b, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// sendSnapshot sends full state snapshot to connection.
+func (p *Publisher) sendSnapshot(conn comm.Connection) error {
+	p.sendMu.Lock()
+	defer p.sendMu.Unlock()
+
+	p.mu.Lock()
+	frame, err := Encode(&Message{
+		Type: MSG_SNAPSHOT, Seq: p.seq, Data: p.state}, p.CompressSize)
+	p.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := conn.Send(frame, p.SendTimeout); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.subs[conn] = true
+	p.mu.Unlock()
+	return nil
+}
+
+// Serve handles a subscriber connection, sending the full state snapshot
+// then the state changes, and answering resync requests until the
+// connection is closed. It can be used as listener connection handler.
+func (p *Publisher) Serve(conn comm.Connection) {
+	defer func() {
+		p.mu.Lock()
+		delete(p.subs, conn)
+		p.mu.Unlock()
+	}()
+
+	if err := p.sendSnapshot(conn); err != nil {
+		return
+	}
+
+	var buf []byte
+	for conn.IsOpened() && !comm.IsDraining(conn) {
+		data, err := conn.Recv(0)
+		if err != nil {
+			if err == comm.ErrClosed || comm.IsDraining(conn) {
+				return
+			}
+			continue
+		}
+		buf = append(buf, data...)
+		for {
+			msg, n, err := Decode(buf)
+			if err != nil {
+				conn.Close()
+				return
+			}
+			if n == 0 {
+				break
+			}
+			buf = buf[n:]
+			if msg.Type == MSG_RESYNC {
+				if err := p.sendSnapshot(conn); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
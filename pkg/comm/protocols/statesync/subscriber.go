@@ -0,0 +1,170 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package statesync
+
+import (
+	"sync"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/comm"
+)
+
+// Subscriber mirrors a publisher state over a comm connection, applying
+// the received snapshots and diffs, and requesting full resync when
+// a sequence gap is detected.
+type Subscriber struct {
+	// Conn defines the underlying comm connection.
+	Conn comm.Connection
+
+	// OnChange is called after applying received snapshots and diffs.
+	// For snapshots, set holds the full state and del is empty.
+	OnChange func(set map[string]any, del []string)
+
+	// state holds the mirrored state.
+	state dictx.Dict
+	// seq holds the state sequence number.
+	seq uint64
+	// synced marks the state as synced with publisher.
+	synced bool
+	// buf holds the received partial frames.
+	buf []byte
+	// mu defines mutex for state.
+	mu sync.Mutex
+}
+
+// NewSubscriber creates a new state subscriber over connection.
+func NewSubscriber(conn comm.Connection) *Subscriber {
+	return &Subscriber{
+		Conn:  conn,
+		state: dictx.Dict{},
+	}
+}
+
+// IsSynced checks if the state is synced with publisher.
+func (s *Subscriber) IsSynced() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.synced
+}
+
+// Seq returns the current state sequence number.
+func (s *Subscriber) Seq() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seq
+}
+
+// Get returns the value of nested key, or defaultValue if not found.
+func (s *Subscriber) Get(key string, defaultValue any) any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return dictx.Get(s.state, key, defaultValue)
+}
+
+// State returns a copy of the mirrored state.
+func (s *Subscriber) State() dictx.Dict {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, _ := dictx.Clone(s.state)
+	return d
+}
+
+// Resync requests full state snapshot from publisher.
+func (s *Subscriber) Resync() error {
+	s.mu.Lock()
+	s.synced = false
+	s.mu.Unlock()
+
+	frame, err := Encode(&Message{Type: MSG_RESYNC}, 0)
+	if err != nil {
+		return err
+	}
+	return s.Conn.Send(frame, 1)
+}
+
+// Recv waits for incoming messages until timeout and applies them.
+// Setting timeout=0 will wait indefinitely.
+func (s *Subscriber) Recv(timeout float64) error {
+	data, err := s.Conn.Recv(timeout)
+	if err != nil {
+		return err
+	}
+
+	s.buf = append(s.buf, data...)
+	for {
+		msg, n, err := Decode(s.buf)
+		if err != nil {
+			s.buf = nil
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		s.buf = s.buf[n:]
+		if err := s.apply(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// Run receives and applies messages until the connection is closed.
+func (s *Subscriber) Run() error {
+	for {
+		if err := s.Recv(0); err != nil && err != comm.ErrTimeout {
+			if err == ErrFrame {
+				if err := s.Resync(); err == nil {
+					continue
+				}
+			}
+			return err
+		}
+	}
+}
+
+// apply applies a received message on state.
+func (s *Subscriber) apply(msg *Message) error {
+	s.mu.Lock()
+
+	switch msg.Type {
+	case MSG_SNAPSHOT:
+		if msg.Data == nil {
+			msg.Data = dictx.Dict{}
+		}
+		s.state = msg.Data
+		s.seq = msg.Seq
+		s.synced = true
+		s.mu.Unlock()
+		if s.OnChange != nil {
+			s.OnChange(msg.Data, nil)
+		}
+		return nil
+
+	case MSG_DIFF:
+		// ignore diffs until resync snapshot
+		if !s.synced {
+			s.mu.Unlock()
+			return nil
+		}
+		if msg.Seq != s.seq+1 {
+			s.mu.Unlock()
+			return s.Resync()
+		}
+		for _, k := range msg.Del {
+			dictx.Delete(s.state, k)
+		}
+		for k, v := range msg.Set {
+			dictx.Set(s.state, k, v)
+		}
+		s.seq = msg.Seq
+		s.mu.Unlock()
+		if s.OnChange != nil {
+			s.OnChange(msg.Set, msg.Del)
+		}
+		return nil
+	}
+
+	s.mu.Unlock()
+	return nil
+}
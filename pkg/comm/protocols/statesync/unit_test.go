@@ -0,0 +1,153 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package statesync_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/comm/memcomm"
+	"github.com/exonlabs/go-utils/pkg/comm/protocols/statesync"
+)
+
+func TestFrame(t *testing.T) {
+	msg := &statesync.Message{
+		Type: statesync.MSG_DIFF,
+		Seq:  7,
+		Set:  map[string]any{"a.b": strings.Repeat("x", 1000)},
+		Del:  []string{"c"},
+	}
+	frame, err := statesync.Encode(msg, 512)
+	require.NoError(t, err)
+	assert.Less(t, len(frame), 1000)
+
+	// partial frame
+	m, n, err := statesync.Decode(frame[:10])
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Nil(t, m)
+
+	// joined frames
+	frame2, _ := statesync.Encode(&statesync.Message{Type: "resync"}, 0)
+	data := append(append([]byte{}, frame...), frame2...)
+	m, n, err = statesync.Decode(data)
+	assert.NoError(t, err)
+	assert.Equal(t, len(frame), n)
+	assert.Equal(t, msg, m)
+	m, _, err = statesync.Decode(data[n:])
+	assert.NoError(t, err)
+	assert.Equal(t, "resync", m.Type)
+
+	_, _, err = statesync.Decode([]byte{0, 0, 0, 2, 0, '{'})
+	assert.ErrorIs(t, err, statesync.ErrFrame)
+}
+
+func TestSync(t *testing.T) {
+	pub := statesync.NewPublisher(nil)
+	pub.Set("status.mode", "auto")
+	pub.Set("status.temp", 21.5)
+
+	c1, c2 := memcomm.NewPipe(nil, nil)
+	defer c1.Close()
+	go pub.Serve(c1)
+
+	sub := statesync.NewSubscriber(c2)
+	require.NoError(t, sub.Recv(1))
+	assert.True(t, sub.IsSynced())
+	assert.Equal(t, uint64(2), sub.Seq())
+	assert.Equal(t, "auto", sub.Get("status.mode", nil))
+
+	// incremental diffs
+	pub.Apply(map[string]any{"status.temp": 22.0, "alarms.high": true},
+		[]string{"status.mode"})
+	require.NoError(t, sub.Recv(1))
+	assert.Equal(t, uint64(3), sub.Seq())
+	assert.Equal(t, 22.0, sub.Get("status.temp", nil))
+	assert.Equal(t, true, sub.Get("alarms.high", nil))
+	assert.Nil(t, sub.Get("status.mode", nil))
+
+	// resync on sequence gap
+	frame, _ := statesync.Encode(&statesync.Message{
+		Type: statesync.MSG_DIFF, Seq: 10,
+		Set: map[string]any{"status.temp": 0}}, 0)
+	require.NoError(t, c1.Send(frame, 1))
+	require.NoError(t, sub.Recv(1))
+	assert.False(t, sub.IsSynced())
+	require.NoError(t, sub.Recv(1))
+	assert.True(t, sub.IsSynced())
+	assert.Equal(t, 22.0, sub.Get("status.temp", nil))
+
+	state, seq := pub.Snapshot()
+	assert.Equal(t, seq, sub.Seq())
+	assert.Equal(t, state, sub.State())
+
+	// pending diffs delivered to running subscriber
+	done := make(chan error)
+	go func() { done <- sub.Run() }()
+	pub.Set("status.temp", 23.0)
+	for i := 0; i < 100 && sub.Seq() != pub.Seq(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 23.0, sub.Get("status.temp", nil))
+	c2.Close()
+	assert.Error(t, <-done)
+}
+
+func TestSyncTypes(t *testing.T) {
+	pub := statesync.NewPublisher(nil)
+	require.NoError(t, pub.Apply(map[string]any{
+		"count":  3,
+		"limits": map[string]int{"high": 80},
+		"tags":   []string{"a", "b"},
+	}, nil))
+	assert.Error(t, pub.Set("bad", make(chan int)))
+	assert.Equal(t, uint64(1), pub.Seq())
+
+	c1, c2 := memcomm.NewPipe(nil, nil)
+	defer c1.Close()
+	go pub.Serve(c1)
+
+	sub := statesync.NewSubscriber(c2)
+	require.NoError(t, sub.Recv(1))
+	require.NoError(t, pub.Set("limits.low", 10))
+	require.NoError(t, sub.Recv(1))
+
+	// publisher and subscriber hold the same decoded types
+	state, _ := pub.Snapshot()
+	assert.Equal(t, state, sub.State())
+	assert.Equal(t, float64(3), sub.Get("count", nil))
+	assert.Equal(t, 3, dictx.GetInt(sub.State(), "count", 0))
+	assert.Equal(t, float64(80), dictx.Get(state, "limits.high", nil))
+	assert.Equal(t, []any{"a", "b"}, sub.Get("tags", nil))
+}
+
+func TestSyncSlowSubscriber(t *testing.T) {
+	pub := statesync.NewPublisher(dictx.Dict{"send_timeout": 1})
+	c1, c2 := memcomm.NewPipe(nil, dictx.Dict{"inbox_size": 1})
+	defer c1.Close()
+	go pub.Serve(c1)
+	time.Sleep(100 * time.Millisecond)
+
+	// blocked send to full subscriber inbox keeps the state readable
+	done := make(chan error)
+	go func() { done <- pub.Set("status.temp", 21.5) }()
+	time.Sleep(100 * time.Millisecond)
+	tStart := time.Now()
+	assert.Equal(t, uint64(1), pub.Seq())
+	state, _ := pub.Snapshot()
+	assert.Equal(t, 21.5, dictx.Get(state, "status.temp", nil))
+	assert.Less(t, time.Since(tStart), 100*time.Millisecond)
+
+	sub := statesync.NewSubscriber(c2)
+	require.NoError(t, sub.Recv(1))
+	require.NoError(t, <-done)
+	require.NoError(t, sub.Recv(1))
+	assert.Equal(t, 21.5, sub.Get("status.temp", nil))
+}
@@ -0,0 +1,438 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package comm
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+)
+
+const (
+	// SEND_QUEUE_SIZE defines the default max number of queued messages.
+	SEND_QUEUE_SIZE = 1024
+
+	// SLOW_CONSUMER_LOG defines the slow consumer policy to log only.
+	SLOW_CONSUMER_LOG = "log"
+	// SLOW_CONSUMER_DISCONNECT defines the slow consumer policy to
+	// close the connection.
+	SLOW_CONSUMER_DISCONNECT = "disconnect"
+	// SLOW_CONSUMER_DROP defines the slow consumer policy to degrade
+	// by dropping new messages until the consumer catches up, where
+	// dropped messages fail with [ErrQueueFull].
+	SLOW_CONSUMER_DROP = "drop"
+
+	// FLUSH_DISCARD defines the flush policy to discard the pending
+	// messages on close.
+	FLUSH_DISCARD = "discard"
+	// FLUSH_DRAIN defines the flush policy to send the pending messages
+	// on close, waiting up to the flush timeout.
+	FLUSH_DRAIN = "drain"
+)
+
+// AsyncSender defines the connections supporting asynchronous sending.
+type AsyncSender interface {
+	// SendAsync queues data for sending and returns a channel delivering
+	// the sending result.
+	SendAsync(data []byte) <-chan error
+	// SendToAsync queues data for sending to addr and returns a channel
+	// delivering the sending result.
+	SendToAsync(data []byte, addr any) <-chan error
+}
+
+// SendQueueStats represents the sending queue statistics.
+type SendQueueStats struct {
+	// Depth defines the number of queued messages.
+	Depth int
+	// Bytes defines the size of queued messages in bytes.
+	Bytes int
+	// Lag defines the age in seconds of the oldest queued message.
+	Lag float64
+	// Sent defines the number of sent messages.
+	Sent uint64
+	// Dropped defines the number of dropped messages.
+	Dropped uint64
+	// SlowEvents defines the number of times the peer was marked
+	// as slow consumer.
+	SlowEvents uint64
+}
+
+// sendItem represents a queued message.
+type sendItem struct {
+	data  []byte
+	addr  any
+	tPush time.Time
+	// done delivers the sending result for async messages.
+	done chan error
+}
+
+// result delivers the sending result for async messages.
+func (i *sendItem) result(err error) {
+	if i.done != nil {
+		i.done <- err
+	}
+}
+
+// SendQueueConnection wraps a Connection with a bounded sending queue
+// drained by a writer routine, and tracks the queue depth and consumer
+// lag to detect slow consumers. When a slow consumer threshold is
+// exceeded, the peer is marked slow, the SlowConsumer callback is called
+// and the slow consumer policy is applied.
+type SendQueueConnection struct {
+	Connection
+
+	// QueueSize defines the max number of queued messages. sending fails
+	// with [ErrQueueFull] when the queue is full.
+	QueueSize int
+	// MaxDepth defines the queued messages threshold of slow consumer.
+	MaxDepth int
+	// MaxBytes defines the queued bytes threshold of slow consumer.
+	MaxBytes int
+	// MaxLag defines the consumer lag threshold in seconds of slow consumer.
+	MaxLag float64
+	// Policy defines the slow consumer policy {log|disconnect|drop}.
+	Policy string
+	// SendTimeout defines the timeout in seconds for sending each message.
+	SendTimeout float64
+	// FlushPolicy defines the pending messages policy on close
+	// {discard|drain}.
+	FlushPolicy string
+	// FlushTimeout defines the timeout in seconds for draining the
+	// pending messages on close.
+	FlushTimeout float64
+
+	// SlowConsumer is called when the peer is marked as slow consumer.
+	SlowConsumer func(c *SendQueueConnection, stats SendQueueStats)
+
+	// queue holds the pending messages.
+	queue []sendItem
+	// stats holds the queue statistics.
+	stats SendQueueStats
+	// isSlow marks the peer as slow consumer.
+	isSlow bool
+
+	// pushCh signals the writer routine for new messages.
+	pushCh chan struct{}
+	// stopCh signals the writer routine to stop, nil when not running.
+	stopCh chan struct{}
+	// mu defines mutex for queue, stats and writer routine state.
+	mu sync.Mutex
+	// waitGrp defines wait group for writer routine.
+	waitGrp sync.WaitGroup
+}
+
+// NewSendQueueConnection creates a new sending queue wrapper for connection.
+// The writer routine starts when the connection is opened, or immediately
+// if the connection is already opened.
+// The parsed options are:
+//   - send_queue_size: (int) the max number of queued messages.
+//     (default is 1024)
+//   - slow_consumer_depth: (int) the queued messages threshold.
+//   - slow_consumer_bytes: (int) the queued bytes threshold.
+//   - slow_consumer_lag: (float64) the consumer lag threshold in seconds.
+//   - slow_consumer_policy: (string) the slow consumer policy
+//     {log|disconnect|drop}. (default is log)
+//   - send_timeout: (float64) the timeout in seconds for sending each
+//     message. use 0 to wait indefinitely. (default is 0)
+//   - send_flush_policy: (string) the pending messages policy on close
+//     {discard|drain}. (default is discard)
+//   - send_flush_timeout: (float64) the timeout in seconds for draining
+//     pending messages on close. use 0 to wait indefinitely. (default is 0)
+func NewSendQueueConnection(conn Connection, opts dictx.Dict) *SendQueueConnection {
+	c := &SendQueueConnection{
+		Connection:  conn,
+		QueueSize:   dictx.GetInt(opts, "send_queue_size", SEND_QUEUE_SIZE),
+		MaxDepth:    dictx.GetInt(opts, "slow_consumer_depth", 0),
+		MaxBytes:    dictx.GetInt(opts, "slow_consumer_bytes", 0),
+		MaxLag:      dictx.GetFloat(opts, "slow_consumer_lag", 0),
+		SendTimeout: dictx.GetFloat(opts, "send_timeout", 0),
+		Policy: strings.ToLower(dictx.GetString(
+			opts, "slow_consumer_policy", SLOW_CONSUMER_LOG)),
+		FlushPolicy: strings.ToLower(dictx.GetString(
+			opts, "send_flush_policy", FLUSH_DISCARD)),
+		FlushTimeout: dictx.GetFloat(opts, "send_flush_timeout", 0),
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = SEND_QUEUE_SIZE
+	}
+
+	if conn.IsOpened() {
+		c.start()
+	}
+	return c
+}
+
+// IsSendQueueEnabled checks if the sending queue is configured in options.
+func IsSendQueueEnabled(opts dictx.Dict) bool {
+	return dictx.GetInt(opts, "send_queue_size", 0) > 0
+}
+
+// start runs the writer routine. The routine channels are created for
+// each run, and cleared when the routine exits.
+func (c *SendQueueConnection) start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopCh != nil {
+		return
+	}
+	c.pushCh = make(chan struct{}, 1)
+	c.stopCh = make(chan struct{})
+
+	c.waitGrp.Add(1)
+	go c.writer(c.pushCh, c.stopCh)
+}
+
+// stop signals the writer routine to stop.
+func (c *SendQueueConnection) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopCh != nil {
+		close(c.stopCh)
+		c.stopCh = nil
+	}
+}
+
+// Stats returns a snapshot of the sending queue statistics.
+func (c *SendQueueConnection) Stats() SendQueueStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.snapshot()
+}
+
+// snapshot returns the queue statistics. requires queue lock to be held.
+func (c *SendQueueConnection) snapshot() SendQueueStats {
+	st := c.stats
+	st.Depth = len(c.queue)
+	if len(c.queue) > 0 {
+		st.Lag = time.Since(c.queue[0].tPush).Seconds()
+	}
+	return st
+}
+
+// IsSlow checks if the peer is currently marked as slow consumer.
+func (c *SendQueueConnection) IsSlow() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.isSlow
+}
+
+// isExceeded checks the slow consumer thresholds for stats.
+func (c *SendQueueConnection) isExceeded(st SendQueueStats) bool {
+	return (c.MaxDepth > 0 && st.Depth > c.MaxDepth) ||
+		(c.MaxBytes > 0 && st.Bytes > c.MaxBytes) ||
+		(c.MaxLag > 0 && st.Lag > c.MaxLag)
+}
+
+// Flush waits until all queued messages are sent, up to timeout seconds.
+// Setting timeout=0 will wait indefinitely.
+func (c *SendQueueConnection) Flush(timeout float64) error {
+	var tBreak time.Time
+	if timeout > 0 {
+		tBreak = time.Now().Add(time.Duration(timeout * float64(time.Second)))
+	}
+	for {
+		c.mu.Lock()
+		n, running := len(c.queue), c.stopCh != nil
+		c.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+		if !running || !c.Connection.IsOpened() {
+			return ErrClosed
+		}
+		if timeout > 0 && time.Now().After(tBreak) {
+			return ErrTimeout
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Open establishes the connection and starts the writer routine.
+func (c *SendQueueConnection) Open(timeout float64) error {
+	if err := c.Connection.Open(timeout); err != nil {
+		return err
+	}
+	c.start()
+	return nil
+}
+
+// Close stops the writer routine and terminates the connection.
+// Pending queued messages are sent or discarded according to FlushPolicy,
+// and the discarded async messages get [ErrClosed] result.
+func (c *SendQueueConnection) Close() {
+	if c.FlushPolicy == FLUSH_DRAIN {
+		c.Flush(c.FlushTimeout)
+	}
+
+	c.stop()
+	c.Connection.CancelSend()
+	c.Connection.Close()
+	c.waitGrp.Wait()
+
+	// discard pending messages
+	c.mu.Lock()
+	for i := range c.queue {
+		c.queue[i].result(ErrClosed)
+		c.stats.Dropped++
+	}
+	c.queue = nil
+	c.stats.Bytes = 0
+	c.mu.Unlock()
+}
+
+// Send queues data for sending over the connection.
+// The timeout argument is ignored, see SendTimeout.
+func (c *SendQueueConnection) Send(data []byte, timeout float64) error {
+	return c.SendTo(data, nil, timeout)
+}
+
+// SendTo queues data for sending to addr over the connection.
+// The timeout argument is ignored, see SendTimeout.
+func (c *SendQueueConnection) SendTo(data []byte, addr any, timeout float64) error {
+	return c.push(data, addr, nil)
+}
+
+// SendAsync queues data for sending over the connection, and returns
+// a channel delivering the sending result once the message is sent
+// or dropped.
+func (c *SendQueueConnection) SendAsync(data []byte) <-chan error {
+	return c.SendToAsync(data, nil)
+}
+
+// SendToAsync queues data for sending to addr over the connection, and
+// returns a channel delivering the sending result once the message is
+// sent or dropped.
+func (c *SendQueueConnection) SendToAsync(data []byte, addr any) <-chan error {
+	done := make(chan error, 1)
+	if err := c.push(data, addr, done); err != nil {
+		done <- err
+	}
+	return done
+}
+
+// push adds a message to the sending queue.
+func (c *SendQueueConnection) push(data []byte, addr any, done chan error) error {
+	c.mu.Lock()
+
+	if c.stopCh == nil || !c.Connection.IsOpened() {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+
+	// degrade by dropping new messages for slow consumer
+	if (c.isSlow && c.Policy == SLOW_CONSUMER_DROP) ||
+		len(c.queue) >= c.QueueSize {
+		c.stats.Dropped++
+		c.mu.Unlock()
+		return ErrQueueFull
+	}
+
+	c.queue = append(c.queue, sendItem{
+		data:  append([]byte(nil), data...),
+		addr:  addr,
+		tPush: time.Now(),
+		done:  done,
+	})
+	c.stats.Bytes += len(data)
+	pushCh := c.pushCh
+
+	var slowStats *SendQueueStats
+	if !c.isSlow {
+		if st := c.snapshot(); c.isExceeded(st) {
+			c.isSlow = true
+			c.stats.SlowEvents++
+			st.SlowEvents = c.stats.SlowEvents
+			slowStats = &st
+		}
+	}
+	c.mu.Unlock()
+
+	select {
+	case pushCh <- struct{}{}:
+	default:
+	}
+
+	if slowStats != nil {
+		if err := c.slowConsumer(*slowStats); err != nil && done == nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// slowConsumer applies the slow consumer policy.
+func (c *SendQueueConnection) slowConsumer(st SendQueueStats) error {
+	if c.SlowConsumer != nil {
+		c.SlowConsumer(c, st)
+	}
+	if ctx, ok := c.Connection.(interface{ LogMsg(string, ...any) }); ok {
+		ctx.LogMsg("SLOW_CONSUMER -- depth=%d bytes=%d lag=%.3fs",
+			st.Depth, st.Bytes, st.Lag)
+	}
+	if c.Policy == SLOW_CONSUMER_DISCONNECT {
+		go c.Close()
+		return ErrClosed
+	}
+	return nil
+}
+
+// writer sends the queued messages over the connection.
+func (c *SendQueueConnection) writer(pushCh, stopCh chan struct{}) {
+	defer c.waitGrp.Done()
+	defer func() {
+		// clear the routine state if not already stopped or restarted
+		c.mu.Lock()
+		if c.stopCh == stopCh {
+			c.stopCh = nil
+		}
+		c.mu.Unlock()
+	}()
+
+	for {
+		c.mu.Lock()
+		if len(c.queue) == 0 {
+			c.mu.Unlock()
+			select {
+			case <-stopCh:
+				return
+			case <-pushCh:
+			}
+			continue
+		}
+		item := c.queue[0]
+		c.mu.Unlock()
+
+		err := c.Connection.SendTo(item.data, item.addr, c.SendTimeout)
+
+		c.mu.Lock()
+		c.queue[0] = sendItem{}
+		c.queue = c.queue[1:]
+		c.stats.Bytes -= len(item.data)
+		if err == nil {
+			c.stats.Sent++
+		} else {
+			c.stats.Dropped++
+		}
+		// clear slow consumer mark when caught up
+		if c.isSlow && !c.isExceeded(c.snapshot()) {
+			c.isSlow = false
+		}
+		c.mu.Unlock()
+		item.result(err)
+
+		if err == ErrClosed {
+			return
+		}
+		select {
+		case <-stopCh:
+			return
+		default:
+		}
+	}
+}
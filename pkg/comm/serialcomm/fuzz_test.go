@@ -0,0 +1,30 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package serialcomm_test
+
+import (
+	"testing"
+
+	"github.com/exonlabs/go-utils/pkg/comm/serialcomm"
+)
+
+func FuzzParseUri(f *testing.F) {
+	for _, s := range []string{
+		"serial@/dev/ttyS0:115200:8N1", "SERIAL@COM1:9600:7E2",
+		"serial@/dev/ttyUSB0:9600:8X1", "serial@/dev/tty:abc:8N1",
+		"serial@/dev/tty:9600", "serial@:::", "serial@", "",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, uri string) {
+		_, mode, err := serialcomm.ParseUri(uri)
+		if err != nil {
+			return
+		}
+		if mode.DataBits < 0 || mode.DataBits > 9 {
+			t.Fatalf("invalid data bits %d for uri %q", mode.DataBits, uri)
+		}
+	})
+}
@@ -154,6 +154,9 @@ func (sc *Connection) SerialPort() serial.Port {
 
 // Parent retrieves the parent Listener, if any, associated with the Connection.
 func (sc *Connection) Parent() comm.Listener {
+	if sc.parent == nil {
+		return nil
+	}
 	return sc.parent
 }
 
@@ -180,6 +183,7 @@ func (sc *Connection) Open(timeout float64) error {
 	com, err := serial.Open(sc.port, &sc.mode)
 	if err != nil {
 		sc.LogMsg("OPEN_FAIL -- %v", err)
+		sc.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
 		return fmt.Errorf("%w, %v", comm.ErrConnection, err)
 	}
 	sc.serialPort = com
@@ -187,6 +191,7 @@ func (sc *Connection) Open(timeout float64) error {
 	sc.serialPort.ResetOutputBuffer()
 
 	sc.LogMsg("OPENED -- %s", sc.Uri())
+	sc.EmitEvent(comm.EVENT_CONNECTED, nil, nil, nil)
 	sc.closeEvent.Store(false)
 	sc.isOpened.Store(true)
 	return nil
@@ -216,6 +221,7 @@ func (sc *Connection) Close() {
 
 	sc.rwWaitGrp.Wait()
 	sc.LogMsg("CLOSED -- %s", sc.Uri())
+	sc.EmitEvent(comm.EVENT_DISCONNECTED, nil, nil, nil)
 	sc.isOpened.Store(false)
 }
 
@@ -271,10 +277,12 @@ func (sc *Connection) SendTo(data []byte, _ any, timeout float64) error {
 		if comm.IsClosedError(err) {
 			sc.closeEvent.Store(true)
 			sc.LogMsg("PORT_CLOSED -- %v", err)
+			sc.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
 			go sc.Close()
 			return comm.ErrClosed
 		}
 		sc.LogMsg("SEND_ERROR -- %v", err)
+		sc.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
 		sc.serialPort.ResetOutputBuffer()
 		return fmt.Errorf("%w, %v", comm.ErrWrite, err)
 	}
@@ -332,10 +340,12 @@ func (sc *Connection) RecvFrom(timeout float64) ([]byte, any, error) {
 			if comm.IsClosedError(err) {
 				sc.closeEvent.Store(true)
 				sc.LogMsg("PORT_CLOSED -- %v", err)
+				sc.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
 				go sc.Close()
 				return nil, nil, comm.ErrClosed
 			}
 			sc.LogMsg("RECV_ERROR -- %v", err)
+			sc.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
 			return nil, nil, fmt.Errorf("%w, %v", comm.ErrRead, err)
 		}
 
@@ -381,12 +391,17 @@ type Listener struct {
 	// isActive represents the listener status, started or stopped.
 	isActive atomic.Bool
 
+	// drainer tracks the serial connection for graceful stop.
+	drainer comm.Drainer
+
 	// sMutex defines mutex for state change operations (start/stop).
 	sMutex sync.Mutex
 }
 
 // NewListener creates a new Listener for the specified URI, with
-// optional logging and connection limit.
+// optional logging and connection limit. The serial connection is wrapped
+// with heartbeat handling if the heartbeat_interval option is set, see
+// [comm.NewHeartbeatConnection].
 func NewListener(uri string, log *logging.Logger, opts dictx.Dict) (*Listener, error) {
 	conn, err := NewConnection(uri, log, opts)
 	if err != nil {
@@ -437,20 +452,54 @@ func (l *Listener) Start() error {
 	}
 	l.serialConn.parent = l
 
+	l.drainer.Reset()
 	l.isActive.Store(true)
 	defer func() {
 		l.serialConn.parent = nil
 		l.serialConn.Close()
 		l.isActive.Store(false)
 	}()
+	defer l.drainer.Track(l.serialConn, l.Stop)()
+
+	// wrap with heartbeat handling if enabled
+	var conn comm.Connection = l.serialConn
+	if comm.IsHeartbeatEnabled(l.Options) {
+		hc := comm.NewHeartbeatConnection(l.serialConn, l.Options)
+		defer hc.Close()
+		conn = hc
+	}
 
 	// run connection handler
-	l.connectionHandler(l.serialConn)
+	l.connectionHandler(conn)
 
 	return nil
 }
 
-// Stop gracefully shuts down the listener.
+// IsDraining checks if a graceful stop is in progress.
+func (l *Listener) IsDraining() bool {
+	return l.drainer.IsDraining()
+}
+
+// StopGraceful notifies the connection handler via the drain event,
+// see [comm.IsDraining], then waits up to timeout seconds for handler
+// termination before closing the serial connection.
+// Setting timeout=0 will wait indefinitely.
+func (l *Listener) StopGraceful(timeout float64) {
+	// stop immediately if not active
+	if !l.IsActive() {
+		l.Stop()
+		return
+	}
+
+	l.LogMsg("DRAINING -- %s", l.Uri())
+	l.drainer.Drain()
+	if !l.drainer.Wait(timeout) {
+		l.LogMsg("DRAIN_TIMEOUT -- %s", l.Uri())
+	}
+	l.Stop()
+}
+
+// Stop shuts down the listener, closing the serial connection.
 func (l *Listener) Stop() {
 	// do nothing if already stopped
 	if !l.isActive.Load() {
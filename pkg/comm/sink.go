@@ -0,0 +1,106 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package comm
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// EventType defines the type of communication event.
+type EventType uint8
+
+// Communication event types.
+const (
+	EVENT_CONNECTED    EventType = iota + 1 // Connection established
+	EVENT_DISCONNECTED                      // Connection terminated
+	EVENT_TX_FRAME                          // Data frame transmitted
+	EVENT_RX_FRAME                          // Data frame received
+	EVENT_ERROR                             // Communication error
+)
+
+// String returns the string representation of the event type.
+func (t EventType) String() string {
+	switch t {
+	case EVENT_CONNECTED:
+		return "Connected"
+	case EVENT_DISCONNECTED:
+		return "Disconnected"
+	case EVENT_TX_FRAME:
+		return "TxFrame"
+	case EVENT_RX_FRAME:
+		return "RxFrame"
+	case EVENT_ERROR:
+		return "Error"
+	}
+	return "Unknown"
+}
+
+// Event represents a typed communication event.
+type Event struct {
+	// Type defines the event type.
+	Type EventType
+	// Time defines the event timestamp.
+	Time time.Time
+	// Uri defines the URI of connection emitting the event.
+	Uri string
+	// Addr defines the remote address of frame events, if available.
+	Addr any
+	// Data defines the frame data of frame events. the data buffer is
+	// owned by the connection and must not be modified or retained.
+	Data []byte
+	// Err defines the error of error and disconnect events.
+	Err error
+}
+
+// EventSink defines the interface for handling communication events.
+// Events are delivered synchronously from the communication routines,
+// so sinks should return quickly.
+type EventSink interface {
+	HandleEvent(ev *Event)
+}
+
+// EventSinkFunc is an adapter to use functions as event sinks.
+type EventSinkFunc func(ev *Event)
+
+// HandleEvent calls f(ev).
+func (f EventSinkFunc) HandleEvent(ev *Event) {
+	f(ev)
+}
+
+// sinkHolder wraps the event sink for atomic storage.
+type sinkHolder struct {
+	sink EventSink
+}
+
+// eventSink holds the global event sink.
+var eventSink atomic.Pointer[sinkHolder]
+
+// SetEventSink sets the global sink receiving the communication events
+// of all connections. Use nil to disable events.
+func SetEventSink(sink EventSink) {
+	if sink == nil {
+		eventSink.Store(nil)
+		return
+	}
+	eventSink.Store(&sinkHolder{sink: sink})
+}
+
+// EmitEvent sends a communication event for the connection URI to the
+// global event sink, if set.
+func (c *Context) EmitEvent(typ EventType, addr any, data []byte, err error) {
+	h := eventSink.Load()
+	if h == nil {
+		return
+	}
+	h.sink.HandleEvent(&Event{
+		Type: typ,
+		Time: time.Now(),
+		Uri:  c.uri,
+		Addr: addr,
+		Data: data,
+		Err:  err,
+	})
+}
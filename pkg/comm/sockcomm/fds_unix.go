@@ -0,0 +1,254 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package sockcomm
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/comm"
+	"golang.org/x/sys/unix"
+)
+
+// MAX_FILES defines the max number of files passed in a single message.
+const MAX_FILES = 64
+
+// unixConn returns the underlying unix socket connection, unwrapping the
+// connections of a limited listener (see connections_limit option).
+func unixConn(c net.Conn) (*net.UnixConn, error) {
+	for c != nil {
+		if uc, ok := c.(*net.UnixConn); ok {
+			return uc, nil
+		}
+		v := reflect.ValueOf(c)
+		if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+			break
+		}
+		f := v.Elem().FieldByName("Conn")
+		if !f.IsValid() || !f.CanInterface() {
+			break
+		}
+		c, _ = f.Interface().(net.Conn)
+	}
+	return nil, errors.New("not a unix socket connection")
+}
+
+// SendFiles transmits data along with the open files descriptors over the
+// connection, with a specified timeout. The files remain owned by caller.
+func (c *Connection) SendFiles(data []byte, files []*os.File, timeout float64) error {
+	if len(data) == 0 {
+		return errors.New("empty data")
+	}
+	if len(files) > MAX_FILES {
+		return fmt.Errorf("too many files, max %d", MAX_FILES)
+	}
+
+	// Acquire write lock
+	c.wMutex.Lock()
+	defer c.wMutex.Unlock()
+
+	// Check connection state after acquiring the lock
+	if c.closeEvent.Load() || !c.isOpened.Load() {
+		return comm.ErrClosed
+	}
+
+	uc, err := unixConn(c.netConn)
+	if err != nil {
+		return fmt.Errorf("%w, %v", comm.ErrWrite, err)
+	}
+
+	c.rwWaitGrp.Add(1)
+	defer c.rwWaitGrp.Done()
+
+	fds := make([]int, len(files))
+	for i, f := range files {
+		fds[i] = int(f.Fd())
+	}
+
+	c.LogTx(data, nil)
+	c.LogMsg("SEND_FILES -- %d", len(fds))
+	if timeout > 0 {
+		uc.SetWriteDeadline(time.Now().Add(
+			time.Duration(timeout * float64(time.Second))))
+	}
+	n, _, err := uc.WriteMsgUnix(data, unix.UnixRights(fds...), nil)
+	if err == nil && n != len(data) {
+		err = errors.New("partial data sent")
+	}
+
+	if err != nil {
+		if comm.IsClosedError(err) {
+			c.closeEvent.Store(true)
+			c.LogMsg("CONN_CLOSED -- %v", err)
+			c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
+			go c.Close()
+			return comm.ErrClosed
+		}
+		c.LogMsg("SEND_ERROR -- %v", err)
+		c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
+		return fmt.Errorf("%w, %v", comm.ErrWrite, err)
+	}
+
+	return nil
+}
+
+// RecvFiles waits for incoming data along with the passed files over the
+// connection until a timeout or interrupt event occurs. Setting timeout=0
+// will wait indefinitely. The caller owns and closes the returned files.
+func (c *Connection) RecvFiles(timeout float64) ([]byte, []*os.File, error) {
+	// Acquire read lock
+	c.rMutex.Lock()
+	defer c.rMutex.Unlock()
+
+	// Check connection state after acquiring the lock
+	if c.closeEvent.Load() || !c.isOpened.Load() {
+		return nil, nil, comm.ErrClosed
+	}
+
+	uc, err := unixConn(c.netConn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w, %v", comm.ErrRead, err)
+	}
+
+	c.rwWaitGrp.Add(1)
+	defer c.rwWaitGrp.Done()
+
+	c.breakReadEvent.Store(false)
+
+	tPoll := time.Duration(c.PollTimeout * float64(time.Second))
+	if tPoll <= 0 {
+		tPoll = time.Duration(comm.POLL_TIMEOUT * float64(time.Second))
+	}
+
+	var tBreak time.Time
+	if timeout > 0 {
+		tBreak = time.Now().Add(
+			time.Duration(timeout * float64(time.Second)))
+	}
+
+	b := make([]byte, c.PollChunkSize)
+	oob := make([]byte, unix.CmsgSpace(MAX_FILES*4))
+	for {
+		uc.SetReadDeadline(time.Now().Add(tPoll))
+		n, oobn, _, _, err := uc.ReadMsgUnix(b, oob)
+		if err != nil {
+			if comm.IsClosedError(err) {
+				c.closeEvent.Store(true)
+				c.LogMsg("CONN_CLOSED -- %v", err)
+				c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
+				go c.Close()
+				return nil, nil, comm.ErrClosed
+			}
+			if _, ok := err.(net.Error); !ok || !err.(net.Error).Timeout() {
+				c.LogMsg("RECV_ERROR -- %v", err)
+				c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
+				return nil, nil, fmt.Errorf("%w, %v", comm.ErrRead, err)
+			}
+		}
+
+		if n > 0 || oobn > 0 {
+			files, err := parseRights(oob[:oobn])
+			if err != nil {
+				c.LogMsg("RECV_ERROR -- %v", err)
+				c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
+				return nil, nil, fmt.Errorf("%w, %v", comm.ErrRead, err)
+			}
+			data := b[:n]
+			c.LogRx(data, nil)
+			c.LogMsg("RECV_FILES -- %d", len(files))
+			return data, files, nil
+		}
+
+		if c.parent != nil && c.parent.stopEvent.Load() {
+			return nil, nil, comm.ErrClosed
+		}
+		if c.breakReadEvent.Load() {
+			return nil, nil, comm.ErrBreak
+		}
+		if timeout > 0 && time.Now().After(tBreak) {
+			return nil, nil, comm.ErrTimeout
+		}
+	}
+}
+
+// parseRights returns the files passed in the socket control messages.
+func parseRights(oob []byte) ([]*os.File, error) {
+	msgs, err := unix.ParseSocketControlMessage(oob)
+	if err != nil {
+		return nil, err
+	}
+	var files []*os.File
+	for i := range msgs {
+		fds, err := unix.ParseUnixRights(&msgs[i])
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			files = append(files, os.NewFile(uintptr(fd), "sockcomm-fd"))
+		}
+	}
+	return files, nil
+}
+
+// SendListeners hands over the listening sockets of listeners to the peer
+// process over the connection, the peer receives them via [RecvListeners].
+// The listeners keep running and should be stopped once the peer confirms
+// its listeners are started.
+func SendListeners(c *Connection, listeners []comm.FileListener, timeout float64) error {
+	if len(listeners) == 0 {
+		return errors.New("empty listeners")
+	}
+
+	uris := make([]string, len(listeners))
+	files := make([]*os.File, len(listeners))
+	defer func() {
+		for _, f := range files {
+			if f != nil {
+				f.Close()
+			}
+		}
+	}()
+	for i, l := range listeners {
+		f, err := l.File()
+		if err != nil {
+			return fmt.Errorf("%s, %v", l.Uri(), err)
+		}
+		uris[i] = l.Uri()
+		files[i] = f
+	}
+
+	return c.SendFiles([]byte(strings.Join(uris, "\n")), files, timeout)
+}
+
+// RecvListeners receives the listening sockets handed over by the peer
+// process via [SendListeners], and registers them as inherited files,
+// see [comm.SetInheritedFile]. The listeners created afterwards with the
+// same URIs resume on the received sockets. It returns the received URIs.
+func RecvListeners(c *Connection, timeout float64) ([]string, error) {
+	data, files, err := c.RecvFiles(timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	uris := strings.Split(string(data), "\n")
+	if len(uris) != len(files) {
+		for _, f := range files {
+			f.Close()
+		}
+		return nil, fmt.Errorf("%w, mismatched listeners count (%d uris, %d files)",
+			comm.ErrRead, len(uris), len(files))
+	}
+	for i, u := range uris {
+		comm.SetInheritedFile(u, files[i])
+	}
+	return uris, nil
+}
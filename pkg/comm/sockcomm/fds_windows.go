@@ -0,0 +1,40 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build windows
+
+package sockcomm
+
+import (
+	"errors"
+	"os"
+
+	"github.com/exonlabs/go-utils/pkg/comm"
+)
+
+// MAX_FILES defines the max number of files passed in a single message.
+const MAX_FILES = 64
+
+// errNoFiles indicates that files passing is not available on windows.
+var errNoFiles = errors.New("files passing not supported on windows")
+
+// SendFiles is not supported on windows.
+func (c *Connection) SendFiles(data []byte, files []*os.File, timeout float64) error {
+	return errNoFiles
+}
+
+// RecvFiles is not supported on windows.
+func (c *Connection) RecvFiles(timeout float64) ([]byte, []*os.File, error) {
+	return nil, nil, errNoFiles
+}
+
+// SendListeners is not supported on windows.
+func SendListeners(c *Connection, listeners []comm.FileListener, timeout float64) error {
+	return errNoFiles
+}
+
+// RecvListeners is not supported on windows.
+func RecvListeners(c *Connection, timeout float64) ([]string, error) {
+	return nil, errNoFiles
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package sockcomm_test
+
+import (
+	"testing"
+
+	"github.com/exonlabs/go-utils/pkg/comm/sockcomm"
+)
+
+func FuzzParseUri(f *testing.F) {
+	for _, s := range []string{
+		"sock@/tmp/app.sock", "SOCK@relative/../path", "sock@", "sock",
+		"sock@@", "", "tcp@/tmp/x",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, uri string) {
+		path, err := sockcomm.ParseUri(uri)
+		if err != nil {
+			return
+		}
+		if p, err := sockcomm.ParseUri("sock@" + path); err != nil || p != path {
+			t.Fatalf("round trip failed for %q: %q, %v", uri, p, err)
+		}
+	})
+}
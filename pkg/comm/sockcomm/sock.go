@@ -107,6 +107,9 @@ func (c *Connection) NetConn() net.Conn {
 
 // Parent retrieves the parent Listener, if any, associated with the Connection.
 func (c *Connection) Parent() comm.Listener {
+	if c.parent == nil {
+		return nil
+	}
 	return c.parent
 }
 
@@ -140,9 +143,11 @@ func (c *Connection) Open(timeout float64) error {
 	conn, err := dialer.Dial("unix", c.path)
 	if err != nil {
 		c.LogMsg("CONNECT_FAIL -- %v", err)
+		c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
 		return fmt.Errorf("%w, %v", comm.ErrConnection, err)
 	}
 	c.LogMsg("CONNECTED -- %s", c.Uri())
+	c.EmitEvent(comm.EVENT_CONNECTED, nil, nil, nil)
 	c.netConn = conn
 
 	c.closeEvent.Store(false)
@@ -172,6 +177,7 @@ func (c *Connection) Close() {
 
 	c.rwWaitGrp.Wait()
 	c.LogMsg("DISCONNECTED -- %s", c.Uri())
+	c.EmitEvent(comm.EVENT_DISCONNECTED, nil, nil, nil)
 	c.isOpened.Store(false)
 }
 
@@ -227,10 +233,12 @@ func (c *Connection) SendTo(data []byte, addr any, timeout float64) error {
 		if comm.IsClosedError(err) {
 			c.closeEvent.Store(true)
 			c.LogMsg("CONN_CLOSED -- %v", err)
+			c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
 			go c.Close()
 			return comm.ErrClosed
 		}
 		c.LogMsg("SEND_ERROR -- %v", err)
+		c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
 		return fmt.Errorf("%w, %v", comm.ErrWrite, err)
 	}
 
@@ -279,6 +287,7 @@ func (c *Connection) RecvFrom(timeout float64) ([]byte, any, error) {
 	}
 
 	var data []byte
+	var tFrame time.Time
 
 	b := make([]byte, nRead)
 	for {
@@ -288,17 +297,25 @@ func (c *Connection) RecvFrom(timeout float64) ([]byte, any, error) {
 			if comm.IsClosedError(err) {
 				c.closeEvent.Store(true)
 				c.LogMsg("CONN_CLOSED -- %v", err)
+				c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
 				go c.Close()
 				return nil, nil, comm.ErrClosed
 			}
 			if _, ok := err.(net.Error); !ok || !err.(net.Error).Timeout() {
 				c.LogMsg("RECV_ERROR -- %v", err)
+				c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
 				return nil, nil, fmt.Errorf("%w, %v", comm.ErrRead, err)
 			}
 		}
 
 		if n > 0 {
+			if tFrame.IsZero() {
+				tFrame = time.Now()
+			}
 			data = append(data, b[:n]...)
+			if err := c.Guard.CheckSize(len(data)); err != nil {
+				return nil, nil, c.guardClose(err)
+			}
 			if c.PollMaxSize > 0 {
 				nRead -= n
 				if nRead <= 0 {
@@ -317,15 +334,32 @@ func (c *Connection) RecvFrom(timeout float64) ([]byte, any, error) {
 		if c.breakReadEvent.Load() {
 			return nil, nil, comm.ErrBreak
 		}
+		if err := c.Guard.CheckTime(tFrame); err != nil {
+			return nil, nil, c.guardClose(err)
+		}
 		if timeout > 0 && time.Now().After(tBreak) {
 			return nil, nil, comm.ErrTimeout
 		}
 	}
 
+	if err := c.Guard.CheckRate(); err != nil {
+		return nil, nil, c.guardClose(err)
+	}
+
 	c.LogRx(data, nil)
 	return data, nil, nil
 }
 
+// guardClose closes the connection on receiving guard limits violation.
+func (c *Connection) guardClose(err error) error {
+	c.closeEvent.Store(true)
+	c.LogMsg("GUARD_CLOSED -- %v", err)
+	c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
+	c.netConn.Close()
+	go c.Close()
+	return comm.ErrClosed
+}
+
 /////////////////////////////////////////////////////
 
 // Listener represents a net socket listener that handles incoming connections
@@ -339,6 +373,11 @@ type Listener struct {
 
 	// The underlying net listener.
 	netListener net.Listener
+	// The unwrapped unix listener, used to get the listening socket file.
+	rawListener *net.UnixListener
+	// handOver indicates the listening socket was handed over to a child
+	// process, so the socket file is preserved on close.
+	handOver atomic.Bool
 
 	// The handler function to be called when a new connection is accepted.
 	connectionHandler func(comm.Connection)
@@ -348,6 +387,9 @@ type Listener struct {
 	// stopEvent signals a stop operation.
 	stopEvent atomic.Bool
 
+	// drainer tracks active connections for graceful stop.
+	drainer comm.Drainer
+
 	// sMutex defines mutex for state change operations (start/stop).
 	sMutex sync.Mutex
 }
@@ -356,6 +398,17 @@ type Listener struct {
 // The parsed options are:
 //   - connections_limit: (int) the limit on number of concurrent connections.
 //     use 0 to disable connections limit.
+//   - handler_pool_size: (int) the number of connection handler workers.
+//     use 0 to run one handler goroutine per connection. (default is 0)
+//   - handler_pool_policy: (string) the overflow policy {queue|reject}
+//     when all handler workers are busy. (default is queue)
+//   - handler_queue_size: (int) the size of pending connections queue.
+//     (default is the handler pool size)
+//
+// The receiving guard limits for accepted connections are also parsed
+// from options, see [comm.NewGuard]. Accepted connections are wrapped with
+// heartbeat handling if the heartbeat_interval option is set, see
+// [comm.NewHeartbeatConnection].
 func NewListener(uri string, log *logging.Logger, opts dictx.Dict) (*Listener, error) {
 	path, err := ParseUri(uri)
 	if err != nil {
@@ -378,6 +431,22 @@ func (l *Listener) NetListener() net.Listener {
 	return l.netListener
 }
 
+// File returns a duplicate of the listening socket file, used to hand over
+// the listener to a child process, see [comm.InheritedFile].
+// The socket file path is preserved when the listener is stopped afterwards.
+func (l *Listener) File() (*os.File, error) {
+	if l.rawListener == nil {
+		return nil, errors.New("listener not active")
+	}
+	f, err := l.rawListener.File()
+	if err != nil {
+		return nil, err
+	}
+	l.rawListener.SetUnlinkOnClose(false)
+	l.handOver.Store(true)
+	return f, nil
+}
+
 // ConnectionHandler sets a callback function to handle connections.
 func (l *Listener) ConnectionHandler(h func(comm.Connection)) {
 	l.connectionHandler = h
@@ -396,11 +465,21 @@ func (l *Listener) startListener() error {
 		cfg.KeepAlive = time.Duration(v * float64(time.Second))
 	}
 
-	// listener instance
-	netListener, err := cfg.Listen(context.Background(), "unix", l.path)
+	// listener instance, inherited from parent process or created
+	var netListener net.Listener
+	var err error
+	if f := comm.InheritedFile(l.Uri()); f != nil {
+		netListener, err = net.FileListener(f)
+		f.Close()
+		l.LogMsg("INHERITED -- %s", l.Uri())
+	} else {
+		netListener, err = cfg.Listen(context.Background(), "unix", l.path)
+	}
 	if err != nil {
 		return err
 	}
+	l.rawListener, _ = netListener.(*net.UnixListener)
+	l.handOver.Store(false)
 	// set connection limit (if configured)
 	if v := dictx.GetInt(l.Options, "connections_limit", 0); v > 0 {
 		netListener = netutil.LimitListener(netListener, v)
@@ -408,16 +487,22 @@ func (l *Listener) startListener() error {
 	l.LogMsg("LISTENING -- %s", l.Uri())
 	l.netListener = netListener
 
-	var waitGrp sync.WaitGroup
+	pool := comm.NewHandlerPool(l.CommLog, l.Options)
 
+	l.drainer.Reset()
 	l.stopEvent.Store(false)
 	l.isActive.Store(true)
 	defer func() {
-		l.stopEvent.Store(true)
+		// keep handlers running while draining
+		if !l.drainer.IsDraining() {
+			l.stopEvent.Store(true)
+		}
 		netListener.Close()
 		// wait all connections handlers termination
-		waitGrp.Wait()
-		os.Remove(l.path)
+		pool.Stop()
+		if !l.handOver.Load() {
+			os.Remove(l.path)
+		}
 		l.LogMsg("CLOSED -- %s", l.Uri())
 		l.isActive.Store(false)
 	}()
@@ -435,31 +520,48 @@ func (l *Listener) startListener() error {
 		}
 
 		// handle new connection
-		waitGrp.Add(1)
-		go func(netConn net.Conn) {
-			uri := fmt.Sprintf("%s@%s", l.Type(), netConn.RemoteAddr())
-			nc, err := NewConnection(uri, l.CommLog, l.Options)
-			if err != nil {
-				l.LogMsg("CONN_ERROR -- %v", err)
-				netConn.Close()
-				return
-			}
-			nc.netConn = netConn
-			nc.parent = l
-			nc.isOpened.Store(true)
-			nc.LogMsg("CONNECTED")
+		if !pool.Submit(func() { l.handleConnection(c) }) {
+			l.LogMsg("CONN_REJECTED -- %s", c.RemoteAddr())
+			c.Close()
+		}
+	}
+
+	return nil
+}
 
-			defer func() {
-				netConn.Close()
-				nc.LogMsg("DISCONNECTED")
-				waitGrp.Done()
-			}()
+// handleConnection runs the connection handler for an accepted connection.
+func (l *Listener) handleConnection(netConn net.Conn) {
+	defer netConn.Close()
 
-			l.connectionHandler(nc)
-		}(c)
+	// drop pending connections if listener is stopping
+	if l.stopEvent.Load() || l.drainer.IsDraining() {
+		return
 	}
 
-	return nil
+	uri := fmt.Sprintf("%s@%s", l.Type(), netConn.RemoteAddr())
+	nc, err := NewConnection(uri, l.CommLog, l.Options)
+	if err != nil {
+		l.LogMsg("CONN_ERROR -- %v", err)
+		return
+	}
+	nc.netConn = netConn
+	nc.parent = l
+	nc.isOpened.Store(true)
+	nc.LogMsg("CONNECTED")
+	nc.EmitEvent(comm.EVENT_CONNECTED, nil, nil, nil)
+	defer nc.LogMsg("DISCONNECTED")
+	defer nc.EmitEvent(comm.EVENT_DISCONNECTED, nil, nil, nil)
+	defer l.drainer.Track(nc, func() { netConn.Close() })()
+
+	// wrap with heartbeat handling if enabled
+	var conn comm.Connection = nc
+	if comm.IsHeartbeatEnabled(l.Options) {
+		hc := comm.NewHeartbeatConnection(nc, l.Options)
+		defer hc.Close()
+		conn = hc
+	}
+
+	l.connectionHandler(conn)
 }
 
 // Start begins listening for connections, calling the connectionHandler
@@ -478,7 +580,37 @@ func (l *Listener) Start() error {
 	return l.startListener()
 }
 
-// Stop gracefully shuts down the listener.
+// IsDraining checks if a graceful stop is in progress.
+func (l *Listener) IsDraining() bool {
+	return l.drainer.IsDraining()
+}
+
+// StopGraceful stops accepting new connections and notifies the active
+// connection handlers via the drain event, see [comm.IsDraining], then
+// waits up to timeout seconds for handlers termination before force
+// closing the listener and the remaining connections.
+// Setting timeout=0 will wait indefinitely.
+func (l *Listener) StopGraceful(timeout float64) {
+	// stop immediately if not active
+	if !l.IsActive() {
+		l.Stop()
+		return
+	}
+
+	l.LogMsg("DRAINING -- %s", l.Uri())
+	l.drainer.Drain()
+	// stop accepting new connections
+	l.netListener.Close()
+
+	if !l.drainer.Wait(timeout) {
+		l.LogMsg("DRAIN_TIMEOUT -- %s", l.Uri())
+	}
+	l.Stop()
+	l.drainer.ForceClose()
+}
+
+// Stop shuts down the listener, signaling the active connection handlers
+// to terminate.
 func (l *Listener) Stop() {
 	l.stopEvent.Store(true)
 
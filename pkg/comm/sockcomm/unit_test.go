@@ -0,0 +1,128 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package sockcomm_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/comm"
+	"github.com/exonlabs/go-utils/pkg/comm/sockcomm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startListener starts a listener on uri running handler for connections.
+func startListener(t *testing.T, uri string, handler func(comm.Connection)) *sockcomm.Listener {
+	l, err := sockcomm.NewListener(uri, nil, nil)
+	require.NoError(t, err)
+	l.ConnectionHandler(handler)
+	go l.Start()
+	require.Eventually(t, l.IsActive, time.Second, 10*time.Millisecond)
+	return l
+}
+
+// dial opens a client connection to uri.
+func dial(t *testing.T, uri string) *sockcomm.Connection {
+	c, err := sockcomm.NewConnection(uri, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, c.Open(1))
+	t.Cleanup(c.Close)
+	return c
+}
+
+func TestSendRecvFiles(t *testing.T) {
+	uri := "sock@" + filepath.Join(t.TempDir(), "fds.sock")
+
+	type result struct {
+		data  []byte
+		files []*os.File
+		err   error
+	}
+	ch := make(chan result, 1)
+	l := startListener(t, uri, func(c comm.Connection) {
+		data, files, err := c.(*sockcomm.Connection).RecvFiles(2)
+		ch <- result{data, files, err}
+	})
+	defer l.Stop()
+
+	pr, pw, err := os.Pipe()
+	require.NoError(t, err)
+	defer pr.Close()
+
+	c := dial(t, uri)
+	require.NoError(t, c.SendFiles([]byte("pipe"), []*os.File{pw}, 1))
+	pw.Close()
+
+	res := <-ch
+	require.NoError(t, res.err)
+	assert.Equal(t, []byte("pipe"), res.data)
+	require.Len(t, res.files, 1)
+
+	// write through the received descriptor, read from the local end
+	_, err = res.files[0].Write([]byte("hello"))
+	require.NoError(t, err)
+	res.files[0].Close()
+	b, err := io.ReadAll(pr)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+}
+
+func TestRecvFilesTimeout(t *testing.T) {
+	uri := "sock@" + filepath.Join(t.TempDir(), "fds.sock")
+	l := startListener(t, uri, func(c comm.Connection) {
+		time.Sleep(500 * time.Millisecond)
+	})
+	defer l.Stop()
+
+	c := dial(t, uri)
+	_, _, err := c.RecvFiles(0.2)
+	assert.ErrorIs(t, err, comm.ErrTimeout)
+}
+
+func TestListenersHandOver(t *testing.T) {
+	dir := t.TempDir()
+	ctlUri := "sock@" + filepath.Join(dir, "ctl.sock")
+	appUri := "sock@" + filepath.Join(dir, "app.sock")
+
+	// old instance serving on app socket
+	old := startListener(t, appUri, func(c comm.Connection) {
+		c.Send([]byte("old"), 1)
+		time.Sleep(300 * time.Millisecond)
+	})
+
+	// new instance receives the app socket over the control socket
+	received := make(chan error, 1)
+	ctl := startListener(t, ctlUri, func(c comm.Connection) {
+		_, err := sockcomm.RecvListeners(c.(*sockcomm.Connection), 2)
+		received <- err
+	})
+	defer ctl.Stop()
+
+	c := dial(t, ctlUri)
+	require.NoError(t, sockcomm.SendListeners(
+		c, []comm.FileListener{old}, 1))
+	require.NoError(t, <-received)
+
+	// new listener resumes on the handed over socket, old one stops
+	l := startListener(t, appUri, func(c comm.Connection) {
+		c.Send([]byte("new"), 1)
+		time.Sleep(300 * time.Millisecond)
+	})
+	defer l.Stop()
+	old.Stop()
+
+	_, err := os.Stat(filepath.Join(dir, "app.sock"))
+	require.NoError(t, err)
+
+	b, err := dial(t, appUri).Recv(1)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(b))
+}
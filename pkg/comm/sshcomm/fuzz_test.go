@@ -0,0 +1,32 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package sshcomm_test
+
+import (
+	"testing"
+
+	"github.com/exonlabs/go-utils/pkg/comm/sshcomm"
+)
+
+func FuzzParseUri(f *testing.F) {
+	for _, s := range []string{
+		"ssh@user@host/tcp:127.0.0.1:22",
+		"ssh@user@host:2222/tcp4:localhost:8080",
+		"ssh@user@[::1]/tcp6:[::1]:80",
+		"ssh@user@/tcp:a:1", "ssh@@host/tcp:a:1", "ssh@user@host/udp:a:1",
+		"ssh@user@host", "ssh@", "ssh", "",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, uri string) {
+		user, server, network, address, err := sshcomm.ParseUri(uri)
+		if err != nil {
+			return
+		}
+		if user == "" || server == "" || network == "" || address == "" {
+			t.Fatalf("empty parts for uri %q", uri)
+		}
+	})
+}
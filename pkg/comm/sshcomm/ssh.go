@@ -0,0 +1,443 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package sshcomm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/comm"
+	"github.com/exonlabs/go-utils/pkg/comm/netcomm"
+	"github.com/exonlabs/go-utils/pkg/logging"
+	"github.com/exonlabs/go-utils/pkg/secrets"
+)
+
+const (
+	// SSH_PORT defines the default SSH server port.
+	SSH_PORT = "22"
+	// TUNNEL_TIMEOUT defines the default tunnel setup timeout in seconds.
+	TUNNEL_TIMEOUT = 10.0
+	// BIND_RETRIES defines the tunnel setup attempts on local port bind
+	// failures.
+	BIND_RETRIES = 3
+)
+
+// errBind indicates the SSH client failed binding the local forward port.
+var errBind = errors.New("local forward bind failed")
+
+// ParseUri parses a SSH tunnel URI into SSH user, SSH server address,
+// and the forwarded target network and address.
+//
+//	The expected URI format is `ssh@<user>@<host>[:<port>]/<network>:<target>`
+//
+//	<user>     The SSH login user.
+//	<host>     The SSH server FQDN or IP address.
+//	<port>     The SSH server port. default is 22.
+//	<network>  The forwarded target network {tcp}.
+//	<target>   The forwarded target address <host>:<port> reachable
+//	           from the SSH server.
+//
+//	example:
+//	   - ssh@admin@10.0.0.1:22/tcp:127.0.0.1:502
+//	   - ssh@admin@jump.example.com/tcp:192.168.1.10:1234
+//
+// Returns the parsed user, server address, target network, target address,
+// and error for invalid URI format.
+func ParseUri(uri string) (string, string, string, string, error) {
+	parts := strings.SplitN(uri, "@", 2)
+	if len(parts) < 2 || strings.ToLower(parts[0]) != "ssh" {
+		return "", "", "", "", comm.ErrUri
+	}
+
+	session, target, ok := strings.Cut(parts[1], "/")
+	if !ok {
+		return "", "", "", "", comm.ErrUri
+	}
+	user, server, ok := strings.Cut(session, "@")
+	if !ok || user == "" || server == "" {
+		return "", "", "", "", comm.ErrUri
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, SSH_PORT)
+	}
+
+	network, address, ok := strings.Cut(target, ":")
+	network = strings.ToLower(network)
+	if !ok || (network != "tcp" && network != "tcp4" && network != "tcp6") {
+		return "", "", "", "", comm.ErrUri
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return "", "", "", "", comm.ErrUri
+	}
+
+	return user, server, network, address, nil
+}
+
+// Connection represents a stream connection forwarded through a SSH
+// tunnel. The tunnel is established using the system SSH client, which
+// forwards a local loopback port to the target address.
+type Connection struct {
+	// Context containing common attributes and functions.
+	*comm.Context
+
+	// The SSH login user.
+	user string
+	// The SSH server address (host:port).
+	server string
+	// The forwarded target network.
+	network string
+	// The forwarded target address (host:port).
+	address string
+
+	// The underlying local tunnel connection.
+	conn atomic.Pointer[netcomm.Connection]
+	// The SSH client process.
+	cmd *exec.Cmd
+	// The SSH client process exit signal.
+	exitCh chan struct{}
+	// The temp askpass script for password auth.
+	askpass string
+
+	// sMutex defines mutex for state change operations (open/close).
+	sMutex sync.Mutex
+}
+
+// NewConnection creates and initializes a new Connection for the given URI.
+// The parsed options are:
+//   - ssh_bin: (string) the SSH client binary path. default is ssh.
+//   - ssh_key_file: (string) the private key file path for key auth.
+//   - ssh_password: (string) the password for password auth, the value
+//     could be secret reference `secret:<name>` loaded from default
+//     secrets provider. requires OpenSSH client 8.4 or later.
+//   - ssh_known_hosts: (string) the known hosts file path.
+//   - ssh_strict_host_key: (bool) enable/disable strict host key checking.
+//     default enabled.
+//   - ssh_jump_hosts: (string) comma separated list of jump hosts in
+//     the form [user@]host[:port].
+//   - ssh_keepalive_interval: (int) the SSH server alive interval in seconds.
+//
+// The tunnel connection also accepts the netcomm connection options.
+func NewConnection(uri string, log *logging.Logger, opts dictx.Dict) (*Connection, error) {
+	user, server, network, address, err := ParseUri(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Connection{
+		Context: comm.NewContext(uri, log, opts),
+		user:    user,
+		server:  server,
+		network: network,
+		address: address,
+	}, nil
+}
+
+// String returns a string representation of the Connection.
+func (c *Connection) String() string {
+	return fmt.Sprintf("<SshConnection: %s>", c.Uri())
+}
+
+// Parent retrieves the parent Listener, always nil for SSH connections.
+func (c *Connection) Parent() comm.Listener {
+	return nil
+}
+
+// IsOpened indicates whether the connection is currently open and active.
+func (c *Connection) IsOpened() bool {
+	if conn := c.conn.Load(); conn != nil {
+		return conn.IsOpened()
+	}
+	return false
+}
+
+// Open establishes the SSH tunnel and the forwarded connection.
+func (c *Connection) Open(timeout float64) error {
+	c.sMutex.Lock()
+	defer c.sMutex.Unlock()
+
+	// do nothing if already opened
+	if conn := c.conn.Load(); conn != nil {
+		if conn.IsOpened() {
+			return nil
+		}
+		c.closeTunnel()
+	}
+
+	if timeout <= 0 {
+		timeout = TUNNEL_TIMEOUT
+	}
+	tBreak := time.Now().Add(time.Duration(timeout * float64(time.Second)))
+
+	// retry on local forward bind failures, where the free local port
+	// could be taken before the ssh client binds it
+	var conn *netcomm.Connection
+	var err error
+	for i := 0; i < BIND_RETRIES; i++ {
+		conn, err = c.openTunnel(tBreak)
+		if !errors.Is(err, errBind) || time.Now().After(tBreak) {
+			break
+		}
+		c.LogMsg("CONNECT_RETRY -- %v", err)
+	}
+	if err != nil {
+		c.LogMsg("CONNECT_FAIL -- %v", err)
+		c.EmitEvent(comm.EVENT_ERROR, nil, nil, err)
+		return fmt.Errorf("%w, %v", comm.ErrConnection, err)
+	}
+
+	c.conn.Store(conn)
+	c.LogMsg("CONNECTED SSH -- %s", c.Uri())
+	c.EmitEvent(comm.EVENT_CONNECTED, nil, nil, nil)
+	return nil
+}
+
+// openTunnel starts the SSH client process forwarding a free local port,
+// and connects to the local port until tBreak. The tunnel is closed on
+// failures, and errBind is returned if the local port bind failed.
+func (c *Connection) openTunnel(tBreak time.Time) (*netcomm.Connection, error) {
+	localAddr, err := freeLocalAddr()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.startTunnel(localAddr); err != nil {
+		return nil, err
+	}
+
+	// wait for tunnel local port forwarding, the local leg is always
+	// loopback tcp and the target network only applies to remote side
+	conn, err := netcomm.NewConnection("tcp@"+localAddr, c.CommLog, c.Options)
+	if err == nil {
+		for {
+			err = conn.Open(time.Until(tBreak).Seconds())
+			if err == nil || time.Now().After(tBreak) {
+				break
+			}
+			select {
+			case <-c.exitCh:
+				msg := strings.TrimSpace(c.cmd.Stderr.(*bytes.Buffer).String())
+				if strings.Contains(msg, "Address already in use") {
+					err = fmt.Errorf("%w, %s", errBind, msg)
+				} else {
+					err = fmt.Errorf("ssh exited, %s", msg)
+				}
+			case <-time.After(100 * time.Millisecond):
+				continue
+			}
+			break
+		}
+	}
+	if err != nil {
+		c.closeTunnel()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// startTunnel starts the SSH client process forwarding localAddr
+// to the target address.
+func (c *Connection) startTunnel(localAddr string) error {
+	host, port, _ := net.SplitHostPort(c.server)
+
+	args := []string{
+		"-N", "-T",
+		"-o", "ExitOnForwardFailure=yes",
+		"-L", localAddr + ":" + c.address,
+		"-p", port,
+	}
+	if v := strings.TrimSpace(
+		dictx.GetString(c.Options, "ssh_key_file", "")); v != "" {
+		args = append(args, "-i", v, "-o", "IdentitiesOnly=yes")
+	}
+	if v := strings.TrimSpace(
+		dictx.GetString(c.Options, "ssh_known_hosts", "")); v != "" {
+		args = append(args, "-o", "UserKnownHostsFile="+v)
+	}
+	if dictx.Fetch(c.Options, "ssh_strict_host_key", true) {
+		args = append(args, "-o", "StrictHostKeyChecking=yes")
+	} else {
+		args = append(args, "-o", "StrictHostKeyChecking=no")
+	}
+	if v := strings.TrimSpace(
+		dictx.GetString(c.Options, "ssh_jump_hosts", "")); v != "" {
+		args = append(args, "-J", strings.ReplaceAll(v, " ", ""))
+	}
+	if v := dictx.GetInt(c.Options, "ssh_keepalive_interval", 0); v > 0 {
+		args = append(args, "-o", fmt.Sprintf("ServerAliveInterval=%d", v))
+	}
+
+	env := os.Environ()
+	password := dictx.GetString(c.Options, "ssh_password", "")
+	if secrets.IsRef(password) {
+		v, err := secrets.Resolve(password)
+		if err != nil {
+			return err
+		}
+		password = v
+	}
+	if password != "" {
+		askpass, err := writeAskpass()
+		if err != nil {
+			return err
+		}
+		c.askpass = askpass
+		env = append(env,
+			"SSH_ASKPASS="+askpass,
+			"SSH_ASKPASS_REQUIRE=force",
+			"SSHCOMM_PASSWORD="+password)
+	} else {
+		args = append(args, "-o", "BatchMode=yes")
+	}
+	args = append(args, "-l", c.user, host)
+
+	sshBin := dictx.GetString(c.Options, "ssh_bin", "ssh")
+	c.cmd = exec.Command(sshBin, args...)
+	c.cmd.Env = env
+	c.cmd.Stderr = &bytes.Buffer{}
+	if err := c.cmd.Start(); err != nil {
+		c.removeAskpass()
+		return err
+	}
+
+	c.exitCh = make(chan struct{})
+	go func(cmd *exec.Cmd, exitCh chan struct{}) {
+		cmd.Wait()
+		close(exitCh)
+	}(c.cmd, c.exitCh)
+
+	return nil
+}
+
+// closeTunnel terminates the forwarded connection and SSH client process.
+func (c *Connection) closeTunnel() {
+	if conn := c.conn.Swap(nil); conn != nil {
+		conn.Close()
+	}
+	if c.cmd != nil {
+		c.cmd.Process.Kill()
+		<-c.exitCh
+		c.cmd = nil
+	}
+	c.removeAskpass()
+}
+
+// removeAskpass deletes the temp askpass script if any.
+func (c *Connection) removeAskpass() {
+	if c.askpass != "" {
+		os.Remove(c.askpass)
+		c.askpass = ""
+	}
+}
+
+// Close shuts down the forwarded connection and the SSH tunnel.
+func (c *Connection) Close() {
+	c.sMutex.Lock()
+	defer c.sMutex.Unlock()
+
+	if c.cmd == nil {
+		return
+	}
+	c.closeTunnel()
+	c.LogMsg("DISCONNECTED -- %s", c.Uri())
+	c.EmitEvent(comm.EVENT_DISCONNECTED, nil, nil, nil)
+}
+
+// Cancel cancels any ongoing operations on the connection.
+func (c *Connection) Cancel() {
+	if conn := c.conn.Load(); conn != nil {
+		conn.Cancel()
+	}
+}
+
+// CancelSend interrupts the ongoing sending operation for this Connection.
+func (c *Connection) CancelSend() {
+	if conn := c.conn.Load(); conn != nil {
+		conn.CancelSend()
+	}
+}
+
+// CancelRecv interrupts the ongoing receiving operation for this Connection.
+func (c *Connection) CancelRecv() {
+	if conn := c.conn.Load(); conn != nil {
+		conn.CancelRecv()
+	}
+}
+
+// Send transmits data over the connection, with a specified timeout.
+func (c *Connection) Send(data []byte, timeout float64) error {
+	return c.SendTo(data, nil, timeout)
+}
+
+// SendTo transmits data over the connection, with a specified timeout.
+// The addr argument is ignored for SSH stream connections.
+func (c *Connection) SendTo(data []byte, addr any, timeout float64) error {
+	conn := c.conn.Load()
+	if conn == nil {
+		return comm.ErrClosed
+	}
+	return conn.Send(data, timeout)
+}
+
+// Recv waits for incoming data over the connection until a timeout
+// or interrupt event occurs. Setting timeout=0 will wait indefinitely.
+func (c *Connection) Recv(timeout float64) ([]byte, error) {
+	b, _, err := c.RecvFrom(timeout)
+	return b, err
+}
+
+// RecvFrom waits for incoming data over the connection until a timeout
+// or interrupt event occurs. Setting timeout=0 will wait indefinitely.
+func (c *Connection) RecvFrom(timeout float64) ([]byte, any, error) {
+	conn := c.conn.Load()
+	if conn == nil {
+		return nil, nil, comm.ErrClosed
+	}
+	return conn.RecvFrom(timeout)
+}
+
+// freeLocalAddr returns a free loopback address for tunnel forwarding.
+func freeLocalAddr() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	return l.Addr().String(), nil
+}
+
+// writeAskpass creates a temp askpass script that prints the password
+// from SSHCOMM_PASSWORD environment variable.
+func writeAskpass() (string, error) {
+	pattern, content := "askpass-*.sh", "#!/bin/sh\nprintf '%s\\n' \"$SSHCOMM_PASSWORD\"\n"
+	if runtime.GOOS == "windows" {
+		// delayed expansion keeps cmd metacharacters in password literal
+		pattern, content = "askpass-*.cmd",
+			"@echo off\r\nsetlocal EnableDelayedExpansion\r\necho(!SSHCOMM_PASSWORD!\r\n"
+	}
+
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Chmod(0o700); err != nil && runtime.GOOS != "windows" {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package sshcomm_test
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/comm/sshcomm"
+)
+
+// TestSshHelper runs as fake SSH client, forwarding the local port of
+// the -L argument to the target address.
+func TestSshHelper(t *testing.T) {
+	args := os.Getenv("SSHCOMM_HELPER_ARGS")
+	if args == "" {
+		t.Skip("ssh helper process")
+	}
+	// fail binding local port once if requested
+	if marker := os.Getenv("SSHCOMM_HELPER_FAIL"); marker != "" {
+		if _, err := os.Stat(marker); err != nil {
+			os.WriteFile(marker, nil, 0o644)
+			fmt.Fprintln(os.Stderr, "bind [127.0.0.1]:1: Address already in use")
+			os.Exit(255)
+		}
+	}
+
+	var forward string
+	fields := strings.Fields(args)
+	for i, a := range fields {
+		if a == "-L" && i+1 < len(fields) {
+			forward = fields[i+1]
+		}
+	}
+	parts := strings.SplitN(forward, ":", 3)
+	l, err := net.Listen("tcp", parts[0]+":"+parts[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bind [%s]:%s: %v\n", parts[0], parts[1], err)
+		os.Exit(255)
+	}
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			os.Exit(1)
+		}
+		go func(c net.Conn) {
+			defer c.Close()
+			target, err := net.Dial("tcp", parts[2])
+			if err != nil {
+				return
+			}
+			defer target.Close()
+			go io.Copy(target, c)
+			io.Copy(c, target)
+		}(c)
+	}
+}
+
+// fakeSsh writes a fake ssh client script running the helper process.
+func fakeSsh(t *testing.T) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	path := filepath.Join(t.TempDir(), "ssh")
+	require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(
+		"#!/bin/sh\nSSHCOMM_HELPER_ARGS=\"$*\" exec %q -test.run='^TestSshHelper$'\n",
+		os.Args[0])), 0o755))
+	return path
+}
+
+// echoServer starts an echo server on network and address.
+func echoServer(t *testing.T, network, address string) string {
+	l, err := net.Listen(network, address)
+	if err != nil {
+		t.Skipf("%s not available: %v", network, err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				io.Copy(c, c)
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+// checkEcho opens the tunnel connection and checks data round trip.
+func checkEcho(t *testing.T, uri string, opts dictx.Dict) {
+	c, err := sshcomm.NewConnection(uri, nil, opts)
+	require.NoError(t, err)
+	require.NoError(t, c.Open(5))
+	defer c.Close()
+	require.NoError(t, c.Send([]byte("ping"), 1))
+	b, err := c.Recv(1)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ping"), b)
+}
+
+func TestConnectionTcp6(t *testing.T) {
+	addr := echoServer(t, "tcp6", "[::1]:0")
+	checkEcho(t, "ssh@user@[::1]/tcp6:"+addr, dictx.Dict{"ssh_bin": fakeSsh(t)})
+}
+
+func TestConnectionBindRetry(t *testing.T) {
+	addr := echoServer(t, "tcp4", "127.0.0.1:0")
+	marker := filepath.Join(t.TempDir(), "failed")
+	t.Setenv("SSHCOMM_HELPER_FAIL", marker)
+	checkEcho(t, "ssh@user@host/tcp4:"+addr, dictx.Dict{"ssh_bin": fakeSsh(t)})
+	assert.FileExists(t, marker)
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package trace
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/comm"
+)
+
+// Connection wraps a comm Connection and records all transmitted and
+// received data frames to a trace recorder.
+type Connection struct {
+	comm.Connection
+
+	// Recorder defines the trace recorder for connection frames.
+	Recorder *Recorder
+}
+
+// NewConnection creates a new tracing wrapper for a connection.
+func NewConnection(conn comm.Connection, rec *Recorder) *Connection {
+	return &Connection{
+		Connection: conn,
+		Recorder:   rec,
+	}
+}
+
+// Send transmits data over the connection and records the frame.
+func (c *Connection) Send(data []byte, timeout float64) error {
+	return c.SendTo(data, nil, timeout)
+}
+
+// SendTo transmits data to addr over the connection and records the frame.
+func (c *Connection) SendTo(data []byte, addr any, timeout float64) error {
+	err := c.Connection.SendTo(data, addr, timeout)
+	if err == nil {
+		c.Recorder.Record(TX, data)
+	}
+	return err
+}
+
+// Recv receives data over the connection and records the frame.
+func (c *Connection) Recv(timeout float64) ([]byte, error) {
+	b, _, err := c.RecvFrom(timeout)
+	return b, err
+}
+
+// RecvFrom receives data from addr over the connection and records the frame.
+func (c *Connection) RecvFrom(timeout float64) ([]byte, any, error) {
+	data, addr, err := c.Connection.RecvFrom(timeout)
+	if err == nil && len(data) > 0 {
+		c.Recorder.Record(RX, data)
+	}
+	return data, addr, err
+}
+
+/////////////////////////////////////////////////////
+
+// Replay feeds a recorded session back through a connection, acting as
+// the remote peer of the recorded connection. Recorded RX frames are sent
+// over the connection, and recorded TX frames are expected to be received
+// from the connection and compared with the recorded data.
+// If realtime is set, the recorded delays between sent frames are kept.
+// Returns an error on first mismatch or communication failure.
+func Replay(conn comm.Connection, frames []*Frame, timeout float64, realtime bool) error {
+	var tLast time.Time
+	var pending []byte
+
+	for i, f := range frames {
+		switch f.Dir {
+		case RX:
+			if realtime && !tLast.IsZero() {
+				time.Sleep(f.Time.Sub(tLast))
+			}
+			if err := conn.Send(f.Data, timeout); err != nil {
+				return fmt.Errorf("frame %d: %w", i, err)
+			}
+		case TX:
+			// received data may be split or merged across frames
+			for len(pending) < len(f.Data) {
+				b, err := conn.Recv(timeout)
+				if err != nil {
+					return fmt.Errorf("frame %d: %w", i, err)
+				}
+				pending = append(pending, b...)
+			}
+			if !bytes.Equal(pending[:len(f.Data)], f.Data) {
+				return fmt.Errorf("frame %d: data mismatch, "+
+					"expected %X, received %X", i, f.Data,
+					pending[:len(f.Data)])
+			}
+			pending = pending[len(f.Data):]
+		}
+		tLast = f.Time
+	}
+	return nil
+}
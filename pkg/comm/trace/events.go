@@ -0,0 +1,329 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package trace
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/comm"
+)
+
+// Event types of structured trace.
+const (
+	EV_CONNECT    = "connect"
+	EV_DISCONNECT = "disconnect"
+	EV_TX         = "tx"
+	EV_RX         = "rx"
+	EV_ERROR      = "error"
+)
+
+// Event represents a message level event of structured trace.
+type Event struct {
+	// Time defines the event timestamp.
+	Time time.Time `json:"ts"`
+	// Node defines the name of local node recording the event.
+	Node string `json:"node"`
+	// Peer defines the name of remote node.
+	Peer string `json:"peer"`
+	// Type defines the event type.
+	Type string `json:"event"`
+	// Seq defines the event sequence number of the connection.
+	Seq uint64 `json:"seq"`
+	// Size defines the frame size for tx and rx events.
+	Size int `json:"size,omitempty"`
+	// MsgId defines the protocol message ID of frame, if available.
+	MsgId string `json:"msg_id,omitempty"`
+	// Error defines the error message for error events.
+	Error string `json:"error,omitempty"`
+}
+
+/////////////////////////////////////////////////////
+
+// EventRecorder writes structured trace events to a JSONL file, one
+// JSON encoded event per line. Events are appended to existing files, so
+// multiple recorders and processes can share a trace file.
+type EventRecorder struct {
+	fd *os.File
+	mu sync.Mutex
+}
+
+// NewEventRecorder creates a new structured trace recorder appending
+// events to the file path.
+func NewEventRecorder(path string) (*EventRecorder, error) {
+	fd, err := os.OpenFile(
+		path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o664)
+	if err != nil {
+		return nil, err
+	}
+	return &EventRecorder{fd: fd}, nil
+}
+
+// Record writes an event to the trace file.
+func (r *EventRecorder) Record(ev *Event) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.fd == nil {
+		return os.ErrClosed
+	}
+	_, err = r.fd.Write(append(b, '\n'))
+	return err
+}
+
+// Close closes the trace file.
+func (r *EventRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.fd == nil {
+		return nil
+	}
+	err := r.fd.Close()
+	r.fd = nil
+	return err
+}
+
+// ReadEvents loads events from JSONL trace files, merged and sorted
+// by time to build the timeline of multi-party interactions.
+func ReadEvents(paths ...string) ([]*Event, error) {
+	events := []*Event{}
+	for _, p := range paths {
+		fd, err := os.Open(p)
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(fd)
+		scanner.Buffer(make([]byte, 4096), 1<<20)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			ev := &Event{}
+			if err := json.Unmarshal([]byte(line), ev); err != nil {
+				fd.Close()
+				return nil, fmt.Errorf("%w - %s", ErrFormat, p)
+			}
+			events = append(events, ev)
+		}
+		err = scanner.Err()
+		fd.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	return events, nil
+}
+
+// WriteSequence exports events as a mermaid sequence diagram, where
+// transmitted frames are drawn as messages between nodes. Received frames
+// are omitted when recorded by both nodes, since the tx event of the peer
+// already draws the message.
+func WriteSequence(w io.Writer, events []*Event) error {
+	// collect participants and recording nodes
+	nodes := map[string]bool{}
+	participants := []string{}
+	addParticipant := func(name string) {
+		for _, p := range participants {
+			if p == name {
+				return
+			}
+		}
+		participants = append(participants, name)
+	}
+	for _, ev := range events {
+		nodes[ev.Node] = true
+		addParticipant(ev.Node)
+		if ev.Peer != "" {
+			addParticipant(ev.Peer)
+		}
+	}
+
+	lines := []string{"sequenceDiagram"}
+	for _, p := range participants {
+		lines = append(lines, fmt.Sprintf("    participant %s", quote(p)))
+	}
+	for _, ev := range events {
+		node, peer := quote(ev.Node), quote(ev.Peer)
+		label := fmt.Sprintf("%s %dB", ev.Type, ev.Size)
+		if ev.MsgId != "" {
+			label += " id=" + ev.MsgId
+		}
+		switch ev.Type {
+		case EV_TX:
+			lines = append(lines, fmt.Sprintf("    %s->>%s: %s", node, peer, label))
+		case EV_RX:
+			if !nodes[ev.Peer] {
+				lines = append(lines, fmt.Sprintf("    %s->>%s: %s", peer, node, label))
+			}
+		case EV_CONNECT, EV_DISCONNECT:
+			lines = append(lines, fmt.Sprintf("    %s-->>%s: %s", node, peer, ev.Type))
+		case EV_ERROR:
+			lines = append(lines, fmt.Sprintf(
+				"    Note over %s: error %s", node, sanitize(ev.Error)))
+		}
+	}
+
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// quote returns a mermaid safe participant name.
+func quote(name string) string {
+	if name == "" {
+		return "unknown"
+	}
+	r := strings.NewReplacer(" ", "_", ":", "_", "-", "_", ">", "_",
+		";", "_", "#", "_", ",", "_")
+	return r.Replace(name)
+}
+
+// sanitize returns a mermaid safe message text.
+func sanitize(msg string) string {
+	r := strings.NewReplacer(";", ",", "#", "", "\n", " ")
+	return r.Replace(msg)
+}
+
+/////////////////////////////////////////////////////
+
+// EventConnection wraps a comm Connection and records message level
+// events to a structured trace recorder.
+type EventConnection struct {
+	comm.Connection
+
+	// Recorder defines the structured trace recorder.
+	Recorder *EventRecorder
+	// Node defines the name of local node.
+	Node string
+	// Peer defines the name of remote node.
+	Peer string
+	// MsgId extracts the protocol message ID from frame data, if set.
+	MsgId func(data []byte) string
+
+	// seq holds the last event sequence number.
+	seq uint64
+	// mu defines mutex for events sequence.
+	mu sync.Mutex
+}
+
+// NewEventConnection creates a new structured tracing wrapper for
+// a connection between local node and remote peer.
+func NewEventConnection(
+	conn comm.Connection, rec *EventRecorder, node, peer string) *EventConnection {
+	c := &EventConnection{
+		Connection: conn,
+		Recorder:   rec,
+		Node:       node,
+		Peer:       peer,
+	}
+	if conn.IsOpened() {
+		c.record(EV_CONNECT, nil, nil)
+	}
+	return c
+}
+
+// record writes an event for connection.
+func (c *EventConnection) record(typ string, data []byte, err error) {
+	c.mu.Lock()
+	c.seq++
+	ev := &Event{
+		Time: time.Now(),
+		Node: c.Node,
+		Peer: c.Peer,
+		Type: typ,
+		Seq:  c.seq,
+		Size: len(data),
+	}
+	c.mu.Unlock()
+
+	if len(data) > 0 && c.MsgId != nil {
+		ev.MsgId = c.MsgId(data)
+	}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+	c.Recorder.Record(ev)
+}
+
+// recordError writes an error event, ignoring timeout and break errors.
+func (c *EventConnection) recordError(err error) {
+	if err != nil && !errors.Is(err, comm.ErrTimeout) &&
+		!errors.Is(err, comm.ErrBreak) {
+		c.record(EV_ERROR, nil, err)
+	}
+}
+
+// Open establishes the connection and records a connect event.
+func (c *EventConnection) Open(timeout float64) error {
+	if c.Connection.IsOpened() {
+		return nil
+	}
+	if err := c.Connection.Open(timeout); err != nil {
+		c.recordError(err)
+		return err
+	}
+	c.record(EV_CONNECT, nil, nil)
+	return nil
+}
+
+// Close terminates the connection and records a disconnect event.
+func (c *EventConnection) Close() {
+	opened := c.Connection.IsOpened()
+	c.Connection.Close()
+	if opened {
+		c.record(EV_DISCONNECT, nil, nil)
+	}
+}
+
+// Send transmits data over the connection and records a tx event.
+func (c *EventConnection) Send(data []byte, timeout float64) error {
+	return c.SendTo(data, nil, timeout)
+}
+
+// SendTo transmits data to addr over the connection and records a tx event.
+func (c *EventConnection) SendTo(data []byte, addr any, timeout float64) error {
+	err := c.Connection.SendTo(data, addr, timeout)
+	if err == nil {
+		c.record(EV_TX, data, nil)
+	} else {
+		c.recordError(err)
+	}
+	return err
+}
+
+// Recv receives data over the connection and records a rx event.
+func (c *EventConnection) Recv(timeout float64) ([]byte, error) {
+	b, _, err := c.RecvFrom(timeout)
+	return b, err
+}
+
+// RecvFrom receives data from addr over the connection and records
+// a rx event.
+func (c *EventConnection) RecvFrom(timeout float64) ([]byte, any, error) {
+	data, addr, err := c.Connection.RecvFrom(timeout)
+	if err == nil && len(data) > 0 {
+		c.record(EV_RX, data, nil)
+	} else {
+		c.recordError(err)
+	}
+	return data, addr, err
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package trace
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// FILE_MAGIC defines the trace file header signature.
+const FILE_MAGIC = "CTRC\x01"
+
+// ErrFormat indicates an invalid trace file format.
+var ErrFormat = errors.New("invalid trace file format")
+
+// Direction defines the frame direction.
+type Direction uint8
+
+// Frame directions.
+const (
+	TX Direction = 0 // Transmitted frame
+	RX Direction = 1 // Received frame
+)
+
+// String returns the string representation of the frame direction.
+func (d Direction) String() string {
+	if d == RX {
+		return "RX"
+	}
+	return "TX"
+}
+
+// Frame represents a recorded data frame.
+type Frame struct {
+	Time time.Time // Frame timestamp
+	Dir  Direction // Frame direction
+	Data []byte    // Frame data
+}
+
+// frame header: timestamp(8) + direction(1) + data length(4)
+const frameHeaderSize = 13
+
+/////////////////////////////////////////////////////
+
+// Recorder writes timestamped frames to rotating trace files.
+type Recorder struct {
+	// path defines the trace file path.
+	path string
+	// maxSize defines the file size in bytes triggering rotation.
+	maxSize int64
+	// maxFiles defines the number of rotated files to keep.
+	maxFiles int
+
+	fd   *os.File
+	size int64
+	mu   sync.Mutex
+}
+
+// NewRecorder creates a new trace recorder writing to path. Files are rotated
+// when size exceeds maxSize, keeping maxFiles rotated files with suffixes
+// ".1", ".2", ... Use maxSize 0 to disable rotation.
+func NewRecorder(path string, maxSize int64, maxFiles int) (*Recorder, error) {
+	r := &Recorder{
+		path:     path,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open creates a new trace file and writes the file header.
+func (r *Recorder) open() error {
+	fd, err := os.OpenFile(r.path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o664)
+	if err != nil {
+		return err
+	}
+	if _, err := fd.Write([]byte(FILE_MAGIC)); err != nil {
+		fd.Close()
+		return err
+	}
+	r.fd = fd
+	r.size = int64(len(FILE_MAGIC))
+	return nil
+}
+
+// rotate shifts the rotated files and opens a new trace file.
+func (r *Recorder) rotate() error {
+	r.fd.Close()
+	r.fd = nil
+	if r.maxFiles > 0 {
+		for i := r.maxFiles - 1; i > 0; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", r.path, i),
+				fmt.Sprintf("%s.%d", r.path, i+1))
+		}
+		os.Rename(r.path, r.path+".1")
+	}
+	return r.open()
+}
+
+// Record writes a data frame to the trace file.
+func (r *Recorder) Record(dir Direction, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.fd == nil {
+		return os.ErrClosed
+	}
+	if r.maxSize > 0 && r.size >= r.maxSize {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+
+	b := make([]byte, frameHeaderSize+len(data))
+	binary.BigEndian.PutUint64(b[0:8], uint64(time.Now().UnixNano()))
+	b[8] = byte(dir)
+	binary.BigEndian.PutUint32(b[9:13], uint32(len(data)))
+	copy(b[frameHeaderSize:], data)
+
+	n, err := r.fd.Write(b)
+	r.size += int64(n)
+	return err
+}
+
+// Close closes the trace file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.fd == nil {
+		return nil
+	}
+	err := r.fd.Close()
+	r.fd = nil
+	return err
+}
+
+/////////////////////////////////////////////////////
+
+// Reader reads frames from a trace file.
+type Reader struct {
+	rd io.Reader
+}
+
+// NewReader creates a new trace reader and validates the file header.
+func NewReader(rd io.Reader) (*Reader, error) {
+	hdr := make([]byte, len(FILE_MAGIC))
+	if _, err := io.ReadFull(rd, hdr); err != nil ||
+		string(hdr) != FILE_MAGIC {
+		return nil, ErrFormat
+	}
+	return &Reader{rd: bufio.NewReader(rd)}, nil
+}
+
+// Next reads the next frame, returns io.EOF at end of trace.
+func (r *Reader) Next() (*Frame, error) {
+	hdr := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(r.rd, hdr); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, ErrFormat
+		}
+		return nil, err
+	}
+	f := &Frame{
+		Time: time.Unix(0, int64(binary.BigEndian.Uint64(hdr[0:8]))),
+		Dir:  Direction(hdr[8]),
+		Data: make([]byte, binary.BigEndian.Uint32(hdr[9:13])),
+	}
+	if _, err := io.ReadFull(r.rd, f.Data); err != nil {
+		return nil, ErrFormat
+	}
+	return f, nil
+}
+
+// ReadFile loads all frames from a trace file.
+func ReadFile(path string) ([]*Frame, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	r, err := NewReader(fd)
+	if err != nil {
+		return nil, err
+	}
+	frames := []*Frame{}
+	for {
+		f, err := r.Next()
+		if err == io.EOF {
+			return frames, nil
+		} else if err != nil {
+			return nil, err
+		}
+		frames = append(frames, f)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package trace_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/comm"
+	"github.com/exonlabs/go-utils/pkg/comm/memcomm"
+	"github.com/exonlabs/go-utils/pkg/comm/trace"
+)
+
+func TestRecordAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.trc")
+
+	rec, err := trace.NewRecorder(path, 0, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, rec.Record(trace.TX, []byte{0x01, 0x02}))
+	assert.NoError(t, rec.Record(trace.RX, []byte("reply")))
+	assert.NoError(t, rec.Close())
+
+	frames, err := trace.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Len(t, frames, 2)
+	assert.Equal(t, trace.TX, frames[0].Dir)
+	assert.Equal(t, []byte{0x01, 0x02}, frames[0].Data)
+	assert.Equal(t, trace.RX, frames[1].Dir)
+	assert.Equal(t, []byte("reply"), frames[1].Data)
+	assert.False(t, frames[1].Time.Before(frames[0].Time))
+}
+
+func TestRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.trc")
+
+	rec, err := trace.NewRecorder(path, 32, 2)
+	assert.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, rec.Record(trace.TX, make([]byte, 20)))
+	}
+	assert.NoError(t, rec.Close())
+
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		frames, err := trace.ReadFile(p)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, frames)
+	}
+	_, err = trace.ReadFile(path + ".3")
+	assert.Error(t, err)
+}
+
+func TestEventTrace(t *testing.T) {
+	dir := t.TempDir()
+	c1, c2 := memcomm.NewPipe(nil, nil)
+
+	rec1, err := trace.NewEventRecorder(filepath.Join(dir, "master.jsonl"))
+	require.NoError(t, err)
+	rec2, err := trace.NewEventRecorder(filepath.Join(dir, "slave.jsonl"))
+	require.NoError(t, err)
+
+	e1 := trace.NewEventConnection(c1, rec1, "master", "slave")
+	e2 := trace.NewEventConnection(c2, rec2, "slave", "master")
+	e1.MsgId = func(b []byte) string { return string(b[:1]) }
+
+	assert.NoError(t, e1.Send([]byte("Aping"), 1))
+	_, err = e2.Recv(1)
+	assert.NoError(t, err)
+	assert.NoError(t, e2.Send([]byte("pong"), 1))
+	_, err = e1.Recv(1)
+	assert.NoError(t, err)
+	_, err = e1.Recv(0.01)
+	assert.ErrorIs(t, err, comm.ErrTimeout)
+	e1.Close()
+	rec1.Close()
+	rec2.Close()
+
+	events, err := trace.ReadEvents(
+		filepath.Join(dir, "master.jsonl"), filepath.Join(dir, "slave.jsonl"))
+	require.NoError(t, err)
+	types := []string{}
+	for _, ev := range events {
+		types = append(types, ev.Node+":"+ev.Type)
+	}
+	assert.Equal(t, []string{
+		"master:connect", "slave:connect", "master:tx", "slave:rx",
+		"slave:tx", "master:rx", "master:disconnect"}, types)
+	assert.Equal(t, 5, events[2].Size)
+	assert.Equal(t, "A", events[2].MsgId)
+
+	var buf bytes.Buffer
+	assert.NoError(t, trace.WriteSequence(&buf, events))
+	assert.Equal(t, `sequenceDiagram
+    participant master
+    participant slave
+    master-->>slave: connect
+    slave-->>master: connect
+    master->>slave: tx 5B id=A
+    slave->>master: tx 4B
+    master-->>slave: disconnect
+`, buf.String())
+}
@@ -0,0 +1,348 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package comm_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/comm"
+	"github.com/exonlabs/go-utils/pkg/comm/memcomm"
+	"github.com/exonlabs/go-utils/pkg/logging"
+)
+
+// recordHandler collects the handled log records.
+type recordHandler struct {
+	mu      sync.Mutex
+	records []string
+}
+
+func (h *recordHandler) HandleRecord(r string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *recordHandler) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return strings.Join(h.records, "\n")
+}
+
+func TestHandlerPoolOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   dictx.Dict
+		size   int
+		policy string
+	}{
+		{"default", nil, 0, comm.POOL_QUEUE},
+		{"queue", dictx.Dict{"handler_pool_size": 4}, 4, comm.POOL_QUEUE},
+		{"reject", dictx.Dict{
+			"handler_pool_size": 2, "handler_pool_policy": "REJECT"},
+			2, comm.POOL_REJECT},
+		{"unpooled reject", dictx.Dict{"handler_pool_policy": "reject"},
+			0, comm.POOL_QUEUE},
+		{"invalid policy", dictx.Dict{
+			"handler_pool_size": 1, "handler_pool_policy": "drop"},
+			1, comm.POOL_QUEUE},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := comm.NewHandlerPool(nil, tc.opts)
+			defer p.Stop()
+			assert.Equal(t, tc.size, p.Size())
+			assert.Equal(t, tc.policy, p.Policy())
+		})
+	}
+}
+
+func TestHandlerPoolQueue(t *testing.T) {
+	p := comm.NewHandlerPool(nil, dictx.Dict{
+		"handler_pool_size": 1, "handler_queue_size": 2})
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	require.True(t, p.Submit(func() { close(started); <-release }))
+	<-started
+
+	// fill the pending queue while the worker is busy
+	assert.True(t, p.Submit(func() {}))
+	assert.True(t, p.Submit(func() {}))
+	assert.Equal(t, int64(2), p.Stats().Queued)
+
+	// queue policy blocks until a queued job is picked
+	done := make(chan bool)
+	go func() { done <- p.Submit(func() {}) }()
+	select {
+	case <-done:
+		t.Fatal("submit did not block on full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	assert.True(t, <-done)
+	p.Stop()
+
+	st := p.Stats()
+	assert.Equal(t, uint64(4), st.Completed)
+	assert.Equal(t, uint64(0), st.Rejected)
+}
+
+func TestHandlerPoolReject(t *testing.T) {
+	p := comm.NewHandlerPool(nil, dictx.Dict{
+		"handler_pool_size":   1,
+		"handler_pool_policy": comm.POOL_REJECT,
+		"handler_queue_size":  1,
+	})
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	require.True(t, p.Submit(func() { close(started); <-release }))
+	<-started
+
+	assert.True(t, p.Submit(func() {}))
+	assert.False(t, p.Submit(func() {}))
+	assert.False(t, p.Submit(func() {}))
+
+	close(release)
+	p.Stop()
+
+	st := p.Stats()
+	assert.Equal(t, uint64(2), st.Completed)
+	assert.Equal(t, uint64(2), st.Rejected)
+}
+
+func TestHandlerPoolPanic(t *testing.T) {
+	h := new(recordHandler)
+	log := &logging.Logger{Name: "pool", Level: logging.TRACE1}
+	log.SetFormatter(logging.NewRawFormatter())
+	log.AddHandler(h)
+
+	for _, size := range []int{0, 1} {
+		p := comm.NewHandlerPool(log, dictx.Dict{"handler_pool_size": size})
+		assert.True(t, p.Submit(func() { panic("handler failure") }))
+
+		// the pool worker survives the panic
+		ran := make(chan struct{})
+		assert.True(t, p.Submit(func() { close(ran) }))
+		<-ran
+		p.Stop()
+		assert.Equal(t, uint64(1), p.Stats().Panics, "size=%d", size)
+	}
+
+	out := h.String()
+	assert.Contains(t, out, "connection handler panic: handler failure")
+	assert.Contains(t, out, "unit_test.go")
+}
+
+func TestHeartbeatOptions(t *testing.T) {
+	c1, _ := memcomm.NewPipe(nil, nil)
+	defer c1.Close()
+
+	hc := comm.NewHeartbeatConnection(c1, nil)
+	assert.Equal(t, 0.0, hc.Interval)
+	assert.Equal(t, 0.0, hc.Timeout)
+	assert.Equal(t, []byte(comm.HEARTBEAT_PAYLOAD), hc.Payload)
+	assert.False(t, hc.Framing)
+	assert.False(t, comm.IsHeartbeatEnabled(nil))
+
+	opts := dictx.Dict{
+		"heartbeat_interval": 2,
+		"heartbeat_payload":  "PING",
+		"heartbeat_framing":  true,
+	}
+	hc = comm.NewHeartbeatConnection(c1, opts)
+	defer hc.Close()
+	assert.Equal(t, 2.0, hc.Interval)
+	assert.Equal(t, 6.0, hc.Timeout)
+	assert.Equal(t, []byte("PING"), hc.Payload)
+	assert.True(t, hc.Framing)
+	assert.True(t, comm.IsHeartbeatEnabled(opts))
+}
+
+func TestHeartbeatIdle(t *testing.T) {
+	c1, c2 := memcomm.NewPipe(nil, nil)
+	defer c2.Close()
+	hc := comm.NewHeartbeatConnection(c1, dictx.Dict{
+		"heartbeat_interval": 0.1,
+		"heartbeat_timeout":  0,
+		"heartbeat_payload":  "PING",
+	})
+	defer hc.Close()
+
+	// heartbeats are sent unframed on idle link
+	for i := 0; i < 2; i++ {
+		b, err := c2.Recv(1)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("PING"), b)
+	}
+
+	// no heartbeats are sent while data is sent within interval
+	for i := 0; i < 15; i++ {
+		require.NoError(t, hc.Send([]byte("data"), 1))
+		time.Sleep(20 * time.Millisecond)
+	}
+	for {
+		b, err := c2.Recv(0.01)
+		if err != nil {
+			assert.ErrorIs(t, err, comm.ErrTimeout)
+			break
+		}
+		assert.Equal(t, []byte("data"), b)
+	}
+}
+
+func TestHeartbeatUnframed(t *testing.T) {
+	c1, c2 := memcomm.NewPipe(nil, nil)
+	defer c1.Close()
+	hc := comm.NewHeartbeatConnection(c2, dictx.Dict{
+		"heartbeat_interval": 0.05,
+		"heartbeat_timeout":  0.2,
+		"heartbeat_payload":  "PING",
+	})
+	defer hc.Close()
+
+	// received heartbeats are filtered out
+	for _, s := range []string{"PING", "data", "PING", "PING\n", "more"} {
+		require.NoError(t, c1.Send([]byte(s), 1))
+	}
+	for _, s := range []string{"data", "PING\n", "more"} {
+		b, err := hc.Recv(1)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(s), b)
+	}
+
+	// peer heartbeats keep the connection alive
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(50 * time.Millisecond):
+				c1.Send([]byte("PING"), 1)
+			}
+		}
+	}()
+	_, err := hc.Recv(0.5)
+	assert.ErrorIs(t, err, comm.ErrTimeout)
+	assert.False(t, hc.IsExpired())
+
+	// silent peer expires the connection
+	close(stop)
+	_, err = hc.Recv(1)
+	assert.ErrorIs(t, err, comm.ErrClosed)
+	assert.True(t, hc.IsExpired())
+}
+
+func TestHeartbeatExpiry(t *testing.T) {
+	c1, _ := memcomm.NewPipe(nil, nil)
+	hc := comm.NewHeartbeatConnection(c1, dictx.Dict{
+		"heartbeat_interval": 0.05,
+		"heartbeat_timeout":  0.2,
+	})
+	defer hc.Close()
+	assert.True(t, hc.IsOpened())
+
+	// sent data doesn't keep the connection alive with a silent peer
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = hc.Send([]byte("data"), 1)
+		time.Sleep(40 * time.Millisecond)
+	}
+	assert.ErrorIs(t, err, comm.ErrClosed)
+	assert.True(t, hc.IsExpired())
+	_, err = hc.Recv(1)
+	assert.ErrorIs(t, err, comm.ErrClosed)
+	assert.False(t, hc.IsOpened())
+	assert.ErrorIs(t, hc.Send([]byte("data"), 1), comm.ErrClosed)
+}
+
+func TestHeartbeatFraming(t *testing.T) {
+	opts := dictx.Dict{
+		"heartbeat_interval": 0.01,
+		"heartbeat_timeout":  1,
+		"heartbeat_framing":  true,
+	}
+	c1, c2 := memcomm.NewPipe(nil, nil)
+	h1 := comm.NewHeartbeatConnection(c1, opts)
+	defer h1.Close()
+	h2 := comm.NewHeartbeatConnection(c2, opts)
+	defer h2.Close()
+
+	// data matching heartbeat payload is delivered
+	for _, data := range [][]byte{{0}, []byte("data"), {0}} {
+		time.Sleep(30 * time.Millisecond)
+		assert.NoError(t, h1.Send(data, 1))
+		b, err := h2.Recv(1)
+		assert.NoError(t, err)
+		assert.Equal(t, data, b)
+	}
+
+	// frames coalesced or split by the underlying connection
+	c1, c2 = memcomm.NewPipe(nil, nil)
+	hc := comm.NewHeartbeatConnection(c2, dictx.Dict{"heartbeat_framing": true})
+	heartbeat := []byte{2, 0, 0, 0, 1, 0}
+	frame := func(s string) []byte {
+		return append([]byte{1, 0, 0, 0, byte(len(s))}, s...)
+	}
+	chunk := append(append(append([]byte{}, heartbeat...), frame("a")...),
+		frame("\x00")...)
+	chunk = append(chunk, frame("tail")[:3]...)
+	assert.NoError(t, c1.Send(chunk, 1))
+	assert.NoError(t, c1.Send(append(frame("tail")[3:], heartbeat...), 1))
+	for _, s := range []string{"a", "\x00", "tail"} {
+		b, err := hc.Recv(1)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(s), b)
+	}
+	_, err := hc.Recv(0.05)
+	assert.ErrorIs(t, err, comm.ErrTimeout)
+
+	// invalid frames are rejected
+	assert.NoError(t, c1.Send([]byte("\x00invalid"), 1))
+	_, err = hc.Recv(1)
+	assert.ErrorIs(t, err, comm.ErrRead)
+}
+
+func TestGuard(t *testing.T) {
+	assert.Nil(t, comm.NewGuard(nil))
+
+	var nilGuard *comm.Guard
+	assert.NoError(t, nilGuard.CheckSize(1<<20))
+	assert.NoError(t, nilGuard.CheckTime(time.Now().Add(-time.Hour)))
+	assert.NoError(t, nilGuard.CheckRate())
+
+	g := comm.NewGuard(dictx.Dict{
+		"guard_max_frame_size": 8,
+		"guard_frame_timeout":  0.1,
+		"guard_max_rate":       20,
+		"guard_rate_burst":     2,
+	})
+	require.NotNil(t, g)
+
+	assert.NoError(t, g.CheckSize(8))
+	assert.ErrorIs(t, g.CheckSize(9), comm.ErrGuard)
+
+	assert.NoError(t, g.CheckTime(time.Time{}))
+	assert.NoError(t, g.CheckTime(time.Now()))
+	assert.ErrorIs(t, g.CheckTime(
+		time.Now().Add(-200*time.Millisecond)), comm.ErrGuard)
+
+	// burst is consumed then tokens refill at max rate
+	assert.NoError(t, g.CheckRate())
+	assert.NoError(t, g.CheckRate())
+	assert.ErrorIs(t, g.CheckRate(), comm.ErrGuard)
+	time.Sleep(60 * time.Millisecond)
+	assert.NoError(t, g.CheckRate())
+}
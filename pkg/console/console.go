@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 )
@@ -22,7 +23,11 @@ type Console struct {
 	required bool // required marks the input as mandatory.
 	hidden   bool // hidden indicates if the input should be masked (e.g., for passwords).
 
-	parser func(string) (any, error) // parser is used to validate and parse input.
+	maskOpts MaskOptions // maskOpts defines the masking and paste policy for hidden input.
+
+	validators []Validator               // validators are checked on the input before parsing.
+	parser     func(string) (any, error) // parser is used to validate and parse input.
+	completer  CompleteFunc              // completer provides tab-completion candidates.
 
 	cAsk *color.Color // cAsk is the color used for asking prompts.
 	cErr *color.Color // cErr is the color used for showing errors.
@@ -73,11 +78,45 @@ func (c *Console) Hidden() *Console {
 	return c
 }
 
+// Mask echoes the given char for each typed char of hidden input.
+// It implies Hidden.
+func (c *Console) Mask(ch rune) *Console {
+	c.hidden = true
+	c.maskOpts.Mask = ch
+	return c
+}
+
+// NoPaste rejects pasted text for hidden input. It implies Hidden.
+func (c *Console) NoPaste() *Console {
+	c.hidden = true
+	c.maskOpts.NoPaste = true
+	return c
+}
+
+// Timeout aborts hidden input with ErrInputTimeout after the given
+// inactivity period. It implies Hidden.
+func (c *Console) Timeout(d time.Duration) *Console {
+	c.hidden = true
+	c.maskOpts.Timeout = d
+	return c
+}
+
 // Regex sets a regular expression to validate the input.
 func (c *Console) Regex(regex string) *Console {
-	c.parser = func(input string) (any, error) {
-		return RegexParser(input, regex)
-	}
+	return c.Validate(RegexValidator(regex))
+}
+
+// Validate adds validators to check the input with. Validators are chained
+// in order and the first failing one rejects the input with its error.
+func (c *Console) Validate(validators ...Validator) *Console {
+	c.validators = append(c.validators, validators...)
+	return c
+}
+
+// Complete sets a tab-completion callback for the next input. Completion
+// is only available with handlers implementing CompletionHandler.
+func (c *Console) Complete(fn CompleteFunc) *Console {
+	c.completer = fn
 	return c
 }
 
@@ -85,7 +124,10 @@ func (c *Console) Regex(regex string) *Console {
 func (c *Console) resetFlags() {
 	c.required = false
 	c.hidden = false
+	c.maskOpts = MaskOptions{}
+	c.validators = nil
 	c.parser = nil
+	c.completer = nil
 }
 
 // getInput reads and validates user input based on the provided message and default value.
@@ -108,12 +150,23 @@ func (c *Console) getInput(msg string, defVal any) (any, error) {
 		c.handler.Write(c.cErr.Sprint("-- "+errMsg) + "\n\r")
 	}
 
+	// Enable tab-completion if supported by the handler
+	if ch, ok := c.handler.(CompletionHandler); ok && c.completer != nil {
+		ch.SetCompleter(c.completer)
+		defer ch.SetCompleter(nil)
+	}
+
 	// Attempt to get input based on the number of allowed trials
 	var input string
 	var err error
 	for i := c.Trials; i > 0; i-- {
 		if c.hidden {
-			input, err = c.handler.ReadHidden(msg)
+			mh, ok := c.handler.(MaskedHandler)
+			if ok && c.maskOpts != (MaskOptions{}) {
+				input, err = mh.ReadMasked(msg, c.maskOpts)
+			} else {
+				input, err = c.handler.ReadHidden(msg)
+			}
 		} else {
 			input, err = c.handler.Read(msg)
 		}
@@ -122,6 +175,9 @@ func (c *Console) getInput(msg string, defVal any) (any, error) {
 				c.handler.Write("\n\r")
 				return nil, err
 			}
+			if errors.Is(err, ErrInputTimeout) {
+				return nil, err
+			}
 			showError(i, err.Error())
 			continue
 		}
@@ -137,6 +193,11 @@ func (c *Console) getInput(msg string, defVal any) (any, error) {
 			}
 		}
 
+		if err := c.validate(input); err != nil {
+			showError(i, err.Error())
+			continue
+		}
+
 		if c.parser != nil {
 			if val, err := c.parser(input); err != nil {
 				showError(i, err.Error())
@@ -152,6 +213,16 @@ func (c *Console) getInput(msg string, defVal any) (any, error) {
 	return nil, fmt.Errorf("failed to get a valid input")
 }
 
+// validate runs the input through the validators chain.
+func (c *Console) validate(input string) error {
+	for _, v := range c.validators {
+		if err := v(input); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ReadValue prompts the user for a string value with an optional default.
 // If the input is empty and not required, it returns the default.
 func (c *Console) ReadValue(msg string, defVal string) (string, error) {
@@ -6,6 +6,7 @@ package console_test
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/exonlabs/go-utils/pkg/console"
 )
@@ -25,6 +26,22 @@ func ExampleConsole_ReadValue() {
 	// read a required input with echo off (hidden input)
 	passwd, _ := con.Hidden().Required().ReadValue("Enter password", "")
 	fmt.Println(passwd)
+
+	// read a masked password echoing '*', rejecting pasted text and
+	// aborting after 30 seconds of inactivity
+	passwd, _ = con.Mask('*').NoPaste().Timeout(30*time.Second).
+		Required().ReadValue("Enter password", "")
+	fmt.Println(passwd)
+}
+
+func ExampleConsole_Complete() {
+	con, _ := console.NewTermConsole()
+	defer con.Close()
+
+	// read a command with tab-completion of known commands
+	cmd, _ := con.Complete(console.PrefixCompleter("show", "status", "exit")).
+		Required().ReadValue("Command", "")
+	fmt.Println(cmd)
 }
 
 func ExampleConsole_SelectValue() {
@@ -36,6 +53,24 @@ func ExampleConsole_SelectValue() {
 	fmt.Println(color)
 }
 
+func ExampleConsole_Menu() {
+	con, _ := console.NewTermConsole()
+	defer con.Close()
+
+	color, _ := con.Menu("Select color?",
+		[]string{"Red", "Blue", "Green"}, "Red")
+	fmt.Println(color)
+}
+
+func ExampleConsole_SelectMulti() {
+	con, _ := console.NewTermConsole()
+	defer con.Close()
+
+	colors, _ := con.SelectMulti("Select colors?",
+		[]string{"Red", "Blue", "Green"}, []string{"Red"})
+	fmt.Println(colors)
+}
+
 func ExampleConsole_SelectYesNo() {
 	con, _ := console.NewTermConsole()
 	defer con.Close()
@@ -0,0 +1,172 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package console
+
+import (
+	"fmt"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+)
+
+// FieldType defines the kind of input read for a form field.
+type FieldType int
+
+// Form field types.
+const (
+	FIELD_STRING  FieldType = iota // string value read with ReadValue.
+	FIELD_NUMBER                   // int value read with ReadNumber.
+	FIELD_DECIMAL                  // float64 value read with ReadDecimal.
+	FIELD_BOOL                     // bool value read with SelectYesNo.
+	FIELD_SELECT                   // string value chosen from Options with Menu.
+)
+
+// Field defines a single input of a form.
+type Field struct {
+	Name       string      // Name is the key to store the value, nested keys use dots.
+	Type       FieldType   // Type is the kind of input to read.
+	Prompt     string      // Prompt is the message shown, defaults to Name.
+	Default    any         // Default is used when no current value exists.
+	Options    []string    // Options are the choices of select fields.
+	Decimals   int         // Decimals is the precision of decimal fields.
+	Required   bool        // Required marks the input as mandatory.
+	Secure     bool        // Secure reads string fields hidden and confirmed.
+	Validators []Validator // Validators are checked on the raw input.
+}
+
+// Form is a declarative set of fields that are filled interactively from
+// the console into a Dict, such as for a configuration wizard.
+type Form struct {
+	Title  string  // Title is written before the first field if set.
+	Fields []Field // Fields are read in order.
+}
+
+// NewForm creates a new Form with the given fields.
+func NewForm(fields ...Field) *Form {
+	return &Form{Fields: fields}
+}
+
+// Add appends a field to the form.
+func (f *Form) Add(field Field) *Form {
+	f.Fields = append(f.Fields, field)
+	return f
+}
+
+// Run reads all fields of the form and returns the values in a new Dict.
+func (f *Form) Run(con *Console) (dictx.Dict, error) {
+	values := dictx.Dict{}
+	if err := f.Fill(con, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// Fill reads all fields of the form using the current values as defaults
+// and updates values with the input. The values are only updated when all
+// fields are read successfully.
+func (f *Form) Fill(con *Console, values dictx.Dict) error {
+	if f.Title != "" {
+		con.handler.Write(con.cAsk.Sprint(f.Title) + "\n\r")
+	}
+
+	result := dictx.Dict{}
+	for _, field := range f.Fields {
+		if field.Name == "" {
+			return fmt.Errorf("form field name cannot be empty")
+		}
+		val, err := field.read(con, dictx.Get(values, field.Name, nil))
+		if err != nil {
+			return err
+		}
+		dictx.Set(result, field.Name, val)
+	}
+
+	dictx.Merge(values, result)
+	return nil
+}
+
+// read reads the field value from the console, using the current value
+// or the field default for the input default.
+func (f *Field) read(con *Console, current any) (any, error) {
+	prompt := f.Prompt
+	if prompt == "" {
+		prompt = f.Name
+	}
+	if f.Secure && f.Type == FIELD_STRING {
+		return f.readSecure(con, prompt, current)
+	}
+
+	// lookup holds the default so the dictx conversions can be reused
+	lookup := dictx.Dict{"value": f.Default}
+	if current != nil {
+		lookup["value"] = current
+	}
+	hasDefault := lookup["value"] != nil
+
+	if f.Required {
+		con.Required()
+	}
+	con.Validate(f.Validators...)
+
+	switch f.Type {
+	case FIELD_STRING:
+		def := ""
+		if hasDefault {
+			def = dictx.GetString(lookup, "value", "")
+		}
+		return con.ReadValue(prompt, def)
+	case FIELD_NUMBER:
+		val, err := con.ReadNumber(prompt,
+			int64(dictx.GetInt(lookup, "value", 0)))
+		return int(val), err
+	case FIELD_DECIMAL:
+		return con.ReadDecimal(prompt, f.Decimals,
+			dictx.GetFloat(lookup, "value", 0))
+	case FIELD_BOOL:
+		def := ""
+		if hasDefault {
+			def = "n"
+			if dictx.Fetch(lookup, "value", false) {
+				def = "y"
+			}
+		}
+		return con.SelectYesNo(prompt, def)
+	case FIELD_SELECT:
+		def := ""
+		if hasDefault {
+			def = dictx.GetString(lookup, "value", "")
+		}
+		return con.Menu(prompt, f.Options, def)
+	}
+
+	con.resetFlags()
+	return nil, fmt.Errorf("invalid type for form field '%s'", f.Name)
+}
+
+// readSecure reads a hidden string value and asks to confirm it. The
+// current value is never shown and is kept if the input is left empty.
+func (f *Field) readSecure(con *Console, prompt string, current any) (any, error) {
+	if current == nil && f.Default != nil {
+		current = f.Default
+	}
+	if f.Required && current == nil {
+		con.Required()
+	}
+
+	val, err := con.Hidden().Validate(f.Validators...).ReadValue(prompt, "")
+	if err != nil {
+		return nil, err
+	}
+	if val == "" {
+		if current != nil {
+			return current, nil
+		}
+		return "", nil
+	}
+
+	if err := con.Hidden().ConfirmValue("Confirm "+prompt, val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
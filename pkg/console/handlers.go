@@ -4,6 +4,11 @@
 
 package console
 
+import (
+	"os"
+	"strings"
+)
+
 // Handler defines the interface for reading and writing from/to the console.
 // It includes methods to read regular and hidden input, write output, and close the handler.
 type Handler interface {
@@ -12,3 +17,98 @@ type Handler interface {
 	Write(string) error                // Write outputs a formatted message to the console.
 	Close() error                      // Close cleans up any resources used by the handler.
 }
+
+// CompleteFunc returns the completion candidates for the input typed so far
+// before the cursor. Each candidate replaces that whole input when chosen.
+type CompleteFunc func(input string) []string
+
+// CompletionHandler is an optional interface implemented by handlers that
+// support tab-completion while reading input.
+type CompletionHandler interface {
+	SetCompleter(CompleteFunc) // SetCompleter sets the callback, nil disables completion.
+}
+
+// PrefixCompleter returns a CompleteFunc that completes the input to any
+// of the given words that start with it.
+func PrefixCompleter(words ...string) CompleteFunc {
+	return func(input string) []string {
+		result := []string{}
+		for _, w := range words {
+			if strings.HasPrefix(w, input) {
+				result = append(result, w)
+			}
+		}
+		return result
+	}
+}
+
+// completeInput resolves the completion for the input before the cursor.
+// A single candidate is completed in full, while several candidates are
+// completed to their longest common prefix, or returned for listing when
+// that prefix adds nothing to the input.
+func completeInput(fn CompleteFunc, input string) (string, []string) {
+	cands := fn(input)
+	switch len(cands) {
+	case 0:
+		return input, nil
+	case 1:
+		return cands[0], nil
+	}
+
+	prefix := []rune(cands[0])
+	for _, c := range cands[1:] {
+		for !strings.HasPrefix(c, string(prefix)) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if len(string(prefix)) > len(input) &&
+		strings.HasPrefix(string(prefix), input) {
+		return string(prefix), nil
+	}
+	return input, cands
+}
+
+// Key represents a decoded key press read in raw terminal mode.
+type Key int
+
+// Keys recognized by interactive menus.
+const (
+	KEY_OTHER Key = iota
+	KEY_UP
+	KEY_DOWN
+	KEY_ENTER
+	KEY_SPACE
+	KEY_CANCEL
+)
+
+// KeyHandler is an optional interface implemented by handlers that can read
+// single key presses, which enables arrow-key navigation in menus.
+type KeyHandler interface {
+	Interactive() bool     // Interactive reports if key reading is supported.
+	ReadKey() (Key, error) // ReadKey reads and decodes a single key press.
+}
+
+// decodeKey maps raw terminal input bytes to a Key.
+func decodeKey(b []byte) Key {
+	if len(b) == 0 {
+		return KEY_OTHER
+	}
+	switch string(b) {
+	case "\x1b[A", "\x1bOA", "k":
+		return KEY_UP
+	case "\x1b[B", "\x1bOB", "j":
+		return KEY_DOWN
+	case "\r", "\n", "\r\n":
+		return KEY_ENTER
+	case " ":
+		return KEY_SPACE
+	case "\x1b", "\x03", "\x04", "q":
+		return KEY_CANCEL
+	}
+	return KEY_OTHER
+}
+
+// isDumbTerm checks if the terminal type lacks cursor movement support.
+func isDumbTerm() bool {
+	return os.Getenv("TERM") == "dumb"
+}
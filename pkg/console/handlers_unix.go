@@ -10,12 +10,16 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"golang.org/x/sys/unix"
 	"golang.org/x/term"
 )
 
 // TermHandler is a terminal-based implementation of the Handler interface.
-// It uses the 'golang.org/x/term' package for reading input from the terminal.
+// It uses the 'golang.org/x/term' package for reading input from the terminal,
+// which provides line editing with the arrow keys, Ctrl-A/E/W/K/U and the
+// history of previous inputs.
 type TermHandler struct {
 	tm *term.Terminal
 }
@@ -78,3 +82,73 @@ func (h *TermHandler) Write(msg string) error {
 	}
 	return nil
 }
+
+// ReadMasked prompts the user for hidden input, echoing a mask char per
+// typed char and applying the paste and inactivity policy of opts.
+func (h *TermHandler) ReadMasked(msg string, opts MaskOptions) (string, error) {
+	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", fmt.Errorf("failed to set terminal to raw mode: %v", err)
+	}
+	defer term.Restore(int(os.Stdin.Fd()), oldState)
+
+	return readMasked(os.Stdin, h.Write, waitInput, msg, opts)
+}
+
+// waitInput waits until stdin has input available or the timeout expires.
+func waitInput(timeout time.Duration) (bool, error) {
+	fds := []unix.PollFd{{Fd: int32(os.Stdin.Fd()), Events: unix.POLLIN}}
+	for {
+		n, err := unix.Poll(fds, int(timeout.Milliseconds()))
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to wait for input: %v", err)
+		}
+		return n > 0, nil
+	}
+}
+
+// SetCompleter sets the tab-completion callback used by Read, nil disables
+// completion. When several candidates remain they are listed above the
+// prompt, as readline does.
+func (h *TermHandler) SetCompleter(fn CompleteFunc) {
+	if fn == nil {
+		h.tm.AutoCompleteCallback = nil
+		return
+	}
+	h.tm.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		prefix, cands := completeInput(fn, line[:pos])
+		if len(cands) > 0 {
+			h.tm.Write([]byte(strings.Join(cands, "  ") + "\n"))
+		}
+		return prefix + line[pos:], len(prefix), true
+	}
+}
+
+// Interactive reports if the terminal supports raw key reading, which
+// requires both stdin and stdout to be terminals and a non-dumb TERM.
+func (h *TermHandler) Interactive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) &&
+		term.IsTerminal(int(os.Stdout.Fd())) && !isDumbTerm()
+}
+
+// ReadKey reads a single key press from the terminal in raw mode.
+func (h *TermHandler) ReadKey() (Key, error) {
+	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return KEY_OTHER, fmt.Errorf("failed to set terminal to raw mode: %v", err)
+	}
+	defer term.Restore(int(os.Stdin.Fd()), oldState)
+
+	buf := make([]byte, 8)
+	n, err := os.Stdin.Read(buf)
+	if err != nil {
+		return KEY_OTHER, err
+	}
+	return decodeKey(buf[:n]), nil
+}
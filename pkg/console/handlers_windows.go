@@ -11,8 +11,10 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"golang.org/x/sys/windows"
+	"golang.org/x/term"
 )
 
 // TermHandler is a terminal-based implementation of the Handler interface.
@@ -77,3 +79,49 @@ func (h *TermHandler) Write(msg string) error {
 	}
 	return nil
 }
+
+// ReadMasked prompts the user for hidden input, echoing a mask char per
+// typed char and applying the paste and inactivity policy of opts.
+func (h *TermHandler) ReadMasked(msg string, opts MaskOptions) (string, error) {
+	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", fmt.Errorf("unable to set console mode: %v", err)
+	}
+	defer term.Restore(int(os.Stdin.Fd()), oldState)
+
+	return readMasked(os.Stdin, h.Write, waitInput, msg, opts)
+}
+
+// waitInput waits until the console has input events or the timeout expires.
+func waitInput(timeout time.Duration) (bool, error) {
+	handle := windows.Handle(os.Stdin.Fd())
+	ev, err := windows.WaitForSingleObject(handle, uint32(timeout.Milliseconds()))
+	if err != nil {
+		return false, fmt.Errorf("failed to wait for input: %v", err)
+	}
+	return ev == windows.WAIT_OBJECT_0, nil
+}
+
+// Interactive reports if the console supports raw key reading, which
+// requires both stdin and stdout to be terminals and a non-dumb TERM.
+func (h *TermHandler) Interactive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) &&
+		term.IsTerminal(int(os.Stdout.Fd())) && !isDumbTerm()
+}
+
+// ReadKey reads a single key press from the console in raw mode. Raw mode
+// enables virtual terminal input so arrow keys arrive as escape sequences.
+func (h *TermHandler) ReadKey() (Key, error) {
+	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return KEY_OTHER, fmt.Errorf("unable to set console mode: %v", err)
+	}
+	defer term.Restore(int(os.Stdin.Fd()), oldState)
+
+	buf := make([]byte, 8)
+	n, err := os.Stdin.Read(buf)
+	if err != nil {
+		return KEY_OTHER, err
+	}
+	return decodeKey(buf[:n]), nil
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package console
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInputTimeout is returned when no input is received within the
+	// inactivity timeout.
+	ErrInputTimeout = errors.New("input timeout")
+	// ErrPasteDenied is returned when input was pasted while pasting
+	// is disabled.
+	ErrPasteDenied = errors.New("pasting input is not allowed")
+)
+
+// MaskOptions defines the policy for reading hidden input.
+type MaskOptions struct {
+	Mask    rune          // Mask is echoed for each typed char, 0 echoes nothing.
+	NoPaste bool          // NoPaste rejects input that was pasted.
+	Timeout time.Duration // Timeout aborts input after inactivity, 0 disables it.
+}
+
+// MaskedHandler is an optional interface implemented by handlers that can
+// read hidden input with masking, paste protection and inactivity timeout.
+type MaskedHandler interface {
+	ReadMasked(string, MaskOptions) (string, error) // ReadMasked reads hidden input with options.
+}
+
+// bracketed paste mode control and markers
+const (
+	pasteModeOn  = "\x1b[?2004h"
+	pasteModeOff = "\x1b[?2004l"
+	pasteStart   = "\x1b[200~"
+	pasteEnd     = "\x1b[201~"
+)
+
+// readMasked reads a line of hidden input from a terminal in raw mode.
+// The wait function blocks until input is available or the timeout expires.
+// Pasted text is detected either by the bracketed paste markers or, for
+// terminals without bracketed paste, by several chars arriving in one read.
+func readMasked(in io.Reader, write func(string) error,
+	wait func(time.Duration) (bool, error), msg string, opts MaskOptions) (string, error) {
+	if err := write(msg); err != nil {
+		return "", err
+	}
+	if opts.NoPaste {
+		write(pasteModeOn)
+		defer write(pasteModeOff)
+	}
+
+	echo := func(s string) {
+		if opts.Mask != 0 {
+			write(s)
+		}
+	}
+	mask := string(opts.Mask)
+
+	input := []rune{}
+	pasted := false
+	buf := make([]byte, 256)
+	for {
+		if opts.Timeout > 0 {
+			ok, err := wait(opts.Timeout)
+			if err != nil {
+				return "", err
+			}
+			if !ok {
+				write("\n\r")
+				return "", ErrInputTimeout
+			}
+		}
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return "", err
+		}
+
+		data := buf[:n]
+		chars := 0
+		for len(data) > 0 {
+			switch {
+			case bytes.HasPrefix(data, []byte(pasteStart)):
+				pasted = true
+				data = data[len(pasteStart):]
+				continue
+			case bytes.HasPrefix(data, []byte(pasteEnd)):
+				data = data[len(pasteEnd):]
+				continue
+			}
+
+			switch b := data[0]; b {
+			case '\r', '\n':
+				write("\n\r")
+				if opts.NoPaste && pasted {
+					return "", ErrPasteDenied
+				}
+				return string(input), nil
+			case 0x03, 0x04: // Ctrl-C, Ctrl-D
+				write("\n\r")
+				return "", io.EOF
+			case 0x7f, 0x08: // Backspace
+				if len(input) > 0 {
+					input = input[:len(input)-1]
+					echo("\b \b")
+				}
+				data = data[1:]
+			case 0x15: // Ctrl-U
+				for range input {
+					echo("\b \b")
+				}
+				input = input[:0]
+				data = data[1:]
+			case 0x1b:
+				data = skipEscape(data)
+			default:
+				r, size := utf8.DecodeRune(data)
+				data = data[size:]
+				if r < 0x20 || r == utf8.RuneError {
+					continue
+				}
+				input = append(input, r)
+				echo(mask)
+				chars++
+			}
+		}
+		if chars > 1 {
+			pasted = true
+		}
+	}
+}
+
+// skipEscape drops an escape sequence from the start of data.
+func skipEscape(data []byte) []byte {
+	if len(data) < 2 || (data[1] != '[' && data[1] != 'O') {
+		return data[1:]
+	}
+	for i := 2; i < len(data); i++ {
+		if data[i] >= 0x40 && data[i] <= 0x7e {
+			return data[i+1:]
+		}
+	}
+	return nil
+}
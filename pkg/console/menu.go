@@ -0,0 +1,183 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package console
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Menu prompts the user to choose one value from a list of options.
+// On interactive terminals the options are navigated with the arrow keys
+// and selected with enter, otherwise it falls back to a numbered list where
+// either the option number or its value can be entered.
+func (c *Console) Menu(msg string, options []string, defVal string) (string, error) {
+	defer c.resetFlags()
+
+	if len(options) == 0 {
+		return "", errors.New("no options to select from")
+	}
+
+	if kh, ok := c.handler.(KeyHandler); ok && kh.Interactive() {
+		val, err := c.runMenu(kh, msg, options, []string{defVal}, false)
+		if err != nil {
+			return "", err
+		}
+		return val[0], nil
+	}
+
+	c.parser = func(input string) (any, error) {
+		return SelectionParser(input, options, false)
+	}
+
+	var v any
+	if !c.required || defVal != "" {
+		v = defVal
+	}
+
+	c.writeOptions(options)
+	val, err := c.getInput(msg, v)
+	if err != nil {
+		return "", err
+	}
+	if sel, ok := val.([]string); ok {
+		return sel[0], nil
+	}
+	return val.(string), nil
+}
+
+// SelectMulti prompts the user to choose any number of values from a list
+// of options, with defaults preselected. On interactive terminals the
+// options are navigated with the arrow keys, toggled with space and
+// confirmed with enter, otherwise it falls back to a numbered list where
+// comma separated option numbers or values can be entered.
+func (c *Console) SelectMulti(msg string, options []string, defaults []string) ([]string, error) {
+	defer c.resetFlags()
+
+	if len(options) == 0 {
+		return nil, errors.New("no options to select from")
+	}
+
+	if kh, ok := c.handler.(KeyHandler); ok && kh.Interactive() {
+		return c.runMenu(kh, msg, options, defaults, true)
+	}
+
+	c.parser = func(input string) (any, error) {
+		return SelectionParser(input, options, true)
+	}
+
+	var v any
+	if !c.required || len(defaults) > 0 {
+		v = strings.Join(defaults, ",")
+	}
+
+	c.writeOptions(options)
+	val, err := c.getInput(msg+" (comma separated)", v)
+	if err != nil {
+		return nil, err
+	}
+	if sel, ok := val.([]string); ok {
+		return sel, nil
+	}
+	return append([]string{}, defaults...), nil
+}
+
+// writeOptions writes the numbered list of options for non-interactive
+// selection.
+func (c *Console) writeOptions(options []string) {
+	for i, opt := range options {
+		c.handler.Write(fmt.Sprintf("  %d) %s\n\r", i+1, opt))
+	}
+}
+
+// runMenu runs the interactive key-driven selection loop. The options are
+// redrawn in place after each key press by moving the cursor back up.
+// Canceling the menu returns io.EOF, the same as ending the input stream.
+func (c *Console) runMenu(kh KeyHandler, msg string, options []string,
+	defaults []string, multi bool) ([]string, error) {
+	cursor := 0
+	selected := make([]bool, len(options))
+	for i, opt := range options {
+		for _, d := range defaults {
+			if opt == d {
+				selected[i] = true
+				if !multi {
+					cursor = i
+				}
+			}
+		}
+	}
+
+	hint := "(arrows to move, enter to select)"
+	if multi {
+		hint = "(arrows to move, space to toggle, enter to confirm)"
+	}
+	c.handler.Write(c.cAsk.Sprintf("%s %s: ", c.Prompt, msg) + hint + "\n\r")
+
+	render := func() {
+		for i, opt := range options {
+			line := "  "
+			if i == cursor {
+				line = "> "
+			}
+			if multi {
+				if selected[i] {
+					line += "[x] "
+				} else {
+					line += "[ ] "
+				}
+			}
+			line += opt
+			if i == cursor {
+				line = c.cAsk.Sprint(line)
+			}
+			c.handler.Write("\r\x1b[2K" + line + "\n\r")
+		}
+	}
+
+	render()
+	for {
+		key, err := kh.ReadKey()
+		if err != nil {
+			return nil, err
+		}
+
+		switch key {
+		case KEY_UP:
+			cursor = (cursor + len(options) - 1) % len(options)
+		case KEY_DOWN:
+			cursor = (cursor + 1) % len(options)
+		case KEY_SPACE:
+			if !multi {
+				continue
+			}
+			selected[cursor] = !selected[cursor]
+		case KEY_ENTER:
+			if !multi {
+				return []string{options[cursor]}, nil
+			}
+			result := []string{}
+			for i, opt := range options {
+				if selected[i] {
+					result = append(result, opt)
+				}
+			}
+			// keep waiting for a selection when input is required
+			if len(result) == 0 && c.required {
+				continue
+			}
+			return result, nil
+		case KEY_CANCEL:
+			return nil, io.EOF
+		default:
+			continue
+		}
+
+		c.handler.Write(fmt.Sprintf("\x1b[%dA", len(options)))
+		render()
+	}
+}
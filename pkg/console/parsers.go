@@ -9,6 +9,7 @@ import (
 	"math"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 // RegexParser validates the input string using a provided regular expression.
@@ -76,3 +77,50 @@ func DecimalParser(input string, decimals int, vmin, vmax *float64) (float64, er
 
 	return val, nil
 }
+
+// SelectionParser resolves the input against a list of options. Entries are
+// either 1-based option numbers or exact option values, separated by commas
+// when multi is set. Returns the selected options in input order without
+// duplicates, or an error if any entry does not match an option.
+func SelectionParser(input string, options []string, multi bool) ([]string, error) {
+	if input == "" {
+		return nil, fmt.Errorf("empty input")
+	}
+
+	entries := []string{input}
+	if multi {
+		entries = strings.Split(input, ",")
+	}
+
+	result := []string{}
+	seen := map[string]bool{}
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		sel := ""
+		if n, err := strconv.Atoi(e); err == nil && n >= 1 && n <= len(options) {
+			sel = options[n-1]
+		} else {
+			for _, opt := range options {
+				if opt == e {
+					sel = opt
+					break
+				}
+			}
+		}
+		if sel == "" {
+			return nil, fmt.Errorf("invalid selection: %s", e)
+		}
+		if !seen[sel] {
+			seen[sel] = true
+			result = append(result, sel)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("empty input")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,207 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package console
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/fatih/color"
+)
+
+// Column alignments.
+const (
+	ALIGN_LEFT   = "left"
+	ALIGN_RIGHT  = "right"
+	ALIGN_CENTER = "center"
+)
+
+// TRUNCATE_MARK defines the mark appended to truncated cells.
+const TRUNCATE_MARK = "..."
+
+// matches ANSI escape sequences
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// visibleLen returns the text length without ANSI escape sequences.
+func visibleLen(s string) int {
+	return utf8.RuneCountInString(ansiRegex.ReplaceAllString(s, ""))
+}
+
+// truncate shortens text to max visible width, dropping its styling
+// if truncated. Setting max=0 disables truncation.
+func truncate(s string, max int) string {
+	if max <= 0 || visibleLen(s) <= max {
+		return s
+	}
+	r := []rune(ansiRegex.ReplaceAllString(s, ""))
+	if max <= len(TRUNCATE_MARK) {
+		return string(r[:max])
+	}
+	return string(r[:max-len(TRUNCATE_MARK)]) + TRUNCATE_MARK
+}
+
+// pad aligns text within width.
+func pad(s string, width int, align string) string {
+	n := width - visibleLen(s)
+	if n <= 0 {
+		return s
+	}
+	switch align {
+	case ALIGN_RIGHT:
+		return strings.Repeat(" ", n) + s
+	case ALIGN_CENTER:
+		return strings.Repeat(" ", n/2) + s + strings.Repeat(" ", n-n/2)
+	}
+	return s + strings.Repeat(" ", n)
+}
+
+// FormatTable renders the rows as a text table with headers.
+// The parsed options are:
+//   - align: ([]string) the columns alignments {left|right|center}.
+//     (default is left)
+//   - max_width: (int) the max column width, where longer cells are
+//     truncated. (default is no limit)
+//   - separator: (string) the columns separator. (default is 2 spaces)
+//   - border: (bool) draws the table borders. (default is false)
+//   - style: (bool) enables the ANSI styling of headers. (default is false)
+func FormatTable(headers []string, rows [][]string, opts dictx.Dict) string {
+	aligns := dictx.Fetch(opts, "align", []string(nil))
+	maxWidth := dictx.GetInt(opts, "max_width", 0)
+	sep := dictx.GetString(opts, "separator", "  ")
+	border := dictx.Fetch(opts, "border", false)
+	style := dictx.Fetch(opts, "style", false)
+
+	ncols := len(headers)
+	for _, row := range rows {
+		if len(row) > ncols {
+			ncols = len(row)
+		}
+	}
+	if ncols == 0 {
+		return ""
+	}
+
+	// truncated cells and columns widths
+	cell := func(row []string, i int) string {
+		if i < len(row) {
+			return truncate(row[i], maxWidth)
+		}
+		return ""
+	}
+	hdrs := make([]string, ncols)
+	widths := make([]int, ncols)
+	for i := range hdrs {
+		hdrs[i] = cell(headers, i)
+		widths[i] = visibleLen(hdrs[i])
+	}
+	cells := make([][]string, len(rows))
+	for r, row := range rows {
+		cells[r] = make([]string, ncols)
+		for i := range cells[r] {
+			cells[r][i] = cell(row, i)
+			if n := visibleLen(cells[r][i]); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	align := func(i int) string {
+		if i < len(aligns) {
+			return aligns[i]
+		}
+		return ALIGN_LEFT
+	}
+
+	var b strings.Builder
+	line := func(vals []string, hdr bool) {
+		parts := make([]string, ncols)
+		for i, v := range vals {
+			v = pad(v, widths[i], align(i))
+			if hdr && style {
+				v = color.New(color.Bold).Sprint(v)
+			}
+			parts[i] = v
+		}
+		s := strings.Join(parts, sep)
+		if border {
+			s = "| " + strings.Join(parts, " | ") + " |"
+		}
+		b.WriteString(strings.TrimRight(s, " ") + "\n")
+	}
+	rule := func() {
+		if !border {
+			return
+		}
+		parts := make([]string, ncols)
+		for i, w := range widths {
+			parts[i] = strings.Repeat("-", w+2)
+		}
+		b.WriteString("+" + strings.Join(parts, "+") + "+\n")
+	}
+
+	rule()
+	if len(headers) > 0 {
+		line(hdrs, true)
+		if border {
+			rule()
+		} else {
+			parts := make([]string, ncols)
+			for i, w := range widths {
+				parts[i] = strings.Repeat("-", w)
+			}
+			b.WriteString(strings.Join(parts, sep) + "\n")
+		}
+	}
+	for _, row := range cells {
+		line(row, false)
+	}
+	rule()
+	return b.String()
+}
+
+// FormatList renders the items as a text list.
+// The parsed options are:
+//   - numbered: (bool) numbers the items instead of bullets.
+//     (default is false)
+//   - bullet: (string) the items bullet. (default is "-")
+//   - indent: (int) the list indentation. (default is 0)
+//   - max_width: (int) the max item width, where longer items are
+//     truncated. (default is no limit)
+//   - style: (bool) enables the ANSI styling of bullets. (default is false)
+func FormatList(items []string, opts dictx.Dict) string {
+	numbered := dictx.Fetch(opts, "numbered", false)
+	bullet := dictx.GetString(opts, "bullet", "-")
+	indent := strings.Repeat(" ", dictx.GetInt(opts, "indent", 0))
+	maxWidth := dictx.GetInt(opts, "max_width", 0)
+	style := dictx.Fetch(opts, "style", false)
+
+	numWidth := len(fmt.Sprint(len(items)))
+	var b strings.Builder
+	for i, item := range items {
+		mark := bullet
+		if numbered {
+			mark = fmt.Sprintf("%*d.", numWidth, i+1)
+		}
+		if style {
+			mark = color.New(color.Bold).Sprint(mark)
+		}
+		b.WriteString(indent + mark + " " + truncate(item, maxWidth) + "\n")
+	}
+	return b.String()
+}
+
+// PrintTable writes the rows as a text table to console,
+// see [FormatTable] for options.
+func (c *Console) PrintTable(headers []string, rows [][]string, opts dictx.Dict) error {
+	return c.handler.Write(FormatTable(headers, rows, opts))
+}
+
+// PrintList writes the items as a text list to console,
+// see [FormatList] for options.
+func (c *Console) PrintList(items []string, opts dictx.Dict) error {
+	return c.handler.Write(FormatList(items, opts))
+}
@@ -7,11 +7,14 @@ package console_test
 import (
 	"bytes"
 	"errors"
+	"io"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
 	"github.com/exonlabs/go-utils/pkg/console"
 )
 
@@ -152,6 +155,61 @@ func TestConsole_SelectValue(t *testing.T) {
 	require.Error(t, err, "Expected error for invalid selection")
 }
 
+func TestConsole_Validate(t *testing.T) {
+	mockHandler := &MockHandler{input: "10.0.0.1"}
+	con, err := console.New(mockHandler)
+	require.NoError(t, err)
+
+	val, err := con.Validate(console.IPv4Validator).ReadValue("Address", "")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", val)
+
+	// validators are chained and the first failure is reported
+	mockHandler.writeBuf.Reset()
+	_, err = con.Validate(console.IPValidator, console.IPv6Validator).
+		ReadValue("Address", "")
+	assert.Error(t, err)
+	assert.Contains(t, mockHandler.writeBuf.String(), "invalid IPv6 address")
+
+	// validators apply to selections and are reset after each input
+	_, err = con.Validate(console.MACValidator).
+		SelectValue("Address", []string{"10.0.0.1", "host"}, "")
+	assert.Error(t, err)
+	val, err = con.SelectValue("Address", []string{"10.0.0.1", "host"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", val)
+}
+
+func TestValidators(t *testing.T) {
+	tests := []struct {
+		v     console.Validator
+		valid []string
+		bad   []string
+	}{
+		{console.IPValidator, []string{"10.0.0.1", "fe80::1"}, []string{"10.0.0.256", "host"}},
+		{console.IPv4Validator, []string{"192.168.1.1"}, []string{"fe80::1", "::ffff:1.2.3.4", "1.2.3"}},
+		{console.IPv6Validator, []string{"fe80::1", "::1"}, []string{"10.0.0.1"}},
+		{console.CIDRValidator, []string{"10.0.0.0/8", "fe80::/64"}, []string{"10.0.0.0", "10.0.0.0/33"}},
+		{console.MACValidator, []string{"00:1a:2b:3c:4d:5e", "00-1A-2B-3C-4D-5E"}, []string{"00:1a:2b", "zz:1a:2b:3c:4d:5e"}},
+		{console.URLValidator, []string{"http://example.com/path", "tcp://localhost:1234"}, []string{"example.com", "http://", "/path"}},
+		{console.PortValidator, []string{"1", "8080", "65535"}, []string{"0", "65536", "http"}},
+		{console.HostnameValidator, []string{"localhost", "a-b.example.com", "example.com."}, []string{"-host", "host_1", "a..b", ""}},
+		{console.DurationValidator, []string{"30s", "1h30m", "250ms", "2d", "30"}, []string{"30x", "1 h"}},
+		{console.SizeValidator, []string{"512", "10MiB", "1.5G"}, []string{"10XB", "-1"}},
+		{console.BoolValidator, []string{"yes", "off", "true"}, []string{"maybe", ""}},
+		{console.RegexValidator("^[a-z]+$"), []string{"abc"}, []string{"ABC", ""}},
+		{console.AnyOf(console.IPValidator, console.HostnameValidator), []string{"10.0.0.1", "host"}, []string{"host_1"}},
+	}
+	for _, tc := range tests {
+		for _, in := range tc.valid {
+			assert.NoError(t, tc.v(in), "input: %s", in)
+		}
+		for _, in := range tc.bad {
+			assert.Error(t, tc.v(in), "input: %s", in)
+		}
+	}
+}
+
 func TestConsole_SelectValue_InvalidSelection(t *testing.T) {
 	mockHandler := &MockHandler{input: "invalid"}
 	options := []string{"option1", "option2", "option3"}
@@ -199,3 +257,351 @@ func TestConsole_Close(t *testing.T) {
 	err = con.Close()
 	assert.NoError(t, err)
 }
+
+// KeyMockHandler is a MockHandler that also replays key presses.
+type KeyMockHandler struct {
+	MockHandler
+	keys []console.Key
+}
+
+func (m *KeyMockHandler) Interactive() bool {
+	return true
+}
+
+func (m *KeyMockHandler) ReadKey() (console.Key, error) {
+	if len(m.keys) == 0 {
+		return console.KEY_OTHER, io.EOF
+	}
+	k := m.keys[0]
+	m.keys = m.keys[1:]
+	return k, nil
+}
+
+func TestConsole_Menu_Numbered(t *testing.T) {
+	mockHandler := &MockHandler{input: "2"}
+	con, err := console.New(mockHandler)
+	require.NoError(t, err)
+
+	val, err := con.Menu("Select", []string{"a", "b", "c"}, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "b", val)
+	assert.Contains(t, mockHandler.writeBuf.String(), "2) b")
+
+	mockHandler.input = "c"
+	val, err = con.Menu("Select", []string{"a", "b", "c"}, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "c", val)
+
+	mockHandler.input = ""
+	val, err = con.Menu("Select", []string{"a", "b", "c"}, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "a", val)
+
+	mockHandler.input = "4"
+	_, err = con.Menu("Select", []string{"a", "b", "c"}, "a")
+	assert.Error(t, err)
+}
+
+func TestConsole_Menu_Keys(t *testing.T) {
+	mockHandler := &KeyMockHandler{keys: []console.Key{
+		console.KEY_DOWN, console.KEY_DOWN, console.KEY_UP, console.KEY_ENTER,
+	}}
+	con, err := console.New(mockHandler)
+	require.NoError(t, err)
+
+	// starts at default "b", wraps around to "a" then back to "c"
+	val, err := con.Menu("Select", []string{"a", "b", "c"}, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "c", val)
+
+	mockHandler.keys = []console.Key{console.KEY_CANCEL}
+	_, err = con.Menu("Select", []string{"a", "b", "c"}, "")
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestConsole_SelectMulti_Numbered(t *testing.T) {
+	mockHandler := &MockHandler{input: "1, c,1"}
+	con, err := console.New(mockHandler)
+	require.NoError(t, err)
+
+	val, err := con.SelectMulti("Select", []string{"a", "b", "c"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, val)
+
+	mockHandler.input = ""
+	val, err = con.SelectMulti("Select", []string{"a", "b", "c"}, []string{"b"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, val)
+
+	mockHandler.input = "1,x"
+	_, err = con.SelectMulti("Select", []string{"a", "b", "c"}, nil)
+	assert.Error(t, err)
+
+	_, err = con.SelectMulti("Select", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestConsole_SelectMulti_Keys(t *testing.T) {
+	mockHandler := &KeyMockHandler{keys: []console.Key{
+		console.KEY_SPACE, console.KEY_DOWN, console.KEY_DOWN,
+		console.KEY_SPACE, console.KEY_ENTER,
+	}}
+	con, err := console.New(mockHandler)
+	require.NoError(t, err)
+
+	// toggles off default "a" and toggles on "c"
+	val, err := con.SelectMulti("Select", []string{"a", "b", "c"}, []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, val)
+
+	// required input ignores enter until something is selected
+	mockHandler.keys = []console.Key{
+		console.KEY_ENTER, console.KEY_SPACE, console.KEY_ENTER,
+	}
+	val, err = con.Required().SelectMulti("Select", []string{"a", "b"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, val)
+}
+
+// CompleteMockHandler is a MockHandler that records the completion callback.
+type CompleteMockHandler struct {
+	MockHandler
+	completer console.CompleteFunc
+	used      bool
+}
+
+func (m *CompleteMockHandler) SetCompleter(fn console.CompleteFunc) {
+	m.completer = fn
+}
+
+func (m *CompleteMockHandler) Read(msg string) (string, error) {
+	if m.completer != nil {
+		m.used = true
+		return m.completer(m.input)[0], nil
+	}
+	return m.MockHandler.Read(msg)
+}
+
+func TestConsole_Complete(t *testing.T) {
+	mockHandler := &CompleteMockHandler{MockHandler: MockHandler{input: "sh"}}
+	con, err := console.New(mockHandler)
+	require.NoError(t, err)
+
+	val, err := con.Complete(console.PrefixCompleter("status", "show")).
+		ReadValue("Command", "")
+	require.NoError(t, err)
+	assert.Equal(t, "show", val)
+	assert.True(t, mockHandler.used)
+	assert.Nil(t, mockHandler.completer, "completer should be cleared")
+
+	// completion is not kept for the next input
+	mockHandler.used = false
+	val, err = con.ReadValue("Command", "")
+	require.NoError(t, err)
+	assert.Equal(t, "sh", val)
+	assert.False(t, mockHandler.used)
+}
+
+func TestPrefixCompleter(t *testing.T) {
+	fn := console.PrefixCompleter("show", "shutdown", "status")
+	assert.Equal(t, []string{"show", "shutdown"}, fn("sh"))
+	assert.Equal(t, []string{"show", "shutdown", "status"}, fn(""))
+	assert.Empty(t, fn("x"))
+}
+
+// MaskedMockHandler is a MockHandler that replays masked read results.
+type MaskedMockHandler struct {
+	MockHandler
+	opts  []console.MaskOptions
+	errs  []error
+	calls int
+}
+
+func (m *MaskedMockHandler) ReadMasked(msg string, opts console.MaskOptions) (string, error) {
+	m.opts = append(m.opts, opts)
+	m.calls++
+	if len(m.errs) > 0 {
+		err := m.errs[0]
+		m.errs = m.errs[1:]
+		return "", err
+	}
+	return m.input, nil
+}
+
+func TestConsole_Masked(t *testing.T) {
+	mockHandler := &MaskedMockHandler{MockHandler: MockHandler{input: "secret"}}
+	con, err := console.New(mockHandler)
+	require.NoError(t, err)
+
+	val, err := con.Mask('*').NoPaste().Timeout(time.Second).
+		ReadValue("Password", "")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", val)
+	require.Len(t, mockHandler.opts, 1)
+	assert.Equal(t, console.MaskOptions{
+		Mask: '*', NoPaste: true, Timeout: time.Second}, mockHandler.opts[0])
+
+	// plain hidden input keeps using ReadHidden
+	val, err = con.Hidden().ReadValue("Password", "")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", val)
+	assert.Equal(t, 1, mockHandler.calls)
+}
+
+func TestConsole_Masked_Errors(t *testing.T) {
+	mockHandler := &MaskedMockHandler{
+		MockHandler: MockHandler{input: "secret"},
+		errs:        []error{console.ErrPasteDenied},
+	}
+	con, err := console.New(mockHandler)
+	require.NoError(t, err)
+
+	// denied paste is retried
+	val, err := con.NoPaste().ReadValue("Password", "")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", val)
+	assert.Equal(t, 2, mockHandler.calls)
+	assert.Contains(t, mockHandler.writeBuf.String(), "pasting input is not allowed")
+
+	// timeout aborts without retrying
+	mockHandler.calls = 0
+	mockHandler.errs = []error{console.ErrInputTimeout}
+	_, err = con.Timeout(time.Second).ReadValue("Password", "")
+	assert.ErrorIs(t, err, console.ErrInputTimeout)
+	assert.Equal(t, 1, mockHandler.calls)
+}
+
+// SeqMockHandler is a MockHandler that replays a sequence of inputs.
+type SeqMockHandler struct {
+	MockHandler
+	inputs []string
+}
+
+func (m *SeqMockHandler) Read(msg string) (string, error) {
+	m.writeBuf.WriteString(msg)
+	if len(m.inputs) == 0 {
+		return "", io.EOF
+	}
+	in := m.inputs[0]
+	m.inputs = m.inputs[1:]
+	return in, nil
+}
+
+func (m *SeqMockHandler) ReadHidden(msg string) (string, error) {
+	return m.Read(msg)
+}
+
+func TestForm_Fill(t *testing.T) {
+	mockHandler := &SeqMockHandler{inputs: []string{
+		"", "70000", "8080", "n", "2", "", "pw", "pw",
+	}}
+	con, err := console.New(mockHandler)
+	require.NoError(t, err)
+
+	form := console.NewForm(
+		console.Field{Name: "name", Default: "device"},
+		console.Field{Name: "net.port", Type: console.FIELD_NUMBER,
+			Prompt: "Port", Validators: []console.Validator{console.PortValidator}},
+		console.Field{Name: "net.enabled", Type: console.FIELD_BOOL, Default: true},
+		console.Field{Name: "mode", Type: console.FIELD_SELECT,
+			Options: []string{"client", "server"}, Required: true},
+		console.Field{Name: "ratio", Type: console.FIELD_DECIMAL, Decimals: 2, Default: 0.5},
+		console.Field{Name: "secret", Secure: true, Required: true},
+	)
+	form.Title = "Setup"
+
+	values := dictx.Dict{"name": "old", "other": 1}
+	require.NoError(t, form.Fill(con, values))
+	assert.Equal(t, "old", dictx.Get(values, "name", nil))
+	assert.Equal(t, 8080, dictx.Get(values, "net.port", nil))
+	assert.Equal(t, false, dictx.Get(values, "net.enabled", nil))
+	assert.Equal(t, "server", dictx.Get(values, "mode", nil))
+	assert.Equal(t, 0.5, dictx.Get(values, "ratio", nil))
+	assert.Equal(t, "pw", dictx.Get(values, "secret", nil))
+	assert.Equal(t, 1, dictx.Get(values, "other", nil))
+
+	out := mockHandler.writeBuf.String()
+	assert.Contains(t, out, "Setup")
+	assert.Contains(t, out, "invalid port")
+	assert.Contains(t, out, "Confirm secret")
+}
+
+func TestForm_Run_Error(t *testing.T) {
+	mockHandler := &SeqMockHandler{inputs: []string{"value"}}
+	con, err := console.New(mockHandler)
+	require.NoError(t, err)
+
+	form := console.NewForm().
+		Add(console.Field{Name: "a"}).
+		Add(console.Field{Name: "b"})
+
+	// input ends before all fields are read
+	values := dictx.Dict{}
+	assert.ErrorIs(t, form.Fill(con, values), io.EOF)
+	assert.Empty(t, values, "values should not be partially updated")
+
+	mockHandler.inputs = []string{"x", ""}
+	values, err = form.Run(con)
+	require.NoError(t, err)
+	assert.Equal(t, dictx.Dict{"a": "x", "b": ""}, values)
+}
+
+func TestFormatTable(t *testing.T) {
+	headers := []string{"NAME", "STATE", "RESTARTS"}
+	rows := [][]string{
+		{"wrk1", "running", "0"},
+		{"worker-long-name", "\x1b[31mstopped\x1b[0m", "12"},
+		{"wrk3"},
+	}
+
+	assert.Equal(t, ""+
+		"NAME              STATE    RESTARTS\n"+
+		"----------------  -------  --------\n"+
+		"wrk1              running  0\n"+
+		"worker-long-name  \x1b[31mstopped\x1b[0m  12\n"+
+		"wrk3\n",
+		console.FormatTable(headers, rows, nil))
+
+	assert.Equal(t, ""+
+		"+-----------+---------+----------+\n"+
+		"| NAME      | STATE   | RESTARTS |\n"+
+		"+-----------+---------+----------+\n"+
+		"| wrk1      | running |        0 |\n"+
+		"| worker... | \x1b[31mstopped\x1b[0m |       12 |\n"+
+		"| wrk3      |         |          |\n"+
+		"+-----------+---------+----------+\n",
+		console.FormatTable(headers, rows, dictx.Dict{
+			"align":     []string{"left", "left", "right"},
+			"max_width": 9,
+			"border":    true,
+		}))
+
+	// truncated cells drop styling
+	assert.Equal(t, ""+
+		"NAME   STATE\n"+
+		"-----  -----\n"+
+		"wrk1   ru...\n"+
+		"wo...  st...\n"+
+		"wrk3\n",
+		console.FormatTable(headers[:2], [][]string{
+			rows[0][:2], rows[1][:2], rows[2]}, dictx.Dict{"max_width": 5}))
+
+	assert.Equal(t, "", console.FormatTable(nil, nil, nil))
+}
+
+func TestFormatList(t *testing.T) {
+	items := []string{"first", "second item", "third"}
+	assert.Equal(t, "- first\n- second item\n- third\n",
+		console.FormatList(items, nil))
+	assert.Equal(t, "  1. first\n  2. secon...\n  3. third\n",
+		console.FormatList(items, dictx.Dict{
+			"numbered": true, "indent": 2, "max_width": 8}))
+
+	mockHandler := &MockHandler{}
+	con, err := console.New(mockHandler)
+	require.NoError(t, err)
+	assert.NoError(t, con.PrintList(items, dictx.Dict{"bullet": "*"}))
+	assert.NoError(t, con.PrintTable([]string{"A"}, [][]string{{"1"}}, nil))
+	assert.Equal(t, "* first\n* second item\n* third\nA\n-\n1\n",
+		mockHandler.writeBuf.String())
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package console
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/exonlabs/go-utils/pkg/abc/parsex"
+)
+
+// Validator checks an input string and returns a user friendly error
+// describing the expected input when it is invalid.
+type Validator func(string) error
+
+// AnyOf returns a Validator that accepts the input if any of the given
+// validators accepts it. The errors of all validators are joined.
+func AnyOf(validators ...Validator) Validator {
+	return func(input string) error {
+		msgs := []string{}
+		for _, v := range validators {
+			err := v(input)
+			if err == nil {
+				return nil
+			}
+			msgs = append(msgs, err.Error())
+		}
+		return errors.New(strings.Join(msgs, " or "))
+	}
+}
+
+// RegexValidator returns a Validator that matches the input against the
+// given regular expression.
+func RegexValidator(regex string) Validator {
+	return func(input string) error {
+		_, err := RegexParser(input, regex)
+		return err
+	}
+}
+
+// IPValidator validates an IPv4 or IPv6 address.
+func IPValidator(input string) error {
+	if net.ParseIP(input) == nil {
+		return errors.New("invalid IP address")
+	}
+	return nil
+}
+
+// IPv4Validator validates an IPv4 address.
+func IPv4Validator(input string) error {
+	if ip := net.ParseIP(input); ip == nil || ip.To4() == nil ||
+		strings.Contains(input, ":") {
+		return errors.New("invalid IPv4 address, expected format x.x.x.x")
+	}
+	return nil
+}
+
+// IPv6Validator validates an IPv6 address.
+func IPv6Validator(input string) error {
+	if ip := net.ParseIP(input); ip == nil || !strings.Contains(input, ":") {
+		return errors.New("invalid IPv6 address")
+	}
+	return nil
+}
+
+// CIDRValidator validates a network in CIDR notation.
+func CIDRValidator(input string) error {
+	if _, _, err := net.ParseCIDR(input); err != nil {
+		return errors.New("invalid network, expected format address/prefix")
+	}
+	return nil
+}
+
+// MACValidator validates a hardware address.
+func MACValidator(input string) error {
+	if _, err := net.ParseMAC(input); err != nil {
+		return errors.New(
+			"invalid MAC address, expected format xx:xx:xx:xx:xx:xx")
+	}
+	return nil
+}
+
+// URLValidator validates an absolute URL with scheme and host.
+func URLValidator(input string) error {
+	u, err := url.Parse(input)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return errors.New("invalid URL, expected format scheme://host[/path]")
+	}
+	return nil
+}
+
+// PortValidator validates a network port number.
+func PortValidator(input string) error {
+	if p, err := strconv.Atoi(input); err != nil || p < 1 || p > 65535 {
+		return errors.New("invalid port, expected number between 1 and 65535")
+	}
+	return nil
+}
+
+// hostnameLabel matches a single RFC 1123 hostname label.
+var hostnameLabel = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+// HostnameValidator validates a hostname according to RFC 1123.
+func HostnameValidator(input string) error {
+	name := strings.TrimSuffix(input, ".")
+	if name == "" || len(name) > 253 {
+		return errors.New("invalid hostname length")
+	}
+	for _, label := range strings.Split(name, ".") {
+		if !hostnameLabel.MatchString(label) {
+			return fmt.Errorf("invalid hostname label '%s'", label)
+		}
+	}
+	return nil
+}
+
+// DurationValidator validates a time duration.
+func DurationValidator(input string) error {
+	if _, err := parsex.ParseDuration(input); err != nil {
+		return errors.New("invalid duration, expected format like 30s, 5m, 1h30m or 2d")
+	}
+	return nil
+}
+
+// SizeValidator validates a size in bytes.
+func SizeValidator(input string) error {
+	if _, err := parsex.ParseSize(input); err != nil {
+		return errors.New("invalid size, expected format like 512, 10MB or 1.5GiB")
+	}
+	return nil
+}
+
+// BoolValidator validates a boolean value.
+func BoolValidator(input string) error {
+	if _, err := parsex.ParseBool(input); err != nil {
+		return errors.New("invalid value, expected yes/no, on/off or true/false")
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package cipherx
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrEmptyData indicates empty input data.
+	ErrEmptyData = errors.New("input data cannot be empty")
+	// ErrShortData indicates input data shorter than the cipher overhead.
+	ErrShortData = errors.New("input data is too short")
+	// ErrPadding indicates invalid padding of decrypted data.
+	ErrPadding = errors.New("invalid padding")
+	// ErrDecrypt indicates a decryption or authentication failure.
+	ErrDecrypt = errors.New("decryption failed")
+)
+
+// RandomBytes returns n random bytes from the system secure source.
+func RandomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// NewGCM creates an AES-GCM cipher for key, which must be 16, 24 or 32
+// bytes long to select AES-128, AES-192 or AES-256.
+func NewGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SealGCM encrypts and authenticates data with aead and the additional
+// data aad. A random nonce is generated and prepended to the output.
+func SealGCM(aead cipher.AEAD, data, aad []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptyData
+	}
+	nonce, err := RandomBytes(aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, data, aad), nil
+}
+
+// OpenGCM authenticates and decrypts data sealed with [SealGCM].
+func OpenGCM(aead cipher.AEAD, data, aad []byte) ([]byte, error) {
+	nonceSize := aead.NonceSize()
+	if len(data) <= nonceSize {
+		return nil, ErrShortData
+	}
+	return aead.Open(nil, data[:nonceSize], data[nonceSize:], aad)
+}
+
+// EncryptGCM encrypts data with AES-GCM using key and additional data aad.
+func EncryptGCM(key, data, aad []byte) ([]byte, error) {
+	aead, err := NewGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return SealGCM(aead, data, aad)
+}
+
+// DecryptGCM decrypts data encrypted with [EncryptGCM].
+func DecryptGCM(key, data, aad []byte) ([]byte, error) {
+	aead, err := NewGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return OpenGCM(aead, data, aad)
+}
+
+// EncryptCBC encrypts data with AES-CBC and PKCS#7 padding using key.
+// A random IV is generated and prepended to the output.
+// CBC mode is not authenticated, it is provided for interoperability and
+// should be combined with [HMAC], prefer GCM otherwise.
+func EncryptCBC(key, data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptyData
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	n := aes.BlockSize - len(data)%aes.BlockSize
+	padded := append(append([]byte{}, data...), bytes.Repeat([]byte{byte(n)}, n)...)
+
+	out := make([]byte, aes.BlockSize+len(padded))
+	if _, err := io.ReadFull(rand.Reader, out[:aes.BlockSize]); err != nil {
+		return nil, err
+	}
+	cipher.NewCBCEncrypter(block, out[:aes.BlockSize]).
+		CryptBlocks(out[aes.BlockSize:], padded)
+	return out, nil
+}
+
+// DecryptCBC decrypts data encrypted with [EncryptCBC].
+func DecryptCBC(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 2*aes.BlockSize {
+		return nil, ErrShortData
+	}
+	if len(data)%aes.BlockSize != 0 {
+		return nil, ErrDecrypt
+	}
+
+	out := make([]byte, len(data)-aes.BlockSize)
+	cipher.NewCBCDecrypter(block, data[:aes.BlockSize]).
+		CryptBlocks(out, data[aes.BlockSize:])
+
+	n := int(out[len(out)-1])
+	if n == 0 || n > aes.BlockSize {
+		return nil, ErrPadding
+	}
+	for _, b := range out[len(out)-n:] {
+		if int(b) != n {
+			return nil, ErrPadding
+		}
+	}
+	return out[:len(out)-n], nil
+}
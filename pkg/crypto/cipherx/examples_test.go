@@ -0,0 +1,43 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package cipherx_test
+
+import (
+	"fmt"
+
+	"github.com/exonlabs/go-utils/pkg/crypto/cipherx"
+)
+
+func ExampleEncrypt() {
+	secret := []byte("my secret")
+
+	b, err := cipherx.Encrypt(secret, []byte("sensitive data"))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	p, err := cipherx.Decrypt(secret, b)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(string(p))
+
+	// Output:
+	// sensitive data
+}
+
+func ExampleVerifyHMAC() {
+	key := []byte("shared key")
+	msg := []byte("payload")
+	mac := cipherx.HMAC(key, msg)
+
+	fmt.Println(cipherx.VerifyHMAC(key, msg, mac))
+	fmt.Println(cipherx.VerifyHMAC(key, []byte("changed"), mac))
+
+	// Output:
+	// true
+	// false
+}
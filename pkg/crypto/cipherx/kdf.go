@@ -0,0 +1,149 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package cipherx
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// KDF_TIME defines the default Argon2id number of passes.
+	KDF_TIME = 3
+	// KDF_MEMORY defines the default Argon2id memory size in KiB.
+	KDF_MEMORY = 64 * 1024
+	// KDF_THREADS defines the default Argon2id parallelism.
+	KDF_THREADS = 4
+
+	// KDF_MAX_TIME defines the max supported Argon2id passes.
+	KDF_MAX_TIME = 64
+	// KDF_MAX_MEMORY defines the max supported Argon2id memory size in KiB.
+	KDF_MAX_MEMORY = 1024 * 1024
+
+	// SALT_SIZE defines the size of random salts used by [Encrypt].
+	SALT_SIZE = 16
+	// KEY_SIZE defines the size of derived keys used by [Encrypt],
+	// selecting AES-256.
+	KEY_SIZE = 32
+
+	// formatV1 defines the [Encrypt] output format version:
+	// version (1 byte) | kdf params (9 bytes) | salt | nonce | ciphertext and tag
+	formatV1 = 1
+	// kdfParamsSize defines the encoded kdf params size:
+	// time (4 bytes) | memory (4 bytes) | threads (1 byte)
+	kdfParamsSize = 9
+)
+
+// KDFParams defines the Argon2id key derivation cost parameters.
+type KDFParams struct {
+	// Time defines the number of passes over the memory.
+	Time uint32
+	// Memory defines the memory size in KiB.
+	Memory uint32
+	// Threads defines the number of parallel lanes.
+	Threads uint8
+}
+
+// DefaultKDFParams returns the default key derivation parameters,
+// following the RFC 9106 recommendation for memory constrained
+// environments.
+func DefaultKDFParams() KDFParams {
+	return KDFParams{Time: KDF_TIME, Memory: KDF_MEMORY, Threads: KDF_THREADS}
+}
+
+// validate checks the parameters are within the supported limits.
+func (p KDFParams) validate() error {
+	if p.Time < 1 || p.Time > KDF_MAX_TIME || p.Threads < 1 ||
+		p.Memory < 8*uint32(p.Threads) || p.Memory > KDF_MAX_MEMORY {
+		return fmt.Errorf("invalid kdf params: time=%d memory=%d threads=%d",
+			p.Time, p.Memory, p.Threads)
+	}
+	return nil
+}
+
+// exceeds checks if any of the parameters exceeds the max parameters.
+func (p KDFParams) exceeds(max KDFParams) bool {
+	return p.Time > max.Time || p.Memory > max.Memory || p.Threads > max.Threads
+}
+
+// DeriveKey derives a key of keyLen bytes from secret and salt using
+// Argon2id (RFC 9106) with the p cost parameters.
+func DeriveKey(secret, salt []byte, keyLen int, p KDFParams) []byte {
+	return argon2.IDKey(secret, salt, p.Time, p.Memory, p.Threads,
+		uint32(keyLen))
+}
+
+// Encrypt encrypts data with AES-256-GCM using a key derived from secret
+// with a random salt and the default key derivation parameters. The output
+// embeds the format version, derivation parameters and salt, so it is
+// decrypted with [Decrypt] using the secret only.
+func Encrypt(secret, data []byte) ([]byte, error) {
+	return EncryptWithParams(secret, data, DefaultKDFParams())
+}
+
+// EncryptWithParams encrypts data like [Encrypt], deriving the key with
+// the p cost parameters.
+func EncryptWithParams(secret, data []byte, p KDFParams) ([]byte, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	salt, err := RandomBytes(SALT_SIZE)
+	if err != nil {
+		return nil, err
+	}
+	b, err := EncryptGCM(DeriveKey(secret, salt, KEY_SIZE, p), data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]byte, 1+kdfParamsSize, 1+kdfParamsSize+SALT_SIZE+len(b))
+	res[0] = formatV1
+	binary.BigEndian.PutUint32(res[1:5], p.Time)
+	binary.BigEndian.PutUint32(res[5:9], p.Memory)
+	res[9] = p.Threads
+	return append(append(res, salt...), b...), nil
+}
+
+// Decrypt decrypts data encrypted with [Encrypt] using secret. The key
+// derivation parameters embedded in data are accepted up to the default
+// parameters, so tampered data can't force costly key derivation. Data
+// encrypted with higher parameters is decrypted with [DecryptWithLimits].
+func Decrypt(secret, data []byte) ([]byte, error) {
+	return DecryptWithLimits(secret, data, DefaultKDFParams())
+}
+
+// DecryptWithLimits decrypts data like [Decrypt], accepting the key
+// derivation parameters embedded in data up to the max parameters.
+func DecryptWithLimits(secret, data []byte, max KDFParams) ([]byte, error) {
+	if len(data) < 1+kdfParamsSize+SALT_SIZE {
+		return nil, ErrShortData
+	}
+	if data[0] != formatV1 {
+		return nil, fmt.Errorf("%w, unsupported format version %d",
+			ErrDecrypt, data[0])
+	}
+	p := KDFParams{
+		Time:    binary.BigEndian.Uint32(data[1:5]),
+		Memory:  binary.BigEndian.Uint32(data[5:9]),
+		Threads: data[9],
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("%w, %v", ErrDecrypt, err)
+	}
+	if p.exceeds(max) {
+		return nil, fmt.Errorf(
+			"%w, kdf params exceed limits: time=%d memory=%d threads=%d",
+			ErrDecrypt, p.Time, p.Memory, p.Threads)
+	}
+	data = data[1+kdfParamsSize:]
+	salt, data := data[:SALT_SIZE], data[SALT_SIZE:]
+	b, err := DecryptGCM(DeriveKey(secret, salt, KEY_SIZE, p), data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w, %v", ErrDecrypt, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package cipherx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+)
+
+// HMAC returns the HMAC-SHA256 of data using key.
+func HMAC(key, data []byte) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write(data)
+	return m.Sum(nil)
+}
+
+// VerifyHMAC checks in constant time that mac is the HMAC-SHA256 of data
+// using key.
+func VerifyHMAC(key, data, mac []byte) bool {
+	return hmac.Equal(HMAC(key, data), mac)
+}
+
+// Equal compares a and b in constant time, for comparing secrets such as
+// tokens and digests without leaking timing information.
+func Equal(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
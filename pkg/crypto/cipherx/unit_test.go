@@ -0,0 +1,172 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package cipherx_test
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/crypto/cipherx"
+)
+
+func TestGCM(t *testing.T) {
+	for _, size := range []int{16, 24, 32} {
+		key, err := cipherx.RandomBytes(size)
+		require.NoError(t, err)
+
+		b, err := cipherx.EncryptGCM(key, []byte("secret data"), []byte("aad"))
+		require.NoError(t, err)
+		p, err := cipherx.DecryptGCM(key, b, []byte("aad"))
+		require.NoError(t, err)
+		assert.Equal(t, "secret data", string(p))
+
+		// wrong additional data or tampered data fail authentication
+		_, err = cipherx.DecryptGCM(key, b, []byte("other"))
+		assert.Error(t, err)
+		b[len(b)-1] ^= 1
+		_, err = cipherx.DecryptGCM(key, b, []byte("aad"))
+		assert.Error(t, err)
+	}
+
+	key := make([]byte, 32)
+	_, err := cipherx.EncryptGCM(key, nil, nil)
+	assert.ErrorIs(t, err, cipherx.ErrEmptyData)
+	_, err = cipherx.DecryptGCM(key, []byte("short"), nil)
+	assert.ErrorIs(t, err, cipherx.ErrShortData)
+	_, err = cipherx.EncryptGCM(key[:10], []byte("x"), nil)
+	assert.Error(t, err, "invalid key size")
+}
+
+func TestCBC(t *testing.T) {
+	key, err := cipherx.RandomBytes(32)
+	require.NoError(t, err)
+
+	for _, s := range []string{"a", "exactly 16 bytes", "more than one block of data"} {
+		b, err := cipherx.EncryptCBC(key, []byte(s))
+		require.NoError(t, err)
+		assert.Zero(t, len(b)%16)
+		p, err := cipherx.DecryptCBC(key, b)
+		require.NoError(t, err)
+		assert.Equal(t, s, string(p))
+	}
+
+	_, err = cipherx.EncryptCBC(key, nil)
+	assert.ErrorIs(t, err, cipherx.ErrEmptyData)
+	_, err = cipherx.DecryptCBC(key, make([]byte, 16))
+	assert.ErrorIs(t, err, cipherx.ErrShortData)
+	_, err = cipherx.DecryptCBC(key, make([]byte, 33))
+	assert.ErrorIs(t, err, cipherx.ErrDecrypt)
+
+	// wrong key fails padding check in most cases
+	b, _ := cipherx.EncryptCBC(key, []byte("data"))
+	other, _ := cipherx.RandomBytes(32)
+	if p, err := cipherx.DecryptCBC(other, b); err == nil {
+		assert.NotEqual(t, "data", string(p))
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	// Argon2id known answers for password "password" and salt "somesalt"
+	for _, tc := range []struct {
+		params cipherx.KDFParams
+		want   string
+	}{
+		// phc-winner-argon2 reference implementation
+		{cipherx.KDFParams{Time: 2, Memory: 1 << 16, Threads: 1},
+			"09316115d5cf24ed5a15a31a3ba326e5cf32edc24702987c02b6566f61913cf7"},
+		{cipherx.KDFParams{Time: 1, Memory: 64, Threads: 1},
+			"655ad15eac652dc59f7170a7332bf49b8469be1fdb9c28bb"},
+		{cipherx.KDFParams{Time: 2, Memory: 64, Threads: 2},
+			"350ac37222f436ccb5c0972f1ebd3bf6b958bf2071841362"},
+		{cipherx.KDFParams{Time: 3, Memory: 256, Threads: 2},
+			"4668d30ac4187e6878eedeacf0fd83c5a0a30db2cc16ef0b"},
+	} {
+		want, _ := hex.DecodeString(tc.want)
+		dk := cipherx.DeriveKey(
+			[]byte("password"), []byte("somesalt"), len(want), tc.params)
+		assert.Equal(t, tc.want, hex.EncodeToString(dk))
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	b1, err := cipherx.Encrypt([]byte("pass"), []byte("firmware"))
+	require.NoError(t, err)
+	b2, err := cipherx.Encrypt([]byte("pass"), []byte("firmware"))
+	require.NoError(t, err)
+	assert.NotEqual(t, b1, b2, "random salt and nonce")
+
+	p, err := cipherx.Decrypt([]byte("pass"), b1)
+	require.NoError(t, err)
+	assert.Equal(t, "firmware", string(p))
+
+	_, err = cipherx.Decrypt([]byte("wrong"), b1)
+	assert.ErrorIs(t, err, cipherx.ErrDecrypt)
+	_, err = cipherx.Decrypt([]byte("pass"), b1[:10])
+	assert.ErrorIs(t, err, cipherx.ErrShortData)
+	b1[0] = 9
+	_, err = cipherx.Decrypt([]byte("pass"), b1)
+	assert.ErrorIs(t, err, cipherx.ErrDecrypt)
+
+	// custom cost parameters are embedded in output
+	params := cipherx.KDFParams{Time: 1, Memory: 1024, Threads: 2}
+	b, err := cipherx.EncryptWithParams([]byte("pass"), []byte("data"), params)
+	require.NoError(t, err)
+	p, err = cipherx.Decrypt([]byte("pass"), b)
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(p))
+
+	// excessive cost parameters are rejected
+	_, err = cipherx.EncryptWithParams([]byte("pass"), []byte("data"),
+		cipherx.KDFParams{Time: 1, Memory: 1024})
+	assert.Error(t, err)
+	b[5] = 0xff
+	_, err = cipherx.Decrypt([]byte("pass"), b)
+	assert.ErrorIs(t, err, cipherx.ErrDecrypt)
+
+	// embedded parameters above the limits are rejected before derivation
+	params = cipherx.KDFParams{Time: 4, Memory: 1024, Threads: 1}
+	b, err = cipherx.EncryptWithParams([]byte("pass"), []byte("data"), params)
+	require.NoError(t, err)
+	_, err = cipherx.Decrypt([]byte("pass"), b)
+	assert.ErrorIs(t, err, cipherx.ErrDecrypt)
+	assert.ErrorContains(t, err, "exceed limits")
+	_, err = cipherx.DecryptWithLimits([]byte("pass"), b,
+		cipherx.KDFParams{Time: 3, Memory: 1 << 20, Threads: 4})
+	assert.ErrorContains(t, err, "exceed limits")
+	p, err = cipherx.DecryptWithLimits([]byte("pass"), b, params)
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(p))
+
+	// tampered max memory parameter is rejected
+	b, err = cipherx.Encrypt([]byte("pass"), []byte("data"))
+	require.NoError(t, err)
+	binary.BigEndian.PutUint32(b[5:9], cipherx.KDF_MAX_MEMORY)
+	_, err = cipherx.Decrypt([]byte("pass"), b)
+	assert.ErrorContains(t, err, "exceed limits")
+}
+
+func TestHMAC(t *testing.T) {
+	// RFC 4231 test case 2
+	mac := cipherx.HMAC([]byte("Jefe"), []byte("what do ya want for nothing?"))
+	assert.Equal(t,
+		"5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		hex.EncodeToString(mac))
+
+	assert.True(t, cipherx.VerifyHMAC(
+		[]byte("Jefe"), []byte("what do ya want for nothing?"), mac))
+	assert.False(t, cipherx.VerifyHMAC(
+		[]byte("Jefe"), []byte("tampered"), mac))
+}
+
+func TestEqual(t *testing.T) {
+	assert.True(t, cipherx.Equal([]byte("token"), []byte("token")))
+	assert.False(t, cipherx.Equal([]byte("token"), []byte("tokem")))
+	assert.False(t, cipherx.Equal([]byte("token"), []byte("tok")))
+	assert.True(t, cipherx.Equal(nil, []byte{}))
+}
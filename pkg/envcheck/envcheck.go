@@ -0,0 +1,329 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package envcheck
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+)
+
+// Path types used in path specs.
+const (
+	PATH_ANY  = ""
+	PATH_FILE = "file"
+	PATH_DIR  = "dir"
+)
+
+var (
+	// ErrCheck indicates that one or more environment checks failed.
+	ErrCheck = errors.New("environment check failed")
+	// ErrConfig indicates invalid manifest configuration.
+	ErrConfig = errors.New("invalid envcheck manifest")
+)
+
+// PathSpec defines the requirements of a file system path.
+type PathSpec struct {
+	// Path defines the file system path.
+	Path string
+	// Type defines the path type {file|dir}, empty for any type.
+	Type string
+	// Mode defines the max allowed permission bits, the path fails the
+	// check if it has any permission bit not included in Mode.
+	// use 0 to skip permissions check.
+	Mode os.FileMode
+	// Writable defines whether the path must be writable by process.
+	Writable bool
+}
+
+// Manifest defines the declarative requirements of the process environment.
+type Manifest struct {
+	// Paths defines the required file system paths.
+	Paths []PathSpec
+	// Env defines the required environment variables.
+	Env []string
+	// Ulimits defines the min soft resource limits by name
+	// {nofile|core|cpu|data|fsize|stack|as}.
+	Ulimits map[string]uint64
+	// MinTime defines the min valid system time, used to detect
+	// unset clocks on systems without RTC. zero value skips the check.
+	MinTime time.Time
+	// MaxTime defines the max valid system time. zero value skips the check.
+	MaxTime time.Time
+}
+
+// Result represents the result of a single check.
+type Result struct {
+	// Check defines the check type {path|env|ulimit|clock}.
+	Check string
+	// Target defines the checked item.
+	Target string
+	// Err holds the check failure, nil if passed.
+	Err error
+}
+
+// Report represents the results of environment checks.
+type Report struct {
+	Results []Result
+}
+
+// Load creates a new manifest from config dict.
+// The parsed config keys are:
+//   - paths: (list) the required paths, each path has keys:
+//     path (string), type (string) {file|dir}, mode (string) octal
+//     permission bits and writable (bool).
+//   - env: (list) the required environment variables.
+//   - ulimits: (dict) the min soft resource limits by name.
+//   - min_time: (string) the min valid system time in RFC3339 format.
+//   - max_time: (string) the max valid system time in RFC3339 format.
+//
+// example:
+//
+//	{
+//	  "paths": [
+//	    {"path": "/var/lib/app", "type": "dir", "writable": true},
+//	    {"path": "/etc/app/secret.key", "type": "file", "mode": "0600"}
+//	  ],
+//	  "env": ["APP_HOME"],
+//	  "ulimits": {"nofile": 4096},
+//	  "min_time": "2024-01-01T00:00:00Z"
+//	}
+func Load(cfg dictx.Dict) (*Manifest, error) {
+	m := &Manifest{Ulimits: map[string]uint64{}}
+
+	paths, ok := dictx.Get(cfg, "paths", []any{}).([]any)
+	if !ok {
+		return nil, fmt.Errorf("%w - paths", ErrConfig)
+	}
+	for _, v := range paths {
+		d, ok := v.(dictx.Dict)
+		if !ok {
+			return nil, fmt.Errorf("%w - paths", ErrConfig)
+		}
+		p := PathSpec{
+			Path:     dictx.GetString(d, "path", ""),
+			Type:     strings.ToLower(dictx.GetString(d, "type", PATH_ANY)),
+			Writable: dictx.Fetch(d, "writable", false),
+		}
+		if p.Path == "" {
+			return nil, fmt.Errorf("%w - empty path", ErrConfig)
+		}
+		if p.Type != PATH_ANY && p.Type != PATH_FILE && p.Type != PATH_DIR {
+			return nil, fmt.Errorf("%w - path type: %s", ErrConfig, p.Type)
+		}
+		if s := dictx.GetString(d, "mode", ""); s != "" {
+			mode, err := strconv.ParseUint(s, 8, 32)
+			if err != nil {
+				return nil, fmt.Errorf("%w - path mode: %s", ErrConfig, s)
+			}
+			p.Mode = os.FileMode(mode) & os.ModePerm
+		}
+		m.Paths = append(m.Paths, p)
+	}
+
+	switch v := dictx.Get(cfg, "env", nil).(type) {
+	case nil:
+	case []any:
+		for _, s := range v {
+			str, ok := s.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w - env", ErrConfig)
+			}
+			m.Env = append(m.Env, str)
+		}
+	default:
+		return nil, fmt.Errorf("%w - env", ErrConfig)
+	}
+
+	ulimits, ok := dictx.Get(cfg, "ulimits", dictx.Dict{}).(dictx.Dict)
+	if !ok {
+		return nil, fmt.Errorf("%w - ulimits", ErrConfig)
+	}
+	for name := range ulimits {
+		v := dictx.GetFloat(ulimits, name, -1)
+		if v < 0 {
+			return nil, fmt.Errorf("%w - ulimit: %s", ErrConfig, name)
+		}
+		m.Ulimits[strings.ToLower(name)] = uint64(v)
+	}
+
+	for key, t := range map[string]*time.Time{
+		"min_time": &m.MinTime, "max_time": &m.MaxTime} {
+		if s := dictx.GetString(cfg, key, ""); s != "" {
+			v, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return nil, fmt.Errorf("%w - %s: %s", ErrConfig, key, s)
+			}
+			*t = v
+		}
+	}
+
+	return m, nil
+}
+
+// Run runs all the manifest checks and returns the checks report.
+func (m *Manifest) Run() *Report {
+	r := &Report{}
+
+	for _, p := range m.Paths {
+		r.add("path", p.Path, checkPath(p))
+	}
+
+	for _, name := range m.Env {
+		var err error
+		if _, ok := os.LookupEnv(name); !ok {
+			err = errors.New("not set")
+		}
+		r.add("env", name, err)
+	}
+
+	names := make([]string, 0, len(m.Ulimits))
+	for name := range m.Ulimits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var err error
+		cur, e := ulimit(name)
+		if e != nil {
+			err = e
+		} else if cur < m.Ulimits[name] {
+			err = fmt.Errorf("soft limit %d below required %d",
+				cur, m.Ulimits[name])
+		}
+		r.add("ulimit", name, err)
+	}
+
+	if !m.MinTime.IsZero() || !m.MaxTime.IsZero() {
+		var err error
+		now := time.Now()
+		if !m.MinTime.IsZero() && now.Before(m.MinTime) {
+			err = fmt.Errorf("system time %s before %s",
+				now.Format(time.RFC3339), m.MinTime.Format(time.RFC3339))
+		} else if !m.MaxTime.IsZero() && now.After(m.MaxTime) {
+			err = fmt.Errorf("system time %s after %s",
+				now.Format(time.RFC3339), m.MaxTime.Format(time.RFC3339))
+		}
+		r.add("clock", "system time", err)
+	}
+
+	return r
+}
+
+// Check loads the manifest from config dict and runs the checks,
+// returning error with the failures report if any check failed.
+func Check(cfg dictx.Dict) error {
+	m, err := Load(cfg)
+	if err != nil {
+		return err
+	}
+	return m.Run().Err()
+}
+
+// checkPath checks a path against its spec.
+func checkPath(p PathSpec) error {
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.New("not found")
+		}
+		return err
+	}
+
+	switch {
+	case p.Type == PATH_DIR && !info.IsDir():
+		return errors.New("not a directory")
+	case p.Type == PATH_FILE && !info.Mode().IsRegular():
+		return errors.New("not a regular file")
+	}
+
+	if p.Mode != 0 {
+		if extra := info.Mode().Perm() &^ p.Mode; extra != 0 {
+			return fmt.Errorf("permissions %04o exceed %04o",
+				info.Mode().Perm(), p.Mode)
+		}
+	}
+
+	if p.Writable {
+		if info.IsDir() {
+			f, err := os.CreateTemp(p.Path, ".envcheck-*")
+			if err != nil {
+				return errors.New("directory not writable")
+			}
+			f.Close()
+			os.Remove(f.Name())
+		} else {
+			f, err := os.OpenFile(p.Path, os.O_WRONLY|os.O_APPEND, 0)
+			if err != nil {
+				return errors.New("file not writable")
+			}
+			f.Close()
+		}
+	}
+
+	return nil
+}
+
+// add appends a check result to report.
+func (r *Report) add(check, target string, err error) {
+	r.Results = append(r.Results, Result{
+		Check:  check,
+		Target: target,
+		Err:    err,
+	})
+}
+
+// Ok checks if all the checks passed.
+func (r *Report) Ok() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed returns the failed checks results.
+func (r *Report) Failed() []Result {
+	res := []Result{}
+	for _, v := range r.Results {
+		if v.Err != nil {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// String returns the checks report, one line per check.
+//
+//	[ OK ] path /var/lib/app
+//	[FAIL] ulimit nofile: soft limit 1024 below required 4096
+func (r *Report) String() string {
+	lines := make([]string, 0, len(r.Results))
+	for _, v := range r.Results {
+		if v.Err != nil {
+			lines = append(lines,
+				fmt.Sprintf("[FAIL] %s %s: %v", v.Check, v.Target, v.Err))
+		} else {
+			lines = append(lines, fmt.Sprintf("[ OK ] %s %s", v.Check, v.Target))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Err returns error holding the failed checks report if any check failed.
+func (r *Report) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	lines := make([]string, 0, len(failed))
+	for _, v := range failed {
+		lines = append(lines, fmt.Sprintf("  %s %s: %v", v.Check, v.Target, v.Err))
+	}
+	return fmt.Errorf("%w - %d failed:\n%s",
+		ErrCheck, len(failed), strings.Join(lines, "\n"))
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package envcheck
+
+import (
+	"errors"
+	"syscall"
+)
+
+// rlimits defines the supported resource limits by name.
+var rlimits = map[string]int{
+	"nofile": syscall.RLIMIT_NOFILE,
+	"core":   syscall.RLIMIT_CORE,
+	"cpu":    syscall.RLIMIT_CPU,
+	"data":   syscall.RLIMIT_DATA,
+	"fsize":  syscall.RLIMIT_FSIZE,
+	"stack":  syscall.RLIMIT_STACK,
+	"as":     syscall.RLIMIT_AS,
+}
+
+// ulimit returns the current soft resource limit by name.
+func ulimit(name string) (uint64, error) {
+	res, ok := rlimits[name]
+	if !ok {
+		return 0, errors.New("unknown limit")
+	}
+	var rl syscall.Rlimit
+	if err := syscall.Getrlimit(res, &rl); err != nil {
+		return 0, err
+	}
+	return uint64(rl.Cur), nil
+}
@@ -0,0 +1,16 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build windows
+
+package envcheck
+
+import (
+	"errors"
+)
+
+// ulimit returns the current soft resource limit by name.
+func ulimit(name string) (uint64, error) {
+	return 0, errors.New("resource limits not supported")
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package envcheck_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/envcheck"
+)
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "secret.key")
+	require.NoError(t, os.WriteFile(file, []byte("key"), 0600))
+	t.Setenv("ENVCHECK_TEST", "1")
+
+	cfg := dictx.Dict{
+		"paths": []any{
+			dictx.Dict{"path": dir, "type": "dir", "writable": true},
+			dictx.Dict{"path": file, "type": "file", "mode": "0644"},
+		},
+		"env":      []any{"ENVCHECK_TEST"},
+		"min_time": "2020-01-01T00:00:00Z",
+	}
+	if runtime.GOOS != "windows" {
+		cfg["ulimits"] = dictx.Dict{"nofile": 1}
+	}
+	m, err := envcheck.Load(cfg)
+	require.NoError(t, err)
+	r := m.Run()
+	assert.True(t, r.Ok(), r.String())
+	assert.NoError(t, r.Err())
+	assert.NoError(t, envcheck.Check(cfg))
+
+	// failed checks
+	m = &envcheck.Manifest{
+		Paths: []envcheck.PathSpec{
+			{Path: filepath.Join(dir, "missing")},
+			{Path: file, Type: envcheck.PATH_DIR},
+		},
+		Env: []string{"ENVCHECK_MISSING"},
+	}
+	if runtime.GOOS != "windows" {
+		require.NoError(t, os.Chmod(file, 0644))
+		m.Paths = append(m.Paths, envcheck.PathSpec{Path: file, Mode: 0600})
+	}
+	r = m.Run()
+	assert.False(t, r.Ok())
+	assert.Len(t, r.Failed(), len(r.Results))
+	assert.Contains(t, r.String(), "[FAIL] path")
+	err = r.Err()
+	assert.ErrorIs(t, err, envcheck.ErrCheck)
+	assert.Contains(t, err.Error(), "ENVCHECK_MISSING: not set")
+}
+
+func TestLoad(t *testing.T) {
+	for _, cfg := range []dictx.Dict{
+		{"paths": []any{dictx.Dict{"type": "dir"}}},
+		{"paths": []any{dictx.Dict{"path": "/tmp", "type": "link"}}},
+		{"paths": []any{dictx.Dict{"path": "/tmp", "mode": "rwx"}}},
+		{"env": "HOME"},
+		{"ulimits": dictx.Dict{"nofile": "many"}},
+		{"min_time": "yesterday"},
+	} {
+		_, err := envcheck.Load(cfg)
+		assert.ErrorIs(t, err, envcheck.ErrConfig, cfg)
+	}
+}
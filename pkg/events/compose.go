@@ -0,0 +1,84 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package events
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// done returns the channel closed when the event is set.
+func (e *Event) done() <-chan struct{} {
+	e.opMutex.Lock()
+	defer e.opMutex.Unlock()
+
+	return e.waitCh
+}
+
+// WaitAny blocks until any of the events is set or timeout elapses.
+// It returns the index of the first set event, or -1 on timeout.
+func WaitAny(timeout float64, evts ...*Event) int {
+	for i, e := range evts {
+		if e.state.Load() {
+			return i
+		}
+	}
+
+	timer := time.NewTimer(time.Duration(timeout * float64(time.Second)))
+	defer timer.Stop()
+
+	cases := make([]reflect.SelectCase, len(evts)+1)
+	for i, e := range evts {
+		cases[i] = reflect.SelectCase{
+			Dir: reflect.SelectRecv, Chan: reflect.ValueOf(e.done())}
+	}
+	cases[len(evts)] = reflect.SelectCase{
+		Dir: reflect.SelectRecv, Chan: reflect.ValueOf(timer.C)}
+
+	i, _, _ := reflect.Select(cases)
+	if i == len(evts) {
+		return -1 // Timed out.
+	}
+	return i
+}
+
+// WaitAll blocks until all the events are set or timeout elapses.
+// It returns true if all the events were set, or false on timeout.
+// The events are waited in order, where an event observed as set is not
+// checked again, so an event cleared after being observed still counts
+// as set, while an event set and cleared before being observed does not.
+func WaitAll(timeout float64, evts ...*Event) bool {
+	timer := time.NewTimer(time.Duration(timeout * float64(time.Second)))
+	defer timer.Stop()
+
+	for _, e := range evts {
+		if e.state.Load() {
+			continue
+		}
+		select {
+		case <-timer.C:
+			return false // Timed out.
+		case <-e.done():
+		}
+	}
+	return true
+}
+
+// AsContext returns a context derived from parent which is cancelled when
+// the event is set, to bridge events with context based APIs.
+// The returned cancel function releases the context resources.
+func AsContext(parent context.Context, e *Event) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	ch := e.done()
+	go func() {
+		select {
+		case <-ch:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
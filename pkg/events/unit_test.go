@@ -5,6 +5,7 @@
 package events_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -78,3 +79,67 @@ func TestWaitWithTimeout(t *testing.T) {
 	// Test timeout wait
 	assert.True(t, e.Wait(0.01)) // Should timeout since the event is cleared
 }
+
+func TestWaitAny(t *testing.T) {
+	e1, e2 := events.New(), events.New()
+
+	// Test timeout when no event is set
+	assert.Equal(t, -1, events.WaitAny(0.01, e1, e2))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		e2.Set()
+	}()
+	assert.Equal(t, 1, events.WaitAny(1.0, e1, e2))
+
+	// Test immediate return when already set
+	e1.Set()
+	assert.Equal(t, 0, events.WaitAny(1.0, e1, e2))
+}
+
+func TestWaitAll(t *testing.T) {
+	e1, e2 := events.New(), events.New()
+
+	// Test timeout when only one event is set
+	e1.Set()
+	tStart := time.Now()
+	assert.False(t, events.WaitAll(0.05, e1, e2))
+	assert.GreaterOrEqual(t, time.Since(tStart), 50*time.Millisecond)
+	assert.Less(t, time.Since(tStart), time.Second)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		e2.Set()
+	}()
+	assert.True(t, events.WaitAll(1.0, e1, e2))
+
+	// Test immediate return when all set or no events
+	tStart = time.Now()
+	assert.True(t, events.WaitAll(1.0, e1, e2))
+	assert.True(t, events.WaitAll(1.0))
+	assert.Less(t, time.Since(tStart), 100*time.Millisecond)
+
+	// Test timeout when event was cleared before being waited
+	e2.Clear()
+	assert.False(t, events.WaitAll(0.01, e1, e2))
+}
+
+func TestAsContext(t *testing.T) {
+	e := events.New()
+	ctx, cancel := events.AsContext(context.Background(), e)
+	defer cancel()
+	assert.NoError(t, ctx.Err())
+
+	e.Set()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context not cancelled")
+	}
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+
+	// Test cancel releases context without event set
+	ctx, cancel = events.AsContext(context.Background(), events.New())
+	cancel()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
@@ -0,0 +1,313 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package identity
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/jconfig"
+)
+
+// Config keys used to persist the identity attributes.
+const (
+	KEY_ID     = "identity.id"
+	KEY_KEY    = "identity.key"
+	KEY_CERT   = "identity.cert"
+	KEY_CA     = "identity.ca_certs"
+	KEY_TOKENS = "identity.tokens"
+)
+
+// Identity represents the machine identity, with a unique ID and keypair
+// generated on first boot and persisted in secure configuration.
+type Identity struct {
+	// cfg defines the secure configuration storing identity attributes.
+	cfg *jconfig.Config
+
+	// id defines the machine unique ID.
+	id string
+	// key defines the machine private key.
+	key *ecdsa.PrivateKey
+}
+
+// Load loads the machine identity from secure configuration, generating
+// and saving a new identity on first boot. The configuration ciphering must
+// be initialized to protect the private key and tokens.
+func Load(cfg *jconfig.Config) (*Identity, error) {
+	if cfg == nil {
+		return nil, errors.New("config cannot be empty")
+	}
+
+	i := &Identity{cfg: cfg}
+	i.id = dictx.Fetch(cfg.Buffer, KEY_ID, "")
+
+	val, err := cfg.GetSecure(KEY_KEY, "")
+	if err != nil {
+		return nil, err
+	}
+	if keyPem, _ := val.(string); i.id != "" && keyPem != "" {
+		i.key, err = parseKey(keyPem)
+		if err != nil {
+			return nil, err
+		}
+		return i, nil
+	}
+
+	// generate new identity
+	if err := i.generate(); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// generate creates and saves a new machine ID and keypair.
+func (i *Identity) generate() error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	keyPem, err := encodeKey(key)
+	if err != nil {
+		return err
+	}
+
+	i.id = hex.EncodeToString(b)
+	i.key = key
+	i.cfg.Set(KEY_ID, i.id)
+	if err := i.cfg.SetSecure(KEY_KEY, keyPem); err != nil {
+		return err
+	}
+	i.cfg.Delete(KEY_CERT)
+	i.cfg.Delete(KEY_CA)
+	i.cfg.Delete(KEY_TOKENS)
+	return i.cfg.Save()
+}
+
+// encodeKey returns the private key in PEM format.
+func encodeKey(key *ecdsa.PrivateKey) (string, error) {
+	b, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(
+		&pem.Block{Type: "PRIVATE KEY", Bytes: b})), nil
+}
+
+// parseKey loads the private key from PEM format.
+func parseKey(keyPem string) (*ecdsa.PrivateKey, error) {
+	blk, _ := pem.Decode([]byte(keyPem))
+	if blk == nil {
+		return nil, errors.New("invalid identity key format")
+	}
+	k, err := x509.ParsePKCS8PrivateKey(blk.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := k.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid identity key type")
+	}
+	return key, nil
+}
+
+// Id returns the machine unique ID.
+func (i *Identity) Id() string {
+	return i.id
+}
+
+// PublicKey returns the machine public key.
+func (i *Identity) PublicKey() crypto.PublicKey {
+	return i.key.Public()
+}
+
+// Sign signs the data with the machine private key.
+// Returns the ASN.1 encoded ECDSA signature of the data SHA-256 digest.
+func (i *Identity) Sign(data []byte) ([]byte, error) {
+	h := sha256.Sum256(data)
+	return ecdsa.SignASN1(rand.Reader, i.key, h[:])
+}
+
+// Reset discards the current identity and generates a new one.
+func (i *Identity) Reset() error {
+	return i.generate()
+}
+
+/////////////////////////////////////////////////////
+
+// Enrollment represents a signed enrollment request sent to the
+// provisioning service.
+type Enrollment struct {
+	Id        string `json:"id"`        // Machine ID
+	Csr       string `json:"csr"`       // PEM encoded certificate signing request
+	Token     string `json:"token"`     // Provisioning token
+	Timestamp int64  `json:"timestamp"` // Request time in unix seconds
+	Signature string `json:"signature"` // Base64 encoded signature
+}
+
+// SignedData returns the enrollment content covered by signature.
+func (e *Enrollment) SignedData() []byte {
+	return []byte(strings.Join([]string{
+		e.Id, e.Csr, e.Token, fmt.Sprint(e.Timestamp)}, "\n"))
+}
+
+// Verify checks the enrollment signature with the public key of its CSR.
+func (e *Enrollment) Verify() error {
+	blk, _ := pem.Decode([]byte(e.Csr))
+	if blk == nil {
+		return errors.New("invalid csr format")
+	}
+	csr, err := x509.ParseCertificateRequest(blk.Bytes)
+	if err != nil {
+		return err
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return err
+	}
+	pub, ok := csr.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return errors.New("invalid csr key type")
+	}
+	sig, err := base64.StdEncoding.DecodeString(e.Signature)
+	if err != nil {
+		return err
+	}
+	h := sha256.Sum256(e.SignedData())
+	if !ecdsa.VerifyASN1(pub, h[:], sig) {
+		return errors.New("invalid enrollment signature")
+	}
+	return nil
+}
+
+// CSR creates a PEM encoded certificate signing request with the machine
+// ID as common name.
+func (i *Identity) CSR() (string, error) {
+	b, err := x509.CreateCertificateRequest(rand.Reader,
+		&x509.CertificateRequest{
+			Subject: pkix.Name{CommonName: i.id},
+		}, i.key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(
+		&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: b})), nil
+}
+
+// EnrollmentRequest creates a signed enrollment request using the
+// provisioning token issued for the machine.
+func (i *Identity) EnrollmentRequest(token string) (*Enrollment, error) {
+	csr, err := i.CSR()
+	if err != nil {
+		return nil, err
+	}
+	e := &Enrollment{
+		Id:        i.id,
+		Csr:       csr,
+		Token:     token,
+		Timestamp: time.Now().Unix(),
+	}
+	sig, err := i.Sign(e.SignedData())
+	if err != nil {
+		return nil, err
+	}
+	e.Signature = base64.StdEncoding.EncodeToString(sig)
+	return e, nil
+}
+
+/////////////////////////////////////////////////////
+
+// SetCertificate validates and stores the issued PEM certificate and
+// the optional CA certificates chain.
+func (i *Identity) SetCertificate(certPem, caPem string) error {
+	blk, _ := pem.Decode([]byte(certPem))
+	if blk == nil {
+		return errors.New("invalid certificate format")
+	}
+	cert, err := x509.ParseCertificate(blk.Bytes)
+	if err != nil {
+		return err
+	}
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok || !pub.Equal(i.key.Public()) {
+		return errors.New("certificate does not match identity key")
+	}
+
+	i.cfg.Set(KEY_CERT, certPem)
+	if caPem != "" {
+		i.cfg.Set(KEY_CA, caPem)
+	} else {
+		i.cfg.Delete(KEY_CA)
+	}
+	return i.cfg.Save()
+}
+
+// Certificate returns the stored PEM certificate, or empty if not enrolled.
+func (i *Identity) Certificate() string {
+	return dictx.Fetch(i.cfg.Buffer, KEY_CERT, "")
+}
+
+// IsEnrolled checks if a certificate was issued for the identity.
+func (i *Identity) IsEnrolled() bool {
+	return i.Certificate() != ""
+}
+
+// SetToken stores a named issued token in secure configuration.
+func (i *Identity) SetToken(name, token string) error {
+	if name == "" {
+		return errors.New("token name cannot be empty")
+	}
+	if err := i.cfg.SetSecure(KEY_TOKENS+"."+name, token); err != nil {
+		return err
+	}
+	return i.cfg.Save()
+}
+
+// Token returns a named issued token, or empty if not exist.
+func (i *Identity) Token(name string) (string, error) {
+	val, err := i.cfg.GetSecure(KEY_TOKENS+"."+name, "")
+	if err != nil {
+		return "", err
+	}
+	s, _ := val.(string)
+	return s, nil
+}
+
+// TlsOptions returns the comm TLS options using the identity certificate
+// and key, to use for mutual TLS comm connections and listeners.
+func (i *Identity) TlsOptions() (dictx.Dict, error) {
+	if !i.IsEnrolled() {
+		return nil, errors.New("identity is not enrolled")
+	}
+	keyPem, err := encodeKey(i.key)
+	if err != nil {
+		return nil, err
+	}
+	opts := dictx.Dict{
+		"tls_enable":     true,
+		"tls_local_cert": i.Certificate(),
+		"tls_local_key":  keyPem,
+	}
+	if ca := dictx.Fetch(i.cfg.Buffer, KEY_CA, ""); ca != "" {
+		opts["tls_ca_certs"] = ca
+		opts["tls_mutual_auth"] = true
+	}
+	return opts, nil
+}
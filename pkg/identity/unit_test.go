@@ -0,0 +1,106 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package identity_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/comm/netcomm"
+	"github.com/exonlabs/go-utils/pkg/identity"
+	"github.com/exonlabs/go-utils/pkg/jconfig"
+)
+
+func newConfig(t *testing.T, path string) *jconfig.Config {
+	cfg, err := jconfig.New(path, nil)
+	require.NoError(t, err)
+	require.NoError(t, cfg.InitAES256("secret"))
+	require.NoError(t, cfg.Load())
+	return cfg
+}
+
+// issueCert signs the csr with a self signed test CA.
+func issueCert(t *testing.T, csrPem string) string {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	blk, _ := pem.Decode([]byte(csrPem))
+	csr, err := x509.ParseCertificateRequest(blk.Bytes)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      csr.Subject,
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	b, err := x509.CreateCertificate(
+		rand.Reader, tmpl, tmpl, csr.PublicKey, caKey)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(
+		&pem.Block{Type: "CERTIFICATE", Bytes: b}))
+}
+
+func TestLoadIdentity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "identity.json")
+
+	id1, err := identity.Load(newConfig(t, path))
+	require.NoError(t, err)
+	assert.Len(t, id1.Id(), 32)
+	assert.False(t, id1.IsEnrolled())
+
+	// reload persisted identity
+	id2, err := identity.Load(newConfig(t, path))
+	require.NoError(t, err)
+	assert.Equal(t, id1.Id(), id2.Id())
+	assert.True(t, id1.PublicKey().(*ecdsa.PublicKey).Equal(id2.PublicKey()))
+}
+
+func TestEnrollment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "identity.json")
+	id, err := identity.Load(newConfig(t, path))
+	require.NoError(t, err)
+
+	e, err := id.EnrollmentRequest("provisioning-token")
+	require.NoError(t, err)
+	assert.Equal(t, id.Id(), e.Id)
+	assert.NoError(t, e.Verify())
+
+	e.Token = "other-token"
+	assert.Error(t, e.Verify())
+
+	// store issued certificate and tokens
+	require.NoError(t, id.SetCertificate(issueCert(t, e.Csr), ""))
+	require.NoError(t, id.SetToken("api", "token-value"))
+	assert.True(t, id.IsEnrolled())
+
+	id, err = identity.Load(newConfig(t, path))
+	require.NoError(t, err)
+	assert.True(t, id.IsEnrolled())
+	tk, err := id.Token("api")
+	assert.NoError(t, err)
+	assert.Equal(t, "token-value", tk)
+
+	opts, err := id.TlsOptions()
+	require.NoError(t, err)
+	tlsCfg, err := netcomm.GetTlsConfig(opts)
+	assert.NoError(t, err)
+	assert.Len(t, tlsCfg.Certificates, 1)
+
+	// certificate for different key
+	other, err := identity.Load(
+		newConfig(t, filepath.Join(t.TempDir(), "other.json")))
+	require.NoError(t, err)
+	csr, _ := other.CSR()
+	assert.Error(t, id.SetCertificate(issueCert(t, csr), ""))
+}
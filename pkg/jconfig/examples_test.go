@@ -6,6 +6,7 @@ package jconfig_test
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/exonlabs/go-utils/pkg/abc/dictx"
 	"github.com/exonlabs/go-utils/pkg/jconfig"
@@ -35,3 +36,41 @@ func ExampleConfig_GetSecure() {
 
 	// Output: default
 }
+
+func ExampleConfig_Watch() {
+	cfg, _ := jconfig.New("config.json", dictx.Dict{"interval": 10})
+	cfg.Load()
+
+	// reject configurations with invalid values
+	cfg.SetValidator(func(d jconfig.Dict) error {
+		if dictx.GetInt(d, "interval", 0) <= 0 {
+			return fmt.Errorf("interval must be positive")
+		}
+		return nil
+	})
+	cfg.OnChange(func(keys []string) {
+		fmt.Println("changed keys:", keys)
+	})
+	cfg.OnError(func(err error) {
+		fmt.Println("reload failed:", err)
+	})
+
+	// reload the file when it changes on disk
+	cfg.Watch(time.Second)
+	defer cfg.StopWatch()
+}
+
+func ExampleConfig_RegisterMigration() {
+	cfg, _ := jconfig.New("config.json", nil)
+
+	// version 1 moved the server settings into a nested section
+	cfg.RegisterMigration(0, 1, func(d jconfig.Dict) error {
+		jconfig.MoveKey(d, "host", "server.host")
+		jconfig.MoveKey(d, "port", "server.port")
+		return nil
+	})
+
+	// old config files are upgraded and saved on load
+	cfg.Load()
+	fmt.Println(cfg.Version())
+}
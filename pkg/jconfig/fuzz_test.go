@@ -0,0 +1,38 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package jconfig_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/exonlabs/go-utils/pkg/jconfig"
+)
+
+func FuzzLoad(f *testing.F) {
+	for _, s := range []string{
+		`{"a": 1, "b": {"c": [1, 2, "x"]}}`, `{"a.b": null}`, `{}`, `null`,
+		`[]`, `"x"`, `{"a": {"b": {"c": {}}}}`, `{"a":`, "",
+	} {
+		f.Add([]byte(s))
+	}
+	path := filepath.Join(f.TempDir(), "config.json")
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := os.WriteFile(path, data, 0o664); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := jconfig.New(path, jconfig.Dict{"a": 0, "b": jconfig.Dict{}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cfg.Load(); err != nil {
+			return
+		}
+		for _, k := range cfg.Keys() {
+			cfg.Get(k, nil)
+		}
+	})
+}
@@ -11,20 +11,39 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/abc/fsx"
 	"github.com/exonlabs/go-utils/pkg/ciphering"
+	"github.com/exonlabs/go-utils/pkg/secrets"
 )
 
 type Dict = dictx.Dict
 
 // Config represents a configuration manager that handles loading,
 // saving, and backing up configuration data.
+//
+// The Config methods are safe for concurrent use. The Buffer is replaced
+// on reload, so direct access to it must not be mixed with watching.
 type Config struct {
-	Buffer  Dict              // Holds the current configuration in memory
-	cfgPath string            // Path to the main configuration file
-	bakPath string            // Path to the backup configuration file (optional)
-	cipher  ciphering.Handler // Cipher handler for encryption and decryption (optional)
+	Buffer   Dict              // Holds the current configuration in memory
+	cfgPath  string            // Path to the main configuration file
+	bakPath  string            // Path to the backup configuration file (optional)
+	cipher   ciphering.Handler // Cipher handler for encryption and decryption (optional)
+	defaults Dict              // Copy of the defaults used to rebuild the buffer on reload
+	lock     sync.RWMutex      // Guards the buffer and the watch state
+
+	readOnly    bool          // Fails modifications and file writes when set
+	lockTimeout time.Duration // Timeout for the inter-process file lock, 0 disables it
+
+	migrations map[int]migration     // Registered layout migrations by source version
+	validator  func(Dict) error      // Validates reloaded configuration (optional)
+	onChange   []func(keys []string) // Handlers called with changed keys on reload
+	onError    []func(error)         // Handlers called on watch reload errors
+	watch      *watcher              // Active file watcher (optional)
 }
 
 // New creates a new Config instance with the provided file path and default values.
@@ -37,9 +56,14 @@ func New(path string, defaults Dict) (*Config, error) {
 	if defaults == nil {
 		defaults = Dict{}
 	}
+	d, err := dictx.Clone(defaults)
+	if err != nil {
+		return nil, err
+	}
 	return &Config{
-		Buffer:  defaults,
-		cfgPath: path,
+		Buffer:   defaults,
+		cfgPath:  path,
+		defaults: d,
 	}, nil
 }
 
@@ -76,35 +100,60 @@ func (c *Config) IsBackupExist() bool {
 }
 
 // load merges the provided byte slice into the current buffer
-// after unmarshalling it from JSON.
-func (c *Config) load(b []byte) error {
+// after unmarshalling it from JSON and migrating it to the latest version.
+// Returns the original version if the data was migrated, or -1.
+func (c *Config) load(b []byte) (int, error) {
 	if len(b) == 0 {
-		return nil
+		return -1, nil
 	}
 
 	var buffer map[string]any
 	if err := json.Unmarshal(b, &buffer); err != nil {
-		return err
+		return -1, err
+	}
+	if buffer == nil {
+		buffer = Dict{}
+	}
+	from, err := c.migrate(buffer)
+	if err != nil {
+		return -1, err
 	}
 	// Merge the new data into the current buffer
+	c.lock.Lock()
 	dictx.Merge(c.Buffer, buffer)
-	return nil
+	c.lock.Unlock()
+	return from, nil
 }
 
 // Load reads the configuration from the main file and loads it into memory.
 // If the main config fails to load, attempts to load from a backup file.
 // Also saves the loaded data back to the backup if successful.
+// Configurations of older versions are migrated and saved, keeping the
+// pre-migration file with a `.v<version>` suffix.
 func (c *Config) Load() error {
+	fl, err := c.lockFile()
+	if err != nil {
+		return err
+	}
+	defer unlockFile(fl)
+
 	var b []byte
-	var err error
+	var from int
+	readOnly := c.IsReadOnly()
 
 	// Attempt to load the primary configuration file
 	if c.IsExist() {
 		b, err = os.ReadFile(c.cfgPath)
 		if err == nil {
-			if err = c.load(b); err == nil {
+			if from, err = c.load(b); err == nil {
+				if readOnly {
+					return nil
+				}
+				if from >= 0 {
+					return c.saveMigrated(b, from)
+				}
 				if c.bakPath != "" {
-					os.WriteFile(c.bakPath, b, 0o664)
+					fsx.WriteFileAtomic(c.bakPath, b, c.filePerm())
 				}
 				return nil
 			}
@@ -115,8 +164,14 @@ func (c *Config) Load() error {
 	if c.IsBackupExist() {
 		b, err = os.ReadFile(c.bakPath)
 		if err == nil {
-			if err = c.load(b); err == nil {
-				return os.WriteFile(c.cfgPath, b, 0o664)
+			if from, err = c.load(b); err == nil {
+				if readOnly {
+					return nil
+				}
+				if from >= 0 {
+					return c.saveMigrated(b, from)
+				}
+				return fsx.WriteFileAtomic(c.cfgPath, b, c.filePerm())
 			}
 		}
 	}
@@ -124,57 +179,136 @@ func (c *Config) Load() error {
 	return err
 }
 
+// filePerm returns the file mode of new configuration files, which is the
+// mode of the existing main or backup file, so new backups are not more
+// accessible than the configuration. Existing files keep their mode on
+// writes, and the umask applies to new files.
+func (c *Config) filePerm() os.FileMode {
+	for _, path := range []string{c.cfgPath, c.bakPath} {
+		if path == "" {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil {
+			return info.Mode().Perm()
+		}
+	}
+	return 0o664
+}
+
+// saveMigrated keeps the pre-migration file contents and saves the
+// migrated configuration.
+func (c *Config) saveMigrated(b []byte, from int) error {
+	path := fmt.Sprintf("%s.v%d", c.cfgPath, from)
+	if err := fsx.WriteFileAtomic(path, b, c.filePerm()); err != nil {
+		return err
+	}
+	return c.save()
+}
+
 // Save serializes the current buffer to a formatted JSON byte slice,
 // then writes the configuration buffer to both the main file
 // and the backup file (if a backup path is set).
+// Files are replaced atomically, so a crash or power loss during save
+// leaves either the old or the new contents, never a truncated file.
 func (c *Config) Save() error {
+	if c.IsReadOnly() {
+		return ErrReadOnly
+	}
+	fl, err := c.lockFile()
+	if err != nil {
+		return err
+	}
+	defer unlockFile(fl)
+	return c.save()
+}
+
+// save writes the configuration files, the file lock must be held by
+// the caller if locking is enabled.
+func (c *Config) save() error {
+	c.lock.Lock()
+	if latest := c.latestVersion(); latest > 0 {
+		c.Buffer[VERSION_KEY] = latest
+	}
 	b, err := json.MarshalIndent(c.Buffer, "", "  ")
+	c.lock.Unlock()
 	if err != nil {
 		return err
 	}
 	b = append(b, '\n')
-	if err = os.WriteFile(c.cfgPath, b, 0o664); err != nil {
+	if err = fsx.WriteFileAtomic(c.cfgPath, b, c.filePerm()); err != nil {
 		return err
 	}
+	c.syncWatch()
 	if c.bakPath != "" {
-		return os.WriteFile(c.bakPath, b, 0o664)
+		return fsx.WriteFileAtomic(c.bakPath, b, c.filePerm())
 	}
 	return nil
 }
 
 // Keys returns a list of all keys in the configuration buffer.
 func (c *Config) Keys() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 	return dictx.KeysN(c.Buffer, -1)
 }
 
 // Get retrieves a value from the configuration buffer by key.
 // If the key is not found, the default_value is returned.
 func (c *Config) Get(key string, defaultValue any) any {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 	return dictx.Get(c.Buffer, key, defaultValue)
 }
 
 // Set adds a new value in the configuration buffer by key.
 // If the key already exists, its value is overwritten.
-func (c *Config) Set(key string, newValue any) {
+// Returns ErrReadOnly in read-only mode.
+func (c *Config) Set(key string, newValue any) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.readOnly {
+		return ErrReadOnly
+	}
 	dictx.Set(c.Buffer, key, newValue)
+	return nil
 }
 
 // Merge updates a configuration buffer recursively with an update dictionary.
 // It merges keys and values, allowing nested dictionaries to be updated as well.
-func (c *Config) Merge(updt Dict) {
+// Returns ErrReadOnly in read-only mode.
+func (c *Config) Merge(updt Dict) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.readOnly {
+		return ErrReadOnly
+	}
 	dictx.Merge(c.Buffer, updt)
+	return nil
 }
 
 // Delete removes a key from the configuration buffer if it exists.
 // It supports nested keys using the separator.
-func (c *Config) Delete(key string) {
+// Returns ErrReadOnly in read-only mode.
+func (c *Config) Delete(key string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.readOnly {
+		return ErrReadOnly
+	}
 	dictx.Delete(c.Buffer, key)
+	return nil
 }
 
 // Purge clears the configuration buffer and deletes the main and
 // backup files (if they exist).
 func (c *Config) Purge() error {
+	c.lock.Lock()
+	if c.readOnly {
+		c.lock.Unlock()
+		return ErrReadOnly
+	}
 	c.Buffer = Dict{}
+	c.lock.Unlock()
 	if c.IsBackupExist() {
 		os.Remove(c.bakPath)
 	}
@@ -194,8 +328,7 @@ func (c *Config) InitAES128(secret string) error {
 	if err != nil {
 		return err
 	}
-	c.cipher = cipher
-	return nil
+	return c.InitCipher(cipher)
 }
 
 // InitAES256 initializes AES-256 encryption for the configuration
@@ -206,19 +339,73 @@ func (c *Config) InitAES256(secret string) error {
 	if err != nil {
 		return err
 	}
-	c.cipher = cipher
+	return c.InitCipher(cipher)
+}
+
+// InitCipher initializes encryption for the configuration using a custom
+// cipher handler, such as a hardware-backed TPM or PKCS#11 module that
+// performs the encryption without exposing the key.
+func (c *Config) InitCipher(h ciphering.Handler) error {
+	if h == nil {
+		return errors.New("cipher handler cannot be empty")
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cipher = h
 	return nil
 }
 
+// getCipher returns the configured cipher handler.
+func (c *Config) getCipher() ciphering.Handler {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.cipher
+}
+
+// InitAES128Provider initializes AES-128 encryption for the configuration
+// using the named secret key loaded from secrets provider.
+// Uses the default secrets provider if p is nil.
+func (c *Config) InitAES128Provider(p secrets.Provider, name string) error {
+	secret, err := getSecret(p, name)
+	if err != nil {
+		return err
+	}
+	return c.InitAES128(secret)
+}
+
+// InitAES256Provider initializes AES-256 encryption for the configuration
+// using the named secret key loaded from secrets provider.
+// Uses the default secrets provider if p is nil.
+func (c *Config) InitAES256Provider(p secrets.Provider, name string) error {
+	secret, err := getSecret(p, name)
+	if err != nil {
+		return err
+	}
+	return c.InitAES256(secret)
+}
+
+// getSecret loads the named secret from provider.
+func getSecret(p secrets.Provider, name string) (string, error) {
+	if p == nil {
+		p = secrets.Default()
+	}
+	b, err := p.GetSecret(name)
+	if err != nil {
+		return "", fmt.Errorf("failed loading secret %s - %w", name, err)
+	}
+	return string(b), nil
+}
+
 // GetSecure retrieves and decrypts a secure value by key from the configuration.
 // If the key does not exist or decryption fails, it returns the defaultValue.
 // Returns an error if encryption is not configured or the value format is invalid.
 func (c *Config) GetSecure(key string, defaultValue any) (any, error) {
-	if c.cipher == nil {
+	cipher := c.getCipher()
+	if cipher == nil {
 		return nil, fmt.Errorf("ciphering is not configured")
 	}
 	// Retrieve the encrypted value from the buffer
-	data := dictx.Get(c.Buffer, key, nil)
+	data := c.Get(key, nil)
 	if data == nil {
 		return defaultValue, nil
 	}
@@ -228,7 +415,7 @@ func (c *Config) GetSecure(key string, defaultValue any) (any, error) {
 		if err != nil {
 			return nil, err
 		}
-		decryptedBytes, err := c.cipher.Decrypt(encryptedBytes)
+		decryptedBytes, err := cipher.Decrypt(encryptedBytes)
 		if err != nil {
 			return nil, err
 		}
@@ -248,18 +435,68 @@ func (c *Config) GetSecure(key string, defaultValue any) (any, error) {
 // The key is created if it doesn't exist.
 // Returns an error if encryption is not configured.
 func (c *Config) SetSecure(key string, val any) error {
-	if c.cipher == nil {
+	cipher := c.getCipher()
+	if cipher == nil {
 		return fmt.Errorf("ciphering is not configured")
 	}
 	valBytes, err := json.Marshal(val)
 	if err != nil {
 		return err
 	}
-	encryptedBytes, err := c.cipher.Encrypt(valBytes)
+	encryptedBytes, err := cipher.Encrypt(valBytes)
 	if err != nil {
 		return err
 	}
 	encryptedStr := base64.StdEncoding.EncodeToString(encryptedBytes)
-	dictx.Set(c.Buffer, key, encryptedStr)
-	return nil
+	return c.Set(key, encryptedStr)
+}
+
+// RotateCipher re-encrypts all secure values in the configuration buffer
+// with the new cipher handler and sets it as the configuration cipher.
+// Secure values are detected by decrypting them with the current cipher,
+// values that fail to decrypt are left unchanged. The buffer is only
+// updated if all values are re-encrypted successfully.
+// Returns the sorted list of re-encrypted keys.
+func (c *Config) RotateCipher(h ciphering.Handler) ([]string, error) {
+	if h == nil {
+		return nil, errors.New("cipher handler cannot be empty")
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.readOnly {
+		return nil, ErrReadOnly
+	}
+	if c.cipher == nil {
+		return nil, fmt.Errorf("ciphering is not configured")
+	}
+
+	updates := map[string]string{}
+	for _, k := range dictx.KeysN(c.Buffer, -1) {
+		str, ok := dictx.Get(c.Buffer, k, nil).(string)
+		if !ok || len(str) == 0 {
+			continue
+		}
+		encryptedBytes, err := base64.StdEncoding.DecodeString(str)
+		if err != nil {
+			continue
+		}
+		decryptedBytes, err := c.cipher.Decrypt(encryptedBytes)
+		if err != nil {
+			continue
+		}
+		encryptedBytes, err = h.Encrypt(decryptedBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed encrypting key %s - %w", k, err)
+		}
+		updates[k] = base64.StdEncoding.EncodeToString(encryptedBytes)
+	}
+
+	keys := make([]string, 0, len(updates))
+	for k, v := range updates {
+		dictx.Set(c.Buffer, k, v)
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	c.cipher = h
+	return keys, nil
 }
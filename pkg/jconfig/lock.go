@@ -0,0 +1,81 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package jconfig
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/fsx"
+)
+
+// ErrReadOnly indicates a modification of a read-only configuration.
+var ErrReadOnly = errors.New("config is read-only")
+
+const (
+	// LOCK_TIMEOUT is the default timeout for acquiring the file lock.
+	LOCK_TIMEOUT = 5 * time.Second
+	// LOCK_RETRY is the delay between attempts to acquire the file lock.
+	LOCK_RETRY = 20 * time.Millisecond
+)
+
+// EnableLocking coordinates Load and Save with other processes sharing
+// the config file, using an exclusive lock on the file with a `.lock`
+// suffix. If timeout is zero or negative, LOCK_TIMEOUT is used.
+func (c *Config) EnableLocking(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = LOCK_TIMEOUT
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.lockTimeout = timeout
+}
+
+// SetReadOnly sets the read-only mode. In read-only mode the config files
+// are never written and all modifications fail with ErrReadOnly.
+func (c *Config) SetReadOnly(readOnly bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.readOnly = readOnly
+}
+
+// IsReadOnly checks whether the configuration is in read-only mode.
+func (c *Config) IsReadOnly() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.readOnly
+}
+
+// lockFile acquires the inter-process file lock if locking is enabled,
+// retrying until the lock timeout expires. Returns a nil lock if locking
+// is disabled.
+func (c *Config) lockFile() (*fsx.FileLock, error) {
+	c.lock.RLock()
+	timeout := c.lockTimeout
+	c.lock.RUnlock()
+	if timeout <= 0 {
+		return nil, nil
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		l, err := fsx.Lock(c.cfgPath + ".lock")
+		if err == nil {
+			return l, nil
+		}
+		if !errors.Is(err, fsx.ErrLocked) || time.Now().After(deadline) {
+			return nil, fmt.Errorf("failed locking config - %w", err)
+		}
+		time.Sleep(LOCK_RETRY)
+	}
+}
+
+// unlockFile releases the inter-process file lock if held.
+func unlockFile(l *fsx.FileLock) {
+	if l != nil {
+		l.Unlock()
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package jconfig
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+)
+
+// VERSION_KEY is the configuration key storing the layout version.
+// Configurations without the key are considered version 0.
+const VERSION_KEY = "_version"
+
+// MigrationFunc upgrades the configuration data in place from one layout
+// version to the next.
+type MigrationFunc func(d Dict) error
+
+// migration defines a registered upgrade step.
+type migration struct {
+	to int
+	fn MigrationFunc
+}
+
+// RegisterMigration registers a function to upgrade the configuration
+// layout from one version to a higher version. Loaded configurations are
+// upgraded by chaining the migrations up to the latest registered version.
+func (c *Config) RegisterMigration(from, to int, fn MigrationFunc) error {
+	if from < 0 || to <= from {
+		return fmt.Errorf("invalid migration versions %d -> %d", from, to)
+	}
+	if fn == nil {
+		return errors.New("migration function cannot be empty")
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.migrations == nil {
+		c.migrations = map[int]migration{}
+	}
+	if _, ok := c.migrations[from]; ok {
+		return fmt.Errorf("migration from version %d already registered", from)
+	}
+	c.migrations[from] = migration{to: to, fn: fn}
+	return nil
+}
+
+// Version returns the layout version of the loaded configuration.
+func (c *Config) Version() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return dictx.GetInt(c.Buffer, VERSION_KEY, 0)
+}
+
+// latestVersion returns the highest registered migration version, or 0
+// if no migrations are registered. The lock must be held by the caller.
+func (c *Config) latestVersion() int {
+	latest := 0
+	for _, m := range c.migrations {
+		if m.to > latest {
+			latest = m.to
+		}
+	}
+	return latest
+}
+
+// migrate upgrades the configuration data to the latest version.
+// Returns the original version if the data was migrated, or -1 if the
+// data is already at the latest version.
+func (c *Config) migrate(data Dict) (int, error) {
+	c.lock.RLock()
+	latest := c.latestVersion()
+	migrations := make(map[int]migration, len(c.migrations))
+	for k, v := range c.migrations {
+		migrations[k] = v
+	}
+	c.lock.RUnlock()
+
+	if latest == 0 {
+		return -1, nil
+	}
+
+	ver := dictx.GetInt(data, VERSION_KEY, 0)
+	if ver == latest {
+		return -1, nil
+	}
+	if ver > latest {
+		return -1, fmt.Errorf("unsupported config version %d", ver)
+	}
+
+	from := ver
+	for ver < latest {
+		m, ok := migrations[ver]
+		if !ok {
+			return -1, fmt.Errorf("no migration from config version %d", ver)
+		}
+		if err := m.fn(data); err != nil {
+			return -1, fmt.Errorf(
+				"failed migrating config version %d -> %d - %w", ver, m.to, err)
+		}
+		ver = m.to
+	}
+	data[VERSION_KEY] = latest
+	return from, nil
+}
+
+// MoveKey moves a value to a new key, creating the nested keys as needed.
+// Used in migrations to rename keys or restructure nesting.
+func MoveKey(d Dict, oldKey, newKey string) {
+	if !dictx.IsExist(d, oldKey) {
+		return
+	}
+	val := dictx.Get(d, oldKey, nil)
+	dictx.Delete(d, oldKey)
+	dictx.Set(d, newKey, val)
+}
@@ -5,13 +5,21 @@
 package jconfig_test
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/abc/fsx"
+	"github.com/exonlabs/go-utils/pkg/ciphering"
 	"github.com/exonlabs/go-utils/pkg/jconfig"
+	"github.com/exonlabs/go-utils/pkg/secrets"
 )
 
 // TestNewConfig tests creating a new Config instance with valid parameters
@@ -84,3 +92,367 @@ func TestInvalidSecureValueFormat(t *testing.T) {
 	_, err = cfg.GetSecure("invalid_key", nil)
 	assert.Error(t, err)
 }
+
+// TestInitAESProvider tests the initialization of encryption from secrets provider
+func TestInitAESProvider(t *testing.T) {
+	cfg, err := jconfig.New("config.json", dictx.Dict{})
+	require.NoError(t, err)
+
+	p := secrets.FuncProvider(func(name string) ([]byte, error) {
+		if name == "config_key" {
+			return []byte("thisisaverylongkeythatisfortestingaes256"), nil
+		}
+		return nil, secrets.ErrNotFound
+	})
+	require.NoError(t, cfg.InitAES256Provider(p, "config_key"))
+	require.NoError(t, cfg.SetSecure("key", "value"))
+	val, err := cfg.GetSecure("key", nil)
+	require.NoError(t, err)
+	require.Equal(t, "value", val)
+
+	require.ErrorIs(t, cfg.InitAES128Provider(p, "missing"), secrets.ErrNotFound)
+}
+
+// TestReload tests reloading the config with defaults and changed keys
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg, err := jconfig.New(path, dictx.Dict{"a": 1, "b": dictx.Dict{"c": "x"}})
+	require.NoError(t, err)
+
+	var changed []string
+	cfg.OnChange(func(keys []string) { changed = keys })
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"b": {"c": "y"}, "d": true}`), 0o664))
+	keys, err := cfg.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b.c", "d"}, keys)
+	assert.Equal(t, keys, changed)
+	assert.Equal(t, 1, cfg.Get("a", nil))
+	assert.Equal(t, "y", cfg.Get("b.c", nil))
+
+	// keys removed from file fall back to defaults
+	require.NoError(t, os.WriteFile(path, []byte(`{}`), 0o664))
+	keys, err = cfg.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b.c", "d"}, keys)
+	assert.Equal(t, "x", cfg.Get("b.c", nil))
+
+	// invalid config keeps the current buffer
+	cfg.SetValidator(func(d jconfig.Dict) error {
+		if dictx.Get(d, "a", nil) == "bad" {
+			return errors.New("bad value")
+		}
+		return nil
+	})
+	require.NoError(t, os.WriteFile(path, []byte(`{"a": "bad"}`), 0o664))
+	_, err = cfg.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, 1, cfg.Get("a", nil))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"a": `), 0o664))
+	_, err = cfg.Reload()
+	assert.Error(t, err)
+}
+
+// TestWatch tests watching the config file for changes
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"a": 1}`), 0o664))
+
+	cfg, err := jconfig.New(path, nil)
+	require.NoError(t, err)
+	require.NoError(t, cfg.Load())
+
+	changes := make(chan []string, 10)
+	errs := make(chan error, 10)
+	cfg.OnChange(func(keys []string) { changes <- keys })
+	cfg.OnError(func(err error) { errs <- err })
+
+	require.NoError(t, cfg.Watch(10*time.Millisecond))
+	defer cfg.StopWatch()
+	assert.Error(t, cfg.Watch(0), "expected error for watching twice")
+
+	// bump the modification time to detect changes within the same tick
+	touch := func(data string) {
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o664))
+		mt := time.Now().Add(time.Duration(len(changes)+len(errs)+1) * time.Second)
+		require.NoError(t, os.Chtimes(path, mt, mt))
+	}
+
+	touch(`{"a": 2}`)
+	select {
+	case keys := <-changes:
+		assert.Equal(t, []string{"a"}, keys)
+	case <-time.After(time.Second):
+		t.Fatal("change not detected")
+	}
+	assert.Equal(t, float64(2), cfg.Get("a", nil))
+
+	touch(`{"a": `)
+	select {
+	case err := <-errs:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("error not reported")
+	}
+	assert.Equal(t, float64(2), cfg.Get("a", nil))
+
+	// own saves are not reloaded
+	cfg.Set("b", "x")
+	require.NoError(t, cfg.Save())
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, changes)
+
+	cfg.StopWatch()
+	touch(`{"a": 3}`)
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, changes)
+	assert.Equal(t, float64(2), cfg.Get("a", nil))
+}
+
+// TestSaveAtomic tests saving the config replaces files without leftovers
+func TestSaveAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"a": 1}`), 0o664))
+
+	cfg, err := jconfig.New(path, nil)
+	require.NoError(t, err)
+	cfg.EnableBackup()
+	require.NoError(t, cfg.Load())
+
+	cfg.Set("b", "x")
+	require.NoError(t, cfg.Save())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.ElementsMatch(t, []string{"config.json", "config.json.backup"}, names)
+
+	main, err := os.ReadFile(path)
+	require.NoError(t, err)
+	backup, err := os.ReadFile(path + ".backup")
+	require.NoError(t, err)
+	assert.Equal(t, main, backup)
+	assert.JSONEq(t, `{"a": 1, "b": "x"}`, string(main))
+
+	// failed serialization keeps the existing file
+	cfg.Set("c", make(chan int))
+	assert.Error(t, cfg.Save())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, main, data)
+
+	// failed write leaves no temp files
+	cfg.Delete("c")
+	cfg2, err := jconfig.New(filepath.Join(dir, "missing", "config.json"), nil)
+	require.NoError(t, err)
+	assert.Error(t, cfg2.Save())
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+// TestSaveMode tests keeping the file modes on save and load
+func TestSaveMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes not supported on windows")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"a": 1}`), 0o600))
+	require.NoError(t, os.Chmod(path, 0o600))
+
+	cfg, err := jconfig.New(path, nil)
+	require.NoError(t, err)
+	cfg.EnableBackup()
+	require.NoError(t, cfg.Load())
+	cfg.Set("b", "x")
+	require.NoError(t, cfg.Save())
+
+	// existing file mode is kept, and new backup uses the same mode
+	for _, p := range []string{path, path + ".backup"} {
+		info, err := os.Stat(p)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), p)
+	}
+
+	// new files apply umask
+	ref := filepath.Join(dir, "ref")
+	require.NoError(t, os.WriteFile(ref, nil, 0o664))
+	refInfo, err := os.Stat(ref)
+	require.NoError(t, err)
+	cfg, err = jconfig.New(filepath.Join(dir, "new.json"), nil)
+	require.NoError(t, err)
+	require.NoError(t, cfg.Save())
+	info, err := os.Stat(filepath.Join(dir, "new.json"))
+	require.NoError(t, err)
+	assert.Equal(t, refInfo.Mode().Perm(), info.Mode().Perm())
+}
+
+// TestRotateCipher tests re-encrypting secure values with a new key
+func TestRotateCipher(t *testing.T) {
+	cfg, err := jconfig.New("config.json", dictx.Dict{"plain": "text"})
+	require.NoError(t, err)
+
+	_, err = cfg.RotateCipher(nil)
+	assert.Error(t, err)
+	old, err := ciphering.NewAES128("old secret")
+	require.NoError(t, err)
+	_, err = cfg.RotateCipher(old)
+	assert.Error(t, err, "expected error without configured cipher")
+
+	require.NoError(t, cfg.InitCipher(old))
+	require.NoError(t, cfg.SetSecure("a.b", "secret"))
+	require.NoError(t, cfg.SetSecure("c", 1234))
+
+	h, err := ciphering.NewAES256("new secret")
+	require.NoError(t, err)
+	keys, err := cfg.RotateCipher(h)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.b", "c"}, keys)
+	assert.Equal(t, "text", cfg.Get("plain", nil))
+
+	val, err := cfg.GetSecure("a.b", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", val)
+	val, err = cfg.GetSecure("c", nil)
+	require.NoError(t, err)
+	assert.Equal(t, float64(1234), val)
+
+	// values are no longer readable with the old key
+	require.NoError(t, cfg.InitCipher(old))
+	_, err = cfg.GetSecure("a.b", nil)
+	assert.Error(t, err)
+}
+
+// TestMigrations tests upgrading old config layouts on load
+func TestMigrations(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	orig := []byte(`{"host": "x", "port": 1}`)
+	require.NoError(t, os.WriteFile(path, orig, 0o664))
+
+	register := func(cfg *jconfig.Config) {
+		require.NoError(t, cfg.RegisterMigration(0, 1, func(d jconfig.Dict) error {
+			jconfig.MoveKey(d, "host", "server.host")
+			jconfig.MoveKey(d, "port", "server.port")
+			return nil
+		}))
+		require.NoError(t, cfg.RegisterMigration(1, 3, func(d jconfig.Dict) error {
+			dictx.Set(d, "server.tls", false)
+			return nil
+		}))
+	}
+
+	cfg, err := jconfig.New(path, dictx.Dict{"server": dictx.Dict{"tls": true}})
+	require.NoError(t, err)
+	register(cfg)
+	assert.Error(t, cfg.RegisterMigration(1, 2, func(jconfig.Dict) error { return nil }))
+	assert.Error(t, cfg.RegisterMigration(2, 2, func(jconfig.Dict) error { return nil }))
+	assert.Error(t, cfg.RegisterMigration(2, 3, nil))
+
+	require.NoError(t, cfg.Load())
+	assert.Equal(t, 3, cfg.Version())
+	assert.Equal(t, "x", cfg.Get("server.host", nil))
+	assert.Equal(t, false, cfg.Get("server.tls", nil))
+	assert.Nil(t, cfg.Get("host", nil))
+
+	// pre-migration file is kept and the migrated config is saved
+	b, err := os.ReadFile(path + ".v0")
+	require.NoError(t, err)
+	assert.Equal(t, orig, b)
+	cfg2, err := jconfig.New(path, nil)
+	require.NoError(t, err)
+	register(cfg2)
+	require.NoError(t, cfg2.Load())
+	assert.Equal(t, float64(3), cfg2.Get(jconfig.VERSION_KEY, nil))
+	assert.Equal(t, "x", cfg2.Get("server.host", nil))
+
+	// newer versions are not supported
+	require.NoError(t, os.WriteFile(path, []byte(`{"_version": 4}`), 0o664))
+	cfg3, err := jconfig.New(path, nil)
+	require.NoError(t, err)
+	register(cfg3)
+	assert.Error(t, cfg3.Load())
+
+	// new configs are saved with the latest version
+	cfg4, err := jconfig.New(filepath.Join(dir, "new.json"), nil)
+	require.NoError(t, err)
+	register(cfg4)
+	require.NoError(t, cfg4.Save())
+	assert.Equal(t, 3, cfg4.Version())
+}
+
+// TestMigrationsMissing tests loading fails without a migration path
+func TestMigrationsMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	orig := []byte(`{"a": 1}`)
+	require.NoError(t, os.WriteFile(path, orig, 0o664))
+
+	cfg, err := jconfig.New(path, nil)
+	require.NoError(t, err)
+	require.NoError(t, cfg.RegisterMigration(0, 1, func(jconfig.Dict) error { return nil }))
+	require.NoError(t, cfg.RegisterMigration(2, 3, func(jconfig.Dict) error { return nil }))
+
+	assert.ErrorContains(t, cfg.Load(), "no migration from config version 1")
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, orig, b)
+	assert.Nil(t, cfg.Get("a", nil))
+}
+
+// TestReadOnly tests read-only mode fails modifications
+func TestReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"a": 1}`), 0o664))
+
+	cfg, err := jconfig.New(path, nil)
+	require.NoError(t, err)
+	require.NoError(t, cfg.InitAES128("secret"))
+	cfg.EnableBackup()
+	cfg.SetReadOnly(true)
+	assert.True(t, cfg.IsReadOnly())
+
+	require.NoError(t, cfg.Load())
+	assert.Equal(t, float64(1), cfg.Get("a", nil))
+	assert.False(t, cfg.IsBackupExist(), "backup should not be written")
+
+	assert.ErrorIs(t, cfg.Set("a", 2), jconfig.ErrReadOnly)
+	assert.ErrorIs(t, cfg.Merge(dictx.Dict{"a": 2}), jconfig.ErrReadOnly)
+	assert.ErrorIs(t, cfg.Delete("a"), jconfig.ErrReadOnly)
+	assert.ErrorIs(t, cfg.SetSecure("b", "x"), jconfig.ErrReadOnly)
+	assert.ErrorIs(t, cfg.Save(), jconfig.ErrReadOnly)
+	assert.ErrorIs(t, cfg.Purge(), jconfig.ErrReadOnly)
+	assert.Equal(t, float64(1), cfg.Get("a", nil))
+
+	cfg.SetReadOnly(false)
+	assert.NoError(t, cfg.Set("a", 2))
+	assert.NoError(t, cfg.Save())
+}
+
+// TestLocking tests coordinating file access with other processes
+func TestLocking(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg, err := jconfig.New(path, dictx.Dict{"a": 1})
+	require.NoError(t, err)
+	cfg.EnableLocking(50 * time.Millisecond)
+	require.NoError(t, cfg.Save())
+
+	// lock held by another process
+	l, err := fsx.Lock(path + ".lock")
+	require.NoError(t, err)
+	assert.ErrorIs(t, cfg.Save(), fsx.ErrLocked)
+	assert.ErrorIs(t, cfg.Load(), fsx.ErrLocked)
+
+	// lock released while waiting
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		l.Unlock()
+	}()
+	assert.NoError(t, cfg.Save())
+	assert.NoError(t, cfg.Load())
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package jconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/abc/fsx"
+)
+
+// WATCH_INTERVAL is the default polling interval for watching the
+// configuration file.
+const WATCH_INTERVAL = 2 * time.Second
+
+// watcher holds the state of an active file watch.
+type watcher struct {
+	stop  chan struct{}
+	done  chan struct{}
+	state fileState // last seen state of the config file
+}
+
+// fileState identifies a version of the config file on disk.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// statFile returns the current state of the file, or the zero state if
+// the file does not exist.
+func statFile(path string) fileState {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: fi.ModTime(), size: fi.Size()}
+}
+
+// SetValidator sets a function to validate the configuration on reload.
+// An invalid configuration is rejected and the current buffer is kept.
+func (c *Config) SetValidator(fn func(Dict) error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.validator = fn
+}
+
+// OnChange registers a handler called with the sorted list of changed
+// keys after the configuration is reloaded with changes.
+func (c *Config) OnChange(fn func(keys []string)) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.onChange = append(c.onChange, fn)
+}
+
+// OnError registers a handler called when reloading a watched file fails.
+func (c *Config) OnError(fn func(error)) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.onError = append(c.onError, fn)
+}
+
+// Reload reads the main configuration file and replaces the buffer with
+// the defaults merged with the file contents, after migrating and
+// validating it. Migrated contents are not written back to the file.
+// Registered change handlers are called if any keys changed.
+// Returns the sorted list of changed keys.
+func (c *Config) Reload() ([]string, error) {
+	fl, err := c.lockFile()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(c.cfgPath)
+	unlockFile(fl)
+	if err != nil {
+		return nil, err
+	}
+
+	var data Dict
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+	if data == nil {
+		data = Dict{}
+	}
+	if _, err := c.migrate(data); err != nil {
+		return nil, err
+	}
+	buffer, err := dictx.Clone(c.defaults)
+	if err != nil {
+		return nil, err
+	}
+	dictx.Merge(buffer, data)
+
+	c.lock.RLock()
+	validator := c.validator
+	readOnly := c.readOnly
+	c.lock.RUnlock()
+	if validator != nil {
+		if err := validator(buffer); err != nil {
+			return nil, fmt.Errorf("invalid configuration - %w", err)
+		}
+	}
+
+	// swap the buffer
+	c.lock.Lock()
+	keys := changedKeys(c.Buffer, buffer)
+	c.Buffer = buffer
+	handlers := append([]func([]string){}, c.onChange...)
+	c.lock.Unlock()
+
+	if c.bakPath != "" && !readOnly {
+		fsx.WriteFileAtomic(c.bakPath, b, c.filePerm())
+	}
+	if len(keys) > 0 {
+		for _, fn := range handlers {
+			fn(keys)
+		}
+	}
+	return keys, nil
+}
+
+// Watch starts polling the main configuration file at the given interval
+// and reloads it when it changes on disk. If interval is zero or negative,
+// WATCH_INTERVAL is used. Change and error handlers are called from the
+// watching goroutine and must not call StopWatch.
+func (c *Config) Watch(interval time.Duration) error {
+	if interval <= 0 {
+		interval = WATCH_INTERVAL
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.watch != nil {
+		return errors.New("config is already watched")
+	}
+	w := &watcher{
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+		state: statFile(c.cfgPath),
+	}
+	c.watch = w
+
+	go c.watchLoop(w, interval)
+	return nil
+}
+
+// StopWatch stops watching the configuration file and waits for the
+// watching goroutine to exit.
+func (c *Config) StopWatch() {
+	c.lock.Lock()
+	w := c.watch
+	c.watch = nil
+	c.lock.Unlock()
+
+	if w != nil {
+		close(w.stop)
+		<-w.done
+	}
+}
+
+// watchLoop polls the file state and reloads on changes.
+func (c *Config) watchLoop(w *watcher, interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+
+		state := statFile(c.cfgPath)
+		c.lock.Lock()
+		changed := state != w.state
+		w.state = state
+		c.lock.Unlock()
+
+		// a removed file keeps the current configuration
+		if !changed || state == (fileState{}) {
+			continue
+		}
+		if _, err := c.Reload(); err != nil {
+			c.lock.RLock()
+			handlers := append([]func(error){}, c.onError...)
+			c.lock.RUnlock()
+			for _, fn := range handlers {
+				fn(err)
+			}
+		}
+	}
+}
+
+// syncWatch updates the watched file state after the file is written
+// by Save, to avoid reloading our own changes.
+func (c *Config) syncWatch() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.watch != nil {
+		c.watch.state = statFile(c.cfgPath)
+	}
+}
+
+// changedKeys returns the sorted list of nested keys that were added,
+// removed or modified between two buffers.
+func changedKeys(old, new Dict) []string {
+	keys := map[string]bool{}
+	for _, k := range dictx.KeysN(old, -1) {
+		keys[k] = true
+	}
+	for _, k := range dictx.KeysN(new, -1) {
+		keys[k] = true
+	}
+
+	result := []string{}
+	for k := range keys {
+		if dictx.IsExist(old, k) != dictx.IsExist(new, k) ||
+			!reflect.DeepEqual(dictx.Get(old, k, nil), dictx.Get(new, k, nil)) {
+			result = append(result, k)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
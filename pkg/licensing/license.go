@@ -0,0 +1,184 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package licensing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrFormat indicates an invalid license file format.
+	ErrFormat = errors.New("invalid license format")
+	// ErrSignature indicates an invalid license signature.
+	ErrSignature = errors.New("invalid license signature")
+	// ErrMachine indicates a license bound to a different machine.
+	ErrMachine = errors.New("license machine mismatch")
+	// ErrExpired indicates an expired license.
+	ErrExpired = errors.New("license expired")
+	// ErrNotValidYet indicates a license with future issue date.
+	ErrNotValidYet = errors.New("license not valid yet")
+)
+
+// Status defines the license validity state.
+type Status int
+
+// License validity states.
+const (
+	INVALID Status = iota // License is invalid or expired
+	VALID                 // License is valid
+	GRACE                 // License expired but within grace period
+)
+
+// String returns the string representation of the license status.
+func (s Status) String() string {
+	switch s {
+	case VALID:
+		return "valid"
+	case GRACE:
+		return "grace"
+	default:
+		return "invalid"
+	}
+}
+
+// License represents the license entitlements.
+type License struct {
+	Id        string    `json:"id"`                   // License identifier
+	Customer  string    `json:"customer"`             // Licensee name
+	Product   string    `json:"product"`              // Licensed product
+	Features  []string  `json:"features"`             // Entitled features
+	IssuedAt  time.Time `json:"issued_at"`            // Issue date
+	ExpiresAt time.Time `json:"expires_at,omitempty"` // Expiry date, zero for perpetual
+	GraceDays int       `json:"grace_days,omitempty"` // Grace period after expiry in days
+	MachineId string    `json:"machine_id,omitempty"` // Bound machine ID, empty for unbound
+}
+
+// licenseFile represents the signed license file content.
+type licenseFile struct {
+	License   json.RawMessage `json:"license"`
+	Signature string          `json:"signature"`
+}
+
+// Sign serializes and signs the license with the issuer private key.
+// Returns the signed license file content.
+func Sign(lic *License, key ed25519.PrivateKey) ([]byte, error) {
+	b, err := json.Marshal(lic)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(&licenseFile{
+		License:   b,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, b)),
+	}, "", "  ")
+}
+
+// Parse verifies the signed license file content with the issuer public
+// key and returns the license. It does not check expiry or machine binding,
+// use [License.Validate] for that.
+func Parse(b []byte, key ed25519.PublicKey) (*License, error) {
+	var f licenseFile
+	if err := json.Unmarshal(b, &f); err != nil || len(f.License) == 0 {
+		return nil, ErrFormat
+	}
+	// signature is computed over the compact license content
+	var data bytes.Buffer
+	if err := json.Compact(&data, f.License); err != nil {
+		return nil, ErrFormat
+	}
+	sig, err := base64.StdEncoding.DecodeString(f.Signature)
+	if err != nil || len(key) != ed25519.PublicKeySize ||
+		!ed25519.Verify(key, data.Bytes(), sig) {
+		return nil, ErrSignature
+	}
+
+	lic := &License{}
+	if err := json.Unmarshal(f.License, lic); err != nil {
+		return nil, fmt.Errorf("%w, %v", ErrFormat, err)
+	}
+	return lic, nil
+}
+
+// Load reads and verifies the license file at path.
+func Load(path string, key ed25519.PublicKey) (*License, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b, key)
+}
+
+// graceEnd returns the end time of the grace period.
+func (l *License) graceEnd() time.Time {
+	return l.ExpiresAt.Add(time.Duration(l.GraceDays) * 24 * time.Hour)
+}
+
+// StatusAt returns the license validity status at the given time.
+func (l *License) StatusAt(t time.Time) Status {
+	switch {
+	case !l.IssuedAt.IsZero() && t.Before(l.IssuedAt):
+		return INVALID
+	case l.ExpiresAt.IsZero() || t.Before(l.ExpiresAt):
+		return VALID
+	case t.Before(l.graceEnd()):
+		return GRACE
+	default:
+		return INVALID
+	}
+}
+
+// Status returns the current license validity status.
+func (l *License) Status() Status {
+	return l.StatusAt(time.Now())
+}
+
+// Validate checks the license machine binding against the local machine ID
+// and checks the license expiry. Licenses within the grace period are valid.
+func (l *License) Validate() error {
+	if l.MachineId != "" {
+		id, err := MachineId()
+		if err != nil {
+			return fmt.Errorf("%w, %v", ErrMachine, err)
+		}
+		if !strings.EqualFold(id, l.MachineId) {
+			return ErrMachine
+		}
+	}
+
+	now := time.Now()
+	if !l.IssuedAt.IsZero() && now.Before(l.IssuedAt) {
+		return ErrNotValidYet
+	}
+	if l.StatusAt(now) == INVALID {
+		return ErrExpired
+	}
+	return nil
+}
+
+// GraceRemaining returns the remaining grace period duration, or zero
+// if license is not in grace period.
+func (l *License) GraceRemaining() time.Duration {
+	if l.Status() != GRACE {
+		return 0
+	}
+	return time.Until(l.graceEnd())
+}
+
+// HasFeature checks if the license entitles the named feature.
+func (l *License) HasFeature(name string) bool {
+	for _, f := range l.Features {
+		if f == name || f == "*" {
+			return true
+		}
+	}
+	return false
+}
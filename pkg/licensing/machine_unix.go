@@ -0,0 +1,32 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package licensing
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// machineIdPaths defines the system files holding the machine ID.
+var machineIdPaths = []string{
+	"/etc/machine-id",
+	"/var/lib/dbus/machine-id",
+	"/etc/hostid",
+}
+
+// MachineId returns the unique ID of the local machine.
+func MachineId() (string, error) {
+	for _, p := range machineIdPaths {
+		if b, err := os.ReadFile(p); err == nil {
+			if id := strings.TrimSpace(string(b)); id != "" {
+				return id, nil
+			}
+		}
+	}
+	return "", errors.New("machine id not found")
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build windows
+
+package licensing
+
+import (
+	"golang.org/x/sys/windows/registry"
+)
+
+// MachineId returns the unique ID of the local machine.
+func MachineId() (string, error) {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE,
+		`SOFTWARE\Microsoft\Cryptography`,
+		registry.QUERY_VALUE|registry.WOW64_64KEY)
+	if err != nil {
+		return "", err
+	}
+	defer k.Close()
+
+	id, _, err := k.GetStringValue("MachineGuid")
+	return id, err
+}
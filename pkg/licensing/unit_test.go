@@ -0,0 +1,81 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package licensing_test
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/licensing"
+)
+
+func TestSignAndParse(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+
+	lic := &licensing.License{
+		Id:        "lic-001",
+		Customer:  "ACME",
+		Features:  []string{"basic", "reports"},
+		IssuedAt:  time.Now().Add(-time.Hour).UTC(),
+		ExpiresAt: time.Now().Add(24 * time.Hour).UTC(),
+	}
+	b, err := licensing.Sign(lic, priv)
+	assert.NoError(t, err)
+
+	l, err := licensing.Parse(b, pub)
+	assert.NoError(t, err)
+	assert.Equal(t, "lic-001", l.Id)
+	assert.True(t, l.HasFeature("reports"))
+	assert.False(t, l.HasFeature("admin"))
+	assert.Equal(t, licensing.VALID, l.Status())
+	assert.NoError(t, l.Validate())
+
+	// tampered content
+	_, err = licensing.Parse(
+		[]byte(strings.Replace(string(b), "ACME", "ACMX", 1)), pub)
+	assert.ErrorIs(t, err, licensing.ErrSignature)
+
+	// invalid format
+	_, err = licensing.Parse([]byte("{}"), pub)
+	assert.ErrorIs(t, err, licensing.ErrFormat)
+}
+
+func TestStatus(t *testing.T) {
+	now := time.Now()
+	lic := &licensing.License{
+		IssuedAt:  now.Add(-48 * time.Hour),
+		ExpiresAt: now.Add(-time.Hour),
+		GraceDays: 2,
+	}
+	assert.Equal(t, licensing.GRACE, lic.Status())
+	assert.NoError(t, lic.Validate())
+	assert.Greater(t, lic.GraceRemaining(), 24*time.Hour)
+
+	lic.GraceDays = 0
+	assert.Equal(t, licensing.INVALID, lic.Status())
+	assert.ErrorIs(t, lic.Validate(), licensing.ErrExpired)
+
+	// perpetual license
+	lic.ExpiresAt = time.Time{}
+	assert.Equal(t, licensing.VALID, lic.Status())
+
+	// future license
+	lic.IssuedAt = now.Add(time.Hour)
+	assert.ErrorIs(t, lic.Validate(), licensing.ErrNotValidYet)
+}
+
+func TestMachineBinding(t *testing.T) {
+	lic := &licensing.License{MachineId: "invalid-machine-id"}
+	assert.ErrorIs(t, lic.Validate(), licensing.ErrMachine)
+
+	if id, err := licensing.MachineId(); err == nil {
+		lic.MachineId = id
+		assert.NoError(t, lic.Validate())
+	}
+}
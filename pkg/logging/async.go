@@ -0,0 +1,183 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package logging
+
+import (
+	"errors"
+	"sync"
+)
+
+// ASYNC_QUEUE_SIZE defines the default async handler queue size.
+const ASYNC_QUEUE_SIZE = 1024
+
+// OverflowPolicy defines the async handler behavior on full queue.
+type OverflowPolicy int
+
+const (
+	// DROP_OLDEST discards the oldest queued record to queue new records.
+	DROP_OLDEST OverflowPolicy = iota
+	// BLOCK blocks logging until queue space is available.
+	BLOCK
+)
+
+// ErrHandlerClosed indicates logging to a closed handler.
+var ErrHandlerClosed = errors.New("handler closed")
+
+// AsyncHandler wraps a handler with a bounded queue and a background
+// writer, so logging never waits for slow handlers such as files on slow
+// storage or network sinks. Write errors of the wrapped handler are
+// reported on Flush and Close.
+type AsyncHandler struct {
+	handler Handler
+	size    int
+	policy  OverflowPolicy
+
+	queue   []asyncRecord
+	busy    bool
+	closed  bool
+	dropped uint64
+	err     error
+	mu      sync.Mutex
+	cond    *sync.Cond
+	done    chan struct{}
+}
+
+// asyncRecord defines a queued log record.
+type asyncRecord struct {
+	lvl    Level
+	record string
+	// flag set for records with level
+	leveled bool
+}
+
+// NewAsyncHandler creates a new async handler writing records to h,
+// queuing up to size records and applying policy when queue is full.
+// Setting size=0 will use the default size [ASYNC_QUEUE_SIZE].
+func NewAsyncHandler(h Handler, size int, policy OverflowPolicy) *AsyncHandler {
+	if size <= 0 {
+		size = ASYNC_QUEUE_SIZE
+	}
+	a := &AsyncHandler{
+		handler: h,
+		size:    size,
+		policy:  policy,
+		done:    make(chan struct{}),
+	}
+	a.cond = sync.NewCond(&a.mu)
+	go a.writer()
+	return a
+}
+
+// Dropped returns the number of records dropped on queue overflow.
+func (a *AsyncHandler) Dropped() uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.dropped
+}
+
+// HandleRecord queues the log record for writing.
+func (a *AsyncHandler) HandleRecord(record string) error {
+	return a.enqueue(asyncRecord{record: record})
+}
+
+// HandleLevelRecord queues the log record with its level for writing.
+// The level is passed to the wrapped handler if it implements
+// [LevelHandler].
+func (a *AsyncHandler) HandleLevelRecord(lvl Level, record string) error {
+	return a.enqueue(asyncRecord{lvl: lvl, record: record, leveled: true})
+}
+
+// enqueue adds the record to queue applying the overflow policy.
+func (a *AsyncHandler) enqueue(r asyncRecord) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for !a.closed && len(a.queue) >= a.size {
+		if a.policy == BLOCK {
+			a.cond.Wait()
+			continue
+		}
+		a.queue = a.queue[1:]
+		a.dropped++
+	}
+	if a.closed {
+		return ErrHandlerClosed
+	}
+	a.queue = append(a.queue, r)
+	a.cond.Broadcast()
+	return nil
+}
+
+// Flush waits until all queued records are written, flushes the wrapped
+// handler if it implements [Flusher], and returns the first write error
+// since last flush.
+func (a *AsyncHandler) Flush() error {
+	a.mu.Lock()
+	for len(a.queue) > 0 || a.busy {
+		a.cond.Wait()
+	}
+	err := a.err
+	a.err = nil
+	a.mu.Unlock()
+
+	if f, ok := a.handler.(Flusher); ok {
+		err = errors.Join(err, f.Flush())
+	}
+	return err
+}
+
+// Close writes all queued records and stops the background writer.
+// Logging to a closed handler returns [ErrHandlerClosed].
+func (a *AsyncHandler) Close() error {
+	a.mu.Lock()
+	a.closed = true
+	a.cond.Broadcast()
+	a.mu.Unlock()
+	<-a.done
+	return a.Flush()
+}
+
+// writer writes the queued records in batches until closed.
+func (a *AsyncHandler) writer() {
+	defer close(a.done)
+	for {
+		a.mu.Lock()
+		for len(a.queue) == 0 && !a.closed {
+			a.cond.Wait()
+		}
+		if len(a.queue) == 0 {
+			a.mu.Unlock()
+			return
+		}
+		batch := a.queue
+		a.queue = make([]asyncRecord, 0, len(batch))
+		a.busy = true
+		// wake writers blocked on full queue
+		a.cond.Broadcast()
+		a.mu.Unlock()
+
+		lh, leveled := a.handler.(LevelHandler)
+		var firstErr error
+		for _, r := range batch {
+			var err error
+			if leveled && r.leveled {
+				err = lh.HandleLevelRecord(r.lvl, r.record)
+			} else {
+				err = a.handler.HandleRecord(r.record)
+			}
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+
+		a.mu.Lock()
+		// keep first error only, failing sinks fail for every record
+		if a.err == nil {
+			a.err = firstErr
+		}
+		a.busy = false
+		a.cond.Broadcast()
+		a.mu.Unlock()
+	}
+}
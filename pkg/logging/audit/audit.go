@@ -0,0 +1,294 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+)
+
+// MAX_RECORD_SIZE defines the max size of an encoded audit record.
+const MAX_RECORD_SIZE = 1024 * 1024
+
+var (
+	// ErrTampered indicates an audit log failing verification.
+	ErrTampered = errors.New("audit log tampered")
+	// ErrClosed indicates writing to a closed audit log.
+	ErrClosed = errors.New("audit log closed")
+	// ErrIncomplete indicates an audit log ending with a partially
+	// written record, as left by a crash during write.
+	ErrIncomplete = errors.New("audit log incomplete")
+)
+
+// Record defines an audit record.
+type Record struct {
+	// Seq defines the record sequence number starting from 1.
+	Seq uint64 `json:"seq"`
+	// Time defines the record time in UTC.
+	Time time.Time `json:"time"`
+	// Actor defines who did the operation.
+	Actor string `json:"actor"`
+	// Action defines what operation was done.
+	Action string `json:"action"`
+	// Target defines the operation target, if any.
+	Target string `json:"target,omitempty"`
+	// Details defines the operation details, if any.
+	Details dictx.Dict `json:"details,omitempty"`
+	// Prev defines the MAC of previous record, empty for first record.
+	Prev string `json:"prev"`
+	// Mac defines the record HMAC-SHA256 in hex.
+	Mac string `json:"mac"`
+}
+
+// rawRecord defines the record as stored, keeping the encoded details
+// for exact MAC computation.
+type rawRecord struct {
+	Seq     uint64          `json:"seq"`
+	Time    time.Time       `json:"time"`
+	Actor   string          `json:"actor"`
+	Action  string          `json:"action"`
+	Target  string          `json:"target,omitempty"`
+	Details json.RawMessage `json:"details,omitempty"`
+	Prev    string          `json:"prev"`
+	Mac     string          `json:"mac"`
+}
+
+// mac returns the record HMAC computed over the record encoding with
+// empty mac field.
+func (r rawRecord) mac(key []byte) (string, error) {
+	r.Mac = ""
+	b, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	m := hmac.New(sha256.New, key)
+	m.Write(b)
+	return hex.EncodeToString(m.Sum(nil)), nil
+}
+
+// Log defines an append-only audit log file, where records are stored as
+// JSON lines chained by HMAC, each record MAC covering the record and the
+// previous record MAC. Modified, inserted, removed or reordered records
+// break the chain and are detected by [Verify]. Removing records at the
+// end of the log is only detected by comparing the last sequence number
+// and MAC with values kept elsewhere, see [Log.Last].
+type Log struct {
+	Path string
+
+	key  []byte
+	file *os.File
+	last Record
+	mu   sync.Mutex
+}
+
+// Open opens or creates the audit log at path using the HMAC key. The
+// existing records are verified before appending, and tampered logs are
+// refused with [ErrTampered]. A partially written last record, as left
+// by a crash during write, is discarded from the log.
+func Open(path string, key []byte) (*Log, error) {
+	if len(key) == 0 {
+		return nil, errors.New("empty audit key")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o640)
+	if err != nil {
+		return nil, err
+	}
+	last, size, err := verify(f, key)
+	if errors.Is(err, ErrIncomplete) {
+		if err = f.Truncate(size); err == nil {
+			err = f.Sync()
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Log{
+		Path: path,
+		key:  append([]byte(nil), key...),
+		file: f,
+		last: last,
+	}, nil
+}
+
+// Last returns the last written record, with zero Seq if log is empty.
+func (l *Log) Last() Record {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.last
+}
+
+// Write appends a new audit record and syncs it to storage.
+func (l *Log) Write(actor, action, target string, details dictx.Dict) (Record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return Record{}, ErrClosed
+	}
+
+	var det json.RawMessage
+	if len(details) > 0 {
+		b, err := json.Marshal(details)
+		if err != nil {
+			return Record{}, err
+		}
+		det = b
+	}
+	raw := rawRecord{
+		Seq:     l.last.Seq + 1,
+		Time:    time.Now().UTC(),
+		Actor:   actor,
+		Action:  action,
+		Target:  target,
+		Details: det,
+		Prev:    l.last.Mac,
+	}
+	mac, err := raw.mac(l.key)
+	if err != nil {
+		return Record{}, err
+	}
+	raw.Mac = mac
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return Record{}, err
+	}
+	if len(b) >= MAX_RECORD_SIZE {
+		return Record{}, errors.New("audit record too large")
+	}
+	if _, err := l.file.Write(append(b, '\n')); err != nil {
+		return Record{}, err
+	}
+	if err := l.file.Sync(); err != nil {
+		return Record{}, err
+	}
+
+	rec := Record{
+		Seq:     raw.Seq,
+		Time:    raw.Time,
+		Actor:   actor,
+		Action:  action,
+		Target:  target,
+		Details: details,
+		Prev:    raw.Prev,
+		Mac:     raw.Mac,
+	}
+	l.last = rec
+	return rec, nil
+}
+
+// Close closes the audit log.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Verify verifies the audit log chain at path using the HMAC key, and
+// returns the last record. Failures are reported as [ErrTampered] with
+// the failing line number. A partially written last line is reported as
+// [ErrIncomplete] with the last complete record.
+func Verify(path string, key []byte) (Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Record{}, err
+	}
+	defer f.Close()
+	return VerifyReader(f, key)
+}
+
+// VerifyReader verifies the audit records read from r, see [Verify].
+func VerifyReader(r io.Reader, key []byte) (Record, error) {
+	last, _, err := verify(r, key)
+	return last, err
+}
+
+// verify verifies the audit records read from r, and returns the last
+// record and the size of complete records read.
+func verify(r io.Reader, key []byte) (Record, int64, error) {
+	var last Record
+	var size int64
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), MAX_RECORD_SIZE)
+	s.Split(scanRecords)
+	line := 0
+	for s.Scan() {
+		line++
+		b := s.Bytes()
+		if b[len(b)-1] != '\n' {
+			return last, size, fmt.Errorf("%w, line %d: partial record",
+				ErrIncomplete, line)
+		}
+		var raw rawRecord
+		if err := json.Unmarshal(b, &raw); err != nil {
+			return last, size, fmt.Errorf("%w, line %d: invalid record",
+				ErrTampered, line)
+		}
+		if raw.Seq != last.Seq+1 {
+			return last, size, fmt.Errorf("%w, line %d: invalid sequence %d",
+				ErrTampered, line, raw.Seq)
+		}
+		if raw.Prev != last.Mac {
+			return last, size, fmt.Errorf("%w, line %d: broken chain",
+				ErrTampered, line)
+		}
+		mac, err := raw.mac(key)
+		if err != nil || !hmac.Equal([]byte(mac), []byte(raw.Mac)) {
+			return last, size, fmt.Errorf("%w, line %d: invalid mac",
+				ErrTampered, line)
+		}
+
+		rec := Record{
+			Seq:    raw.Seq,
+			Time:   raw.Time,
+			Actor:  raw.Actor,
+			Action: raw.Action,
+			Target: raw.Target,
+			Prev:   raw.Prev,
+			Mac:    raw.Mac,
+		}
+		if len(raw.Details) > 0 {
+			if err := json.Unmarshal(raw.Details, &rec.Details); err != nil {
+				return last, size, fmt.Errorf("%w, line %d: invalid details",
+					ErrTampered, line)
+			}
+		}
+		last = rec
+		size += int64(len(b))
+	}
+	if err := s.Err(); err != nil {
+		return last, size, fmt.Errorf("%w, line %d: %v",
+			ErrTampered, line+1, err)
+	}
+	return last, size, nil
+}
+
+// scanRecords splits records on line ends keeping the newline, so that
+// a partial last line without newline can be detected.
+func scanRecords(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i+1], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
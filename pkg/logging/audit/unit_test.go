@@ -0,0 +1,149 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package audit_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/logging/audit"
+)
+
+var key = []byte("secret-key")
+
+func writeLog(t *testing.T, path string) {
+	l, err := audit.Open(path, key)
+	require.NoError(t, err)
+	defer l.Close()
+	_, err = l.Write("admin", "login", "", nil)
+	require.NoError(t, err)
+	_, err = l.Write("admin", "set_config", "net.ip",
+		dictx.Dict{"old": "10.0.0.1", "new": "10.0.0.2", "retries": 3})
+	require.NoError(t, err)
+	_, err = l.Write("admin", "logout", "", nil)
+	require.NoError(t, err)
+}
+
+func TestAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	writeLog(t, path)
+
+	last, err := audit.Verify(path, key)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), last.Seq)
+	assert.Equal(t, "logout", last.Action)
+
+	// reopened log resumes the chain
+	l, err := audit.Open(path, key)
+	require.NoError(t, err)
+	assert.Equal(t, last.Mac, l.Last().Mac)
+	rec, err := l.Write("operator", "reboot", "gateway", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), rec.Seq)
+	assert.Equal(t, last.Mac, rec.Prev)
+	assert.NoError(t, l.Close())
+	_, err = l.Write("operator", "reboot", "", nil)
+	assert.ErrorIs(t, err, audit.ErrClosed)
+
+	last, err = audit.Verify(path, key)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), last.Seq)
+
+	_, err = audit.Verify(path, []byte("wrong-key"))
+	assert.ErrorIs(t, err, audit.ErrTampered)
+}
+
+func TestAuditTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	writeLog(t, path)
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.SplitAfter(string(b), "\n")
+
+	tests := map[string]string{
+		"modified":  strings.Replace(string(b), "10.0.0.2", "10.0.0.3", 1),
+		"removed":   lines[0] + lines[2],
+		"reordered": lines[1] + lines[0] + lines[2],
+		"torn":      lines[0] + lines[1][:20] + "\n" + lines[2],
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := filepath.Join(t.TempDir(), "audit.log")
+			require.NoError(t, os.WriteFile(p, []byte(data), 0o640))
+			_, err := audit.Verify(p, key)
+			assert.ErrorIs(t, err, audit.ErrTampered)
+			_, err = audit.Open(p, key)
+			assert.ErrorIs(t, err, audit.ErrTampered)
+		})
+	}
+}
+
+func TestAuditPartialRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	writeLog(t, path)
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.SplitAfter(string(b), "\n")
+
+	// crash during writing the last record
+	data := lines[0] + lines[1] + lines[2][:20]
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o640))
+	last, err := audit.Verify(path, key)
+	assert.ErrorIs(t, err, audit.ErrIncomplete)
+	assert.NotErrorIs(t, err, audit.ErrTampered)
+	assert.Equal(t, uint64(2), last.Seq)
+
+	// reopened log discards the partial record and resumes the chain
+	l, err := audit.Open(path, key)
+	require.NoError(t, err)
+	assert.Equal(t, last.Mac, l.Last().Mac)
+	rec, err := l.Write("admin", "logout", "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), rec.Seq)
+	assert.Equal(t, last.Mac, rec.Prev)
+	assert.NoError(t, l.Close())
+
+	last, err = audit.Verify(path, key)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), last.Seq)
+
+	// complete record missing only the line end is also partial
+	b, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, b[:len(b)-1], 0o640))
+	l, err = audit.Open(path, key)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), l.Last().Seq)
+	assert.NoError(t, l.Close())
+}
+
+func TestAuditDetails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	_, err := audit.Open(path, nil)
+	assert.Error(t, err)
+
+	l, err := audit.Open(path, key)
+	require.NoError(t, err)
+	_, err = l.Write("admin", "set_config", "net.ip",
+		dictx.Dict{"new": "10.0.0.2", "retries": 3, "html": "<b>"})
+	assert.NoError(t, err)
+	assert.NoError(t, l.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	last, err := audit.VerifyReader(f, key)
+	assert.NoError(t, err)
+	assert.Equal(t, dictx.Dict{
+		"new": "10.0.0.2", "retries": float64(3), "html": "<b>"}, last.Details)
+	assert.Equal(t, "net.ip", last.Target)
+	assert.Empty(t, last.Prev)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package logging
+
+import (
+	"os"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+// colors of levels and sources
+var (
+	colorTrace  = newColor(color.FgHiBlack)
+	colorDebug  = newColor(color.FgCyan)
+	colorInfo   = newColor(color.FgGreen)
+	colorWarn   = newColor(color.FgYellow)
+	colorError  = newColor(color.FgRed)
+	colorFatal  = newColor(color.FgHiRed, color.Bold)
+	colorSource = newColor(color.FgBlue)
+)
+
+func newColor(attrs ...color.Attribute) *color.Color {
+	c := color.New(attrs...)
+	// colors are enabled by formatter flags
+	c.EnableColor()
+	return c
+}
+
+// ColorEnabled returns whether colored output is supported, where the
+// standard output is a terminal and the NO_COLOR environment variable is
+// not set.
+func ColorEnabled() bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// NewColorFormatter creates a standard text formatter with level-based
+// colors and colored sources, where colors are disabled when the standard
+// output is not a terminal or NO_COLOR is set, see [ColorEnabled].
+func NewColorFormatter() *Formatter {
+	enabled := ColorEnabled()
+	return &Formatter{
+		RecordFormat: "{time} {level} [{source}] {message}",
+		TimeFormat:   "2006-01-02 15:04:05.000000",
+		ColorLevel:   enabled,
+		ColorSource:  enabled,
+	}
+}
+
+// colorLevel returns the colored level name.
+func colorLevel(lvl Level) string {
+	var c *color.Color
+	switch {
+	case lvl >= FATAL:
+		c = colorFatal
+	case lvl == ERROR:
+		c = colorError
+	case lvl == WARN:
+		c = colorWarn
+	case lvl == INFO:
+		c = colorInfo
+	case lvl == DEBUG:
+		c = colorDebug
+	default:
+		c = colorTrace
+	}
+	return c.Sprint(lvl.String())
+}
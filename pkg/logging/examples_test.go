@@ -30,3 +30,17 @@ func ExampleNewFileLogger() {
 	logger.Info("logging message type: info")
 	logger.Debug("logging message type: debug")
 }
+
+func ExampleNewAsyncHandler() {
+	log_path := filepath.Join(os.TempDir(), "foo.log")
+
+	logger := logging.NewStdoutLogger("main")
+	logger.ClearHandlers()
+	handler := logging.NewAsyncHandler(
+		logging.NewFileHandler(log_path), 0, logging.DROP_OLDEST)
+	defer handler.Close()
+	logger.AddHandler(handler)
+
+	logger.Info("logging message type: info")
+	logger.Flush()
+}
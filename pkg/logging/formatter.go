@@ -5,23 +5,76 @@
 package logging
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
 )
 
+// Lazy defines a deferred log message argument. The wrapped function is
+// only evaluated when the log record is actually emitted.
+type Lazy func() string
+
+// String returns the evaluated value of the deferred argument.
+func (f Lazy) String() string {
+	return f()
+}
+
+// lazyArgs returns args with plain function arguments wrapped as deferred
+// values. The caller's slice is copied on first change, never modified.
+func lazyArgs(args []any) []any {
+	var res []any
+	for i, a := range args {
+		if fn, ok := a.(func() string); ok {
+			if res == nil {
+				res = append([]any{}, args...)
+			}
+			res[i] = Lazy(fn)
+		}
+	}
+	if res == nil {
+		return args
+	}
+	return res
+}
+
 // Formatter formats the log record structure. It controls the
-// record format fields "time", "level", "source", and "message".
+// record format fields "time", "level", "source", "message" and "fields".
 // A message prefix can also be added to each logged message.
+//
+// The record fields, see [Logger.WithFields], are rendered as sorted
+// key=value pairs in place of "{fields}", or appended to the message if
+// the record format has no "{fields}" placeholder. With JsonFields set,
+// the fields are rendered as `,"key":value` JSON members.
 type Formatter struct {
 	MsgPrefix    string // Prefix to prepend to the message
 	RecordFormat string // Template for the log record format
 	TimeFormat   string // Custom time format
 	EscapeMsg    bool   // Flag to escape special characters in messages
+	JsonFields   bool   // Flag to render fields as JSON members
+	ColorLevel   bool   // Flag to color level names
+	ColorSource  bool   // Flag to color sources
 }
 
 // Emit generates a formatted log record message.
 func (f *Formatter) Emit(lvl Level, src, msg string, args ...any) string {
+	return f.EmitFields(lvl, src, nil, msg, args...)
+}
+
+// EmitFields generates a formatted log record message with fields.
+func (f *Formatter) EmitFields(
+	lvl Level, src string, fields dictx.Dict, msg string, args ...any) string {
+	r, _ := f.format(lvl, src, fields, msg, args...)
+	return r
+}
+
+// format returns the formatted log record and the formatted message.
+func (f *Formatter) format(lvl Level, src string, fields dictx.Dict,
+	msg string, args ...any) (string, string) {
 	now := time.Now().Local()
 
 	// Determine the time string based on the specified format
@@ -32,20 +85,75 @@ func (f *Formatter) Emit(lvl Level, src, msg string, args ...any) string {
 		t = now.Format(f.TimeFormat)
 	}
 
+	// Wrap plain function arguments as deferred values
+	args = lazyArgs(args)
+
 	// Format the message with optional prefix and arguments
 	m := fmt.Sprintf(f.MsgPrefix+msg, args...)
+	message := m
 	if f.EscapeMsg {
 		m = strings.ReplaceAll(m, `\`, `\\`)
 		m = strings.ReplaceAll(m, `"`, `\"`)
 	}
 
+	// Render fields in place or appended to message
+	var flds string
+	if len(fields) > 0 {
+		flds = f.formatFields(fields)
+		if !strings.Contains(f.RecordFormat, "{fields}") {
+			m += flds
+			flds = ""
+		}
+	}
+
+	level := lvl.String()
+	if f.ColorLevel {
+		level = colorLevel(lvl)
+	}
+	if f.ColorSource && src != "" {
+		src = colorSource.Sprint(src)
+	}
+
 	// Replace placeholders in the record format with actual values
 	return strings.NewReplacer(
 		"{time}", t,
-		"{level}", lvl.String(),
+		"{level}", level,
 		"{source}", src,
 		"{message}", m,
-	).Replace(f.RecordFormat)
+		"{fields}", flds,
+	).Replace(f.RecordFormat), message
+}
+
+// formatFields renders the sorted fields as text or JSON members.
+func (f *Formatter) formatFields(fields dictx.Dict) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		v := fields[k]
+		if fn, ok := v.(func() string); ok {
+			v = fn()
+		}
+		if f.JsonFields {
+			jk, _ := json.Marshal(k)
+			jv, err := json.Marshal(v)
+			if err != nil {
+				jv, _ = json.Marshal(fmt.Sprint(v))
+			}
+			b.WriteString("," + string(jk) + ":" + string(jv))
+			continue
+		}
+		s := fmt.Sprint(v)
+		if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+			s = strconv.Quote(s)
+		}
+		b.WriteString(" " + k + "=" + s)
+	}
+	return b.String()
 }
 
 // NewStdFormatter creates a standard text formatted log record.
@@ -100,8 +208,9 @@ func NewCustomTimeFormatter(tsFmt string) *Formatter {
 func NewJsonFormatter() *Formatter {
 	return &Formatter{
 		RecordFormat: `{"ts":"{time}","lvl":"{level}",` +
-			`"src":"{source}","msg":"{message}"}`,
+			`"src":"{source}","msg":"{message}"{fields}}`,
 		TimeFormat: "2006-01-02 15:04:05.000000",
 		EscapeMsg:  true,
+		JsonFields: true,
 	}
 }
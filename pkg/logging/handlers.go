@@ -13,6 +13,68 @@ type Handler interface {
 	HandleRecord(string) error
 }
 
+// LevelHandler interface for handlers processing log records with their
+// severity level, such as handlers mapping levels to native priorities.
+type LevelHandler interface {
+	Handler
+	HandleLevelRecord(Level, string) error
+}
+
+// Flusher interface for handlers buffering log records.
+type Flusher interface {
+	Flush() error
+}
+
+// FilterHandler wraps a handler with its own minimum level and an optional
+// filter predicate, so handlers of the same logger can handle different
+// records, such as writing debug records to file while only warnings go to
+// standard output. The logger level still applies before handler levels.
+// Records without level pass the level check.
+type FilterHandler struct {
+	Handler Handler           // Wrapped handler
+	Level   Level             // Minimum level of handled records
+	Filter  func(string) bool // Optional predicate selecting records
+}
+
+// NewFilterHandler creates a new filter handler for h, handling records
+// with lvl or higher levels and matching filter if not nil.
+func NewFilterHandler(h Handler, lvl Level, filter func(string) bool) *FilterHandler {
+	return &FilterHandler{
+		Handler: h,
+		Level:   lvl,
+		Filter:  filter,
+	}
+}
+
+// HandleRecord passes the record to the wrapped handler if it matches
+// the filter.
+func (h *FilterHandler) HandleRecord(record string) error {
+	if h.Filter != nil && !h.Filter(record) {
+		return nil
+	}
+	return h.Handler.HandleRecord(record)
+}
+
+// HandleLevelRecord passes the record to the wrapped handler if its level
+// is not lower than the handler level and it matches the filter.
+func (h *FilterHandler) HandleLevelRecord(lvl Level, record string) error {
+	if lvl < h.Level || (h.Filter != nil && !h.Filter(record)) {
+		return nil
+	}
+	if lh, ok := h.Handler.(LevelHandler); ok {
+		return lh.HandleLevelRecord(lvl, record)
+	}
+	return h.Handler.HandleRecord(record)
+}
+
+// Flush flushes the wrapped handler if it implements [Flusher].
+func (h *FilterHandler) Flush() error {
+	if f, ok := h.Handler.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
 // StdoutHandler writes log messages to standard output.
 type StdoutHandler struct{}
 
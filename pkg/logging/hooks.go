@@ -0,0 +1,76 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package logging
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+)
+
+// Record defines a log record passed to hooks.
+type Record struct {
+	Time    time.Time  // Record time
+	Level   Level      // Record level
+	Source  string     // Name of logger emitting the record
+	Message string     // Formatted message
+	Fields  dictx.Dict // Record fields
+	Text    string     // Formatted record
+}
+
+// Hook defines a function executed on log records.
+type Hook func(Record)
+
+type hook struct {
+	lvl Level
+	fn  Hook
+}
+
+// AddHook adds a hook executed on records with lvl or higher levels,
+// emitted by the logger or its child loggers. Hooks run synchronously
+// before the handlers, so they should return quickly, and can be used to
+// count errors, trigger alerts or forward selected events.
+func (l *Logger) AddHook(lvl Level, fn Hook) {
+	if fn != nil {
+		l.hooks = append(l.hooks, hook{lvl, fn})
+	}
+}
+
+// ClearHooks removes all hooks from the logger.
+func (l *Logger) ClearHooks() {
+	l.hooks = nil
+}
+
+// hooked returns whether any hook of logger or parents matches lvl.
+func (l *Logger) hooked(lvl Level) bool {
+	for p := l; p != nil; p = p.parent {
+		for _, h := range p.hooks {
+			if lvl >= h.lvl {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runHooks executes the matching hooks of logger.
+func (l *Logger) runHooks(rec Record) {
+	for _, h := range l.hooks {
+		if rec.Level < h.lvl {
+			continue
+		}
+		func() {
+			defer func() {
+				// hooks failures must not break logging
+				if r := recover(); r != nil {
+					fmt.Fprintf(os.Stderr, "log hook panic: %v\n", r)
+				}
+			}()
+			h.fn(rec)
+		}()
+	}
+}
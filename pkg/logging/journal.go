@@ -0,0 +1,119 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package logging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// JOURNAL_SOCKET defines the systemd-journald native protocol socket.
+const JOURNAL_SOCKET = "/run/systemd/journal/socket"
+
+// JournalHandler writes log messages to systemd-journald using its native
+// protocol, with the journal priority mapped from the log level, see
+// [SyslogSeverity]. Records without level are sent with info priority.
+// Not supported on windows.
+type JournalHandler struct {
+	// SocketPath defines the journald socket path.
+	SocketPath string
+	// Identifier defines the SYSLOG_IDENTIFIER field, (default is
+	// program name).
+	Identifier string
+	// Fields defines extra journal fields added to all records, where
+	// field names are uppercase letters, digits and underscores.
+	Fields map[string]string
+
+	conn *net.UnixConn
+	mu   sync.Mutex
+}
+
+// NewJournalHandler creates a new journald handler.
+func NewJournalHandler(identifier string) *JournalHandler {
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+	return &JournalHandler{
+		SocketPath: JOURNAL_SOCKET,
+		Identifier: identifier,
+	}
+}
+
+// JournalAvailable returns whether the journald socket is available.
+func JournalAvailable() bool {
+	_, err := os.Stat(JOURNAL_SOCKET)
+	return err == nil
+}
+
+// HandleRecord writes the log record with info priority.
+func (h *JournalHandler) HandleRecord(record string) error {
+	return h.HandleLevelRecord(INFO, record)
+}
+
+// HandleLevelRecord writes the log record with priority mapped from level.
+func (h *JournalHandler) HandleLevelRecord(lvl Level, record string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.conn == nil {
+		conn, err := net.DialUnix("unixgram", nil,
+			&net.UnixAddr{Name: h.SocketPath, Net: "unixgram"})
+		if err != nil {
+			return err
+		}
+		h.conn = conn
+	}
+
+	var b bytes.Buffer
+	writeJournalField(&b, "MESSAGE", strings.TrimRight(record, "\n"))
+	writeJournalField(&b, "PRIORITY", strconv.Itoa(SyslogSeverity(lvl)))
+	writeJournalField(&b, "SYSLOG_IDENTIFIER", h.Identifier)
+	keys := make([]string, 0, len(h.Fields))
+	for k := range h.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeJournalField(&b, k, h.Fields[k])
+	}
+
+	_, err := h.conn.Write(b.Bytes())
+	return err
+}
+
+// Close closes the journald connection.
+func (h *JournalHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.conn == nil {
+		return nil
+	}
+	err := h.conn.Close()
+	h.conn = nil
+	return err
+}
+
+// writeJournalField writes a journal field in native protocol format,
+// where values with newlines are written length prefixed.
+func writeJournalField(b *bytes.Buffer, key, value string) {
+	b.WriteString(key)
+	if !strings.Contains(value, "\n") {
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteByte('\n')
+	binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
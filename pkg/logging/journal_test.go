@@ -0,0 +1,48 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package logging_test
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/logging"
+)
+
+func TestJournalHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram",
+		&net.UnixAddr{Name: path, Net: "unixgram"})
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	h := logging.NewJournalHandler("app")
+	h.SocketPath = path
+	h.Fields = map[string]string{"DEVICE_ID": "dev1"}
+	defer h.Close()
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+
+	assert.NoError(t, h.HandleLevelRecord(logging.WARN, "message"))
+	n, err := conn.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "MESSAGE=message\nPRIORITY=4\n"+
+		"SYSLOG_IDENTIFIER=app\nDEVICE_ID=dev1\n", string(buf[:n]))
+
+	// multiline messages are length prefixed
+	assert.NoError(t, h.HandleRecord("line1\nline2"))
+	n, err = conn.Read(buf)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(buf[:n]),
+		"MESSAGE\n\x0b\x00\x00\x00\x00\x00\x00\x00line1\nline2\nPRIORITY=6\n"))
+}
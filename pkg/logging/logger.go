@@ -6,6 +6,9 @@ package logging
 
 import (
 	"errors"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
 )
 
 // Level defines the severity of a log event.
@@ -54,6 +57,8 @@ type Logger struct {
 	parent    *Logger    // Parent logger for inheritance
 	formatter *Formatter // Formatter for log messages
 	handlers  []Handler  // Handlers for processing log records
+	fields    dictx.Dict // Fields added to log records
+	hooks     []hook     // Hooks executed on log records
 }
 
 // NewStdoutLogger creates a new logger that outputs to standard output.
@@ -77,13 +82,14 @@ func NewFileLogger(name, path string) *Logger {
 }
 
 // ChildLogger creates new named child logger from parent logger.
-// child logger inherits the parent log [Level] and [Formatter].
+// child logger inherits the parent log [Level], [Formatter] and fields.
 func (l *Logger) ChildLogger(name string) *Logger {
 	return &Logger{
 		Name:      name,
 		parent:    l,
 		Level:     l.Level,
 		formatter: l.formatter,
+		fields:    l.fields,
 	}
 }
 
@@ -98,7 +104,31 @@ func (l *Logger) SubLogger(prefix string) *Logger {
 			RecordFormat: l.formatter.RecordFormat,
 			TimeFormat:   l.formatter.TimeFormat,
 			EscapeMsg:    l.formatter.EscapeMsg,
+			JsonFields:   l.formatter.JsonFields,
+			ColorLevel:   l.formatter.ColorLevel,
+			ColorSource:  l.formatter.ColorSource,
 		},
+		fields: l.fields,
+	}
+}
+
+// WithFields creates a new logger adding the fields to its log records,
+// merged with the logger fields. The new logger has the same name, level
+// and formatter, and passes records to the logger handlers.
+func (l *Logger) WithFields(fields dictx.Dict) *Logger {
+	merged := make(dictx.Dict, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &Logger{
+		Name:      l.Name,
+		parent:    l,
+		Level:     l.Level,
+		formatter: l.formatter,
+		fields:    merged,
 	}
 }
 
@@ -116,97 +146,148 @@ func (l *Logger) AddHandler(h Handler) {
 	}
 }
 
+// AddFilteredHandler adds a new handler to the logger, handling records
+// with lvl or higher levels and matching filter if not nil.
+// see [FilterHandler].
+func (l *Logger) AddFilteredHandler(h Handler, lvl Level, filter func(string) bool) {
+	if h != nil {
+		l.handlers = append(l.handlers, NewFilterHandler(h, lvl, filter))
+	}
+}
+
 // ClearHandlers removes all handlers from the logger.
 func (l *Logger) ClearHandlers() {
 	l.handlers = nil
 }
 
+// Flush flushes the logger handlers implementing [Flusher],
+// and the parent logger handlers.
+func (l *Logger) Flush() error {
+	var errAll error
+	for _, h := range l.handlers {
+		if f, ok := h.(Flusher); ok {
+			if err := f.Flush(); err != nil {
+				errAll = errors.Join(errAll, err)
+			}
+		}
+	}
+	if l.parent != nil {
+		if err := l.parent.Flush(); err != nil {
+			errAll = errors.Join(errAll, err)
+		}
+	}
+	return errAll
+}
+
+// Enabled reports whether the logger emits messages with the given level.
+// It can be used to guard expensive message construction code.
+func (l *Logger) Enabled(lvl Level) bool {
+	return l.Level <= lvl
+}
+
 // log processes the log message and sends it to all attached handlers.
-func (l *Logger) log(r string) error {
+// handlers implementing [LevelHandler] also receive the record level.
+// rec is the record passed to hooks, set only if hooks are matching.
+func (l *Logger) log(lvl Level, r string, rec *Record) error {
+	if rec != nil {
+		l.runHooks(*rec)
+	}
 	var errAll error
 	for _, h := range l.handlers {
-		if err := h.HandleRecord(r); err != nil {
+		var err error
+		if lh, ok := h.(LevelHandler); ok {
+			err = lh.HandleLevelRecord(lvl, r)
+		} else {
+			err = h.HandleRecord(r)
+		}
+		if err != nil {
 			// Combine errors
 			errAll = errors.Join(errAll, err)
 		}
 	}
 	// Propagate to parent logger
 	if l.parent != nil {
-		if err := l.parent.log(r); err != nil {
+		if err := l.parent.log(lvl, r, rec); err != nil {
 			errAll = errors.Join(errAll, err)
 		}
 	}
 	return errAll
 }
 
+// emit logs a message with level and extra fields merged with the
+// logger fields.
+func (l *Logger) emit(lvl Level, fields dictx.Dict, msg string, args ...any) error {
+	if l.Level > lvl {
+		return nil
+	}
+	if len(l.fields) > 0 && len(fields) > 0 {
+		merged := make(dictx.Dict, len(l.fields)+len(fields))
+		for k, v := range l.fields {
+			merged[k] = v
+		}
+		for k, v := range fields {
+			merged[k] = v
+		}
+		fields = merged
+	} else if len(fields) == 0 {
+		fields = l.fields
+	}
+	r, m := l.formatter.format(lvl, l.Name, fields, msg, args...)
+	var rec *Record
+	if l.hooked(lvl) {
+		rec = &Record{
+			Time:    time.Now(),
+			Level:   lvl,
+			Source:  l.Name,
+			Message: m,
+			Fields:  fields,
+			Text:    r,
+		}
+	}
+	return l.log(lvl, r, rec)
+}
+
 // Panic logs a message with Panic severity level.
 func (l *Logger) Panic(msg string, args ...any) error {
-	if l.Level <= PANIC {
-		return l.log(l.formatter.Emit(PANIC, l.Name, msg, args...))
-	}
-	return nil
+	return l.emit(PANIC, nil, msg, args...)
 }
 
 // Fatal logs a message with Fatal severity level.
 func (l *Logger) Fatal(msg string, args ...any) error {
-	if l.Level <= FATAL {
-		return l.log(l.formatter.Emit(FATAL, l.Name, msg, args...))
-	}
-	return nil
+	return l.emit(FATAL, nil, msg, args...)
 }
 
 // Error logs a message with Error severity level.
 func (l *Logger) Error(msg string, args ...any) error {
-	if l.Level <= ERROR {
-		return l.log(l.formatter.Emit(ERROR, l.Name, msg, args...))
-	}
-	return nil
+	return l.emit(ERROR, nil, msg, args...)
 }
 
 // Warn logs a message with Warn severity level.
 func (l *Logger) Warn(msg string, args ...any) error {
-	if l.Level <= WARN {
-		return l.log(l.formatter.Emit(WARN, l.Name, msg, args...))
-	}
-	return nil
+	return l.emit(WARN, nil, msg, args...)
 }
 
 // Info logs a message with Info severity level.
 func (l *Logger) Info(msg string, args ...any) error {
-	if l.Level <= INFO {
-		return l.log(l.formatter.Emit(INFO, l.Name, msg, args...))
-	}
-	return nil
+	return l.emit(INFO, nil, msg, args...)
 }
 
 // Debug logs a message with Debug severity level.
 func (l *Logger) Debug(msg string, args ...any) error {
-	if l.Level <= DEBUG {
-		return l.log(l.formatter.Emit(DEBUG, l.Name, msg, args...))
-	}
-	return nil
+	return l.emit(DEBUG, nil, msg, args...)
 }
 
 // Trace1 logs a message with Trace1 severity level.
 func (l *Logger) Trace1(msg string, args ...any) error {
-	if l.Level <= TRACE1 {
-		return l.log(l.formatter.Emit(TRACE1, l.Name, msg, args...))
-	}
-	return nil
+	return l.emit(TRACE1, nil, msg, args...)
 }
 
 // Trace2 logs a message with Trace2 severity level.
 func (l *Logger) Trace2(msg string, args ...any) error {
-	if l.Level <= TRACE2 {
-		return l.log(l.formatter.Emit(TRACE2, l.Name, msg, args...))
-	}
-	return nil
+	return l.emit(TRACE2, nil, msg, args...)
 }
 
 // Trace3 logs a message with Trace3 severity level.
 func (l *Logger) Trace3(msg string, args ...any) error {
-	if l.Level <= TRACE3 {
-		return l.log(l.formatter.Emit(TRACE3, l.Name, msg, args...))
-	}
-	return nil
+	return l.emit(TRACE3, nil, msg, args...)
 }
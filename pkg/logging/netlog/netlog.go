@@ -0,0 +1,118 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package netlog
+
+import (
+	"strings"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/comm"
+	"github.com/exonlabs/go-utils/pkg/comm/commutils"
+	"github.com/exonlabs/go-utils/pkg/events"
+	"github.com/exonlabs/go-utils/pkg/logging"
+)
+
+// RECONNECT_DELAY defines the default delay in seconds between
+// reconnect attempts.
+const RECONNECT_DELAY = 5
+
+// SEND_TIMEOUT defines the default connect and send timeout in seconds.
+const SEND_TIMEOUT = 5
+
+// Handler ships the log records to a remote collector over a comm
+// connection, one record per line. The records are queued and written
+// in background, see [logging.AsyncHandler], so logging never waits for
+// the network. While the collector is unreachable, the records stay
+// queued and the connection is retried periodically, where the oldest
+// records are dropped when the queue is full.
+type Handler struct {
+	*logging.AsyncHandler
+
+	// ReconnectDelay defines the delay in seconds between reconnect
+	// attempts.
+	ReconnectDelay float64
+	// SendTimeout defines the connect and send timeout in seconds.
+	SendTimeout float64
+
+	conn    comm.Connection
+	stopEvt *events.Event
+}
+
+// NewHandler creates a new network log handler for the collector uri,
+// using the comm URI scheme such as tcp@host:port or udp@host:port.
+// The connection is established on first record.
+// The parsed options are:
+//   - queue_size: (int) the max number of queued records.
+//     (default is [logging.ASYNC_QUEUE_SIZE])
+//   - overflow_block: (bool) block logging on full queue instead of
+//     dropping the oldest records. (default is false)
+//   - reconnect_delay: (float64) the delay in seconds between reconnect
+//     attempts. (default is 5)
+//   - send_timeout: (float64) the connect and send timeout in seconds.
+//     (default is 5)
+//
+// and the connection options, such as the TLS options for tcp
+// connections, see netcomm.GetTlsConfig.
+func NewHandler(uri string, opts dictx.Dict) (*Handler, error) {
+	// no traffic logging, the handler must not log to itself
+	conn, err := commutils.NewConnection(uri, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	h := &Handler{
+		ReconnectDelay: dictx.GetFloat(opts, "reconnect_delay", RECONNECT_DELAY),
+		SendTimeout:    dictx.GetFloat(opts, "send_timeout", SEND_TIMEOUT),
+		conn:           conn,
+		stopEvt:        events.New(),
+	}
+	policy := logging.DROP_OLDEST
+	if dictx.Fetch(opts, "overflow_block", false) {
+		policy = logging.BLOCK
+	}
+	h.AsyncHandler = logging.NewAsyncHandler(
+		&sender{h}, dictx.GetInt(opts, "queue_size", 0), policy)
+	return h, nil
+}
+
+// Close sends the queued records if connected and closes the connection.
+// Queued records are discarded if the collector is unreachable.
+func (h *Handler) Close() error {
+	h.stopEvt.Set()
+	err := h.AsyncHandler.Close()
+	h.conn.Close()
+	return err
+}
+
+// send writes the record, reconnecting until sent or handler closed.
+func (h *Handler) send(record string) error {
+	data := []byte(strings.TrimRight(record, "\n") + "\n")
+	for {
+		if !h.conn.IsOpened() {
+			if err := h.conn.Open(h.SendTimeout); err != nil {
+				if !h.stopEvt.Wait(h.ReconnectDelay) {
+					return err
+				}
+				continue
+			}
+		}
+		err := h.conn.Send(data, h.SendTimeout)
+		if err == nil {
+			return nil
+		}
+		h.conn.Close()
+		if h.stopEvt.IsSet() {
+			return err
+		}
+	}
+}
+
+// sender defines the wrapped handler of async handler.
+type sender struct {
+	h *Handler
+}
+
+func (s *sender) HandleRecord(record string) error {
+	return s.h.send(record)
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package netlog_test
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/logging"
+	"github.com/exonlabs/go-utils/pkg/logging/netlog"
+)
+
+// collect accepts one connection and sends the received lines.
+func collect(l net.Listener, lines chan<- string, conns chan<- net.Conn) {
+	conn, err := l.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conns <- conn
+	s := bufio.NewScanner(conn)
+	for s.Scan() {
+		lines <- s.Text()
+	}
+}
+
+func recvLine(t *testing.T, lines <-chan string) string {
+	select {
+	case line := <-lines:
+		return line
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for record")
+	}
+	return ""
+}
+
+func TestHandler(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	lines := make(chan string, 100)
+	conns := make(chan net.Conn, 2)
+	go collect(l, lines, conns)
+
+	h, err := netlog.NewHandler("tcp@"+addr, dictx.Dict{
+		"reconnect_delay": 0.1,
+	})
+	require.NoError(t, err)
+	defer h.Close()
+
+	logger := logging.NewStdoutLogger("main")
+	logger.SetFormatter(logging.NewRawFormatter())
+	logger.ClearHandlers()
+	logger.AddHandler(h)
+
+	logger.Info("first")
+	assert.Equal(t, "first", recvLine(t, lines))
+
+	// records are buffered while collector is down
+	l.Close()
+	(<-conns).Close()
+	time.Sleep(100 * time.Millisecond)
+	for _, m := range []string{"second", "third"} {
+		logger.Info(m)
+	}
+	time.Sleep(300 * time.Millisecond)
+
+	l, err = net.Listen("tcp", addr)
+	require.NoError(t, err)
+	defer l.Close()
+	go collect(l, lines, conns)
+
+	// the first record after disconnect may be lost in the broken
+	// connection before failure is detected
+	for recvLine(t, lines) != "third" {
+	}
+	assert.NoError(t, h.Flush())
+}
+
+func TestHandlerUdp(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	h, err := netlog.NewHandler("udp@"+pc.LocalAddr().String(), nil)
+	require.NoError(t, err)
+	assert.NoError(t, h.HandleRecord("message"))
+	assert.NoError(t, h.Flush())
+
+	buf := make([]byte, 1024)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "message\n", string(buf[:n]))
+	assert.NoError(t, h.Close())
+}
+
+func TestHandlerInvalidUri(t *testing.T) {
+	_, err := netlog.NewHandler("invalid", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownLogger indicates an unregistered logger name.
+var ErrUnknownLogger = errors.New("unknown logger")
+
+// DefaultRegistry is the default loggers registry.
+var DefaultRegistry = NewRegistry()
+
+// Name returns the level name, usable with [ParseLevel].
+func (l Level) Name() string {
+	switch {
+	case l <= TRACE3:
+		return "TRACE3"
+	case l == TRACE2:
+		return "TRACE2"
+	case l == TRACE1:
+		return "TRACE1"
+	}
+	return strings.TrimSpace(l.String())
+}
+
+// ParseLevel parses the level from its name or numeric value.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "TRACE3":
+		return TRACE3, nil
+	case "TRACE2":
+		return TRACE2, nil
+	case "TRACE1", "TRACE":
+		return TRACE1, nil
+	case "DEBUG":
+		return DEBUG, nil
+	case "INFO":
+		return INFO, nil
+	case "WARN", "WARNING":
+		return WARN, nil
+	case "ERROR":
+		return ERROR, nil
+	case "FATAL":
+		return FATAL, nil
+	case "PANIC":
+		return PANIC, nil
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(s)); err == nil &&
+		n >= int(TRACE3) && n <= int(PANIC) {
+		return Level(n), nil
+	}
+	return 0, fmt.Errorf("invalid log level %q", s)
+}
+
+// Registry defines a registry of named loggers, for controlling loggers
+// levels at runtime. Logger names are dotted paths, such as "main.comm"
+// for the comm child logger of main logger, where setting a logger level
+// also sets the levels of its registered descendants.
+type Registry struct {
+	loggers map[string]*Logger
+	mu      sync.RWMutex
+}
+
+// NewRegistry creates a new empty loggers registry.
+func NewRegistry() *Registry {
+	return &Registry{loggers: map[string]*Logger{}}
+}
+
+// Register adds the logger with name, replacing existing loggers.
+func (r *Registry) Register(name string, l *Logger) {
+	if name == "" || l == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.loggers[name] = l
+}
+
+// Unregister removes the named logger.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.loggers, name)
+}
+
+// Get returns the named logger, or nil if not registered.
+func (r *Registry) Get(name string) *Logger {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.loggers[name]
+}
+
+// Names returns the registered logger names sorted.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.loggers))
+	for n := range r.loggers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Levels returns the levels of registered loggers.
+func (r *Registry) Levels() map[string]Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make(map[string]Level, len(r.loggers))
+	for n, l := range r.loggers {
+		res[n] = l.Level
+	}
+	return res
+}
+
+// Level returns the named logger level.
+func (r *Registry) Level(name string) (Level, error) {
+	l := r.Get(name)
+	if l == nil {
+		return 0, fmt.Errorf("%w %s", ErrUnknownLogger, name)
+	}
+	return l.Level, nil
+}
+
+// SetLevel sets the level of named logger and its registered descendants,
+// and returns the names of updated loggers.
+func (r *Registry) SetLevel(name string, lvl Level) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, ok := r.loggers[name]; !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownLogger, name)
+	}
+	updated := []string{}
+	for n, l := range r.loggers {
+		if n == name || strings.HasPrefix(n, name+".") {
+			l.Level = lvl
+			updated = append(updated, n)
+		}
+	}
+	sort.Strings(updated)
+	return updated, nil
+}
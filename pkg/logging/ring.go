@@ -0,0 +1,127 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package logging
+
+import (
+	"errors"
+	"sync"
+)
+
+// RING_SIZE defines the default ring handler size.
+const RING_SIZE = 256
+
+// RingHandler wraps a handler keeping the last records below a pass level
+// in a memory ring, and dumps them to the wrapped handler when a record
+// with trigger level or higher is logged. This gives debug context of
+// failures without writing debug records constantly, where the logger
+// level is set to the lowest buffered level. Records with pass level or
+// higher and records without level are passed to the wrapped handler.
+type RingHandler struct {
+	handler Handler
+	pass    Level
+	trigger Level
+
+	ring  []ringRecord
+	start int
+	count int
+	mu    sync.Mutex
+}
+
+type ringRecord struct {
+	lvl    Level
+	record string
+}
+
+// NewRingHandler creates a new ring handler for h, keeping up to size
+// records below pass level, and dumping them on records with trigger
+// level or higher. Setting size=0 will use the default size [RING_SIZE].
+func NewRingHandler(h Handler, size int, pass, trigger Level) *RingHandler {
+	if size <= 0 {
+		size = RING_SIZE
+	}
+	return &RingHandler{
+		handler: h,
+		pass:    pass,
+		trigger: trigger,
+		ring:    make([]ringRecord, size),
+	}
+}
+
+// Len returns the number of buffered records.
+func (h *RingHandler) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+// HandleRecord passes the record to the wrapped handler.
+func (h *RingHandler) HandleRecord(record string) error {
+	return h.handler.HandleRecord(record)
+}
+
+// HandleLevelRecord buffers records below pass level, and passes other
+// records to the wrapped handler after dumping the buffered records if
+// the record level is trigger level or higher.
+func (h *RingHandler) HandleLevelRecord(lvl Level, record string) error {
+	if lvl < h.pass {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		i := (h.start + h.count) % len(h.ring)
+		h.ring[i] = ringRecord{lvl, record}
+		if h.count < len(h.ring) {
+			h.count++
+		} else {
+			h.start = (h.start + 1) % len(h.ring)
+		}
+		return nil
+	}
+
+	var errAll error
+	if lvl >= h.trigger {
+		errAll = h.Dump()
+	}
+	if err := h.write(lvl, record); err != nil {
+		errAll = errors.Join(errAll, err)
+	}
+	return errAll
+}
+
+// Dump writes the buffered records to the wrapped handler in order and
+// clears the ring.
+func (h *RingHandler) Dump() error {
+	h.mu.Lock()
+	records := make([]ringRecord, 0, h.count)
+	for i := 0; i < h.count; i++ {
+		records = append(records, h.ring[(h.start+i)%len(h.ring)])
+		h.ring[(h.start+i)%len(h.ring)] = ringRecord{}
+	}
+	h.start, h.count = 0, 0
+	h.mu.Unlock()
+
+	var errAll error
+	for _, r := range records {
+		if err := h.write(r.lvl, r.record); err != nil {
+			errAll = errors.Join(errAll, err)
+		}
+	}
+	return errAll
+}
+
+// Flush flushes the wrapped handler if it implements [Flusher], without
+// dumping the buffered records.
+func (h *RingHandler) Flush() error {
+	if f, ok := h.handler.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// write passes the record to the wrapped handler.
+func (h *RingHandler) write(lvl Level, record string) error {
+	if lh, ok := h.handler.(LevelHandler); ok {
+		return lh.HandleLevelRecord(lvl, record)
+	}
+	return h.handler.HandleRecord(record)
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build go1.21
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+)
+
+// SlogLevel returns the slog level mapped from log level.
+func SlogLevel(lvl Level) slog.Level {
+	switch {
+	case lvl >= FATAL:
+		return slog.LevelError + 4*slog.Level(lvl-ERROR)
+	case lvl == ERROR:
+		return slog.LevelError
+	case lvl == WARN:
+		return slog.LevelWarn
+	case lvl == INFO:
+		return slog.LevelInfo
+	case lvl == DEBUG:
+		return slog.LevelDebug
+	default:
+		return slog.LevelDebug + 4*slog.Level(lvl-DEBUG)
+	}
+}
+
+// FromSlogLevel returns the log level mapped from slog level.
+func FromSlogLevel(lvl slog.Level) Level {
+	switch {
+	case lvl >= slog.LevelError+8:
+		return PANIC
+	case lvl >= slog.LevelError+4:
+		return FATAL
+	case lvl >= slog.LevelError:
+		return ERROR
+	case lvl >= slog.LevelWarn:
+		return WARN
+	case lvl >= slog.LevelInfo:
+		return INFO
+	case lvl >= slog.LevelDebug:
+		return DEBUG
+	case lvl >= slog.LevelDebug-4:
+		return TRACE1
+	case lvl >= slog.LevelDebug-8:
+		return TRACE2
+	default:
+		return TRACE3
+	}
+}
+
+// SlogHandler exposes a logger as [slog.Handler], so libraries using the
+// standard structured logger share the logger level, formatter and
+// handlers. The record attributes are logged as logger fields, see
+// [Logger.WithFields], where grouped attributes keys are prefixed with
+// the dotted group names.
+type SlogHandler struct {
+	logger *Logger
+	fields dictx.Dict
+	prefix string
+}
+
+// NewSlogHandler creates a new slog handler logging to l.
+func NewSlogHandler(l *Logger) *SlogHandler {
+	return &SlogHandler{logger: l}
+}
+
+// NewSlogLogger creates a new slog logger logging to l.
+func NewSlogLogger(l *Logger) *slog.Logger {
+	return slog.New(NewSlogHandler(l))
+}
+
+// Enabled reports whether the logger emits records with the given level.
+func (h *SlogHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	return h.logger.Enabled(FromSlogLevel(lvl))
+}
+
+// Handle logs the record with its attributes as fields.
+func (h *SlogHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := make(dictx.Dict, len(h.fields)+r.NumAttrs())
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addSlogAttr(fields, h.prefix, a)
+		return true
+	})
+	return h.logger.emit(FromSlogLevel(r.Level), fields, "%s", r.Message)
+}
+
+// WithAttrs returns a new handler adding the attributes to records.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make(dictx.Dict, len(h.fields)+len(attrs))
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+	for _, a := range attrs {
+		addSlogAttr(fields, h.prefix, a)
+	}
+	return &SlogHandler{logger: h.logger, fields: fields, prefix: h.prefix}
+}
+
+// WithGroup returns a new handler prefixing the attributes keys with the
+// group name.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{
+		logger: h.logger, fields: h.fields, prefix: h.prefix + name + "."}
+}
+
+// addSlogAttr adds the resolved attribute to fields, flattening groups.
+func addSlogAttr(fields dictx.Dict, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			addSlogAttr(fields, prefix, ga)
+		}
+		return
+	}
+	fields[prefix+a.Key] = a.Value.Any()
+}
+
+// SlogForwarder forwards log records to a [slog.Handler], so loggers can
+// write to handlers of libraries using the standard structured logger.
+// The log records are sent as the slog record message, so loggers using
+// this handler are better used with [NewRawFormatter] formatter.
+type SlogForwarder struct {
+	handler slog.Handler
+}
+
+// NewSlogForwarder creates a new handler forwarding records to h.
+func NewSlogForwarder(h slog.Handler) *SlogForwarder {
+	return &SlogForwarder{handler: h}
+}
+
+// HandleRecord forwards the log record with info level.
+func (f *SlogForwarder) HandleRecord(record string) error {
+	return f.HandleLevelRecord(INFO, record)
+}
+
+// HandleLevelRecord forwards the log record with slog level mapped from
+// level.
+func (f *SlogForwarder) HandleLevelRecord(lvl Level, record string) error {
+	ctx := context.Background()
+	slvl := SlogLevel(lvl)
+	if !f.handler.Enabled(ctx, slvl) {
+		return nil
+	}
+	return f.handler.Handle(ctx, slog.NewRecord(time.Now(), slvl, record, 0))
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build go1.21
+
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/logging"
+)
+
+func TestSlogHandler(t *testing.T) {
+	sink := &recordHandler{}
+	logger := logging.NewStdoutLogger("main")
+	logger.SetFormatter(logging.NewRawFormatter())
+	logger.ClearHandlers()
+	logger.AddHandler(sink)
+
+	slogger := logging.NewSlogLogger(logger)
+	slogger.Debug("hidden")
+	slogger.Info("message 100%", "req", 12)
+	slogger.With("dev", "d1").WithGroup("conn").Warn("group",
+		"uri", "tcp@host", slog.Group("stats", "rx", 5))
+	assert.Equal(t, []string{
+		"message 100% req=12",
+		"group conn.stats.rx=5 conn.uri=tcp@host dev=d1",
+	}, sink.records)
+
+	logger.Level = logging.DEBUG
+	assert.True(t, slogger.Enabled(context.Background(), slog.LevelDebug))
+	assert.False(t, slogger.Enabled(context.Background(), slog.LevelDebug-8))
+}
+
+func TestSlogForwarder(t *testing.T) {
+	var buf bytes.Buffer
+	h := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	logger := logging.NewStdoutLogger("main")
+	logger.SetFormatter(logging.NewRawFormatter())
+	logger.ClearHandlers()
+	logger.AddHandler(logging.NewSlogForwarder(h))
+
+	logger.Warn("message")
+	logger.Debug("hidden")
+	assert.Equal(t, "level=WARN msg=message",
+		strings.TrimSpace(buf.String()))
+}
+
+func TestSlogLevels(t *testing.T) {
+	for _, lvl := range []logging.Level{
+		logging.TRACE3, logging.TRACE2, logging.TRACE1, logging.DEBUG,
+		logging.INFO, logging.WARN, logging.ERROR, logging.FATAL,
+		logging.PANIC,
+	} {
+		assert.Equal(t, lvl, logging.FromSlogLevel(logging.SlogLevel(lvl)))
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Syslog facilities.
+const (
+	LOG_KERN     = 0
+	LOG_USER     = 1
+	LOG_MAIL     = 2
+	LOG_DAEMON   = 3
+	LOG_AUTH     = 4
+	LOG_SYSLOG   = 5
+	LOG_LPR      = 6
+	LOG_NEWS     = 7
+	LOG_UUCP     = 8
+	LOG_CRON     = 9
+	LOG_AUTHPRIV = 10
+	LOG_FTP      = 11
+	LOG_LOCAL0   = 16
+	LOG_LOCAL1   = 17
+	LOG_LOCAL2   = 18
+	LOG_LOCAL3   = 19
+	LOG_LOCAL4   = 20
+	LOG_LOCAL5   = 21
+	LOG_LOCAL6   = 22
+	LOG_LOCAL7   = 23
+)
+
+// Syslog severities.
+const (
+	LOG_EMERG   = 0
+	LOG_ALERT   = 1
+	LOG_CRIT    = 2
+	LOG_ERR     = 3
+	LOG_WARNING = 4
+	LOG_NOTICE  = 5
+	LOG_INFO    = 6
+	LOG_DEBUG   = 7
+)
+
+// SyslogFormat defines the syslog message format.
+type SyslogFormat int
+
+const (
+	// RFC3164 defines the BSD syslog message format.
+	RFC3164 SyslogFormat = iota
+	// RFC5424 defines the IETF syslog message format.
+	RFC5424
+)
+
+// syslog local socket paths
+var syslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogSeverity returns the syslog severity mapped from log level.
+func SyslogSeverity(lvl Level) int {
+	switch {
+	case lvl >= FATAL:
+		return LOG_CRIT
+	case lvl == ERROR:
+		return LOG_ERR
+	case lvl == WARN:
+		return LOG_WARNING
+	case lvl == INFO:
+		return LOG_INFO
+	default:
+		return LOG_DEBUG
+	}
+}
+
+// SyslogHandler writes log messages to local or remote syslog server.
+// The log records are sent as the syslog message, so loggers using this
+// handler are better used with [NewRawFormatter] formatter, leaving the
+// timestamp and severity to syslog. Records without level are sent with
+// info severity.
+type SyslogHandler struct {
+	// Network defines the connection network, one of "udp", "tcp", "unix"
+	// or "unixgram". Empty network with empty address connects to the
+	// local syslog socket.
+	Network string
+	// Address defines the syslog server address or socket path.
+	Address string
+	// Facility defines the syslog facility.
+	Facility int
+	// Tag defines the application name, (default is program name).
+	Tag string
+	// Format defines the syslog message format.
+	Format SyslogFormat
+
+	hostname string
+	local    bool
+	stream   bool
+	conn     net.Conn
+	mu       sync.Mutex
+}
+
+// NewSyslogHandler creates a new syslog handler. Setting empty network
+// and address uses the local syslog socket.
+func NewSyslogHandler(network, address string, facility int,
+	tag string, format SyslogFormat) *SyslogHandler {
+	if tag == "" {
+		tag = filepath.Base(os.Args[0])
+	}
+	hostname, _ := os.Hostname()
+	return &SyslogHandler{
+		Network:  network,
+		Address:  address,
+		Facility: facility,
+		Tag:      tag,
+		Format:   format,
+		hostname: hostname,
+	}
+}
+
+// HandleRecord writes the log record with info severity.
+func (h *SyslogHandler) HandleRecord(record string) error {
+	return h.HandleLevelRecord(INFO, record)
+}
+
+// HandleLevelRecord writes the log record with severity mapped from level.
+func (h *SyslogHandler) HandleLevelRecord(lvl Level, record string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// retry once on broken connection
+	var err error
+	for i := 0; i < 2; i++ {
+		if h.conn == nil {
+			if err = h.connect(); err != nil {
+				return err
+			}
+		}
+		if _, err = h.conn.Write(h.message(lvl, record)); err == nil {
+			return nil
+		}
+		h.conn.Close()
+		h.conn = nil
+	}
+	return err
+}
+
+// Close closes the syslog connection.
+func (h *SyslogHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.conn == nil {
+		return nil
+	}
+	err := h.conn.Close()
+	h.conn = nil
+	return err
+}
+
+// connect opens the syslog connection.
+func (h *SyslogHandler) connect() error {
+	if h.Network != "" || h.Address != "" {
+		conn, err := net.Dial(h.Network, h.Address)
+		if err != nil {
+			return err
+		}
+		h.conn = conn
+		h.local = strings.HasPrefix(h.Network, "unix")
+		h.stream = strings.HasPrefix(h.Network, "tcp") || h.Network == "unix"
+		return nil
+	}
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range syslogSockets {
+			if conn, err := net.Dial(network, path); err == nil {
+				h.conn = conn
+				h.local = true
+				h.stream = network == "unix"
+				return nil
+			}
+		}
+	}
+	return errors.New("local syslog socket not available")
+}
+
+// message builds the syslog message.
+func (h *SyslogHandler) message(lvl Level, record string) []byte {
+	pri := h.Facility<<3 | SyslogSeverity(lvl)
+	record = strings.TrimRight(record, "\n")
+	now := time.Now()
+
+	var msg string
+	if h.Format == RFC5424 {
+		hostname := h.hostname
+		if hostname == "" {
+			hostname = "-"
+		}
+		msg = fmt.Sprintf("<%d>1 %s %s %s %d - - %s", pri,
+			now.Format("2006-01-02T15:04:05.000000Z07:00"),
+			hostname, h.Tag, os.Getpid(), record)
+	} else if h.local {
+		// local sockets don't expect the hostname field
+		msg = fmt.Sprintf("<%d>%s %s[%d]: %s", pri,
+			now.Format(time.Stamp), h.Tag, os.Getpid(), record)
+	} else {
+		msg = fmt.Sprintf("<%d>%s %s %s[%d]: %s", pri,
+			now.Format(time.Stamp), h.hostname, h.Tag, os.Getpid(), record)
+	}
+	// stream connections are newline framed
+	if h.stream {
+		msg += "\n"
+	}
+	return []byte(msg)
+}
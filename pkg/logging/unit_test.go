@@ -5,12 +5,22 @@
 package logging_test
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
 	"github.com/exonlabs/go-utils/pkg/logging"
 )
 
@@ -157,3 +167,469 @@ func TestFormatterEmit(t *testing.T) {
 		})
 	}
 }
+
+func TestLoggerEnabledAndLazy(t *testing.T) {
+	handler := new(MockHandler)
+	logger := &logging.Logger{Name: "TestLogger", Level: logging.INFO}
+	logger.SetFormatter(logging.NewRawFormatter())
+	logger.AddHandler(handler)
+
+	assert.True(t, logger.Enabled(logging.ERROR))
+	assert.True(t, logger.Enabled(logging.INFO))
+	assert.False(t, logger.Enabled(logging.DEBUG))
+
+	// deferred arguments are not evaluated for inactive levels
+	calls := 0
+	lazy := func() string {
+		calls++
+		return "lazy value"
+	}
+	assert.NoError(t, logger.Debug("value: %s", lazy))
+	assert.NoError(t, logger.Trace1("value: %s", logging.Lazy(lazy)))
+	assert.Equal(t, 0, calls)
+
+	// deferred arguments are evaluated for active levels
+	handler.On("HandleRecord", "value: lazy value").Return(nil).Twice()
+	assert.NoError(t, logger.Info("value: %s", lazy))
+	assert.NoError(t, logger.Warn("value: %s", logging.Lazy(lazy)))
+	assert.Equal(t, 2, calls)
+	handler.AssertExpectations(t)
+
+	// caller arguments are not modified
+	args := []any{lazy}
+	handler.On("HandleRecord", "value: lazy value").Return(nil).Once()
+	assert.NoError(t, logger.Info("value: %s", args...))
+	_, isLazy := args[0].(logging.Lazy)
+	assert.False(t, isLazy)
+}
+
+type flushHandler struct {
+	MockHandler
+	flushed int
+}
+
+func (h *flushHandler) Flush() error {
+	h.flushed++
+	return nil
+}
+
+func TestLoggerFlush(t *testing.T) {
+	parent := &logging.Logger{Name: "Parent"}
+	handler := &flushHandler{}
+	parent.AddHandler(handler)
+	parent.AddHandler(new(MockHandler))
+
+	child := parent.ChildLogger("Child")
+	assert.NoError(t, child.Flush())
+	assert.Equal(t, 1, handler.flushed)
+}
+
+type slowHandler struct {
+	mu      sync.Mutex
+	records []string
+	delay   time.Duration
+	err     error
+}
+
+func (h *slowHandler) HandleRecord(record string) error {
+	time.Sleep(h.delay)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, record)
+	return h.err
+}
+
+func (h *slowHandler) Records() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.records...)
+}
+
+func TestAsyncHandler(t *testing.T) {
+	t.Run("FlushOrder", func(t *testing.T) {
+		sink := &slowHandler{}
+		h := logging.NewAsyncHandler(sink, 0, logging.BLOCK)
+		for i := 0; i < 100; i++ {
+			assert.NoError(t, h.HandleRecord(strconv.Itoa(i)))
+		}
+		assert.NoError(t, h.Flush())
+		records := sink.Records()
+		assert.Len(t, records, 100)
+		assert.Equal(t, "0", records[0])
+		assert.Equal(t, "99", records[99])
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("DropOldest", func(t *testing.T) {
+		sink := &slowHandler{delay: 10 * time.Millisecond}
+		h := logging.NewAsyncHandler(sink, 4, logging.DROP_OLDEST)
+		tStart := time.Now()
+		for i := 0; i < 50; i++ {
+			assert.NoError(t, h.HandleRecord(strconv.Itoa(i)))
+		}
+		// logging never waits for the slow sink
+		assert.Less(t, time.Since(tStart), 100*time.Millisecond)
+		assert.NoError(t, h.Close())
+		records := sink.Records()
+		assert.Equal(t, uint64(50-len(records)), h.Dropped())
+		assert.Greater(t, h.Dropped(), uint64(0))
+		assert.Equal(t, "49", records[len(records)-1])
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		sink := &slowHandler{delay: time.Millisecond}
+		h := logging.NewAsyncHandler(sink, 2, logging.BLOCK)
+		for i := 0; i < 20; i++ {
+			assert.NoError(t, h.HandleRecord(strconv.Itoa(i)))
+		}
+		assert.NoError(t, h.Close())
+		assert.Len(t, sink.Records(), 20)
+		assert.Equal(t, uint64(0), h.Dropped())
+	})
+
+	t.Run("ErrorsAndClose", func(t *testing.T) {
+		sink := &slowHandler{err: errors.New("write failed")}
+		h := logging.NewAsyncHandler(sink, 0, logging.DROP_OLDEST)
+		assert.NoError(t, h.HandleRecord("a"))
+		assert.EqualError(t, h.Flush(), "write failed")
+		assert.NoError(t, h.Flush())
+		assert.NoError(t, h.Close())
+		assert.ErrorIs(t, h.HandleRecord("b"), logging.ErrHandlerClosed)
+	})
+
+	t.Run("LoggerFlush", func(t *testing.T) {
+		sink := &slowHandler{delay: time.Millisecond}
+		h := logging.NewAsyncHandler(sink, 0, logging.BLOCK)
+		logger := logging.NewStdoutLogger("Async")
+		logger.ClearHandlers()
+		logger.AddHandler(h)
+		logger.Info("message")
+		assert.NoError(t, logger.Flush())
+		assert.Len(t, sink.Records(), 1)
+		assert.NoError(t, h.Close())
+	})
+}
+
+func TestSyslogHandler(t *testing.T) {
+	t.Run("RFC3164Udp", func(t *testing.T) {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		defer pc.Close()
+
+		h := logging.NewSyslogHandler("udp", pc.LocalAddr().String(),
+			logging.LOG_LOCAL0, "app", logging.RFC3164)
+		defer h.Close()
+		assert.NoError(t, h.HandleLevelRecord(logging.ERROR, "message"))
+
+		buf := make([]byte, 1024)
+		pc.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		assert.NoError(t, err)
+		msg := string(buf[:n])
+		// local0 facility (16) and err severity (3)
+		assert.True(t, strings.HasPrefix(msg, "<131>"), msg)
+		assert.True(t, strings.HasSuffix(msg,
+			fmt.Sprintf(" app[%d]: message", os.Getpid())), msg)
+	})
+
+	t.Run("RFC5424Tcp", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		defer l.Close()
+
+		h := logging.NewSyslogHandler("tcp", l.Addr().String(),
+			logging.LOG_DAEMON, "app", logging.RFC5424)
+		defer h.Close()
+		assert.NoError(t, h.HandleRecord("first"))
+		assert.NoError(t, h.HandleLevelRecord(logging.DEBUG, "second"))
+
+		conn, err := l.Accept()
+		assert.NoError(t, err)
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		r := bufio.NewReader(conn)
+		line, err := r.ReadString('\n')
+		assert.NoError(t, err)
+		// daemon facility (3) and info severity (6)
+		assert.True(t, strings.HasPrefix(line, "<30>1 "), line)
+		assert.True(t, strings.HasSuffix(line,
+			fmt.Sprintf(" app %d - - first\n", os.Getpid())), line)
+		line, err = r.ReadString('\n')
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(line, "<31>1 "), line)
+	})
+
+	t.Run("Severity", func(t *testing.T) {
+		assert.Equal(t, logging.LOG_CRIT, logging.SyslogSeverity(logging.PANIC))
+		assert.Equal(t, logging.LOG_CRIT, logging.SyslogSeverity(logging.FATAL))
+		assert.Equal(t, logging.LOG_WARNING, logging.SyslogSeverity(logging.WARN))
+		assert.Equal(t, logging.LOG_DEBUG, logging.SyslogSeverity(logging.TRACE2))
+	})
+}
+
+func TestLevelHandler(t *testing.T) {
+	sink := &levelHandler{}
+	logger := logging.NewStdoutLogger("Parent")
+	logger.ClearHandlers()
+	logger.AddHandler(sink)
+	child := logger.ChildLogger("Child")
+	child.Warn("message")
+	assert.Equal(t, []logging.Level{logging.WARN}, sink.levels)
+
+	// levels are kept through async handler
+	sink = &levelHandler{}
+	h := logging.NewAsyncHandler(sink, 0, logging.BLOCK)
+	logger.ClearHandlers()
+	logger.AddHandler(h)
+	logger.Error("message")
+	assert.NoError(t, h.Close())
+	assert.Equal(t, []logging.Level{logging.ERROR}, sink.levels)
+}
+
+type levelHandler struct {
+	levels []logging.Level
+}
+
+func (h *levelHandler) HandleRecord(record string) error {
+	return errors.New("level expected")
+}
+
+func (h *levelHandler) HandleLevelRecord(lvl logging.Level, record string) error {
+	h.levels = append(h.levels, lvl)
+	return nil
+}
+
+type recordHandler struct {
+	records []string
+}
+
+func (h *recordHandler) HandleRecord(record string) error {
+	h.records = append(h.records, record)
+	return nil
+}
+
+func TestLoggerWithFields(t *testing.T) {
+	t.Run("Text", func(t *testing.T) {
+		sink := &recordHandler{}
+		logger := logging.NewStdoutLogger("main")
+		logger.SetFormatter(logging.NewRawFormatter())
+		logger.ClearHandlers()
+		logger.AddHandler(sink)
+
+		log := logger.WithFields(dictx.Dict{"req": 12, "uri": "tcp@host:80"})
+		log = log.WithFields(dictx.Dict{"dev": "sensor 1"})
+		log.Info("message %d", 1)
+		logger.Info("no fields")
+		log.ChildLogger("child").Warn("child")
+		assert.Equal(t, []string{
+			`message 1 dev="sensor 1" req=12 uri=tcp@host:80`,
+			"no fields",
+			`child dev="sensor 1" req=12 uri=tcp@host:80`,
+		}, sink.records)
+	})
+
+	t.Run("Placeholder", func(t *testing.T) {
+		f := logging.NewCustomMsgFormatter("{level} [{source}]{fields} {message}")
+		assert.Equal(t, "INFO  [src] a=1 message",
+			f.EmitFields(logging.INFO, "src", dictx.Dict{"a": 1}, "message"))
+		assert.Equal(t, "INFO  [src] message",
+			f.Emit(logging.INFO, "src", "message"))
+	})
+
+	t.Run("Json", func(t *testing.T) {
+		sink := &recordHandler{}
+		logger := logging.NewStdoutLogger("main")
+		f := logging.NewJsonFormatter()
+		f.TimeFormat = "-"
+		logger.SetFormatter(f)
+		logger.ClearHandlers()
+		logger.AddHandler(sink)
+
+		logger.WithFields(dictx.Dict{
+			"req": 12, "dev": `say "hi"`, "ok": true,
+		}).Info("message")
+		assert.Equal(t, `{"ts":"-","lvl":"INFO ","src":"main",`+
+			`"msg":"message","dev":"say \"hi\"","ok":true,"req":12}`,
+			sink.records[0])
+
+		var rec map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(sink.records[0]), &rec))
+		assert.Equal(t, float64(12), rec["req"])
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	reg := logging.NewRegistry()
+	main := logging.NewStdoutLogger("main")
+	comm := main.ChildLogger("comm")
+	conn := comm.ChildLogger("conn")
+	other := logging.NewStdoutLogger("maintenance")
+	reg.Register("main", main)
+	reg.Register("main.comm", comm)
+	reg.Register("main.comm.conn", conn)
+	reg.Register("maintenance", other)
+
+	assert.Equal(t, []string{
+		"main", "main.comm", "main.comm.conn", "maintenance"}, reg.Names())
+	assert.Equal(t, comm, reg.Get("main.comm"))
+	assert.Nil(t, reg.Get("none"))
+
+	updated, err := reg.SetLevel("main.comm", logging.DEBUG)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"main.comm", "main.comm.conn"}, updated)
+	assert.Equal(t, logging.DEBUG, conn.Level)
+	assert.Equal(t, logging.INFO, main.Level)
+
+	updated, err = reg.SetLevel("main", logging.ERROR)
+	assert.NoError(t, err)
+	assert.Len(t, updated, 3)
+	assert.Equal(t, logging.INFO, other.Level)
+
+	lvl, err := reg.Level("main.comm.conn")
+	assert.NoError(t, err)
+	assert.Equal(t, logging.ERROR, lvl)
+
+	_, err = reg.SetLevel("none", logging.INFO)
+	assert.ErrorIs(t, err, logging.ErrUnknownLogger)
+
+	reg.Unregister("maintenance")
+	assert.Len(t, reg.Levels(), 3)
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, lvl := range []logging.Level{
+		logging.TRACE3, logging.TRACE2, logging.TRACE1, logging.DEBUG,
+		logging.INFO, logging.WARN, logging.ERROR, logging.FATAL,
+		logging.PANIC,
+	} {
+		parsed, err := logging.ParseLevel(lvl.Name())
+		assert.NoError(t, err)
+		assert.Equal(t, lvl, parsed)
+	}
+	lvl, err := logging.ParseLevel("warning")
+	assert.NoError(t, err)
+	assert.Equal(t, logging.WARN, lvl)
+	lvl, err = logging.ParseLevel("-1")
+	assert.NoError(t, err)
+	assert.Equal(t, logging.DEBUG, lvl)
+	_, err = logging.ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestFilterHandler(t *testing.T) {
+	file := &recordHandler{}
+	stdout := &recordHandler{}
+	logger := logging.NewStdoutLogger("main")
+	logger.Level = logging.DEBUG
+	logger.SetFormatter(logging.NewRawFormatter())
+	logger.ClearHandlers()
+	logger.AddHandler(file)
+	logger.AddFilteredHandler(stdout, logging.WARN, nil)
+
+	logger.Debug("debug")
+	logger.Warn("warn")
+	logger.ChildLogger("child").Error("error")
+	assert.Equal(t, []string{"debug", "warn", "error"}, file.records)
+	assert.Equal(t, []string{"warn", "error"}, stdout.records)
+
+	// filter predicate
+	audit := &recordHandler{}
+	h := logging.NewFilterHandler(audit, logging.TRACE3,
+		func(r string) bool { return strings.HasPrefix(r, "audit:") })
+	logger.ClearHandlers()
+	logger.AddHandler(h)
+	logger.Info("audit: login")
+	logger.Info("other")
+	assert.NoError(t, h.HandleRecord("audit: no level"))
+	assert.Equal(t, []string{"audit: login", "audit: no level"}, audit.records)
+
+	// levels are passed to wrapped level handlers
+	sink := &levelHandler{}
+	logger.ClearHandlers()
+	logger.AddFilteredHandler(sink, logging.INFO, nil)
+	logger.Debug("debug")
+	logger.Info("info")
+	assert.Equal(t, []logging.Level{logging.INFO}, sink.levels)
+}
+
+func TestColorFormatter(t *testing.T) {
+	f := logging.NewColorFormatter()
+	// test output is not a terminal
+	assert.False(t, f.ColorLevel)
+	assert.False(t, f.ColorSource)
+
+	f = logging.NewCustomMsgFormatter("{level} [{source}] {message}")
+	f.ColorLevel = true
+	assert.Equal(t, "\x1b[31mERROR\x1b[0m [src] message",
+		f.Emit(logging.ERROR, "src", "message"))
+	f.ColorSource = true
+	assert.Equal(t, "\x1b[33mWARN \x1b[0m [\x1b[34msrc\x1b[0m] message",
+		f.Emit(logging.WARN, "src", "message"))
+
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, logging.ColorEnabled())
+}
+
+func TestLoggerHooks(t *testing.T) {
+	logger := logging.NewStdoutLogger("main")
+	logger.SetFormatter(logging.NewRawFormatter())
+	logger.ClearHandlers()
+	logger.AddHandler(&recordHandler{})
+
+	errCount := 0
+	var fatal []logging.Record
+	logger.AddHook(logging.ERROR, func(r logging.Record) { errCount++ })
+	logger.AddHook(logging.FATAL, func(r logging.Record) {
+		fatal = append(fatal, r)
+	})
+	logger.AddHook(logging.PANIC, func(r logging.Record) { panic("hook") })
+
+	child := logger.ChildLogger("child").WithFields(dictx.Dict{"dev": 1})
+	logger.Warn("warn")
+	logger.Error("error")
+	child.Fatal("fatal %d%%", 100)
+	assert.NoError(t, logger.Panic("panic"))
+
+	assert.Equal(t, 3, errCount)
+	assert.Len(t, fatal, 2)
+	assert.Equal(t, "child", fatal[0].Source)
+	assert.Equal(t, logging.FATAL, fatal[0].Level)
+	assert.Equal(t, "fatal 100%", fatal[0].Message)
+	assert.Equal(t, "fatal 100% dev=1", fatal[0].Text)
+	assert.Equal(t, dictx.Dict{"dev": 1}, fatal[0].Fields)
+
+	logger.ClearHooks()
+	logger.Error("error")
+	assert.Equal(t, 3, errCount)
+}
+
+func TestRingHandler(t *testing.T) {
+	sink := &recordHandler{}
+	h := logging.NewRingHandler(sink, 3, logging.INFO, logging.ERROR)
+	logger := logging.NewStdoutLogger("main")
+	logger.Level = logging.TRACE3
+	logger.SetFormatter(logging.NewRawFormatter())
+	logger.ClearHandlers()
+	logger.AddHandler(h)
+
+	for i := 1; i <= 5; i++ {
+		logger.Debug("debug %d", i)
+	}
+	logger.Info("info")
+	assert.Equal(t, []string{"info"}, sink.records)
+	assert.Equal(t, 3, h.Len())
+
+	// last records are dumped before the error record
+	logger.Trace1("trace")
+	logger.Error("error")
+	assert.Equal(t, []string{
+		"info", "debug 4", "debug 5", "trace", "error"}, sink.records)
+	assert.Equal(t, 0, h.Len())
+
+	logger.Error("error 2")
+	assert.Equal(t, "error 2", sink.records[5])
+
+	logger.Debug("debug")
+	assert.NoError(t, h.Dump())
+	assert.Equal(t, "debug", sink.records[6])
+}
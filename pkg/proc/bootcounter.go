@@ -0,0 +1,121 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/abc/fsx"
+)
+
+const (
+	// BOOT_MAX_CRASHES defines the default number of unclean starts
+	// within the crash window that triggers safe mode.
+	BOOT_MAX_CRASHES = 5
+	// BOOT_CRASH_WINDOW defines the default crash window in seconds.
+	BOOT_CRASH_WINDOW = 300
+)
+
+// bootEntry represents a recorded process start.
+type bootEntry struct {
+	Pid  int   `json:"pid"`
+	Time int64 `json:"time"`
+}
+
+// BootCounter tracks the process starts in a persisted file to detect
+// rapid restart loops. Each start is recorded and removed on clean stop,
+// so the remaining records within the crash window are unclean starts.
+type BootCounter struct {
+	// Path defines the boot counter file path.
+	Path string
+	// MaxCrashes defines the number of unclean starts within the crash
+	// window that triggers safe mode.
+	MaxCrashes int
+	// Window defines the crash window in seconds.
+	Window float64
+
+	// boot holds the current process start record.
+	boot bootEntry
+}
+
+// NewBootCounter creates a new boot counter persisted in path.
+// The parsed options are:
+//   - boot_max_crashes: (int) the number of unclean starts within the
+//     crash window that triggers safe mode. (default is 5)
+//   - boot_crash_window: (float64) the crash window in seconds.
+//     (default is 300)
+func NewBootCounter(path string, opts dictx.Dict) *BootCounter {
+	b := &BootCounter{
+		Path:       path,
+		MaxCrashes: dictx.GetInt(opts, "boot_max_crashes", BOOT_MAX_CRASHES),
+		Window: dictx.GetFloat(
+			opts, "boot_crash_window", BOOT_CRASH_WINDOW),
+	}
+	if b.MaxCrashes <= 0 {
+		b.MaxCrashes = BOOT_MAX_CRASHES
+	}
+	if b.Window <= 0 {
+		b.Window = BOOT_CRASH_WINDOW
+	}
+	return b
+}
+
+// load reads the recorded starts within the crash window.
+func (b *BootCounter) load() []bootEntry {
+	entries := []bootEntry{}
+	if d, err := os.ReadFile(b.Path); err == nil {
+		json.Unmarshal(d, &entries)
+	}
+	tMin := time.Now().Add(
+		-time.Duration(b.Window * float64(time.Second))).Unix()
+	res := []bootEntry{}
+	for _, e := range entries {
+		if e.Time >= tMin {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// save writes the recorded starts.
+func (b *BootCounter) save(entries []bootEntry) error {
+	d, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return fsx.WriteFileAtomic(b.Path, d, 0o664)
+}
+
+// Register records the current process start and returns the number of
+// previous unclean starts within the crash window.
+func (b *BootCounter) Register() (int, error) {
+	entries := b.load()
+	b.boot = bootEntry{Pid: os.Getpid(), Time: time.Now().Unix()}
+	return len(entries), b.save(append(entries, b.boot))
+}
+
+// IsCrashLoop checks if the number of unclean starts reached MaxCrashes.
+func (b *BootCounter) IsCrashLoop(crashes int) bool {
+	return crashes >= b.MaxCrashes
+}
+
+// Clear removes the current process start record, marking a clean stop.
+func (b *BootCounter) Clear() error {
+	res := []bootEntry{}
+	for _, e := range b.load() {
+		if e != b.boot {
+			res = append(res, e)
+		}
+	}
+	return b.save(res)
+}
+
+// Reset removes all recorded starts.
+func (b *BootCounter) Reset() error {
+	return b.save([]bootEntry{})
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/comm"
+	"github.com/exonlabs/go-utils/pkg/comm/commutils"
+	"github.com/exonlabs/go-utils/pkg/logging"
+)
+
+// CLIENT_TIMEOUT defines the default manager client call timeout in seconds.
+const CLIENT_TIMEOUT = 5
+
+// ErrReply indicates a failed command reply.
+var ErrReply = errors.New("command failed")
+
+// ManagerClient defines the client side of the process command handling,
+// see [Process.SetCmdHandler]. Each call connects to the process command
+// listener, sends the command request and waits for its reply line.
+type ManagerClient struct {
+	// Log is the logger instance.
+	Log *logging.Logger
+	// Uri defines the process command listener uri.
+	Uri string
+	// Options defines the connection options.
+	Options dictx.Dict
+}
+
+// NewManagerClient creates a new manager client for the process command
+// listener at uri. The options are passed to the connection, see
+// [commutils.NewConnection].
+func NewManagerClient(
+	uri string, log *logging.Logger, opts ...dictx.Dict) *ManagerClient {
+	c := &ManagerClient{Log: log, Uri: uri}
+	if len(opts) > 0 {
+		c.Options = opts[0]
+	}
+	return c
+}
+
+// Call sends the command with arguments encoded as JSON and returns the
+// parsed reply. Structured replies, see [CommandRegistry], return the reply
+// result or [ErrReply] with the reply error, and other replies are returned
+// as string. Setting timeout=0 will use the default timeout [CLIENT_TIMEOUT].
+func (c *ManagerClient) Call(
+	cmd string, args dictx.Dict, timeout float64) (any, error) {
+	req := cmd
+	if len(args) > 0 {
+		b, err := json.Marshal(args)
+		if err != nil {
+			return nil, err
+		}
+		req += " " + string(b)
+	}
+
+	reply, err := c.CallRaw(req, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(reply, "{") {
+		return reply, nil
+	}
+	var res CmdReply
+	if err := json.Unmarshal([]byte(reply), &res); err != nil {
+		return reply, nil
+	}
+	if !res.Ok {
+		return nil, fmt.Errorf("%w: %s", ErrReply, res.Error)
+	}
+	return res.Result, nil
+}
+
+// CallRaw sends the raw command request and returns the raw reply line.
+// Setting timeout=0 will use the default timeout [CLIENT_TIMEOUT].
+func (c *ManagerClient) CallRaw(request string, timeout float64) (string, error) {
+	if timeout <= 0 {
+		timeout = CLIENT_TIMEOUT
+	}
+	tBreak := time.Now().Add(time.Duration(timeout * float64(time.Second)))
+	remaining := func() float64 {
+		return time.Until(tBreak).Seconds()
+	}
+
+	conn, err := commutils.NewConnection(c.Uri, c.Log, c.Options)
+	if err != nil {
+		return "", err
+	}
+	if err := conn.Open(timeout); err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if err := conn.Send([]byte(request+"\n"), remaining()); err != nil {
+		return "", err
+	}
+
+	var buf []byte
+	for {
+		t := remaining()
+		if t <= 0 {
+			return "", comm.ErrTimeout
+		}
+		b, err := conn.Recv(t)
+		if err != nil {
+			return "", err
+		}
+		buf = append(buf, b...)
+		if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+			return strings.TrimSpace(string(buf[:i])), nil
+		}
+	}
+}
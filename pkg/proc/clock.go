@@ -0,0 +1,79 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/events"
+	"github.com/exonlabs/go-utils/pkg/sync/timerx"
+)
+
+// Clock defines the time source and sleeping interface.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Sleep pauses for timeout seconds, or until any of the events is set.
+	// It returns true if the timeout elapsed, or false if interrupted.
+	Sleep(timeout float64, evts ...*events.Event) bool
+}
+
+// SystemClock defines the clock based on the system time.
+type SystemClock struct{}
+
+// Now returns the current system time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep pauses for timeout seconds, or until any of the events is set.
+func (SystemClock) Sleep(timeout float64, evts ...*events.Event) bool {
+	return timerx.Sleep(timeout, evts...)
+}
+
+// FakeClock defines a manually controlled clock for tests.
+type FakeClock struct {
+	now time.Time
+	mu  sync.Mutex
+}
+
+// NewFakeClock creates a new fake clock set at t.
+func NewFakeClock(t time.Time) *FakeClock {
+	return &FakeClock{now: t}
+}
+
+// Now returns the current fake time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep moves the fake time forward by timeout seconds without blocking.
+// It returns false without moving time if any of the events is set.
+func (c *FakeClock) Sleep(timeout float64, evts ...*events.Event) bool {
+	for _, e := range evts {
+		if e.IsSet() {
+			return false
+		}
+	}
+	c.Advance(time.Duration(timeout * float64(time.Second)))
+	return true
+}
+
+// Advance moves the fake time forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set sets the fake time to t.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
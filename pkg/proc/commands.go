@@ -0,0 +1,405 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+)
+
+// Command parameter types.
+const (
+	PARAM_STRING = "string"
+	PARAM_INT    = "int"
+	PARAM_FLOAT  = "float"
+	PARAM_BOOL   = "bool"
+	PARAM_ANY    = "any"
+)
+
+// Builtin commands of command registry.
+const (
+	HELP_CMD          = "help"
+	LIST_COMMANDS_CMD = "list_commands"
+)
+
+// ErrCommand indicates invalid command or arguments.
+var ErrCommand = errors.New("invalid command")
+
+// CmdParam defines a command parameter.
+type CmdParam struct {
+	// Name defines the parameter name.
+	Name string `json:"name"`
+	// Type defines the parameter type {string|int|float|bool|any}.
+	Type string `json:"type"`
+	// Required defines whether the parameter is mandatory.
+	Required bool `json:"required,omitempty"`
+	// Default defines the parameter default value if not required.
+	Default any `json:"default,omitempty"`
+	// Help defines the parameter description.
+	Help string `json:"help,omitempty"`
+}
+
+// CmdFunc defines the function handling a command with parsed arguments,
+// and returning the reply result encoded as JSON.
+type CmdFunc func(args dictx.Dict) (any, error)
+
+// CmdReply defines the JSON reply of commands.
+type CmdReply struct {
+	Ok     bool   `json:"ok"`
+	Result any    `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type command struct {
+	name   string
+	help   string
+	params []CmdParam
+	fn     CmdFunc
+}
+
+// CommandRegistry defines a structured command handler, where named
+// commands are registered with typed parameters and reply in JSON.
+// Command requests are in one of the formats:
+//
+//	<name>
+//	<name> key1=value1 key2="value 2"
+//	<name> value1 "value 2"
+//	<name> {"key1": value1, "key2": value2}
+//	{"cmd": "<name>", "args": {"key1": value1}}
+//
+// where positional values are assigned to the parameters in order.
+//
+// The builtin commands `help` and `list_commands` describe the registered
+// commands. The registry [CommandRegistry.Handle] method is used as the
+// process command handler, see [Process.SetCmdHandler].
+type CommandRegistry struct {
+	cmds     map[string]*command
+	fallback CommandHandler
+	mu       sync.RWMutex
+}
+
+// NewCommandRegistry creates a new command registry.
+func NewCommandRegistry() *CommandRegistry {
+	return &CommandRegistry{cmds: map[string]*command{}}
+}
+
+// Register adds a named command with its description, parameters and
+// handling function.
+func (r *CommandRegistry) Register(
+	name, help string, params []CmdParam, fn CmdFunc) error {
+	if name == "" || strings.ContainsAny(name, " \t{") || fn == nil {
+		return fmt.Errorf("%w name or function", ErrCommand)
+	}
+	if name == HELP_CMD || name == LIST_COMMANDS_CMD {
+		return fmt.Errorf("%w, reserved name %s", ErrCommand, name)
+	}
+	// copy params to keep the caller slice unchanged
+	params = append([]CmdParam(nil), params...)
+	for i := range params {
+		if params[i].Type == "" {
+			params[i].Type = PARAM_STRING
+		}
+		switch params[i].Type {
+		case PARAM_STRING, PARAM_INT, PARAM_FLOAT, PARAM_BOOL, PARAM_ANY:
+		default:
+			return fmt.Errorf("%w param type %s", ErrCommand, params[i].Type)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.cmds[name]; ok {
+		return fmt.Errorf("%w, duplicate name %s", ErrCommand, name)
+	}
+	r.cmds[name] = &command{name, help, params, fn}
+	return nil
+}
+
+// Unregister removes a named command.
+func (r *CommandRegistry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.cmds, name)
+}
+
+// SetFallback sets the handler for unregistered commands, replying with
+// raw text, to keep legacy commands working alongside registered ones.
+func (r *CommandRegistry) SetFallback(h CommandHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = h
+}
+
+// Commands returns the registered command names sorted.
+func (r *CommandRegistry) Commands() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.names()
+}
+
+// Handle parses and runs the command request, and returns the JSON reply.
+func (r *CommandRegistry) Handle(request string) string {
+	name, args, pos, err := parseRequest(request)
+	if err != nil {
+		return encodeReply(nil, err)
+	}
+
+	switch name {
+	case HELP_CMD:
+		cmdName := dictx.GetString(args, "cmd", "")
+		if len(pos) > 0 {
+			cmdName = pos[0]
+		}
+		return encodeReply(r.help(cmdName))
+	case LIST_COMMANDS_CMD:
+		return encodeReply(r.Commands(), nil)
+	}
+
+	r.mu.RLock()
+	cmd, ok := r.cmds[name]
+	fallback := r.fallback
+	r.mu.RUnlock()
+	if !ok {
+		if fallback != nil {
+			return fallback(request)
+		}
+		return encodeReply(nil, fmt.Errorf("%w %s", ErrCommand, name))
+	}
+
+	if args, err = cmd.parseArgs(args, pos); err != nil {
+		return encodeReply(nil, err)
+	}
+	return encodeReply(cmd.fn(args))
+}
+
+// help returns the description of command, or all commands.
+func (r *CommandRegistry) help(name string) (any, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	describe := func(c *command) dictx.Dict {
+		d := dictx.Dict{"name": c.name, "help": c.help}
+		if len(c.params) > 0 {
+			d["params"] = c.params
+		}
+		return d
+	}
+	if name != "" {
+		c, ok := r.cmds[name]
+		if !ok {
+			return nil, fmt.Errorf("%w %s", ErrCommand, name)
+		}
+		return describe(c), nil
+	}
+	res := []dictx.Dict{}
+	for _, n := range r.names() {
+		res = append(res, describe(r.cmds[n]))
+	}
+	return res, nil
+}
+
+// names returns the sorted command names, lock must be held by caller.
+func (r *CommandRegistry) names() []string {
+	names := make([]string, 0, len(r.cmds))
+	for n := range r.cmds {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseArgs assigns the positional values to parameters in order, then
+// validates and converts the arguments to parameters types, and sets
+// default values of missing parameters.
+func (c *command) parseArgs(args dictx.Dict, pos []string) (dictx.Dict, error) {
+	for i, v := range pos {
+		if i >= len(c.params) {
+			return nil, fmt.Errorf("%w, too many arguments", ErrCommand)
+		}
+		if _, ok := args[c.params[i].Name]; ok {
+			return nil, fmt.Errorf("%w, duplicate param %s",
+				ErrCommand, c.params[i].Name)
+		}
+		args[c.params[i].Name] = v
+	}
+
+	res := dictx.Dict{}
+	known := map[string]bool{}
+	for _, p := range c.params {
+		known[p.Name] = true
+		v, ok := args[p.Name]
+		if !ok {
+			if p.Required {
+				return nil, fmt.Errorf("%w, missing param %s", ErrCommand, p.Name)
+			}
+			if p.Default != nil {
+				res[p.Name] = p.Default
+			}
+			continue
+		}
+		cv, err := convertParam(v, p.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%w param %s, %s expected",
+				ErrCommand, p.Name, p.Type)
+		}
+		res[p.Name] = cv
+	}
+	for k := range args {
+		if !known[k] {
+			return nil, fmt.Errorf("%w, unknown param %s", ErrCommand, k)
+		}
+	}
+	return res, nil
+}
+
+// convertParam converts value to parameter type, where values can be
+// strings parsed from key=value arguments or decoded JSON values.
+func convertParam(v any, typ string) (any, error) {
+	s, isStr := v.(string)
+	switch typ {
+	case PARAM_STRING:
+		if isStr {
+			return s, nil
+		}
+	case PARAM_INT:
+		switch n := v.(type) {
+		case float64:
+			if n == float64(int(n)) {
+				return int(n), nil
+			}
+		case string:
+			return strconv.Atoi(n)
+		}
+	case PARAM_FLOAT:
+		switch n := v.(type) {
+		case float64:
+			return n, nil
+		case string:
+			return strconv.ParseFloat(n, 64)
+		}
+	case PARAM_BOOL:
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			return strconv.ParseBool(b)
+		}
+	case PARAM_ANY:
+		return v, nil
+	}
+	return nil, ErrCommand
+}
+
+// parseRequest parses the command request into name, arguments and
+// positional values.
+func parseRequest(request string) (string, dictx.Dict, []string, error) {
+	request = strings.TrimSpace(request)
+	args := dictx.Dict{}
+
+	// full JSON request
+	if strings.HasPrefix(request, "{") {
+		var req struct {
+			Cmd  string     `json:"cmd"`
+			Args dictx.Dict `json:"args"`
+		}
+		if err := json.Unmarshal([]byte(request), &req); err != nil ||
+			req.Cmd == "" {
+			return "", nil, nil, fmt.Errorf("%w request", ErrCommand)
+		}
+		if req.Args != nil {
+			args = req.Args
+		}
+		return req.Cmd, args, nil, nil
+	}
+
+	name, rest, _ := strings.Cut(request, " ")
+	rest = strings.TrimSpace(rest)
+	if name == "" {
+		return "", nil, nil, fmt.Errorf("%w request", ErrCommand)
+	}
+	if rest == "" {
+		return name, args, nil, nil
+	}
+
+	// JSON arguments
+	if strings.HasPrefix(rest, "{") {
+		if err := json.Unmarshal([]byte(rest), &args); err != nil {
+			return "", nil, nil, fmt.Errorf("%w arguments", ErrCommand)
+		}
+		return name, args, nil, nil
+	}
+
+	// key=value arguments and positional values
+	var pos []string
+	for _, f := range splitFields(rest) {
+		k, v, ok := strings.Cut(f, "=")
+		if !ok {
+			if len(args) > 0 {
+				return "", nil, nil, fmt.Errorf(
+					"%w, positional after named argument %s", ErrCommand, f)
+			}
+			pos = append(pos, f)
+			continue
+		}
+		if k == "" {
+			return "", nil, nil, fmt.Errorf("%w argument %s", ErrCommand, f)
+		}
+		args[k] = v
+	}
+	return name, args, pos, nil
+}
+
+// splitFields splits text on spaces, keeping double quoted values
+// together and removing the quotes.
+func splitFields(s string) []string {
+	fields := []string{}
+	var b strings.Builder
+	quoted, escaped, has := false, false, false
+	for _, c := range s {
+		switch {
+		case escaped:
+			b.WriteRune(c)
+			escaped = false
+		case c == '\\' && quoted:
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+			has = true
+		case (c == ' ' || c == '\t') && !quoted:
+			if b.Len() > 0 || has {
+				fields = append(fields, b.String())
+				b.Reset()
+				has = false
+			}
+		default:
+			b.WriteRune(c)
+		}
+	}
+	if b.Len() > 0 || has {
+		fields = append(fields, b.String())
+	}
+	return fields
+}
+
+// encodeReply returns the JSON encoded reply of result or error.
+func encodeReply(result any, err error) string {
+	reply := CmdReply{Ok: err == nil, Result: result}
+	if err != nil {
+		reply.Result = nil
+		reply.Error = err.Error()
+	}
+	b, e := json.Marshal(reply)
+	if e != nil {
+		b, _ = json.Marshal(CmdReply{Error: e.Error()})
+	}
+	return string(b)
+}
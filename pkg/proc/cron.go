@@ -0,0 +1,226 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule defines the job schedules, returning the next activation time.
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// intervalSchedule represents a fixed interval schedule.
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+// Next returns the next activation time after t.
+func (s *intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+// cronSchedule represents a cron expression schedule.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny mark unrestricted day fields.
+	domAny, dowAny bool
+}
+
+// cronFields defines the cron fields value ranges, and the value names
+// starting from min value.
+var cronFields = []struct {
+	name     string
+	min, max int
+	names    []string
+}{
+	{"minute", 0, 59, nil},
+	{"hour", 0, 23, nil},
+	{"day of month", 1, 31, nil},
+	{"month", 1, 12, []string{"jan", "feb", "mar", "apr", "may", "jun",
+		"jul", "aug", "sep", "oct", "nov", "dec"}},
+	{"day of week", 0, 7, []string{"sun", "mon", "tue", "wed", "thu",
+		"fri", "sat"}},
+}
+
+// cronAliases defines the predefined schedules.
+var cronAliases = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseSchedule parses a schedule spec, which could be a standard cron
+// expression, a predefined schedule or a fixed interval.
+//
+//	cron expression: `<minute> <hour> <day of month> <month> <day of week>`
+//	  each field supports `*`, values, ranges `a-b`, lists `a,b` and
+//	  steps `*/n` or `a-b/n`. day of week is 0-7 where 0 and 7 are Sunday.
+//	  month and day of week also accept names, `jan-dec` and `sun-sat`.
+//	predefined: @yearly, @monthly, @weekly, @daily, @hourly
+//	interval:   `@every <duration>`, e.g. `@every 1m30s`
+//
+//	example:
+//	   - */5 * * * *     every 5 minutes
+//	   - 30 2 * * 1-5    at 02:30 on weekdays
+//	   - @every 10s      every 10 seconds
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if v, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid schedule interval: %s", v)
+		}
+		return &intervalSchedule{interval: d}, nil
+	}
+	if v, ok := cronAliases[strings.ToLower(spec)]; ok {
+		spec = v
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("invalid cron expression: %s", spec)
+	}
+	masks := make([]uint64, len(fields))
+	for i, f := range fields {
+		m, err := parseCronField(f, cronFields[i].min, cronFields[i].max,
+			cronFields[i].names)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid cron %s field: %s - %v", cronFields[i].name, f, err)
+		}
+		masks[i] = m
+	}
+
+	s := &cronSchedule{
+		minute: masks[0],
+		hour:   masks[1],
+		dom:    masks[2],
+		month:  masks[3],
+		dow:    masks[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}
+	// map Sunday 7 to 0
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parseCronField parses a cron field into bitmask of values.
+func parseCronField(field string, min, max int, names []string) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			v, err := strconv.Atoi(stepStr)
+			if err != nil || v <= 0 {
+				return 0, errors.New("invalid step")
+			}
+			step = v
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			v, err := parseCronValue(a, min, names)
+			if err != nil {
+				return 0, errors.New("invalid value")
+			}
+			lo, hi = v, v
+			if isRange {
+				if hi, err = parseCronValue(b, min, names); err != nil {
+					return 0, errors.New("invalid range")
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, errors.New("value out of range")
+		}
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	return mask, nil
+}
+
+// parseCronValue parses a cron field value given as number or name.
+func parseCronValue(s string, min int, names []string) (int, error) {
+	for i, name := range names {
+		if strings.EqualFold(s, name) {
+			return min + i, nil
+		}
+	}
+	return strconv.Atoi(s)
+}
+
+// matchDay checks if the day of t matches the day fields. When both day
+// of month and day of week are restricted, either field matches.
+func (s *cronSchedule) matchDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if !s.domAny && !s.dowAny {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// Next returns the next activation time after t, or zero time if no
+// activation time is found within 5 years. The schedule is matched against
+// the wall clock of t location, where the wall clock times skipped by a
+// daylight saving shift are activated after the shift, and the repeated
+// wall clock times are activated once.
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	// iterate over the wall clock times, free of location offset changes
+	w := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1,
+		0, 0, time.UTC)
+	limit := w.AddDate(5, 0, 0)
+
+	for w.Before(limit) {
+		if s.month&(1<<uint(w.Month())) == 0 {
+			w = time.Date(w.Year(), w.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.matchDay(w) {
+			w = time.Date(w.Year(), w.Month(), w.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(w.Hour())) == 0 {
+			w = w.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s.minute&(1<<uint(w.Minute())) == 0 {
+			w = w.Add(time.Minute)
+			continue
+		}
+
+		r := time.Date(w.Year(), w.Month(), w.Day(), w.Hour(), w.Minute(),
+			0, 0, loc)
+		// move the skipped wall clock times to after the shift
+		if d := w.Sub(time.Date(r.Year(), r.Month(), r.Day(), r.Hour(),
+			r.Minute(), 0, 0, time.UTC)); d > 0 {
+			r = r.Add(d)
+		}
+		if r.After(t) {
+			return r
+		}
+		w = w.Add(time.Minute)
+	}
+	return time.Time{}
+}
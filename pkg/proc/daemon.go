@@ -0,0 +1,100 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc
+
+import (
+	"os"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+)
+
+// DAEMON_ENV defines the environment variable marking the daemonized
+// process instance.
+const DAEMON_ENV = "PROC_DAEMONIZED"
+
+// FOREGROUND_FLAG defines the command line flag disabling daemon mode.
+const FOREGROUND_FLAG = "foreground"
+
+// DaemonConfig defines the daemon mode settings.
+type DaemonConfig struct {
+	// LogFile defines the file receiving the daemon stdout and stderr.
+	// use empty value to discard output.
+	LogFile string
+	// WorkDir defines the daemon working directory.
+	WorkDir string
+	// Umask defines the daemon file mode creation mask.
+	// use negative value to keep current umask.
+	Umask int
+	// Chroot defines the daemon root directory. use empty value to disable.
+	Chroot string
+	// User defines the user name or id to run the daemon as.
+	// use empty value to keep current user.
+	User string
+	// Group defines the group name or id to run the daemon as.
+	// use empty value for the user primary group.
+	Group string
+	// Foreground disables daemon mode and runs the process in foreground.
+	Foreground bool
+}
+
+// NewDaemonConfig creates daemon mode settings from options.
+// The parsed options are:
+//   - daemon_log_file: (string) the file receiving the daemon stdout and
+//     stderr. (default is discard output)
+//   - daemon_workdir: (string) the daemon working directory. (default is /)
+//   - daemon_umask: (int) the daemon file mode creation mask, use negative
+//     value to keep current umask. (default is 0o022)
+//   - daemon_chroot: (string) the daemon root directory. (default is none)
+//   - daemon_user: (string) the user name or id to run the daemon as.
+//     (default is current user)
+//   - daemon_group: (string) the group name or id to run the daemon as.
+//     (default is user primary group)
+//   - daemon_foreground: (bool) disables daemon mode. (default is false)
+func NewDaemonConfig(opts dictx.Dict) DaemonConfig {
+	return DaemonConfig{
+		LogFile:    dictx.GetString(opts, "daemon_log_file", ""),
+		WorkDir:    dictx.GetString(opts, "daemon_workdir", "/"),
+		Umask:      dictx.GetInt(opts, "daemon_umask", 0o022),
+		Chroot:     dictx.GetString(opts, "daemon_chroot", ""),
+		User:       dictx.GetString(opts, "daemon_user", ""),
+		Group:      dictx.GetString(opts, "daemon_group", ""),
+		Foreground: dictx.Fetch(opts, "daemon_foreground", false),
+	}
+}
+
+// IsDaemonized returns whether the current process is a daemonized instance.
+func IsDaemonized() bool {
+	return os.Getenv(DAEMON_ENV) != ""
+}
+
+// isForeground returns whether daemon mode is disabled by settings or by
+// the `-foreground` or `--foreground` command line flags.
+func (c *DaemonConfig) isForeground() bool {
+	if c.Foreground {
+		return true
+	}
+	for _, a := range os.Args[1:] {
+		switch a {
+		case "-" + FOREGROUND_FLAG, "--" + FOREGROUND_FLAG,
+			"-" + FOREGROUND_FLAG + "=true", "--" + FOREGROUND_FLAG + "=true":
+			return true
+		case "--":
+			return false
+		}
+	}
+	return false
+}
+
+// EnableDaemon enables the daemon mode on process. On start, the process
+// re-executes itself detached from the terminal in a new session, with
+// stdio redirected to the log file, then the original process exits.
+// The daemonized instance applies the umask, chroot and working dir
+// settings before starting the tasklet, where with chroot the user is
+// switched after chroot while still privileged. Daemon mode is disabled by the
+// foreground setting or the `--foreground` command line flag, and is
+// not supported on windows.
+func (h *Process) EnableDaemon(cfg DaemonConfig) {
+	h.daemonCfg = &cfg
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package proc
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDaemonForeground(t *testing.T) {
+	args := os.Args
+	defer func() { os.Args = args }()
+
+	tests := []struct {
+		args       []string
+		foreground bool
+	}{
+		{[]string{}, false},
+		{[]string{"-v", "run"}, false},
+		{[]string{"-foreground"}, true},
+		{[]string{"--foreground"}, true},
+		{[]string{"-v", "-foreground=true"}, true},
+		{[]string{"--foreground=true"}, true},
+		{[]string{"--foreground=false"}, false},
+		{[]string{"-foregroundx"}, false},
+		{[]string{"--", "--foreground"}, false},
+		{[]string{"--foreground", "--"}, true},
+	}
+	for _, tc := range tests {
+		os.Args = append([]string{"prog"}, tc.args...)
+		c := NewDaemonConfig(nil)
+		assert.Equal(t, tc.foreground, c.isForeground(), tc.args)
+	}
+
+	// foreground setting ignores flags
+	os.Args = []string{"prog"}
+	c := NewDaemonConfig(map[string]any{"daemon_foreground": true})
+	assert.True(t, c.isForeground())
+}
+
+func TestDaemonizedSetup(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(wd)
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	// daemonized instance applies settings without re-executing
+	t.Setenv(DAEMON_ENV, "1")
+	c := NewDaemonConfig(map[string]any{
+		"daemon_workdir": dir, "daemon_umask": 0o027})
+	oldMask := syscall.Umask(0o022)
+	defer syscall.Umask(oldMask)
+	pid, err := c.daemonize()
+	require.NoError(t, err)
+	assert.Equal(t, 0, pid)
+	cwd, _ := os.Getwd()
+	assert.Equal(t, dir, cwd)
+	assert.Equal(t, 0o027, syscall.Umask(0o022))
+
+	// negative umask keeps current umask
+	c.Umask = -1
+	_, err = c.daemonize()
+	require.NoError(t, err)
+	assert.Equal(t, 0o022, syscall.Umask(0o022))
+
+	// chroot is applied once, restarted instances are marked as chrooted
+	c.Chroot = filepath.Join(dir, "missing")
+	_, err = c.daemonize()
+	assert.ErrorContains(t, err, "chroot failed")
+	assert.Equal(t, "1", os.Getenv(DAEMON_ENV))
+	t.Setenv(DAEMON_ENV, "chroot")
+	_, err = c.daemonize()
+	assert.NoError(t, err)
+	assert.Equal(t, "chroot", os.Getenv(DAEMON_ENV))
+
+	// missing workdir fails
+	c.WorkDir = filepath.Join(dir, "missing")
+	_, err = c.daemonize()
+	assert.Error(t, err)
+}
+
+// TestDaemonHelper runs as the daemonized instance with chroot and user.
+func TestDaemonHelper(t *testing.T) {
+	root := os.Getenv("PROC_DAEMON_HELPER")
+	if root == "" {
+		t.Skip("daemon helper process")
+	}
+	os.Setenv(DAEMON_ENV, "1")
+	c := NewDaemonConfig(map[string]any{
+		"daemon_chroot": root, "daemon_workdir": "/work",
+		"daemon_user": "nobody", "daemon_umask": -1})
+	if _, err := c.daemonize(); err != nil {
+		fmt.Printf("error=%v\n", err)
+		os.Exit(0)
+	}
+	cwd, _ := os.Getwd()
+	_, err := os.Stat("/work/marker")
+	fmt.Printf("uid=%d cwd=%s marker=%v env=%s\n",
+		os.Getuid(), cwd, err == nil, os.Getenv(DAEMON_ENV))
+	os.Exit(0)
+}
+
+func TestDaemonizedChrootUser(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("requires root")
+	}
+	u, err := user.Lookup("nobody")
+	if err != nil {
+		t.Skip("requires nobody user")
+	}
+	root := t.TempDir()
+	require.NoError(t, os.Chmod(root, 0o755))
+	require.NoError(t, os.Mkdir(filepath.Join(root, "work"), 0o755))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(root, "work", "marker"), nil, 0o644))
+
+	// chroot is applied before switching to the unprivileged user
+	cmd := exec.Command(os.Args[0], "-test.run=^TestDaemonHelper$")
+	cmd.Env = append(os.Environ(), "PROC_DAEMON_HELPER="+root)
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("uid=%s cwd=/work marker=true env=chroot", u.Uid),
+		strings.TrimSpace(string(out)))
+}
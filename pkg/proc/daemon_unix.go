@@ -0,0 +1,108 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package proc
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// daemonize starts the detached daemon instance and returns its pid,
+// or applies the daemon settings and returns 0 if running as the
+// daemonized instance.
+func (c *DaemonConfig) daemonize() (int, error) {
+	if IsDaemonized() {
+		return 0, c.setup()
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		return 0, err
+	}
+	defer devNull.Close()
+	out := devNull
+	if c.LogFile != "" {
+		out, err = os.OpenFile(
+			c.LogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o664)
+		if err != nil {
+			return 0, err
+		}
+		defer out.Close()
+	}
+
+	// with chroot the user is switched by the daemon instance after chroot
+	var cred *syscall.Credential
+	if c.User != "" {
+		uid, gid, groups, err := lookupCredential(c.User, c.Group)
+		if err != nil {
+			return 0, err
+		}
+		if c.Chroot == "" {
+			cred = &syscall.Credential{Uid: uid, Gid: gid, Groups: groups}
+		}
+	}
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), DAEMON_ENV+"=1")
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = devNull, out, out
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setsid:     true,
+		Credential: cred,
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	pid := cmd.Process.Pid
+	cmd.Process.Release()
+	return pid, nil
+}
+
+// setup applies the daemon settings on the daemonized instance.
+func (c *DaemonConfig) setup() error {
+	if c.Umask >= 0 {
+		syscall.Umask(c.Umask)
+	}
+	// chroot once while privileged then switch user, restarted instances
+	// inherit the root dir and user
+	if c.Chroot != "" && os.Getenv(DAEMON_ENV) != "chroot" {
+		// lookup user before chroot, the new root may lack user database
+		var uid, gid uint32
+		var groups []uint32
+		if c.User != "" {
+			var err error
+			uid, gid, groups, err = lookupCredential(c.User, c.Group)
+			if err != nil {
+				return err
+			}
+		}
+		if err := syscall.Chroot(c.Chroot); err != nil {
+			return fmt.Errorf("chroot failed: %w", err)
+		}
+		if err := os.Chdir("/"); err != nil {
+			return err
+		}
+		if c.User != "" {
+			if err := setCredential(uid, gid, groups); err != nil {
+				return fmt.Errorf("switch to user %s failed: %w", c.User, err)
+			}
+		}
+		os.Setenv(DAEMON_ENV, "chroot")
+	}
+	if c.WorkDir != "" {
+		if err := os.Chdir(c.WorkDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
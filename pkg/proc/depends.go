@@ -0,0 +1,193 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DEPENDENCY_TIMEOUT defines the default timeout in seconds to wait for
+// routine dependencies to initialize.
+const DEPENDENCY_TIMEOUT = 30
+
+// RoutineOption defines the routine options used with
+// [RoutineManager.AddRoutine].
+type RoutineOption func(*routineOptions)
+
+type routineOptions struct {
+	deps     []string
+	priority int
+}
+
+// DependsOn sets the routines that must be initialized before starting
+// the routine. The routine is stopped before its dependencies.
+func DependsOn(names ...string) RoutineOption {
+	return func(o *routineOptions) {
+		o.deps = append(o.deps, names...)
+	}
+}
+
+// Priority sets the routine startup priority, where routines with lower
+// priority start first when not ordered by dependencies. (default is 0)
+func Priority(priority int) RoutineOption {
+	return func(o *routineOptions) {
+		o.priority = priority
+	}
+}
+
+// routineOrder returns the routine names in startup order, where routines
+// are ordered after their dependencies, then by priority and name.
+// It returns error for unknown dependencies or dependency cycles.
+// The routines buffer lock must be held by caller.
+func (m *RoutineManager) routineOrder() ([]string, error) {
+	indegree := map[string]int{}
+	dependents := map[string][]string{}
+	for n := range m.rtBuffer {
+		indegree[n] += 0
+		for _, d := range m.rtOpts[n].deps {
+			if _, ok := m.rtBuffer[d]; !ok {
+				return nil, fmt.Errorf(
+					"unknown dependency %s for routine %s", d, n)
+			}
+			indegree[n]++
+			dependents[d] = append(dependents[d], n)
+		}
+	}
+
+	less := func(a, b string) bool {
+		pa, pb := m.rtOpts[a].priority, m.rtOpts[b].priority
+		if pa != pb {
+			return pa < pb
+		}
+		return a < b
+	}
+	ready := []string{}
+	for n, c := range indegree {
+		if c == 0 {
+			ready = append(ready, n)
+		}
+	}
+
+	order := make([]string, 0, len(m.rtBuffer))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return less(ready[i], ready[j]) })
+		n := ready[0]
+		ready = ready[1:]
+		order = append(order, n)
+		for _, d := range dependents[n] {
+			if indegree[d]--; indegree[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+	if len(order) < len(m.rtBuffer) {
+		cycle := []string{}
+		for n, c := range indegree {
+			if c > 0 {
+				cycle = append(cycle, n)
+			}
+		}
+		sort.Strings(cycle)
+		return nil, fmt.Errorf(
+			"dependency cycle between routines: %s", strings.Join(cycle, ", "))
+	}
+	return order, nil
+}
+
+// startRoutines starts the enabled routines in dependency order, where
+// routines are started after their dependencies are initialized. Routines
+// waiting for dependencies longer than the dependency timeout are disabled.
+// It returns whether routines are still waiting for dependencies.
+func (m *RoutineManager) startRoutines() bool {
+	m.rtBuffLock.Lock()
+	defer m.rtBuffLock.Unlock()
+
+	order, err := m.routineOrder()
+	if err != nil {
+		m.Log.Error(err.Error())
+		return false
+	}
+
+	waiting := false
+	for _, n := range order {
+		rt := m.rtBuffer[n]
+		if !rt.IsEnabled() || rt.IsAlive() {
+			delete(m.depWait, n)
+			continue
+		}
+
+		pending := []string{}
+		for _, d := range m.rtOpts[n].deps {
+			if !m.rtBuffer[d].IsInitialized() {
+				pending = append(pending, d)
+			}
+		}
+		if len(pending) == 0 {
+			delete(m.depWait, n)
+			m.launch(rt)
+			continue
+		}
+
+		tWait, ok := m.depWait[n]
+		if !ok {
+			tWait = m.clock.Now()
+			m.depWait[n] = tWait
+		}
+		if m.clock.Now().Sub(tWait).Seconds() >= m.DependencyTimeout {
+			m.Log.Error("routine %s disabled, dependencies not initialized: %s",
+				n, strings.Join(pending, ", "))
+			rt.Disable()
+			delete(m.depWait, n)
+			continue
+		}
+		waiting = true
+	}
+	return waiting
+}
+
+// stopRoutines stops the routines in reverse dependency order, where
+// routines are stopped after their dependents exit, waiting up to the
+// stopping delay.
+func (m *RoutineManager) stopRoutines() {
+	// snapshot the stopping order, then wait without holding the lock
+	m.rtBuffLock.Lock()
+	order, err := m.routineOrder()
+	if err != nil {
+		order = []string{}
+		for n := range m.rtBuffer {
+			order = append(order, n)
+		}
+	}
+	routines := make(map[string]Routine, len(m.rtBuffer))
+	dependents := map[string][]string{}
+	for n, rt := range m.rtBuffer {
+		routines[n] = rt
+		for _, d := range m.rtOpts[n].deps {
+			dependents[d] = append(dependents[d], n)
+		}
+	}
+	m.rtBuffLock.Unlock()
+
+	tBreak := m.clock.Now().Add(
+		time.Duration(m.StoppingDelay * float64(time.Second)))
+	for i := len(order) - 1; i >= 0; i-- {
+		n := order[i]
+		for _, d := range dependents[n] {
+			for routines[d].IsAlive() && m.clock.Now().Before(tBreak) {
+				if !m.clock.Sleep(0.05, m.KillEvent) {
+					break
+				}
+			}
+		}
+		routines[n].Disable()
+		if routines[n].IsAlive() {
+			m.Log.Info("stopping routine: %s", n)
+			routines[n].Stop()
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc
+
+import (
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/logging"
+)
+
+// LOG_LEVEL_CMD defines the management command controlling loggers levels.
+const LOG_LEVEL_CMD = "log_level"
+
+// RegisterLogLevel adds the log_level command to command registry,
+// controlling the levels of loggers in reg at runtime, or the default
+// loggers registry if reg is nil. The command formats are:
+//
+//	log_level                  list all loggers levels
+//	log_level <name>           get the logger level
+//	log_level <name> <level>   set the logger and its descendants level
+func RegisterLogLevel(r *CommandRegistry, reg *logging.Registry) error {
+	if reg == nil {
+		reg = logging.DefaultRegistry
+	}
+	return r.Register(LOG_LEVEL_CMD, "get or set loggers levels",
+		[]CmdParam{
+			{Name: "name", Help: "logger name, empty for all loggers"},
+			{Name: "level", Help: "new logger level, empty to get level"},
+		},
+		func(args dictx.Dict) (any, error) {
+			name := dictx.GetString(args, "name", "")
+			if name == "" {
+				res := dictx.Dict{}
+				for n, lvl := range reg.Levels() {
+					res[n] = lvl.Name()
+				}
+				return res, nil
+			}
+			lvlName := dictx.GetString(args, "level", "")
+			if lvlName == "" {
+				lvl, err := reg.Level(name)
+				if err != nil {
+					return nil, err
+				}
+				return lvl.Name(), nil
+			}
+			lvl, err := logging.ParseLevel(lvlName)
+			if err != nil {
+				return nil, err
+			}
+			updated, err := reg.SetLevel(name, lvl)
+			if err != nil {
+				return nil, err
+			}
+			res := dictx.Dict{}
+			for _, n := range updated {
+				res[n] = lvl.Name()
+			}
+			return res, nil
+		})
+}
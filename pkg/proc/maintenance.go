@@ -0,0 +1,413 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+)
+
+// MaintenanceWindow defines a scheduled maintenance time window.
+type MaintenanceWindow struct {
+	// Name defines the window name.
+	Name string
+	// Groups defines the routine groups under maintenance.
+	Groups []string
+	// Spec defines the window start schedule spec, see [ParseSchedule].
+	Spec string
+	// Duration defines the window duration.
+	Duration time.Duration
+
+	// schedule is the parsed start schedule.
+	schedule Schedule
+}
+
+// MaintenanceStatus represents an active maintenance of routine group.
+type MaintenanceStatus struct {
+	Group string
+	// Until defines the maintenance end time, zero for manual
+	// maintenance without end time.
+	Until time.Time
+	// Window defines the scheduled window name, empty for manual maintenance.
+	Window string
+}
+
+// Maintenance is a routine managing maintenance mode of routine groups,
+// where the routines of groups under maintenance are paused and resumed
+// automatically when maintenance ends. Maintenance is entered manually or
+// by scheduled time windows.
+type Maintenance struct {
+	*RoutineHandler
+
+	// manager is the routine manager of paused routines.
+	manager *RoutineManager
+
+	// groups defines the routine name patterns by group name.
+	groups map[string][]string
+	// windows defines the scheduled maintenance windows.
+	windows []*MaintenanceWindow
+	// manual holds the manual maintenance end time by group.
+	manual map[string]time.Time
+	// active holds the current active maintenance by group.
+	active map[string]MaintenanceStatus
+	// paused holds the paused routines names.
+	paused map[string]bool
+	// mu defines mutex for maintenance state.
+	mu sync.Mutex
+	// uMutex defines mutex for update operations.
+	uMutex sync.Mutex
+}
+
+// NewMaintenance creates a new maintenance routine for routine manager.
+// The parsed config keys are:
+//   - groups: (dict) the routine name patterns by group name. the '*'
+//     char in pattern matches any sequence of chars.
+//   - windows: (list) the maintenance windows, each window has keys:
+//     name (string), groups (list of groups), schedule (string) the window
+//     start schedule, see [ParseSchedule], and duration (float64) the
+//     window duration in seconds.
+//
+// example:
+//
+//	{
+//	  "groups": {"field": ["modbus_*", "poller"]},
+//	  "windows": [
+//	    {"name": "weekly", "groups": ["field"],
+//	     "schedule": "0 2 * * 6", "duration": 7200}
+//	  ]
+//	}
+func NewMaintenance(rm *RoutineManager, cfg dictx.Dict) (*Maintenance, error) {
+	m := &Maintenance{
+		manager: rm,
+		groups:  map[string][]string{},
+		manual:  map[string]time.Time{},
+		active:  map[string]MaintenanceStatus{},
+		paused:  map[string]bool{},
+	}
+	m.RoutineHandler = NewRoutineHandler(rm.Log, m)
+
+	groups, ok := dictx.Get(cfg, "groups", dictx.Dict{}).(dictx.Dict)
+	if !ok {
+		return nil, fmt.Errorf("invalid maintenance groups")
+	}
+	for name, v := range groups {
+		patterns, err := toStrings(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maintenance group: %s", name)
+		}
+		m.groups[name] = patterns
+	}
+
+	windows, ok := dictx.Get(cfg, "windows", []any{}).([]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid maintenance windows")
+	}
+	for _, v := range windows {
+		d, ok := v.(dictx.Dict)
+		if !ok {
+			return nil, fmt.Errorf("invalid maintenance windows")
+		}
+		w := &MaintenanceWindow{
+			Name: dictx.GetString(d, "name", ""),
+			Spec: dictx.GetString(d, "schedule", ""),
+			Duration: time.Duration(
+				dictx.GetFloat(d, "duration", 0) * float64(time.Second)),
+		}
+		var err error
+		if w.Groups, err = toStrings(dictx.Get(d, "groups", nil)); err != nil {
+			return nil, fmt.Errorf("invalid maintenance window: %s", w.Name)
+		}
+		if err := m.AddWindow(w); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// toStrings converts config list value to strings slice.
+func toStrings(v any) ([]string, error) {
+	switch val := v.(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return val, nil
+	case []any:
+		res := make([]string, 0, len(val))
+		for _, s := range val {
+			str, ok := s.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid string value")
+			}
+			res = append(res, str)
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("invalid list value")
+}
+
+// SetGroup sets the routine name patterns of group.
+func (m *Maintenance) SetGroup(name string, patterns ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.groups[name] = patterns
+}
+
+// AddWindow adds a scheduled maintenance window.
+func (m *Maintenance) AddWindow(w *MaintenanceWindow) error {
+	if w.Name == "" || w.Duration <= 0 {
+		return fmt.Errorf("invalid maintenance window: %s", w.Name)
+	}
+	sch, err := ParseSchedule(w.Spec)
+	if err != nil {
+		return fmt.Errorf("invalid maintenance window: %s - %v", w.Name, err)
+	}
+	w.schedule = sch
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, g := range w.Groups {
+		if _, ok := m.groups[g]; !ok {
+			return fmt.Errorf("invalid maintenance group: %s", g)
+		}
+	}
+	m.windows = append(m.windows, w)
+	return nil
+}
+
+// Enter starts manual maintenance of group for duration in seconds.
+// Setting duration=0 keeps maintenance active until [Maintenance.Exit].
+func (m *Maintenance) Enter(group string, duration float64) error {
+	m.mu.Lock()
+	if _, ok := m.groups[group]; !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("invalid maintenance group: %s", group)
+	}
+	var until time.Time
+	if duration > 0 {
+		until = m.clock.Now().Add(
+			time.Duration(duration * float64(time.Second)))
+	}
+	m.manual[group] = until
+	m.mu.Unlock()
+
+	m.Update()
+	return nil
+}
+
+// Exit ends manual maintenance of group. Scheduled windows are not affected.
+func (m *Maintenance) Exit(group string) error {
+	m.mu.Lock()
+	if _, ok := m.groups[group]; !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("invalid maintenance group: %s", group)
+	}
+	delete(m.manual, group)
+	m.mu.Unlock()
+
+	m.Update()
+	return nil
+}
+
+// Status returns the active maintenance of groups sorted by group name.
+func (m *Maintenance) Status() []MaintenanceStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res := make([]MaintenanceStatus, 0, len(m.active))
+	for _, st := range m.active {
+		res = append(res, st)
+	}
+	sort.Slice(res, func(i, k int) bool { return res[i].Group < res[k].Group })
+	return res
+}
+
+// IsActive checks if group is under maintenance.
+func (m *Maintenance) IsActive(group string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.active[group]
+	return ok
+}
+
+// IsSuppressed checks if the named routine belongs to a group under
+// maintenance, used by alarm handlers to suppress alarms raised by
+// routines under maintenance.
+func (m *Maintenance) IsSuppressed(routine string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for g := range m.active {
+		if m.matchGroup(g, routine) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGroup checks if routine name matches the group patterns.
+// requires lock to be held.
+func (m *Maintenance) matchGroup(group, routine string) bool {
+	for _, p := range m.groups[group] {
+		if ok, _ := path.Match(p, routine); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Update evaluates the active maintenance of groups at the routine clock
+// time, see [TaskletHandler.SetClock], pausing the routines of groups
+// entering maintenance and resuming the routines of groups leaving
+// maintenance.
+func (m *Maintenance) Update() {
+	m.uMutex.Lock()
+	defer m.uMutex.Unlock()
+
+	now := m.clock.Now()
+
+	m.mu.Lock()
+	active := map[string]MaintenanceStatus{}
+	for g, until := range m.manual {
+		if !until.IsZero() && !now.Before(until) {
+			delete(m.manual, g)
+			continue
+		}
+		active[g] = MaintenanceStatus{Group: g, Until: until}
+	}
+	for _, w := range m.windows {
+		// window is active if last start is within duration
+		start := w.schedule.Next(now.Add(-w.Duration))
+		if start.IsZero() || start.After(now) {
+			continue
+		}
+		until := start.Add(w.Duration)
+		for _, g := range w.Groups {
+			st, ok := active[g]
+			if ok && (st.Until.IsZero() || !until.After(st.Until)) {
+				continue
+			}
+			active[g] = MaintenanceStatus{Group: g, Until: until, Window: w.Name}
+		}
+	}
+
+	for g, st := range active {
+		if _, ok := m.active[g]; !ok {
+			m.Log.Info("maintenance started: %s (until %s)", g, untilStr(st.Until))
+		}
+	}
+	for g := range m.active {
+		if _, ok := active[g]; !ok {
+			m.Log.Info("maintenance ended: %s", g)
+		}
+	}
+	m.active = active
+
+	// find routines to pause and resume
+	toPause, toResume := []string{}, []string{}
+	routines := m.manager.ListRoutines()
+	for _, name := range routines {
+		inMaint := false
+		for g := range active {
+			if m.matchGroup(g, name) {
+				inMaint = true
+				break
+			}
+		}
+		if inMaint && !m.paused[name] {
+			toPause = append(toPause, name)
+		} else if !inMaint && m.paused[name] {
+			toResume = append(toResume, name)
+		}
+	}
+	for name := range m.paused {
+		// forget deleted routines
+		if !contains(routines, name) {
+			delete(m.paused, name)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, name := range toPause {
+		m.manager.rtBuffLock.Lock()
+		rt, ok := m.manager.rtBuffer[name]
+		wasEnabled := ok && rt.IsEnabled()
+		m.manager.rtBuffLock.Unlock()
+		if !wasEnabled {
+			continue
+		}
+		m.Log.Info("pausing routine: %s", name)
+		m.manager.StopRoutine(name)
+		m.mu.Lock()
+		m.paused[name] = true
+		m.mu.Unlock()
+	}
+	for _, name := range toResume {
+		m.Log.Info("resuming routine: %s", name)
+		m.manager.StartRoutine(name)
+		m.mu.Lock()
+		delete(m.paused, name)
+		m.mu.Unlock()
+	}
+}
+
+// untilStr returns the maintenance end time string.
+func untilStr(t time.Time) string {
+	if t.IsZero() {
+		return "manual exit"
+	}
+	return t.Format(time.RFC3339)
+}
+
+// contains checks if value exists in list.
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Initialize prepares the maintenance routine.
+func (m *Maintenance) Initialize() error {
+	return nil
+}
+
+// Execute evaluates the maintenance state periodically.
+func (m *Maintenance) Execute() error {
+	m.Update()
+	m.Sleep(1)
+	return nil
+}
+
+// Terminate resumes the paused routines, unless the routine manager
+// is stopping.
+func (m *Maintenance) Terminate() error {
+	m.uMutex.Lock()
+	defer m.uMutex.Unlock()
+
+	m.mu.Lock()
+	names := []string{}
+	for name := range m.paused {
+		names = append(names, name)
+	}
+	m.paused = map[string]bool{}
+	m.active = map[string]MaintenanceStatus{}
+	m.mu.Unlock()
+
+	if !m.manager.IsEnabled() {
+		return nil
+	}
+	for _, name := range names {
+		m.Log.Info("resuming routine: %s", name)
+		m.manager.StartRoutine(name)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/exonlabs/go-utils/pkg/abc/fsx"
+)
+
+// ErrRunning indicates another process instance is running.
+var ErrRunning = errors.New("another instance is running")
+
+// PidFile manages a locked pid file enforcing single process instance.
+// The pid file is locked while the process holds it, so a pid file left
+// by a crashed process is detected as stale and reused.
+type PidFile struct {
+	// Path defines the pid file path.
+	Path string
+
+	lock *fsx.FileLock
+	mu   sync.Mutex
+}
+
+// NewPidFile creates a new pid file handler.
+func NewPidFile(path string) *PidFile {
+	return &PidFile{Path: path}
+}
+
+// Acquire locks the pid file and writes the current process pid. It returns
+// [ErrRunning] if the pid file is locked by another instance, and the pid
+// of a previous crashed instance if the pid file was stale, or 0.
+func (p *PidFile) Acquire() (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.lock != nil {
+		return 0, nil
+	}
+	lock, err := fsx.Lock(p.Path)
+	if err != nil {
+		if errors.Is(err, fsx.ErrLocked) {
+			pid, _ := ReadPidFile(p.Path)
+			return 0, fmt.Errorf("%w, pid %d", ErrRunning, pid)
+		}
+		return 0, err
+	}
+
+	stale, _ := ReadPidFile(p.Path)
+	if stale == os.Getpid() {
+		stale = 0
+	}
+
+	f := lock.File()
+	if err := f.Truncate(0); err == nil {
+		_, err = f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+		if err == nil {
+			err = f.Sync()
+		}
+	}
+	if err != nil {
+		lock.Unlock()
+		return 0, err
+	}
+	p.lock = lock
+	return stale, nil
+}
+
+// Release removes and unlocks the pid file if held.
+func (p *PidFile) Release() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.lock == nil {
+		return nil
+	}
+	err := os.Remove(p.Path)
+	if e := p.lock.Unlock(); err == nil {
+		err = e
+	}
+	p.lock = nil
+	return err
+}
+
+// IsHeld returns whether the pid file is held by current process.
+func (p *PidFile) IsHeld() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lock != nil
+}
+
+// ReadPidFile reads the pid stored in pid file at path.
+func ReadPidFile(path string) (int, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(b)))
+}
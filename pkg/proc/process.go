@@ -6,13 +6,17 @@ package proc
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"os/signal"
 	"runtime/debug"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 
+	"github.com/exonlabs/go-utils/pkg/abc/exitcode"
 	"github.com/exonlabs/go-utils/pkg/comm"
 	"github.com/exonlabs/go-utils/pkg/logging"
 )
@@ -28,6 +32,37 @@ type Process struct {
 	cmdHandler  CommandHandler
 	cmdListener comm.Listener
 
+	// restart command timeout and listeners to hand over
+	restartTimeout   float64
+	restartListeners []comm.Listener
+
+	// error defining the process exit code
+	exitErr atomic.Pointer[error]
+
+	// daemon mode settings
+	daemonCfg *DaemonConfig
+
+	// user and group to switch to after initialization, pending switch
+	// flag and function checking the privileged initialization is done
+	runAsUser    string
+	runAsGroup   string
+	runAsPending atomic.Bool
+	runAsReady   func() bool
+
+	// pid file enforcing single instance
+	pidFile *PidFile
+
+	// boot counter for crash loop protection and safe mode flag
+	bootCounter *BootCounter
+	safeMode    atomic.Bool
+
+	// reload handler and pending reload requests
+	reloadHandler func() error
+	reloadWaiters []chan error
+	reloadPending atomic.Bool
+	reloadLock    sync.Mutex
+	reloadRunLock sync.Mutex
+
 	// Map of signal handlers.
 	sigHandlers map[os.Signal]func()
 }
@@ -45,9 +80,20 @@ func NewProcessHandler(log *logging.Logger, tsk Tasklet) *Process {
 		syscall.SIGQUIT: h.Stop, // Handle quit signals.
 		syscall.SIGHUP:  h.Stop, // Handle hangup signals.
 	}
+	h.TaskletHandler.preExec = h.beforeExecute
 	return h
 }
 
+// beforeExecute switches the process user and runs the pending reload
+// before each tasklet execution.
+func (h *Process) beforeExecute() error {
+	if err := h.switchUser(); err != nil {
+		return err
+	}
+	h.runReload()
+	return nil
+}
+
 // SetCmdHandler sets the command handling function and comm listener to
 // enable command handling feature on process.
 func (h *Process) SetCmdHandler(l comm.Listener, f CommandHandler) {
@@ -58,6 +104,62 @@ func (h *Process) SetCmdHandler(l comm.Listener, f CommandHandler) {
 	h.cmdHandler = f
 }
 
+// EnablePidFile enables the pid file at path, enforcing a single running
+// process instance. On start, the pid file is locked and the process pid
+// is written, and the process refuses to start if the pid file is held by
+// another running instance. The pid file is removed on exit.
+func (h *Process) EnablePidFile(path string) {
+	h.pidFile = NewPidFile(path)
+}
+
+// SetBootCounter enables the crash loop protection on process. When the
+// number of unclean starts within the crash window reaches the limit, the
+// process starts in safe mode, where only the command handling and logging
+// are active and the tasklet is not started, so operators can reach the
+// process and fix configuration remotely. A clean stop in safe mode resets
+// the boot counter.
+func (h *Process) SetBootCounter(b *BootCounter) {
+	h.bootCounter = b
+}
+
+// IsSafeMode returns whether the process is running in safe mode.
+func (h *Process) IsSafeMode() bool {
+	return h.safeMode.Load()
+}
+
+// StopWithError stops the process and records the error defining the
+// process exit code, see [Process.ExitCode]. Only the first recorded
+// error is kept.
+func (h *Process) StopWithError(err error) {
+	if err != nil {
+		h.exitErr.CompareAndSwap(nil, &err)
+	}
+	h.Stop()
+}
+
+// ExitError returns the error recorded on stop, or the last tasklet
+// failure error if the tasklet was disabled by its restart policy.
+// returns nil on clean stop.
+func (h *Process) ExitError() error {
+	if err := h.exitErr.Load(); err != nil {
+		return *err
+	}
+	if err := h.LastError(); err != nil && h.failed.Load() {
+		return err
+	}
+	return nil
+}
+
+// ExitCode returns the process exit code mapped from the exit error
+// using [exitcode.Code], where recovered panics map to SOFTWARE code.
+func (h *Process) ExitCode() int {
+	err := h.ExitError()
+	if errors.Is(err, ErrPanic) {
+		return exitcode.SOFTWARE
+	}
+	return exitcode.Code(err)
+}
+
 // SetSignalHandler allows the user to define custom handlers for specific signals.
 func (h *Process) SetSignalHandler(sig os.Signal, fn func()) {
 	if sig != nil && fn != nil {
@@ -96,9 +198,12 @@ func (h *Process) handleConnection(conn comm.Connection) {
 		}
 	}()
 
-	for !h.TermEvent.IsSet() && conn.IsOpened() {
+	for !h.TermEvent.IsSet() && conn.IsOpened() && !comm.IsDraining(conn) {
 		b, addr, err := conn.RecvFrom(0)
 		if err != nil {
+			if comm.IsDraining(conn) {
+				return
+			}
 			if conn.IsOpened() && err != comm.ErrClosed {
 				h.Log.Error(err.Error())
 				continue
@@ -110,6 +215,27 @@ func (h *Process) handleConnection(conn comm.Connection) {
 		if cmd == "" {
 			continue
 		}
+		if cmd == RESTART_CMD && h.restartTimeout > 0 {
+			pid, err := h.restart()
+			if err != nil {
+				conn.SendTo([]byte("ERROR: "+err.Error()+"\n"), addr, 0)
+				continue
+			}
+			conn.SendTo([]byte(fmt.Sprintf("OK %d\n", pid)), addr, 0)
+			// stop process after handler returns
+			go h.Stop()
+			return
+		}
+		if cmd == RELOAD_CMD && h.reloadHandler != nil {
+			reply := "OK\n"
+			if err := h.Reload(0); err != nil {
+				reply = "ERROR: " + err.Error() + "\n"
+			}
+			if err := conn.SendTo([]byte(reply), addr, 0); err != nil {
+				h.Log.Error(err.Error())
+			}
+			continue
+		}
 		reply := h.cmdHandler(cmd)
 		if reply != "" {
 			if err := conn.SendTo([]byte(reply+"\n"), addr, 0); err != nil {
@@ -121,6 +247,39 @@ func (h *Process) handleConnection(conn comm.Connection) {
 
 // Start begins the process and sets up signal handling.
 func (h *Process) Start() {
+	// detach as daemon and exit original process
+	if h.daemonCfg != nil && !h.daemonCfg.isForeground() {
+		pid, err := h.daemonCfg.daemonize()
+		if err != nil {
+			h.Log.Error("daemon mode failed: %s", err.Error())
+			h.exitErr.CompareAndSwap(nil, &err)
+			return
+		}
+		if pid > 0 {
+			h.Log.Info("started daemon, pid %d", pid)
+			os.Exit(0)
+		}
+	}
+
+	// enforce single instance
+	if h.pidFile != nil {
+		stale, err := h.pidFile.Acquire()
+		if err != nil {
+			h.Log.Error("pid file %s failed: %s", h.pidFile.Path, err.Error())
+			h.exitErr.CompareAndSwap(nil, &err)
+			return
+		}
+		if stale > 0 {
+			h.Log.Warn("replaced stale pid file, previous pid %d", stale)
+		}
+		defer func() {
+			if err := h.pidFile.Release(); err != nil {
+				h.Log.Error("pid file %s failed: %s",
+					h.pidFile.Path, err.Error())
+			}
+		}()
+	}
+
 	// Create a buffered channel to receive multiple signals without blocking.
 	sigCh := make(chan os.Signal, 2)
 	for sig := range h.sigHandlers {
@@ -135,6 +294,18 @@ func (h *Process) Start() {
 		}
 	}()
 
+	// check crash loop and enable safe mode
+	if h.bootCounter != nil {
+		crashes, err := h.bootCounter.Register()
+		if err != nil {
+			h.Log.Error("boot counter failed: %s", err.Error())
+		} else if h.bootCounter.IsCrashLoop(crashes) {
+			h.Log.Warn("crash loop detected (%d crashes), starting in safe mode",
+				crashes)
+			h.safeMode.Store(true)
+		}
+	}
+
 	var waitGrp sync.WaitGroup
 
 	if h.cmdListener != nil && h.cmdHandler != nil {
@@ -147,11 +318,30 @@ func (h *Process) Start() {
 		}()
 	}
 
-	// Start the tasklet lifecycle.
-	h.TaskletHandler.Enable()
-	h.TaskletHandler.Start()
+	if h.safeMode.Load() {
+		// wait for stop, only command handling is active in safe mode
+		for h.TermEvent.Wait(1) {
+		}
+	} else {
+		// Start the tasklet lifecycle.
+		h.TaskletHandler.Enable()
+		h.TaskletHandler.Start()
+	}
 
 	waitGrp.Wait()
+
+	// mark clean stop
+	if h.bootCounter != nil {
+		var err error
+		if h.safeMode.Load() {
+			err = h.bootCounter.Reset()
+		} else {
+			err = h.bootCounter.Clear()
+		}
+		if err != nil {
+			h.Log.Error("boot counter failed: %s", err.Error())
+		}
+	}
 }
 
 // Stop stop the process.
@@ -0,0 +1,115 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc
+
+import (
+	"errors"
+	"runtime/debug"
+	"syscall"
+	"time"
+)
+
+// RELOAD_CMD defines the management command reloading the process.
+const RELOAD_CMD = "reload"
+
+// RELOAD_TIMEOUT defines the default timeout in seconds to wait for the
+// reload handler to run.
+const RELOAD_TIMEOUT = 30
+
+// ErrReloadTimeout indicates the reload did not run within timeout.
+var ErrReloadTimeout = errors.New("reload timeout")
+
+// SetReloadHandler sets the function reloading the process configuration,
+// triggered by the SIGHUP signal and the reload management command. The
+// handler runs in the tasklet goroutine between execution iterations, so
+// it never runs concurrently with the tasklet Execute. A reload request
+// wakes up the tasklet sleep, see [TaskletHandler.Sleep], so the reload
+// runs once the current Execute returns. Handler errors are logged and
+// reported to the reload command, and the process keeps running with its
+// current configuration.
+// Setting nil handler restores stopping the process on SIGHUP.
+func (h *Process) SetReloadHandler(fn func() error) {
+	h.reloadLock.Lock()
+	h.reloadHandler = fn
+	h.reloadLock.Unlock()
+	if fn == nil {
+		h.sigHandlers[syscall.SIGHUP] = h.Stop
+		return
+	}
+	h.sigHandlers[syscall.SIGHUP] = func() {
+		// don't block signals handling waiting for reload
+		go h.Reload(0)
+	}
+}
+
+// Reload requests a configuration reload and waits up to timeout seconds
+// for the reload handler to run, returning the handler error. The reload
+// runs immediately if the tasklet is not running or in safe mode.
+// Setting timeout=0 will use the default timeout [RELOAD_TIMEOUT].
+func (h *Process) Reload(timeout float64) error {
+	if timeout <= 0 {
+		timeout = RELOAD_TIMEOUT
+	}
+	ch := make(chan error, 1)
+	h.reloadLock.Lock()
+	if h.reloadHandler == nil {
+		h.reloadLock.Unlock()
+		return errors.New("reload not supported")
+	}
+	h.reloadWaiters = append(h.reloadWaiters, ch)
+	h.reloadPending.Store(true)
+	h.reloadLock.Unlock()
+
+	if h.safeMode.Load() || !h.IsInitialized() {
+		h.runReload()
+	} else {
+		h.wakeUp()
+	}
+
+	select {
+	case err := <-ch:
+		return err
+	case <-time.After(time.Duration(timeout * float64(time.Second))):
+		h.Log.Warn("reload still pending after %.1fs", timeout)
+		return ErrReloadTimeout
+	}
+}
+
+// runReload runs the reload handler if a reload is pending and reports
+// the result to the waiting requests.
+func (h *Process) runReload() {
+	if !h.reloadPending.Load() {
+		return
+	}
+	h.reloadRunLock.Lock()
+	defer h.reloadRunLock.Unlock()
+
+	h.reloadLock.Lock()
+	fn, waiters := h.reloadHandler, h.reloadWaiters
+	h.reloadWaiters = nil
+	h.reloadPending.Store(false)
+	h.reloadLock.Unlock()
+	if len(waiters) == 0 || fn == nil {
+		return
+	}
+
+	h.Log.Info("reloading")
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = h.recovered(r, debug.Stack())
+			}
+		}()
+		return fn()
+	}()
+	if err != nil {
+		h.Log.Error("reload failed: %s", err.Error())
+	} else {
+		h.Log.Info("reload done")
+	}
+	for _, ch := range waiters {
+		ch <- err
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/events"
+	"github.com/exonlabs/go-utils/pkg/logging"
+)
+
+// Routine lifecycle transitions recorded in replay mode.
+const (
+	REPLAY_INITIALIZED = "initialized"
+	REPLAY_EXECUTED    = "executed"
+	REPLAY_EXEC_ERROR  = "exec_error"
+	REPLAY_FAILED      = "failed"
+	REPLAY_RESTARTING  = "restarting"
+	REPLAY_DISABLED    = "disabled"
+	REPLAY_TERMINATED  = "terminated"
+)
+
+// Transition defines a recorded routine lifecycle transition.
+type Transition struct {
+	// Time defines the fake clock time of transition.
+	Time time.Time
+	// Routine defines the routine name.
+	Routine string
+	// Event defines the transition event.
+	Event string
+	// Err defines the transition error if any.
+	Err error
+}
+
+// String returns the transition text representation.
+func (t Transition) String() string {
+	s := fmt.Sprintf("%s %s %s",
+		t.Time.Format(time.RFC3339), t.Routine, t.Event)
+	if t.Err != nil {
+		s += ": " + t.Err.Error()
+	}
+	return s
+}
+
+// replayClock defines the clock of a routine in replay mode, where sleeps
+// schedule the routine next step on the fake clock instead of blocking.
+type replayClock struct {
+	*FakeClock
+	// time of next step, set by sleeps and restart backoff
+	wakeAt time.Time
+}
+
+// Sleep schedules the next step after timeout seconds. It returns false
+// if any of the events is set.
+func (c *replayClock) Sleep(timeout float64, evts ...*events.Event) bool {
+	c.wakeAt = c.Now().Add(time.Duration(timeout * float64(time.Second)))
+	for _, e := range evts {
+		if e.IsSet() {
+			return false
+		}
+	}
+	return true
+}
+
+// isDue checks if the next step time is reached.
+func (c *replayClock) isDue() bool {
+	return !c.Now().Before(c.wakeAt)
+}
+
+// ReplayManager defines a deterministic routine manager for tests. It runs
+// the [RoutineManager] and the routines lifecycle synchronously step-by-step
+// under test control on a fake clock, where [TaskletHandler.Sleep] and the
+// restart backoff schedule the routine next step on the fake clock instead
+// of blocking. Routines are started after their dependencies and stepped
+// in startup order, and the lifecycle transitions are recorded.
+//
+//	clock := proc.NewFakeClock(time.Now())
+//	m := proc.NewReplayManager(log, clock)
+//	m.AddRoutine("worker", worker.RoutineHandler, true)
+//	m.RunOnce()                  // initializes and executes worker
+//	m.AdvanceTime(5*time.Second) // runs steps due within 5 seconds
+//	m.Transitions()
+type ReplayManager struct {
+	*RoutineManager
+
+	// Clock is the fake clock driving the routines.
+	Clock *FakeClock
+
+	transitions []Transition
+	mu          sync.Mutex
+}
+
+// NewReplayManager creates a new replay manager on the fake clock.
+func NewReplayManager(log *logging.Logger, clock *FakeClock) *ReplayManager {
+	m := &ReplayManager{
+		RoutineManager: NewRoutineManager(log),
+		Clock:          clock,
+	}
+	m.SetClock(&replayClock{FakeClock: clock})
+	m.launch = m.start
+	return m
+}
+
+// AddRoutine adds a routine handler to the replay manager, with optional
+// dependencies and priority, see [DependsOn] and [Priority].
+func (m *ReplayManager) AddRoutine(name string, h *TaskletHandler,
+	enabled bool, opts ...RoutineOption) error {
+	h.SetClock(&replayClock{FakeClock: m.Clock})
+	h.trace = func(event string, err error) { m.record(name, event, err) }
+	h.TermEvent.Clear()
+	h.KillEvent.Clear()
+	return m.RoutineManager.AddRoutine(name, h, enabled, opts...)
+}
+
+// Transitions returns the recorded lifecycle transitions.
+func (m *ReplayManager) Transitions() []Transition {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Transition(nil), m.transitions...)
+}
+
+// Events returns the recorded transition events of routine.
+func (m *ReplayManager) Events(name string) []string {
+	res := []string{}
+	for _, t := range m.Transitions() {
+		if t.Routine == name {
+			res = append(res, t.Event)
+		}
+	}
+	return res
+}
+
+// RunOnce runs the routine manager monitoring if due, then one step for
+// each running routine due at current fake time, in startup order.
+func (m *ReplayManager) RunOnce() {
+	if m.clock.(*replayClock).isDue() {
+		m.Execute()
+	}
+	for _, h := range m.handlers() {
+		if h.IsAlive() && h.clock.(*replayClock).isDue() {
+			m.step(h)
+		}
+	}
+}
+
+// AdvanceTime moves the fake clock forward by d, running the manager and
+// routine steps at each due time within d in order.
+func (m *ReplayManager) AdvanceTime(d time.Duration) {
+	tEnd := m.Clock.Now().Add(d)
+	for {
+		now, next := m.Clock.Now(), tEnd
+		clocks := []*replayClock{m.clock.(*replayClock)}
+		for _, h := range m.handlers() {
+			if h.IsAlive() {
+				clocks = append(clocks, h.clock.(*replayClock))
+			}
+		}
+		for _, c := range clocks {
+			if c.wakeAt.After(now) && c.wakeAt.Before(next) {
+				next = c.wakeAt
+			}
+		}
+		m.Clock.Set(next)
+		m.RunOnce()
+		if !next.Before(tEnd) {
+			return
+		}
+	}
+}
+
+// Stop disables and terminates all routines in reverse startup order.
+func (m *ReplayManager) Stop() {
+	hs := m.handlers()
+	for i := len(hs) - 1; i >= 0; i-- {
+		hs[i].Disable()
+		hs[i].Stop()
+		for hs[i].IsAlive() {
+			m.step(hs[i])
+		}
+	}
+}
+
+// start starts the routine lifecycle, stepped by the replay manager.
+func (m *ReplayManager) start(rt Routine) {
+	if h, ok := rt.(*TaskletHandler); ok && isReplay(h) && !h.IsAlive() {
+		h.clock.(*replayClock).wakeAt = time.Time{}
+		h.begin()
+		h.isAlive.Store(true)
+	}
+}
+
+// step runs one lifecycle step of the routine handler.
+func (m *ReplayManager) step(h *TaskletHandler) {
+	if !h.step() {
+		h.isAlive.Store(false)
+	}
+}
+
+// isReplay checks if the routine handler runs in replay mode.
+func isReplay(h *TaskletHandler) bool {
+	_, ok := h.clock.(*replayClock)
+	return ok
+}
+
+// handlers returns the replay routine handlers in startup order, or in names
+// order if the startup order is invalid.
+func (m *ReplayManager) handlers() []*TaskletHandler {
+	m.rtBuffLock.Lock()
+	defer m.rtBuffLock.Unlock()
+
+	order, err := m.routineOrder()
+	if err != nil {
+		order = make([]string, 0, len(m.rtBuffer))
+		for n := range m.rtBuffer {
+			order = append(order, n)
+		}
+		sort.Strings(order)
+	}
+	res := make([]*TaskletHandler, 0, len(order))
+	for _, n := range order {
+		if h, ok := m.rtBuffer[n].(*TaskletHandler); ok && isReplay(h) {
+			res = append(res, h)
+		}
+	}
+	return res
+}
+
+// record adds a lifecycle transition.
+func (m *ReplayManager) record(name, event string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.transitions = append(m.transitions, Transition{
+		Time: m.Clock.Now(), Routine: name, Event: event, Err: err,
+	})
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/logging"
+)
+
+// RESMON_INTERVAL defines the default resource sampling interval in seconds.
+const RESMON_INTERVAL = 10
+
+// resource limits names
+const (
+	LIMIT_RSS        = "rss"
+	LIMIT_HEAP       = "heap"
+	LIMIT_GOROUTINES = "goroutines"
+	LIMIT_FDS        = "fds"
+)
+
+// ResourceStats defines a sample of the process resources usage.
+type ResourceStats struct {
+	// Time is the sampling time.
+	Time time.Time
+	// Rss is the process resident memory size in bytes, 0 if unavailable.
+	Rss uint64
+	// HeapAlloc is the allocated heap objects size in bytes.
+	HeapAlloc uint64
+	// HeapSys is the heap memory obtained from the OS in bytes.
+	HeapSys uint64
+	// Goroutines is the number of running goroutines.
+	Goroutines int
+	// NumGC is the number of completed GC cycles.
+	NumGC uint32
+	// PauseTotal is the cumulative GC pause time.
+	PauseTotal time.Duration
+	// Fds is the number of open file descriptors, or handles on windows,
+	// -1 if unavailable.
+	Fds int
+}
+
+// Dict returns the resource stats as dict.
+func (s ResourceStats) Dict() dictx.Dict {
+	return dictx.Dict{
+		"time":        s.Time.Format(time.RFC3339),
+		"rss":         s.Rss,
+		"heap_alloc":  s.HeapAlloc,
+		"heap_sys":    s.HeapSys,
+		"goroutines":  s.Goroutines,
+		"num_gc":      s.NumGC,
+		"gc_pause_ms": s.PauseTotal.Milliseconds(),
+		"fds":         s.Fds,
+	}
+}
+
+// LimitHandler defines the function called when a resource limit is
+// exceeded, with the exceeded limit name and the resources sample.
+type LimitHandler func(limit string, stats ResourceStats)
+
+// SampleResources returns the current process resources usage.
+func SampleResources() ResourceStats {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ResourceStats{
+		Time:       time.Now(),
+		Rss:        processRss(),
+		HeapAlloc:  ms.HeapAlloc,
+		HeapSys:    ms.HeapSys,
+		Goroutines: runtime.NumGoroutine(),
+		NumGC:      ms.NumGC,
+		PauseTotal: time.Duration(ms.PauseTotalNs),
+		Fds:        processFds(),
+	}
+}
+
+// ResourceMonitor defines a routine sampling the process resources usage
+// periodically. When a limit is exceeded, a warning is logged and the
+// limit handlers are called once, until usage drops below the limit again.
+// Handlers can restart the process or write heap profiles for example.
+//
+// ResourceMonitor implements [Routine] and can be added to routine
+// manager using [RoutineManager.AddRoutine].
+type ResourceMonitor struct {
+	*RoutineHandler
+
+	// Interval defines the sampling interval in seconds.
+	Interval float64
+	// MaxRss defines the resident memory limit in bytes, 0 to disable.
+	MaxRss uint64
+	// MaxHeap defines the allocated heap limit in bytes, 0 to disable.
+	MaxHeap uint64
+	// MaxGoroutines defines the goroutines count limit, 0 to disable.
+	MaxGoroutines int
+	// MaxFds defines the open file descriptors limit, 0 to disable.
+	MaxFds int
+
+	stats    ResourceStats
+	handlers []LimitHandler
+	breached map[string]bool
+	mu       sync.Mutex
+	// serializes sampling and limits checks
+	sampleLock sync.Mutex
+}
+
+// NewResourceMonitor creates a new resource monitor routine.
+// The parsed options are:
+//   - resmon_interval: (float64) the sampling interval in seconds.
+//     (default is 10)
+//   - resmon_max_rss: (uint) the resident memory limit in bytes.
+//     (default is disabled)
+//   - resmon_max_heap: (uint) the allocated heap limit in bytes.
+//     (default is disabled)
+//   - resmon_max_goroutines: (int) the goroutines count limit.
+//     (default is disabled)
+//   - resmon_max_fds: (int) the open file descriptors limit.
+//     (default is disabled)
+func NewResourceMonitor(log *logging.Logger, opts dictx.Dict) *ResourceMonitor {
+	m := &ResourceMonitor{
+		Interval:      dictx.GetFloat(opts, "resmon_interval", RESMON_INTERVAL),
+		MaxRss:        uint64(dictx.GetUint(opts, "resmon_max_rss", 0)),
+		MaxHeap:       uint64(dictx.GetUint(opts, "resmon_max_heap", 0)),
+		MaxGoroutines: dictx.GetInt(opts, "resmon_max_goroutines", 0),
+		MaxFds:        dictx.GetInt(opts, "resmon_max_fds", 0),
+		breached:      map[string]bool{},
+	}
+	m.RoutineHandler = NewRoutineHandler(log, m)
+	return m
+}
+
+// OnLimit adds a handler called when a resource limit is exceeded.
+func (m *ResourceMonitor) OnLimit(h LimitHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers = append(m.handlers, h)
+}
+
+// Stats returns the last resources sample.
+func (m *ResourceMonitor) Stats() ResourceStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+// Initialize takes the initial resources sample.
+func (m *ResourceMonitor) Initialize() error {
+	m.Sample()
+	return nil
+}
+
+// Execute samples the resources and waits for next interval.
+func (m *ResourceMonitor) Execute() error {
+	m.Sample()
+	m.Sleep(m.Interval)
+	return nil
+}
+
+// Terminate is a no-op.
+func (m *ResourceMonitor) Terminate() error {
+	return nil
+}
+
+// Sample takes a resources sample, checks the limits and returns the
+// sample.
+func (m *ResourceMonitor) Sample() ResourceStats {
+	m.sampleLock.Lock()
+	defer m.sampleLock.Unlock()
+
+	st := SampleResources()
+	m.mu.Lock()
+	m.stats = st
+	handlers := append([]LimitHandler(nil), m.handlers...)
+	m.mu.Unlock()
+
+	m.Log.Trace1("rss=%d heap=%d goroutines=%d gc=%d fds=%d",
+		st.Rss, st.HeapAlloc, st.Goroutines, st.NumGC, st.Fds)
+
+	m.check(LIMIT_RSS, m.MaxRss > 0 && st.Rss > m.MaxRss,
+		st.Rss, m.MaxRss, st, handlers)
+	m.check(LIMIT_HEAP, m.MaxHeap > 0 && st.HeapAlloc > m.MaxHeap,
+		st.HeapAlloc, m.MaxHeap, st, handlers)
+	m.check(LIMIT_GOROUTINES,
+		m.MaxGoroutines > 0 && st.Goroutines > m.MaxGoroutines,
+		st.Goroutines, m.MaxGoroutines, st, handlers)
+	m.check(LIMIT_FDS, m.MaxFds > 0 && st.Fds > m.MaxFds,
+		st.Fds, m.MaxFds, st, handlers)
+	return st
+}
+
+// check logs and handles the limit state transitions.
+func (m *ResourceMonitor) check(limit string, exceeded bool,
+	value, max any, st ResourceStats, handlers []LimitHandler) {
+	if !exceeded {
+		if m.breached[limit] {
+			m.breached[limit] = false
+			m.Log.Info("%s usage back within limit, %v <= %v",
+				limit, value, max)
+		}
+		return
+	}
+	if m.breached[limit] {
+		return
+	}
+	m.breached[limit] = true
+	m.Log.Warn("%s usage exceeded limit, %v > %v", limit, value, max)
+	for _, h := range handlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					m.Log.Error("limit handler panic: %v", r)
+				}
+			}()
+			h(limit, st)
+		}()
+	}
+}
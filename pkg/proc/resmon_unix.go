@@ -0,0 +1,47 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package proc
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// processRss returns the current resident memory size from procfs, or
+// falls back to the peak resident size where procfs is not available.
+func processRss() uint64 {
+	if b, err := os.ReadFile("/proc/self/statm"); err == nil {
+		if f := bytes.Fields(b); len(f) > 1 {
+			if n, err := strconv.ParseUint(string(f[1]), 10, 64); err == nil {
+				return n * uint64(os.Getpagesize())
+			}
+		}
+	}
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err == nil {
+		// linux and bsd report kilobytes
+		return uint64(ru.Maxrss) * 1024
+	}
+	return 0
+}
+
+// processFds returns the open file descriptors count.
+func processFds() int {
+	for _, d := range []string{"/proc/self/fd", "/dev/fd"} {
+		if f, err := os.Open(d); err == nil {
+			names, err := f.Readdirnames(-1)
+			f.Close()
+			if err == nil {
+				// exclude the descriptor used for reading the dir
+				return len(names) - 1
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build windows
+
+package proc
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modkernel32               = windows.NewLazySystemDLL("kernel32.dll")
+	procGetProcessMemoryInfo  = modkernel32.NewProc("K32GetProcessMemoryInfo")
+	procGetProcessHandleCount = modkernel32.NewProc("GetProcessHandleCount")
+)
+
+// processMemoryCounters defines the PROCESS_MEMORY_COUNTERS struct.
+type processMemoryCounters struct {
+	Cb                         uint32
+	PageFaultCount             uint32
+	PeakWorkingSetSize         uintptr
+	WorkingSetSize             uintptr
+	QuotaPeakPagedPoolUsage    uintptr
+	QuotaPagedPoolUsage        uintptr
+	QuotaPeakNonPagedPoolUsage uintptr
+	QuotaNonPagedPoolUsage     uintptr
+	PagefileUsage              uintptr
+	PeakPagefileUsage          uintptr
+}
+
+// processRss returns the process working set size.
+func processRss() uint64 {
+	var pmc processMemoryCounters
+	pmc.Cb = uint32(unsafe.Sizeof(pmc))
+	r, _, _ := procGetProcessMemoryInfo.Call(
+		uintptr(windows.CurrentProcess()),
+		uintptr(unsafe.Pointer(&pmc)), uintptr(pmc.Cb))
+	if r == 0 {
+		return 0
+	}
+	return uint64(pmc.WorkingSetSize)
+}
+
+// processFds returns the open handles count.
+func processFds() int {
+	var n uint32
+	r, _, _ := procGetProcessHandleCount.Call(
+		uintptr(windows.CurrentProcess()), uintptr(unsafe.Pointer(&n)))
+	if r == 0 {
+		return -1
+	}
+	return int(n)
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/comm"
+)
+
+// RESTART_CMD defines the management command restarting the process.
+const RESTART_CMD = "restart"
+
+// RESTART_STOP_TIMEOUT defines the default timeout in seconds to wait
+// for the tasklet termination before restarting.
+const RESTART_STOP_TIMEOUT = 10
+
+// EnableRestart enables the restart management command on process.
+// On restart, the tasklet is stopped in orderly manner waiting up to
+// timeout seconds, then a new copy of the binary is started with same
+// arguments and environment, and the new PID is reported to the client.
+// The command listener and the given listeners supporting socket hand over,
+// see [comm.FileListener], are passed to the new process to keep accepting
+// connections on the same sockets.
+// Setting timeout=0 will use the default timeout [RESTART_STOP_TIMEOUT].
+func (h *Process) EnableRestart(timeout float64, listeners ...comm.Listener) {
+	if timeout <= 0 {
+		timeout = RESTART_STOP_TIMEOUT
+	}
+	h.restartTimeout = timeout
+	h.restartListeners = listeners
+}
+
+// Restart restarts the process in background, stopping the tasklet in
+// orderly manner and starting a new process instance with the listeners
+// hand over, see [Process.EnableRestart], then stopping the process.
+// It is safe to call from the tasklet itself, such as after installing
+// a binary update. Restart failures are logged and the tasklet resumes.
+func (h *Process) Restart() {
+	go func() {
+		if _, err := h.restart(); err == nil {
+			h.Stop()
+		}
+	}()
+}
+
+// restart stops the tasklet and starts a new process instance,
+// returning the new process PID.
+func (h *Process) restart() (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+
+	// collect listening sockets to hand over
+	uris := []string{}
+	files := []*os.File{}
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	if runtime.GOOS != "windows" {
+		ls := append([]comm.Listener{h.cmdListener}, h.restartListeners...)
+		for _, l := range ls {
+			fl, ok := l.(comm.FileListener)
+			if !ok || !fl.IsActive() {
+				continue
+			}
+			f, err := fl.File()
+			if err != nil {
+				return 0, fmt.Errorf("listener %s - %w", fl.Uri(), err)
+			}
+			uris = append(uris, fl.Uri())
+			files = append(files, f)
+		}
+	}
+
+	// orderly stop of tasklet
+	h.Log.Info("restarting process")
+	h.TaskletHandler.Disable()
+	h.TaskletHandler.Stop()
+	timeout := h.restartTimeout
+	if timeout <= 0 {
+		timeout = RESTART_STOP_TIMEOUT
+	}
+	tBreak := time.Now().Add(time.Duration(timeout * float64(time.Second)))
+	for h.IsAlive() && time.Now().Before(tBreak) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if h.IsAlive() {
+		h.TaskletHandler.Kill()
+		h.Log.Warn("tasklet stop timeout, killed")
+	}
+
+	env := []string{}
+	for _, e := range os.Environ() {
+		if !strings.HasPrefix(e, comm.INHERIT_ENV+"=") {
+			env = append(env, e)
+		}
+	}
+	if len(uris) > 0 {
+		env = append(env, comm.InheritEnv(uris))
+	}
+
+	// release pid file for the new process
+	if h.pidFile != nil {
+		if err := h.pidFile.Release(); err != nil {
+			h.Log.Error("pid file %s failed: %s", h.pidFile.Path, err.Error())
+		}
+	}
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Env = env
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = files
+	if err := cmd.Start(); err != nil {
+		// resume tasklet on failure
+		h.Log.Error("restart failed: %s", err.Error())
+		if h.pidFile != nil {
+			if _, err := h.pidFile.Acquire(); err != nil {
+				h.Log.Error("pid file %s failed: %s",
+					h.pidFile.Path, err.Error())
+			}
+		}
+		if !h.safeMode.Load() {
+			h.TaskletHandler.Enable()
+			go h.TaskletHandler.Start()
+		}
+		return 0, err
+	}
+	pid := cmd.Process.Pid
+	cmd.Process.Release()
+	h.Log.Info("restarted process, new pid: %d", pid)
+	return pid, nil
+}
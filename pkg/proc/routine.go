@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
 	"github.com/exonlabs/go-utils/pkg/logging"
 )
 
@@ -40,21 +42,35 @@ type RoutineManager struct {
 	rtBuffer map[string]Routine
 	// rtBuffLock is used to synchronize access to rtBuffer.
 	rtBuffLock sync.Mutex
+	// rtOpts holds the routines dependencies and priorities.
+	rtOpts map[string]routineOptions
+	// depWait holds the start time of routines waiting for dependencies.
+	depWait map[string]time.Time
+	// launch starts a routine lifecycle, see [ReplayManager].
+	launch func(Routine)
 
 	// MonitoringInterval specifies the routines monitoring interval in sec.
 	MonitoringInterval float64
 	// StoppingDelay specifies the duration to wait for routines to stop.
 	StoppingDelay float64
+	// DependencyTimeout specifies the duration in sec to wait for routine
+	// dependencies to initialize before disabling the routine.
+	DependencyTimeout float64
 }
 
 // New creates a new routine manager instance.
 func NewRoutineManager(log *logging.Logger) *RoutineManager {
 	rm := &RoutineManager{
 		rtBuffer:           make(map[string]Routine),
+		rtOpts:             make(map[string]routineOptions),
+		depWait:            make(map[string]time.Time),
+		launch:             func(rt Routine) { go rt.Start() },
 		MonitoringInterval: 300,
 		StoppingDelay:      3,
+		DependencyTimeout:  DEPENDENCY_TIMEOUT,
 	}
 	rm.Process = NewProcessHandler(log, rm)
+	rm.Process.runAsReady = rm.routinesSettled
 	return rm
 }
 
@@ -63,18 +79,25 @@ func (m *RoutineManager) Initialize() error {
 	if len(m.rtBuffer) == 0 {
 		return fmt.Errorf("no routines loaded")
 	}
-	m.Log.Debug("loaded routines: %s", strings.Join(m.ListRoutines(), ", "))
+	m.rtBuffLock.Lock()
+	order, err := m.routineOrder()
+	m.rtBuffLock.Unlock()
+	if err != nil {
+		return err
+	}
+	m.Log.Debug("loaded routines: %s", strings.Join(order, ", "))
 	return nil
 }
 
 // Execute runs the routine check and waits for the specified monitor interval.
 func (m *RoutineManager) Execute() error {
-	for n := range m.rtBuffer {
-		if m.rtBuffer[n].IsEnabled() && !m.rtBuffer[n].IsAlive() {
-			go m.rtBuffer[n].Start()
-		}
+	// poll routines waiting for dependencies, or routines initialization
+	// before switching user
+	if m.startRoutines() || m.runAsPending.Load() {
+		m.Sleep(0.1)
+	} else {
+		m.Sleep(m.MonitoringInterval)
 	}
-	m.Sleep(m.MonitoringInterval)
 	return nil
 }
 
@@ -87,15 +110,7 @@ func (m *RoutineManager) Terminate() error {
 	}()
 
 	m.Log.Info("stopping all activated routines")
-	m.rtBuffLock.Lock()
-	for n := range m.rtBuffer {
-		m.rtBuffer[n].Disable()
-		if m.rtBuffer[n].IsAlive() {
-			m.Log.Info("stopping routine: %s", n)
-			m.rtBuffer[n].Stop()
-		}
-	}
-	m.rtBuffLock.Unlock()
+	m.stopRoutines()
 
 	// no wait required
 	if m.StoppingDelay <= 0 {
@@ -144,8 +159,10 @@ func (m *RoutineManager) ListRoutines() []string {
 	return names
 }
 
-// AddRoutine adds a new routine to the routine manager.
-func (m *RoutineManager) AddRoutine(name string, rt Routine, enabled bool) error {
+// AddRoutine adds a new routine to the routine manager, with optional
+// dependencies and priority, see [DependsOn] and [Priority].
+func (m *RoutineManager) AddRoutine(
+	name string, rt Routine, enabled bool, opts ...RoutineOption) error {
 	m.rtBuffLock.Lock()
 	defer m.rtBuffLock.Unlock()
 
@@ -153,14 +170,37 @@ func (m *RoutineManager) AddRoutine(name string, rt Routine, enabled bool) error
 		return fmt.Errorf("duplicate routine name")
 	}
 
+	o := routineOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	// dependencies are validated on initialization for loaded routines
+	if m.IsInitialized() {
+		for _, d := range o.deps {
+			if _, ok := m.rtBuffer[d]; !ok {
+				return fmt.Errorf("unknown dependency %s", d)
+			}
+		}
+	}
 	m.rtBuffer[name] = rt
+	m.rtOpts[name] = o
 	if enabled {
 		rt.Enable()
 	}
 	m.Log.Trace1("added routine: %s", name)
 
-	if m.IsInitialized() {
-		go rt.Start()
+	// start routine if dependencies are initialized, else the routine is
+	// started by the manager monitoring.
+	if m.IsInitialized() && rt.IsEnabled() {
+		ready := true
+		for _, d := range o.deps {
+			if dep, ok := m.rtBuffer[d]; !ok || !dep.IsInitialized() {
+				ready = false
+			}
+		}
+		if ready {
+			m.launch(rt)
+		}
 	}
 	return nil
 }
@@ -173,6 +213,13 @@ func (m *RoutineManager) DelRoutine(name string) error {
 	if _, ok := m.rtBuffer[name]; !ok {
 		return fmt.Errorf("invalid routine name")
 	}
+	for n, o := range m.rtOpts {
+		for _, d := range o.deps {
+			if d == name {
+				return fmt.Errorf("routine is required by: %s", n)
+			}
+		}
+	}
 
 	m.rtBuffer[name].Disable()
 	if m.rtBuffer[name].IsAlive() {
@@ -187,6 +234,8 @@ func (m *RoutineManager) DelRoutine(name string) error {
 
 	m.Log.Trace1("deleting routine: %s", name)
 	delete(m.rtBuffer, name)
+	delete(m.rtOpts, name)
+	delete(m.depWait, name)
 	return nil
 }
 
@@ -202,7 +251,7 @@ func (m *RoutineManager) StartRoutine(name string) error {
 	m.rtBuffer[name].Enable()
 	if !m.rtBuffer[name].IsAlive() {
 		m.Log.Trace1("activating routine: %s", name)
-		go m.rtBuffer[name].Start()
+		m.launch(m.rtBuffer[name])
 	} else {
 		m.Log.Trace1("already running routine: %s", name)
 	}
@@ -239,7 +288,110 @@ func (m *RoutineManager) RestartRoutine(name string) error {
 		m.rtBuffer[name].Stop()
 	} else {
 		m.Log.Trace1("starting routine: %s", name)
-		go m.rtBuffer[name].Start()
+		m.launch(m.rtBuffer[name])
 	}
 	return nil
 }
+
+// restartHandler defines the routines supporting restart policies,
+// implemented by [RoutineHandler].
+type restartHandler interface {
+	SetRestartPolicy(p RestartPolicy)
+	RestartCount() int
+	LastError() error
+}
+
+// SetRestartPolicy sets the restart policy applied after routine failures.
+func (m *RoutineManager) SetRestartPolicy(name string, p RestartPolicy) error {
+	m.rtBuffLock.Lock()
+	defer m.rtBuffLock.Unlock()
+
+	if _, ok := m.rtBuffer[name]; !ok {
+		return fmt.Errorf("invalid routine name")
+	}
+	rt, ok := m.rtBuffer[name].(restartHandler)
+	if !ok {
+		return fmt.Errorf("restart policy not supported by routine: %s", name)
+	}
+	rt.SetRestartPolicy(p)
+	return nil
+}
+
+// RestartCounts returns the number of restarts after failures by routine
+// name, for routines supporting restart policies.
+func (m *RoutineManager) RestartCounts() map[string]int {
+	m.rtBuffLock.Lock()
+	defer m.rtBuffLock.Unlock()
+
+	res := map[string]int{}
+	for n, rt := range m.rtBuffer {
+		if v, ok := rt.(restartHandler); ok {
+			res[n] = v.RestartCount()
+		}
+	}
+	return res
+}
+
+// statusHandler defines the routines supporting execution status,
+// implemented by [RoutineHandler].
+type statusHandler interface {
+	LastExecute() time.Time
+	GoroutineId() uint64
+	IsStuck() bool
+}
+
+// Status returns the status snapshot of routines by routine name.
+// The status of each routine has keys:
+//   - state: (string) the routine state {running|stopping|stopped|disabled}.
+//   - enabled: (bool) the routine enabled flag.
+//   - alive: (bool) the routine running flag.
+//   - initialized: (bool) the routine initialization flag.
+//
+// and for routines based on [RoutineHandler]:
+//   - last_error: (string) the last failure error, empty if no failure.
+//   - last_execute: (string) the last execution time in RFC3339 format,
+//     empty if not executed yet.
+//   - restarts: (int) the number of restarts after failures.
+//   - goroutine_id: (uint64) the id of goroutine running the routine.
+//   - stuck: (bool) the routine execution or termination exceeding
+//     its timeout.
+func (m *RoutineManager) Status() dictx.Dict {
+	m.rtBuffLock.Lock()
+	defer m.rtBuffLock.Unlock()
+
+	res := dictx.Dict{}
+	for n, rt := range m.rtBuffer {
+		state := "stopped"
+		switch {
+		case rt.IsAlive() && rt.IsEnabled():
+			state = "running"
+		case rt.IsAlive():
+			state = "stopping"
+		case !rt.IsEnabled():
+			state = "disabled"
+		}
+		st := dictx.Dict{
+			"state":       state,
+			"enabled":     rt.IsEnabled(),
+			"alive":       rt.IsAlive(),
+			"initialized": rt.IsInitialized(),
+		}
+		if v, ok := rt.(restartHandler); ok {
+			st["restarts"] = v.RestartCount()
+			st["last_error"] = ""
+			if err := v.LastError(); err != nil {
+				st["last_error"] = err.Error()
+			}
+		}
+		if v, ok := rt.(statusHandler); ok {
+			st["last_execute"] = ""
+			if t := v.LastExecute(); !t.IsZero() {
+				st["last_execute"] = t.Format(time.RFC3339)
+			}
+			st["goroutine_id"] = v.GoroutineId()
+			st["stuck"] = v.IsStuck()
+		}
+		res[n] = st
+	}
+	return res
+}
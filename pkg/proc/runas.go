@@ -0,0 +1,68 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc
+
+import "fmt"
+
+// SetRunAs sets the user and group to switch the process to after the
+// privileged initialization, so privileged operations such as binding low
+// ports are done in initialization. The process switches user before the
+// first tasklet execution once the command listener is active, and for
+// [RoutineManager] once all enabled routines have initialized or failed.
+// The user and group are names or ids, and empty group uses the user
+// primary group. The supplementary groups are set to the user groups.
+// Switching is skipped if the process already runs as the user, and
+// failures stop the process with the error as exit error, see
+// [Process.ExitError]. Not supported on windows.
+func (h *Process) SetRunAs(user, group string) {
+	h.runAsUser, h.runAsGroup = user, group
+	h.runAsPending.Store(user != "")
+}
+
+// switchUser switches the process user if pending, once the command
+// listener is active and the privileged initialization is done.
+func (h *Process) switchUser() error {
+	if !h.runAsPending.Load() {
+		return nil
+	}
+	// wait for the command listener binding
+	for h.cmdListener != nil && h.cmdHandler != nil &&
+		!h.cmdListener.IsActive() {
+		if !h.clock.Sleep(0.05, h.TermEvent) {
+			return nil
+		}
+	}
+	if h.runAsReady != nil && !h.runAsReady() {
+		return nil
+	}
+
+	h.runAsPending.Store(false)
+	if err := runAs(h.runAsUser, h.runAsGroup); err != nil {
+		err = fmt.Errorf("switch to user %s failed: %w", h.runAsUser, err)
+		h.Log.Error(err.Error())
+		h.StopWithError(err)
+		return err
+	}
+	h.Log.Debug("switched to user %s", h.runAsUser)
+	return nil
+}
+
+// routinesSettled checks if all enabled routines have initialized or
+// failed, so routines are done binding privileged resources.
+func (m *RoutineManager) routinesSettled() bool {
+	m.rtBuffLock.Lock()
+	defer m.rtBuffLock.Unlock()
+
+	for _, rt := range m.rtBuffer {
+		if !rt.IsEnabled() || rt.IsInitialized() {
+			continue
+		}
+		if v, ok := rt.(restartHandler); ok && v.LastError() != nil {
+			continue
+		}
+		return false
+	}
+	return true
+}
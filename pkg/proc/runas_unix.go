@@ -0,0 +1,86 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package proc
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// runAs switches the process user, group and supplementary groups.
+func runAs(usr, group string) error {
+	uid, gid, groups, err := lookupCredential(usr, group)
+	if err != nil {
+		return err
+	}
+	return setCredential(uid, gid, groups)
+}
+
+// setCredential switches the process uid, gid and supplementary groups.
+func setCredential(uid, gid uint32, groups []uint32) error {
+	if os.Geteuid() == int(uid) && os.Getegid() == int(gid) {
+		return nil
+	}
+
+	gids := make([]int, len(groups))
+	for i, g := range groups {
+		gids[i] = int(g)
+	}
+	if err := syscall.Setgroups(gids); err != nil {
+		return fmt.Errorf("setgroups: %w", err)
+	}
+	if err := syscall.Setgid(int(gid)); err != nil {
+		return fmt.Errorf("setgid %d: %w", gid, err)
+	}
+	if err := syscall.Setuid(int(uid)); err != nil {
+		return fmt.Errorf("setuid %d: %w", uid, err)
+	}
+	return nil
+}
+
+// lookupCredential returns the uid, gid and supplementary groups of user
+// and group names or ids. empty group uses the user primary group.
+func lookupCredential(usr, group string) (uint32, uint32, []uint32, error) {
+	u, err := user.Lookup(usr)
+	if err != nil {
+		if u, err = user.LookupId(usr); err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid user %s", usr)
+		}
+	}
+	gid := u.Gid
+	if group != "" {
+		g, err := user.LookupGroup(group)
+		if err != nil {
+			if g, err = user.LookupGroupId(group); err != nil {
+				return 0, 0, nil, fmt.Errorf("invalid group %s", group)
+			}
+		}
+		gid = g.Gid
+	}
+
+	uidN, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	gidN, err := strconv.ParseUint(gid, 10, 32)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	groups := []uint32{uint32(gidN)}
+	if ids, err := u.GroupIds(); err == nil {
+		for _, id := range ids {
+			if n, err := strconv.ParseUint(id, 10, 32); err == nil &&
+				uint32(n) != uint32(gidN) {
+				groups = append(groups, uint32(n))
+			}
+		}
+	}
+	return uint32(uidN), uint32(gidN), groups, nil
+}
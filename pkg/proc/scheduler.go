@@ -0,0 +1,311 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/logging"
+)
+
+const (
+	// MISSED_RUN_ONCE defines the missed runs policy to run the job once
+	// for all missed activations.
+	MISSED_RUN_ONCE = "run_once"
+	// MISSED_SKIP defines the missed runs policy to skip missed activations.
+	MISSED_SKIP = "skip"
+
+	// SCHEDULER_MISSED_GRACE defines the default delay in seconds after
+	// which a job activation is considered missed.
+	SCHEDULER_MISSED_GRACE = 5
+	// SCHEDULER_POLL_INTERVAL defines the max interval in seconds between
+	// scheduler checks.
+	SCHEDULER_POLL_INTERVAL = 1
+)
+
+// JobFunc defines the scheduled job function. The context is canceled
+// on job timeout or scheduler termination.
+type JobFunc func(ctx context.Context) error
+
+// Job represents a scheduled job.
+type Job struct {
+	// Name defines the job name.
+	Name string
+	// Spec defines the job schedule spec, see [ParseSchedule].
+	Spec string
+	// Jitter defines the max random delay in seconds added to activations.
+	Jitter float64
+	// Timeout defines the job run timeout in seconds. use 0 to disable.
+	Timeout float64
+	// MissedPolicy defines the missed runs policy {run_once|skip}.
+	MissedPolicy string
+	// MissedGrace defines the delay in seconds after which an activation
+	// is considered missed.
+	MissedGrace float64
+
+	// fn is the job function.
+	fn JobFunc
+	// schedule is the parsed job schedule.
+	schedule Schedule
+
+	// next holds the next scheduled activation time.
+	next time.Time
+	// due holds the next activation time including jitter.
+	due time.Time
+	// running marks the job as running.
+	running bool
+	// lastRun holds the last run start time.
+	lastRun time.Time
+	// lastErr holds the last run error.
+	lastErr error
+}
+
+// JobStatus represents the job status snapshot.
+type JobStatus struct {
+	Name    string
+	Spec    string
+	Next    time.Time
+	LastRun time.Time
+	LastErr error
+	Running bool
+}
+
+// Scheduler is a routine running registered jobs on cron expressions or
+// fixed intervals, see [ParseSchedule]. It is managed as any routine by
+// the routine manager.
+type Scheduler struct {
+	*RoutineHandler
+
+	// jobs holds the registered jobs by name.
+	jobs map[string]*Job
+	// ctx is canceled on scheduler termination.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// mu defines mutex for jobs.
+	mu sync.Mutex
+	// waitGrp defines wait group for running jobs.
+	waitGrp sync.WaitGroup
+}
+
+// NewScheduler creates a new scheduler routine.
+func NewScheduler(log *logging.Logger) *Scheduler {
+	s := &Scheduler{
+		jobs: map[string]*Job{},
+	}
+	s.RoutineHandler = NewRoutineHandler(log, s)
+	return s
+}
+
+// AddJob registers a new scheduled job.
+// The parsed options are:
+//   - jitter: (float64) the max random delay in seconds added to
+//     activations. (default is 0)
+//   - timeout: (float64) the job run timeout in seconds. use 0 to
+//     disable. (default is 0)
+//   - missed_policy: (string) the missed runs policy {run_once|skip},
+//     applied when activations are missed due to clock changes, system
+//     suspend or previous run overlap. (default is run_once)
+//   - missed_grace: (float64) the delay in seconds after which an
+//     activation is considered missed. (default is 5)
+func (s *Scheduler) AddJob(name, spec string, fn JobFunc, opts dictx.Dict) error {
+	if name == "" || fn == nil {
+		return errors.New("empty job name or function")
+	}
+	sch, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+
+	j := &Job{
+		Name:    name,
+		Spec:    spec,
+		Jitter:  dictx.GetFloat(opts, "jitter", 0),
+		Timeout: dictx.GetFloat(opts, "timeout", 0),
+		MissedPolicy: strings.ToLower(dictx.GetString(
+			opts, "missed_policy", MISSED_RUN_ONCE)),
+		MissedGrace: dictx.GetFloat(
+			opts, "missed_grace", SCHEDULER_MISSED_GRACE),
+		fn:       fn,
+		schedule: sch,
+	}
+	if j.MissedPolicy != MISSED_RUN_ONCE && j.MissedPolicy != MISSED_SKIP {
+		return fmt.Errorf("invalid missed policy: %s", j.MissedPolicy)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("duplicate job name")
+	}
+	s.scheduleJob(j, time.Now())
+	s.jobs[name] = j
+	s.Log.Trace1("added job: %s (%s)", name, spec)
+	return nil
+}
+
+// DelJob removes a scheduled job, a running job is not interrupted.
+func (s *Scheduler) DelJob(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[name]; !ok {
+		return fmt.Errorf("invalid job name")
+	}
+	delete(s.jobs, name)
+	s.Log.Trace1("deleted job: %s", name)
+	return nil
+}
+
+// Jobs returns the status of scheduled jobs sorted by name.
+func (s *Scheduler) Jobs() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		res = append(res, JobStatus{
+			Name:    j.Name,
+			Spec:    j.Spec,
+			Next:    j.next,
+			LastRun: j.lastRun,
+			LastErr: j.lastErr,
+			Running: j.running,
+		})
+	}
+	sort.Slice(res, func(i, k int) bool { return res[i].Name < res[k].Name })
+	return res
+}
+
+// scheduleJob sets the job next activation after t. requires lock to be held.
+func (s *Scheduler) scheduleJob(j *Job, t time.Time) {
+	j.next = j.schedule.Next(t)
+	j.due = j.next
+	if j.Jitter > 0 && !j.next.IsZero() {
+		j.due = j.next.Add(
+			time.Duration(rand.Float64() * j.Jitter * float64(time.Second)))
+	}
+}
+
+// Initialize prepares the scheduler and computes the jobs activations.
+func (s *Scheduler) Initialize() error {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for _, j := range s.jobs {
+		s.scheduleJob(j, now)
+	}
+	return nil
+}
+
+// Execute runs the due jobs and waits for the next activation.
+func (s *Scheduler) Execute() error {
+	now := time.Now()
+	tWait := time.Duration(SCHEDULER_POLL_INTERVAL * float64(time.Second))
+
+	s.mu.Lock()
+	for _, j := range s.jobs {
+		if j.due.IsZero() {
+			continue
+		}
+		if j.due.After(now) {
+			if d := j.due.Sub(now); d < tWait {
+				tWait = d
+			}
+			continue
+		}
+
+		late := now.Sub(j.due).Seconds()
+		if j.running {
+			s.Log.Warn("job %s: missed activation, previous run active", j.Name)
+			s.scheduleJob(j, now)
+			continue
+		}
+		if late > j.MissedGrace {
+			s.Log.Warn("job %s: missed activation by %.1fs", j.Name, late)
+			if j.MissedPolicy == MISSED_SKIP {
+				s.scheduleJob(j, now)
+				continue
+			}
+		}
+
+		s.runJob(j)
+		s.scheduleJob(j, now)
+	}
+	s.mu.Unlock()
+
+	s.Sleep(tWait.Seconds())
+	return nil
+}
+
+// runJob runs the job in a new goroutine. requires lock to be held.
+func (s *Scheduler) runJob(j *Job) {
+	j.running = true
+	j.lastRun = time.Now()
+
+	ctx, cancel := s.ctx, context.CancelFunc(func() {})
+	if j.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(
+			s.ctx, time.Duration(j.Timeout*float64(time.Second)))
+	}
+
+	s.waitGrp.Add(1)
+	go func() {
+		defer s.waitGrp.Done()
+		defer cancel()
+
+		var err error
+		defer func() {
+			if r := recover(); r != nil {
+				stack := debug.Stack()
+				indx := bytes.Index(stack, []byte("panic({"))
+				if indx < 0 {
+					indx = 0
+				}
+				s.Log.Error("job %s: %s", j.Name, r)
+				s.Log.Trace1("\n----------\n%s----------", stack[indx:])
+				err = fmt.Errorf("panic: %v", r)
+			}
+			s.mu.Lock()
+			j.running = false
+			j.lastErr = err
+			s.mu.Unlock()
+		}()
+
+		s.Log.Trace1("job %s: started", j.Name)
+		err = j.fn(ctx)
+		if err == nil && ctx.Err() == context.DeadlineExceeded {
+			err = ctx.Err()
+		}
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				s.Log.Error("job %s: timeout", j.Name)
+			} else {
+				s.Log.Error("job %s: %s", j.Name, err.Error())
+			}
+		} else {
+			s.Log.Trace1("job %s: done", j.Name)
+		}
+	}()
+}
+
+// Terminate cancels the running jobs and waits for them to finish.
+func (s *Scheduler) Terminate() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.waitGrp.Wait()
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package proc_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/proc"
+)
+
+func TestReloadSignal(t *testing.T) {
+	rm, reloads, stop := startReloadManager(t, "mem@reload_sig", nil)
+	defer stop()
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	assert.Eventually(t, func() bool {
+		return reloads.Load() == 1
+	}, time.Second, 10*time.Millisecond)
+	// process keeps running after reload
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, rm.IsAlive())
+	assert.False(t, rm.TermEvent.IsSet())
+}
+
+// isRunning checks if process pid is running, where zombie processes
+// not reaped by their parent are not running.
+func isRunning(pid int) bool {
+	if b, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid)); err == nil {
+		// state field follows the command name in parentheses
+		i := bytes.LastIndexByte(b, ')')
+		return i < 0 || i+2 >= len(b) || b[i+2] != 'Z'
+	}
+	return syscall.Kill(pid, 0) == nil
+}
+
+// descendantPid returns the helper descendant pid from the output logs.
+func descendantPid(t *testing.T, sink *logSink) int {
+	var pid int
+	require.Eventually(t, func() bool {
+		sink.mu.Lock()
+		defer sink.mu.Unlock()
+		for _, r := range sink.records {
+			if _, err := fmt.Sscanf(r, "descendant %d", &pid); err == nil {
+				return true
+			}
+		}
+		return false
+	}, 10*time.Second, 10*time.Millisecond)
+	return pid
+}
+
+func TestProcessSupervisorGroup(t *testing.T) {
+	// stop terminates the command and its descendants
+	s, sink, done := startSupervisor(t, "tree", dictx.Dict{"stop_timeout": 5})
+	pid := descendantPid(t, sink)
+	tStart := time.Now()
+	s.Disable()
+	s.Stop()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("supervisor stop timeout")
+	}
+	assert.Less(t, time.Since(tStart), 5*time.Second)
+	assert.Eventually(t, func() bool {
+		return !isRunning(pid)
+	}, time.Second, 10*time.Millisecond)
+
+	// exit is detected while descendants keep output open
+	s, sink, done = startSupervisor(t, "orphan", dictx.Dict{
+		"restart_policy": proc.RESTART_ON_FAILURE})
+	pid = descendantPid(t, sink)
+	defer syscall.Kill(pid, syscall.SIGKILL)
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("supervisor exit timeout")
+	}
+	assert.Equal(t, 0, s.RestartCount())
+	assert.Equal(t, 1, sink.count("process exited"))
+	assert.Equal(t, 1, sink.count("process output left open"))
+	assert.True(t, isRunning(pid))
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/logging"
+)
+
+const (
+	// OUTPUT_WAIT_DELAY defines the time in seconds to wait for command
+	// output after exit, when the output is kept open by descendants.
+	OUTPUT_WAIT_DELAY = 1
+	// OUTPUT_MAX_LINE defines the max logged command output line size.
+	OUTPUT_MAX_LINE = 1024 * 1024
+)
+
+// ProcessSupervisor spawns and supervises an external OS command as a
+// routine. The command output lines are logged, stdout lines at info
+// level and stderr lines at warning level. The command is restarted when
+// it exits according to the restart policy, where exits with zero status
+// end the supervision unless the policy is RESTART_ALWAYS.
+//
+// ProcessSupervisor implements [Routine] and can be added to routine
+// manager using [RoutineManager.AddRoutine].
+type ProcessSupervisor struct {
+	*RoutineHandler
+
+	// Argv defines the command path and arguments.
+	Argv []string
+	// Env defines the extra environment variables in "key=value" format,
+	// appended to the current process environment.
+	Env []string
+	// Dir defines the command working directory.
+	Dir string
+	// StopTimeout defines the timeout in seconds to wait for command exit
+	// after SIGTERM before sending SIGKILL.
+	StopTimeout float64
+
+	cmd     *exec.Cmd
+	cmdLock sync.Mutex
+	// channel closed when command exits
+	done chan struct{}
+	// command exit error
+	waitErr error
+	// running command pid
+	pid atomic.Int64
+}
+
+// NewProcessSupervisor creates a new supervisor for the command argv.
+// The parsed options are:
+//   - env: ([]string) the extra environment variables in "key=value"
+//     format. (default is none)
+//   - dir: (string) the command working directory. (default is current dir)
+//   - stop_timeout: (float64) the timeout in seconds to wait for command
+//     exit after SIGTERM before sending SIGKILL. (default is 10)
+//
+// and the restart policy options, see [NewRestartPolicy].
+func NewProcessSupervisor(
+	log *logging.Logger, argv []string, opts dictx.Dict) *ProcessSupervisor {
+	s := &ProcessSupervisor{
+		Argv:        argv,
+		Env:         dictx.Fetch(opts, "env", []string(nil)),
+		Dir:         dictx.GetString(opts, "dir", ""),
+		StopTimeout: dictx.GetFloat(opts, "stop_timeout", 10),
+	}
+	s.RoutineHandler = NewRoutineHandler(log, s)
+	s.SetRestartPolicy(NewRestartPolicy(opts))
+	return s
+}
+
+// Pid returns the running command pid, or 0 if not running.
+func (s *ProcessSupervisor) Pid() int {
+	return int(s.pid.Load())
+}
+
+// Initialize spawns the command and starts capturing its output.
+func (s *ProcessSupervisor) Initialize() error {
+	if len(s.Argv) == 0 {
+		return errors.New("empty command")
+	}
+
+	cmd := exec.Command(s.Argv[0], s.Argv[1:]...)
+	cmd.Dir = s.Dir
+	if len(s.Env) > 0 {
+		cmd.Env = append(os.Environ(), s.Env...)
+	}
+	// output is copied to line writers, so waiting command does not
+	// hang on pipes kept open by descendants after command exit
+	stdout, stderr := &lineWriter{fn: s.Log.Info}, &lineWriter{fn: s.Log.Warn}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	cmd.WaitDelay = OUTPUT_WAIT_DELAY * time.Second
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	s.cmdLock.Lock()
+	s.cmd = cmd
+	s.done = make(chan struct{})
+	s.waitErr = nil
+	s.cmdLock.Unlock()
+	s.pid.Store(int64(cmd.Process.Pid))
+	s.Log.Info("started process, pid %d", cmd.Process.Pid)
+
+	go func(done chan struct{}) {
+		err := cmd.Wait()
+		if errors.Is(err, exec.ErrWaitDelay) {
+			s.Log.Warn("process output left open by descendants")
+			err = nil
+		}
+		stdout.flush()
+		stderr.flush()
+		s.cmdLock.Lock()
+		s.waitErr = err
+		s.cmdLock.Unlock()
+		s.pid.Store(0)
+		close(done)
+	}(s.done)
+	return nil
+}
+
+// Execute waits for command exit or supervisor stop.
+func (s *ProcessSupervisor) Execute() error {
+	for s.TermEvent.Wait(0.1) {
+		select {
+		case <-s.done:
+			return s.exited()
+		default:
+		}
+	}
+	return nil
+}
+
+// Terminate stops the command gracefully, sending SIGTERM then SIGKILL
+// after the stop timeout. The command runs in its own process group, and
+// the signals are sent to the whole group to stop its descendants.
+func (s *ProcessSupervisor) Terminate() error {
+	s.cmdLock.Lock()
+	cmd, done := s.cmd, s.done
+	s.cmdLock.Unlock()
+	if cmd == nil {
+		return nil
+	}
+
+	select {
+	case <-done:
+		return nil
+	default:
+	}
+
+	s.Log.Info("stopping process, pid %d", cmd.Process.Pid)
+	if err := terminateProcess(cmd); err == nil {
+		timeout := time.Duration(s.StopTimeout * float64(time.Second))
+		select {
+		case <-done:
+			return nil
+		case <-time.After(timeout):
+			s.Log.Warn("stop timeout, killing process")
+		}
+	}
+	if err := killProcess(cmd); err != nil {
+		return err
+	}
+	<-done
+	return nil
+}
+
+// Kill terminates the supervisor and kills the running command.
+func (s *ProcessSupervisor) Kill() {
+	s.cmdLock.Lock()
+	if s.cmd != nil && s.cmd.Process != nil {
+		killProcess(s.cmd)
+	}
+	s.cmdLock.Unlock()
+	s.RoutineHandler.Kill()
+}
+
+// exited handles the command exit, returns failure error on unclean exit
+// or when the command is always restarted.
+func (s *ProcessSupervisor) exited() error {
+	s.cmdLock.Lock()
+	err := s.waitErr
+	s.cmdLock.Unlock()
+
+	if err == nil {
+		s.Log.Info("process exited")
+		if s.RestartPolicy().Policy != RESTART_ALWAYS {
+			s.Disable()
+			s.TermEvent.Set()
+			return nil
+		}
+		return fmt.Errorf("%w: process exited", ErrFailure)
+	}
+	return fmt.Errorf("%w: process exited: %v", ErrFailure, err)
+}
+
+// lineWriter logs the written command output lines, where lines longer
+// than [OUTPUT_MAX_LINE] are logged in chunks.
+type lineWriter struct {
+	fn  func(string, ...any) error
+	buf []byte
+}
+
+func (w *lineWriter) Write(b []byte) (int, error) {
+	w.buf = append(w.buf, b...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.fn("%s", bytes.TrimSuffix(w.buf[:i], []byte("\r")))
+		w.buf = append(w.buf[:0], w.buf[i+1:]...)
+	}
+	if len(w.buf) >= OUTPUT_MAX_LINE {
+		w.flush()
+	}
+	return len(b), nil
+}
+
+// flush logs the remaining partial line.
+func (w *lineWriter) flush() {
+	if len(w.buf) > 0 {
+		w.fn("%s", w.buf)
+		w.buf = nil
+	}
+}
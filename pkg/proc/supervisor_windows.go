@@ -0,0 +1,25 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build windows
+
+package proc
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on windows.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateProcess is not supported on windows, the command is killed.
+func terminateProcess(cmd *exec.Cmd) error {
+	return errors.New("not supported on windows")
+}
+
+// killProcess kills the command process.
+func killProcess(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -6,14 +6,95 @@ package proc
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
 	"runtime/debug"
+	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
 	"github.com/exonlabs/go-utils/pkg/events"
 	"github.com/exonlabs/go-utils/pkg/logging"
 )
 
+// Restart policies applied after tasklet failures.
+const (
+	// RESTART_ALWAYS restarts the tasklet after every failure.
+	RESTART_ALWAYS = "always"
+	// RESTART_ON_FAILURE restarts the tasklet after failures up to the max
+	// restarts count, then the tasklet is disabled.
+	RESTART_ON_FAILURE = "on-failure"
+	// RESTART_NEVER disables the tasklet after the first failure.
+	RESTART_NEVER = "never"
+)
+
+// RestartPolicy defines the tasklet restart behavior after failures,
+// where a failure is an initialization error or a panic. Restarts
+// requested by stopping an enabled tasklet are not failures.
+type RestartPolicy struct {
+	// Policy defines the restart policy {always|on-failure|never}.
+	Policy string
+	// MaxRestarts defines the max consecutive restarts for on-failure
+	// policy. use 0 for unlimited restarts.
+	MaxRestarts int
+	// BackoffMin defines the initial restart delay in seconds.
+	BackoffMin float64
+	// BackoffMax defines the max restart delay in seconds.
+	BackoffMax float64
+	// BackoffFactor defines the restart delay multiplier on each
+	// consecutive failure.
+	BackoffFactor float64
+	// ResetAfter defines the run duration in seconds after which the
+	// consecutive failures count is reset.
+	ResetAfter float64
+}
+
+// NewRestartPolicy creates a restart policy from options.
+// The parsed options are:
+//   - restart_policy: (string) the restart policy {always|on-failure|never}.
+//     (default is always)
+//   - restart_max: (int) the max consecutive restarts for on-failure policy.
+//     use 0 for unlimited restarts. (default is 0)
+//   - restart_backoff_min: (float64) the initial restart delay in seconds.
+//     (default is 1)
+//   - restart_backoff_max: (float64) the max restart delay in seconds.
+//     (default is 60)
+//   - restart_backoff_factor: (float64) the restart delay multiplier.
+//     (default is 2)
+//   - restart_reset_after: (float64) the run duration in seconds after
+//     which the consecutive failures count is reset. (default is 60)
+func NewRestartPolicy(opts dictx.Dict) RestartPolicy {
+	return RestartPolicy{
+		Policy: strings.ToLower(dictx.GetString(
+			opts, "restart_policy", RESTART_ALWAYS)),
+		MaxRestarts:   dictx.GetInt(opts, "restart_max", 0),
+		BackoffMin:    dictx.GetFloat(opts, "restart_backoff_min", 1),
+		BackoffMax:    dictx.GetFloat(opts, "restart_backoff_max", 60),
+		BackoffFactor: dictx.GetFloat(opts, "restart_backoff_factor", 2),
+		ResetAfter:    dictx.GetFloat(opts, "restart_reset_after", 60),
+	}
+}
+
+// Backoff returns the restart delay in seconds after the given number
+// of consecutive failures.
+func (p *RestartPolicy) Backoff(failures int) float64 {
+	if failures < 1 || p.BackoffMin <= 0 {
+		return 0
+	}
+	d := p.BackoffMin
+	if p.BackoffFactor > 1 {
+		d *= math.Pow(p.BackoffFactor, float64(failures-1))
+	}
+	if p.BackoffMax > 0 && d > p.BackoffMax {
+		d = p.BackoffMax
+	}
+	return d
+}
+
 // Tasklet defines the interface for tasklets.
 type Tasklet interface {
 	Initialize() error
@@ -21,6 +102,26 @@ type Tasklet interface {
 	Terminate() error
 }
 
+var (
+	// ErrPanic indicates a recovered tasklet panic.
+	ErrPanic = errors.New("panic")
+	// ErrFailure indicates a tasklet failure. Execution errors wrapping
+	// ErrFailure end the tasklet run and apply the restart policy.
+	ErrFailure = errors.New("failure")
+)
+
+// PanicHandler defines the callback invoked on recovered tasklet panics,
+// with the recovered value and the panic stack trace.
+type PanicHandler func(r any, stack []byte)
+
+// runState holds the tasklet lifecycle state between steps.
+type runState struct {
+	// start time of the current run
+	tStart time.Time
+	// consecutive failures count
+	failures int
+}
+
 // TaskletHandler manages a Tasklet's lifecycle.
 type TaskletHandler struct {
 	// Log is the logger instance for application logging.
@@ -36,6 +137,41 @@ type TaskletHandler struct {
 	// flag to track current tasklet initialization state
 	isInitialized atomic.Bool
 
+	// restart policy applied after failures
+	restartPolicy atomic.Pointer[RestartPolicy]
+	// number of restarts after failures
+	restarts atomic.Int64
+	// last failure error
+	lastErr atomic.Pointer[error]
+	// flag set when tasklet is disabled by the restart policy
+	failed atomic.Bool
+	// last execution time in unix nano
+	lastExecute atomic.Int64
+	// goroutine id running the tasklet
+	goroutineId atomic.Uint64
+	// callback invoked on recovered panics
+	onPanic atomic.Pointer[PanicHandler]
+	// clock used for timing, sleeps and restart backoff
+	clock Clock
+	// lifecycle state of the running tasklet
+	state runState
+	// function recording lifecycle transitions, see [ReplayManager]
+	trace func(event string, err error)
+	// function called before each execution iteration, its error ends
+	// the tasklet run as failure
+	preExec func() error
+	// execution and termination timeouts in nanoseconds
+	executeTimeout   atomic.Int64
+	terminateTimeout atomic.Int64
+	// flag to abandon stuck tasklet calls after timeout
+	abandonStuck atomic.Bool
+	// flag set while a tasklet call exceeds its timeout
+	stuck atomic.Bool
+
+	// wakeEvent signals a wake up of the tasklet sleep to run the
+	// pending pre-execution function
+	wakeEvent *events.Event
+
 	// TermEvent signals a termination operation.
 	TermEvent *events.Event
 	// KillEvent signals a forceful termination operation.
@@ -44,12 +180,100 @@ type TaskletHandler struct {
 
 // NewTaskletHandler creates a new tasklet handler.
 func NewTaskletHandler(log *logging.Logger, tsk Tasklet) *TaskletHandler {
-	return &TaskletHandler{
+	h := &TaskletHandler{
 		Log:       log,
 		tasklet:   tsk,
+		clock:     SystemClock{},
+		wakeEvent: events.New(),
 		TermEvent: events.New(),
 		KillEvent: events.New(),
 	}
+	p := NewRestartPolicy(nil)
+	h.restartPolicy.Store(&p)
+	return h
+}
+
+// SetClock sets the clock used for the tasklet timing, sleeps and restart
+// backoff. It must be set before starting the tasklet. (default is the
+// system clock)
+func (h *TaskletHandler) SetClock(c Clock) {
+	h.clock = c
+}
+
+// SetRestartPolicy sets the restart policy applied after failures.
+func (h *TaskletHandler) SetRestartPolicy(p RestartPolicy) {
+	h.restartPolicy.Store(&p)
+}
+
+// RestartPolicy returns the restart policy applied after failures.
+func (h *TaskletHandler) RestartPolicy() RestartPolicy {
+	return *h.restartPolicy.Load()
+}
+
+// RestartCount returns the number of restarts after failures.
+func (h *TaskletHandler) RestartCount() int {
+	return int(h.restarts.Load())
+}
+
+// SetExecuteTimeout sets the timeout in seconds for a single tasklet
+// execution. When exceeded, the tasklet is marked stuck, and is abandoned
+// and restarted as failure if abandoning is enabled, see
+// [TaskletHandler.SetAbandonStuck]. use 0 to disable timeout.
+func (h *TaskletHandler) SetExecuteTimeout(timeout float64) {
+	h.executeTimeout.Store(int64(timeout * float64(time.Second)))
+}
+
+// SetTerminateTimeout sets the timeout in seconds for the tasklet
+// termination. When exceeded, the tasklet is marked stuck, and is
+// abandoned if abandoning is enabled, see [TaskletHandler.SetAbandonStuck].
+// use 0 to disable timeout.
+func (h *TaskletHandler) SetTerminateTimeout(timeout float64) {
+	h.terminateTimeout.Store(int64(timeout * float64(time.Second)))
+}
+
+// SetAbandonStuck enables abandoning tasklet calls exceeding their
+// timeouts. An abandoned call keeps running in the background until
+// it returns, while the tasklet handler proceeds without waiting.
+func (h *TaskletHandler) SetAbandonStuck(abandon bool) {
+	h.abandonStuck.Store(abandon)
+}
+
+// IsStuck returns whether a tasklet call is exceeding its timeout.
+func (h *TaskletHandler) IsStuck() bool {
+	return h.stuck.Load()
+}
+
+// OnPanic sets the callback invoked on panics recovered during tasklet
+// initialization, execution or termination. use nil to clear callback.
+func (h *TaskletHandler) OnPanic(cb PanicHandler) {
+	if cb == nil {
+		h.onPanic.Store(nil)
+	} else {
+		h.onPanic.Store(&cb)
+	}
+}
+
+// LastExecute returns the last tasklet execution time, or zero time
+// if not executed yet.
+func (h *TaskletHandler) LastExecute() time.Time {
+	if v := h.lastExecute.Load(); v > 0 {
+		return time.Unix(0, v)
+	}
+	return time.Time{}
+}
+
+// GoroutineId returns the id of goroutine running the tasklet,
+// or 0 if not running.
+func (h *TaskletHandler) GoroutineId() uint64 {
+	return h.goroutineId.Load()
+}
+
+// LastError returns the last failure error, or nil if no failure occurred.
+func (h *TaskletHandler) LastError() error {
+	if err := h.lastErr.Load(); err != nil {
+		return *err
+	}
+	return nil
 }
 
 // IsEnabled returns whether the tasklet is currently enabled.
@@ -77,52 +301,270 @@ func (h *TaskletHandler) Disable() {
 	h.isEnabled.Store(false)
 }
 
-// Run initiates the tasklet lifecycle, handling initialization,
-// execution, and termination.
+// Run runs a single tasklet lifecycle, handling initialization,
+// execution, and termination, without restarts.
 func (h *TaskletHandler) Run() {
-	defer func() {
-		// Panic recovery to handle unexpected errors during execution.
-		if r := recover(); r != nil {
-			stack := debug.Stack()
-			indx := bytes.Index(stack, []byte("panic({"))
-			h.Log.Error("%s", r)
-			h.Log.Trace1("\n----------\n%s----------", stack[indx:])
-		}
-		// Ensure termination execute if initialized and not killed.
-		if h.isInitialized.Load() && !h.KillEvent.IsSet() {
-			if err := h.tasklet.Terminate(); err != nil {
-				h.Log.Error("termination failed: %s", err.Error())
+	h.TermEvent.Clear()
+	h.KillEvent.Clear()
+	if h.initialize() == nil {
+		for !h.TermEvent.IsSet() {
+			if h.execute() != nil {
+				break
 			}
 		}
-	}()
+	}
+	h.finish()
+}
 
-	h.TermEvent.Clear()
-	h.KillEvent.Clear()
+// Start initiates the tasklet lifecycle, handling initialization,
+// execution, and termination. The tasklet is restarted after failures
+// according to its restart policy, see [RestartPolicy].
+func (h *TaskletHandler) Start() {
+	h.isAlive.Store(true)
+	defer h.isAlive.Store(false)
+	h.goroutineId.Store(currentGoroutineId())
+	defer h.goroutineId.Store(0)
 
-	// Attempt to initialize the tasklet.
-	if err := h.tasklet.Initialize(); err != nil {
+	h.begin()
+	for h.step() {
+	}
+}
+
+// begin resets the lifecycle state before starting the tasklet.
+func (h *TaskletHandler) begin() {
+	h.failed.Store(false)
+	h.state = runState{}
+}
+
+// step runs one lifecycle step, initializing the tasklet if not initialized,
+// then running one execution. The tasklet is terminated when stopped or on
+// failure, then restarted if still enabled, see [RestartPolicy].
+// It returns false when the tasklet lifecycle ends.
+func (h *TaskletHandler) step() bool {
+	if !h.isInitialized.Load() {
+		if !h.isEnabled.Load() {
+			return false
+		}
+		h.TermEvent.Clear()
+		h.KillEvent.Clear()
+		h.state.tStart = h.clock.Now()
+		if err := h.initialize(); err != nil {
+			return h.failure(err)
+		}
+	}
+
+	// terminate on stop, and restart if still enabled
+	if h.TermEvent.IsSet() {
+		if err := h.finish(); err != nil {
+			return h.failure(err)
+		}
+		return h.isEnabled.Load()
+	}
+
+	if err := h.execute(); err != nil {
+		return h.failure(err)
+	}
+	return true
+}
+
+// initialize runs the tasklet initialization, and returns the failure error.
+func (h *TaskletHandler) initialize() error {
+	if err := h.safeCall(h.tasklet.Initialize); err != nil {
+		if errors.Is(err, ErrPanic) {
+			return err
+		}
 		h.Log.Error("initialization failed: %s", err.Error())
-		return
+		return fmt.Errorf("initialization failed: %w", err)
 	}
 	h.isInitialized.Store(true)
+	h.record(REPLAY_INITIALIZED, nil)
+	return nil
+}
 
-	// Run tasklet execution loop until a termination event is set.
-	for !h.TermEvent.IsSet() {
-		if err := h.tasklet.Execute(); err != nil {
-			h.Log.Error("execution error: %s", err.Error())
+// execute runs one tasklet execution, and returns the failure error,
+// which is a recovered panic or an error wrapping [ErrFailure]. Other
+// execution errors are only logged.
+func (h *TaskletHandler) execute() error {
+	if h.preExec != nil {
+		h.wakeEvent.Clear()
+		if err := h.safeCall(h.preExec); err != nil {
+			return err
 		}
 	}
+	h.lastExecute.Store(h.clock.Now().UnixNano())
+	err := h.safeCall(func() error {
+		return h.callTimeout(
+			"execution", h.tasklet.Execute, h.executeTimeout.Load())
+	})
+	if err == nil {
+		h.record(REPLAY_EXECUTED, nil)
+		return nil
+	}
+	if errors.Is(err, ErrPanic) {
+		return err
+	}
+	h.Log.Error("execution error: %s", err.Error())
+	if errors.Is(err, ErrFailure) {
+		return err
+	}
+	h.record(REPLAY_EXEC_ERROR, err)
+	return nil
 }
 
-// Start initiates the tasklet lifecycle, handling initialization,
-// execution, and termination.
-func (h *TaskletHandler) Start() {
-	h.isAlive.Store(true)
-	defer h.isAlive.Store(false)
+// finish terminates the tasklet if initialized and not killed, and resets
+// the tasklet initialization state. It returns the termination failure.
+func (h *TaskletHandler) finish() error {
+	if !h.isInitialized.Load() {
+		return nil
+	}
+	defer h.isInitialized.Store(false)
+	if h.KillEvent.IsSet() {
+		return nil
+	}
+	return h.terminate()
+}
+
+// failure terminates the tasklet after failure and applies the restart
+// policy, waiting the restart backoff delay. It returns false if the
+// tasklet is not restarted.
+func (h *TaskletHandler) failure(err error) bool {
+	h.finish()
+	if !h.isEnabled.Load() {
+		return false
+	}
+	h.lastErr.Store(&err)
+	h.record(REPLAY_FAILED, err)
+
+	p := h.RestartPolicy()
+	if h.clock.Now().Sub(h.state.tStart).Seconds() >= p.ResetAfter {
+		h.state.failures = 0
+	}
+	h.state.failures++
+	if p.Policy == RESTART_NEVER || (p.Policy == RESTART_ON_FAILURE &&
+		p.MaxRestarts > 0 && h.state.failures > p.MaxRestarts) {
+		h.Log.Error("restart limit reached after %d failures, disabled",
+			h.state.failures)
+		h.failed.Store(true)
+		h.Disable()
+		h.record(REPLAY_DISABLED, nil)
+		return false
+	}
+
+	delay := p.Backoff(h.state.failures)
+	h.Log.Warn("restarting after %.1fs, failure %d", delay, h.state.failures)
+	h.record(REPLAY_RESTARTING, nil)
+	if delay > 0 && !h.clock.Sleep(delay, h.TermEvent) {
+		// stopped while waiting
+		if h.KillEvent.IsSet() {
+			return false
+		}
+	}
+	h.restarts.Add(1)
+	return true
+}
+
+// terminate runs the tasklet termination, and returns the recovered
+// panic as failure error. termination errors are only logged.
+func (h *TaskletHandler) terminate() error {
+	err := h.safeCall(func() error {
+		return h.callTimeout(
+			"termination", h.tasklet.Terminate, h.terminateTimeout.Load())
+	})
+	h.record(REPLAY_TERMINATED, err)
+	if errors.Is(err, ErrPanic) {
+		return err
+	}
+	// abandoned termination is already logged
+	if err != nil && !errors.Is(err, ErrFailure) {
+		h.Log.Error("termination failed: %s", err.Error())
+	}
+	return nil
+}
+
+// safeCall calls the tasklet function, returning the recovered panic
+// as error.
+func (h *TaskletHandler) safeCall(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = h.recovered(r, debug.Stack())
+		}
+	}()
+	return fn()
+}
+
+// record records a lifecycle transition if tracing is enabled.
+func (h *TaskletHandler) record(event string, err error) {
+	if h.trace != nil {
+		h.trace(event, err)
+	}
+}
+
+// callTimeout calls the tasklet function with timeout in nanoseconds.
+// On timeout, the tasklet is marked stuck, and the call is abandoned
+// returning failure error if abandoning is enabled, else the call is
+// waited to return. Panics in timed calls are returned as errors.
+func (h *TaskletHandler) callTimeout(
+	name string, fn func() error, timeout int64) error {
+	if timeout <= 0 {
+		return fn()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- h.recovered(r, debug.Stack())
+			}
+		}()
+		done <- fn()
+	}()
+
+	timer := time.NewTimer(time.Duration(timeout))
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+	}
 
-	for h.isEnabled.Load() {
-		h.Run()
+	h.stuck.Store(true)
+	if h.abandonStuck.Load() {
+		h.Log.Error("%s timeout after %s, abandoned",
+			name, time.Duration(timeout))
+		go func() {
+			<-done
+			h.stuck.Store(false)
+		}()
+		return fmt.Errorf("%w: %s timeout", ErrFailure, name)
 	}
+	h.Log.Error("%s timeout after %s, routine stuck",
+		name, time.Duration(timeout))
+	err := <-done
+	h.stuck.Store(false)
+	h.Log.Warn("%s recovered after timeout", name)
+	return err
+}
+
+// recovered logs the recovered panic with its stack trace, invokes the
+// panic callback if set, and returns the panic as error.
+func (h *TaskletHandler) recovered(r any, stack []byte) error {
+	if indx := bytes.Index(stack, []byte("panic({")); indx >= 0 {
+		stack = stack[indx:]
+	}
+	h.Log.Error("%s", r)
+	h.Log.Trace1("\n----------\n%s----------", stack)
+
+	if cb := h.onPanic.Load(); cb != nil {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					h.Log.Error("panic callback failed: %s", r)
+				}
+			}()
+			(*cb)(r, stack)
+		}()
+	}
+	return fmt.Errorf("%w: %v", ErrPanic, r)
 }
 
 // Stop gracefully stops the tasklet by setting the termination event.
@@ -142,13 +584,38 @@ func (h *TaskletHandler) Kill() {
 }
 
 // Sleep pauses execution for the given timeout duration (in seconds),
-// and waits for either a termination or kill event.
+// and waits for either a termination or kill event. The sleep also ends
+// early when the tasklet is woken up to run pending work before the next
+// execution, such as a process reload, see [Process.Reload].
 func (h *TaskletHandler) Sleep(timeout float64) bool {
+	return h.sleep(timeout, true)
+}
+
+// sleep pauses execution for timeout seconds, and waits for either a
+// termination or kill event, or the wake up event if wake is set.
+func (h *TaskletHandler) sleep(timeout float64, wake bool) bool {
 	// Wait for kill event if termination is already set.
 	if h.TermEvent.IsSet() {
-		return h.KillEvent.Wait(timeout)
+		return h.clock.Sleep(timeout, h.KillEvent)
+	}
+	if !wake || h.preExec == nil {
+		return h.clock.Sleep(timeout, h.TermEvent)
+	}
+	if h.clock.Sleep(timeout, h.TermEvent, h.wakeEvent) {
+		return true
 	}
-	return h.TermEvent.Wait(timeout)
+	if h.TermEvent.IsSet() {
+		return false
+	}
+	// woken up, consume wake up to not shorten next sleeps
+	h.wakeEvent.Clear()
+	return true
+}
+
+// wakeUp wakes up the tasklet sleep to run the pending pre-execution
+// function after the current execution returns.
+func (h *TaskletHandler) wakeUp() {
+	h.wakeEvent.Set()
 }
 
 // WaitStop waits for tasklet to stop for the given timeout duration (in seconds),
@@ -156,15 +623,30 @@ func (h *TaskletHandler) Sleep(timeout float64) bool {
 func (h *TaskletHandler) WaitStop(timeout float64) bool {
 	var tBreak time.Time
 	if timeout > 0 {
-		tBreak = time.Now().Add(time.Duration(timeout * float64(time.Second)))
+		tBreak = h.clock.Now().Add(
+			time.Duration(timeout * float64(time.Second)))
 	}
-	for h.Sleep(0.05) {
+	for h.sleep(0.05, false) {
 		if !h.isAlive.Load() {
 			return true
 		}
-		if timeout > 0 && time.Now().After(tBreak) {
+		if timeout > 0 && h.clock.Now().After(tBreak) {
 			return false
 		}
 	}
 	return false
 }
+
+// currentGoroutineId returns the id of the current goroutine, parsed from
+// the goroutine stack header `goroutine <id> [...]`.
+func currentGoroutineId() uint64 {
+	b := make([]byte, 64)
+	b = b[:runtime.Stack(b, false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		if id, err := strconv.ParseUint(string(b[:i]), 10, 64); err == nil {
+			return id
+		}
+	}
+	return 0
+}
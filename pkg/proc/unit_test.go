@@ -0,0 +1,1683 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package proc_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/comm"
+	"github.com/exonlabs/go-utils/pkg/comm/memcomm"
+	"github.com/exonlabs/go-utils/pkg/logging"
+	"github.com/exonlabs/go-utils/pkg/proc"
+)
+
+// nextTimes returns the next n activation times of spec after t.
+func nextTimes(t *testing.T, spec string, from time.Time, n int) []time.Time {
+	s, err := proc.ParseSchedule(spec)
+	require.NoError(t, err)
+	res := []time.Time{}
+	for i := 0; i < n; i++ {
+		from = s.Next(from)
+		res = append(res, from)
+	}
+	return res
+}
+
+// utc returns the UTC time of date and wall clock values.
+func utc(y int, m time.Month, d, h, min int) time.Time {
+	return time.Date(y, m, d, h, min, 0, 0, time.UTC)
+}
+
+func TestParseSchedule(t *testing.T) {
+	// Monday 2024-01-01 00:00
+	from := utc(2024, 1, 1, 0, 0)
+	tests := []struct {
+		name string
+		spec string
+		next []time.Time
+	}{
+		{"steps", "*/15 * * * *", []time.Time{
+			utc(2024, 1, 1, 0, 15), utc(2024, 1, 1, 0, 30),
+			utc(2024, 1, 1, 0, 45), utc(2024, 1, 1, 1, 0)}},
+		{"step from value", "10/20 0 * * *", []time.Time{
+			utc(2024, 1, 1, 0, 10), utc(2024, 1, 1, 0, 30),
+			utc(2024, 1, 1, 0, 50), utc(2024, 1, 2, 0, 10)}},
+		{"range", "0 9-11 * * *", []time.Time{
+			utc(2024, 1, 1, 9, 0), utc(2024, 1, 1, 10, 0),
+			utc(2024, 1, 1, 11, 0), utc(2024, 1, 2, 9, 0)}},
+		{"range step", "0 0-12/6 * * *", []time.Time{
+			utc(2024, 1, 1, 6, 0), utc(2024, 1, 1, 12, 0),
+			utc(2024, 1, 2, 0, 0)}},
+		{"list", "5,10 0 * * *", []time.Time{
+			utc(2024, 1, 1, 0, 5), utc(2024, 1, 1, 0, 10),
+			utc(2024, 1, 2, 0, 5)}},
+		{"list of ranges", "0 1-2,22-23 * * *", []time.Time{
+			utc(2024, 1, 1, 1, 0), utc(2024, 1, 1, 2, 0),
+			utc(2024, 1, 1, 22, 0), utc(2024, 1, 1, 23, 0)}},
+		{"month names", "0 0 1 feb,MAR *", []time.Time{
+			utc(2024, 2, 1, 0, 0), utc(2024, 3, 1, 0, 0),
+			utc(2025, 2, 1, 0, 0)}},
+		{"month name step", "0 0 1 jan/5 *", []time.Time{
+			utc(2024, 6, 1, 0, 0), utc(2024, 11, 1, 0, 0),
+			utc(2025, 1, 1, 0, 0)}},
+		{"day names", "0 0 * * Tue-wed,SAT", []time.Time{
+			utc(2024, 1, 2, 0, 0), utc(2024, 1, 3, 0, 0),
+			utc(2024, 1, 6, 0, 0), utc(2024, 1, 9, 0, 0)}},
+		{"sunday as 7", "0 0 * * 7", []time.Time{
+			utc(2024, 1, 7, 0, 0), utc(2024, 1, 14, 0, 0)}},
+		{"day of month only", "0 0 13 * *", []time.Time{
+			utc(2024, 1, 13, 0, 0), utc(2024, 2, 13, 0, 0),
+			utc(2024, 3, 13, 0, 0)}},
+		{"day of week only", "0 0 * * fri", []time.Time{
+			utc(2024, 1, 5, 0, 0), utc(2024, 1, 12, 0, 0),
+			utc(2024, 1, 19, 0, 0)}},
+		{"day of month or week", "0 0 13 * fri", []time.Time{
+			utc(2024, 1, 5, 0, 0), utc(2024, 1, 12, 0, 0),
+			utc(2024, 1, 13, 0, 0), utc(2024, 1, 19, 0, 0)}},
+		{"first week or mondays", "0 0 1-7 * mon", []time.Time{
+			utc(2024, 1, 2, 0, 0), utc(2024, 1, 3, 0, 0),
+			utc(2024, 1, 4, 0, 0), utc(2024, 1, 5, 0, 0),
+			utc(2024, 1, 6, 0, 0), utc(2024, 1, 7, 0, 0),
+			utc(2024, 1, 8, 0, 0), utc(2024, 1, 15, 0, 0)}},
+		{"alias", "@weekly", []time.Time{
+			utc(2024, 1, 7, 0, 0), utc(2024, 1, 14, 0, 0)}},
+		{"interval", "@every 1m30s", []time.Time{
+			from.Add(90 * time.Second), from.Add(180 * time.Second)}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.next, nextTimes(t, tc.spec, from, len(tc.next)))
+		})
+	}
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"", "* * * *", "* * * * * *", "60 * * * *", "* 24 * * *",
+		"* * 0 * *", "* * 32 * *", "* * * 13 * ", "* * * * 8",
+		"*/0 * * * *", "*/-1 * * * *", "5-1 * * * *", "1-2-3 * * * *",
+		"1,,2 * * * *", "a * * * *", "* * * foo *", "* * * * mon-",
+		"* * * * sat-sun", "* * * jan-foo *",
+		"@every", "@every 0s", "@every -1m", "@sometimes",
+	} {
+		_, err := proc.ParseSchedule(spec)
+		assert.Error(t, err, "spec: %q", spec)
+	}
+}
+
+func TestScheduleMonthBoundaries(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		next []time.Time
+	}{
+		{"skip short months", "0 0 31 * *", utc(2024, 1, 1, 0, 0),
+			[]time.Time{utc(2024, 1, 31, 0, 0), utc(2024, 3, 31, 0, 0),
+				utc(2024, 5, 31, 0, 0)}},
+		{"leap day", "0 0 29 2 *", utc(2024, 1, 1, 0, 0),
+			[]time.Time{utc(2024, 2, 29, 0, 0), utc(2028, 2, 29, 0, 0)}},
+		{"end of month", "59 23 * * *", utc(2024, 1, 31, 23, 58),
+			[]time.Time{utc(2024, 1, 31, 23, 59), utc(2024, 2, 1, 23, 59)}},
+		{"end of year", "0 0 1 1 *", utc(2024, 12, 31, 23, 59),
+			[]time.Time{utc(2025, 1, 1, 0, 0), utc(2026, 1, 1, 0, 0)}},
+		{"seconds truncated", "* * * * *",
+			time.Date(2024, 1, 31, 23, 59, 30, 5, time.UTC),
+			[]time.Time{utc(2024, 2, 1, 0, 0), utc(2024, 2, 1, 0, 1)}},
+		{"never", "0 0 30 2 *", utc(2024, 1, 1, 0, 0),
+			[]time.Time{{}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.next, nextTimes(t, tc.spec, tc.from, len(tc.next)))
+		})
+	}
+}
+
+func TestScheduleDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	local := func(d, h, min int, offset int) time.Time {
+		m := time.March
+		if d > 31 {
+			m, d = time.November, d-31
+		}
+		return time.Date(2024, m, d, h, min, 0, 0,
+			time.FixedZone("", offset*3600)).In(loc)
+	}
+	const est, edt = -5, -4
+
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		next []time.Time
+	}{
+		// 2024-03-10 02:00 EST clocks turned forward to 03:00 EDT
+		{"hourly over skipped hour", "0 * * * *", local(10, 1, 30, est),
+			[]time.Time{local(10, 3, 0, edt), local(10, 4, 0, edt)}},
+		{"daily in skipped hour", "30 2 * * *", local(9, 3, 0, est),
+			[]time.Time{local(10, 3, 30, edt), local(11, 2, 30, edt)}},
+		{"daily after skipped hour", "0 12 * * *", local(9, 12, 0, est),
+			[]time.Time{local(10, 12, 0, edt), local(11, 12, 0, edt)}},
+		// 2024-11-03 02:00 EDT clocks turned back to 01:00 EST
+		{"hourly over repeated hour", "0 * * * *", local(31+3, 0, 30, edt),
+			[]time.Time{local(31+3, 1, 0, edt), local(31+3, 2, 0, est)}},
+		{"daily in repeated hour", "30 1 * * *", local(31+3, 0, 0, edt),
+			[]time.Time{local(31+3, 1, 30, edt), local(31+4, 1, 30, est)}},
+		{"daily from repeated hour", "45 1 * * *", local(31+3, 1, 10, est),
+			[]time.Time{local(31+4, 1, 45, est)}},
+		{"minutely over repeated hour", "*/30 * * * *",
+			local(31+3, 1, 0, edt),
+			[]time.Time{local(31+3, 1, 30, edt), local(31+3, 2, 0, est)}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			next := nextTimes(t, tc.spec, tc.from, len(tc.next))
+			for i := range tc.next {
+				assert.True(t, tc.next[i].Equal(next[i]),
+					"expected %v, got %v", tc.next[i], next[i])
+				assert.Equal(t, loc, next[i].Location())
+			}
+		})
+	}
+}
+
+// hookTasklet records the tasklet hooks calls.
+type hookTasklet struct {
+	h     *proc.TaskletHandler
+	calls []string
+	// execs counts the executions, panicAt panics on an execution, and
+	// panicEvery panics on every n-th execution.
+	execs      int
+	panicAt    int
+	panicEvery int
+	initErr    error
+}
+
+func (t *hookTasklet) Initialize() error {
+	t.calls = append(t.calls, "initialize")
+	return t.initErr
+}
+
+func (t *hookTasklet) Execute() error {
+	t.execs++
+	t.calls = append(t.calls, "execute")
+	if t.execs == t.panicAt ||
+		(t.panicEvery > 0 && t.execs%t.panicEvery == 0) {
+		panic("execute failed")
+	}
+	t.h.Sleep(1)
+	return nil
+}
+
+func (t *hookTasklet) Terminate() error {
+	t.calls = append(t.calls, "terminate")
+	return nil
+}
+
+// newReplay creates a replay manager running tsk as worker routine.
+func newReplay(t *testing.T, tsk *hookTasklet,
+	p proc.RestartPolicy) (*proc.ReplayManager, time.Time) {
+	log := logging.NewStdoutLogger("replay")
+	log.Level = logging.FATAL
+	t0 := utc(2024, 1, 1, 0, 0)
+	m := proc.NewReplayManager(log, proc.NewFakeClock(t0))
+	tsk.h = proc.NewTaskletHandler(log, tsk)
+	tsk.h.SetRestartPolicy(p)
+	require.NoError(t, m.AddRoutine("worker", tsk.h, true))
+	return m, t0
+}
+
+func TestReplayManager(t *testing.T) {
+	run := func() (*hookTasklet, []proc.Transition, time.Time) {
+		tsk := &hookTasklet{panicAt: 3}
+		m, t0 := newReplay(t, tsk, proc.NewRestartPolicy(
+			map[string]any{"restart_backoff_min": 2}))
+		m.RunOnce()
+		m.AdvanceTime(5 * time.Second)
+		m.Stop()
+		assert.Equal(t, 1, tsk.h.RestartCount())
+		assert.ErrorIs(t, tsk.h.LastError(), proc.ErrPanic)
+		return tsk, m.Transitions(), t0
+	}
+
+	tsk, recorded, t0 := run()
+	assert.Equal(t, []string{
+		"initialize", "execute", "execute", "execute", "terminate",
+		"initialize", "execute", "execute", "terminate",
+	}, tsk.calls)
+
+	expected := []struct {
+		sec   int
+		event string
+	}{
+		{0, proc.REPLAY_INITIALIZED},
+		{0, proc.REPLAY_EXECUTED},
+		{1, proc.REPLAY_EXECUTED},
+		{2, proc.REPLAY_TERMINATED},
+		{2, proc.REPLAY_FAILED},
+		{2, proc.REPLAY_RESTARTING},
+		{4, proc.REPLAY_INITIALIZED},
+		{4, proc.REPLAY_EXECUTED},
+		{5, proc.REPLAY_EXECUTED},
+		{5, proc.REPLAY_TERMINATED},
+	}
+	require.Len(t, recorded, len(expected))
+	for i, e := range expected {
+		assert.Equal(t, "worker", recorded[i].Routine)
+		assert.Equal(t, e.event, recorded[i].Event, "transition %d", i)
+		assert.Equal(t, t0.Add(time.Duration(e.sec)*time.Second),
+			recorded[i].Time, "transition %d", i)
+	}
+	assert.ErrorIs(t, recorded[4].Err, proc.ErrPanic)
+
+	// replaying the same run gives the same transitions
+	tsk, replayed, _ := run()
+	assert.Equal(t, recorded, replayed)
+	assert.Len(t, tsk.calls, 9)
+}
+
+func TestReplayManagerDisable(t *testing.T) {
+	tsk := &hookTasklet{initErr: errors.New("init error")}
+	m, _ := newReplay(t, tsk, proc.NewRestartPolicy(
+		map[string]any{"restart_policy": "never"}))
+	m.RunOnce()
+	m.AdvanceTime(time.Minute)
+	m.Stop()
+
+	assert.Equal(t, []string{"initialize"}, tsk.calls)
+	assert.Equal(t, []string{
+		proc.REPLAY_FAILED, proc.REPLAY_DISABLED}, m.Events("worker"))
+	assert.False(t, tsk.h.IsEnabled())
+	assert.ErrorContains(t, tsk.h.LastError(), "initialization failed")
+}
+
+func TestReplayManagerOrder(t *testing.T) {
+	log := logging.NewStdoutLogger("replay")
+	log.Level = logging.FATAL
+	t0 := utc(2024, 1, 1, 0, 0)
+	m := proc.NewReplayManager(log, proc.NewFakeClock(t0))
+
+	add := func(name string, opts ...proc.RoutineOption) *hookTasklet {
+		tsk := &hookTasklet{}
+		tsk.h = proc.NewTaskletHandler(log, tsk)
+		require.NoError(t, m.AddRoutine(name, tsk.h, true, opts...))
+		return tsk
+	}
+	add("a", proc.Priority(1))
+	add("b")
+	c := add("c", proc.DependsOn("a"))
+
+	// c starts on next monitoring after a is initialized
+	m.RunOnce()
+	assert.Empty(t, c.calls)
+	m.AdvanceTime(200 * time.Millisecond)
+	m.Stop()
+
+	type step struct {
+		ms      int
+		routine string
+		event   string
+	}
+	expected := []step{
+		{0, "b", proc.REPLAY_INITIALIZED}, {0, "b", proc.REPLAY_EXECUTED},
+		{0, "a", proc.REPLAY_INITIALIZED}, {0, "a", proc.REPLAY_EXECUTED},
+		{100, "c", proc.REPLAY_INITIALIZED}, {100, "c", proc.REPLAY_EXECUTED},
+		{200, "c", proc.REPLAY_TERMINATED}, {200, "a", proc.REPLAY_TERMINATED},
+		{200, "b", proc.REPLAY_TERMINATED},
+	}
+	recorded := m.Transitions()
+	require.Len(t, recorded, len(expected))
+	for i, e := range expected {
+		assert.Equal(t, step{e.ms, e.routine, e.event}, step{
+			int(recorded[i].Time.Sub(t0).Milliseconds()),
+			recorded[i].Routine, recorded[i].Event}, "transition %d", i)
+	}
+	for _, n := range []string{"a", "b", "c"} {
+		assert.False(t, m.Status()[n].(dictx.Dict)["initialized"].(bool))
+	}
+}
+
+// eventTimes returns the seconds since t0 of the recorded routine event.
+func eventTimes(m *proc.ReplayManager, t0 time.Time, event string) []float64 {
+	res := []float64{}
+	for _, tr := range m.Transitions() {
+		if tr.Event == event {
+			res = append(res, tr.Time.Sub(t0).Seconds())
+		}
+	}
+	return res
+}
+
+func TestRestartPolicy(t *testing.T) {
+	initErr := errors.New("init error")
+	tests := []struct {
+		name     string
+		opts     map[string]any
+		tsk      *hookTasklet
+		failed   []float64
+		inits    []float64
+		restarts int
+		enabled  bool
+	}{
+		{"always backoff", map[string]any{
+			"restart_backoff_max": 4},
+			&hookTasklet{initErr: initErr},
+			[]float64{0, 1, 3, 7, 11}, []float64{}, 5, true},
+		{"on-failure max", map[string]any{
+			"restart_policy": "on-failure", "restart_max": 2},
+			&hookTasklet{initErr: initErr},
+			[]float64{0, 1, 3}, []float64{}, 2, false},
+		{"on-failure unlimited", map[string]any{
+			"restart_policy": "on-failure", "restart_backoff_factor": 1},
+			&hookTasklet{initErr: initErr},
+			[]float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, []float64{},
+			13, true},
+		{"never", map[string]any{"restart_policy": "never"},
+			&hookTasklet{panicAt: 2},
+			[]float64{1}, []float64{0}, 0, false},
+		{"no reset", map[string]any{},
+			&hookTasklet{panicEvery: 3},
+			[]float64{2, 5, 9}, []float64{0, 3, 7}, 3, true},
+		{"reset after", map[string]any{"restart_reset_after": 2},
+			&hookTasklet{panicEvery: 3},
+			[]float64{2, 5, 8, 11}, []float64{0, 3, 6, 9, 12}, 4, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m, t0 := newReplay(t, tc.tsk, proc.NewRestartPolicy(tc.opts))
+			m.RunOnce()
+			m.AdvanceTime(12 * time.Second)
+
+			assert.Equal(t, tc.failed, eventTimes(m, t0, proc.REPLAY_FAILED))
+			assert.Equal(t, tc.inits,
+				eventTimes(m, t0, proc.REPLAY_INITIALIZED))
+			assert.Equal(t, tc.restarts, tc.tsk.h.RestartCount())
+			assert.Equal(t, tc.enabled, tc.tsk.h.IsEnabled())
+			if !tc.enabled {
+				assert.Equal(t, tc.failed[len(tc.failed)-1:],
+					eventTimes(m, t0, proc.REPLAY_DISABLED))
+			}
+			m.Stop()
+		})
+	}
+}
+
+func TestRestartBackoff(t *testing.T) {
+	p := proc.NewRestartPolicy(map[string]any{
+		"restart_backoff_min":    0.5,
+		"restart_backoff_max":    10,
+		"restart_backoff_factor": 3,
+	})
+	backoff := []float64{}
+	for i := 0; i <= 5; i++ {
+		backoff = append(backoff, p.Backoff(i))
+	}
+	assert.Equal(t, []float64{0, 0.5, 1.5, 4.5, 10, 10}, backoff)
+
+	p.BackoffMin = 0
+	assert.Equal(t, 0.0, p.Backoff(3))
+}
+
+// blockTasklet blocks its first execution and termination until released.
+type blockTasklet struct {
+	h         *proc.TaskletHandler
+	execBlock chan struct{}
+	termBlock chan struct{}
+	execs     atomic.Int32
+	terms     atomic.Int32
+}
+
+func (t *blockTasklet) Initialize() error {
+	return nil
+}
+
+func (t *blockTasklet) Execute() error {
+	if t.execs.Add(1) == 1 && t.execBlock != nil {
+		<-t.execBlock
+	}
+	t.h.Sleep(0.01)
+	return nil
+}
+
+func (t *blockTasklet) Terminate() error {
+	if t.terms.Add(1) == 1 && t.termBlock != nil {
+		<-t.termBlock
+	}
+	return nil
+}
+
+// startBlock starts a handler running tsk with the execution and
+// termination timeouts in seconds.
+func startBlock(tsk *blockTasklet, execute, terminate float64,
+	abandon bool) *proc.TaskletHandler {
+	log := logging.NewStdoutLogger("timeout")
+	log.Level = logging.FATAL
+	tsk.h = proc.NewTaskletHandler(log, tsk)
+	tsk.h.SetRestartPolicy(proc.NewRestartPolicy(
+		map[string]any{"restart_backoff_min": 0}))
+	tsk.h.SetExecuteTimeout(execute)
+	tsk.h.SetTerminateTimeout(terminate)
+	tsk.h.SetAbandonStuck(abandon)
+	tsk.h.Enable()
+	go tsk.h.Start()
+	return tsk.h
+}
+
+func TestExecuteTimeout(t *testing.T) {
+	tsk := &blockTasklet{execBlock: make(chan struct{})}
+	h := startBlock(tsk, 0.05, 0, false)
+	defer h.Kill()
+
+	// stuck execution is waited
+	require.Eventually(t, h.IsStuck, time.Second, 5*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(1), tsk.execs.Load())
+	assert.True(t, h.IsStuck())
+
+	// recovered execution continues without restart
+	close(tsk.execBlock)
+	require.Eventually(t, func() bool {
+		return !h.IsStuck() && tsk.execs.Load() > 2
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 0, h.RestartCount())
+	assert.NoError(t, h.LastError())
+
+	h.Disable()
+	h.Stop()
+	assert.True(t, h.WaitStop(1))
+	assert.Equal(t, int32(1), tsk.terms.Load())
+}
+
+func TestExecuteTimeoutAbandon(t *testing.T) {
+	tsk := &blockTasklet{execBlock: make(chan struct{})}
+	h := startBlock(tsk, 0.05, 0, true)
+	defer h.Kill()
+
+	// stuck execution is abandoned and restarted as failure
+	require.Eventually(t, func() bool {
+		return h.RestartCount() == 1 && tsk.execs.Load() > 2
+	}, time.Second, 5*time.Millisecond)
+	assert.ErrorIs(t, h.LastError(), proc.ErrFailure)
+	assert.ErrorContains(t, h.LastError(), "execution timeout")
+	assert.Equal(t, int32(1), tsk.terms.Load())
+	assert.True(t, h.IsStuck())
+
+	// abandoned call clears stuck flag when returns
+	close(tsk.execBlock)
+	require.Eventually(t, func() bool { return !h.IsStuck() },
+		time.Second, 5*time.Millisecond)
+
+	h.Disable()
+	h.Stop()
+	assert.True(t, h.WaitStop(1))
+}
+
+func TestTerminateTimeout(t *testing.T) {
+	for _, abandon := range []bool{false, true} {
+		tsk := &blockTasklet{termBlock: make(chan struct{})}
+		h := startBlock(tsk, 0, 0.05, abandon)
+		require.Eventually(t, func() bool { return tsk.execs.Load() > 0 },
+			time.Second, 5*time.Millisecond)
+
+		h.Disable()
+		h.Stop()
+		require.Eventually(t, h.IsStuck, time.Second, 5*time.Millisecond)
+		if abandon {
+			// abandoned termination doesn't block stopping
+			assert.True(t, h.WaitStop(1))
+			assert.False(t, h.IsInitialized())
+		} else {
+			assert.False(t, h.WaitStop(0.1))
+			assert.True(t, h.IsAlive())
+		}
+
+		close(tsk.termBlock)
+		require.Eventually(t, func() bool {
+			return !h.IsStuck() && !h.IsAlive()
+		}, time.Second, 5*time.Millisecond, "abandon=%v", abandon)
+		assert.Equal(t, 0, h.RestartCount())
+	}
+}
+
+// panicTasklet panics in the tasklet hook named by panicIn.
+type panicTasklet struct {
+	h       *proc.TaskletHandler
+	panicIn string
+}
+
+func (t *panicTasklet) Initialize() error {
+	if t.panicIn == "initialize" {
+		panic("initialize failed")
+	}
+	return nil
+}
+
+func (t *panicTasklet) Execute() error {
+	if t.panicIn == "execute" {
+		panic("execute failed")
+	}
+	t.h.Sleep(1)
+	return nil
+}
+
+func (t *panicTasklet) Terminate() error {
+	if t.panicIn == "terminate" {
+		panic("terminate failed")
+	}
+	return nil
+}
+
+func TestPanicRecovery(t *testing.T) {
+	const (
+		initialized = proc.REPLAY_INITIALIZED
+		executed    = proc.REPLAY_EXECUTED
+		terminated  = proc.REPLAY_TERMINATED
+		failed      = proc.REPLAY_FAILED
+		restarting  = proc.REPLAY_RESTARTING
+		disabled    = proc.REPLAY_DISABLED
+	)
+	tests := []struct {
+		panicIn string
+		events  []string
+	}{
+		{"initialize", []string{
+			failed, restarting, failed, disabled}},
+		{"execute", []string{
+			initialized, terminated, failed, restarting,
+			initialized, terminated, failed, disabled}},
+		{"terminate", []string{
+			initialized, executed, terminated, failed, restarting,
+			initialized, executed, executed, executed, executed,
+			terminated}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.panicIn, func(t *testing.T) {
+			log := logging.NewStdoutLogger("panic")
+			log.Level = logging.FATAL
+			m := proc.NewReplayManager(
+				log, proc.NewFakeClock(utc(2024, 1, 1, 0, 0)))
+			tsk := &panicTasklet{panicIn: tc.panicIn}
+			tsk.h = proc.NewTaskletHandler(log, tsk)
+			tsk.h.SetRestartPolicy(proc.NewRestartPolicy(map[string]any{
+				"restart_policy": "on-failure", "restart_max": 1}))
+			panics := []any{}
+			tsk.h.OnPanic(func(r any, stack []byte) {
+				assert.Contains(t, string(stack), "panicTasklet")
+				panics = append(panics, r)
+			})
+			require.NoError(t, m.AddRoutine("worker", tsk.h, true))
+
+			m.RunOnce()
+			if tc.panicIn == "terminate" {
+				// restart request runs the panicking termination
+				require.NoError(t, m.RestartRoutine("worker"))
+			}
+			m.AdvanceTime(5 * time.Second)
+			m.Stop()
+
+			assert.Equal(t, tc.events, m.Events("worker"))
+			assert.Equal(t, []any{tc.panicIn + " failed",
+				tc.panicIn + " failed"}, panics)
+			assert.ErrorIs(t, tsk.h.LastError(), proc.ErrPanic)
+			assert.False(t, tsk.h.IsAlive())
+			assert.False(t, tsk.h.IsInitialized())
+		})
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	log := logging.NewStdoutLogger("maintenance")
+	log.Level = logging.FATAL
+	// 2024-01-06 is saturday
+	clock := proc.NewFakeClock(utc(2024, 1, 6, 1, 0))
+	rm := proc.NewReplayManager(log, clock)
+	for _, n := range []string{"modbus_1", "modbus_2", "poller", "web"} {
+		tsk := &hookTasklet{}
+		tsk.h = proc.NewTaskletHandler(log, tsk)
+		require.NoError(t, rm.AddRoutine(n, tsk.h, n != "modbus_2"))
+	}
+	rm.RunOnce()
+
+	m, err := proc.NewMaintenance(rm.RoutineManager, dictx.Dict{
+		"groups": dictx.Dict{
+			"field": []any{"modbus_*", "poller"},
+			"ops":   []any{"web"},
+		},
+		"windows": []any{dictx.Dict{
+			"name": "weekly", "groups": []any{"field"},
+			"schedule": "0 2 * * 6", "duration": 7200,
+		}},
+	})
+	require.NoError(t, err)
+	m.SetClock(clock)
+	assert.Error(t, m.Enter("unknown", 0))
+
+	enabled := func() map[string]bool {
+		res := map[string]bool{}
+		for n, st := range rm.Status() {
+			res[n] = st.(dictx.Dict)["enabled"].(bool)
+		}
+		return res
+	}
+	suppressed := func() []string {
+		res := []string{}
+		for _, n := range []string{"modbus_1", "modbus_2", "poller", "web"} {
+			if m.IsSuppressed(n) {
+				res = append(res, n)
+			}
+		}
+		return res
+	}
+
+	m.Update()
+	assert.Empty(t, m.Status())
+	assert.Empty(t, suppressed())
+
+	// scheduled window pauses group, already stopped routines are kept
+	clock.Set(utc(2024, 1, 6, 2, 30))
+	m.Update()
+	assert.Equal(t, []proc.MaintenanceStatus{{
+		Group: "field", Until: utc(2024, 1, 6, 4, 0), Window: "weekly"},
+	}, m.Status())
+	assert.Equal(t, []string{"modbus_1", "modbus_2", "poller"}, suppressed())
+	assert.Equal(t, map[string]bool{"modbus_1": false, "modbus_2": false,
+		"poller": false, "web": true}, enabled())
+
+	// manual maintenance with duration
+	require.NoError(t, m.Enter("ops", 600))
+	assert.True(t, m.IsActive("ops"))
+	assert.Equal(t, utc(2024, 1, 6, 2, 40), m.Status()[1].Until)
+	assert.False(t, enabled()["web"])
+	clock.Set(utc(2024, 1, 6, 2, 45))
+	m.Update()
+	assert.False(t, m.IsActive("ops"))
+	assert.True(t, enabled()["web"])
+
+	// manual maintenance until exit
+	require.NoError(t, m.Enter("ops", 0))
+	clock.Set(utc(2024, 1, 6, 3, 30))
+	m.Update()
+	assert.Equal(t, []string{"modbus_1", "modbus_2", "poller", "web"},
+		suppressed())
+	require.NoError(t, m.Exit("ops"))
+	assert.True(t, enabled()["web"])
+
+	// window end resumes paused routines only
+	clock.Set(utc(2024, 1, 6, 4, 0))
+	m.Update()
+	assert.Empty(t, m.Status())
+	assert.Empty(t, suppressed())
+	assert.Equal(t, map[string]bool{"modbus_1": true, "modbus_2": false,
+		"poller": true, "web": true}, enabled())
+	rm.RunOnce()
+	for n, st := range rm.Status() {
+		assert.Equal(t, n != "modbus_2", st.(dictx.Dict)["alive"], n)
+	}
+	rm.Stop()
+}
+
+// startReloadManager starts a routine manager with the default monitoring
+// interval, a command listener at uri and a reload handler returning err.
+// It returns the reloads counter and a function stopping the manager.
+func startReloadManager(t *testing.T, uri string,
+	err error) (*proc.RoutineManager, *atomic.Int32, func()) {
+	log := logging.NewStdoutLogger("reload")
+	log.Level = logging.FATAL
+	rm := proc.NewRoutineManager(log)
+	tsk := &hookTasklet{}
+	tsk.h = proc.NewRoutineHandler(log, tsk)
+	require.NoError(t, rm.AddRoutine("worker", tsk.h, true))
+
+	l, lerr := memcomm.NewListener(uri, log, nil)
+	require.NoError(t, lerr)
+	rm.SetCmdHandler(l, func(string) string { return "" })
+	reloads := &atomic.Int32{}
+	rm.SetReloadHandler(func() error {
+		reloads.Add(1)
+		return err
+	})
+
+	done := make(chan struct{})
+	go func() {
+		rm.Start()
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		return rm.IsInitialized() && l.IsActive()
+	}, time.Second, 10*time.Millisecond)
+	// let the manager sleep in its monitoring interval
+	time.Sleep(200 * time.Millisecond)
+
+	return rm, reloads, func() {
+		rm.Stop()
+		<-done
+	}
+}
+
+func TestReloadCommand(t *testing.T) {
+	rm, reloads, stop := startReloadManager(t, "mem@reload_cmd", nil)
+	defer stop()
+	assert.Equal(t, float64(300), rm.MonitoringInterval)
+
+	c := proc.NewManagerClient("mem@reload_cmd", nil)
+	tStart := time.Now()
+	reply, err := c.CallRaw(proc.RELOAD_CMD, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "OK", reply)
+	assert.Less(t, time.Since(tStart), time.Second)
+	assert.Equal(t, int32(1), reloads.Load())
+
+	// reload runs directly
+	require.NoError(t, rm.Reload(1))
+	assert.Equal(t, int32(2), reloads.Load())
+	assert.True(t, rm.IsInitialized())
+}
+
+func TestReloadCommandError(t *testing.T) {
+	rm, reloads, stop := startReloadManager(
+		t, "mem@reload_err", errors.New("bad config"))
+	defer stop()
+
+	c := proc.NewManagerClient("mem@reload_err", nil)
+	reply, err := c.CallRaw(proc.RELOAD_CMD, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "ERROR: bad config", reply)
+	assert.Equal(t, int32(1), reloads.Load())
+	// process keeps running
+	assert.True(t, rm.IsAlive())
+}
+
+// gateTasklet blocks its initialization until the gate is closed, and
+// counts its initializations and executions.
+type gateTasklet struct {
+	h     *proc.TaskletHandler
+	gate  chan struct{}
+	inits atomic.Int32
+	execs atomic.Int32
+}
+
+func (t *gateTasklet) Initialize() error {
+	if t.gate != nil {
+		<-t.gate
+	}
+	t.inits.Add(1)
+	return nil
+}
+
+func (t *gateTasklet) Execute() error {
+	t.execs.Add(1)
+	t.h.Sleep(0.05)
+	return nil
+}
+
+func (t *gateTasklet) Terminate() error {
+	return nil
+}
+
+// delayListener delays the listener start.
+type delayListener struct {
+	*memcomm.Listener
+	delay time.Duration
+}
+
+func (l *delayListener) Start() error {
+	time.Sleep(l.delay)
+	return l.Listener.Start()
+}
+
+func TestRunAsProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("not supported on windows")
+	}
+	log := logging.NewStdoutLogger("runas")
+	log.Level = logging.FATAL
+	usr, err := user.Current()
+	require.NoError(t, err)
+
+	newProcess := func(uri, usr, group string) (*proc.Process, *gateTasklet) {
+		tsk := &gateTasklet{}
+		p := proc.NewProcessHandler(log, tsk)
+		tsk.h = p.TaskletHandler
+		l, err := memcomm.NewListener(uri, log, nil)
+		require.NoError(t, err)
+		p.SetCmdHandler(&delayListener{l, 300 * time.Millisecond},
+			func(string) string { return "" })
+		p.SetRunAs(usr, group)
+		return p, tsk
+	}
+
+	// switching waits for the command listener, and failure stops the
+	// process before any execution
+	p, tsk := newProcess("mem@runas_invalid", "no_such_user_xyz", "")
+	tStart := time.Now()
+	p.Start()
+	assert.GreaterOrEqual(t, time.Since(tStart), 300*time.Millisecond)
+	assert.Equal(t, int32(1), tsk.inits.Load())
+	assert.Zero(t, tsk.execs.Load())
+	assert.ErrorContains(t, p.ExitError(), "invalid user no_such_user_xyz")
+
+	p, tsk = newProcess("mem@runas_group", usr.Username, "no_such_group_xyz")
+	p.Start()
+	assert.Zero(t, tsk.execs.Load())
+	assert.ErrorContains(t, p.ExitError(), "invalid group no_such_group_xyz")
+
+	// switching to the current user by name or id is skipped
+	for _, v := range [][2]string{{usr.Username, ""}, {usr.Uid, usr.Gid}} {
+		p, tsk = newProcess("mem@runas_current", v[0], v[1])
+		done := make(chan struct{})
+		go func() {
+			p.Start()
+			close(done)
+		}()
+		assert.Eventually(t, func() bool {
+			return tsk.execs.Load() > 0
+		}, 2*time.Second, 10*time.Millisecond)
+		p.Stop()
+		<-done
+		assert.NoError(t, p.ExitError())
+	}
+}
+
+func TestRunAsRoutines(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("not supported on windows")
+	}
+	log := logging.NewStdoutLogger("runas")
+	log.Level = logging.FATAL
+	rm := proc.NewRoutineManager(log)
+	tsk := &gateTasklet{gate: make(chan struct{})}
+	tsk.h = proc.NewRoutineHandler(log, tsk)
+	require.NoError(t, rm.AddRoutine("worker", tsk.h, true))
+	// failed routines don't hold switching
+	broken := &hookTasklet{initErr: errors.New("init error")}
+	broken.h = proc.NewRoutineHandler(log, broken)
+	require.NoError(t, rm.AddRoutine("broken", broken.h, true))
+	rm.SetRunAs("no_such_user_xyz", "")
+
+	done := make(chan struct{})
+	go func() {
+		rm.Start()
+		close(done)
+	}()
+	require.Eventually(t, rm.IsInitialized, time.Second, 10*time.Millisecond)
+
+	// switching waits for routines initialization
+	time.Sleep(300 * time.Millisecond)
+	assert.NoError(t, rm.ExitError())
+	assert.False(t, rm.TermEvent.IsSet())
+
+	close(tsk.gate)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		rm.Kill()
+		t.Fatal("routine manager not stopped")
+	}
+	assert.Equal(t, int32(1), tsk.inits.Load())
+	assert.ErrorContains(t, rm.ExitError(), "invalid user no_such_user_xyz")
+}
+
+// decodeReply decodes the JSON command reply.
+func decodeReply(t *testing.T, s string) proc.CmdReply {
+	var reply proc.CmdReply
+	require.NoError(t, json.Unmarshal([]byte(s), &reply), s)
+	return reply
+}
+
+func TestCommandRegister(t *testing.T) {
+	r := proc.NewCommandRegistry()
+	fn := func(args dictx.Dict) (any, error) { return args, nil }
+
+	params := []proc.CmdParam{{Name: "a"}, {Name: "b", Type: proc.PARAM_INT}}
+	require.NoError(t, r.Register("cmd1", "", params, fn))
+	assert.Equal(t, "", params[0].Type)
+	require.NoError(t, r.Register("cmd2", "", params, fn))
+	assert.Equal(t, "", params[0].Type)
+
+	assert.ErrorIs(t, r.Register("cmd1", "", nil, fn), proc.ErrCommand)
+	assert.ErrorIs(t, r.Register("bad name", "", nil, fn), proc.ErrCommand)
+	assert.ErrorIs(t, r.Register(proc.HELP_CMD, "", nil, fn), proc.ErrCommand)
+	assert.ErrorIs(t, r.Register("cmd3", "", nil, nil), proc.ErrCommand)
+	assert.ErrorIs(t, r.Register("cmd3", "",
+		[]proc.CmdParam{{Name: "a", Type: "list"}}, fn), proc.ErrCommand)
+	assert.Equal(t, []string{"cmd1", "cmd2"}, r.Commands())
+}
+
+func TestCommandRequests(t *testing.T) {
+	r := proc.NewCommandRegistry()
+	require.NoError(t, r.Register("echo", "echo args", []proc.CmdParam{
+		{Name: "a", Required: true},
+		{Name: "n", Type: proc.PARAM_INT, Default: 5},
+		{Name: "f", Type: proc.PARAM_FLOAT},
+		{Name: "b", Type: proc.PARAM_BOOL},
+		{Name: "x", Type: proc.PARAM_ANY},
+	}, func(args dictx.Dict) (any, error) {
+		return args, nil
+	}))
+
+	tests := []struct {
+		request string
+		result  map[string]any
+		err     string
+	}{
+		// positional and quoted values
+		{`echo hi`, map[string]any{"a": "hi", "n": 5.0}, ""},
+		{`  echo   hi  `, map[string]any{"a": "hi", "n": 5.0}, ""},
+		{`echo "hello world" 7`, map[string]any{"a": "hello world", "n": 7.0}, ""},
+		{"echo x\t3\t2.5", map[string]any{"a": "x", "n": 3.0, "f": 2.5}, ""},
+		{`echo ""`, map[string]any{"a": "", "n": 5.0}, ""},
+		{`echo 1 2 3 true 5 6`, nil, "too many arguments"},
+		// named and escaped values
+		{`echo a="say \"hi\"" n=3`, map[string]any{"a": `say "hi"`, "n": 3.0}, ""},
+		{`echo a="back\\slash"`, map[string]any{"a": `back\slash`, "n": 5.0}, ""},
+		{`echo a=x\y`, map[string]any{"a": `x\y`, "n": 5.0}, ""},
+		{`echo a=k=v`, map[string]any{"a": "k=v", "n": 5.0}, ""},
+		{`echo a=x b=true f=1.5`,
+			map[string]any{"a": "x", "n": 5.0, "b": true, "f": 1.5}, ""},
+		{`echo x n=2`, map[string]any{"a": "x", "n": 2.0}, ""},
+		{`echo a=x hi`, nil, "positional after named argument"},
+		{`echo x a=y`, nil, "duplicate param a"},
+		{`echo =x`, nil, "invalid command argument"},
+		// JSON arguments
+		{`echo {"a": "x", "n": 2, "x": [1, "2"]}`,
+			map[string]any{"a": "x", "n": 2.0, "x": []any{1.0, "2"}}, ""},
+		{`{"cmd": "echo", "args": {"a": "x", "b": false}}`,
+			map[string]any{"a": "x", "n": 5.0, "b": false}, ""},
+		{`{"cmd": "echo", "args": {"a": "x", "n": 2.5}}`, nil, "param n, int expected"},
+		{`{"cmd": "echo", "args": {"a": 1}}`, nil, "param a, string expected"},
+		{`echo {"a": "x"`, nil, "invalid command arguments"},
+		{`{"cmd": ""}`, nil, "invalid command request"},
+		{`{"cmd": "echo"`, nil, "invalid command request"},
+		// typed and missing params
+		{`echo`, nil, "missing param a"},
+		{`echo n=2`, nil, "missing param a"},
+		{`echo a=x n=abc`, nil, "param n, int expected"},
+		{`echo a=x f=abc`, nil, "param f, float expected"},
+		{`echo a=x b=maybe`, nil, "param b, bool expected"},
+		{`echo a=x q=1`, nil, "unknown param q"},
+		// unknown commands
+		{``, nil, "invalid command request"},
+		{`unknown a=1`, nil, "invalid command unknown"},
+	}
+	for _, tc := range tests {
+		reply := decodeReply(t, r.Handle(tc.request))
+		if tc.err != "" {
+			assert.False(t, reply.Ok, tc.request)
+			assert.Nil(t, reply.Result, tc.request)
+			assert.Contains(t, reply.Error, tc.err, tc.request)
+			continue
+		}
+		assert.True(t, reply.Ok, tc.request, reply.Error)
+		assert.Equal(t, tc.result, reply.Result, tc.request)
+	}
+}
+
+func TestCommandReplies(t *testing.T) {
+	r := proc.NewCommandRegistry()
+	require.NoError(t, r.Register("fail", "", nil,
+		func(args dictx.Dict) (any, error) {
+			return "partial", errors.New("failed")
+		}))
+	require.NoError(t, r.Register("chan", "", nil,
+		func(args dictx.Dict) (any, error) {
+			return make(chan int), nil
+		}))
+	require.NoError(t, r.Register("empty", "", nil,
+		func(args dictx.Dict) (any, error) {
+			return nil, nil
+		}))
+
+	// error replies drop results
+	assert.Equal(t, `{"ok":false,"error":"failed"}`, r.Handle("fail"))
+	assert.Equal(t, `{"ok":true}`, r.Handle("empty"))
+
+	// results failing to encode reply with encoding error
+	reply := decodeReply(t, r.Handle("chan"))
+	assert.False(t, reply.Ok)
+	assert.Contains(t, reply.Error, "unsupported type")
+
+	assert.Equal(t, `{"ok":true,"result":["chan","empty","fail"]}`,
+		r.Handle(proc.LIST_COMMANDS_CMD))
+	reply = decodeReply(t, r.Handle(proc.HELP_CMD+" fail"))
+	assert.True(t, reply.Ok)
+	assert.Equal(t, map[string]any{"name": "fail", "help": ""}, reply.Result)
+	reply = decodeReply(t, r.Handle(proc.HELP_CMD+" cmd=none"))
+	assert.False(t, reply.Ok)
+	assert.Contains(t, reply.Error, "invalid command none")
+
+	// fallback handles unregistered commands
+	r.SetFallback(func(req string) string { return "RAW " + req })
+	assert.Equal(t, "RAW legacy cmd", r.Handle("legacy cmd"))
+}
+
+// startListener starts a memory listener at uri with connection handler.
+func startListener(t *testing.T, uri string,
+	h func(comm.Connection)) *memcomm.Listener {
+	l, err := memcomm.NewListener(uri, nil, nil)
+	require.NoError(t, err)
+	l.ConnectionHandler(h)
+	go l.Start()
+	require.Eventually(t, l.IsActive, time.Second, 10*time.Millisecond)
+	return l
+}
+
+func TestManagerClient(t *testing.T) {
+	log := logging.NewStdoutLogger("client")
+	log.Level = logging.FATAL
+	tsk := &hookTasklet{}
+	p := proc.NewProcessHandler(log, tsk)
+	tsk.h = p.TaskletHandler
+
+	r := proc.NewCommandRegistry()
+	require.NoError(t, r.Register("add", "", []proc.CmdParam{
+		{Name: "a", Type: proc.PARAM_INT, Required: true},
+		{Name: "b", Type: proc.PARAM_INT, Required: true},
+	}, func(args dictx.Dict) (any, error) {
+		return dictx.GetInt(args, "a", 0) + dictx.GetInt(args, "b", 0), nil
+	}))
+	r.SetFallback(func(req string) string {
+		if req == "ping" {
+			return "PONG"
+		}
+		return ""
+	})
+	l, err := memcomm.NewListener("mem@client_cmd", log, nil)
+	require.NoError(t, err)
+	p.SetCmdHandler(l, r.Handle)
+
+	done := make(chan struct{})
+	go func() {
+		p.Start()
+		close(done)
+	}()
+	defer func() {
+		p.Stop()
+		<-done
+	}()
+	require.Eventually(t, l.IsActive, time.Second, 10*time.Millisecond)
+
+	c := proc.NewManagerClient("mem@client_cmd", nil)
+	res, err := c.Call("add", dictx.Dict{"a": 1, "b": 2}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), res)
+
+	// error reply
+	_, err = c.Call("add", dictx.Dict{"a": 1}, 0)
+	assert.ErrorIs(t, err, proc.ErrReply)
+	assert.ErrorContains(t, err, "missing param b")
+	_, err = c.Call("add", dictx.Dict{"a": 1, "b": "x"}, 0)
+	assert.ErrorIs(t, err, proc.ErrReply)
+	assert.ErrorContains(t, err, "param b, int expected")
+
+	// raw replies
+	res, err = c.Call("ping", nil, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "PONG", res)
+	reply, err := c.CallRaw(proc.LIST_COMMANDS_CMD, 0)
+	require.NoError(t, err)
+	assert.Equal(t, `{"ok":true,"result":["add"]}`, reply)
+
+	// no reply from fallback
+	tStart := time.Now()
+	_, err = c.CallRaw("noreply", 0.2)
+	assert.ErrorIs(t, err, comm.ErrTimeout)
+	assert.Less(t, time.Since(tStart), time.Second)
+
+	// no listener
+	_, err = proc.NewManagerClient("mem@client_none", nil).CallRaw("ping", 0.2)
+	assert.ErrorIs(t, err, comm.ErrConnection)
+}
+
+func TestManagerClientFraming(t *testing.T) {
+	requests := make(chan string, 10)
+	l := startListener(t, "mem@client_frame", func(conn comm.Connection) {
+		b, err := conn.Recv(0)
+		if err != nil {
+			return
+		}
+		requests <- string(b)
+		// reply split over chunks with trailing data after newline
+		for _, s := range []string{
+			"  {\"ok\":tr", "ue,\"result\":", "[1,2]}\n", "extra\n"} {
+			if conn.Send([]byte(s), 0) != nil {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		conn.Recv(0)
+	})
+	defer l.Stop()
+
+	c := proc.NewManagerClient("mem@client_frame", nil)
+	res, err := c.Call("cmd", dictx.Dict{"key": "a b"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []any{1.0, 2.0}, res)
+	assert.Equal(t, "cmd {\"key\":\"a b\"}\n", <-requests)
+
+	reply, err := c.CallRaw("cmd", 1)
+	require.NoError(t, err)
+	assert.Equal(t, `{"ok":true,"result":[1,2]}`, reply)
+	assert.Equal(t, "cmd\n", <-requests)
+}
+
+func TestManagerClientTimeout(t *testing.T) {
+	// reply never terminated with newline
+	l := startListener(t, "mem@client_timeout", func(conn comm.Connection) {
+		if _, err := conn.Recv(0); err != nil {
+			return
+		}
+		for conn.IsOpened() {
+			if conn.Send([]byte("partial"), 0) != nil {
+				return
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	})
+	defer l.Stop()
+
+	c := proc.NewManagerClient("mem@client_timeout", nil)
+	tStart := time.Now()
+	_, err := c.CallRaw("cmd", 0.3)
+	assert.ErrorIs(t, err, comm.ErrTimeout)
+	assert.Less(t, time.Since(tStart), time.Second)
+}
+
+func TestPidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proc.pid")
+	ownPid := strconv.Itoa(os.Getpid()) + "\n"
+
+	p1 := proc.NewPidFile(path)
+	stale, err := p1.Acquire()
+	require.NoError(t, err)
+	assert.Equal(t, 0, stale)
+	assert.True(t, p1.IsHeld())
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, ownPid, string(b))
+
+	// acquiring again by holder is a no-op
+	stale, err = p1.Acquire()
+	require.NoError(t, err)
+	assert.Equal(t, 0, stale)
+
+	// second instance fails while pid file is locked
+	p2 := proc.NewPidFile(path)
+	_, err = p2.Acquire()
+	assert.ErrorIs(t, err, proc.ErrRunning)
+	assert.ErrorContains(t, err, "pid "+strconv.Itoa(os.Getpid()))
+	assert.False(t, p2.IsHeld())
+	assert.NoError(t, p2.Release())
+	assert.FileExists(t, path)
+
+	// release removes the pid file
+	require.NoError(t, p1.Release())
+	assert.False(t, p1.IsHeld())
+	assert.NoFileExists(t, path)
+	assert.NoError(t, p1.Release())
+
+	stale, err = p2.Acquire()
+	require.NoError(t, err)
+	assert.Equal(t, 0, stale)
+	require.NoError(t, p2.Release())
+}
+
+func TestPidFileStale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proc.pid")
+
+	// unlocked pid file left by a crashed instance is taken over
+	require.NoError(t, os.WriteFile(path, []byte("999999999\n"), 0o644))
+	p := proc.NewPidFile(path)
+	stale, err := p.Acquire()
+	require.NoError(t, err)
+	assert.Equal(t, 999999999, stale)
+	pid, err := proc.ReadPidFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), pid)
+	require.NoError(t, p.Release())
+
+	// own pid and invalid content are not reported as stale
+	for _, s := range []string{strconv.Itoa(os.Getpid()), "garbage", ""} {
+		require.NoError(t, os.WriteFile(path, []byte(s), 0o644))
+		stale, err = p.Acquire()
+		require.NoError(t, err, s)
+		assert.Equal(t, 0, stale, s)
+		pid, err = proc.ReadPidFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.Getpid(), pid)
+		require.NoError(t, p.Release())
+	}
+}
+
+// writeBoots writes boot counter records of pids started ago seconds.
+func writeBoots(t *testing.T, path string, ago int64, pids ...int) {
+	entries := []dictx.Dict{}
+	for _, pid := range pids {
+		entries = append(entries, dictx.Dict{
+			"pid": pid, "time": time.Now().Unix() - ago})
+	}
+	b, err := json.Marshal(entries)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, b, 0o644))
+}
+
+func TestBootCounter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "boot.json")
+	b := proc.NewBootCounter(path, dictx.Dict{
+		"boot_max_crashes": 3, "boot_crash_window": 60})
+	assert.Equal(t, 3, b.MaxCrashes)
+	assert.Equal(t, float64(60), b.Window)
+
+	// unclean starts are counted
+	for i := 0; i < 4; i++ {
+		crashes, err := b.Register()
+		require.NoError(t, err)
+		assert.Equal(t, i, crashes)
+		assert.Equal(t, i >= 3, b.IsCrashLoop(crashes))
+	}
+
+	// clean stop removes current start only
+	require.NoError(t, b.Reset())
+	writeBoots(t, path, 0, 100, 101)
+	crashes, err := b.Register()
+	require.NoError(t, err)
+	assert.Equal(t, 2, crashes)
+	require.NoError(t, b.Clear())
+	crashes, err = b.Register()
+	require.NoError(t, err)
+	assert.Equal(t, 2, crashes)
+
+	// starts outside crash window are dropped
+	writeBoots(t, path, 120, 100, 101, 102, 103)
+	crashes, err = b.Register()
+	require.NoError(t, err)
+	assert.Equal(t, 0, crashes)
+	crashes, err = b.Register()
+	require.NoError(t, err)
+	assert.Equal(t, 1, crashes)
+
+	// missing or invalid file has no starts
+	require.NoError(t, os.WriteFile(path, []byte("invalid"), 0o644))
+	crashes, err = b.Register()
+	require.NoError(t, err)
+	assert.Equal(t, 0, crashes)
+	require.NoError(t, os.Remove(path))
+	crashes, err = b.Register()
+	require.NoError(t, err)
+	assert.Equal(t, 0, crashes)
+
+	// invalid options use defaults
+	b = proc.NewBootCounter(path, dictx.Dict{
+		"boot_max_crashes": 0, "boot_crash_window": -1})
+	assert.Equal(t, proc.BOOT_MAX_CRASHES, b.MaxCrashes)
+	assert.Equal(t, float64(proc.BOOT_CRASH_WINDOW), b.Window)
+}
+
+func TestBootCounterSafeMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "boot.json")
+	run := func() (bool, []string) {
+		log := logging.NewStdoutLogger("boot")
+		log.Level = logging.FATAL
+		tsk := &hookTasklet{}
+		p := proc.NewProcessHandler(log, tsk)
+		tsk.h = p.TaskletHandler
+		p.SetBootCounter(proc.NewBootCounter(path, dictx.Dict{
+			"boot_max_crashes": 2}))
+
+		done := make(chan struct{})
+		go func() {
+			p.Start()
+			close(done)
+		}()
+		time.Sleep(200 * time.Millisecond)
+		safe := p.IsSafeMode()
+		p.Stop()
+		<-done
+		return safe, tsk.calls
+	}
+	records := func() int {
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		entries := []any{}
+		require.NoError(t, json.Unmarshal(b, &entries))
+		return len(entries)
+	}
+
+	// below crash limit runs normally and clears own start
+	writeBoots(t, path, 0, 100)
+	safe, calls := run()
+	assert.False(t, safe)
+	assert.Contains(t, calls, "initialize")
+	assert.Equal(t, 1, records())
+
+	// crash loop starts in safe mode without running tasklet, and
+	// clean stop in safe mode resets the starts
+	writeBoots(t, path, 0, 100, 101)
+	safe, calls = run()
+	assert.True(t, safe)
+	assert.Empty(t, calls)
+	assert.Equal(t, 0, records())
+
+	// next start runs normally
+	safe, calls = run()
+	assert.False(t, safe)
+	assert.Contains(t, calls, "initialize")
+	assert.Equal(t, 0, records())
+}
+
+// logSink collects the log records.
+type logSink struct {
+	records []string
+	mu      sync.Mutex
+}
+
+func (s *logSink) HandleRecord(r string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, r)
+	return nil
+}
+
+// count returns the number of records containing text.
+func (s *logSink) count(text string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, r := range s.records {
+		if strings.Contains(r, text) {
+			n++
+		}
+	}
+	return n
+}
+
+// TestSupervisorHelper runs as the supervised child process.
+func TestSupervisorHelper(t *testing.T) {
+	mode := os.Getenv("PROC_TEST_HELPER")
+	if mode == "" {
+		t.Skip("supervisor helper process")
+	}
+	fmt.Println("helper started")
+	switch mode {
+	case "crash":
+		fmt.Fprintln(os.Stderr, "helper crashed")
+		os.Exit(3)
+	case "exit":
+		os.Exit(0)
+	case "tree", "orphan":
+		// descendant inheriting the output pipes
+		cmd := exec.Command(os.Args[0], "-test.run=^TestSupervisorHelper$")
+		cmd.Env = append(os.Environ(), "PROC_TEST_HELPER=sleep")
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Start(); err != nil {
+			os.Exit(1)
+		}
+		fmt.Printf("descendant %d\n", cmd.Process.Pid)
+		if mode == "orphan" {
+			os.Exit(0)
+		}
+	}
+	time.Sleep(30 * time.Second)
+	os.Exit(0)
+}
+
+// startSupervisor starts a supervisor of the helper child in mode.
+func startSupervisor(t *testing.T, mode string,
+	opts dictx.Dict) (*proc.ProcessSupervisor, *logSink, chan struct{}) {
+	sink := &logSink{}
+	log := logging.NewStdoutLogger("supervisor")
+	log.SetFormatter(logging.NewRawFormatter())
+	log.ClearHandlers()
+	log.AddHandler(sink)
+
+	opts["env"] = []string{"PROC_TEST_HELPER=" + mode}
+	s := proc.NewProcessSupervisor(log, []string{
+		os.Args[0], "-test.run=^TestSupervisorHelper$"}, opts)
+	s.Enable()
+	done := make(chan struct{})
+	go func() {
+		s.Start()
+		close(done)
+	}()
+	return s, sink, done
+}
+
+func TestProcessSupervisor(t *testing.T) {
+	s, sink, done := startSupervisor(t, "sleep", dictx.Dict{"stop_timeout": 5})
+	require.Eventually(t, func() bool {
+		return s.Pid() > 0 && sink.count("helper started") == 1
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.True(t, s.IsAlive())
+	assert.Equal(t, 1, sink.count(fmt.Sprintf("started process, pid %d", s.Pid())))
+
+	// stop terminates the child gracefully
+	tStart := time.Now()
+	s.Disable()
+	s.Stop()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("supervisor stop timeout")
+	}
+	assert.Less(t, time.Since(tStart), 5*time.Second)
+	assert.Equal(t, 0, s.Pid())
+	assert.False(t, s.IsAlive())
+	assert.Equal(t, 1, sink.count("stopping process"))
+	assert.Equal(t, 0, sink.count("killing process"))
+}
+
+func TestProcessSupervisorRestart(t *testing.T) {
+	// failed child is restarted up to max restarts
+	s, sink, done := startSupervisor(t, "crash", dictx.Dict{
+		"restart_policy": proc.RESTART_ON_FAILURE, "restart_max": 2,
+		"restart_backoff_min": 0.01})
+	select {
+	case <-done:
+	case <-time.After(20 * time.Second):
+		t.Fatal("supervisor restart timeout")
+	}
+	assert.Equal(t, 2, s.RestartCount())
+	assert.Equal(t, 3, sink.count("helper started"))
+	assert.Equal(t, 3, sink.count("helper crashed"))
+	assert.ErrorIs(t, s.LastError(), proc.ErrFailure)
+	assert.False(t, s.IsEnabled())
+
+	// clean exit ends supervision without restarts
+	s, sink, done = startSupervisor(t, "exit", dictx.Dict{
+		"restart_policy":      proc.RESTART_ON_FAILURE,
+		"restart_backoff_min": 0.01})
+	select {
+	case <-done:
+	case <-time.After(20 * time.Second):
+		t.Fatal("supervisor exit timeout")
+	}
+	assert.Equal(t, 0, s.RestartCount())
+	assert.Equal(t, 1, sink.count("helper started"))
+	assert.Equal(t, 1, sink.count("process exited"))
+}
+
+func TestResourceMonitorStats(t *testing.T) {
+	log := logging.NewStdoutLogger("resmon")
+	log.Level = logging.FATAL
+	m := proc.NewResourceMonitor(log, dictx.Dict{"resmon_interval": 0.05})
+	assert.Equal(t, 0.05, m.Interval)
+	assert.True(t, m.Stats().Time.IsZero())
+
+	st := m.Sample()
+	assert.Equal(t, st, m.Stats())
+	assert.Greater(t, st.Goroutines, 0)
+	assert.Greater(t, st.HeapAlloc, uint64(0))
+	assert.GreaterOrEqual(t, st.HeapSys, st.HeapAlloc)
+	if runtime.GOOS == "linux" {
+		assert.Greater(t, st.Rss, uint64(0))
+
+		// open files are counted
+		f, err := os.Open(os.Args[0])
+		require.NoError(t, err)
+		n := proc.SampleResources().Fds
+		f.Close()
+		assert.Greater(t, st.Fds, 0)
+		assert.Equal(t, st.Fds+1, n)
+	}
+	d := st.Dict()
+	for _, k := range []string{"time", "rss", "heap_alloc", "heap_sys",
+		"goroutines", "num_gc", "gc_pause_ms", "fds"} {
+		assert.Contains(t, d, k)
+	}
+
+	// routine samples periodically
+	m.Enable()
+	done := make(chan struct{})
+	go func() {
+		m.Start()
+		close(done)
+	}()
+	tStart := time.Now()
+	require.Eventually(t, func() bool {
+		return m.Stats().Time.After(tStart.Add(100 * time.Millisecond))
+	}, 2*time.Second, 10*time.Millisecond)
+	m.Disable()
+	m.Stop()
+	<-done
+}
+
+func TestResourceMonitorLimits(t *testing.T) {
+	sink := &logSink{}
+	log := logging.NewStdoutLogger("resmon")
+	log.SetFormatter(logging.NewRawFormatter())
+	log.ClearHandlers()
+	log.AddHandler(sink)
+
+	m := proc.NewResourceMonitor(log, dictx.Dict{
+		"resmon_max_heap": 1, "resmon_max_goroutines": 1})
+	var mu sync.Mutex
+	limits := []string{}
+	m.OnLimit(func(limit string, st proc.ResourceStats) {
+		panic("handler failed")
+	})
+	m.OnLimit(func(limit string, st proc.ResourceStats) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.False(t, st.Time.IsZero())
+		limits = append(limits, limit)
+	})
+	fired := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		res := limits
+		limits = []string{}
+		return res
+	}
+
+	// handlers called once per breach, panics are recovered
+	m.Sample()
+	assert.Equal(t, []string{proc.LIMIT_HEAP, proc.LIMIT_GOROUTINES}, fired())
+	assert.Equal(t, 2, sink.count("limit handler panic"))
+	m.Sample()
+	assert.Empty(t, fired())
+	assert.Equal(t, 2, sink.count("usage exceeded limit"))
+
+	// handlers called again after usage drops below limit
+	m.MaxGoroutines = 1000000
+	m.Sample()
+	assert.Empty(t, fired())
+	assert.Equal(t, 1, sink.count("goroutines usage back within limit"))
+	m.MaxGoroutines = 1
+	m.Sample()
+	assert.Equal(t, []string{proc.LIMIT_GOROUTINES}, fired())
+
+	// disabled limits
+	m = proc.NewResourceMonitor(log, nil)
+	m.OnLimit(func(limit string, st proc.ResourceStats) {
+		limits = append(limits, limit)
+	})
+	m.Sample()
+	assert.Empty(t, fired())
+}
+
+func TestDaemonConfig(t *testing.T) {
+	c := proc.NewDaemonConfig(nil)
+	assert.Equal(t, proc.DaemonConfig{WorkDir: "/", Umask: 0o022}, c)
+
+	c = proc.NewDaemonConfig(dictx.Dict{
+		"daemon_log_file":   "/var/log/app.log",
+		"daemon_workdir":    "/var/lib/app",
+		"daemon_umask":      -1,
+		"daemon_chroot":     "/srv/app",
+		"daemon_user":       "app",
+		"daemon_group":      "apps",
+		"daemon_foreground": true,
+	})
+	assert.Equal(t, proc.DaemonConfig{
+		LogFile: "/var/log/app.log", WorkDir: "/var/lib/app", Umask: -1,
+		Chroot: "/srv/app", User: "app", Group: "apps", Foreground: true}, c)
+
+	t.Setenv(proc.DAEMON_ENV, "")
+	assert.False(t, proc.IsDaemonized())
+	t.Setenv(proc.DAEMON_ENV, "1")
+	assert.True(t, proc.IsDaemonized())
+}
+
+func TestLogLevelCommand(t *testing.T) {
+	reg := logging.NewRegistry()
+	root := logging.NewStdoutLogger("main")
+	child := logging.NewStdoutLogger("main.comm")
+	other := logging.NewStdoutLogger("mainx")
+	reg.Register("main", root)
+	reg.Register("main.comm", child)
+	reg.Register("mainx", other)
+
+	r := proc.NewCommandRegistry()
+	require.NoError(t, proc.RegisterLogLevel(r, reg))
+	assert.ErrorIs(t, proc.RegisterLogLevel(r, reg), proc.ErrCommand)
+
+	// list and get levels
+	assert.Equal(t, `{"ok":true,"result":{"main":"INFO","main.comm":"INFO","mainx":"INFO"}}`,
+		r.Handle(proc.LOG_LEVEL_CMD))
+	assert.Equal(t, `{"ok":true,"result":"INFO"}`,
+		r.Handle(proc.LOG_LEVEL_CMD+" main.comm"))
+
+	// set level of logger and its descendants
+	assert.Equal(t, `{"ok":true,"result":{"main":"DEBUG","main.comm":"DEBUG"}}`,
+		r.Handle(proc.LOG_LEVEL_CMD+" main debug"))
+	assert.Equal(t, logging.DEBUG, root.Level)
+	assert.Equal(t, logging.DEBUG, child.Level)
+	assert.Equal(t, logging.INFO, other.Level)
+	assert.Equal(t, `{"ok":true,"result":{"main.comm":"WARN"}}`,
+		r.Handle(`{"cmd": "log_level", "args": {"name": "main.comm", "level": "warning"}}`))
+	assert.Equal(t, logging.WARN, child.Level)
+	assert.Equal(t, logging.DEBUG, root.Level)
+
+	// unknown loggers and invalid levels
+	for _, req := range []string{"log_level none", "log_level none info"} {
+		reply := decodeReply(t, r.Handle(req))
+		assert.False(t, reply.Ok, req)
+		assert.Contains(t, reply.Error, logging.ErrUnknownLogger.Error()+" none", req)
+	}
+	reply := decodeReply(t, r.Handle("log_level main verbose"))
+	assert.False(t, reply.Ok)
+	assert.Contains(t, reply.Error, `invalid log level "verbose"`)
+	assert.Equal(t, logging.DEBUG, root.Level)
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package watchdog
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Service manager notification states.
+const (
+	NOTIFY_READY    = "READY=1"
+	NOTIFY_STOPPING = "STOPPING=1"
+	NOTIFY_WATCHDOG = "WATCHDOG=1"
+	NOTIFY_RELOAD   = "RELOADING=1"
+)
+
+// Notify sends the state to the service manager notification socket defined
+// by the NOTIFY_SOCKET environment variable, following the systemd
+// sd_notify protocol. It returns false if notification is not supported.
+func Notify(state string) (bool, error) {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return false, nil
+	}
+	// abstract namespace socket
+	if strings.HasPrefix(addr, "@") {
+		addr = "\x00" + addr[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil,
+		&net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// NotifyStatus sends a free-form status text to the service manager.
+func NotifyStatus(status string) (bool, error) {
+	return Notify("STATUS=" + status)
+}
+
+// WatchdogInterval returns the service manager watchdog interval defined
+// by the WATCHDOG_USEC environment variable, or 0 if not enabled for the
+// current process.
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if s := os.Getenv("WATCHDOG_PID"); s != "" {
+		if pid, err := strconv.Atoi(s); err != nil || pid != os.Getpid() {
+			return 0
+		}
+	}
+	return time.Duration(usec) * time.Microsecond
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package watchdog_test
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/logging"
+	"github.com/exonlabs/go-utils/pkg/proc/watchdog"
+)
+
+func listenNotify(t *testing.T) *net.UnixConn {
+	dir, err := os.MkdirTemp("", "wd")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "notify")
+	conn, err := net.ListenUnixgram("unixgram",
+		&net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+	return conn
+}
+
+func recvNotify(conn *net.UnixConn) string {
+	b := make([]byte, 256)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(b)
+	if err != nil {
+		return ""
+	}
+	return string(b[:n])
+}
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	ok, err := watchdog.Notify(watchdog.NOTIFY_READY)
+	assert.False(t, ok)
+	assert.NoError(t, err)
+
+	conn := listenNotify(t)
+	ok, err = watchdog.Notify(watchdog.NOTIFY_READY)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, "READY=1", recvNotify(conn))
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "")
+	assert.Zero(t, watchdog.WatchdogInterval())
+	t.Setenv("WATCHDOG_USEC", "4000000")
+	assert.Equal(t, 4*time.Second, watchdog.WatchdogInterval())
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()+1))
+	assert.Zero(t, watchdog.WatchdogInterval())
+}
+
+func TestWatchdog(t *testing.T) {
+	conn := listenNotify(t)
+	dev := filepath.Join(t.TempDir(), "watchdog")
+	require.NoError(t, os.WriteFile(dev, nil, 0o664))
+
+	log := logging.NewStdoutLogger("watchdog")
+	log.Level = logging.FATAL
+	wd := watchdog.New(log, dictx.Dict{
+		"watchdog_interval": 0.05,
+		"watchdog_device":   dev,
+	})
+	var unhealthy atomic.Bool
+	wd.AddCheck("test", func() error {
+		if unhealthy.Load() {
+			return errors.New("unhealthy")
+		}
+		return nil
+	})
+
+	wd.Enable()
+	go wd.Start()
+	assert.Equal(t, "READY=1", recvNotify(conn))
+	assert.Equal(t, "WATCHDOG=1", recvNotify(conn))
+
+	unhealthy.Store(true)
+	assert.ErrorContains(t, wd.Check(), "test - unhealthy")
+
+	wd.Disable()
+	wd.Stop()
+	assert.True(t, wd.WaitStop(2))
+	b, err := os.ReadFile(dev)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "V")
+}
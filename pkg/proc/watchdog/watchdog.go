@@ -0,0 +1,204 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package watchdog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/logging"
+	"github.com/exonlabs/go-utils/pkg/proc"
+)
+
+// WATCHDOG_INTERVAL defines the default kick interval in seconds.
+const WATCHDOG_INTERVAL = 10
+
+// HealthCheck defines the function checking the process health,
+// returning error to stop kicking the watchdogs.
+type HealthCheck func() error
+
+// Watchdog defines a routine kicking the service manager watchdog and
+// the hardware watchdog device periodically, while all health checks
+// pass. When a check fails, kicking stops so the service manager or the
+// hardware watchdog takes over and recovers the system.
+type Watchdog struct {
+	*proc.RoutineHandler
+
+	// Interval defines the kick interval in seconds.
+	Interval float64
+	// Device defines the hardware watchdog device path, empty to disable.
+	Device string
+	// Notify enables the service manager notifications.
+	Notify bool
+
+	checks    map[string]HealthCheck
+	checkLock sync.Mutex
+	dev       *os.File
+	healthy   bool
+}
+
+// New creates a new watchdog routine.
+// The parsed options are:
+//   - watchdog_interval: (float64) the kick interval in seconds.
+//     (default is half of service manager watchdog interval if defined,
+//     else 10)
+//   - watchdog_device: (string) the hardware watchdog device path, such
+//     as /dev/watchdog. (default is disabled)
+//   - watchdog_notify: (bool) enables the service manager notifications.
+//     (default is true)
+func New(log *logging.Logger, opts dictx.Dict) *Watchdog {
+	interval := float64(WATCHDOG_INTERVAL)
+	if d := WatchdogInterval(); d > 0 {
+		interval = d.Seconds() / 2
+	}
+	w := &Watchdog{
+		Interval: dictx.GetFloat(opts, "watchdog_interval", interval),
+		Device:   dictx.GetString(opts, "watchdog_device", ""),
+		Notify:   dictx.Fetch(opts, "watchdog_notify", true),
+		checks:   map[string]HealthCheck{},
+	}
+	w.RoutineHandler = proc.NewRoutineHandler(log, w)
+	return w
+}
+
+// AddCheck adds a named health check.
+func (w *Watchdog) AddCheck(name string, fn HealthCheck) {
+	w.checkLock.Lock()
+	defer w.checkLock.Unlock()
+	w.checks[name] = fn
+}
+
+// DelCheck removes a named health check.
+func (w *Watchdog) DelCheck(name string) {
+	w.checkLock.Lock()
+	defer w.checkLock.Unlock()
+	delete(w.checks, name)
+}
+
+// Check runs the health checks and returns the combined failures.
+func (w *Watchdog) Check() error {
+	w.checkLock.Lock()
+	names := make([]string, 0, len(w.checks))
+	for n := range w.checks {
+		names = append(names, n)
+	}
+	w.checkLock.Unlock()
+	sort.Strings(names)
+
+	var errAll error
+	for _, n := range names {
+		w.checkLock.Lock()
+		fn, ok := w.checks[n]
+		w.checkLock.Unlock()
+		if !ok {
+			continue
+		}
+		if err := fn(); err != nil {
+			errAll = errors.Join(errAll, fmt.Errorf("%s - %w", n, err))
+		}
+	}
+	return errAll
+}
+
+// Initialize opens the hardware watchdog device and notifies the
+// service manager readiness.
+func (w *Watchdog) Initialize() error {
+	if w.Device != "" {
+		f, err := os.OpenFile(w.Device, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		w.dev = f
+		w.Log.Info("opened watchdog device %s", w.Device)
+	}
+	w.healthy = true
+	if w.Notify {
+		if _, err := Notify(NOTIFY_READY); err != nil {
+			w.Log.Error("notify failed: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// Execute runs the health checks and kicks the watchdogs if healthy.
+func (w *Watchdog) Execute() error {
+	if err := w.Check(); err != nil {
+		if w.healthy {
+			w.Log.Error("health check failed, watchdog kick stopped: %s",
+				err.Error())
+			w.healthy = false
+		}
+	} else {
+		if !w.healthy {
+			w.Log.Info("health check recovered, watchdog kick resumed")
+			w.healthy = true
+		}
+		w.kick()
+	}
+	w.Sleep(w.Interval)
+	return nil
+}
+
+// Terminate notifies the service manager stopping and disarms the
+// hardware watchdog device.
+func (w *Watchdog) Terminate() error {
+	if w.Notify {
+		Notify(NOTIFY_STOPPING)
+	}
+	if w.dev != nil {
+		// magic close disarms the watchdog on supporting drivers
+		w.dev.Write([]byte("V"))
+		err := w.dev.Close()
+		w.dev = nil
+		return err
+	}
+	return nil
+}
+
+// kick kicks the service manager watchdog and the hardware watchdog.
+func (w *Watchdog) kick() {
+	if w.Notify {
+		if _, err := Notify(NOTIFY_WATCHDOG); err != nil {
+			w.Log.Error("notify failed: %s", err.Error())
+		}
+	}
+	if w.dev != nil {
+		if _, err := w.dev.Write([]byte{0}); err != nil {
+			w.Log.Error("watchdog device kick failed: %s", err.Error())
+		}
+	}
+}
+
+// CheckRoutines returns a health check failing when any running routine
+// of routine manager did not execute for more than maxIdle seconds.
+func CheckRoutines(m *proc.RoutineManager, maxIdle float64) HealthCheck {
+	return func() error {
+		stale := []string{}
+		for name, v := range m.Status() {
+			st, ok := v.(dictx.Dict)
+			if !ok || dictx.GetString(st, "state", "") != "running" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339,
+				dictx.GetString(st, "last_execute", ""))
+			if err != nil {
+				continue
+			}
+			if time.Since(t).Seconds() > maxIdle {
+				stale = append(stale, name)
+			}
+		}
+		if len(stale) > 0 {
+			sort.Strings(stale)
+			return fmt.Errorf("routines not executing: %v", stale)
+		}
+		return nil
+	}
+}
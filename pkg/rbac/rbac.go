@@ -0,0 +1,340 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package rbac
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+)
+
+// Permission prefixes used by the management surfaces.
+const (
+	CMD_PREFIX     = "cmd:"
+	HTTP_PREFIX    = "http:"
+	CONSOLE_PREFIX = "console:"
+)
+
+var (
+	// ErrDenied indicates that the subject is not authorized.
+	ErrDenied = errors.New("permission denied")
+	// ErrConfig indicates invalid rbac configuration.
+	ErrConfig = errors.New("invalid rbac config")
+)
+
+// Role defines a named set of permissions.
+type Role struct {
+	// Name defines the role name.
+	Name string
+	// Permissions defines the role permission patterns. The '*' char
+	// in pattern matches any sequence of chars.
+	Permissions []string
+	// Inherits defines the roles whose permissions are included.
+	Inherits []string
+}
+
+// Policy defines the roles and subjects roles assignment used to
+// authorize actions on management surfaces.
+type Policy struct {
+	// roles defines the roles by name.
+	roles map[string]*Role
+	// subjects defines the assigned roles by subject name.
+	subjects map[string][]string
+	// mu defines mutex for policy changes.
+	mu sync.RWMutex
+}
+
+// New creates a new empty policy.
+func New() *Policy {
+	return &Policy{
+		roles:    map[string]*Role{},
+		subjects: map[string][]string{},
+	}
+}
+
+// Load creates a new policy from config dict.
+// The parsed config keys are:
+//   - roles: (dict) the roles definitions by name, each role has keys:
+//     permissions (list of permission patterns) and inherits (list of roles).
+//   - subjects: (dict) the list of assigned roles by subject name.
+//
+// example:
+//
+//	{
+//	  "roles": {
+//	    "viewer": {"permissions": ["cmd:status", "http:GET:*"]},
+//	    "admin": {"permissions": ["*"], "inherits": ["viewer"]}
+//	  },
+//	  "subjects": {"alice": ["admin"], "bob": ["viewer"]}
+//	}
+func Load(cfg dictx.Dict) (*Policy, error) {
+	p := New()
+
+	roles, _ := dictx.Get(cfg, "roles", dictx.Dict{}).(dictx.Dict)
+	for name, v := range roles {
+		d, ok := v.(dictx.Dict)
+		if !ok {
+			return nil, fmt.Errorf("%w - role: %s", ErrConfig, name)
+		}
+		perms, err := toStrings(dictx.Get(d, "permissions", nil))
+		if err != nil {
+			return nil, fmt.Errorf("%w - role: %s", err, name)
+		}
+		inherits, err := toStrings(dictx.Get(d, "inherits", nil))
+		if err != nil {
+			return nil, fmt.Errorf("%w - role: %s", err, name)
+		}
+		p.SetRole(&Role{Name: name, Permissions: perms, Inherits: inherits})
+	}
+	for name, r := range p.roles {
+		for _, i := range r.Inherits {
+			if _, ok := p.roles[i]; !ok {
+				return nil, fmt.Errorf(
+					"%w - role: %s, undefined inherited role: %s",
+					ErrConfig, name, i)
+			}
+		}
+	}
+
+	subjects, _ := dictx.Get(cfg, "subjects", dictx.Dict{}).(dictx.Dict)
+	for name, v := range subjects {
+		roles, err := toStrings(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w - subject: %s", err, name)
+		}
+		if err := p.Assign(name, roles...); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// toStrings converts config list value to strings slice.
+func toStrings(v any) ([]string, error) {
+	switch val := v.(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return val, nil
+	case []any:
+		res := make([]string, 0, len(val))
+		for _, s := range val {
+			str, ok := s.(string)
+			if !ok {
+				return nil, ErrConfig
+			}
+			res = append(res, str)
+		}
+		return res, nil
+	}
+	return nil, ErrConfig
+}
+
+// SetRole adds or replaces a role in policy.
+func (p *Policy) SetRole(r *Role) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.roles[r.Name] = r
+}
+
+// Role returns a role by name, or nil if not defined.
+func (p *Policy) Role(name string) *Role {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.roles[name]
+}
+
+// Roles returns the sorted names of defined roles.
+func (p *Policy) Roles() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	names := make([]string, 0, len(p.roles))
+	for name := range p.roles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Assign sets the roles of a subject, replacing any previous assignment.
+// Assigned roles must be defined in policy.
+func (p *Policy) Assign(subject string, roles ...string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, r := range roles {
+		if _, ok := p.roles[r]; !ok {
+			return fmt.Errorf("%w - undefined role: %s", ErrConfig, r)
+		}
+	}
+	p.subjects[subject] = roles
+	return nil
+}
+
+// SubjectRoles returns the roles assigned to a subject.
+func (p *Policy) SubjectRoles(subject string) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]string(nil), p.subjects[subject]...)
+}
+
+// IsAllowed checks if subject is granted the permission through its
+// assigned roles and their inherited roles.
+func (p *Policy) IsAllowed(subject, perm string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	visited := map[string]bool{}
+	var check func(name string) bool
+	check = func(name string) bool {
+		if visited[name] {
+			return false
+		}
+		visited[name] = true
+		r, ok := p.roles[name]
+		if !ok {
+			return false
+		}
+		for _, pattern := range r.Permissions {
+			if Match(pattern, perm) {
+				return true
+			}
+		}
+		for _, name := range r.Inherits {
+			if check(name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, name := range p.subjects[subject] {
+		if check(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns [ErrDenied] if subject is not granted the permission.
+func (p *Policy) Check(subject, perm string) error {
+	if !p.IsAllowed(subject, perm) {
+		return fmt.Errorf("%w - %s: %s", ErrDenied, subject, perm)
+	}
+	return nil
+}
+
+// Match checks if permission matches the pattern, where the '*' char
+// in pattern matches any sequence of chars.
+func Match(pattern, perm string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == perm
+	}
+	if !strings.HasPrefix(perm, parts[0]) {
+		return false
+	}
+	perm = perm[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(perm, part)
+		if i < 0 {
+			return false
+		}
+		perm = perm[i+len(part):]
+	}
+	return strings.HasSuffix(perm, last)
+}
+
+// CommandPerm returns the permission of command channel request, which is
+// the command prefix followed by the first word of command.
+func CommandPerm(cmd string) string {
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		return CMD_PREFIX
+	}
+	return CMD_PREFIX + fields[0]
+}
+
+// CommandHandler wraps a command handling function to authorize commands
+// for subject. Unauthorized commands are replied with an error message.
+// The returned function can be used as process command handler.
+func (p *Policy) CommandHandler(
+	subject string, f func(string) string) func(string) string {
+	return func(cmd string) string {
+		if err := p.Check(subject, CommandPerm(cmd)); err != nil {
+			return "ERROR: " + err.Error()
+		}
+		return f(cmd)
+	}
+}
+
+// HttpPerm returns the permission of HTTP request, in the form
+// http:<METHOD>:<PATH> where the path is cleaned from dot segments and
+// duplicate slashes, so patterns can not be bypassed with `..` segments.
+func HttpPerm(r *http.Request) string {
+	return HTTP_PREFIX + r.Method + ":" + cleanPath(r.URL.Path)
+}
+
+// cleanPath returns the canonical form of URL path, keeping the trailing
+// slash of directory paths.
+func cleanPath(p string) string {
+	if p == "*" {
+		return p
+	}
+	c := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && c != "/" {
+		c += "/"
+	}
+	return c
+}
+
+// HttpHandler wraps an HTTP handler to authorize requests. The subject
+// of request is resolved using subjectFn, where an empty subject means
+// unauthenticated request. Requests with non canonical paths are rejected
+// with bad request, so the authorized path is the path served by next.
+func (p *Policy) HttpHandler(
+	subjectFn func(*http.Request) string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "" && r.URL.Path != cleanPath(r.URL.Path) {
+			http.Error(w, http.StatusText(http.StatusBadRequest),
+				http.StatusBadRequest)
+			return
+		}
+		subject := subjectFn(r)
+		if subject == "" {
+			http.Error(w, http.StatusText(http.StatusUnauthorized),
+				http.StatusUnauthorized)
+			return
+		}
+		if !p.IsAllowed(subject, HttpPerm(r)) {
+			http.Error(w, http.StatusText(http.StatusForbidden),
+				http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ConsolePerm returns the permission of console shell command.
+func ConsolePerm(cmd string) string {
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		return CONSOLE_PREFIX
+	}
+	return CONSOLE_PREFIX + fields[0]
+}
+
+// CheckConsole returns [ErrDenied] if subject is not authorized to run
+// the console shell command.
+func (p *Policy) CheckConsole(subject, cmd string) error {
+	return p.Check(subject, ConsolePerm(cmd))
+}
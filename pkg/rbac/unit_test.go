@@ -0,0 +1,147 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package rbac_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/rbac"
+)
+
+const testConfig = `{
+	"roles": {
+		"viewer": {"permissions": ["cmd:status", "http:GET:*", "console:show"]},
+		"operator": {"permissions": ["cmd:restart"], "inherits": ["viewer"]},
+		"admin": {"permissions": ["*"]}
+	},
+	"subjects": {"alice": ["admin"], "bob": ["operator"], "eve": []}
+}`
+
+func loadPolicy(t *testing.T) *rbac.Policy {
+	var cfg map[string]any
+	require.NoError(t, json.Unmarshal([]byte(testConfig), &cfg))
+	p, err := rbac.Load(cfg)
+	require.NoError(t, err)
+	return p
+}
+
+func TestMatch(t *testing.T) {
+	assert.True(t, rbac.Match("*", "cmd:status"))
+	assert.True(t, rbac.Match("cmd:status", "cmd:status"))
+	assert.False(t, rbac.Match("cmd:status", "cmd:stop"))
+	assert.True(t, rbac.Match("http:GET:*", "http:GET:/status"))
+	assert.False(t, rbac.Match("http:GET:*", "http:POST:/status"))
+	assert.True(t, rbac.Match("http:*:/status", "http:PUT:/status"))
+	assert.False(t, rbac.Match("http:*:/status", "http:PUT:/config"))
+}
+
+func TestPolicy(t *testing.T) {
+	p := loadPolicy(t)
+	assert.Equal(t, []string{"admin", "operator", "viewer"}, p.Roles())
+	assert.Equal(t, []string{"operator"}, p.SubjectRoles("bob"))
+
+	assert.True(t, p.IsAllowed("alice", "cmd:shutdown"))
+	assert.True(t, p.IsAllowed("bob", "cmd:restart"))
+	assert.True(t, p.IsAllowed("bob", "cmd:status"))
+	assert.False(t, p.IsAllowed("bob", "cmd:shutdown"))
+	assert.False(t, p.IsAllowed("eve", "cmd:status"))
+	assert.False(t, p.IsAllowed("unknown", "cmd:status"))
+
+	assert.NoError(t, p.CheckConsole("bob", "show config"))
+	assert.ErrorIs(t, p.CheckConsole("bob", "reboot"), rbac.ErrDenied)
+
+	// undefined roles
+	assert.ErrorIs(t, p.Assign("eve", "root"), rbac.ErrConfig)
+	_, err := rbac.Load(map[string]any{
+		"subjects": map[string]any{"eve": []any{"root"}},
+	})
+	assert.ErrorIs(t, err, rbac.ErrConfig)
+	_, err = rbac.Load(map[string]any{
+		"roles": map[string]any{
+			"operator": map[string]any{"inherits": []any{"viewer"}},
+		},
+	})
+	assert.ErrorIs(t, err, rbac.ErrConfig)
+	assert.ErrorContains(t, err, "undefined inherited role: viewer")
+}
+
+func TestCommandHandler(t *testing.T) {
+	p := loadPolicy(t)
+	h := func(cmd string) string { return "OK" }
+
+	assert.Equal(t, "OK", p.CommandHandler("bob", h)("restart now"))
+	assert.Contains(t, p.CommandHandler("bob", h)("shutdown"), "ERROR")
+	assert.Contains(t, p.CommandHandler("eve", h)("status"), "ERROR")
+}
+
+func TestHttpHandler(t *testing.T) {
+	p := loadPolicy(t)
+	h := p.HttpHandler(func(r *http.Request) string {
+		return r.Header.Get("X-User")
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, tc := range []struct {
+		method, user string
+		code         int
+	}{
+		{http.MethodGet, "bob", http.StatusOK},
+		{http.MethodPost, "bob", http.StatusForbidden},
+		{http.MethodPost, "alice", http.StatusOK},
+		{http.MethodGet, "", http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(tc.method, "/status", nil)
+		req.Header.Set("X-User", tc.user)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		assert.Equal(t, tc.code, rec.Code, "%s %s", tc.method, tc.user)
+	}
+}
+
+func TestHttpPerm(t *testing.T) {
+	for path, perm := range map[string]string{
+		"/status":            "http:GET:/status",
+		"/public/":           "http:GET:/public/",
+		"/public/../admin/x": "http:GET:/admin/x",
+		"/public/./a//b/":    "http:GET:/public/a/b/",
+		"/":                  "http:GET:/",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.URL.Path = path
+		assert.Equal(t, perm, rbac.HttpPerm(req), path)
+	}
+	// escaped dot segments are decoded in path
+	req := httptest.NewRequest(http.MethodGet, "/public/%2e%2e/admin", nil)
+	assert.Equal(t, "http:GET:/admin", rbac.HttpPerm(req))
+
+	// wildcard grants can not be bypassed with dot segments
+	p := rbac.New()
+	p.SetRole(&rbac.Role{Name: "public", Permissions: []string{"http:GET:/public/*"}})
+	require.NoError(t, p.Assign("guest", "public"))
+	h := p.HttpHandler(func(r *http.Request) string {
+		return "guest"
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	for path, code := range map[string]int{
+		"/public/index.html":      http.StatusOK,
+		"/public/../admin/config": http.StatusBadRequest,
+		"/public//index.html":     http.StatusBadRequest,
+		"/admin/config":           http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.URL.Path = path
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		assert.Equal(t, code, rec.Code, path)
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrExists indicates that a service name is already registered.
+	ErrExists = errors.New("service already registered")
+	// ErrNotFound indicates that no matching service is registered.
+	ErrNotFound = errors.New("service not found")
+	// ErrType indicates that the service does not implement the requested type.
+	ErrType = errors.New("invalid service type")
+	// ErrAmbiguous indicates that multiple services match the requested type.
+	ErrAmbiguous = errors.New("ambiguous service type")
+	// ErrDependency indicates missing or circular service dependencies.
+	ErrDependency = errors.New("invalid service dependency")
+)
+
+// Initializer defines the lifecycle hook of services requiring
+// initialization when the registry starts.
+type Initializer interface {
+	Initialize() error
+}
+
+// Terminator defines the lifecycle hook of services requiring
+// termination when the registry stops.
+type Terminator interface {
+	Terminate() error
+}
+
+// entry represents a registered service.
+type entry struct {
+	name string
+	svc  any
+	deps []string
+}
+
+// Registry holds named services shared between routines and subsystems,
+// and manages their lifecycle hooks in dependency order.
+type Registry struct {
+	// entries holds the registered services in registration order.
+	entries []*entry
+	// index holds the registered services by name.
+	index map[string]*entry
+	// started holds the initialized services in initialization order.
+	started []*entry
+
+	mu sync.RWMutex
+}
+
+// New creates a new empty service registry.
+func New() *Registry {
+	return &Registry{
+		index: map[string]*entry{},
+	}
+}
+
+// Register adds a named service to the registry. The deps define the names
+// of services that must be initialized before this service.
+func (r *Registry) Register(name string, svc any, deps ...string) error {
+	if name == "" || svc == nil {
+		return errors.New("empty service name or instance")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.index[name]; ok {
+		return fmt.Errorf("%w - %s", ErrExists, name)
+	}
+	e := &entry{name: name, svc: svc, deps: deps}
+	r.entries = append(r.entries, e)
+	r.index[name] = e
+	return nil
+}
+
+// Unregister removes a named service from the registry.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.index[name]; !ok {
+		return
+	}
+	delete(r.index, name)
+	for i, e := range r.entries {
+		if e.name == name {
+			r.entries = append(r.entries[:i], r.entries[i+1:]...)
+			break
+		}
+	}
+}
+
+// Names returns the registered service names in registration order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.entries))
+	for _, e := range r.entries {
+		names = append(names, e.name)
+	}
+	return names
+}
+
+// Get returns a named service instance.
+func (r *Registry) Get(name string) (any, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.index[name]
+	if !ok {
+		return nil, fmt.Errorf("%w - %s", ErrNotFound, name)
+	}
+	return e.svc, nil
+}
+
+// Lookup returns a named service as type T, where T is usually
+// an interface type.
+func Lookup[T any](r *Registry, name string) (T, error) {
+	var zero T
+	svc, err := r.Get(name)
+	if err != nil {
+		return zero, err
+	}
+	v, ok := svc.(T)
+	if !ok {
+		return zero, fmt.Errorf("%w - %s", ErrType, name)
+	}
+	return v, nil
+}
+
+// Find returns the single registered service implementing type T.
+func Find[T any](r *Registry) (T, error) {
+	var zero T
+	all := FindAll[T](r)
+	switch len(all) {
+	case 0:
+		return zero, fmt.Errorf("%w - %T", ErrNotFound, (*T)(nil))
+	case 1:
+		return all[0], nil
+	}
+	return zero, fmt.Errorf("%w - %T", ErrAmbiguous, (*T)(nil))
+}
+
+// FindAll returns all registered services implementing type T
+// in registration order.
+func FindAll[T any](r *Registry) []T {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := []T{}
+	for _, e := range r.entries {
+		if v, ok := e.svc.(T); ok {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// order returns the services sorted by dependencies, keeping the
+// registration order for independent services.
+func (r *Registry) order() ([]*entry, error) {
+	res := make([]*entry, 0, len(r.entries))
+	state := map[string]int{} // 1: visiting, 2: done
+
+	var visit func(e *entry) error
+	visit = func(e *entry) error {
+		switch state[e.name] {
+		case 1:
+			return fmt.Errorf("%w - circular: %s", ErrDependency, e.name)
+		case 2:
+			return nil
+		}
+		state[e.name] = 1
+		for _, d := range e.deps {
+			de, ok := r.index[d]
+			if !ok {
+				return fmt.Errorf(
+					"%w - %s requires %s", ErrDependency, e.name, d)
+			}
+			if err := visit(de); err != nil {
+				return err
+			}
+		}
+		state[e.name] = 2
+		res = append(res, e)
+		return nil
+	}
+
+	for _, e := range r.entries {
+		if err := visit(e); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// Start initializes the registered services in dependency order, calling
+// the Initialize hook of services implementing [Initializer]. On failure,
+// the already initialized services are terminated in reverse order.
+func (r *Registry) Start() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.started) > 0 {
+		return errors.New("registry already started")
+	}
+
+	entries, err := r.order()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if s, ok := e.svc.(Initializer); ok {
+			if err := s.Initialize(); err != nil {
+				err = fmt.Errorf("service %s - %w", e.name, err)
+				return errors.Join(err, r.terminate())
+			}
+		}
+		r.started = append(r.started, e)
+	}
+	return nil
+}
+
+// Stop terminates the started services in reverse initialization order,
+// calling the Terminate hook of services implementing [Terminator].
+// All services are terminated and the errors are joined.
+func (r *Registry) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.terminate()
+}
+
+// terminate terminates the started services. requires lock to be held.
+func (r *Registry) terminate() error {
+	var errs []error
+	for i := len(r.started) - 1; i >= 0; i-- {
+		e := r.started[i]
+		if s, ok := e.svc.(Terminator); ok {
+			if err := s.Terminate(); err != nil {
+				errs = append(errs, fmt.Errorf("service %s - %w", e.name, err))
+			}
+		}
+	}
+	r.started = nil
+	return errors.Join(errs...)
+}
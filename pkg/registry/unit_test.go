@@ -0,0 +1,104 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package registry_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/registry"
+)
+
+type Store interface {
+	Load(key string) string
+}
+
+type service struct {
+	name  string
+	trace *[]string
+	fail  bool
+}
+
+func (s *service) Initialize() error {
+	*s.trace = append(*s.trace, "init:"+s.name)
+	if s.fail {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func (s *service) Terminate() error {
+	*s.trace = append(*s.trace, "term:"+s.name)
+	return nil
+}
+
+type memStore struct {
+	service
+}
+
+func (s *memStore) Load(key string) string {
+	return key
+}
+
+func TestLookup(t *testing.T) {
+	trace := []string{}
+	r := registry.New()
+	assert.NoError(t, r.Register("config", map[string]any{"k": "v"}))
+	assert.NoError(t, r.Register("store", &memStore{service{"store", &trace, false}}))
+	assert.ErrorIs(t, r.Register("store", "dup"), registry.ErrExists)
+	assert.Equal(t, []string{"config", "store"}, r.Names())
+
+	cfg, err := registry.Lookup[map[string]any](r, "config")
+	assert.NoError(t, err)
+	assert.Equal(t, "v", cfg["k"])
+	_, err = registry.Lookup[Store](r, "config")
+	assert.ErrorIs(t, err, registry.ErrType)
+	_, err = registry.Lookup[Store](r, "unknown")
+	assert.ErrorIs(t, err, registry.ErrNotFound)
+
+	s, err := registry.Find[Store](r)
+	assert.NoError(t, err)
+	assert.Equal(t, "x", s.Load("x"))
+
+	assert.NoError(t, r.Register("store2", &memStore{}))
+	_, err = registry.Find[Store](r)
+	assert.ErrorIs(t, err, registry.ErrAmbiguous)
+	assert.Len(t, registry.FindAll[Store](r), 2)
+
+	r.Unregister("store2")
+	_, err = registry.Find[Store](r)
+	assert.NoError(t, err)
+}
+
+func TestLifecycle(t *testing.T) {
+	trace := []string{}
+	r := registry.New()
+	assert.NoError(t, r.Register("api", &service{"api", &trace, false}, "db", "log"))
+	assert.NoError(t, r.Register("db", &service{"db", &trace, false}, "log"))
+	assert.NoError(t, r.Register("log", &service{"log", &trace, false}))
+
+	assert.NoError(t, r.Start())
+	assert.NoError(t, r.Stop())
+	assert.Equal(t, []string{
+		"init:log", "init:db", "init:api",
+		"term:api", "term:db", "term:log"}, trace)
+
+	// failed initialization
+	trace = trace[:0]
+	assert.NoError(t, r.Register("web", &service{"web", &trace, true}, "api"))
+	assert.Error(t, r.Start())
+	assert.Equal(t, []string{
+		"init:log", "init:db", "init:api", "init:web",
+		"term:api", "term:db", "term:log"}, trace)
+
+	// invalid dependencies
+	r = registry.New()
+	assert.NoError(t, r.Register("a", &service{"a", &trace, false}, "b"))
+	assert.ErrorIs(t, r.Start(), registry.ErrDependency)
+	assert.NoError(t, r.Register("b", &service{"b", &trace, false}, "a"))
+	assert.ErrorIs(t, r.Start(), registry.ErrDependency)
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package secrets
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoKeyring indicates the OS keyring is not available.
+var ErrNoKeyring = errors.New("keyring not available")
+
+// KeyringProvider loads secrets from the OS keyring, where each secret is
+// stored as a generic password of the provider service with the secret
+// name as account. It uses the Secret Service (secret-tool) on linux, the
+// login keychain (security) on macOS and the Credential Manager on
+// windows, with the target name "<service>:<name>".
+type KeyringProvider struct {
+	Service string
+}
+
+// NewKeyringProvider creates a new OS keyring secrets provider.
+func NewKeyringProvider(service string) *KeyringProvider {
+	return &KeyringProvider{Service: service}
+}
+
+// GetSecret returns the secret value from the OS keyring.
+func (p *KeyringProvider) GetSecret(name string) ([]byte, error) {
+	if name == "" {
+		return nil, fmt.Errorf("invalid secret name: %s", name)
+	}
+	return keyringGet(p.Service, name)
+}
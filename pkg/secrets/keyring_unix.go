@@ -0,0 +1,46 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// keyringGet looks up the secret using the keyring command line tools.
+func keyringGet(service, name string) ([]byte, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password",
+			"-s", service, "-a", name, "-w")
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.Command("secret-tool", "lookup",
+			"service", service, "username", name)
+	default:
+		return nil, ErrNoKeyring
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %v", ErrNoKeyring, err)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) == 0 {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrNoKeyring, err)
+	}
+	if len(out) == 0 {
+		return nil, ErrNotFound
+	}
+	return []byte(strings.TrimRight(string(out), "\r\n")), nil
+}
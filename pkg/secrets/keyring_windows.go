@@ -0,0 +1,67 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build windows
+
+package secrets
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modadvapi32   = windows.NewLazySystemDLL("advapi32.dll")
+	procCredReadW = modadvapi32.NewProc("CredReadW")
+	procCredFree  = modadvapi32.NewProc("CredFree")
+)
+
+// credTypeGeneric is the CRED_TYPE_GENERIC credential type.
+const credTypeGeneric = 1
+
+// credential maps the CREDENTIALW structure.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// keyringGet looks up the secret in the Credential Manager.
+func keyringGet(service, name string) ([]byte, error) {
+	if err := procCredReadW.Find(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoKeyring, err)
+	}
+	target, err := windows.UTF16PtrFromString(service + ":" + name)
+	if err != nil {
+		return nil, err
+	}
+
+	var cred *credential
+	r, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(target)),
+		credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		if err == windows.ERROR_NOT_FOUND {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrNoKeyring, err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	if cred.CredentialBlobSize == 0 {
+		return nil, ErrNotFound
+	}
+	blob := unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)
+	return append([]byte{}, blob...), nil
+}
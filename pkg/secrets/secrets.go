@@ -0,0 +1,168 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// REF_PREFIX defines the prefix of secret references in config values.
+//
+//	secret:<name>
+const REF_PREFIX = "secret:"
+
+var (
+	// ErrNotFound indicates a missing secret.
+	ErrNotFound = errors.New("secret not found")
+	// ErrInsecure indicates a secret file with insecure permissions.
+	ErrInsecure = errors.New("insecure secret file permissions")
+)
+
+// Provider defines the interface for at-rest secrets backends.
+type Provider interface {
+	// GetSecret returns the secret value by name, or [ErrNotFound].
+	GetSecret(name string) ([]byte, error)
+}
+
+/////////////////////////////////////////////////////
+
+// EnvProvider loads secrets from environment variables. The secret name
+// is converted to upper case, with dots and dashes replaced by underscores,
+// and prefixed by the provider prefix.
+type EnvProvider struct {
+	Prefix string
+}
+
+// NewEnvProvider creates a new environment variables secrets provider.
+func NewEnvProvider(prefix string) *EnvProvider {
+	return &EnvProvider{Prefix: prefix}
+}
+
+// GetSecret returns the secret value from environment variables.
+func (p *EnvProvider) GetSecret(name string) ([]byte, error) {
+	key := p.Prefix + strings.ToUpper(
+		strings.NewReplacer(".", "_", "-", "_").Replace(name))
+	if v, ok := os.LookupEnv(key); ok {
+		return []byte(v), nil
+	}
+	return nil, ErrNotFound
+}
+
+// FileProvider loads secrets from protected files in a directory, where
+// each secret is stored in a file with the secret name. On unix systems
+// the secret files must not be accessible by group or others.
+type FileProvider struct {
+	Dir string
+}
+
+// NewFileProvider creates a new protected files secrets provider.
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{Dir: filepath.Clean(dir)}
+}
+
+// GetSecret returns the secret value from the secret file.
+func (p *FileProvider) GetSecret(name string) ([]byte, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == ".." {
+		return nil, fmt.Errorf("invalid secret name: %s", name)
+	}
+	path := filepath.Join(p.Dir, name)
+
+	finfo, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if runtime.GOOS != "windows" && finfo.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInsecure, path)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.TrimRight(string(b), "\r\n")), nil
+}
+
+// FuncProvider adapts a function as secrets provider, used to hook external
+// backends such as TPM or KMS services.
+type FuncProvider func(name string) ([]byte, error)
+
+// GetSecret returns the secret value from the provider function.
+func (f FuncProvider) GetSecret(name string) ([]byte, error) {
+	return f(name)
+}
+
+// ChainProvider loads secrets from a list of providers, returning the
+// value from first provider having the secret.
+type ChainProvider []Provider
+
+// GetSecret returns the secret value from first provider having it.
+func (c ChainProvider) GetSecret(name string) ([]byte, error) {
+	for _, p := range c {
+		b, err := p.GetSecret(name)
+		if err == nil {
+			return b, nil
+		} else if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+	return nil, ErrNotFound
+}
+
+/////////////////////////////////////////////////////
+
+var (
+	defaultProvider Provider = NewEnvProvider("")
+	defaultMutex    sync.RWMutex
+)
+
+// SetDefault sets the default secrets provider for the deployment.
+// The initial default provider loads secrets from environment variables.
+func SetDefault(p Provider) {
+	if p == nil {
+		return
+	}
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	defaultProvider = p
+}
+
+// Default returns the default secrets provider.
+func Default() Provider {
+	defaultMutex.RLock()
+	defer defaultMutex.RUnlock()
+	return defaultProvider
+}
+
+// Get returns the secret value by name from the default provider.
+func Get(name string) ([]byte, error) {
+	return Default().GetSecret(name)
+}
+
+// IsRef checks if the value is a secret reference.
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, REF_PREFIX)
+}
+
+// Resolve returns the secret value for secret references from the default
+// provider, other values are returned as is.
+func Resolve(value string) (string, error) {
+	if !IsRef(value) {
+		return value, nil
+	}
+	b, err := Get(strings.TrimPrefix(value, REF_PREFIX))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
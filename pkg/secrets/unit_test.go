@@ -0,0 +1,104 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package secrets_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/secrets"
+)
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("APP_DB_PASSWORD", "secret1")
+
+	p := secrets.NewEnvProvider("APP_")
+	b, err := p.GetSecret("db.password")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret1", string(b))
+
+	_, err = p.GetSecret("missing")
+	assert.ErrorIs(t, err, secrets.ErrNotFound)
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(
+		filepath.Join(dir, "key"), []byte("secret2\n"), 0o600))
+	assert.NoError(t, os.WriteFile(
+		filepath.Join(dir, "open"), []byte("secret3"), 0o644))
+
+	p := secrets.NewFileProvider(dir)
+	b, err := p.GetSecret("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret2", string(b))
+
+	if runtime.GOOS != "windows" {
+		_, err = p.GetSecret("open")
+		assert.ErrorIs(t, err, secrets.ErrInsecure)
+	}
+	_, err = p.GetSecret("missing")
+	assert.ErrorIs(t, err, secrets.ErrNotFound)
+	_, err = p.GetSecret("../key")
+	assert.Error(t, err)
+}
+
+func TestChainAndResolve(t *testing.T) {
+	hook := secrets.FuncProvider(func(name string) ([]byte, error) {
+		if name == "kms_key" {
+			return []byte("secret4"), nil
+		}
+		return nil, secrets.ErrNotFound
+	})
+	t.Setenv("ENV_KEY", "secret5")
+
+	p := secrets.ChainProvider{secrets.NewEnvProvider(""), hook}
+	b, err := p.GetSecret("kms_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret4", string(b))
+
+	defer secrets.SetDefault(secrets.Default())
+	secrets.SetDefault(p)
+
+	v, err := secrets.Resolve("secret:env_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret5", v)
+	v, err = secrets.Resolve("plain value")
+	assert.NoError(t, err)
+	assert.Equal(t, "plain value", v)
+	_, err = secrets.Resolve("secret:missing")
+	assert.ErrorIs(t, err, secrets.ErrNotFound)
+}
+
+func TestKeyringProvider(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("keyring test requires linux")
+	}
+
+	// fake secret-tool that stores one secret
+	dir := t.TempDir()
+	script := "#!/bin/sh\n" +
+		"[ \"$3\" = \"app\" ] && [ \"$5\" = \"key\" ] && printf secret4 && exit 0\n" +
+		"exit 1\n"
+	assert.NoError(t, os.WriteFile(
+		filepath.Join(dir, "secret-tool"), []byte(script), 0o755))
+	t.Setenv("PATH", dir)
+
+	p := secrets.NewKeyringProvider("app")
+	b, err := p.GetSecret("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret4", string(b))
+
+	_, err = p.GetSecret("missing")
+	assert.ErrorIs(t, err, secrets.ErrNotFound)
+
+	t.Setenv("PATH", t.TempDir())
+	_, err = p.GetSecret("key")
+	assert.ErrorIs(t, err, secrets.ErrNoKeyring)
+}
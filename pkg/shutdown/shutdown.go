@@ -0,0 +1,228 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/logging"
+)
+
+// Shutdown hooks priorities, hooks with lower priority run first.
+const (
+	// PRIORITY_ROUTINES defines the priority for stopping routines
+	// and process managers.
+	PRIORITY_ROUTINES = 100
+	// PRIORITY_LISTENERS defines the priority for stopping comm listeners.
+	PRIORITY_LISTENERS = 200
+	// PRIORITY_DEFAULT defines the default hooks priority.
+	PRIORITY_DEFAULT = 500
+	// PRIORITY_LOGGING defines the priority for flushing logs.
+	PRIORITY_LOGGING = 1000
+)
+
+// HOOK_TIMEOUT defines the default hook timeout in seconds.
+const HOOK_TIMEOUT = 10
+
+// ErrTimeout indicates a shutdown hook timeout.
+var ErrTimeout = errors.New("shutdown hook timeout")
+
+// HookFunc defines the shutdown hook function. The context is canceled
+// when the hook timeout is reached.
+type HookFunc func(ctx context.Context) error
+
+// Stopper defines the interface for components stopped on shutdown,
+// implemented by proc routine managers and comm listeners.
+type Stopper interface {
+	Stop()
+}
+
+type hook struct {
+	name     string
+	priority int
+	timeout  float64
+	fn       HookFunc
+}
+
+// Coordinator manages the process shutdown, canceling the process-wide
+// context and running the ordered shutdown hooks once.
+type Coordinator struct {
+	// Log is the logger instance.
+	Log *logging.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	hooks  []hook
+	mu     sync.Mutex
+	once   sync.Once
+	done   chan struct{}
+	err    error
+	sigCh  chan os.Signal
+}
+
+// New creates a new shutdown coordinator.
+func New(log *logging.Logger) *Coordinator {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Coordinator{
+		Log:    log,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+}
+
+// Context returns the cancellation context, canceled when shutdown starts.
+func (c *Coordinator) Context() context.Context {
+	return c.ctx
+}
+
+// Add registers a shutdown hook with priority and timeout in seconds,
+// hooks with lower priority run first and hooks with same priority run
+// in registration order. Setting timeout=0 will use the default timeout
+// [HOOK_TIMEOUT].
+func (c *Coordinator) Add(name string, priority int, timeout float64, fn HookFunc) {
+	if timeout <= 0 {
+		timeout = HOOK_TIMEOUT
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, hook{name, priority, timeout, fn})
+}
+
+// AddStopper registers a shutdown hook stopping the component, such as
+// proc.RoutineManager or comm.Listener.
+func (c *Coordinator) AddStopper(name string, priority int, timeout float64, s Stopper) {
+	c.Add(name, priority, timeout, func(context.Context) error {
+		s.Stop()
+		return nil
+	})
+}
+
+// AddLogger registers a shutdown hook flushing the logger, running after
+// other hooks.
+func (c *Coordinator) AddLogger(log *logging.Logger) {
+	c.Add("logging", PRIORITY_LOGGING, 0, func(context.Context) error {
+		return log.Flush()
+	})
+}
+
+// NotifySignals triggers shutdown on receiving the signals. If no signals
+// are given, SIGINT and SIGTERM are used.
+func (c *Coordinator) NotifySignals(sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sigCh == nil {
+		c.sigCh = make(chan os.Signal, 2)
+		go func(ch chan os.Signal) {
+			select {
+			case sig := <-ch:
+				c.Shutdown(fmt.Sprintf("received signal: %v", sig))
+			case <-c.done:
+			}
+		}(c.sigCh)
+	}
+	signal.Notify(c.sigCh, sigs...)
+}
+
+// Shutdown cancels the context and runs the shutdown hooks in order,
+// and returns the combined hooks errors. Only the first call runs the
+// shutdown, later calls wait for it to complete.
+func (c *Coordinator) Shutdown(reason string) error {
+	c.once.Do(func() {
+		if c.Log != nil {
+			c.Log.Info("shutdown: %s", reason)
+		}
+		c.cancel()
+
+		c.mu.Lock()
+		hooks := append([]hook(nil), c.hooks...)
+		sigCh := c.sigCh
+		c.mu.Unlock()
+		if sigCh != nil {
+			signal.Stop(sigCh)
+		}
+
+		sort.SliceStable(hooks, func(i, j int) bool {
+			return hooks[i].priority < hooks[j].priority
+		})
+		var errAll error
+		for _, h := range hooks {
+			if err := c.run(h); err != nil {
+				if c.Log != nil {
+					c.Log.Error("shutdown hook %s failed: %s", h.name, err)
+				}
+				errAll = errors.Join(errAll,
+					fmt.Errorf("%s - %w", h.name, err))
+			}
+		}
+		c.err = errAll
+		close(c.done)
+	})
+	<-c.done
+	return c.err
+}
+
+// run runs the hook function with its timeout.
+func (c *Coordinator) run(h hook) error {
+	ctx, cancel := context.WithTimeout(context.Background(),
+		time.Duration(h.timeout*float64(time.Second)))
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errCh <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		errCh <- h.fn(ctx)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ErrTimeout
+	}
+}
+
+// Done returns a channel closed when shutdown completes.
+func (c *Coordinator) Done() <-chan struct{} {
+	return c.done
+}
+
+// Wait blocks until shutdown completes and returns the hooks errors.
+func (c *Coordinator) Wait() error {
+	<-c.done
+	return c.err
+}
+
+// Default is the process-wide shutdown coordinator.
+var Default = New(nil)
+
+// Context returns the process-wide cancellation context.
+func Context() context.Context {
+	return Default.Context()
+}
+
+// Add registers a shutdown hook on the process-wide coordinator.
+func Add(name string, priority int, timeout float64, fn HookFunc) {
+	Default.Add(name, priority, timeout, fn)
+}
+
+// Shutdown runs the process-wide shutdown.
+func Shutdown(reason string) error {
+	return Default.Shutdown(reason)
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package shutdown_test
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/shutdown"
+)
+
+func TestShutdownSignal(t *testing.T) {
+	sd := shutdown.New(nil)
+	sd.NotifySignals(syscall.SIGUSR1)
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+
+	select {
+	case <-sd.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("shutdown not triggered by signal")
+	}
+	assert.Error(t, sd.Context().Err())
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package shutdown_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/shutdown"
+)
+
+type stopper struct{ stopped bool }
+
+func (s *stopper) Stop() { s.stopped = true }
+
+func TestShutdownOrder(t *testing.T) {
+	sd := shutdown.New(nil)
+	order := []string{}
+	var mu sync.Mutex
+	add := func(name string, priority int) {
+		sd.Add(name, priority, 0, func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return nil
+		})
+	}
+	add("c", 300)
+	add("a", 100)
+	add("b1", 200)
+	add("b2", 200)
+
+	s := &stopper{}
+	sd.AddStopper("stopper", shutdown.PRIORITY_DEFAULT, 0, s)
+
+	assert.NoError(t, sd.Context().Err())
+	assert.NoError(t, sd.Shutdown("test"))
+	assert.Error(t, sd.Context().Err())
+	assert.Equal(t, []string{"a", "b1", "b2", "c"}, order)
+	assert.True(t, s.stopped)
+
+	// later calls return without running hooks again
+	assert.NoError(t, sd.Shutdown("again"))
+	assert.Len(t, order, 4)
+}
+
+func TestShutdownErrors(t *testing.T) {
+	sd := shutdown.New(nil)
+	errHook := errors.New("hook error")
+	sd.Add("err", 1, 0, func(context.Context) error { return errHook })
+	sd.Add("panic", 2, 0, func(context.Context) error { panic("boom") })
+	sd.Add("slow", 3, 0.05, func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	ran := false
+	sd.Add("last", 4, 0, func(context.Context) error {
+		ran = true
+		return nil
+	})
+
+	err := sd.Shutdown("test")
+	assert.ErrorIs(t, err, errHook)
+	assert.ErrorIs(t, err, shutdown.ErrTimeout)
+	assert.ErrorContains(t, err, "panic - panic: boom")
+	assert.True(t, ran)
+	assert.Equal(t, err, sd.Wait())
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package flowx
+
+import (
+	"sync"
+	"time"
+)
+
+// Debouncer delays calling a function until no more calls were requested
+// for a wait duration, collapsing bursts of calls into a single one.
+type Debouncer struct {
+	// fn is the debounced function.
+	fn func()
+	// wait defines the quiet duration before calling fn.
+	wait time.Duration
+
+	// timer holds the pending call timer.
+	timer *time.Timer
+	// gen identifies the pending call, to ignore the stale timers.
+	gen uint64
+	// stopped indicates that no more calls are accepted.
+	stopped bool
+	// mu defines mutex for debouncer state.
+	mu sync.Mutex
+}
+
+// Debounce creates a new [Debouncer] calling fn once no more calls were
+// requested for the wait duration.
+func Debounce(fn func(), wait time.Duration) *Debouncer {
+	return &Debouncer{fn: fn, wait: wait}
+}
+
+// Call requests a call, restarting the wait duration.
+func (d *Debouncer) Call() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		return
+	}
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.gen++
+	gen := d.gen
+	d.timer = time.AfterFunc(d.wait, func() { d.fire(gen) })
+}
+
+// fire runs the debounced function for the pending call.
+func (d *Debouncer) fire(gen uint64) {
+	d.mu.Lock()
+	if d.stopped || d.timer == nil || d.gen != gen {
+		d.mu.Unlock()
+		return
+	}
+	d.timer = nil
+	d.mu.Unlock()
+
+	d.fn()
+}
+
+// Flush runs the pending call immediately if any.
+func (d *Debouncer) Flush() {
+	d.mu.Lock()
+	if d.stopped || d.timer == nil {
+		d.mu.Unlock()
+		return
+	}
+	d.timer.Stop()
+	d.timer = nil
+	d.mu.Unlock()
+
+	d.fn()
+}
+
+// Stop cancels the pending call and ignores any further calls.
+func (d *Debouncer) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.stopped = true
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+}
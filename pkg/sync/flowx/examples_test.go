@@ -0,0 +1,30 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package flowx_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/sync/flowx"
+)
+
+func ExampleDebounce() {
+	done := make(chan struct{})
+	rescan := flowx.Debounce(func() {
+		fmt.Println("rescan devices")
+		close(done)
+	}, 10*time.Millisecond)
+	defer rescan.Stop()
+
+	// burst of hot plug events
+	for i := 0; i < 5; i++ {
+		rescan.Call()
+	}
+	<-done
+
+	// Output:
+	// rescan devices
+}
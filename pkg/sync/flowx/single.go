@@ -0,0 +1,66 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package flowx
+
+import (
+	"sync"
+)
+
+// call represents an in-flight function call shared by concurrent callers.
+type call[V any] struct {
+	wg    sync.WaitGroup
+	value V
+	err   error
+}
+
+// Group deduplicates concurrent function calls by key, so only a single
+// call is running for a key at a time and its result is shared.
+// The zero value is ready to use.
+type Group[K comparable, V any] struct {
+	// calls holds the in-flight calls by key.
+	calls map[K]*call[V]
+	// mu defines mutex for group state.
+	mu sync.Mutex
+}
+
+// Do runs fn for key, or waits for the in-flight call for the same key
+// and returns its result. The shared flag is true if the result was
+// taken from the in-flight call of another caller.
+func (g *Group[K, V]) Do(key K, fn func() (V, error)) (v V, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[K]*call[V]{}
+	}
+	if cl, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		cl.wg.Wait()
+		return cl.value, cl.err, true
+	}
+	cl := &call[V]{}
+	cl.wg.Add(1)
+	g.calls[key] = cl
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		if g.calls[key] == cl {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+		cl.wg.Done()
+	}()
+
+	cl.value, cl.err = fn()
+	return cl.value, cl.err, false
+}
+
+// Forget drops the in-flight call for key, so the next call for key runs
+// fn again instead of waiting for the current one.
+func (g *Group[K, V]) Forget(key K) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.calls, key)
+}
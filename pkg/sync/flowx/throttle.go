@@ -0,0 +1,78 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package flowx
+
+import (
+	"sync"
+	"time"
+)
+
+// Throttler limits calling a function to at most once per interval.
+// The first call runs immediately, and the calls requested during the
+// interval are collapsed into a single trailing call at its end.
+type Throttler struct {
+	// fn is the throttled function.
+	fn func()
+	// interval defines the min duration between calls.
+	interval time.Duration
+
+	// last holds the time of the last call.
+	last time.Time
+	// timer holds the pending trailing call timer.
+	timer *time.Timer
+	// stopped indicates that no more calls are accepted.
+	stopped bool
+	// mu defines mutex for throttler state.
+	mu sync.Mutex
+}
+
+// Throttle creates a new [Throttler] calling fn at most once per interval.
+func Throttle(fn func(), interval time.Duration) *Throttler {
+	return &Throttler{fn: fn, interval: interval}
+}
+
+// Call requests a call, running it now or at the end of current interval.
+func (t *Throttler) Call() {
+	t.mu.Lock()
+	if t.stopped || t.timer != nil {
+		t.mu.Unlock()
+		return
+	}
+	if d := t.interval - time.Since(t.last); d > 0 {
+		t.timer = time.AfterFunc(d, t.fire)
+		t.mu.Unlock()
+		return
+	}
+	t.last = time.Now()
+	t.mu.Unlock()
+
+	t.fn()
+}
+
+// fire runs the throttled function for the trailing call.
+func (t *Throttler) fire() {
+	t.mu.Lock()
+	if t.stopped || t.timer == nil {
+		t.mu.Unlock()
+		return
+	}
+	t.timer = nil
+	t.last = time.Now()
+	t.mu.Unlock()
+
+	t.fn()
+}
+
+// Stop cancels the pending trailing call and ignores any further calls.
+func (t *Throttler) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stopped = true
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package flowx_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/sync/flowx"
+)
+
+func TestDebounce(t *testing.T) {
+	var n atomic.Int32
+	d := flowx.Debounce(func() { n.Add(1) }, 30*time.Millisecond)
+	defer d.Stop()
+
+	for i := 0; i < 10; i++ {
+		d.Call()
+		time.Sleep(2 * time.Millisecond)
+	}
+	assert.Equal(t, int32(0), n.Load())
+	assert.Eventually(t, func() bool { return n.Load() == 1 },
+		time.Second, 5*time.Millisecond)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), n.Load())
+}
+
+func TestDebounceFlushStop(t *testing.T) {
+	var n atomic.Int32
+	d := flowx.Debounce(func() { n.Add(1) }, time.Hour)
+
+	// flush runs pending call immediately
+	d.Flush()
+	assert.Equal(t, int32(0), n.Load())
+	d.Call()
+	d.Flush()
+	assert.Equal(t, int32(1), n.Load())
+	d.Flush()
+	assert.Equal(t, int32(1), n.Load())
+
+	// stop drops pending and further calls
+	d.Call()
+	d.Stop()
+	d.Call()
+	d.Flush()
+	assert.Equal(t, int32(1), n.Load())
+}
+
+func TestThrottle(t *testing.T) {
+	var n atomic.Int32
+	th := flowx.Throttle(func() { n.Add(1) }, 50*time.Millisecond)
+	defer th.Stop()
+
+	// leading call runs immediately
+	th.Call()
+	assert.Equal(t, int32(1), n.Load())
+
+	// calls during interval collapse into a single trailing call
+	for i := 0; i < 10; i++ {
+		th.Call()
+	}
+	assert.Equal(t, int32(1), n.Load())
+	assert.Eventually(t, func() bool { return n.Load() == 2 },
+		time.Second, 5*time.Millisecond)
+
+	time.Sleep(80 * time.Millisecond)
+	assert.Equal(t, int32(2), n.Load())
+}
+
+func TestThrottleStop(t *testing.T) {
+	var n atomic.Int32
+	th := flowx.Throttle(func() { n.Add(1) }, 20*time.Millisecond)
+	th.Call()
+	th.Call()
+	th.Stop()
+	th.Call()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), n.Load())
+}
+
+func TestGroup(t *testing.T) {
+	var g flowx.Group[string, int]
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	var shared atomic.Int32
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err, s := g.Do("key", func() (int, error) {
+				calls.Add(1)
+				<-release
+				return 42, nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, 42, v)
+			if s {
+				shared.Add(1)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, int32(4), shared.Load())
+
+	// errors are returned and not kept
+	_, err, _ := g.Do("key", func() (int, error) {
+		return 0, errors.New("failed")
+	})
+	assert.EqualError(t, err, "failed")
+	v, err, _ := g.Do("key", func() (int, error) { return 1, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+}
+
+func TestGroupForget(t *testing.T) {
+	var g flowx.Group[int, int]
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		g.Do(1, func() (int, error) { <-release; return 1, nil })
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// forgotten key runs a new call
+	g.Forget(1)
+	v, _, s := g.Do(1, func() (int, error) { return 2, nil })
+	assert.Equal(t, 2, v)
+	assert.False(t, s)
+
+	close(release)
+	<-done
+}
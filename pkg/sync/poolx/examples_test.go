@@ -0,0 +1,28 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package poolx_test
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/exonlabs/go-utils/pkg/sync/poolx"
+)
+
+func ExampleNew() {
+	var total atomic.Int64
+	p := poolx.New(4, 8, func(n int) {
+		total.Add(int64(n * n))
+	})
+	for i := 1; i <= 10; i++ {
+		p.Submit(i)
+	}
+	p.Stop()
+
+	fmt.Println(total.Load(), p.Stats().Completed)
+
+	// Output:
+	// 385 10
+}
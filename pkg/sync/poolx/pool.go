@@ -0,0 +1,197 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package poolx
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrStopped indicates that the pool is stopped.
+	ErrStopped = errors.New("pool stopped")
+	// ErrFull indicates that all workers are busy and the queue is full.
+	ErrFull = errors.New("pool queue full")
+)
+
+// Stats holds the pool jobs counters.
+type Stats struct {
+	// Submitted is the number of accepted jobs.
+	Submitted uint64
+	// Rejected is the number of jobs rejected on full queue or stopped pool.
+	Rejected uint64
+	// Completed is the number of finished jobs, including panicked ones.
+	Completed uint64
+	// Panics is the number of recovered jobs panics.
+	Panics uint64
+	// Running is the number of jobs currently running.
+	Running int64
+	// Queued is the number of jobs waiting for a worker.
+	Queued int64
+}
+
+// Pool dispatches jobs of type T to a bounded number of worker goroutines
+// running the pool handler. A pool with zero size runs each job in its
+// own goroutine.
+type Pool[T any] struct {
+	// OnPanic is called with the recovered value when a job panics.
+	// Job panics are always recovered and counted, so a panicking job
+	// does not terminate the process or the pool worker.
+	OnPanic func(job T, r any)
+
+	// size defines the number of pool workers.
+	size int
+	// handler defines the jobs handler function.
+	handler func(T)
+
+	// jobCh holds the pending jobs.
+	jobCh chan T
+	// stopped indicates the pool stop, no more jobs are accepted.
+	stopped bool
+	// sMutex guards the stop state against concurrent submits.
+	sMutex sync.RWMutex
+	// waitGrp defines wait group for running jobs and workers.
+	waitGrp sync.WaitGroup
+
+	submitted, rejected, completed, panics atomic.Uint64
+	running, queued                        atomic.Int64
+}
+
+// New creates a new pool with size workers running handler for the
+// submitted jobs, and a pending jobs queue of queueSize.
+// Use size=0 to run one goroutine per job.
+func New[T any](size, queueSize int, handler func(T)) *Pool[T] {
+	if size < 0 {
+		size = 0
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	p := &Pool[T]{
+		size:    size,
+		handler: handler,
+	}
+	if size == 0 {
+		return p
+	}
+
+	p.jobCh = make(chan T, queueSize)
+	// start pool workers
+	for i := 0; i < size; i++ {
+		p.waitGrp.Add(1)
+		go func() {
+			defer p.waitGrp.Done()
+			for job := range p.jobCh {
+				p.queued.Add(-1)
+				p.run(job)
+			}
+		}()
+	}
+
+	return p
+}
+
+// NewFunc creates a new pool running submitted functions as jobs.
+func NewFunc(size, queueSize int) *Pool[func()] {
+	return New(size, queueSize, func(fn func()) { fn() })
+}
+
+// Size returns the number of pool workers.
+func (p *Pool[T]) Size() int {
+	return p.size
+}
+
+// Stats returns a snapshot of the pool counters.
+func (p *Pool[T]) Stats() Stats {
+	return Stats{
+		Submitted: p.submitted.Load(),
+		Rejected:  p.rejected.Load(),
+		Completed: p.completed.Load(),
+		Panics:    p.panics.Load(),
+		Running:   p.running.Load(),
+		Queued:    p.queued.Load(),
+	}
+}
+
+// run executes a job with the pool handler.
+func (p *Pool[T]) run(job T) {
+	p.running.Add(1)
+	defer func() {
+		p.running.Add(-1)
+		p.completed.Add(1)
+		if r := recover(); r != nil {
+			p.panics.Add(1)
+			if p.OnPanic != nil {
+				p.OnPanic(job, r)
+			}
+		}
+	}()
+	p.handler(job)
+}
+
+// Submit dispatches a job to the pool, blocking while the queue is full.
+// It returns ErrStopped if the pool is stopped.
+func (p *Pool[T]) Submit(job T) error {
+	return p.submit(job, true)
+}
+
+// TrySubmit dispatches a job to the pool without blocking.
+// It returns ErrFull if all workers are busy and the queue is full,
+// or ErrStopped if the pool is stopped.
+func (p *Pool[T]) TrySubmit(job T) error {
+	return p.submit(job, false)
+}
+
+func (p *Pool[T]) submit(job T, wait bool) error {
+	p.sMutex.RLock()
+	defer p.sMutex.RUnlock()
+
+	if p.stopped {
+		p.rejected.Add(1)
+		return ErrStopped
+	}
+
+	if p.size == 0 {
+		p.submitted.Add(1)
+		p.waitGrp.Add(1)
+		go func() {
+			defer p.waitGrp.Done()
+			p.run(job)
+		}()
+		return nil
+	}
+
+	p.queued.Add(1)
+	if wait {
+		p.jobCh <- job
+	} else {
+		select {
+		case p.jobCh <- job:
+		default:
+			p.queued.Add(-1)
+			p.rejected.Add(1)
+			return ErrFull
+		}
+	}
+	p.submitted.Add(1)
+	return nil
+}
+
+// Stop stops accepting new jobs, waits for all pending and running jobs
+// to finish, then terminates the pool workers.
+func (p *Pool[T]) Stop() {
+	p.sMutex.Lock()
+	if !p.stopped {
+		p.stopped = true
+		if p.jobCh != nil {
+			close(p.jobCh)
+		}
+	}
+	p.sMutex.Unlock()
+
+	p.waitGrp.Wait()
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package poolx_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/sync/poolx"
+)
+
+func TestSubmit(t *testing.T) {
+	for _, size := range []int{0, 1, 4} {
+		var sum atomic.Int64
+		p := poolx.New(size, 2, func(v int) { sum.Add(int64(v)) })
+		for i := 1; i <= 100; i++ {
+			assert.NoError(t, p.Submit(i))
+		}
+		p.Stop()
+		assert.Equal(t, int64(5050), sum.Load(), "size=%d", size)
+
+		st := p.Stats()
+		assert.Equal(t, uint64(100), st.Submitted)
+		assert.Equal(t, uint64(100), st.Completed)
+		assert.Equal(t, int64(0), st.Running)
+		assert.Equal(t, int64(0), st.Queued)
+	}
+}
+
+func TestTrySubmit(t *testing.T) {
+	release := make(chan struct{})
+	p := poolx.NewFunc(1, 1)
+
+	started := make(chan struct{})
+	assert.NoError(t, p.TrySubmit(func() { close(started); <-release }))
+	<-started
+	// fills the queue
+	assert.NoError(t, p.TrySubmit(func() {}))
+	// rejected on full queue
+	assert.ErrorIs(t, p.TrySubmit(func() {}), poolx.ErrFull)
+
+	st := p.Stats()
+	assert.Equal(t, int64(1), st.Running)
+	assert.Equal(t, int64(1), st.Queued)
+	assert.Equal(t, uint64(1), st.Rejected)
+
+	close(release)
+	p.Stop()
+	assert.Equal(t, uint64(2), p.Stats().Completed)
+}
+
+func TestStop(t *testing.T) {
+	var done atomic.Int64
+	p := poolx.NewFunc(2, 10)
+	for i := 0; i < 10; i++ {
+		p.Submit(func() {
+			time.Sleep(5 * time.Millisecond)
+			done.Add(1)
+		})
+	}
+
+	// drains pending jobs
+	p.Stop()
+	assert.Equal(t, int64(10), done.Load())
+
+	// rejects jobs after stop
+	assert.ErrorIs(t, p.Submit(func() {}), poolx.ErrStopped)
+	assert.ErrorIs(t, p.TrySubmit(func() {}), poolx.ErrStopped)
+	p.Stop()
+}
+
+func TestPanicRecovery(t *testing.T) {
+	var mu sync.Mutex
+	var failed []int
+	p := poolx.New(2, 0, func(v int) {
+		if v%2 == 0 {
+			panic("even")
+		}
+	})
+	p.OnPanic = func(v int, r any) {
+		mu.Lock()
+		defer mu.Unlock()
+		failed = append(failed, v)
+		assert.Equal(t, "even", r)
+	}
+	for i := 1; i <= 6; i++ {
+		p.Submit(i)
+	}
+	p.Stop()
+
+	assert.ElementsMatch(t, []int{2, 4, 6}, failed)
+	assert.Equal(t, uint64(3), p.Stats().Panics)
+	assert.Equal(t, uint64(6), p.Stats().Completed)
+
+	// panics recovered without callback
+	p = poolx.New(0, 0, func(v int) { panic(v) })
+	for i := 0; i < 3; i++ {
+		p.Submit(i)
+	}
+	p.Stop()
+	assert.Equal(t, uint64(3), p.Stats().Panics)
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package timerx
+
+import (
+	"context"
+	"time"
+
+	"github.com/exonlabs/go-utils/pkg/events"
+)
+
+// Sleep pauses for timeout seconds, or until any of the events is set.
+// It returns true if the full timeout elapsed, or false if interrupted.
+func Sleep(timeout float64, evts ...*events.Event) bool {
+	return events.WaitAny(timeout, evts...) < 0
+}
+
+// SleepUntil pauses until time t, or until any of the events is set.
+// It returns true if t was reached, or false if interrupted.
+func SleepUntil(t time.Time, evts ...*events.Event) bool {
+	return Sleep(time.Until(t).Seconds(), evts...)
+}
+
+// SleepCtx pauses for duration d, or until ctx is done.
+// It returns true if the full duration elapsed, or false if interrupted.
+func SleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package timerx
+
+import (
+	"sync"
+	"time"
+)
+
+// Ticker delivers ticks on the wall-clock boundaries of its interval,
+// for example every full minute for a 1 minute interval. Each tick is
+// scheduled from the current time, so the delivery does not drift over
+// time. Ticks are dropped if the receiver is slow, as [time.Ticker].
+type Ticker struct {
+	// C is the channel on which the ticks are delivered.
+	C <-chan time.Time
+
+	// interval defines the ticks interval.
+	interval time.Duration
+	// offset defines the ticks shift from interval boundaries.
+	offset time.Duration
+
+	c      chan time.Time
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+// NewTicker creates a new [Ticker] firing on the interval boundaries.
+func NewTicker(interval time.Duration) *Ticker {
+	return NewTickerAt(interval, 0)
+}
+
+// NewTickerAt creates a new [Ticker] firing on the interval boundaries
+// shifted by offset, for example at second 30 of every minute.
+func NewTickerAt(interval, offset time.Duration) *Ticker {
+	if interval <= 0 {
+		panic("non-positive interval for ticker")
+	}
+
+	c := make(chan time.Time, 1)
+	t := &Ticker{
+		C:        c,
+		interval: interval,
+		offset:   offset % interval,
+		c:        c,
+		stopCh:   make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// Next returns the next tick time after now.
+func (t *Ticker) Next(now time.Time) time.Time {
+	next := now.Truncate(t.interval).Add(t.offset)
+	for !next.After(now) {
+		next = next.Add(t.interval)
+	}
+	return next
+}
+
+// run delivers the ticks until stopped.
+func (t *Ticker) run() {
+	timer := time.NewTimer(time.Until(t.Next(time.Now())))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-t.stopCh:
+			return
+		case tick := <-timer.C:
+			select {
+			case t.c <- tick:
+			default:
+			}
+			timer.Reset(time.Until(t.Next(time.Now())))
+		}
+	}
+}
+
+// Stop turns off the ticker, no more ticks are delivered.
+func (t *Ticker) Stop() {
+	t.once.Do(func() { close(t.stopCh) })
+}
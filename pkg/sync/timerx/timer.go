@@ -0,0 +1,107 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package timerx
+
+import (
+	"sync"
+	"time"
+)
+
+// Timer calls a function after a duration, and can be stopped and
+// restarted any number of times. Stale expiries of a stopped or restarted
+// timer never call the function.
+type Timer struct {
+	// fn is the function called on expiry.
+	fn func()
+	// d defines the timer duration.
+	d time.Duration
+
+	// timer holds the running timer.
+	timer *time.Timer
+	// gen identifies the running timer, to ignore the stale expiries.
+	gen uint64
+	// mu defines mutex for timer state.
+	mu sync.Mutex
+}
+
+// NewTimer creates a new stopped [Timer] calling fn after duration d.
+func NewTimer(d time.Duration, fn func()) *Timer {
+	return &Timer{fn: fn, d: d}
+}
+
+// Start starts the timer if not running.
+func (t *Timer) Start() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.timer == nil {
+		t.start()
+	}
+}
+
+// Restart starts the timer again from now with the same duration.
+func (t *Timer) Restart() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stop()
+	t.start()
+}
+
+// Reset changes the timer duration to d and starts it again from now.
+func (t *Timer) Reset(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stop()
+	t.d = d
+	t.start()
+}
+
+// Stop stops the timer, it returns true if the timer was running.
+func (t *Timer) Stop() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.stop()
+}
+
+// IsActive checks if the timer is running.
+func (t *Timer) IsActive() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.timer != nil
+}
+
+// start runs a new timer. requires lock to be held.
+func (t *Timer) start() {
+	t.gen++
+	gen := t.gen
+	t.timer = time.AfterFunc(t.d, func() { t.fire(gen) })
+}
+
+// stop stops the running timer. requires lock to be held.
+func (t *Timer) stop() bool {
+	if t.timer == nil {
+		return false
+	}
+	t.timer.Stop()
+	t.timer = nil
+	return true
+}
+
+// fire calls the timer function for the current timer expiry.
+func (t *Timer) fire(gen uint64) {
+	t.mu.Lock()
+	if t.timer == nil || t.gen != gen {
+		t.mu.Unlock()
+		return
+	}
+	t.timer = nil
+	t.mu.Unlock()
+
+	t.fn()
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package timerx_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/events"
+	"github.com/exonlabs/go-utils/pkg/sync/timerx"
+)
+
+func TestTimer(t *testing.T) {
+	var n atomic.Int32
+	tm := timerx.NewTimer(30*time.Millisecond, func() { n.Add(1) })
+	assert.False(t, tm.IsActive())
+
+	tm.Start()
+	assert.True(t, tm.IsActive())
+	assert.Eventually(t, func() bool { return n.Load() == 1 },
+		time.Second, 5*time.Millisecond)
+	assert.False(t, tm.IsActive())
+
+	// restart postpones the expiry
+	tm.Start()
+	for i := 0; i < 5; i++ {
+		time.Sleep(10 * time.Millisecond)
+		tm.Restart()
+	}
+	assert.Equal(t, int32(1), n.Load())
+	assert.Eventually(t, func() bool { return n.Load() == 2 },
+		time.Second, 5*time.Millisecond)
+
+	// stop drops the expiry
+	tm.Reset(10 * time.Millisecond)
+	assert.True(t, tm.Stop())
+	assert.False(t, tm.Stop())
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, int32(2), n.Load())
+}
+
+func TestTickerNext(t *testing.T) {
+	tk := timerx.NewTickerAt(time.Minute, 30*time.Second)
+	defer tk.Stop()
+
+	now := time.Date(2024, 1, 1, 10, 15, 10, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 15, 30, 0, time.UTC), tk.Next(now))
+	now = time.Date(2024, 1, 1, 10, 15, 30, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 16, 30, 0, time.UTC), tk.Next(now))
+
+	tk2 := timerx.NewTicker(time.Hour)
+	defer tk2.Stop()
+	now = time.Date(2024, 1, 1, 10, 59, 59, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC), tk2.Next(now))
+}
+
+func TestTicker(t *testing.T) {
+	interval := 20 * time.Millisecond
+	tk := timerx.NewTicker(interval)
+	for i := 0; i < 3; i++ {
+		select {
+		case tick := <-tk.C:
+			// ticks are aligned on interval boundaries
+			off := tick.Sub(tick.Truncate(interval))
+			assert.Less(t, off, 15*time.Millisecond)
+		case <-time.After(time.Second):
+			t.Fatal("no tick")
+		}
+	}
+	tk.Stop()
+	tk.Stop()
+}
+
+func TestSleep(t *testing.T) {
+	e := events.New()
+	assert.True(t, timerx.Sleep(0.01, e))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		e.Set()
+	}()
+	t0 := time.Now()
+	assert.False(t, timerx.Sleep(5, events.New(), e))
+	assert.Less(t, time.Since(t0), time.Second)
+
+	assert.False(t, timerx.SleepUntil(time.Now().Add(time.Second), e))
+	assert.True(t, timerx.SleepUntil(time.Now().Add(10*time.Millisecond)))
+}
+
+func TestSleepCtx(t *testing.T) {
+	assert.True(t, timerx.SleepCtx(context.Background(), 10*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.False(t, timerx.SleepCtx(ctx, 5*time.Second))
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package testx
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ANSI colors used in failure messages.
+const (
+	COLOR_RED   = "\x1b[31m"
+	COLOR_GREEN = "\x1b[32m"
+	COLOR_RESET = "\x1b[0m"
+)
+
+// NoColor disables colored failure messages, enabled by default when
+// the NO_COLOR environment variable is set.
+var NoColor = os.Getenv("NO_COLOR") != ""
+
+// colored returns the text wrapped in color codes.
+func colored(color, text string) string {
+	if NoColor {
+		return text
+	}
+	return color + text + COLOR_RESET
+}
+
+// fail reports an assertion failure with expected and actual values.
+func fail(t testing.TB, title string, expected, actual any, msg []any) bool {
+	t.Helper()
+	s := colored(COLOR_RED, "FAIL: "+title)
+	if len(msg) > 0 {
+		s += " -- " + message(msg)
+	}
+	s += fmt.Sprintf("\n  expected: %s\n  actual  : %s",
+		colored(COLOR_GREEN, fmt.Sprintf("%#v", expected)),
+		colored(COLOR_RED, fmt.Sprintf("%#v", actual)))
+	t.Errorf("%s", s)
+	return false
+}
+
+// message formats the optional assertion message, where the first
+// item can be a format string.
+func message(msg []any) string {
+	if f, ok := msg[0].(string); ok && len(msg) > 1 {
+		return fmt.Sprintf(f, msg[1:]...)
+	}
+	return strings.TrimSpace(fmt.Sprintln(msg...))
+}
+
+// isNil returns whether the value is nil or a nil pointer, map,
+// slice, channel, function or interface.
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
+		reflect.Pointer, reflect.Slice, reflect.UnsafePointer:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// Equal asserts that expected and actual values are equal.
+func Equal(t testing.TB, expected, actual any, msg ...any) bool {
+	t.Helper()
+	if !assert.ObjectsAreEqual(expected, actual) {
+		return fail(t, "not equal", expected, actual, msg)
+	}
+	return true
+}
+
+// NotEqual asserts that expected and actual values are not equal.
+func NotEqual(t testing.TB, expected, actual any, msg ...any) bool {
+	t.Helper()
+	if assert.ObjectsAreEqual(expected, actual) {
+		return fail(t, "should not be equal", expected, actual, msg)
+	}
+	return true
+}
+
+// True asserts that the value is true.
+func True(t testing.TB, value bool, msg ...any) bool {
+	t.Helper()
+	if !value {
+		return fail(t, "should be true", true, value, msg)
+	}
+	return true
+}
+
+// False asserts that the value is false.
+func False(t testing.TB, value bool, msg ...any) bool {
+	t.Helper()
+	if value {
+		return fail(t, "should be false", false, value, msg)
+	}
+	return true
+}
+
+// Nil asserts that the value is nil.
+func Nil(t testing.TB, value any, msg ...any) bool {
+	t.Helper()
+	if !isNil(value) {
+		return fail(t, "should be nil", nil, value, msg)
+	}
+	return true
+}
+
+// NotNil asserts that the value is not nil.
+func NotNil(t testing.TB, value any, msg ...any) bool {
+	t.Helper()
+	if isNil(value) {
+		return fail(t, "should not be nil", "<not nil>", value, msg)
+	}
+	return true
+}
+
+// NoError asserts that err is nil.
+func NoError(t testing.TB, err error, msg ...any) bool {
+	t.Helper()
+	if err != nil {
+		return fail(t, "unexpected error", nil, err.Error(), msg)
+	}
+	return true
+}
+
+// Error asserts that err is not nil.
+func Error(t testing.TB, err error, msg ...any) bool {
+	t.Helper()
+	if err == nil {
+		return fail(t, "expected error", "<error>", nil, msg)
+	}
+	return true
+}
+
+// ErrorIs asserts that err matches target using [errors.Is].
+func ErrorIs(t testing.TB, err, target error, msg ...any) bool {
+	t.Helper()
+	if !errors.Is(err, target) {
+		return fail(t, "error mismatch", target, err, msg)
+	}
+	return true
+}
+
+// Contains asserts that the string s contains substr.
+func Contains(t testing.TB, s, substr string, msg ...any) bool {
+	t.Helper()
+	if !strings.Contains(s, substr) {
+		return fail(t, "substring not found", substr, s, msg)
+	}
+	return true
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package testx
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/exonlabs/go-utils/pkg/logging"
+)
+
+// GOLDEN_UPDATE_ENV defines the environment variable enabling golden
+// files update instead of comparison.
+const GOLDEN_UPDATE_ENV = "GOLDEN_UPDATE"
+
+// TempDir creates a temporary directory removed when the test ends.
+func TempDir(t testing.TB) string {
+	t.Helper()
+	return t.TempDir()
+}
+
+// TempFile creates a file with content in a temporary directory removed
+// when the test ends, and returns the file path.
+func TempFile(t testing.TB, name string, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o775); err != nil {
+		t.Fatalf("failed creating temp file: %s", err)
+	}
+	if err := os.WriteFile(path, content, 0o664); err != nil {
+		t.Fatalf("failed creating temp file: %s", err)
+	}
+	return path
+}
+
+// FreePort returns a free local TCP port.
+func FreePort(t testing.TB) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed allocating free port: %s", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// FreeUdpPort returns a free local UDP port.
+func FreeUdpPort(t testing.TB) int {
+	t.Helper()
+	c, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed allocating free port: %s", err)
+	}
+	defer c.Close()
+	return c.LocalAddr().(*net.UDPAddr).Port
+}
+
+// TempSocket returns a unix socket path in a temporary directory removed
+// when the test ends. The path is kept short to fit the unix socket path
+// length limit.
+func TempSocket(t testing.TB) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "testx")
+	if err != nil {
+		t.Fatalf("failed creating temp socket dir: %s", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "sock")
+}
+
+// Golden compares actual data with the golden file testdata/<name>.golden.
+// When the GOLDEN_UPDATE environment variable is set, the golden file is
+// written with actual data instead.
+func Golden(t testing.TB, name string, actual []byte) bool {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if os.Getenv(GOLDEN_UPDATE_ENV) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o775); err != nil {
+			t.Fatalf("failed updating golden file: %s", err)
+		}
+		if err := os.WriteFile(path, actual, 0o664); err != nil {
+			t.Fatalf("failed updating golden file: %s", err)
+		}
+		return true
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed reading golden file: %s", err)
+	}
+	if !bytes.Equal(expected, actual) {
+		return fail(t, "golden mismatch: "+path,
+			string(expected), string(actual), nil)
+	}
+	return true
+}
+
+// LogHandler defines a log handler writing records to the test log and
+// keeping them for inspection.
+type LogHandler struct {
+	t       testing.TB
+	mu      sync.Mutex
+	records []string
+	// flag set when the test ends, records are no more written to test log
+	done bool
+}
+
+// HandleRecord writes the log record to the test log.
+func (h *LogHandler) HandleRecord(record string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, record)
+	if !h.done {
+		h.t.Log(record)
+	}
+	return nil
+}
+
+// Records returns the captured log records.
+func (h *LogHandler) Records() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.records...)
+}
+
+// Contains returns whether any captured log record contains substr.
+func (h *LogHandler) Contains(substr string) bool {
+	for _, r := range h.Records() {
+		if strings.Contains(r, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// CaptureLog redirects the logger output to the test log, replacing the
+// logger handlers, and returns the capturing handler.
+func CaptureLog(t testing.TB, log *logging.Logger) *LogHandler {
+	h := &LogHandler{t: t}
+	t.Cleanup(func() {
+		h.mu.Lock()
+		h.done = true
+		h.mu.Unlock()
+	})
+	log.ClearHandlers()
+	log.AddHandler(h)
+	return h
+}
+
+// NewLogger creates a logger at the given level writing to the test log,
+// and returns the logger and its capturing handler.
+func NewLogger(t testing.TB, lvl logging.Level) (*logging.Logger, *LogHandler) {
+	log := &logging.Logger{Name: fmt.Sprintf("test.%s", t.Name()), Level: lvl}
+	log.SetFormatter(logging.NewStdFormatter())
+	return log, CaptureLog(t, log)
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package testx_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/logging"
+	"github.com/exonlabs/go-utils/pkg/testx"
+)
+
+// mockT records test failures without failing the running test.
+type mockT struct {
+	testing.TB
+	failed bool
+	msg    string
+}
+
+func (m *mockT) Helper() {}
+
+func (m *mockT) Errorf(format string, args ...any) {
+	m.failed = true
+	m.msg = fmt.Sprintf(format, args...)
+}
+
+func TestAssertions(t *testing.T) {
+	testx.NoColor = true
+	errBase := errors.New("base")
+	var nilPtr *int
+
+	passing := map[string]func(testing.TB) bool{
+		"Equal":    func(m testing.TB) bool { return testx.Equal(m, 1, 1) },
+		"NotEqual": func(m testing.TB) bool { return testx.NotEqual(m, 1, 2) },
+		"True":     func(m testing.TB) bool { return testx.True(m, true) },
+		"False":    func(m testing.TB) bool { return testx.False(m, false) },
+		"Nil":      func(m testing.TB) bool { return testx.Nil(m, nilPtr) },
+		"NotNil":   func(m testing.TB) bool { return testx.NotNil(m, &t) },
+		"NoError":  func(m testing.TB) bool { return testx.NoError(m, nil) },
+		"Error":    func(m testing.TB) bool { return testx.Error(m, errBase) },
+		"ErrorIs": func(m testing.TB) bool {
+			return testx.ErrorIs(m, fmt.Errorf("x: %w", errBase), errBase)
+		},
+		"Contains": func(m testing.TB) bool {
+			return testx.Contains(m, "hello world", "world")
+		},
+	}
+	for name, fn := range passing {
+		m := &mockT{}
+		assert.True(t, fn(m), name)
+		assert.False(t, m.failed, name)
+	}
+
+	failing := map[string]func(testing.TB) bool{
+		"Equal":    func(m testing.TB) bool { return testx.Equal(m, 1, 2, "n=%d", 5) },
+		"NotEqual": func(m testing.TB) bool { return testx.NotEqual(m, 1, 1) },
+		"True":     func(m testing.TB) bool { return testx.True(m, false) },
+		"False":    func(m testing.TB) bool { return testx.False(m, true) },
+		"Nil":      func(m testing.TB) bool { return testx.Nil(m, 1) },
+		"NotNil":   func(m testing.TB) bool { return testx.NotNil(m, nilPtr) },
+		"NoError":  func(m testing.TB) bool { return testx.NoError(m, errBase) },
+		"Error":    func(m testing.TB) bool { return testx.Error(m, nil) },
+		"ErrorIs": func(m testing.TB) bool {
+			return testx.ErrorIs(m, errors.New("other"), errBase)
+		},
+		"Contains": func(m testing.TB) bool {
+			return testx.Contains(m, "hello", "world")
+		},
+	}
+	for name, fn := range failing {
+		m := &mockT{}
+		assert.False(t, fn(m), name)
+		assert.True(t, m.failed, name)
+	}
+
+	m := &mockT{}
+	testx.Equal(m, 1, 2, "n=%d", 5)
+	assert.Equal(t,
+		"FAIL: not equal -- n=5\n  expected: 1\n  actual  : 2", m.msg)
+}
+
+func TestFixtures(t *testing.T) {
+	dir := testx.TempDir(t)
+	assert.DirExists(t, dir)
+
+	path := testx.TempFile(t, "sub/file.txt", []byte("data"))
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(b))
+
+	port := testx.FreePort(t)
+	assert.Greater(t, port, 0)
+	l, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if assert.NoError(t, err) {
+		l.Close()
+	}
+	assert.Greater(t, testx.FreeUdpPort(t), 0)
+
+	sock := testx.TempSocket(t)
+	assert.Less(t, len(sock), 100)
+	assert.NoFileExists(t, sock)
+}
+
+func TestGolden(t *testing.T) {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	assert.NoError(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+
+	t.Setenv(testx.GOLDEN_UPDATE_ENV, "1")
+	assert.True(t, testx.Golden(t, "sample", []byte("output")))
+	assert.FileExists(t, filepath.Join(dir, "testdata", "sample.golden"))
+
+	t.Setenv(testx.GOLDEN_UPDATE_ENV, "")
+	assert.True(t, testx.Golden(t, "sample", []byte("output")))
+	m := &mockT{TB: t}
+	assert.False(t, testx.Golden(m, "sample", []byte("changed")))
+	assert.True(t, m.failed)
+}
+
+func TestCaptureLog(t *testing.T) {
+	log, logs := testx.NewLogger(t, logging.INFO)
+	log.Info("hello %s", "world")
+	log.Debug("hidden")
+	assert.Len(t, logs.Records(), 1)
+	assert.True(t, logs.Contains("hello world"))
+	assert.False(t, logs.Contains("hidden"))
+}
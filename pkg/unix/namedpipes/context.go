@@ -17,6 +17,9 @@ const (
 	POLL_CHUNKSIZE = 4096
 	// POLL_MAXSIZE is the default maximum size for polling data.
 	POLL_MAXSIZE = 0
+	// MAX_MESSAGE_SIZE is the default maximum size of messages in
+	// message mode.
+	MAX_MESSAGE_SIZE = 1 << 20
 )
 
 // Context represents the configuration and state for communication handling.
@@ -34,6 +37,13 @@ type Context struct {
 	// PollMaxSize defines the maximum size for read polling data.
 	// use 0 or negative value to disable max limit for read data polling.
 	PollMaxSize int
+
+	// MessageMode enables length-prefixed messages, where each Write sends
+	// one message and each Read returns one complete message.
+	MessageMode bool
+	// MaxMessageSize defines the maximum size of messages in message mode.
+	// use 0 or negative value to disable max limit for messages.
+	MaxMessageSize int
 }
 
 // NewContext creates and initializes a new Context instance with optional settings.
@@ -42,13 +52,17 @@ type Context struct {
 //   - poll_chunksize: (int) the size of chunks to read during polling.
 //   - poll_maxsize: (int) the maximum size for read polling data.
 //     use 0 or negative value to disable max limit for read data polling.
+//   - message_mode: (bool) enable length-prefixed message framing.
+//   - max_message_size: (int) the maximum size of messages in message mode.
+//     use 0 or negative value to disable max limit for messages.
 func NewContext(path string, opts dictx.Dict) *Context {
 	ctx := &Context{
-		path:          filepath.Clean(path),
-		Options:       opts,
-		PollTimeout:   POLL_TIMEOUT,
-		PollChunkSize: POLL_CHUNKSIZE,
-		PollMaxSize:   POLL_MAXSIZE,
+		path:           filepath.Clean(path),
+		Options:        opts,
+		PollTimeout:    POLL_TIMEOUT,
+		PollChunkSize:  POLL_CHUNKSIZE,
+		PollMaxSize:    POLL_MAXSIZE,
+		MaxMessageSize: MAX_MESSAGE_SIZE,
 	}
 
 	// Apply custom options.
@@ -62,6 +76,10 @@ func NewContext(path string, opts dictx.Dict) *Context {
 		if v := dictx.GetInt(opts, "poll_maxsize", 0); v >= 0 {
 			ctx.PollMaxSize = v
 		}
+		ctx.MessageMode = dictx.Fetch(opts, "message_mode", false)
+		if dictx.IsExist(opts, "max_message_size") {
+			ctx.MaxMessageSize = dictx.GetInt(opts, "max_message_size", 0)
+		}
 	}
 
 	return ctx
@@ -23,4 +23,7 @@ var (
 
 	// ErrTimeout indicates that the operation timed out.
 	ErrTimeout = errors.New("operation timeout")
+
+	// ErrMessageSize indicates a message exceeding the max size.
+	ErrMessageSize = errors.New("message size exceeded")
 )
@@ -0,0 +1,61 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package namedpipes
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// MESSAGE_HEADER_SIZE defines the size of the message length prefix.
+const MESSAGE_HEADER_SIZE = 4
+
+// encodeMessage returns the message frame with length prefix.
+//
+//	frame: length(4) big-endian + data(N)
+func encodeMessage(data []byte, maxSize int) ([]byte, error) {
+	if maxSize > 0 && len(data) > maxSize {
+		return nil, fmt.Errorf("%w, %d bytes exceeds %d",
+			ErrMessageSize, len(data), maxSize)
+	}
+	b := make([]byte, MESSAGE_HEADER_SIZE, MESSAGE_HEADER_SIZE+len(data))
+	binary.BigEndian.PutUint32(b, uint32(len(data)))
+	return append(b, data...), nil
+}
+
+// messageBuffer reassembles message frames from received data.
+type messageBuffer struct {
+	buf []byte
+}
+
+// write appends received data to the buffer.
+func (m *messageBuffer) write(data []byte) {
+	m.buf = append(m.buf, data...)
+}
+
+// next returns the next complete message in the buffer if available.
+// On oversized messages the buffer is discarded, since the message
+// boundaries can not be recovered.
+func (m *messageBuffer) next(maxSize int) ([]byte, bool, error) {
+	if len(m.buf) < MESSAGE_HEADER_SIZE {
+		return nil, false, nil
+	}
+	n := int(binary.BigEndian.Uint32(m.buf))
+	if maxSize > 0 && n > maxSize {
+		m.buf = nil
+		return nil, false, fmt.Errorf("%w, %d bytes exceeds %d",
+			ErrMessageSize, n, maxSize)
+	}
+	if len(m.buf) < MESSAGE_HEADER_SIZE+n {
+		return nil, false, nil
+	}
+	msg := make([]byte, n)
+	copy(msg, m.buf[MESSAGE_HEADER_SIZE:])
+	m.buf = m.buf[MESSAGE_HEADER_SIZE+n:]
+	if len(m.buf) == 0 {
+		m.buf = nil
+	}
+	return msg, true, nil
+}
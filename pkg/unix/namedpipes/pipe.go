@@ -2,8 +2,6 @@
 // Use of this source code is governed by a BSD 3-Clause
 // license that can be found in the LICENSE file.
 
-//go:build !windows
-
 package namedpipes
 
 import (
@@ -24,6 +22,9 @@ type NamedPipe struct {
 
 	// breakEvent signals an interrupt in operations.
 	breakEvent *events.Event
+
+	// msgBuffer holds received data of incomplete messages in message mode.
+	msgBuffer messageBuffer
 }
 
 // New creates a new NamedPipe instance with options.
@@ -48,10 +48,38 @@ func (p *NamedPipe) close() {
 	p.fd = nil
 }
 
+// flock applies or removes an advisory lock on the open pipe.
+func (p *NamedPipe) flock(how int) error {
+	rc, err := p.fd.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var ferr error
+	if err := rc.Control(func(fd uintptr) {
+		ferr = unix.Flock(int(fd), how)
+	}); err != nil {
+		return err
+	}
+	return ferr
+}
+
+// Close closes the pipe file kept open between reads in message mode,
+// and discards buffered incomplete messages.
+func (p *NamedPipe) Close() {
+	p.close()
+	p.msgBuffer = messageBuffer{}
+}
+
 // Read waits to receive data from the named pipe until a timeout occurs,
 // cancel/close events or an error occurs.
 // timeout=0 waits forever until data is received.
+// In message mode, it returns one complete message and keeps the pipe
+// open for following reads until Close is called.
 func (p *NamedPipe) Read(timeout float64) ([]byte, error) {
+	if p.MessageMode {
+		return p.readMessage(timeout)
+	}
+
 	var data []byte
 
 	// set read polling timeout
@@ -115,10 +143,89 @@ func (p *NamedPipe) Read(timeout float64) ([]byte, error) {
 	return data, nil
 }
 
+// readMessage waits to receive a complete message from the named pipe.
+func (p *NamedPipe) readMessage(timeout float64) ([]byte, error) {
+	// set read polling timeout
+	var tPoll float64
+	if p.PollTimeout > 0 {
+		tPoll = p.PollTimeout
+	} else {
+		tPoll = POLL_TIMEOUT
+	}
+
+	// set timeout for the overall read wait if no message received
+	var tBreak float64
+	if timeout > 0 {
+		tBreak = float64(time.Now().Unix()) + timeout
+	}
+
+	p.breakEvent.Clear()
+	for {
+		// return buffered message from previous reads
+		msg, ok, err := p.msgBuffer.next(p.MaxMessageSize)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return msg, nil
+		}
+
+		// open pipe for read if not already openned, the pipe is kept
+		// open between reads so writers never see a closed pipe in the
+		// middle of a message
+		if p.fd == nil {
+			p.open_read()
+		}
+
+		if p.fd != nil {
+			// drain all available data, pending data in pipe is lost
+			// after closing, and keep extra messages buffered
+			b := make([]byte, p.PollChunkSize)
+			received := false
+			for {
+				n, err := p.fd.Read(b)
+				if n > 0 {
+					p.msgBuffer.write(b[:n])
+					received = true
+				}
+				if err != nil && err != io.EOF {
+					return nil, fmt.Errorf("%w, %v", ErrRead, err)
+				}
+				if n == 0 || err == io.EOF {
+					break
+				}
+			}
+			if received {
+				continue
+			}
+		}
+
+		if !p.breakEvent.Wait(tPoll) {
+			return nil, ErrBreak
+		}
+		if timeout > 0 {
+			if float64(time.Now().Unix()) >= tBreak {
+				return nil, ErrTimeout
+			}
+		}
+	}
+}
+
 // Write wait to write data to the named pipe until a timeout occurs,
 // cancel/close events or an error occurs.
 // timeout=0 waits forever until data is written.
+// In message mode, data is sent as one length-prefixed message while
+// holding an exclusive lock on the pipe, so that messages of concurrent
+// writers never interleave.
 func (p *NamedPipe) Write(data []byte, timeout float64) error {
+	if p.MessageMode {
+		var err error
+		if data, err = encodeMessage(data, p.MaxMessageSize); err != nil {
+			return err
+		}
+	}
+	locked := false
+
 	// set write polling timeout
 	var tPoll float64
 	if p.PollTimeout > 0 {
@@ -142,7 +249,17 @@ func (p *NamedPipe) Write(data []byte, timeout float64) error {
 			}
 		}
 
-		if p.fd != nil {
+		if p.fd != nil && p.MessageMode && !locked {
+			err := p.flock(unix.LOCK_EX | unix.LOCK_NB)
+			if err == nil {
+				locked = true
+				defer p.flock(unix.LOCK_UN)
+			} else if !errors.Is(err, unix.EWOULDBLOCK) {
+				return fmt.Errorf("%w, %v", ErrWrite, err)
+			}
+		}
+
+		if p.fd != nil && (locked || !p.MessageMode) {
 			if _, err := p.fd.Write(data); err != nil {
 				return fmt.Errorf("%w, %v", ErrWrite, err)
 			}
@@ -0,0 +1,333 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build windows
+
+package namedpipes
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// PIPE_PREFIX defines the namespace prefix of windows named pipes.
+const PIPE_PREFIX = `\\.\pipe\`
+
+// pipeName returns the windows pipe name for a path. Paths outside the
+// pipe namespace are mapped to a pipe named by the path base name.
+func pipeName(path string) string {
+	if strings.HasPrefix(strings.ToLower(path), PIPE_PREFIX) {
+		return path
+	}
+	return PIPE_PREFIX + filepath.Base(path)
+}
+
+// handle returns the windows handle of the open pipe.
+func (p *NamedPipe) handle() windows.Handle {
+	return windows.Handle(p.fd.Fd())
+}
+
+// open_read creates the pipe server instance for reading in non-blocking
+// mode. Only one instance is allowed, so writers are served one at a time.
+func (p *NamedPipe) open_read() error {
+	if p.fd != nil {
+		return nil
+	}
+	name := pipeName(p.path)
+	n, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrOpen, err)
+	}
+	h, err := windows.CreateNamedPipe(n,
+		windows.PIPE_ACCESS_INBOUND,
+		windows.PIPE_TYPE_BYTE|windows.PIPE_READMODE_BYTE|windows.PIPE_NOWAIT,
+		1, uint32(p.PollChunkSize), uint32(p.PollChunkSize), 0, nil)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrOpen, err)
+	}
+	p.fd = os.NewFile(uintptr(h), name)
+	return nil
+}
+
+// open_write opens the pipe as client for writing, failing if no
+// reader is listening or another writer is connected.
+func (p *NamedPipe) open_write() error {
+	if p.fd != nil {
+		return nil
+	}
+	name := pipeName(p.path)
+	n, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrOpen, err)
+	}
+	h, err := windows.CreateFile(n, windows.GENERIC_WRITE, 0, nil,
+		windows.OPEN_EXISTING, 0, 0)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrOpen, err)
+	}
+	p.fd = os.NewFile(uintptr(h), name)
+	return nil
+}
+
+// close closes the pipe if it's open.
+func (p *NamedPipe) close() {
+	if p.fd != nil {
+		p.fd.Close()
+	}
+	p.fd = nil
+}
+
+// readAvailable reads the data available from the connected writer,
+// reconnecting the server instance after a writer disconnects.
+// It returns the received data and whether a writer finished sending.
+func (p *NamedPipe) readAvailable(maxSize int) ([]byte, bool, error) {
+	h := p.handle()
+	err := windows.ConnectNamedPipe(h, nil)
+	switch {
+	case err == nil, errors.Is(err, windows.ERROR_PIPE_CONNECTED):
+	case errors.Is(err, windows.ERROR_NO_DATA):
+		// writer already closed, read its pending data until the
+		// broken pipe state then free instance for next writers
+	case errors.Is(err, windows.ERROR_PIPE_LISTENING):
+		return nil, false, nil
+	default:
+		return nil, false, fmt.Errorf("%w, %v", ErrRead, err)
+	}
+
+	var data []byte
+	b := make([]byte, p.PollChunkSize)
+	for maxSize <= 0 || len(data) < maxSize {
+		chunk := b
+		if maxSize > 0 && maxSize-len(data) < len(chunk) {
+			chunk = chunk[:maxSize-len(data)]
+		}
+		var n uint32
+		err := windows.ReadFile(h, chunk, &n, nil)
+		data = append(data, chunk[:n]...)
+		switch {
+		case err == nil:
+			if n == 0 {
+				return data, false, nil
+			}
+		case errors.Is(err, windows.ERROR_NO_DATA):
+			return data, false, nil
+		case errors.Is(err, windows.ERROR_BROKEN_PIPE):
+			windows.DisconnectNamedPipe(h)
+			return data, true, nil
+		default:
+			return nil, false, fmt.Errorf("%w, %v", ErrRead, err)
+		}
+	}
+	return data, false, nil
+}
+
+// Close closes the pipe kept open between reads in message mode,
+// and discards buffered incomplete messages.
+func (p *NamedPipe) Close() {
+	p.close()
+	p.msgBuffer = messageBuffer{}
+}
+
+// Read waits to receive data from the named pipe until a timeout occurs,
+// cancel/close events or an error occurs.
+// timeout=0 waits forever until data is received.
+// In message mode, it returns one complete message and keeps the pipe
+// open for following reads until Close is called.
+func (p *NamedPipe) Read(timeout float64) ([]byte, error) {
+	if p.MessageMode {
+		return p.readMessage(timeout)
+	}
+
+	var data []byte
+
+	// set read polling timeout
+	var tPoll float64
+	if p.PollTimeout > 0 {
+		tPoll = p.PollTimeout
+	} else {
+		tPoll = POLL_TIMEOUT
+	}
+
+	// set timeout for the overall read wait if no data received
+	var tBreak float64
+	if timeout > 0 {
+		tBreak = float64(time.Now().Unix()) + timeout
+	}
+
+	p.breakEvent.Clear()
+	for {
+		// create pipe for read if not already created
+		if p.fd == nil {
+			if err := p.open_read(); err == nil {
+				defer p.close()
+			}
+		}
+
+		if p.fd != nil {
+			maxSize := 0
+			if p.PollMaxSize > 0 {
+				maxSize = p.PollMaxSize - len(data)
+			}
+			b, done, err := p.readAvailable(maxSize)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, b...)
+			if p.PollMaxSize > 0 && len(data) >= p.PollMaxSize {
+				break
+			}
+			if len(data) > 0 && (done || len(b) == 0) {
+				break
+			}
+			if len(b) > 0 {
+				continue
+			}
+		}
+
+		if !p.breakEvent.Wait(tPoll) {
+			return nil, ErrBreak
+		}
+		if timeout > 0 {
+			if float64(time.Now().Unix()) >= tBreak {
+				return nil, ErrTimeout
+			}
+		}
+	}
+
+	return data, nil
+}
+
+// readMessage waits to receive a complete message from the named pipe.
+func (p *NamedPipe) readMessage(timeout float64) ([]byte, error) {
+	// set read polling timeout
+	var tPoll float64
+	if p.PollTimeout > 0 {
+		tPoll = p.PollTimeout
+	} else {
+		tPoll = POLL_TIMEOUT
+	}
+
+	// set timeout for the overall read wait if no message received
+	var tBreak float64
+	if timeout > 0 {
+		tBreak = float64(time.Now().Unix()) + timeout
+	}
+
+	p.breakEvent.Clear()
+	for {
+		// return buffered message from previous reads
+		msg, ok, err := p.msgBuffer.next(p.MaxMessageSize)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return msg, nil
+		}
+
+		// create pipe for read if not already created, the pipe is kept
+		// open between reads so writers are never disconnected in the
+		// middle of a message
+		if p.fd == nil {
+			p.open_read()
+		}
+
+		if p.fd != nil {
+			b, _, err := p.readAvailable(0)
+			if err != nil {
+				return nil, err
+			}
+			if len(b) > 0 {
+				p.msgBuffer.write(b)
+				continue
+			}
+		}
+
+		if !p.breakEvent.Wait(tPoll) {
+			return nil, ErrBreak
+		}
+		if timeout > 0 {
+			if float64(time.Now().Unix()) >= tBreak {
+				return nil, ErrTimeout
+			}
+		}
+	}
+}
+
+// Write wait to write data to the named pipe until a timeout occurs,
+// cancel/close events or an error occurs.
+// timeout=0 waits forever until data is written.
+// In message mode, data is sent as one length-prefixed message. Writers
+// are served one at a time by the reader, so messages of concurrent
+// writers never interleave.
+func (p *NamedPipe) Write(data []byte, timeout float64) error {
+	if p.MessageMode {
+		var err error
+		if data, err = encodeMessage(data, p.MaxMessageSize); err != nil {
+			return err
+		}
+	}
+
+	// set write polling timeout
+	var tPoll float64
+	if p.PollTimeout > 0 {
+		tPoll = p.PollTimeout
+	} else {
+		tPoll = POLL_TIMEOUT
+	}
+
+	// set timeout for the overall write wait if no data written
+	var tBreak float64
+	if timeout > 0 {
+		tBreak = float64(time.Now().Unix()) + timeout
+	}
+
+	p.breakEvent.Clear()
+	for {
+		// open pipe for write if not already openned
+		if p.fd == nil {
+			if err := p.open_write(); err == nil {
+				defer p.close()
+			}
+		}
+
+		if p.fd != nil {
+			if _, err := p.fd.Write(data); err != nil {
+				return fmt.Errorf("%w, %v", ErrWrite, err)
+			}
+			return nil
+		}
+
+		if !p.breakEvent.Wait(tPoll) {
+			return ErrBreak
+		}
+		if timeout > 0 {
+			if float64(time.Now().Unix()) >= tBreak {
+				return ErrTimeout
+			}
+		}
+	}
+}
+
+/////////////////////////////////////////////////////
+
+// Create validates the pipe path on windows, where named pipes exist
+// only while a reader is open, so no file is created.
+func Create(path string, perm os.FileMode) error {
+	if pipeName(filepath.Clean(path)) == PIPE_PREFIX {
+		return fmt.Errorf("invalid pipe path '%s'", path)
+	}
+	return nil
+}
+
+// Delete does nothing on windows, where named pipes are removed when
+// the reader is closed.
+func Delete(path string) error {
+	return nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package namedpipes_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/abc/dictx"
+	"github.com/exonlabs/go-utils/pkg/unix/namedpipes"
+)
+
+func TestMessageMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pipe")
+	require.NoError(t, namedpipes.Create(path, 0o600))
+	opts := dictx.Dict{"message_mode": true, "poll_timeout": 0.01}
+
+	reader := namedpipes.New(path, opts)
+	defer reader.Close()
+
+	// concurrent writers sending messages larger than the pipe buffer
+	const writers, count, size = 4, 10, 100000
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < count; i++ {
+				p := namedpipes.New(path, opts)
+				data := bytes.Repeat([]byte{byte('a' + w)}, size+i)
+				if err := p.Write(data, 5); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(w)
+	}
+
+	for i := 0; i < writers*count; i++ {
+		msg, err := reader.Read(5)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, len(msg), size)
+		require.Equal(t, bytes.Repeat(msg[:1], len(msg)), msg,
+			"message %d interleaved", i)
+	}
+	wg.Wait()
+}
+
+func TestMessageMode_MaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pipe")
+	require.NoError(t, namedpipes.Create(path, 0o600))
+
+	p := namedpipes.New(path, dictx.Dict{
+		"message_mode":     true,
+		"max_message_size": 10,
+	})
+	assert.ErrorIs(t, p.Write(make([]byte, 11), 1), namedpipes.ErrMessageSize)
+}
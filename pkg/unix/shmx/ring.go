@@ -0,0 +1,140 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package shmx
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"unsafe"
+)
+
+// ring header magic value marking an initialized ring.
+const ringMagic = 0x52494E47 // "RING"
+
+// RING_HEADER_SIZE defines the size of the ring header in the segment.
+const RING_HEADER_SIZE = 64
+
+// ErrRingType indicates a ring element type not allowed in shared memory.
+var ErrRingType = errors.New("invalid ring type")
+
+// ringHeader is the ring state stored at the segment start.
+type ringHeader struct {
+	magic    uint64
+	elemSize uint64
+	capacity uint64
+	head     uint64 // next slot to read, written by consumer
+	tail     uint64 // next slot to write, written by producer
+}
+
+// Ring is a fixed capacity ring buffer of T values in a shared memory
+// segment, for a single producer and a single consumer which may be in
+// different processes. Multiple producers or consumers must serialize
+// their access using the segment lock.
+//
+// T must be a fixed size type without pointers, such as numbers, arrays
+// and structs of these.
+type Ring[T any] struct {
+	hdr   *ringHeader
+	slots []T
+}
+
+// RingSize returns the segment size needed for a ring of capacity values.
+func RingSize[T any](capacity int) int {
+	var v T
+	return RING_HEADER_SIZE + capacity*int(unsafe.Sizeof(v))
+}
+
+// NewRing attaches a ring to a segment, initializing the ring state
+// if the segment is new. The capacity is derived from the segment size.
+func NewRing[T any](seg *Segment) (*Ring[T], error) {
+	var v T
+	typ := reflect.TypeOf(&v).Elem()
+	if err := checkPlain(typ); err != nil {
+		return nil, err
+	}
+	size := uint64(typ.Size())
+	if size == 0 {
+		return nil, fmt.Errorf("%w %s, zero size", ErrRingType, typ)
+	}
+	capacity := uint64(len(seg.data)-RING_HEADER_SIZE) / size
+	if len(seg.data) <= RING_HEADER_SIZE || capacity == 0 {
+		return nil, fmt.Errorf("%w %d for ring", ErrSize, len(seg.data))
+	}
+
+	hdr := (*ringHeader)(unsafe.Pointer(&seg.data[0]))
+	if atomic.LoadUint64(&hdr.magic) == 0 {
+		hdr.elemSize = size
+		hdr.capacity = capacity
+		atomic.StoreUint64(&hdr.magic, ringMagic)
+	} else if atomic.LoadUint64(&hdr.magic) != ringMagic ||
+		hdr.elemSize != size || hdr.capacity != capacity {
+		return nil, fmt.Errorf("%w %s, segment holds a different ring",
+			ErrRingType, typ)
+	}
+
+	slots := unsafe.Slice(
+		(*T)(unsafe.Pointer(&seg.data[RING_HEADER_SIZE])), capacity)
+	return &Ring[T]{hdr: hdr, slots: slots}, nil
+}
+
+// checkPlain validates that a type holds no pointers.
+func checkPlain(t reflect.Type) error {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16,
+		reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32,
+		reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return nil
+	case reflect.Array:
+		return checkPlain(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if err := checkPlain(t.Field(i).Type); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%w %s, only fixed size types without pointers",
+		ErrRingType, t)
+}
+
+// Cap returns the ring capacity.
+func (r *Ring[T]) Cap() int {
+	return len(r.slots)
+}
+
+// Len returns the number of values in the ring.
+func (r *Ring[T]) Len() int {
+	return int(atomic.LoadUint64(&r.hdr.tail) - atomic.LoadUint64(&r.hdr.head))
+}
+
+// Push appends a value to the ring, returning false if the ring is full.
+func (r *Ring[T]) Push(v T) bool {
+	tail := atomic.LoadUint64(&r.hdr.tail)
+	if tail-atomic.LoadUint64(&r.hdr.head) >= uint64(len(r.slots)) {
+		return false
+	}
+	r.slots[tail%uint64(len(r.slots))] = v
+	atomic.StoreUint64(&r.hdr.tail, tail+1)
+	return true
+}
+
+// Pop removes the oldest value from the ring, returning false if the
+// ring is empty.
+func (r *Ring[T]) Pop() (T, bool) {
+	var v T
+	head := atomic.LoadUint64(&r.hdr.head)
+	if head == atomic.LoadUint64(&r.hdr.tail) {
+		return v, false
+	}
+	v = r.slots[head%uint64(len(r.slots))]
+	atomic.StoreUint64(&r.hdr.head, head+1)
+	return v, true
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package shmx
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// SHM_DIR defines the mount path of POSIX shared memory objects.
+var SHM_DIR = "/dev/shm"
+
+var (
+	// ErrName indicates an invalid segment name.
+	ErrName = errors.New("invalid segment name")
+	// ErrSize indicates an invalid segment size.
+	ErrSize = errors.New("invalid segment size")
+)
+
+// Segment represents a mapped POSIX shared memory segment.
+type Segment struct {
+	name string
+	fd   *os.File
+	data []byte
+}
+
+// shmPath returns the shared memory object path of a segment name.
+func shmPath(name string) (string, error) {
+	name = strings.TrimPrefix(name, "/")
+	if name == "" || strings.Contains(name, "/") || name == "." || name == ".." {
+		return "", fmt.Errorf("%w '%s'", ErrName, name)
+	}
+	return filepath.Join(SHM_DIR, name), nil
+}
+
+// Create creates and maps a new shared memory segment of size bytes,
+// with memory zeroed. Existing segments are not modified and fail with
+// an error matching [os.ErrExist], use [Open] to attach to them.
+func Create(name string, size int, perm os.FileMode) (*Segment, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("%w %d", ErrSize, size)
+	}
+	path, err := shmPath(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(int64(size)); err != nil {
+		f.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	return mapSegment(name, f, size)
+}
+
+// Open opens and maps an existing shared memory segment, with the size
+// set by its creator.
+func Open(name string) (*Segment, error) {
+	path, err := shmPath(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.Size() <= 0 {
+		f.Close()
+		return nil, fmt.Errorf("%w %d", ErrSize, info.Size())
+	}
+	return mapSegment(name, f, int(info.Size()))
+}
+
+func mapSegment(name string, f *os.File, size int) (*Segment, error) {
+	data, err := unix.Mmap(int(f.Fd()), 0, size,
+		unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Segment{name: name, fd: f, data: data}, nil
+}
+
+// Remove removes a shared memory segment name. Mapped segments stay
+// valid until closed.
+func Remove(name string) error {
+	path, err := shmPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Name returns the segment name.
+func (s *Segment) Name() string {
+	return s.name
+}
+
+// Bytes returns the mapped segment memory.
+func (s *Segment) Bytes() []byte {
+	return s.data
+}
+
+// Size returns the segment size in bytes.
+func (s *Segment) Size() int {
+	return len(s.data)
+}
+
+// Lock acquires an exclusive lock on the segment, shared between
+// processes, blocking until the lock is available.
+func (s *Segment) Lock() error {
+	return unix.Flock(int(s.fd.Fd()), unix.LOCK_EX)
+}
+
+// TryLock acquires an exclusive lock on the segment without blocking,
+// returning false if the lock is held by another holder.
+func (s *Segment) TryLock() (bool, error) {
+	err := unix.Flock(int(s.fd.Fd()), unix.LOCK_EX|unix.LOCK_NB)
+	if errors.Is(err, unix.EWOULDBLOCK) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Unlock releases the segment lock.
+func (s *Segment) Unlock() error {
+	return unix.Flock(int(s.fd.Fd()), unix.LOCK_UN)
+}
+
+// Close unmaps the segment memory and closes the segment.
+func (s *Segment) Close() error {
+	var err error
+	if s.data != nil {
+		err = unix.Munmap(s.data)
+		s.data = nil
+	}
+	if e := s.fd.Close(); err == nil {
+		err = e
+	}
+	return err
+}
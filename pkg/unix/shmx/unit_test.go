@@ -0,0 +1,155 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package shmx_test
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/exonlabs/go-utils/pkg/unix/shmx"
+)
+
+type sample struct {
+	Id    uint32
+	Value float64
+	Tag   [4]byte
+}
+
+func segName(t *testing.T) string {
+	if _, err := os.Stat(shmx.SHM_DIR); err != nil {
+		shmx.SHM_DIR = t.TempDir()
+	}
+	name := fmt.Sprintf("shmx_test_%d_%s", os.Getpid(), t.Name())
+	t.Cleanup(func() { shmx.Remove(name) })
+	return name
+}
+
+func TestSegment(t *testing.T) {
+	name := segName(t)
+
+	s1, err := shmx.Create(name, 128, 0o600)
+	require.NoError(t, err)
+	defer s1.Close()
+	s2, err := shmx.Open(name)
+	require.NoError(t, err)
+	defer s2.Close()
+
+	assert.Equal(t, 128, s2.Size())
+	copy(s1.Bytes(), "shared")
+	assert.Equal(t, "shared", string(s2.Bytes()[:6]))
+
+	require.NoError(t, s1.Lock())
+	ok, err := s2.TryLock()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	require.NoError(t, s1.Unlock())
+	ok, err = s2.TryLock()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, s2.Unlock())
+
+	// existing segment is not truncated by create
+	_, err = shmx.Create(name, 64, 0o600)
+	assert.ErrorIs(t, err, os.ErrExist)
+	assert.Equal(t, "shared", string(s1.Bytes()[:6]))
+	s3, err := shmx.Open(name)
+	require.NoError(t, err)
+	assert.Equal(t, 128, s3.Size())
+	assert.Equal(t, "shared", string(s3.Bytes()[:6]))
+	assert.NoError(t, s3.Close())
+	_, err = shmx.Open(name + "_missing")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = shmx.Create("a/b", 128, 0o600)
+	assert.ErrorIs(t, err, shmx.ErrName)
+	_, err = shmx.Create(name, 0, 0o600)
+	assert.ErrorIs(t, err, shmx.ErrSize)
+}
+
+func TestRing(t *testing.T) {
+	name := segName(t)
+
+	seg, err := shmx.Create(name, shmx.RingSize[sample](4), 0o600)
+	require.NoError(t, err)
+	defer seg.Close()
+	producer, err := shmx.NewRing[sample](seg)
+	require.NoError(t, err)
+	assert.Equal(t, 4, producer.Cap())
+
+	seg2, err := shmx.Open(name)
+	require.NoError(t, err)
+	defer seg2.Close()
+	consumer, err := shmx.NewRing[sample](seg2)
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		assert.True(t, producer.Push(sample{Id: uint32(i), Value: float64(i) / 2}))
+	}
+	assert.False(t, producer.Push(sample{}), "ring should be full")
+	assert.Equal(t, 4, consumer.Len())
+
+	// wrap around
+	for i := 0; i < 10; i++ {
+		v, ok := consumer.Pop()
+		require.True(t, ok)
+		assert.Equal(t, uint32(i), v.Id)
+		assert.Equal(t, float64(i)/2, v.Value)
+		assert.True(t, producer.Push(sample{Id: uint32(i + 4), Value: float64(i+4) / 2}))
+	}
+	assert.Equal(t, 4, consumer.Len())
+
+	_, err = shmx.NewRing[uint16](seg2)
+	assert.ErrorIs(t, err, shmx.ErrRingType)
+	_, err = shmx.NewRing[*sample](seg2)
+	assert.ErrorIs(t, err, shmx.ErrRingType)
+	_, err = shmx.NewRing[string](seg2)
+	assert.ErrorIs(t, err, shmx.ErrRingType)
+}
+
+func TestRing_Concurrent(t *testing.T) {
+	name := segName(t)
+
+	seg, err := shmx.Create(name, shmx.RingSize[uint64](64), 0o600)
+	require.NoError(t, err)
+	defer seg.Close()
+	producer, err := shmx.NewRing[uint64](seg)
+	require.NoError(t, err)
+	seg2, err := shmx.Open(name)
+	require.NoError(t, err)
+	defer seg2.Close()
+	consumer, err := shmx.NewRing[uint64](seg2)
+	require.NoError(t, err)
+
+	const count = 20000
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := uint64(0); i < count; {
+			if producer.Push(i) {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+	for i := uint64(0); i < count; {
+		if v, ok := consumer.Pop(); ok {
+			require.Equal(t, i, v)
+			i++
+		} else {
+			runtime.Gosched()
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,15 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package updater
+
+import "os"
+
+// replaceFile atomically replaces dst with src, where the running
+// process keeps executing the replaced binary.
+func replaceFile(src, dst string) error {
+	return os.Rename(src, dst)
+}
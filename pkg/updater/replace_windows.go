@@ -0,0 +1,26 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+//go:build windows
+
+package updater
+
+import "os"
+
+// replaceFile replaces dst with src. The running binary can't be replaced
+// or removed on windows but can be renamed, so dst is first renamed aside
+// with [REPLACED_SUFFIX], and restored if replacing fails.
+func replaceFile(src, dst string) error {
+	old := dst + REPLACED_SUFFIX
+	// remove replaced binary of previous update if not running
+	os.Remove(old)
+	if err := os.Rename(dst, old); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		os.Rename(old, dst)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package updater_test
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/exonlabs/go-utils/pkg/updater"
+)
+
+func newServer(t *testing.T, key ed25519.PrivateKey, bin []byte) *httptest.Server {
+	return newVersionServer(t, key, bin, "2.0.0")
+}
+
+func newVersionServer(t *testing.T, key ed25519.PrivateKey, bin []byte,
+	version string) *httptest.Server {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+
+	sum := sha256.Sum256(bin)
+	m := &updater.Manifest{
+		Version: version,
+		Url:     srv.URL + "/bin",
+		Sha256:  hex.EncodeToString(sum[:]),
+	}
+	m.Sign(key)
+
+	mux.HandleFunc("/manifest", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(m)
+	})
+	mux.HandleFunc("/bin", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bin)
+	})
+	return srv
+}
+
+func newUpdater(t *testing.T, url string, key ed25519.PublicKey) *updater.Updater {
+	path := filepath.Join(t.TempDir(), "app")
+	assert.NoError(t, os.WriteFile(path, []byte("v1"), 0o755))
+	return &updater.Updater{
+		ManifestUrl: url + "/manifest",
+		PublicKey:   key,
+		ExecPath:    path,
+		Client:      http.DefaultClient,
+	}
+}
+
+func TestUpdateAndConfirm(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	srv := newServer(t, priv, []byte("v2"))
+	defer srv.Close()
+
+	u := newUpdater(t, srv.URL, pub)
+
+	// up to date version
+	m, err := u.Update("2.0.0")
+	assert.NoError(t, err)
+	assert.Nil(t, m)
+
+	m, err = u.Update("1.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0.0", m.Version)
+	assert.True(t, u.IsPending())
+
+	b, _ := os.ReadFile(u.ExecPath)
+	assert.Equal(t, "v2", string(b))
+	finfo, _ := os.Stat(u.ExecPath)
+	assert.Equal(t, os.FileMode(0o755), finfo.Mode().Perm())
+
+	// successful health check commits update
+	assert.NoError(t, u.Confirm(func() error { return nil }))
+	assert.False(t, u.IsPending())
+	assert.ErrorIs(t, u.Rollback(), updater.ErrNoBackup)
+}
+
+func TestRollback(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	srv := newServer(t, priv, []byte("v2"))
+	defer srv.Close()
+
+	u := newUpdater(t, srv.URL, pub)
+	_, err := u.Update("1.0.0")
+	assert.NoError(t, err)
+
+	// failed health check restores previous binary
+	errHealth := errors.New("health check failed")
+	assert.ErrorIs(t, u.Confirm(func() error { return errHealth }), errHealth)
+	assert.False(t, u.IsPending())
+	b, _ := os.ReadFile(u.ExecPath)
+	assert.Equal(t, "v1", string(b))
+}
+
+func TestVerifyFailures(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	srv := newServer(t, priv, []byte("v2"))
+	defer srv.Close()
+
+	// manifest signed with different key
+	u := newUpdater(t, srv.URL, otherPub)
+	_, err := u.Check()
+	assert.ErrorIs(t, err, updater.ErrSignature)
+
+	// checksum mismatch
+	m := &updater.Manifest{Version: "2.0.0", Url: srv.URL + "/bin",
+		Sha256: hex.EncodeToString(make([]byte, 32))}
+	_, err = u.Download(m)
+	assert.ErrorIs(t, err, updater.ErrChecksum)
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		res  int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.0.0", "1.0.0", 0},
+		{"1.0", "1.0.0", 0},
+		{"1.0.0+build5", "1.0.0", 0},
+		{"1.0.1", "1.0.0", 1},
+		{"1.10.0", "1.9.0", 1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.0.0-rc1", "1.0.0", -1},
+		{"0.4.0.dev", "0.4.0", -1},
+		{"1.0.0-rc.10", "1.0.0-rc.2", 1},
+		{"1.0.0-beta", "1.0.0-alpha", 1},
+		{"1.0.0.1", "1.0.0.dev", 1},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.res, updater.CompareVersions(tc.a, tc.b),
+			"%s vs %s", tc.a, tc.b)
+		assert.Equal(t, -tc.res, updater.CompareVersions(tc.b, tc.a),
+			"%s vs %s", tc.b, tc.a)
+	}
+}
+
+func TestUpdateDowngrade(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	// replayed older signed manifest
+	srv := newVersionServer(t, priv, []byte("v1.5"), "1.5.0")
+	defer srv.Close()
+
+	u := newUpdater(t, srv.URL, pub)
+	m, err := u.Update("2.0.0")
+	assert.ErrorIs(t, err, updater.ErrDowngrade)
+	assert.Nil(t, m)
+	assert.False(t, u.IsPending())
+	b, _ := os.ReadFile(u.ExecPath)
+	assert.Equal(t, "v1", string(b))
+
+	m, err = u.Update("1.5.0-rc1")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.5.0", m.Version)
+}
@@ -0,0 +1,319 @@
+// Copyright (c) 2024 ExonLabs, All rights reserved.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+package updater
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrSignature indicates an invalid manifest signature.
+	ErrSignature = errors.New("invalid manifest signature")
+	// ErrChecksum indicates a downloaded binary checksum mismatch.
+	ErrChecksum = errors.New("binary checksum mismatch")
+	// ErrNoBackup indicates that no previous binary exists for rollback.
+	ErrNoBackup = errors.New("no backup binary for rollback")
+	// ErrDowngrade indicates a manifest version older than current version.
+	ErrDowngrade = errors.New("manifest version is older than current")
+)
+
+const (
+	// BACKUP_SUFFIX defines the suffix of the previous binary backup.
+	BACKUP_SUFFIX = ".old"
+	// PENDING_SUFFIX defines the suffix of the pending update marker file.
+	PENDING_SUFFIX = ".pending"
+	// REPLACED_SUFFIX defines the suffix of the replaced running binary on
+	// windows, which can't be removed while running and is removed on the
+	// next replace.
+	REPLACED_SUFFIX = ".replaced"
+)
+
+// Manifest represents a signed release manifest.
+type Manifest struct {
+	Version   string `json:"version"`   // Release version
+	Url       string `json:"url"`       // Download URL of the release binary
+	Sha256    string `json:"sha256"`    // Hex encoded SHA-256 of the binary
+	Signature string `json:"signature"` // Base64 encoded ed25519 signature
+}
+
+// SignedData returns the manifest content covered by signature.
+func (m *Manifest) SignedData() []byte {
+	return []byte(strings.Join(
+		[]string{m.Version, m.Url, strings.ToLower(m.Sha256)}, "\n"))
+}
+
+// Sign signs the manifest with the release private key.
+func (m *Manifest) Sign(key ed25519.PrivateKey) {
+	m.Signature = base64.StdEncoding.EncodeToString(
+		ed25519.Sign(key, m.SignedData()))
+}
+
+// Verify checks the manifest signature with the release public key.
+func (m *Manifest) Verify(key ed25519.PublicKey) error {
+	sig, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil || len(key) != ed25519.PublicKeySize ||
+		!ed25519.Verify(key, m.SignedData(), sig) {
+		return ErrSignature
+	}
+	return nil
+}
+
+// CompareVersions compares two release versions and returns -1, 0 or 1
+// if version a is older, equal or newer than version b. Versions are split
+// into parts on the '.' and '-' chars, ignoring the 'v' prefix and the
+// build metadata after '+' char. Numeric parts are compared as numbers and
+// other parts as strings, where a missing part equals 0 against numeric
+// part, and is newer against non-numeric part, so pre-release versions
+// such as 1.0.0-rc1 or 1.0.0.dev are older than 1.0.0.
+func CompareVersions(a, b string) int {
+	split := func(v string) []string {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+		if i := strings.IndexByte(v, '+'); i >= 0 {
+			v = v[:i]
+		}
+		return strings.FieldsFunc(v, func(r rune) bool {
+			return r == '.' || r == '-'
+		})
+	}
+	pa, pb := split(a), split(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var sa, sb string
+		if i < len(pa) {
+			sa = pa[i]
+		}
+		if i < len(pb) {
+			sb = pb[i]
+		}
+		na, errA := strconv.ParseUint(sa, 10, 64)
+		nb, errB := strconv.ParseUint(sb, 10, 64)
+		switch {
+		case sa == "" && errB != nil:
+			return 1
+		case sb == "" && errA != nil:
+			return -1
+		case sa == "" || sb == "" || (errA == nil && errB == nil):
+			// numeric parts or missing part against numeric part
+			if na < nb {
+				return -1
+			} else if na > nb {
+				return 1
+			}
+		case errA == nil:
+			return 1
+		case errB == nil:
+			return -1
+		default:
+			if c := strings.Compare(sa, sb); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// Updater manages downloading, verifying and installing new releases
+// of the running binary.
+//
+// The update flow is:
+//   - Check: download and verify the release manifest.
+//   - Download: download and verify the release binary.
+//   - Install: swap the running binary atomically, keeping a backup.
+//   - restart the process gracefully, see the proc package
+//     [github.com/exonlabs/go-utils/pkg/proc.Process.Restart].
+//   - Confirm: on startup, run the health check of the new binary and
+//     either commit the update or rollback to the previous binary.
+type Updater struct {
+	// ManifestUrl defines the URL of the release manifest.
+	ManifestUrl string
+	// PublicKey defines the release signing public key.
+	PublicKey ed25519.PublicKey
+	// ExecPath defines the path of the binary to update.
+	ExecPath string
+	// Client defines the HTTP client used for downloads.
+	Client *http.Client
+}
+
+// New creates a new updater for the running executable.
+func New(manifestUrl string, pubKey ed25519.PublicKey) (*Updater, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		return nil, err
+	}
+	return &Updater{
+		ManifestUrl: manifestUrl,
+		PublicKey:   pubKey,
+		ExecPath:    path,
+		Client:      &http.Client{Timeout: 300 * time.Second},
+	}, nil
+}
+
+// fetch downloads url content into writer.
+func (u *Updater) fetch(url string, w io.Writer) error {
+	resp, err := u.Client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download failed: %s", resp.Status)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// Check downloads and verifies the release manifest.
+func (u *Updater) Check() (*Manifest, error) {
+	var b strings.Builder
+	if err := u.fetch(u.ManifestUrl, &b); err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal([]byte(b.String()), m); err != nil {
+		return nil, fmt.Errorf("invalid manifest - %v", err)
+	}
+	if err := m.Verify(u.PublicKey); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Download downloads the release binary to a temp file beside the
+// executable and verifies its checksum. Returns the temp file path.
+func (u *Updater) Download(m *Manifest) (string, error) {
+	f, err := os.CreateTemp(filepath.Dir(u.ExecPath),
+		"."+filepath.Base(u.ExecPath)+".*")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if err := u.fetch(m.Url, io.MultiWriter(f, h)); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	if hex.EncodeToString(h.Sum(nil)) != strings.ToLower(m.Sha256) {
+		os.Remove(f.Name())
+		return "", ErrChecksum
+	}
+	if err := f.Sync(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// Install replaces the executable with the downloaded binary, keeping
+// the current binary as backup and marking the update as pending.
+func (u *Updater) Install(path string) error {
+	finfo, err := os.Stat(u.ExecPath)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(path, finfo.Mode().Perm()); err != nil {
+		return err
+	}
+
+	bakPath := u.ExecPath + BACKUP_SUFFIX
+	os.Remove(bakPath)
+	if err := os.Link(u.ExecPath, bakPath); err != nil {
+		return err
+	}
+	if err := os.WriteFile(
+		u.ExecPath+PENDING_SUFFIX, nil, 0o664); err != nil {
+		return err
+	}
+	// atomic replace of executable
+	if err := replaceFile(path, u.ExecPath); err != nil {
+		os.Remove(u.ExecPath + PENDING_SUFFIX)
+		return err
+	}
+	return nil
+}
+
+// Update runs the full update flow, check, download and install.
+// Returns the installed manifest, or nil if current version is up to date.
+// Manifests older than current version are rejected with [ErrDowngrade],
+// so replayed or stale signed manifests can't downgrade the binary.
+func (u *Updater) Update(currentVersion string) (*Manifest, error) {
+	m, err := u.Check()
+	if err != nil {
+		return nil, err
+	}
+	switch CompareVersions(m.Version, currentVersion) {
+	case 0:
+		return nil, nil
+	case -1:
+		return nil, fmt.Errorf("%w: %s < %s",
+			ErrDowngrade, m.Version, currentVersion)
+	}
+	path, err := u.Download(m)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.Install(path); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return m, nil
+}
+
+// IsPending checks if an installed update is waiting confirmation.
+func (u *Updater) IsPending() bool {
+	_, err := os.Stat(u.ExecPath + PENDING_SUFFIX)
+	return err == nil
+}
+
+// Rollback restores the previous binary from backup.
+func (u *Updater) Rollback() error {
+	bakPath := u.ExecPath + BACKUP_SUFFIX
+	if _, err := os.Stat(bakPath); err != nil {
+		return ErrNoBackup
+	}
+	if err := replaceFile(bakPath, u.ExecPath); err != nil {
+		return err
+	}
+	os.Remove(u.ExecPath + PENDING_SUFFIX)
+	return nil
+}
+
+// Confirm is called on startup to validate a pending update using the
+// health check function. On success the update is committed, otherwise
+// the previous binary is restored and the health check error is returned,
+// the caller then should restart the process.
+// Does nothing if no pending update exists.
+func (u *Updater) Confirm(healthCheck func() error) error {
+	if !u.IsPending() {
+		return nil
+	}
+	if healthCheck != nil {
+		if err := healthCheck(); err != nil {
+			if rerr := u.Rollback(); rerr != nil {
+				return errors.Join(err, rerr)
+			}
+			return err
+		}
+	}
+	os.Remove(u.ExecPath + PENDING_SUFFIX)
+	os.Remove(u.ExecPath + BACKUP_SUFFIX)
+	os.Remove(u.ExecPath + REPLACED_SUFFIX)
+	return nil
+}
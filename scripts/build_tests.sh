@@ -16,21 +16,21 @@ rm -rf ${BUILD_PATH}
 mkdir -m 775 -p ${BUILD_LINUX_PATH} ${BUILD_WIN_PATH}
 
 # linux build
-for n in gx mapx slicex fsx numx dictx ;do
+for n in gx mapx slicex fsx numx dictx memoize exitcode ;do
     ${GO} test ./pkg/abc/${n} -c -o ${BUILD_LINUX_PATH}/${n}.test
 done
-for n in logging events queue ciphering console jconfig ;do
+for n in logging events queue ciphering console jconfig buildinfo updater licensing identity secrets rbac registry envcheck testx shutdown ;do
     ${GO} test ./pkg/${n} -c -o ${BUILD_LINUX_PATH}/${n}.test
 done
 
 # windows build
-for n in gx mapx slicex fsx numx dictx ;do
+for n in gx mapx slicex fsx numx dictx memoize exitcode ;do
     GOOS=windows GOARCH=amd64 ${GO} test \
         ./pkg/abc/${n} -c -o ${BUILD_WIN_PATH}/${n}.test_64.exe
     GOOS=windows GOARCH=386 ${GO} test \
         ./pkg/abc/${n} -c -o ${BUILD_WIN_PATH}/${n}.test_32.exe
 done
-for n in logging events queue ciphering console jconfig ;do
+for n in logging events queue ciphering console jconfig buildinfo updater licensing identity secrets rbac registry envcheck testx shutdown ;do
     GOOS=windows GOARCH=amd64 ${GO} test \
         ./pkg/${n} -c -o ${BUILD_WIN_PATH}/${n}.test_64.exe
     GOOS=windows GOARCH=386 ${GO} test \
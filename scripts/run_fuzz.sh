@@ -0,0 +1,16 @@
+#!/bin/bash
+cd $(dirname $(readlink -f $0))/..
+
+GO=go
+if [ ! -z "${GO_BIN}" ] ;then
+    GO=${GO_BIN}
+fi
+FUZZ_TIME=${FUZZ_TIME:-30s}
+
+# run all fuzz targets, each for FUZZ_TIME duration
+for pkg in $(grep -rl --include=fuzz_test.go '^func Fuzz' pkg |xargs -n1 dirname |sort -u) ;do
+    for fn in $(grep -ho '^func Fuzz[A-Za-z0-9_]*' ${pkg}/fuzz_test.go |cut -d' ' -f2) ;do
+        echo "-- fuzzing ./${pkg} ${fn}"
+        ${GO} test ./${pkg} -run '^$' -fuzz "^${fn}\$" -fuzztime ${FUZZ_TIME} || exit 1
+    done
+done